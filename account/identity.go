@@ -23,6 +23,9 @@ import (
 const (
 	// KeycloakIDP is the name of the main Keycloak Identity Provider
 	KeycloakIDP string = "kc"
+	// ServiceAccountIDP is the provider type of service accounts, i.e.
+	// identities of bots which authenticate with personal access tokens
+	ServiceAccountIDP string = "service-account"
 )
 
 // Identity describes a federated identity provided by Identity Provider (IDP) such as Keycloak, GitHub, OSO, etc.
@@ -42,6 +45,8 @@ type Identity struct {
 	// Link to User
 	UserID id.NullUUID `sql:"type:uuid"`
 	User   User
+	// The identity which created this identity if it is a service account
+	ServiceAccountOwnerID id.NullUUID `gorm:"column:service_account_owner_id" sql:"type:uuid"`
 }
 
 // TableName overrides the table name settings in Gorm to force a specific table name
@@ -50,6 +55,22 @@ func (m Identity) TableName() string {
 	return "identities"
 }
 
+// NewServiceAccount returns a service account identity with the given
+// username which belongs to the given owner
+func NewServiceAccount(username string, ownerID uuid.UUID) Identity {
+	return Identity{
+		ID:                    uuid.NewV4(),
+		Username:              username,
+		ProviderType:          ServiceAccountIDP,
+		ServiceAccountOwnerID: id.NullUUID{UUID: ownerID, Valid: true},
+	}
+}
+
+// IsServiceAccount returns true if the identity is a service account
+func (m Identity) IsServiceAccount() bool {
+	return m.ProviderType == ServiceAccountIDP
+}
+
 // GetETagData returns the field values to use to generate the ETag
 func (m Identity) GetETagData() []interface{} {
 	// using the 'ID' and 'UpdatedAt' (converted to number of seconds since epoch) fields
@@ -269,6 +290,14 @@ func IdentityFilterByID(identityID uuid.UUID) func(db *gorm.DB) *gorm.DB {
 	}
 }
 
+// IdentityFilterByServiceAccountOwner is a gorm filter for the service
+// accounts owned by the given identity
+func IdentityFilterByServiceAccountOwner(ownerID uuid.UUID) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("provider_type = ? AND service_account_owner_id = ?", ServiceAccountIDP, ownerID)
+	}
+}
+
 // IdentityWithUser is a gorm filter for preloading the User relationship.
 func IdentityWithUser() func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
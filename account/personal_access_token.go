@@ -0,0 +1,330 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypePersonalAccessTokens helps to avoid string literal
+const APIStringTypePersonalAccessTokens = "personalaccesstokens"
+
+// PersonalAccessTokenPrefix starts every personal access token so that they
+// can be told apart from JWTs
+const PersonalAccessTokenPrefix = "witpat_"
+
+// Scopes limit what can be done with a personal access token
+const (
+	// ScopeReadOnly only allows to read
+	ScopeReadOnly = "read-only"
+	// ScopeWorkItemWrite additionally allows to create, update and delete work
+	// items
+	ScopeWorkItemWrite = "workitem-write"
+	// ScopeSpaceAdmin allows everything the identity is allowed to do in its
+	// spaces
+	ScopeSpaceAdmin = "space-admin"
+)
+
+// KnownScopes lists all the scopes a personal access token can have
+var KnownScopes = []string{
+	ScopeReadOnly,
+	ScopeWorkItemWrite,
+	ScopeSpaceAdmin,
+}
+
+// Claims which are set on the JWT that stands in for a personal access token in
+// the request context
+const (
+	// TokenTypeClaim holds TokenTypePersonalAccessToken
+	TokenTypeClaim = "typ"
+	// TokenTypePersonalAccessToken marks the JWT of a personal access token
+	TokenTypePersonalAccessToken = "PAT"
+	// ScopesClaim holds the scopes of the personal access token
+	ScopesClaim = "scopes"
+)
+
+// PersonalAccessToken describes a token which is issued locally and lets an
+// identity, typically a service account, call the API without a JWT from the
+// Auth service. Only the hash of the token is stored.
+type PersonalAccessToken struct {
+	gormsupport.Lifecycle
+	ID uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	// IdentityID is the identity on whose behalf the token acts
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	// CreatorID is the identity which issued the token
+	CreatorID uuid.UUID `sql:"type:uuid"`
+	Name      string
+	TokenHash string
+	Scopes    pq.StringArray `sql:"type:text[]"`
+	// ExpiresAt is nil if the token never expires
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m PersonalAccessToken) TableName() string {
+	return "personal_access_tokens"
+}
+
+// Validate checks that the token has a name and only known scopes
+func (m PersonalAccessToken) Validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return errors.NewBadParameterError("name", m.Name).Expected("non empty string")
+	}
+	if len(m.Scopes) == 0 {
+		return errors.NewBadParameterError("scopes", m.Scopes).Expected(strings.Join(KnownScopes, ", "))
+	}
+	for _, s := range m.Scopes {
+		if !isKnownScope(s) {
+			return errors.NewBadParameterError("scopes", s).Expected(strings.Join(KnownScopes, ", "))
+		}
+	}
+	return nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, k := range KnownScopes {
+		if scope == k {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope returns true if the token was issued with the given scope
+func (m PersonalAccessToken) HasScope(scope string) bool {
+	for _, s := range m.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if the token is neither revoked nor expired at the
+// given time
+func (m PersonalAccessToken) IsActive(now time.Time) bool {
+	return m.RevokedAt == nil && (m.ExpiresAt == nil || now.Before(*m.ExpiresAt))
+}
+
+// JWT returns an unsigned JWT carrying the identity and the scopes of the
+// token. It stands in for the token in the request context so that the token
+// manager can locate the identity. It must not be presented to other services;
+// the roles of such requests are resolved locally.
+func (m PersonalAccessToken) JWT() *jwt.Token {
+	scopes := make([]interface{}, len(m.Scopes))
+	for i, s := range m.Scopes {
+		scopes[i] = s
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"sub":          m.IdentityID.String(),
+		"jti":          m.ID.String(),
+		TokenTypeClaim: TokenTypePersonalAccessToken,
+		ScopesClaim:    scopes,
+	})
+	token.Valid = true
+	return token
+}
+
+// IsPersonalAccessToken returns true if the given bearer token looks like a
+// personal access token
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
+
+// ContextPersonalAccessTokenScopes returns the scopes of the personal access
+// token the request was made with. The second return value is false if the
+// request was not made with a personal access token.
+func ContextPersonalAccessTokenScopes(ctx context.Context) ([]string, bool) {
+	token := goajwt.ContextJWT(ctx)
+	if token == nil {
+		return nil, false
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims[TokenTypeClaim] != TokenTypePersonalAccessToken {
+		return nil, false
+	}
+	scopes := []string{}
+	if list, ok := claims[ScopesClaim].([]interface{}); ok {
+		for _, s := range list {
+			if scope, ok := s.(string); ok {
+				scopes = append(scopes, scope)
+			}
+		}
+	}
+	return scopes, true
+}
+
+func hashPersonalAccessToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func generatePersonalAccessToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", errs.Wrap(err, "failed to generate a personal access token")
+	}
+	return PersonalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// PersonalAccessTokenRepository represents the storage interface.
+type PersonalAccessTokenRepository interface {
+	// Create stores the given token and returns its plain value which cannot
+	// be retrieved later
+	Create(ctx context.Context, t *PersonalAccessToken) (string, error)
+	Load(ctx context.Context, id uuid.UUID) (*PersonalAccessToken, error)
+	// List returns the tokens issued by the given identity
+	List(ctx context.Context, creatorID uuid.UUID) ([]PersonalAccessToken, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	// Authenticate returns the active token with the given plain value and
+	// records that it has been used
+	Authenticate(ctx context.Context, token string) (*PersonalAccessToken, error)
+}
+
+// NewPersonalAccessTokenRepository creates a new storage type.
+func NewPersonalAccessTokenRepository(db *gorm.DB) PersonalAccessTokenRepository {
+	return &GormPersonalAccessTokenRepository{db: db}
+}
+
+// GormPersonalAccessTokenRepository is the implementation of the storage
+// interface for personal access tokens.
+type GormPersonalAccessTokenRepository struct {
+	db *gorm.DB
+}
+
+// Create a new personal access token
+func (r *GormPersonalAccessTokenRepository) Create(ctx context.Context, t *PersonalAccessToken) (string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "create"}, time.Now())
+	if err := t.Validate(); err != nil {
+		return "", err
+	}
+	token, err := generatePersonalAccessToken()
+	if err != nil {
+		return "", errors.NewInternalError(ctx, err)
+	}
+	t.ID = uuid.NewV4()
+	t.TokenHash = hashPersonalAccessToken(token)
+	t.LastUsedAt = nil
+	t.RevokedAt = nil
+	if err := r.db.Create(t).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": t.IdentityID,
+			"err":         err,
+		}, "unable to create the personal access token")
+		return "", errors.NewInternalError(ctx, err)
+	}
+	return token, nil
+}
+
+// Load returns the personal access token with the given ID
+func (r *GormPersonalAccessTokenRepository) Load(ctx context.Context, id uuid.UUID) (*PersonalAccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "show"}, time.Now())
+	t := PersonalAccessToken{}
+	tx := r.db.Where("id = ?", id).First(&t)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("personal access token", id.String())
+	}
+	if tx.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"token_id": id,
+			"err":      tx.Error,
+		}, "unable to load the personal access token by ID")
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	return &t, nil
+}
+
+// List returns the personal access tokens issued by the given identity
+func (r *GormPersonalAccessTokenRepository) List(ctx context.Context, creatorID uuid.UUID) ([]PersonalAccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "list"}, time.Now())
+	var objs []PersonalAccessToken
+	err := r.db.Where("creator_id = ?", creatorID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Revoke marks the personal access token with the given ID as revoked. Revoking
+// a token twice keeps the time of the first revocation.
+func (r *GormPersonalAccessTokenRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "revoke"}, time.Now())
+	if _, err := r.Load(ctx, id); err != nil {
+		return err
+	}
+	err := r.db.Model(&PersonalAccessToken{}).Where("id = ? AND revoked_at IS NULL", id).UpdateColumn("revoked_at", time.Now()).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"token_id": id,
+			"err":      err,
+		}, "unable to revoke the personal access token")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Authenticate returns the active personal access token with the given plain
+// value and records the time of its use. Unknown, revoked and expired tokens
+// are rejected with an unauthorized error.
+func (r *GormPersonalAccessTokenRepository) Authenticate(ctx context.Context, token string) (*PersonalAccessToken, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "personal_access_token", "authenticate"}, time.Now())
+	t := PersonalAccessToken{}
+	tx := r.db.Where("token_hash = ?", hashPersonalAccessToken(token)).First(&t)
+	if tx.RecordNotFound() {
+		return nil, errors.NewUnauthorizedError("unknown personal access token")
+	}
+	if tx.Error != nil {
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	now := time.Now()
+	if !t.IsActive(now) {
+		log.Warn(ctx, map[string]interface{}{
+			"token_id":    t.ID,
+			"identity_id": t.IdentityID,
+		}, "rejected a revoked or expired personal access token")
+		return nil, errors.NewUnauthorizedError("personal access token is revoked or expired")
+	}
+	// UpdateColumn keeps the updated_at timestamp as using the token does not
+	// change it
+	err := r.db.Model(&t).UpdateColumn("last_used_at", now).Error
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	t.LastUsedAt = &now
+	return &t, nil
+}
+
+// ContextPersonalAccessTokenID returns the ID of the personal access token the
+// request was made with. The second return value is false if the request was
+// not made with a personal access token.
+func ContextPersonalAccessTokenID(ctx context.Context) (uuid.UUID, bool) {
+	if _, ok := ContextPersonalAccessTokenScopes(ctx); !ok {
+		return uuid.Nil, false
+	}
+	claims := goajwt.ContextJWT(ctx).Claims.(jwt.MapClaims)
+	jti, _ := claims["jti"].(string)
+	id, err := uuid.FromString(jti)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,170 @@
+package account_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/lib/pq"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type personalAccessTokenBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo account.PersonalAccessTokenRepository
+}
+
+func TestRunPersonalAccessTokenBlackBoxTest(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &personalAccessTokenBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *personalAccessTokenBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = account.NewPersonalAccessTokenRepository(s.DB)
+}
+
+func (s *personalAccessTokenBlackBoxTest) newToken(identityID uuid.UUID, scopes ...string) account.PersonalAccessToken {
+	return account.PersonalAccessToken{
+		IdentityID: identityID,
+		CreatorID:  identityID,
+		Name:       "release bot",
+		Scopes:     pq.StringArray(scopes),
+	}
+}
+
+func (s *personalAccessTokenBlackBoxTest) TestCreate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1))
+
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		pat := s.newToken(fxt.Identities[0].ID, account.ScopeReadOnly)
+		// when
+		token, err := s.repo.Create(s.Ctx, &pat)
+		// then
+		require.NoError(t, err)
+		assert.True(t, account.IsPersonalAccessToken(token))
+		assert.NotEqual(t, uuid.Nil, pat.ID)
+		assert.NotContains(t, pat.TokenHash, strings.TrimPrefix(token, account.PersonalAccessTokenPrefix), "only the hash must be stored")
+		loaded, err := s.repo.Load(s.Ctx, pat.ID)
+		require.NoError(t, err)
+		assert.Equal(t, pat.TokenHash, loaded.TokenHash)
+		assert.Nil(t, loaded.LastUsedAt)
+	})
+
+	invalid := map[string]account.PersonalAccessToken{
+		"empty name":    {IdentityID: fxt.Identities[0].ID, CreatorID: fxt.Identities[0].ID, Scopes: pq.StringArray{account.ScopeReadOnly}},
+		"no scopes":     s.newToken(fxt.Identities[0].ID),
+		"unknown scope": s.newToken(fxt.Identities[0].ID, "foo"),
+	}
+	for name, pat := range invalid {
+		s.T().Run(name, func(t *testing.T) {
+			_, err := s.repo.Create(s.Ctx, &pat)
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+	}
+}
+
+func (s *personalAccessTokenBlackBoxTest) TestAuthenticate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1))
+	create := func(t *testing.T, pat account.PersonalAccessToken) (account.PersonalAccessToken, string) {
+		token, err := s.repo.Create(s.Ctx, &pat)
+		require.NoError(t, err)
+		return pat, token
+	}
+
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		pat, token := create(t, s.newToken(fxt.Identities[0].ID, account.ScopeWorkItemWrite))
+		// when
+		authenticated, err := s.repo.Authenticate(s.Ctx, token)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, pat.ID, authenticated.ID)
+		assert.Equal(t, fxt.Identities[0].ID, authenticated.IdentityID)
+		loaded, err := s.repo.Load(s.Ctx, pat.ID)
+		require.NoError(t, err)
+		require.NotNil(t, loaded.LastUsedAt, "the use of the token must be recorded")
+	})
+
+	s.T().Run("unknown", func(t *testing.T) {
+		_, err := s.repo.Authenticate(s.Ctx, account.PersonalAccessTokenPrefix+"foo")
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+	})
+
+	s.T().Run("revoked", func(t *testing.T) {
+		// given
+		pat, token := create(t, s.newToken(fxt.Identities[0].ID, account.ScopeReadOnly))
+		require.NoError(t, s.repo.Revoke(s.Ctx, pat.ID))
+		// when
+		_, err := s.repo.Authenticate(s.Ctx, token)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+		loaded, err := s.repo.Load(s.Ctx, pat.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, loaded.RevokedAt)
+	})
+
+	s.T().Run("expired", func(t *testing.T) {
+		// given
+		expired := s.newToken(fxt.Identities[0].ID, account.ScopeReadOnly)
+		expiresAt := time.Now().Add(-time.Hour)
+		expired.ExpiresAt = &expiresAt
+		_, token := create(t, expired)
+		// when
+		_, err := s.repo.Authenticate(s.Ctx, token)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+	})
+}
+
+func (s *personalAccessTokenBlackBoxTest) TestList() {
+	// given
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2))
+	for i := 0; i < 2; i++ {
+		pat := s.newToken(fxt.Identities[0].ID, account.ScopeReadOnly)
+		_, err := s.repo.Create(s.Ctx, &pat)
+		require.NoError(s.T(), err)
+	}
+	// when
+	tokens, err := s.repo.List(s.Ctx, fxt.Identities[0].ID)
+	// then
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), tokens, 2)
+	tokens, err = s.repo.List(s.Ctx, fxt.Identities[1].ID)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), tokens)
+}
+
+func TestContextPersonalAccessTokenScopes(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("personal access token", func(t *testing.T) {
+		pat := account.PersonalAccessToken{
+			ID:         uuid.NewV4(),
+			IdentityID: uuid.NewV4(),
+			Scopes:     pq.StringArray{account.ScopeReadOnly, account.ScopeWorkItemWrite},
+		}
+		scopes, ok := account.ContextPersonalAccessTokenScopes(goajwt.WithJWT(context.Background(), pat.JWT()))
+		require.True(t, ok)
+		assert.Equal(t, []string{account.ScopeReadOnly, account.ScopeWorkItemWrite}, scopes)
+	})
+	t.Run("no token", func(t *testing.T) {
+		_, ok := account.ContextPersonalAccessTokenScopes(context.Background())
+		assert.False(t, ok)
+	})
+}
@@ -3,17 +3,26 @@ package application
 import (
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/attachment"
 	"github.com/fabric8-services/fabric8-wit/codebase"
 	"github.com/fabric8-services/fabric8-wit/comment"
 	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
 	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
 	"github.com/fabric8-services/fabric8-wit/query"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/archive"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/importer"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/templatemigration"
+	"github.com/fabric8-services/fabric8-wit/webhook"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/event"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
 )
 
 //An Application stands for a particular implementation of the business logic of our application
@@ -22,12 +31,15 @@ type Application interface {
 	WorkItemTypes() workitem.WorkItemTypeRepository
 	Trackers() remoteworkitem.TrackerRepository
 	TrackerQueries() remoteworkitem.TrackerQueryRepository
+	SyncStatuses() remoteworkitem.SyncStatusRepository
 	SearchItems() SearchRepository
 	Identities() account.IdentityRepository
 	WorkItemLinkCategories() link.WorkItemLinkCategoryRepository
 	WorkItemLinkTypes() link.WorkItemLinkTypeRepository
 	WorkItemLinks() link.WorkItemLinkRepository
 	Comments() comment.Repository
+	CommentRevisions() comment.RevisionRepository
+	CommentReactions() comment.ReactionRepository
 	Spaces() space.Repository
 	Iterations() iteration.Repository
 	Users() account.UserRepository
@@ -39,6 +51,18 @@ type Application interface {
 	SpaceTemplates() spacetemplate.Repository
 	WorkItemTypeGroups() workitem.WorkItemTypeGroupRepository
 	Boards() workitem.BoardRepository
+	Webhooks() webhook.Repository
+	WebhookDeliveries() webhook.DeliveryRepository
+	Reports() report.Repository
+	PersonalAccessTokens() account.PersonalAccessTokenRepository
+	SpaceArchives() archive.Repository
+	SpaceTemplateImporter() importer.Repository
+	SpaceTemplateMigrations() templatemigration.Repository
+	Attachments() attachment.Repository
+	MarkupReferences() reference.Repository
+	Watches() subscription.WatchRepository
+	NotificationPreferences() subscription.PreferenceRepository
+	IterationSchedules() schedule.Repository
 }
 
 // A Transaction abstracts a database transaction. The repositories created for the transaction object make changes inside the the transaction
@@ -10,5 +10,5 @@ import (
 // SearchRepository encapsulates searching of woritems,users,etc
 type SearchRepository interface {
 	SearchFullText(ctx context.Context, searchStr string, start *int, length *int, spaceID *string) ([]workitem.WorkItem, int, error)
-	Filter(ctx context.Context, filterStr string, parentExists *bool, start *int, length *int) ([]workitem.WorkItem, int, link.AncestorList, link.WorkItemLinkList, error)
+	Filter(ctx context.Context, filterStr string, parentExists *bool, start *int, length *int, sort workitem.SortWorkItemsBy) ([]workitem.WorkItem, int, link.AncestorList, link.WorkItemLinkList, error)
 }
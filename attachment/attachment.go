@@ -0,0 +1,215 @@
+// Package attachment manages files which are attached to work items and their
+// comments. The metadata of an attachment is kept in the database while its
+// content goes to a pluggable BlobStore.
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeAttachments helps to avoid string literal
+const APIStringTypeAttachments = "attachments"
+
+// DefaultContentType is used for attachments which are uploaded without a
+// content type
+const DefaultContentType = "application/octet-stream"
+
+// maxFilenameLength is the maximum number of characters of a filename
+const maxFilenameLength = 255
+
+// Attachment holds the metadata of a file which is attached to a work item
+// or to one of its comments. The content itself is kept in a BlobStore under
+// the StorageKey.
+type Attachment struct {
+	gormsupport.Lifecycle
+	ID          uuid.UUID   `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID     uuid.UUID   `sql:"type:uuid"`
+	WorkItemID  uuid.UUID   `sql:"type:uuid"`
+	CommentID   id.NullUUID `sql:"type:uuid"`
+	CreatorID   uuid.UUID   `sql:"type:uuid"`
+	Filename    string
+	ContentType string
+	// Size is the number of bytes of the content
+	Size int64
+	// Checksum is the hex encoded SHA-256 hash of the content
+	Checksum   string
+	StorageKey string
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (m Attachment) GetETagData() []interface{} {
+	return []interface{}{m.ID, m.Checksum}
+}
+
+// GetLastModified returns the last modification time
+func (m Attachment) GetLastModified() time.Time {
+	return m.UpdatedAt.Truncate(time.Second)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Attachment) TableName() string {
+	return "attachments"
+}
+
+// markdownEscaper escapes the characters of a filename which would end the
+// text of a Markdown link
+var markdownEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`)
+
+// Markdown returns the Markdown link to the attachment which can be pasted
+// into a description or a comment. Images are linked rather than embedded
+// because the download requires a token which browsers don't send when
+// loading embedded images.
+func (m Attachment) Markdown(url string) string {
+	return fmt.Sprintf("[%s](%s)", markdownEscaper.Replace(m.Filename), url)
+}
+
+// Validate checks the filename and normalizes the content type of the
+// attachment
+func (m *Attachment) Validate() error {
+	name := strings.TrimSpace(m.Filename)
+	if name == "" || name == "." || name == ".." || len(name) > maxFilenameLength || strings.IndexFunc(name, func(r rune) bool {
+		return r == '/' || r == '\\' || unicode.IsControl(r)
+	}) >= 0 {
+		return errors.NewBadParameterError("filename", m.Filename).Expected(fmt.Sprintf("name of at most %d characters without path separators", maxFilenameLength))
+	}
+	m.Filename = name
+	if m.ContentType == "" {
+		m.ContentType = DefaultContentType
+		return nil
+	}
+	mediaType, params, err := mime.ParseMediaType(m.ContentType)
+	if err != nil {
+		return errors.NewBadParameterError("content type", m.ContentType).Expected("media type")
+	}
+	m.ContentType = mime.FormatMediaType(mediaType, params)
+	return nil
+}
+
+// Repository describes interactions with the metadata of attachments
+type Repository interface {
+	Create(ctx context.Context, a *Attachment) error
+	Load(ctx context.Context, id uuid.UUID) (*Attachment, error)
+	// List returns the attachments of a work item. If a comment is given only
+	// the attachments of that comment are returned.
+	List(ctx context.Context, workItemID uuid.UUID, commentID *uuid.UUID) ([]Attachment, error)
+	// Usage returns the number of bytes used by the attachments of a space.
+	// It locks the space until the end of the transaction so that the quota
+	// of the space can't be exceeded by concurrent uploads.
+	Usage(ctx context.Context, spaceID uuid.UUID) (int64, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for
+// attachments.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create stores the metadata of a new attachment whose content was already
+// put into the blob store
+func (r *GormRepository) Create(ctx context.Context, a *Attachment) error {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "create"}, time.Now())
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	if a.ID == uuid.Nil {
+		a.ID = uuid.NewV4()
+	}
+	if err := r.db.Create(a).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"work_item_id": a.WorkItemID,
+			"err":          err,
+		}, "unable to create the attachment")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Load returns the attachment with the given ID
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Attachment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "show"}, time.Now())
+	a := Attachment{}
+	tx := r.db.Where("id = ?", id).First(&a)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("attachment", id.String())
+	}
+	if tx.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"attachment_id": id,
+			"err":           tx.Error,
+		}, "unable to load the attachment by ID")
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	return &a, nil
+}
+
+// List returns the attachments of a work item or of one of its comments
+func (r *GormRepository) List(ctx context.Context, workItemID uuid.UUID, commentID *uuid.UUID) ([]Attachment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "list"}, time.Now())
+	db := r.db.Where("work_item_id = ?", workItemID)
+	if commentID != nil {
+		db = db.Where("comment_id = ?", *commentID)
+	}
+	var objs []Attachment
+	err := db.Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Usage returns the number of bytes used by the attachments of a space
+func (r *GormRepository) Usage(ctx context.Context, spaceID uuid.UUID) (int64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "usage"}, time.Now())
+	if err := r.db.Exec("SELECT 1 FROM spaces WHERE id = ? FOR UPDATE", spaceID).Error; err != nil {
+		return 0, errors.NewInternalError(ctx, err)
+	}
+	var usage struct{ Total int64 }
+	err := r.db.Model(&Attachment{}).Select("COALESCE(SUM(size), 0) AS total").Where("space_id = ?", spaceID).Scan(&usage).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to sum up the size of the attachments")
+		return 0, errors.NewInternalError(ctx, err)
+	}
+	return usage.Total, nil
+}
+
+// Delete removes the metadata of the attachment with the given ID. The content
+// should be removed from the blob store once the transaction is committed,
+// otherwise the Cleaner removes it later on.
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "attachment", "delete"}, time.Now())
+	tx := r.db.Delete(Attachment{ID: id})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"attachment_id": id,
+			"err":           err,
+		}, "unable to delete the attachment")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("attachment", id.String())
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package attachment_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/attachment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestAttachment_Validate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	t.Run("valid", func(t *testing.T) {
+		a := attachment.Attachment{Filename: " build log.txt ", ContentType: "text/plain; charset=UTF-8"}
+		require.NoError(t, a.Validate())
+		assert.Equal(t, "build log.txt", a.Filename)
+		assert.Equal(t, "text/plain; charset=utf-8", a.ContentType)
+	})
+	t.Run("default content type", func(t *testing.T) {
+		a := attachment.Attachment{Filename: "dump"}
+		require.NoError(t, a.Validate())
+		assert.Equal(t, attachment.DefaultContentType, a.ContentType)
+	})
+	for name, a := range map[string]attachment.Attachment{
+		"empty filename":        {Filename: " "},
+		"path":                  {Filename: "../etc/passwd"},
+		"windows path":          {Filename: `C:\log.txt`},
+		"control character":     {Filename: "log\n.txt"},
+		"too long filename":     {Filename: strings.Repeat("a", 256)},
+		"malformed media type":  {Filename: "log.txt", ContentType: "text/"},
+		"parent directory only": {Filename: ".."},
+	} {
+		a := a
+		t.Run(name, func(t *testing.T) {
+			err := a.Validate()
+			require.Error(t, err)
+			_, ok := errors.IsBadParameterError(err)
+			assert.True(t, ok)
+		})
+	}
+}
+
+func TestAttachment_Markdown(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	url := "https://api.example.com/api/attachments/1/content"
+	assert.Equal(t, `[screen \[1\].png](https://api.example.com/api/attachments/1/content)`,
+		attachment.Attachment{Filename: "screen [1].png", ContentType: "image/png"}.Markdown(url))
+	assert.Equal(t, "[build.log](https://api.example.com/api/attachments/1/content)",
+		attachment.Attachment{Filename: "build.log", ContentType: attachment.DefaultContentType}.Markdown(url))
+}
+
+type attachmentRepositorySuite struct {
+	gormtestsupport.DBTestSuite
+	dir   string
+	store attachment.BlobStore
+}
+
+func TestAttachmentRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &attachmentRepositorySuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *attachmentRepositorySuite) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	dir, err := ioutil.TempDir("", "attachments")
+	require.NoError(s.T(), err)
+	s.dir = dir
+	s.store, err = attachment.NewFileStore(dir)
+	require.NoError(s.T(), err)
+}
+
+func (s *attachmentRepositorySuite) TearDownSuite() {
+	os.RemoveAll(s.dir)
+	s.DBTestSuite.TearDownSuite()
+}
+
+// upload attaches the content to the first work item of the fixture
+func (s *attachmentRepositorySuite) upload(fxt *tf.TestFixture, limits attachment.Limits, filename, content string, commentID *uuid.UUID) (*attachment.Attachment, error) {
+	a := attachment.Attachment{
+		SpaceID:    fxt.WorkItems[0].SpaceID,
+		WorkItemID: fxt.WorkItems[0].ID,
+		CreatorID:  fxt.Identities[0].ID,
+		Filename:   filename,
+	}
+	if commentID != nil {
+		a.CommentID = id.NullUUID{UUID: *commentID, Valid: true}
+	}
+	if err := attachment.PutContent(s.Ctx, s.store, limits, &a, strings.NewReader(content)); err != nil {
+		return nil, err
+	}
+	if err := attachment.CreateWithinQuota(s.Ctx, attachment.NewRepository(s.DB), limits, &a); err != nil {
+		attachment.Remove(s.Ctx, s.store, a)
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (s *attachmentRepositorySuite) TestCreateListAndDelete() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.WorkItems(1), tf.Comments(1))
+	repo := attachment.NewRepository(s.DB)
+	limits := attachment.Limits{MaxSize: 1024, SpaceQuota: 4096}
+	log, err := s.upload(fxt, limits, "build.log", "BUILD FAILED", nil)
+	require.NoError(s.T(), err)
+	screenshot, err := s.upload(fxt, limits, "screenshot.png", "PNG", &fxt.Comments[0].ID)
+	require.NoError(s.T(), err)
+
+	s.T().Run("load", func(t *testing.T) {
+		loaded, err := repo.Load(s.Ctx, log.ID)
+		require.NoError(t, err)
+		sum := sha256.Sum256([]byte("BUILD FAILED"))
+		assert.Equal(t, hex.EncodeToString(sum[:]), loaded.Checksum)
+		assert.Equal(t, int64(len("BUILD FAILED")), loaded.Size)
+		assert.Equal(t, attachment.DefaultContentType, loaded.ContentType)
+		assert.False(t, loaded.CommentID.Valid)
+		content, err := s.store.Get(s.Ctx, loaded.StorageKey)
+		require.NoError(t, err)
+		defer content.Close()
+		b, err := ioutil.ReadAll(content)
+		require.NoError(t, err)
+		assert.Equal(t, "BUILD FAILED", string(b))
+	})
+
+	s.T().Run("list", func(t *testing.T) {
+		all, err := repo.List(s.Ctx, fxt.WorkItems[0].ID, nil)
+		require.NoError(t, err)
+		require.Len(t, all, 2)
+		assert.Equal(t, log.ID, all[0].ID)
+		assert.Equal(t, screenshot.ID, all[1].ID)
+		ofComment, err := repo.List(s.Ctx, fxt.WorkItems[0].ID, &fxt.Comments[0].ID)
+		require.NoError(t, err)
+		require.Len(t, ofComment, 1)
+		assert.Equal(t, screenshot.ID, ofComment[0].ID)
+	})
+
+	s.T().Run("usage and delete", func(t *testing.T) {
+		usage, err := repo.Usage(s.Ctx, fxt.WorkItems[0].SpaceID)
+		require.NoError(t, err)
+		assert.Equal(t, log.Size+screenshot.Size, usage)
+		// when
+		require.NoError(t, repo.Delete(s.Ctx, log.ID))
+		// then
+		_, err = repo.Load(s.Ctx, log.ID)
+		require.Error(t, err)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+		usage, err = repo.Usage(s.Ctx, fxt.WorkItems[0].SpaceID)
+		require.NoError(t, err)
+		assert.Equal(t, screenshot.Size, usage)
+		err = repo.Delete(s.Ctx, log.ID)
+		require.Error(t, err)
+		ok, _ = errors.IsNotFoundError(err)
+		assert.True(t, ok)
+	})
+}
+
+func (s *attachmentRepositorySuite) TestLimits() {
+	limits := attachment.Limits{MaxSize: 10, SpaceQuota: 15}
+
+	s.T().Run("too large", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		// when
+		_, err := s.upload(fxt, limits, "large.bin", "0123456789A", nil)
+		// then
+		require.Error(t, err)
+		_, ok := errors.IsBadParameterError(err)
+		assert.True(t, ok)
+		files, err := ioutil.ReadDir(filepath.Join(s.dir, fxt.WorkItems[0].SpaceID.String()))
+		require.NoError(t, err)
+		assert.Empty(t, files, "the content must be removed")
+	})
+
+	s.T().Run("quota exceeded", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		_, err := s.upload(fxt, limits, "first.bin", "0123456789", nil)
+		require.NoError(t, err)
+		// when
+		_, err = s.upload(fxt, limits, "second.bin", "0123456789", nil)
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+		attachments, err := attachment.NewRepository(s.DB).List(s.Ctx, fxt.WorkItems[0].ID, nil)
+		require.NoError(t, err)
+		assert.Len(t, attachments, 1)
+		// other spaces have their own quota
+		other := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		_, err = s.upload(other, limits, "second.bin", "0123456789", nil)
+		require.NoError(t, err)
+	})
+}
+
+func (s *attachmentRepositorySuite) TestCleaner() {
+	limits := attachment.Limits{MaxSize: 1024, SpaceQuota: 4096}
+	cleaner := attachment.NewCleaner(s.DB, s.store, nil)
+	// requireRemoved checks that the content of the attachment is gone once
+	// the orphans are removed
+	requireRemoved := func(t *testing.T, a attachment.Attachment) {
+		_, err := cleaner.RemoveOrphans(s.Ctx)
+		require.NoError(t, err)
+		_, err = s.store.Get(s.Ctx, a.StorageKey)
+		require.Error(t, err)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+	}
+
+	s.T().Run("deleted attachment", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		a, err := s.upload(fxt, limits, "build.log", "BUILD FAILED", nil)
+		require.NoError(t, err)
+		require.NoError(t, attachment.NewRepository(s.DB).Delete(s.Ctx, a.ID))
+		requireRemoved(t, *a)
+	})
+	s.T().Run("deleted work item", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		a, err := s.upload(fxt, limits, "build.log", "BUILD FAILED", nil)
+		require.NoError(t, err)
+		require.NoError(t, workitem.NewWorkItemRepository(s.DB).Delete(s.Ctx, fxt.WorkItems[0].ID, fxt.Identities[0].ID))
+		_, err = attachment.NewRepository(s.DB).Load(s.Ctx, a.ID)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+		requireRemoved(t, *a)
+	})
+	s.T().Run("deleted space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		a, err := s.upload(fxt, limits, "build.log", "BUILD FAILED", nil)
+		require.NoError(t, err)
+		require.NoError(t, space.NewRepository(s.DB).Delete(s.Ctx, fxt.Spaces[0].ID))
+		requireRemoved(t, *a)
+	})
+	s.T().Run("removed along with its work item", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		a, err := s.upload(fxt, limits, "build.log", "BUILD FAILED", nil)
+		require.NoError(t, err)
+		require.NoError(t, s.DB.Exec("DELETE FROM work_items WHERE id = ?", fxt.WorkItems[0].ID).Error)
+		requireRemoved(t, *a)
+	})
+}
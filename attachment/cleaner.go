@@ -0,0 +1,101 @@
+package attachment
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/jinzhu/gorm"
+)
+
+// cleanupBatchSize is the maximum number of orphaned contents removed per tick
+const cleanupBatchSize = 100
+
+// CleanerConfiguration holds the settings of the Cleaner
+type CleanerConfiguration interface {
+	GetAttachmentsCleanupInterval() time.Duration
+}
+
+// Cleaner periodically removes the content of deleted attachments from the
+// blob store. The database records the storage keys of attachments which are
+// deleted, including those deleted along with their work item, comment or
+// space, in the attachment_orphans table.
+type Cleaner struct {
+	db     *gorm.DB
+	store  BlobStore
+	config CleanerConfiguration
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewCleaner creates a new Cleaner
+func NewCleaner(db *gorm.DB, store BlobStore, config CleanerConfiguration) *Cleaner {
+	return &Cleaner{
+		db:     db,
+		store:  store,
+		config: config,
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start runs the cleanup loop in the background until Stop is called
+func (c *Cleaner) Start(ctx context.Context) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.config.GetAttachmentsCleanupInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+				if _, err := c.RemoveOrphans(ctx); err != nil {
+					log.Error(ctx, map[string]interface{}{
+						"err": err,
+					}, "failed to remove the content of deleted attachments")
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the cleanup loop and waits for the current batch to finish.
+// This should be called only from main
+func (c *Cleaner) Stop() {
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// RemoveOrphans removes one batch of orphaned contents from the blob store and
+// returns how many of them were removed. A key is only forgotten once its
+// content is gone, so contents which fail to be removed are retried with the
+// next batch.
+func (c *Cleaner) RemoveOrphans(ctx context.Context) (int, error) {
+	var keys []string
+	err := c.db.Table("attachment_orphans").Order("created_at").Limit(cleanupBatchSize).Pluck("storage_key", &keys).Error
+	if err != nil {
+		return 0, errors.NewInternalError(ctx, err)
+	}
+	removed := 0
+	var firstErr error
+	for _, key := range keys {
+		if err := c.store.Delete(ctx, key); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"key": key,
+				"err": err,
+			}, "unable to delete the content of a deleted attachment")
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if err := c.db.Exec("DELETE FROM attachment_orphans WHERE storage_key = ?", key).Error; err != nil {
+			return removed, errors.NewInternalError(ctx, err)
+		}
+		removed++
+	}
+	return removed, firstErr
+}
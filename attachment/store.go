@@ -0,0 +1,115 @@
+package attachment
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	errs "github.com/pkg/errors"
+)
+
+// BlobStore keeps the content of attachments. Keys are slash separated paths
+// made up of UUIDs which the store may map to its own naming scheme.
+type BlobStore interface {
+	// Put stores the content under the given key, replacing any previous
+	// content. No content is kept if an error is returned.
+	Put(ctx context.Context, key string, content io.Reader) error
+	// Get returns the content stored under the given key. The caller has to
+	// close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under the given key. Deleting a key
+	// which doesn't exist is not an error.
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore is a BlobStore which keeps the content of attachments as files
+// below a root directory
+type FileStore struct {
+	root string
+}
+
+// Ensure FileStore implements the BlobStore interface
+var _ BlobStore = &FileStore{}
+
+// NewFileStore creates a blob store in the given directory. The directory is
+// created if it doesn't exist.
+func NewFileStore(root string) (*FileStore, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to resolve the attachment directory %s", root)
+	}
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, errs.Wrapf(err, "failed to create the attachment directory %s", root)
+	}
+	return &FileStore{root: root}, nil
+}
+
+// path returns the file in which the content of the given key is kept. Keys
+// which would lead out of the root directory are rejected.
+func (s *FileStore) path(key string) (string, error) {
+	p := filepath.Join(s.root, filepath.FromSlash(key))
+	if key == "" || !strings.HasPrefix(p, s.root+string(filepath.Separator)) {
+		return "", errors.NewBadParameterError("key", key).Expected("relative path")
+	}
+	return p, nil
+}
+
+// Put implements BlobStore. The content is written to a temporary file first
+// so that a failed upload never leaves partial content under the key.
+func (s *FileStore) Put(ctx context.Context, key string, content io.Reader) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+		return errs.Wrapf(err, "failed to create the directory of blob %s", key)
+	}
+	f, err := ioutil.TempFile(filepath.Dir(p), ".upload-")
+	if err != nil {
+		return errs.Wrapf(err, "failed to create a temporary file for blob %s", key)
+	}
+	_, err = io.Copy(f, content)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), p)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return errs.Wrapf(err, "failed to write blob %s", key)
+	}
+	return nil
+}
+
+// Get implements BlobStore
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	p, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(p)
+	if os.IsNotExist(err) {
+		return nil, errors.NewNotFoundError("blob", key)
+	}
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to open blob %s", key)
+	}
+	return f, nil
+}
+
+// Delete implements BlobStore
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	p, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return errs.Wrapf(err, "failed to delete blob %s", key)
+	}
+	return nil
+}
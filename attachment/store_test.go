@@ -0,0 +1,83 @@
+package attachment_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/attachment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingReader returns some content and then fails like an aborted upload
+type failingReader struct {
+	done bool
+}
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.done {
+		return 0, errs.New("connection reset")
+	}
+	r.done = true
+	return copy(p, "partial"), nil
+}
+
+func TestFileStore(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	dir, err := ioutil.TempDir("", "attachments")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := attachment.NewFileStore(filepath.Join(dir, "store"))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	t.Run("put, get and delete", func(t *testing.T) {
+		// when
+		require.NoError(t, store.Put(ctx, "space/file", strings.NewReader("hello")))
+		// then
+		content, err := store.Get(ctx, "space/file")
+		require.NoError(t, err)
+		b, err := ioutil.ReadAll(content)
+		require.NoError(t, content.Close())
+		require.NoError(t, err)
+		assert.Equal(t, "hello", string(b))
+		// when
+		require.NoError(t, store.Delete(ctx, "space/file"))
+		// then
+		_, err = store.Get(ctx, "space/file")
+		require.Error(t, err)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+		require.NoError(t, store.Delete(ctx, "space/file"), "deleting twice must not fail")
+	})
+
+	t.Run("failed put keeps nothing", func(t *testing.T) {
+		// when
+		err := store.Put(ctx, "space/broken", &failingReader{})
+		// then
+		require.Error(t, err)
+		_, err = store.Get(ctx, "space/broken")
+		require.Error(t, err)
+		files, err := ioutil.ReadDir(filepath.Join(dir, "store", "space"))
+		require.NoError(t, err)
+		assert.Empty(t, files, "temporary files must be removed")
+	})
+
+	t.Run("keys outside the store", func(t *testing.T) {
+		for _, key := range []string{"", "../escaped", "space/../../escaped"} {
+			err := store.Put(ctx, key, strings.NewReader("hello"))
+			require.Error(t, err, "key %q", key)
+			_, ok := errors.IsBadParameterError(err)
+			assert.True(t, ok, "key %q", key)
+		}
+		_, err := os.Stat(filepath.Join(dir, "escaped"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -0,0 +1,101 @@
+package attachment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Limits restricts the size of attachments
+type Limits struct {
+	// MaxSize is the maximum number of bytes of a single attachment
+	MaxSize int64
+	// SpaceQuota is the maximum number of bytes of all attachments of a space
+	SpaceQuota int64
+}
+
+// StorageKey returns the key under which the content of an attachment is kept
+// in the blob store
+func StorageKey(spaceID, attachmentID uuid.UUID) string {
+	return fmt.Sprintf("%s/%s", spaceID, attachmentID)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// PutContent validates a new attachment and puts its content into the blob
+// store. It sets the ID, storage key, size and checksum of the attachment.
+// Content which is larger than allowed is removed again.
+//
+// The content is put outside of any transaction so that the space isn't
+// locked while it is uploaded. Use CreateWithinQuota afterwards to store the
+// metadata and Remove if that fails.
+func PutContent(ctx context.Context, store BlobStore, limits Limits, a *Attachment, content io.Reader) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+	a.ID = uuid.NewV4()
+	a.StorageKey = StorageKey(a.SpaceID, a.ID)
+	hash := sha256.New()
+	// read one byte more than allowed to tell if the content is too large
+	counter := &countingReader{r: io.LimitReader(content, limits.MaxSize+1)}
+	if err := store.Put(ctx, a.StorageKey, io.TeeReader(counter, hash)); err != nil {
+		return errs.Wrapf(err, "failed to store the content of attachment %s", a.Filename)
+	}
+	if counter.n > limits.MaxSize {
+		Remove(ctx, store, *a)
+		return errors.NewBadParameterError("size", fmt.Sprintf("more than %d bytes", limits.MaxSize)).Expected(fmt.Sprintf("at most %d bytes", limits.MaxSize))
+	}
+	a.Size = counter.n
+	a.Checksum = hex.EncodeToString(hash.Sum(nil))
+	return nil
+}
+
+// CreateWithinQuota stores the metadata of an attachment whose content was put
+// into the blob store unless the attachments of the space would exceed its
+// quota. The repository must belong to a transaction because the space stays
+// locked until it ends.
+func CreateWithinQuota(ctx context.Context, repo Repository, limits Limits, a *Attachment) error {
+	used, err := repo.Usage(ctx, a.SpaceID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to get the attachment usage of space %s", a.SpaceID)
+	}
+	if used+a.Size > limits.SpaceQuota {
+		log.Warn(ctx, map[string]interface{}{
+			"space_id": a.SpaceID,
+			"used":     used,
+			"size":     a.Size,
+			"quota":    limits.SpaceQuota,
+		}, "attachment quota of the space exceeded")
+		return errors.NewForbiddenError(fmt.Sprintf("the attachments of the space would exceed its quota of %d bytes", limits.SpaceQuota))
+	}
+	return repo.Create(ctx, a)
+}
+
+// Remove deletes the content of an attachment from the blob store. A failure
+// is only logged because content without metadata can't be reached anyway.
+func Remove(ctx context.Context, store BlobStore, a Attachment) {
+	if err := store.Delete(ctx, a.StorageKey); err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"attachment_id": a.ID,
+			"key":           a.StorageKey,
+			"err":           err,
+		}, "unable to delete the content of the attachment")
+	}
+}
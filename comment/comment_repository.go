@@ -24,6 +24,12 @@ type Repository interface {
 	Save(ctx context.Context, comment *Comment, modifier uuid.UUID) error
 	Delete(ctx context.Context, commentID uuid.UUID, suppressor uuid.UUID) error
 	List(ctx context.Context, parent uuid.UUID, start *int, limit *int) ([]Comment, uint64, error)
+	// ListThreads lists the comments of the parent which aren't replies to
+	// other comments
+	ListThreads(ctx context.Context, parent uuid.UUID, start *int, limit *int) ([]Comment, uint64, error)
+	// ListReplies lists all replies to the given comments and to their
+	// replies, oldest first
+	ListReplies(ctx context.Context, commentIDs ...uuid.UUID) ([]Comment, error)
 	Load(ctx context.Context, id uuid.UUID) (*Comment, error)
 	Count(ctx context.Context, parentID uuid.UUID) (int, error)
 }
@@ -133,8 +139,19 @@ func (m *GormCommentRepository) Delete(ctx context.Context, commentID uuid.UUID,
 // List all comments related to a single item
 func (m *GormCommentRepository) List(ctx context.Context, parentID uuid.UUID, start *int, limit *int) ([]Comment, uint64, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
+	return m.list(ctx, m.db.Model(&Comment{}).Where("parent_id = ?", parentID), start, limit)
+}
 
-	db := m.db.Model(&Comment{}).Where("parent_id = ?", parentID)
+// ListThreads lists the comments of the parent which aren't replies to other
+// comments
+func (m *GormCommentRepository) ListThreads(ctx context.Context, parentID uuid.UUID, start *int, limit *int) ([]Comment, uint64, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
+	return m.list(ctx, m.db.Model(&Comment{}).Where("parent_id = ? AND parent_comment_id IS NULL", parentID), start, limit)
+}
+
+// list returns a page of the comments selected by the given query along with
+// their total count
+func (m *GormCommentRepository) list(ctx context.Context, db *gorm.DB, start *int, limit *int) ([]Comment, uint64, error) {
 	orgDB := db
 	if start != nil {
 		if *start < 0 {
@@ -199,6 +216,31 @@ func (m *GormCommentRepository) List(ctx context.Context, parentID uuid.UUID, st
 	return result, count, nil
 }
 
+// ListReplies lists all replies to the given comments and to their replies,
+// oldest first
+func (m *GormCommentRepository) ListReplies(ctx context.Context, commentIDs ...uuid.UUID) ([]Comment, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment", "replies"}, time.Now())
+	result := []Comment{}
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	// replies to deleted comments are left out along with them
+	query := `WITH RECURSIVE replies AS (
+			SELECT * FROM comments WHERE parent_comment_id IN (?) AND deleted_at IS NULL
+			UNION ALL
+			SELECT c.* FROM comments c JOIN replies r ON c.parent_comment_id = r.id WHERE c.deleted_at IS NULL
+		)
+		SELECT * FROM replies ORDER BY created_at ASC`
+	if err := m.db.Raw(query, commentIDs).Scan(&result).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"comment_ids": commentIDs,
+			"err":         err,
+		}, "unable to list the replies to the comments")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the replies to the comments"))
+	}
+	return result, nil
+}
+
 // Count all comments related to a single item
 func (m *GormCommentRepository) Count(ctx context.Context, parentID uuid.UUID) (int, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "comment", "query"}, time.Now())
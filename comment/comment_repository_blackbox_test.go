@@ -258,3 +258,42 @@ func (s *TestCommentRepository) TestExistsComment() {
 		require.IsType(t, errors.NotFoundError{}, err)
 	})
 }
+
+func (s *TestCommentRepository) TestListThreadsAndReplies() {
+	// given comments 1 and 2 in a thread below comment 0 and comment 3 in its
+	// own thread
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Comments(4, func(fxt *tf.TestFixture, idx int) error {
+		if idx == 1 || idx == 2 {
+			fxt.Comments[idx].ParentCommentID = id.NullUUID{UUID: fxt.Comments[idx-1].ID, Valid: true}
+		}
+		return nil
+	}))
+	s.T().Run("threads", func(t *testing.T) {
+		// when
+		threads, count, err := s.repo.ListThreads(s.Ctx, fxt.WorkItems[0].ID, nil, nil)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), count)
+		require.Len(t, threads, 2)
+		assert.Equal(t, fxt.Comments[3].ID, threads[0].ID)
+		assert.Equal(t, fxt.Comments[0].ID, threads[1].ID)
+	})
+	s.T().Run("replies", func(t *testing.T) {
+		// when
+		replies, err := s.repo.ListReplies(s.Ctx, fxt.Comments[0].ID, fxt.Comments[3].ID)
+		// then
+		require.NoError(t, err)
+		require.Len(t, replies, 2)
+		assert.Equal(t, fxt.Comments[1].ID, replies[0].ID)
+		assert.Equal(t, fxt.Comments[2].ID, replies[1].ID)
+	})
+	s.T().Run("replies to a deleted comment", func(t *testing.T) {
+		// given
+		require.NoError(t, s.repo.Delete(s.Ctx, fxt.Comments[1].ID, fxt.Identities[0].ID))
+		// when
+		replies, err := s.repo.ListReplies(s.Ctx, fxt.Comments[0].ID)
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, replies)
+	})
+}
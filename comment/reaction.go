@@ -0,0 +1,104 @@
+package comment
+
+import (
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	reactionTableName = "comment_reactions"
+	// maxEmojiLength the maximum number of runes of a reaction. An emoji may
+	// consist of several code points, e.g. with a skin tone modifier.
+	maxEmojiLength = 16
+)
+
+// Reaction is the emoji with which an identity reacted to a comment
+type Reaction struct {
+	CreatedAt  time.Time
+	CommentID  uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	Emoji      string    `gorm:"primary_key"`
+}
+
+// TableName implements gorm.tabler
+func (r Reaction) TableName() string {
+	return reactionTableName
+}
+
+// reactionShortNames are the short names which can be used instead of an emoji
+var reactionShortNames = map[string]struct{}{
+	"+1":       {},
+	"-1":       {},
+	"laugh":    {},
+	"confused": {},
+	"heart":    {},
+	"hooray":   {},
+	"rocket":   {},
+	"eyes":     {},
+}
+
+// emojiRunes holds the code points of pictographs and symbols which are shown
+// as emojis
+var emojiRunes = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x00a9, Hi: 0x00ae, Stride: 5},
+		{Lo: 0x203c, Hi: 0x203c, Stride: 1},
+		{Lo: 0x2049, Hi: 0x2049, Stride: 1},
+		{Lo: 0x2122, Hi: 0x2122, Stride: 1},
+		{Lo: 0x2139, Hi: 0x2139, Stride: 1},
+		{Lo: 0x2194, Hi: 0x21aa, Stride: 1},
+		{Lo: 0x231a, Hi: 0x23ff, Stride: 1},
+		{Lo: 0x24c2, Hi: 0x24c2, Stride: 1},
+		{Lo: 0x25aa, Hi: 0x25fe, Stride: 1},
+		{Lo: 0x2600, Hi: 0x27bf, Stride: 1},
+		{Lo: 0x2934, Hi: 0x2935, Stride: 1},
+		{Lo: 0x2b05, Hi: 0x2b55, Stride: 1},
+		{Lo: 0x3030, Hi: 0x3030, Stride: 1},
+		{Lo: 0x303d, Hi: 0x303d, Stride: 1},
+		{Lo: 0x3297, Hi: 0x3299, Stride: 2},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0x1f000, Hi: 0x1faff, Stride: 1},
+	},
+	LatinOffset: 1,
+}
+
+// emojiModifiers holds the code points which join emojis or change the way
+// they are shown, i.e. the zero width joiner, the keycap, the emoji
+// presentation selector and the tags of subdivision flags. Skin tones and
+// regional indicators are part of the emojiRunes.
+var emojiModifiers = &unicode.RangeTable{
+	R16: []unicode.Range16{
+		{Lo: 0x200d, Hi: 0x200d, Stride: 1},
+		{Lo: 0x20e3, Hi: 0x20e3, Stride: 1},
+		{Lo: 0xfe0f, Hi: 0xfe0f, Stride: 1},
+	},
+	R32: []unicode.Range32{
+		{Lo: 0xe0020, Hi: 0xe007f, Stride: 1},
+	},
+}
+
+// ValidateEmoji returns a BadParameterError unless the given emoji can be used
+// as a reaction. Either a single emoji, which may be made up of several code
+// points, or one of the short names like "+1" is accepted.
+func ValidateEmoji(emoji string) error {
+	if _, ok := reactionShortNames[emoji]; ok {
+		return nil
+	}
+	invalid := errors.NewBadParameterError("emoji", emoji).Expected("an emoji or one of the short names +1, -1, laugh, confused, heart, hooray, rocket and eyes")
+	if emoji == "" || !utf8.ValidString(emoji) || utf8.RuneCountInString(emoji) > maxEmojiLength {
+		return invalid
+	}
+	for i, r := range emoji {
+		// an emoji starts with a pictograph which may be followed by modifiers
+		// and further pictographs
+		if !unicode.Is(emojiRunes, r) && (i == 0 || !unicode.Is(emojiModifiers, r)) {
+			return invalid
+		}
+	}
+	return nil
+}
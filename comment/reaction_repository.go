@@ -0,0 +1,118 @@
+package comment
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/closeable"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ReactionRepository encapsulates storage & retrieval of the reactions to
+// comments
+type ReactionRepository interface {
+	// Add stores the reaction unless the identity already reacted with the
+	// same emoji to the comment. It returns true if the reaction was added.
+	Add(ctx context.Context, r *Reaction) (bool, error)
+	// Remove deletes the reaction of the identity with the emoji from the
+	// comment
+	Remove(ctx context.Context, commentID, identityID uuid.UUID, emoji string) error
+	// List returns the reactions to a comment, oldest first
+	List(ctx context.Context, commentID uuid.UUID) ([]Reaction, error)
+	// Count returns the number of reactions per emoji to each of the given
+	// comments which has any
+	Count(ctx context.Context, commentIDs ...uuid.UUID) (map[uuid.UUID]map[string]int, error)
+}
+
+// NewReactionRepository creates a GormReactionRepository
+func NewReactionRepository(db *gorm.DB) *GormReactionRepository {
+	return &GormReactionRepository{db: db}
+}
+
+// GormReactionRepository implements ReactionRepository using gorm
+type GormReactionRepository struct {
+	db *gorm.DB
+}
+
+// Add stores the reaction unless the identity already reacted with the same
+// emoji to the comment. The reaction is updated with the stored one.
+func (r *GormReactionRepository) Add(ctx context.Context, reaction *Reaction) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment_reaction", "add"}, time.Now())
+	if err := ValidateEmoji(reaction.Emoji); err != nil {
+		return false, err
+	}
+	db := r.db.Exec(`INSERT INTO `+reactionTableName+` (created_at, comment_id, identity_id, emoji)
+		VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		time.Now(), reaction.CommentID, reaction.IdentityID, reaction.Emoji)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"comment_id":  reaction.CommentID,
+			"identity_id": reaction.IdentityID,
+			"err":         db.Error,
+		}, "unable to add the reaction to the comment")
+		return false, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to add the reaction"))
+	}
+	added := db.RowsAffected > 0
+	// an existing reaction keeps the time when it was first added
+	db = r.db.Where("comment_id = ? AND identity_id = ? AND emoji = ?", reaction.CommentID, reaction.IdentityID, reaction.Emoji).First(reaction)
+	if db.Error != nil {
+		return false, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to load the reaction"))
+	}
+	return added, nil
+}
+
+// Remove deletes the reaction of the identity with the emoji from the comment
+func (r *GormReactionRepository) Remove(ctx context.Context, commentID, identityID uuid.UUID, emoji string) error {
+	defer goa.MeasureSince([]string{"goa", "db", "comment_reaction", "remove"}, time.Now())
+	db := r.db.Where("comment_id = ? AND identity_id = ? AND emoji = ?", commentID, identityID, emoji).Delete(&Reaction{})
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to remove the reaction"))
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("reaction", emoji)
+	}
+	return nil
+}
+
+// List returns the reactions to a comment, oldest first
+func (r *GormReactionRepository) List(ctx context.Context, commentID uuid.UUID) ([]Reaction, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment_reaction", "list"}, time.Now())
+	var reactions []Reaction
+	if err := r.db.Where("comment_id = ?", commentID).Order("created_at asc, emoji asc").Find(&reactions).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the reactions to the comment"))
+	}
+	return reactions, nil
+}
+
+// Count returns the number of reactions per emoji to each of the given
+// comments which has any
+func (r *GormReactionRepository) Count(ctx context.Context, commentIDs ...uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "comment_reaction", "count"}, time.Now())
+	result := map[uuid.UUID]map[string]int{}
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	rows, err := r.db.Model(&Reaction{}).Select("comment_id, emoji, count(*)").Where("comment_id IN (?)", commentIDs).Group("comment_id, emoji").Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to count the reactions to the comments"))
+	}
+	defer closeable.Close(ctx, rows)
+	for rows.Next() {
+		var commentID uuid.UUID
+		var emoji string
+		var count int
+		if err := rows.Scan(&commentID, &emoji, &count); err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to count the reactions to the comments"))
+		}
+		if result[commentID] == nil {
+			result[commentID] = map[string]int{}
+		}
+		result[commentID][emoji] = count
+	}
+	return result, nil
+}
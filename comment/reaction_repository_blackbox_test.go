@@ -0,0 +1,96 @@
+package comment_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestValidateEmoji(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	for _, emoji := range []string{"+1", "-1", "heart", "rocket", "🎉", "👍🏽", "❤️", "👨‍👩‍👧", "🇩🇪"} {
+		assert.NoError(t, comment.ValidateEmoji(emoji), "emoji %q", emoji)
+	}
+	for _, emoji := range []string{"", "thumbs up", "a/b", "\x00", "\xff", "abcdefghijklmnopq", "foo", "<b>", "a", "\u200d", "\ufe0f", "🎉x", "é"} {
+		err := comment.ValidateEmoji(emoji)
+		require.Error(t, err, "emoji %q", emoji)
+		_, ok := errors.IsBadParameterError(err)
+		assert.True(t, ok, "emoji %q", emoji)
+	}
+}
+
+type reactionRepositorySuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestReactionRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &reactionRepositorySuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *reactionRepositorySuite) TestAddListCountAndRemove() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.Comments(2))
+	repo := comment.NewReactionRepository(s.DB)
+	react := func(t *testing.T, commentIdx, identityIdx int, emoji string) (comment.Reaction, bool) {
+		r := comment.Reaction{
+			CommentID:  fxt.Comments[commentIdx].ID,
+			IdentityID: fxt.Identities[identityIdx].ID,
+			Emoji:      emoji,
+		}
+		added, err := repo.Add(s.Ctx, &r)
+		require.NoError(t, err)
+		return r, added
+	}
+	first, added := react(s.T(), 0, 0, "+1")
+	require.True(s.T(), added)
+	react(s.T(), 0, 1, "+1")
+	react(s.T(), 0, 1, "🎉")
+
+	s.T().Run("same reaction twice", func(t *testing.T) {
+		// when
+		again, added := react(t, 0, 0, "+1")
+		// then
+		assert.False(t, added)
+		assert.True(t, first.CreatedAt.Equal(again.CreatedAt), "the first reaction must be kept")
+		reactions, err := repo.List(s.Ctx, fxt.Comments[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 3)
+	})
+
+	s.T().Run("count", func(t *testing.T) {
+		// when
+		counts, err := repo.Count(s.Ctx, fxt.Comments[0].ID, fxt.Comments[1].ID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"+1": 2, "🎉": 1}, counts[fxt.Comments[0].ID])
+		assert.NotContains(t, counts, fxt.Comments[1].ID)
+	})
+
+	s.T().Run("invalid emoji", func(t *testing.T) {
+		_, err := repo.Add(s.Ctx, &comment.Reaction{CommentID: fxt.Comments[1].ID, IdentityID: fxt.Identities[0].ID, Emoji: "thumbs up"})
+		require.Error(t, err)
+		_, ok := errors.IsBadParameterError(err)
+		assert.True(t, ok)
+	})
+
+	s.T().Run("remove", func(t *testing.T) {
+		// when
+		require.NoError(t, repo.Remove(s.Ctx, fxt.Comments[0].ID, fxt.Identities[1].ID, "🎉"))
+		// then
+		reactions, err := repo.List(s.Ctx, fxt.Comments[0].ID)
+		require.NoError(t, err)
+		assert.Len(t, reactions, 2)
+		err = repo.Remove(s.Ctx, fxt.Comments[0].ID, fxt.Identities[1].ID, "🎉")
+		require.Error(t, err)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+	})
+}
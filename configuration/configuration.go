@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
 	"time"
@@ -55,6 +56,8 @@ const (
 	varAuthURL                      = "auth.url"
 	varAuthorizationEnabled         = "authz.enabled"
 	varGithubAuthToken              = "github.auth.token"
+	varGitlabAuthToken              = "gitlab.auth.token"
+	varJiraAuthToken                = "jira.auth.token"
 	varOpenshiftProxyURL            = "osoproxy.url"
 	varKeycloakSecret               = "keycloak.secret"
 	varKeycloakClientID             = "keycloak.client.id"
@@ -99,19 +102,29 @@ const (
 	varCacheControlQuery            = "cachecontrol.query"
 	varCacheControlComment          = "cachecontrol.comment"
 
-	defaultConfigFile            = "config.yaml"
-	varOpenshiftTenantMasterURL  = "openshift.tenant.masterurl"
-	varCheStarterURL             = "chestarterurl"
-	varValidRedirectURLs         = "redirect.valid"
-	varLogLevel                  = "log.level"
-	varLogJSON                   = "log.json"
-	varTenantServiceURL          = "tenant.serviceurl"
-	varNotificationServiceURL    = "notification.serviceurl"
-	varTogglesServiceURL         = "toggles.serviceurl"
-	varDeploymentsServiceURL     = "deployments.serviceurl"
-	varCodebaseServiceURL        = "codebase.serviceurl"
-	varAnalyticsGeminiServiceURL = "analytics.gemini.serviceurl"
-	varDeploymentsHTTPTimeout    = "deployments.http.timeout"
+	defaultConfigFile             = "config.yaml"
+	varOpenshiftTenantMasterURL   = "openshift.tenant.masterurl"
+	varCheStarterURL              = "chestarterurl"
+	varValidRedirectURLs          = "redirect.valid"
+	varLogLevel                   = "log.level"
+	varLogJSON                    = "log.json"
+	varTenantServiceURL           = "tenant.serviceurl"
+	varNotificationServiceURL     = "notification.serviceurl"
+	varTogglesServiceURL          = "toggles.serviceurl"
+	varDeploymentsServiceURL      = "deployments.serviceurl"
+	varCodebaseServiceURL         = "codebase.serviceurl"
+	varAnalyticsGeminiServiceURL  = "analytics.gemini.serviceurl"
+	varDeploymentsHTTPTimeout     = "deployments.http.timeout"
+	varWebhookDispatchInterval    = "webhook.dispatch.interval"
+	varWebhookMaxAttempts         = "webhook.max.attempts"
+	varWebhookHTTPTimeout         = "webhook.http.timeout"
+	varWebhookAllowPrivate        = "webhook.allow.private.addresses"
+	varAttachmentsStoreDir        = "attachments.store.dir"
+	varAttachmentsMaxSize         = "attachments.max.size"
+	varAttachmentsSpaceQuota      = "attachments.space.quota"
+	varAttachmentsCleanupInterval = "attachments.cleanup.interval"
+	varSpaceArchiveMaxSize        = "space.archive.max.size"
+	varIterationScheduleInterval  = "iteration.schedule.interval"
 )
 
 // Registry encapsulates the Viper configuration registry which stores the
@@ -257,6 +270,23 @@ func (c *Registry) setConfigDefaults() {
 	c.v.SetDefault(varCodebaseServiceURL, defaultCodebaseServiceURL)
 	c.v.SetDefault(varDeploymentsHTTPTimeout, defaultDeploymentsHTTPTimeout)
 	c.v.SetDefault(varAnalyticsGeminiServiceURL, defaultAnalyticsGeminiServiceURL)
+
+	// Webhooks
+	c.v.SetDefault(varWebhookDispatchInterval, defaultWebhookDispatchInterval)
+	c.v.SetDefault(varWebhookMaxAttempts, defaultWebhookMaxAttempts)
+	c.v.SetDefault(varWebhookHTTPTimeout, defaultWebhookHTTPTimeout)
+
+	// Iteration schedules
+	c.v.SetDefault(varIterationScheduleInterval, defaultIterationScheduleInterval)
+
+	// Attachments
+	c.v.SetDefault(varAttachmentsStoreDir, filepath.Join(os.TempDir(), "wit-attachments"))
+	c.v.SetDefault(varAttachmentsMaxSize, defaultAttachmentsMaxSize)
+	c.v.SetDefault(varAttachmentsSpaceQuota, defaultAttachmentsSpaceQuota)
+	c.v.SetDefault(varAttachmentsCleanupInterval, defaultAttachmentsCleanupInterval)
+
+	// Space archives
+	c.v.SetDefault(varSpaceArchiveMaxSize, defaultSpaceArchiveMaxSize)
 }
 
 // GetPostgresHost returns the postgres host as set via default, config file, or environment variable
@@ -628,6 +658,18 @@ func (c *Registry) GetGithubAuthToken() string {
 	return c.v.GetString(varGithubAuthToken)
 }
 
+// GetGitlabAuthToken returns the GitLab private token used to fetch issues
+// from and push changes to GitLab trackers
+func (c *Registry) GetGitlabAuthToken() string {
+	return c.v.GetString(varGitlabAuthToken)
+}
+
+// GetJiraAuthToken returns the Jira token used to push changes to Jira
+// trackers. Fetching from Jira doesn't need a token.
+func (c *Registry) GetJiraAuthToken() string {
+	return c.v.GetString(varJiraAuthToken)
+}
+
 // GetKeycloakSecret returns the keycloak client secret (as set via config file or environment variable)
 // that is used to make authorized Keycloak API Calls.
 func (c *Registry) GetKeycloakSecret() string {
@@ -890,6 +932,70 @@ func (c *Registry) GetDeploymentsHTTPTimeoutSeconds() time.Duration {
 	return time.Duration(timeout) * time.Second
 }
 
+// GetWebhookDispatchInterval returns the interval at which pending webhook
+// deliveries are picked up and sent
+func (c *Registry) GetWebhookDispatchInterval() time.Duration {
+	return c.v.GetDuration(varWebhookDispatchInterval)
+}
+
+// GetWebhookMaxAttempts returns the number of times a webhook delivery is
+// attempted before it is given up
+func (c *Registry) GetWebhookMaxAttempts() int {
+	return c.v.GetInt(varWebhookMaxAttempts)
+}
+
+// GetWebhookHTTPTimeout returns the timeout of a single webhook delivery
+// request
+func (c *Registry) GetWebhookHTTPTimeout() time.Duration {
+	return c.v.GetDuration(varWebhookHTTPTimeout)
+}
+
+// GetWebhookAllowPrivateAddresses returns true if webhooks may point to
+// loopback, link-local and private addresses. This is only allowed in
+// developer mode unless it is set explicitly.
+func (c *Registry) GetWebhookAllowPrivateAddresses() bool {
+	if c.v.IsSet(varWebhookAllowPrivate) {
+		return c.v.GetBool(varWebhookAllowPrivate)
+	}
+	return c.IsPostgresDeveloperModeEnabled()
+}
+
+// GetIterationScheduleInterval returns the interval at which the upcoming
+// iterations of the iteration schedules are generated
+func (c *Registry) GetIterationScheduleInterval() time.Duration {
+	return c.v.GetDuration(varIterationScheduleInterval)
+}
+
+// GetAttachmentsStoreDir returns the directory in which the content of
+// attachments is stored
+func (c *Registry) GetAttachmentsStoreDir() string {
+	return c.v.GetString(varAttachmentsStoreDir)
+}
+
+// GetAttachmentsMaxSize returns the maximum size of a single attachment in
+// bytes
+func (c *Registry) GetAttachmentsMaxSize() int64 {
+	return c.v.GetInt64(varAttachmentsMaxSize)
+}
+
+// GetAttachmentsSpaceQuota returns the maximum size of all attachments of a
+// space in bytes
+func (c *Registry) GetAttachmentsSpaceQuota() int64 {
+	return c.v.GetInt64(varAttachmentsSpaceQuota)
+}
+
+// GetAttachmentsCleanupInterval returns the interval at which the content of
+// deleted attachments is removed from the blob store
+func (c *Registry) GetAttachmentsCleanupInterval() time.Duration {
+	return c.v.GetDuration(varAttachmentsCleanupInterval)
+}
+
+// GetSpaceArchiveMaxSize returns the maximum size of an imported space archive
+// in bytes
+func (c *Registry) GetSpaceArchiveMaxSize() int64 {
+	return c.v.GetInt64(varSpaceArchiveMaxSize)
+}
+
 const (
 	defaultHeaderMaxLength = 5000 // bytes
 
@@ -916,11 +1022,19 @@ const (
 	devModeKeycloakURL   = "https://sso.prod-preview.openshift.io"
 	devModeKeycloakRealm = "fabric8-test"
 
-	defaultOpenshiftTenantMasterURL = "https://tsrv.devshift.net:8443"
-	defaultTogglesServiceURL        = "http://f8toggles-service"
-	defaultCheStarterURL            = "che-server"
-	minimumDeploymentsHTTPTimeout   = 1
-	defaultDeploymentsHTTPTimeout   = 30
+	defaultOpenshiftTenantMasterURL   = "https://tsrv.devshift.net:8443"
+	defaultTogglesServiceURL          = "http://f8toggles-service"
+	defaultCheStarterURL              = "che-server"
+	minimumDeploymentsHTTPTimeout     = 1
+	defaultDeploymentsHTTPTimeout     = 30
+	defaultWebhookDispatchInterval    = 5 * time.Second
+	defaultWebhookMaxAttempts         = 8
+	defaultWebhookHTTPTimeout         = 10 * time.Second
+	defaultAttachmentsMaxSize         = 10 << 20  // 10 MiB
+	defaultAttachmentsSpaceQuota      = 500 << 20 // 500 MiB
+	defaultAttachmentsCleanupInterval = 10 * time.Minute
+	defaultSpaceArchiveMaxSize        = 100 << 20 // 100 MiB
+	defaultIterationScheduleInterval  = time.Hour
 
 	// as of now deployments and codebase service is integrated in wit, but
 	// going forward this will change
@@ -18,6 +18,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/path"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 
 	"github.com/goadesign/goa"
 	uuid "github.com/satori/go.uuid"
@@ -90,7 +91,11 @@ func (c *AreaController) CreateChild(ctx *app.CreateChildAreaContext) error {
 		if err != nil {
 			return err
 		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
+		authorized, err := authorizeSpacePermission(ctx, *currentUser, *s, authz.PermissionEditArea)
+		if err != nil {
+			return errors.NewUnauthorizedError(err.Error())
+		}
+		if !authorized {
 			log.Warn(ctx, map[string]interface{}{
 				"space_id":     s.ID,
 				"space_owner":  s.OwnerID,
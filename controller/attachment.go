@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/attachment"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AttachmentController implements the attachment resource.
+type AttachmentController struct {
+	*goa.Controller
+	db    application.DB
+	store attachment.BlobStore
+}
+
+// NewAttachmentController creates an attachment controller.
+func NewAttachmentController(service *goa.Service, db application.DB, store attachment.BlobStore) *AttachmentController {
+	return &AttachmentController{
+		Controller: service.NewController("AttachmentController"),
+		db:         db,
+		store:      store,
+	}
+}
+
+// loadAuthorizedAttachment loads an attachment for which the current user has
+// the given permission in its space
+func loadAuthorizedAttachment(ctx context.Context, appl application.Application, currentUser, attachmentID uuid.UUID, p authz.Permission) (*attachment.Attachment, error) {
+	a, err := appl.Attachments().Load(ctx, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := authorizeAttachmentPermission(ctx, appl, currentUser, a.SpaceID, p); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// Show runs the show action.
+func (c *AttachmentController) Show(ctx *app.ShowAttachmentContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var a *attachment.Attachment
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		a, err = loadAuthorizedAttachment(ctx, appl, *currentUser, ctx.AttachmentID, authz.PermissionViewAttachments)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.AttachmentSingle{
+		Data: ConvertAttachment(ctx.Request, *a),
+	})
+}
+
+// Download runs the download action.
+func (c *AttachmentController) Download(ctx *app.DownloadAttachmentContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var a *attachment.Attachment
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		a, err = loadAuthorizedAttachment(ctx, appl, *currentUser, ctx.AttachmentID, authz.PermissionViewAttachments)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	content, err := c.store.Get(ctx, a.StorageKey)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "failed to get the content of attachment %s", a.ID))
+	}
+	defer content.Close()
+	// the attachment disposition makes browsers download the content instead
+	// of rendering it when its URL is opened, and the sandbox keeps content
+	// which is rendered anyway from running scripts in the origin of the API
+	disposition := mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})
+	if disposition == "" {
+		disposition = "attachment"
+	}
+	header := ctx.ResponseData.Header()
+	header.Set("Content-Type", a.ContentType)
+	header.Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	header.Set("Content-Disposition", disposition)
+	header.Set("Content-Security-Policy", "sandbox")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", "private")
+	ctx.ResponseData.WriteHeader(http.StatusOK)
+	// the content is streamed as attachments can be large
+	if _, err := io.Copy(ctx.ResponseData, content); err != nil {
+		// the status was already sent, so the error can only be logged
+		log.Error(ctx, map[string]interface{}{
+			"attachment_id": a.ID,
+			"err":           err,
+		}, "unable to send the content of the attachment")
+	}
+	return nil
+}
+
+// Delete runs the delete action.
+func (c *AttachmentController) Delete(ctx *app.DeleteAttachmentContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var a *attachment.Attachment
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		a, err = loadAuthorizedAttachment(ctx, appl, *currentUser, ctx.AttachmentID, authz.PermissionEditWorkItem)
+		if err != nil {
+			return err
+		}
+		return appl.Attachments().Delete(ctx, a.ID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	attachment.Remove(ctx, c.store, *a)
+	return ctx.NoContent()
+}
+
+// ConvertAttachmentSimple converts an attachment into a Generic Relationship
+func ConvertAttachmentSimple(request *http.Request, a attachment.Attachment) *app.GenericData {
+	selfURL := rest.AbsoluteURL(request, app.AttachmentHref(a.ID))
+	return &app.GenericData{
+		Type: ptr.String(attachment.APIStringTypeAttachments),
+		ID:   ptr.String(a.ID.String()),
+		Links: &app.GenericLinks{
+			Self: &selfURL,
+		},
+	}
+}
+
+// ConvertAttachment converts the metadata of an attachment into its JSON API
+// representation
+func ConvertAttachment(request *http.Request, a attachment.Attachment) *app.Attachment {
+	selfURL := rest.AbsoluteURL(request, app.AttachmentHref(a.ID))
+	downloadURL := selfURL + "/content"
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(a.SpaceID.String()))
+	workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(a.WorkItemID.String()))
+	creatorRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, a.CreatorID))
+	res := &app.Attachment{
+		Type: attachment.APIStringTypeAttachments,
+		ID:   &a.ID,
+		Attributes: &app.AttachmentAttributes{
+			Filename:    a.Filename,
+			ContentType: a.ContentType,
+			Size:        int(a.Size),
+			Checksum:    a.Checksum,
+			Markdown:    a.Markdown(downloadURL),
+			CreatedAt:   &a.CreatedAt,
+		},
+		Relationships: &app.AttachmentRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &space.SpaceType,
+					ID:   ptr.String(a.SpaceID.String()),
+				},
+				Links: &app.GenericLinks{
+					Self:    &spaceRelatedURL,
+					Related: &spaceRelatedURL,
+				},
+			},
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeWorkItem),
+					ID:   ptr.String(a.WorkItemID.String()),
+				},
+				Links: &app.GenericLinks{
+					Self:    &workItemRelatedURL,
+					Related: &workItemRelatedURL,
+				},
+			},
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(a.CreatorID.String()),
+				},
+				Links: &app.GenericLinks{
+					Related: &creatorRelatedURL,
+				},
+			},
+		},
+		Links: &app.AttachmentLinks{
+			Self:     &selfURL,
+			Related:  &selfURL,
+			Download: &downloadURL,
+		},
+	}
+	if a.CommentID.Valid {
+		commentRelatedURL := rest.AbsoluteURL(request, app.CommentsHref(a.CommentID.UUID))
+		res.Relationships.Comment = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APIStringTypeComments),
+				ID:   ptr.String(a.CommentID.UUID.String()),
+			},
+			Links: &app.GenericLinks{
+				Related: &commentRelatedURL,
+			},
+		}
+	}
+	return res
+}
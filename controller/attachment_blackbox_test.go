@@ -0,0 +1,194 @@
+package controller_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	"github.com/fabric8-services/fabric8-wit/attachment"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type attachmentControllerSuite struct {
+	gormtestsupport.DBTestSuite
+	dir   string
+	store attachment.BlobStore
+}
+
+func TestAttachmentController(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &attachmentControllerSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *attachmentControllerSuite) SetupSuite() {
+	s.DBTestSuite.SetupSuite()
+	dir, err := ioutil.TempDir("", "attachments")
+	require.NoError(s.T(), err)
+	s.dir = dir
+	s.store, err = attachment.NewFileStore(dir)
+	require.NoError(s.T(), err)
+}
+
+func (s *attachmentControllerSuite) TearDownSuite() {
+	os.RemoveAll(s.dir)
+	s.DBTestSuite.TearDownSuite()
+}
+
+// upload runs the upload action with the given content. The generated test
+// helpers can't be used because they don't send a request body.
+func (s *attachmentControllerSuite) upload(t *testing.T, svc *goa.Service, wiID uuid.UUID, comment *uuid.UUID, filename, contentType, content string) (int, *app.AttachmentSingle) {
+	query := url.Values{}
+	query["filename"] = []string{filename}
+	if comment != nil {
+		query["comment"] = []string{comment.String()}
+	}
+	u := &url.URL{
+		Path:     fmt.Sprintf("/api/workitems/%s/attachments", wiID),
+		RawQuery: query.Encode(),
+	}
+	req, err := http.NewRequest("POST", u.String(), strings.NewReader(content))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", contentType)
+	prms := url.Values{}
+	prms["wiID"] = []string{wiID.String()}
+	for k, v := range query {
+		prms[k] = v
+	}
+	rw := httptest.NewRecorder()
+	goaCtx := goa.NewContext(goa.WithAction(svc.Context, "WorkItemAttachmentsTest"), rw, req, prms)
+	uploadCtx, err := app.NewUploadWorkItemAttachmentsContext(goaCtx, req, svc)
+	require.NoError(t, err)
+	ctrl := NewWorkItemAttachmentsController(svc, s.GormDB, s.store, s.Configuration)
+	require.NoError(t, ctrl.Upload(uploadCtx))
+	if rw.Code != http.StatusCreated {
+		return rw.Code, nil
+	}
+	var created app.AttachmentSingle
+	require.NoError(t, json.NewDecoder(rw.Body).Decode(&created))
+	return rw.Code, &created
+}
+
+func (s *attachmentControllerSuite) TestAttachments() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("Attachment-Service", *fxt.Identities[0])
+		wiID := fxt.WorkItems[0].ID
+		// when
+		status, created := s.upload(t, svc, wiID, nil, "screenshot.png", "image/png", "PNG")
+		// then
+		require.Equal(t, http.StatusCreated, status)
+		require.NotNil(t, created.Data.ID)
+		attachmentID := *created.Data.ID
+		assert.Equal(t, "screenshot.png", created.Data.Attributes.Filename)
+		assert.Equal(t, "image/png", created.Data.Attributes.ContentType)
+		assert.Equal(t, 3, created.Data.Attributes.Size)
+		assert.Equal(t, fmt.Sprintf("[screenshot.png](%s)", *created.Data.Links.Download), created.Data.Attributes.Markdown)
+		assert.Equal(t, wiID.String(), *created.Data.Relationships.Workitem.Data.ID)
+
+		// when
+		_, list := test.ListWorkItemAttachmentsOK(t, svc.Context, svc, NewWorkItemAttachmentsController(svc, s.GormDB, s.store, s.Configuration), wiID, nil)
+		// then
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, attachmentID, *list.Data[0].ID)
+		assert.Equal(t, 1, list.Meta.TotalCount)
+
+		// when
+		_, wi := test.ShowWorkitemOK(t, svc.Context, svc, NewWorkitemController(svc, s.GormDB, s.Configuration), wiID, nil, nil)
+		// then
+		require.NotNil(t, wi.Data.Relationships.Attachments)
+		require.Len(t, wi.Data.Relationships.Attachments.Data, 1)
+		assert.Equal(t, attachmentID.String(), *wi.Data.Relationships.Attachments.Data[0].ID)
+
+		ctrl := NewAttachmentController(svc, s.GormDB, s.store)
+		// when
+		_, shown := test.ShowAttachmentOK(t, svc.Context, svc, ctrl, attachmentID)
+		// then
+		assert.Equal(t, created.Data.Attributes.Checksum, shown.Data.Attributes.Checksum)
+
+		// when
+		rw := test.DownloadAttachmentOK(t, svc.Context, svc, ctrl, attachmentID)
+		// then
+		recorder := rw.(*httptest.ResponseRecorder)
+		assert.Equal(t, "PNG", recorder.Body.String())
+		assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename=screenshot.png`, recorder.Header().Get("Content-Disposition"))
+		assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+
+		// when
+		test.DeleteAttachmentNoContent(t, svc.Context, svc, ctrl, attachmentID)
+		// then
+		test.ShowAttachmentNotFound(t, svc.Context, svc, ctrl, attachmentID)
+		_, err := s.store.Get(svc.Context, attachment.StorageKey(fxt.WorkItems[0].SpaceID, attachmentID))
+		require.Error(t, err)
+	})
+
+	s.T().Run("comment", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2), tf.Comments(1))
+		svc := testsupport.ServiceAsUser("Attachment-Service", *fxt.Identities[0])
+		commentID := fxt.Comments[0].ID
+		// when
+		status, created := s.upload(t, svc, fxt.Comments[0].ParentID, &commentID, "build.log", "text/plain", "BUILD FAILED")
+		// then
+		require.Equal(t, http.StatusCreated, status)
+		require.NotNil(t, created.Data.Relationships.Comment)
+		assert.Equal(t, commentID.String(), *created.Data.Relationships.Comment.Data.ID)
+		_, list := test.ListWorkItemAttachmentsOK(t, svc.Context, svc, NewWorkItemAttachmentsController(svc, s.GormDB, s.store, s.Configuration), fxt.Comments[0].ParentID, &commentID)
+		require.Len(t, list.Data, 1)
+
+		// the comment must belong to the work item
+		other := fxt.WorkItems[0].ID
+		if uuid.Equal(other, fxt.Comments[0].ParentID) {
+			other = fxt.WorkItems[1].ID
+		}
+		status, _ = s.upload(t, svc, other, &commentID, "build.log", "text/plain", "BUILD FAILED")
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	s.T().Run("invalid filename", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("Attachment-Service", *fxt.Identities[0])
+		status, _ := s.upload(t, svc, fxt.WorkItems[0].ID, nil, "../build.log", "text/plain", "BUILD FAILED")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	s.T().Run("viewer", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		owner := testsupport.ServiceAsUser("Attachment-Service", *fxt.Identities[0])
+		_, created := s.upload(t, owner, fxt.WorkItems[0].ID, nil, "build.log", "text/plain", "BUILD FAILED")
+		roles := authz.NewLocalRoleService().Assign(fxt.Spaces[0].ID, fxt.Identities[1].ID, authz.RoleViewer)
+		svc := testsupport.ServiceAsSpaceUser("Attachment-Service", *fxt.Identities[1], roles)
+		ctrl := NewAttachmentController(svc, s.GormDB, s.store)
+		// viewers can download but neither upload nor delete
+		test.DownloadAttachmentOK(t, svc.Context, svc, ctrl, *created.Data.ID)
+		status, _ := s.upload(t, svc, fxt.WorkItems[0].ID, nil, "build.log", "text/plain", "BUILD FAILED")
+		assert.Equal(t, http.StatusForbidden, status)
+		test.DeleteAttachmentForbidden(t, svc.Context, svc, ctrl, *created.Data.ID)
+	})
+
+	s.T().Run("no role", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.WorkItems(1))
+		owner := testsupport.ServiceAsUser("Attachment-Service", *fxt.Identities[0])
+		_, created := s.upload(t, owner, fxt.WorkItems[0].ID, nil, "build.log", "text/plain", "BUILD FAILED")
+		svc := testsupport.ServiceAsSpaceUser("Attachment-Service", *fxt.Identities[1], authz.NewLocalRoleService())
+		test.ListWorkItemAttachmentsForbidden(t, svc.Context, svc, NewWorkItemAttachmentsController(svc, s.GormDB, s.store, s.Configuration), fxt.WorkItems[0].ID, nil)
+		test.DownloadAttachmentForbidden(t, svc.Context, svc, NewAttachmentController(svc, s.GormDB, s.store), *created.Data.ID)
+	})
+}
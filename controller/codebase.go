@@ -19,6 +19,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/ptr"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/goadesign/goa"
@@ -238,7 +239,7 @@ func (c *CodebaseController) verifyCodebaseOwner(ctx context.Context, codebaseID
 	if err != nil {
 		return nil, err
 	}
-	if !uuid.Equal(*currentUser, cbSpace.OwnerID) {
+	if !authz.AuthorizeOwner(ctx, *currentUser, cbSpace.OwnerID, authz.PermissionManageSpace) {
 		log.Warn(ctx, map[string]interface{}{
 			"codebase_id":  codebaseID,
 			"space_id":     cbSpace.ID,
@@ -0,0 +1,142 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeCommentReactions the JSON API type of comment reactions
+const APIStringTypeCommentReactions = "comment-reactions"
+
+// CommentReactionsController implements the comment_reactions resource.
+type CommentReactionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentReactionsController creates a comment_reactions controller.
+func NewCommentReactionsController(service *goa.Service, db application.DB) *CommentReactionsController {
+	return &CommentReactionsController{
+		Controller: service.NewController("CommentReactionsController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *CommentReactionsController) List(ctx *app.ListCommentReactionsContext) error {
+	var reactions []comment.Reaction
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Comments().Load(ctx, ctx.CommentID); err != nil {
+			return err
+		}
+		var err error
+		reactions, err = appl.CommentReactions().List(ctx, ctx.CommentID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.CommentReactionList{
+		Data: []*app.CommentReaction{},
+		Meta: &app.CommentReactionListMeta{
+			TotalCount: len(reactions),
+			Counts:     map[string]int{},
+		},
+	}
+	for _, r := range reactions {
+		res.Data = append(res.Data, ConvertCommentReaction(ctx.Request, r))
+		res.Meta.Counts[r.Emoji]++
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *CommentReactionsController) Create(ctx *app.CreateCommentReactionsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	reaction := comment.Reaction{
+		CommentID:  ctx.CommentID,
+		IdentityID: *currentUser,
+		Emoji:      ctx.Emoji,
+	}
+	var added bool
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Comments().Load(ctx, ctx.CommentID); err != nil {
+			return err
+		}
+		added, err = appl.CommentReactions().Add(ctx, &reaction)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.CommentReactionSingle{
+		Data: ConvertCommentReaction(ctx.Request, reaction),
+	}
+	if !added {
+		return ctx.OK(res)
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.CommentsHref(reaction.CommentID)+"/reactions/"+url.PathEscape(reaction.Emoji)))
+	return ctx.Created(res)
+}
+
+// Delete runs the delete action.
+func (c *CommentReactionsController) Delete(ctx *app.DeleteCommentReactionsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return appl.CommentReactions().Remove(ctx, ctx.CommentID, *currentUser, ctx.Emoji)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertCommentReaction converts a reaction to a comment into its JSON API
+// representation
+func ConvertCommentReaction(request *http.Request, r comment.Reaction) *app.CommentReaction {
+	commentRelatedURL := rest.AbsoluteURL(request, app.CommentsHref(r.CommentID))
+	creatorRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, r.IdentityID))
+	return &app.CommentReaction{
+		Type: APIStringTypeCommentReactions,
+		Attributes: &app.CommentReactionAttributes{
+			Emoji:     r.Emoji,
+			CreatedAt: &r.CreatedAt,
+		},
+		Relationships: &app.CommentReactionRelations{
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(r.IdentityID.String()),
+				},
+				Links: &app.GenericLinks{
+					Related: &creatorRelatedURL,
+				},
+			},
+			Comment: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeComments),
+					ID:   ptr.String(r.CommentID.String()),
+				},
+				Links: &app.GenericLinks{
+					Related: &commentRelatedURL,
+				},
+			},
+		},
+	}
+}
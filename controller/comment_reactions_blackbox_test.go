@@ -0,0 +1,88 @@
+package controller_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type commentReactionsSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestCommentReactions(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &commentReactionsSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *commentReactionsSuite) TestReactions() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.Comments(1))
+	commentID := fxt.Comments[0].ID
+	svc1 := testsupport.ServiceAsUser("CommentReactions-Service", *fxt.Identities[0])
+	ctrl1 := NewCommentReactionsController(svc1, s.GormDB)
+	svc2 := testsupport.ServiceAsUser("CommentReactions-Service", *fxt.Identities[1])
+	ctrl2 := NewCommentReactionsController(svc2, s.GormDB)
+
+	s.T().Run("react", func(t *testing.T) {
+		// when
+		resp, r := test.CreateCommentReactionsCreated(t, svc1.Context, svc1, ctrl1, commentID, "+1")
+		// then
+		assert.Equal(t, "+1", r.Data.Attributes.Emoji)
+		assert.Equal(t, fxt.Identities[0].ID.String(), *r.Data.Relationships.Creator.Data.ID)
+		assert.Equal(t, commentID.String(), *r.Data.Relationships.Comment.Data.ID)
+		assert.True(t, strings.HasSuffix(resp.Header().Get("Location"), "/comments/"+commentID.String()+"/reactions/+1"), resp.Header().Get("Location"))
+		// reacting twice has no effect
+		test.CreateCommentReactionsOK(t, svc1.Context, svc1, ctrl1, commentID, "+1")
+		test.CreateCommentReactionsCreated(t, svc2.Context, svc2, ctrl2, commentID, "+1")
+		test.CreateCommentReactionsCreated(t, svc2.Context, svc2, ctrl2, commentID, "🎉")
+	})
+
+	s.T().Run("list", func(t *testing.T) {
+		// when
+		_, list := test.ListCommentReactionsOK(t, svc1.Context, svc1, ctrl1, commentID)
+		// then
+		require.Len(t, list.Data, 3)
+		assert.Equal(t, 3, list.Meta.TotalCount)
+		assert.Equal(t, map[string]int{"+1": 2, "🎉": 1}, list.Meta.Counts)
+	})
+
+	s.T().Run("counts in thread", func(t *testing.T) {
+		_, thread := test.ThreadCommentRepliesOK(t, svc1.Context, svc1, NewCommentRepliesController(svc1, s.GormDB), commentID)
+		assert.Equal(t, map[string]int{"+1": 2, "🎉": 1}, thread.Data.Attributes.Reactions)
+	})
+
+	s.T().Run("remove", func(t *testing.T) {
+		// when
+		test.DeleteCommentReactionsNoContent(t, svc2.Context, svc2, ctrl2, commentID, "🎉")
+		// then
+		_, list := test.ListCommentReactionsOK(t, svc1.Context, svc1, ctrl1, commentID)
+		assert.Equal(t, map[string]int{"+1": 2}, list.Meta.Counts)
+		test.DeleteCommentReactionsNotFound(t, svc2.Context, svc2, ctrl2, commentID, "🎉")
+	})
+
+	s.T().Run("invalid emoji", func(t *testing.T) {
+		test.CreateCommentReactionsBadRequest(t, svc1.Context, svc1, ctrl1, commentID, "thumbs up")
+		test.CreateCommentReactionsBadRequest(t, svc1.Context, svc1, ctrl1, commentID, "<b>hi</b>")
+	})
+
+	s.T().Run("unknown comment", func(t *testing.T) {
+		test.CreateCommentReactionsNotFound(t, svc1.Context, svc1, ctrl1, uuid.NewV4(), "+1")
+		test.ListCommentReactionsNotFound(t, svc1.Context, svc1, ctrl1, uuid.NewV4())
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("CommentReactions-Service")
+		test.CreateCommentReactionsUnauthorized(t, svc.Context, svc, NewCommentReactionsController(svc, s.GormDB), commentID, "+1")
+	})
+}
@@ -0,0 +1,174 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/reference"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// CommentRepliesController implements the comment_replies resource.
+type CommentRepliesController struct {
+	*goa.Controller
+	db           application.DB
+	notification notification.Channel
+}
+
+// NewCommentRepliesController creates a comment_replies controller.
+func NewCommentRepliesController(service *goa.Service, db application.DB) *CommentRepliesController {
+	return NewNotifyingCommentRepliesController(service, db, &notification.DevNullChannel{})
+}
+
+// NewNotifyingCommentRepliesController creates a comment_replies controller
+// with notification broadcast.
+func NewNotifyingCommentRepliesController(service *goa.Service, db application.DB, notificationChannel notification.Channel) *CommentRepliesController {
+	n := notificationChannel
+	if n == nil {
+		n = &notification.DevNullChannel{}
+	}
+	return &CommentRepliesController{
+		Controller:   service.NewController("CommentRepliesController"),
+		db:           db,
+		notification: n,
+	}
+}
+
+// Create runs the create action.
+func (c *CommentRepliesController) Create(ctx *app.CreateCommentRepliesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var reply comment.Comment
+	var mentions []reference.Reference
+	var includeReferences CommentConvertFunc
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		parent, err := appl.Comments().Load(ctx, ctx.CommentID)
+		if err != nil {
+			return err
+		}
+		reply = comment.Comment{
+			ParentID:        parent.ParentID,
+			ParentCommentID: id.NullUUID{UUID: parent.ID, Valid: true},
+			Body:            ctx.Payload.Data.Attributes.Body,
+			Markup:          rendering.NilSafeGetMarkup(ctx.Payload.Data.Attributes.Markup),
+			Creator:         *currentUser,
+		}
+		if err := appl.Comments().Create(ctx, &reply, *currentUser); err != nil {
+			return err
+		}
+		mentions, err = updateCommentReferences(ctx, appl, reply)
+		if err != nil {
+			return err
+		}
+		includeReferences, err = commentIncludeReferences(ctx, appl, reply)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	c.notification.Send(ctx, notification.NewCommentCreated(reply.ID.String()))
+	notifyMentions(ctx, c.notification, mentions, *currentUser, func(mentionedID uuid.UUID) notification.Message {
+		return notification.NewCommentMention(reply.ID.String(), mentionedID)
+	})
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.CommentsHref(reply.ID)))
+	return ctx.Created(&app.CommentSingle{
+		Data: ConvertComment(ctx.Request, reply, CommentIncludeParentWorkItem(ctx, &reply), includeReferences),
+	})
+}
+
+// Thread runs the thread action.
+func (c *CommentRepliesController) Thread(ctx *app.ThreadCommentRepliesContext) error {
+	var res *app.CommentSingle
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		cmt, err := appl.Comments().Load(ctx, ctx.CommentID)
+		if err != nil {
+			return err
+		}
+		data, included, err := ConvertCommentThreads(ctx, appl, ctx.Request, []comment.Comment{*cmt})
+		if err != nil {
+			return err
+		}
+		res = &app.CommentSingle{
+			Data:     data[0],
+			Included: included,
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(res)
+}
+
+// ConvertCommentThreads converts the given comments and all replies to them
+// and to their replies. The replies are returned separately to be included in
+// the response. Every comment references its direct replies and carries the
+// number of reactions to it.
+func ConvertCommentThreads(ctx context.Context, appl application.Application, request *http.Request, comments []comment.Comment) ([]*app.Comment, []interface{}, error) {
+	ids := make([]uuid.UUID, len(comments))
+	for i, cmt := range comments {
+		ids[i] = cmt.ID
+	}
+	replies, err := appl.Comments().ListReplies(ctx, ids...)
+	if err != nil {
+		return nil, nil, errs.Wrap(err, "failed to list the replies to the comments")
+	}
+	repliesTo := map[uuid.UUID][]uuid.UUID{}
+	for _, reply := range replies {
+		ids = append(ids, reply.ID)
+		repliesTo[reply.ParentCommentID.UUID] = append(repliesTo[reply.ParentCommentID.UUID], reply.ID)
+	}
+	reactions, err := appl.CommentReactions().Count(ctx, ids...)
+	if err != nil {
+		return nil, nil, errs.Wrap(err, "failed to count the reactions to the comments")
+	}
+	threads := append(append([]comment.Comment{}, comments...), replies...)
+	includeReferences, err := commentIncludeReferences(ctx, appl, threads...)
+	if err != nil {
+		return nil, nil, err
+	}
+	includeThread := func(request *http.Request, cmt *comment.Comment, data *app.Comment) {
+		data.Relationships.Replies = &app.RelationGenericList{
+			Data: []*app.GenericData{},
+			Meta: map[string]interface{}{
+				"totalCount": len(repliesTo[cmt.ID]),
+			},
+		}
+		for _, replyID := range repliesTo[cmt.ID] {
+			data.Relationships.Replies.Data = append(data.Relationships.Replies.Data, &app.GenericData{
+				Type: ptr.String(APIStringTypeComments),
+				ID:   ptr.String(replyID.String()),
+			})
+		}
+		data.Attributes.Reactions = map[string]int{}
+		for emoji, count := range reactions[cmt.ID] {
+			data.Attributes.Reactions[emoji] = count
+		}
+	}
+	convert := func(cmt comment.Comment) *app.Comment {
+		return ConvertComment(request, cmt, CommentIncludeParentWorkItem(ctx, &cmt), includeThread, includeReferences)
+	}
+	data := make([]*app.Comment, len(comments))
+	for i, cmt := range comments {
+		data[i] = convert(cmt)
+	}
+	included := []interface{}{}
+	for _, reply := range replies {
+		included = append(included, convert(reply))
+	}
+	return data, included, nil
+}
@@ -0,0 +1,99 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type commentRepliesSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestCommentReplies(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &commentRepliesSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newCreateCommentRepliesPayload(body string) *app.CreateCommentRepliesPayload {
+	return &app.CreateCommentRepliesPayload{
+		Data: &app.CreateComment{
+			Type: APIStringTypeComments,
+			Attributes: &app.CreateCommentAttributes{
+				Body: body,
+			},
+		},
+	}
+}
+
+// replyIDs returns the IDs of the direct replies to the comment
+func replyIDs(t *testing.T, c *app.Comment) []string {
+	require.NotNil(t, c.Relationships.Replies)
+	ids := []string{}
+	for _, r := range c.Relationships.Replies.Data {
+		ids = append(ids, *r.ID)
+	}
+	return ids
+}
+
+func (s *commentRepliesSuite) TestReplies() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.Comments(2))
+	svc := testsupport.ServiceAsUser("CommentReplies-Service", *fxt.Identities[1])
+	ctrl := NewCommentRepliesController(svc, s.GormDB)
+	// comment 0 gets a reply which gets a reply of its own
+	_, reply := test.CreateCommentRepliesCreated(s.T(), svc.Context, svc, ctrl, fxt.Comments[0].ID, newCreateCommentRepliesPayload("first reply"))
+	_, replyToReply := test.CreateCommentRepliesCreated(s.T(), svc.Context, svc, ctrl, *reply.Data.ID, newCreateCommentRepliesPayload("second reply"))
+
+	s.T().Run("create", func(t *testing.T) {
+		assert.Equal(t, fxt.Comments[0].ID.String(), *reply.Data.Relationships.ParentComment.Data.ID)
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), *reply.Data.Relationships.Parent.Data.ID)
+		assert.Equal(t, fxt.Identities[1].ID.String(), *reply.Data.Relationships.Creator.Data.ID)
+		assert.Equal(t, reply.Data.ID.String(), *replyToReply.Data.Relationships.ParentComment.Data.ID)
+	})
+
+	s.T().Run("thread", func(t *testing.T) {
+		// when
+		_, thread := test.ThreadCommentRepliesOK(t, svc.Context, svc, ctrl, fxt.Comments[0].ID)
+		// then
+		assert.Equal(t, fxt.Comments[0].ID, *thread.Data.ID)
+		assert.Equal(t, []string{reply.Data.ID.String()}, replyIDs(t, thread.Data))
+		require.Len(t, thread.Included, 2)
+		included := thread.Included[0].(*app.Comment)
+		assert.Equal(t, *reply.Data.ID, *included.ID)
+		assert.Equal(t, []string{replyToReply.Data.ID.String()}, replyIDs(t, included))
+	})
+
+	s.T().Run("threads of the work item", func(t *testing.T) {
+		// when
+		_, threads := test.ThreadsWorkItemCommentsOK(t, svc.Context, svc, NewWorkItemCommentsController(svc, s.GormDB, s.Configuration), fxt.WorkItems[0].ID, nil, nil)
+		// then
+		assert.Equal(t, 2, threads.Meta.TotalCount)
+		require.Len(t, threads.Data, 2)
+		assert.Equal(t, fxt.Comments[1].ID, *threads.Data[0].ID)
+		assert.Empty(t, replyIDs(t, threads.Data[0]))
+		assert.Equal(t, fxt.Comments[0].ID, *threads.Data[1].ID)
+		assert.Equal(t, []string{reply.Data.ID.String()}, replyIDs(t, threads.Data[1]))
+		assert.Len(t, threads.Included, 2)
+	})
+
+	s.T().Run("unknown comment", func(t *testing.T) {
+		test.CreateCommentRepliesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), newCreateCommentRepliesPayload("reply"))
+		test.ThreadCommentRepliesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("CommentReplies-Service")
+		test.CreateCommentRepliesUnauthorized(t, svc.Context, svc, NewCommentRepliesController(svc, s.GormDB), fxt.Comments[0].ID, newCreateCommentRepliesPayload("reply"))
+	})
+}
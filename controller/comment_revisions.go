@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+)
+
+// APIStringTypeCommentRevisions the JSON API type of comment revisions
+const APIStringTypeCommentRevisions = "comment-revisions"
+
+// commentRevisionTypes maps the revision types to their names in the API
+var commentRevisionTypes = map[comment.RevisionType]string{
+	comment.RevisionTypeCreate: "create",
+	comment.RevisionTypeUpdate: "update",
+	comment.RevisionTypeDelete: "delete",
+}
+
+// CommentRevisionsController implements the comment_revisions resource.
+type CommentRevisionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewCommentRevisionsController creates a comment_revisions controller.
+func NewCommentRevisionsController(service *goa.Service, db application.DB) *CommentRevisionsController {
+	return &CommentRevisionsController{
+		Controller: service.NewController("CommentRevisionsController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *CommentRevisionsController) List(ctx *app.ListCommentRevisionsContext) error {
+	var revisions []comment.Revision
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		// the history of deleted comments isn't shown
+		if _, err := appl.Comments().Load(ctx, ctx.CommentID); err != nil {
+			return err
+		}
+		var err error
+		revisions, err = appl.CommentRevisions().List(ctx, ctx.CommentID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.CommentRevisionList{
+		Data: []*app.CommentRevision{},
+		Meta: &app.CommentListMeta{
+			TotalCount: len(revisions),
+		},
+	}
+	for _, r := range revisions {
+		res.Data = append(res.Data, ConvertCommentRevision(ctx.Request, r))
+	}
+	return ctx.OK(res)
+}
+
+// ConvertCommentRevision converts a revision of a comment into its JSON API
+// representation
+func ConvertCommentRevision(request *http.Request, r comment.Revision) *app.CommentRevision {
+	commentRelatedURL := rest.AbsoluteURL(request, app.CommentsHref(r.CommentID))
+	modifierRelatedURL := rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, r.ModifierIdentity))
+	res := &app.CommentRevision{
+		Type: APIStringTypeCommentRevisions,
+		ID:   &r.ID,
+		Attributes: &app.CommentRevisionAttributes{
+			RevisionType: commentRevisionTypes[r.Type],
+			Time:         r.Time,
+			Body:         r.CommentBody,
+		},
+		Relationships: &app.CommentRevisionRelations{
+			Modifier: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(r.ModifierIdentity.String()),
+				},
+				Links: &app.GenericLinks{
+					Related: &modifierRelatedURL,
+				},
+			},
+			Comment: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeComments),
+					ID:   ptr.String(r.CommentID.String()),
+				},
+				Links: &app.GenericLinks{
+					Related: &commentRelatedURL,
+				},
+			},
+		},
+	}
+	// the body and markup are not kept when a comment is deleted
+	if r.CommentBody != nil {
+		res.Attributes.Markup = ptr.String(rendering.NilSafeGetMarkup(r.CommentMarkup))
+		res.Attributes.BodyRendered = ptr.String(rendering.RenderMarkupToHTML(html.EscapeString(*r.CommentBody), *res.Attributes.Markup))
+	}
+	return res
+}
@@ -0,0 +1,60 @@
+package controller_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type commentRevisionsSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestCommentRevisions(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &commentRevisionsSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *commentRevisionsSuite) TestList() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.Comments(1))
+	cmt := *fxt.Comments[0]
+	cmt.Body = "**edited**"
+	cmt.Markup = rendering.SystemMarkupMarkdown
+	require.NoError(s.T(), s.GormDB.Comments().Save(context.Background(), &cmt, fxt.Identities[1].ID))
+	svc := goa.New("CommentRevisions-Service")
+	ctrl := NewCommentRevisionsController(svc, s.GormDB)
+
+	s.T().Run("ok", func(t *testing.T) {
+		// when
+		_, list := test.ListCommentRevisionsOK(t, svc.Context, svc, ctrl, cmt.ID)
+		// then
+		require.Len(t, list.Data, 2)
+		assert.Equal(t, 2, list.Meta.TotalCount)
+		created, updated := list.Data[0], list.Data[1]
+		assert.Equal(t, "create", created.Attributes.RevisionType)
+		assert.Equal(t, fxt.Comments[0].Body, *created.Attributes.Body)
+		assert.Equal(t, fxt.Identities[0].ID.String(), *created.Relationships.Modifier.Data.ID)
+		assert.Equal(t, "update", updated.Attributes.RevisionType)
+		assert.Equal(t, "**edited**", *updated.Attributes.Body)
+		assert.Equal(t, "<p><strong>edited</strong></p>\n", *updated.Attributes.BodyRendered)
+		assert.Equal(t, fxt.Identities[1].ID.String(), *updated.Relationships.Modifier.Data.ID)
+		assert.Equal(t, cmt.ID.String(), *updated.Relationships.Comment.Data.ID)
+	})
+
+	s.T().Run("deleted comment", func(t *testing.T) {
+		// given
+		require.NoError(t, s.GormDB.Comments().Delete(context.Background(), cmt.ID, fxt.Identities[0].ID))
+		// then
+		test.ListCommentRevisionsNotFound(t, svc.Context, svc, ctrl, cmt.ID)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/notification"
 	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space/authz"
@@ -63,9 +64,14 @@ func NewNotifyingCommentsController(service *goa.Service, db application.DB, not
 // Show runs the show action.
 func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
 	var cmt *comment.Comment
+	var includeReferences CommentConvertFunc
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		var err error
 		cmt, err = appl.Comments().Load(ctx, ctx.CommentID)
+		if err != nil {
+			return err
+		}
+		includeReferences, err = commentIncludeReferences(ctx, appl, *cmt)
 		return err
 	})
 	if err != nil {
@@ -78,7 +84,8 @@ func (c *CommentsController) Show(ctx *app.ShowCommentsContext) error {
 		res.Data = ConvertComment(
 			ctx.Request,
 			*cmt,
-			includeParentWorkItem)
+			includeParentWorkItem,
+			includeReferences)
 		return ctx.OK(res)
 	})
 }
@@ -103,15 +110,18 @@ func (c *CommentsController) Update(ctx *app.UpdateCommentsContext) error {
 			return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not a space collaborator"))
 		}
 	}
-	err = c.performUpdate(ctx, cm, identityID)
+	mentions, includeReferences, err := c.performUpdate(ctx, cm, identityID)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
 	// This code should change if others type of parents than WI are allowed
 	res := &app.CommentSingle{
-		Data: ConvertComment(ctx.Request, *cm, CommentIncludeParentWorkItem(ctx, cm)),
+		Data: ConvertComment(ctx.Request, *cm, CommentIncludeParentWorkItem(ctx, cm), includeReferences),
 	}
 	c.notification.Send(ctx, notification.NewCommentUpdated(cm.ID.String()))
+	notifyMentions(ctx, c.notification, mentions, *identityID, func(mentionedID uuid.UUID) notification.Message {
+		return notification.NewCommentMention(cm.ID.String(), mentionedID)
+	})
 	return ctx.OK(res)
 }
 
@@ -135,13 +145,25 @@ func (c *CommentsController) loadComment(ctx context.Context, commentID, identit
 	return // using names returned value
 }
 
-func (c *CommentsController) performUpdate(ctx *app.UpdateCommentsContext, cm *comment.Comment, identityID *uuid.UUID) error {
-	return application.Transactional(c.db, func(appl application.Application) error {
+// performUpdate saves the comment along with its references. Returns the
+// references to new targets and a function rendering the references in the
+// comment.
+func (c *CommentsController) performUpdate(ctx *app.UpdateCommentsContext, cm *comment.Comment, identityID *uuid.UUID) (mentions []reference.Reference, includeReferences CommentConvertFunc, err error) {
+	err = application.Transactional(c.db, func(appl application.Application) error {
 		cm.Body = *ctx.Payload.Data.Attributes.Body
 		cm.Markup = rendering.NilSafeGetMarkup(ctx.Payload.Data.Attributes.Markup)
 		err := appl.Comments().Save(ctx.Context, cm, *identityID)
+		if err != nil {
+			return err
+		}
+		mentions, err = updateCommentReferences(ctx, appl, *cm)
+		if err != nil {
+			return err
+		}
+		includeReferences, err = commentIncludeReferences(ctx, appl, *cm)
 		return err
 	})
+	return // using names returned value
 }
 
 // Delete does DELETE comment
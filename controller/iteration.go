@@ -45,19 +45,28 @@ func NewIterationController(service *goa.Service, db application.DB, config Iter
 	return &IterationController{Controller: service.NewController("IterationController"), db: db, config: config}
 }
 
-// verifyUser checks if user is a space owner or a collaborator
-func verifyUser(ctx context.Context, currentUser uuid.UUID, sp *space.Space) (bool, bool, error) {
-	authorized, err := authz.Authorize(ctx, sp.ID.String())
+// verifyUser checks if user is a space owner or if one of the roles of the
+// user in the space grants the given permission
+func verifyUser(ctx context.Context, currentUser uuid.UUID, sp *space.Space, p authz.Permission) (bool, bool, error) {
+	authorized, err := authz.AuthorizePermission(ctx, sp.ID.String(), p)
 	if err != nil {
 		return false, false, err
 	}
-	var spaceOwner bool
-	if uuid.Equal(currentUser, sp.OwnerID) {
-		spaceOwner = true
-	}
+	spaceOwner := authz.AuthorizeOwner(ctx, currentUser, sp.OwnerID, p)
 	return authorized, spaceOwner, nil
 }
 
+// authorizeSpacePermission returns true if the current user is the owner of
+// the space, who is allowed to do everything in it, or if one of the roles of
+// the user in the space grants the given permission. The scopes of a personal
+// access token limit the owner as well.
+func authorizeSpacePermission(ctx context.Context, currentUser uuid.UUID, sp space.Space, p authz.Permission) (bool, error) {
+	if authz.AuthorizeOwner(ctx, currentUser, sp.OwnerID, p) {
+		return true, nil
+	}
+	return authz.AuthorizePermission(ctx, sp.ID.String(), p)
+}
+
 // CreateChild runs the create-child action.
 func (c *IterationController) CreateChild(ctx *app.CreateChildIterationContext) error {
 	currentUser, err := login.ContextIdentity(ctx)
@@ -81,7 +90,7 @@ func (c *IterationController) CreateChild(ctx *app.CreateChildIterationContext)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, itrSpace)
+	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, itrSpace, authz.PermissionEditIteration)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
@@ -215,7 +224,7 @@ func (c *IterationController) Update(ctx *app.UpdateIterationContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, sp)
+	authorized, spaceOwner, err := verifyUser(ctx, *currentUser, sp, authz.PermissionEditIteration)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
@@ -363,8 +372,12 @@ func (c *IterationController) Delete(ctx *app.DeleteIterationContext) error {
 		if err != nil {
 			return goa.ErrNotFound(err.Error())
 		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
-			errorMsg := fmt.Sprintf("only the space owner can delete an iteration and %s is not the space owner of %s",
+		authorized, err := authorizeSpacePermission(ctx, *currentUser, *s, authz.PermissionManageIterations)
+		if err != nil {
+			return errors.NewUnauthorizedError(err.Error())
+		}
+		if !authorized {
+			errorMsg := fmt.Sprintf("only the space owner or planners can delete an iteration and %s is neither for space %s",
 				*currentUser, s.ID)
 			log.Warn(ctx, map[string]interface{}{
 				"space_id":     s.ID,
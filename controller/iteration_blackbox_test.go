@@ -1380,3 +1380,172 @@ func minimumUpdatePayloadWithParent() app.UpdateIterationPayload {
 		},
 	}
 }
+
+func newRolloverIterationPayload(targetID uuid.UUID, includeChildren bool) *app.RolloverIterationPayload {
+	return &app.RolloverIterationPayload{
+		Data: &app.IterationRollover{
+			Type: APIStringTypeIterationRollover,
+			Attributes: &app.IterationRolloverAttributes{
+				IncludeChildren: &includeChildren,
+			},
+			Relationships: &app.IterationRolloverRelations{
+				Target: &app.RelationGeneric{
+					Data: &app.GenericData{
+						ID:   ptr.String(targetID.String()),
+						Type: ptr.String(iteration.APIStringTypeIteration),
+					},
+				},
+			},
+		},
+	}
+}
+
+func (rest *TestIterationREST) TestIterationRollover() {
+	// "sprint 1" and its child "sprint 1.1" each hold an open and a closed
+	// work item
+	newFixture := func(t *testing.T) *tf.TestFixture {
+		return tf.NewTestFixture(t, rest.DB,
+			tf.Iterations(5,
+				tf.SetIterationNames("root", "sprint 1", "sprint 1.1", "sprint 2", "sprint 0"),
+				func(fxt *tf.TestFixture, idx int) error {
+					switch idx {
+					case 1, 3, 4:
+						fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[0])
+					case 2:
+						fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[1])
+					}
+					if idx == 4 {
+						fxt.Iterations[idx].State = iteration.StateClose
+					}
+					return nil
+				}),
+			tf.WorkItems(4, func(fxt *tf.TestFixture, idx int) error {
+				itr := fxt.IterationByName("sprint 1")
+				if idx >= 2 {
+					itr = fxt.IterationByName("sprint 1.1")
+				}
+				fxt.WorkItems[idx].Fields[workitem.SystemIteration] = itr.ID.String()
+				if idx%2 == 1 {
+					fxt.WorkItems[idx].Fields[workitem.SystemState] = workitem.SystemStateClosed
+				}
+				return nil
+			}))
+	}
+	iterationOf := func(t *testing.T, wi *workitem.WorkItem) interface{} {
+		loaded, err := rest.GormDB.WorkItems().LoadByID(context.Background(), wi.ID)
+		require.NoError(t, err)
+		return loaded.Fields[workitem.SystemIteration]
+	}
+
+	rest.T().Run("success - without children", func(t *testing.T) {
+		// given
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		source, target := fxt.IterationByName("sprint 1"), fxt.IterationByName("sprint 2")
+		// when
+		_, res := test.RolloverIterationOK(t, svc.Context, svc, ctrl, source.ID, newRolloverIterationPayload(target.ID, false))
+		// then
+		assert.Equal(t, 1, *res.Data.Attributes.MovedCount)
+		assert.Equal(t, 1, *res.Data.Attributes.RemainingCount)
+		require.Len(t, res.Data.Relationships.Workitems.Data, 1)
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), *res.Data.Relationships.Workitems.Data[0].ID)
+		require.Len(t, res.Data.Relationships.Iterations.Data, 1)
+		assert.Equal(t, source.ID.String(), *res.Data.Relationships.Iterations.Data[0].ID)
+		assert.Equal(t, target.ID.String(), *res.Data.Relationships.Target.Data.ID)
+		assert.Equal(t, target.ID.String(), iterationOf(t, fxt.WorkItems[0]))
+		assert.Equal(t, source.ID.String(), iterationOf(t, fxt.WorkItems[1]))
+		assert.Equal(t, fxt.IterationByName("sprint 1.1").ID.String(), iterationOf(t, fxt.WorkItems[2]))
+		closed, err := rest.GormDB.Iterations().Load(svc.Context, source.ID)
+		require.NoError(t, err)
+		assert.Equal(t, iteration.StateClose, closed.State)
+		child, err := rest.GormDB.Iterations().Load(svc.Context, fxt.IterationByName("sprint 1.1").ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, iteration.StateClose, child.State)
+		// the move is recorded in the history of the work item
+		revisions, err := workitem.NewRevisionRepository(rest.DB).List(svc.Context, fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 2)
+		assert.Equal(t, target.ID.String(), revisions[1].WorkItemFields[workitem.SystemIteration])
+		assert.Equal(t, fxt.Identities[0].ID, revisions[1].ModifierIdentity)
+	})
+
+	rest.T().Run("success - with children", func(t *testing.T) {
+		// given
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		source, target := fxt.IterationByName("sprint 1"), fxt.IterationByName("sprint 2")
+		// when
+		_, res := test.RolloverIterationOK(t, svc.Context, svc, ctrl, source.ID, newRolloverIterationPayload(target.ID, true))
+		// then
+		assert.Equal(t, 2, *res.Data.Attributes.MovedCount)
+		assert.Equal(t, 2, *res.Data.Attributes.RemainingCount)
+		assert.Len(t, res.Data.Relationships.Iterations.Data, 2)
+		assert.Equal(t, target.ID.String(), iterationOf(t, fxt.WorkItems[2]))
+		assert.Equal(t, fxt.IterationByName("sprint 1.1").ID.String(), iterationOf(t, fxt.WorkItems[3]))
+		child, err := rest.GormDB.Iterations().Load(svc.Context, fxt.IterationByName("sprint 1.1").ID)
+		require.NoError(t, err)
+		assert.Equal(t, iteration.StateClose, child.State)
+	})
+
+	rest.T().Run("conflict - closed target", func(t *testing.T) {
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		test.RolloverIterationConflict(t, svc.Context, svc, ctrl, fxt.IterationByName("sprint 1").ID, newRolloverIterationPayload(fxt.IterationByName("sprint 0").ID, false))
+		// nothing moved
+		assert.Equal(t, fxt.IterationByName("sprint 1").ID.String(), iterationOf(t, fxt.WorkItems[0]))
+	})
+
+	rest.T().Run("conflict - closed source", func(t *testing.T) {
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		test.RolloverIterationConflict(t, svc.Context, svc, ctrl, fxt.IterationByName("sprint 0").ID, newRolloverIterationPayload(fxt.IterationByName("sprint 2").ID, false))
+	})
+
+	rest.T().Run("bad request - target closed by the rollover", func(t *testing.T) {
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		test.RolloverIterationBadRequest(t, svc.Context, svc, ctrl, fxt.IterationByName("sprint 1").ID, newRolloverIterationPayload(fxt.IterationByName("sprint 1.1").ID, true))
+	})
+
+	rest.T().Run("bad request - unfinished work item can't be moved", func(t *testing.T) {
+		// given
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		source := fxt.IterationByName("sprint 1")
+		// the work item in the child iteration can't be saved without a title
+		require.NoError(t, rest.DB.Exec("UPDATE work_items SET fields = fields - 'system.title' WHERE id = ?", fxt.WorkItems[2].ID).Error)
+		// when
+		test.RolloverIterationBadRequest(t, svc.Context, svc, ctrl, source.ID, newRolloverIterationPayload(fxt.IterationByName("sprint 2").ID, true))
+		// then nothing was moved or closed
+		assert.Equal(t, source.ID.String(), iterationOf(t, fxt.WorkItems[0]))
+		assert.Equal(t, fxt.IterationByName("sprint 1.1").ID.String(), iterationOf(t, fxt.WorkItems[2]))
+		loaded, err := rest.GormDB.Iterations().Load(svc.Context, source.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, iteration.StateClose, loaded.State)
+	})
+
+	rest.T().Run("not found - unknown target", func(t *testing.T) {
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		test.RolloverIterationNotFound(t, svc.Context, svc, ctrl, fxt.IterationByName("sprint 1").ID, newRolloverIterationPayload(uuid.NewV4(), false))
+	})
+
+	rest.T().Run("forbidden - root iteration", func(t *testing.T) {
+		fxt := newFixture(t)
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.Identities[0])
+		test.RolloverIterationForbidden(t, svc.Context, svc, ctrl, fxt.IterationByName("root").ID, newRolloverIterationPayload(fxt.IterationByName("sprint 2").ID, false))
+	})
+
+	rest.T().Run("forbidden - other user", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, rest.DB,
+			tf.Identities(2, tf.SetIdentityUsernames("space owner", "other user")),
+			tf.Iterations(3, func(fxt *tf.TestFixture, idx int) error {
+				if idx > 0 {
+					fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[0])
+				}
+				return nil
+			}))
+		svc, ctrl := rest.SecuredControllerWithIdentity(fxt.IdentityByUsername("other user"))
+		test.RolloverIterationForbidden(t, svc.Context, svc, ctrl, fxt.Iterations[1].ID, newRolloverIterationPayload(fxt.Iterations[2].ID, false))
+	})
+}
@@ -0,0 +1,239 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeIterationRollover helps to avoid string literal
+const APIStringTypeIterationRollover = "iterationrollovers"
+
+// IterationRollover is the outcome of closing iterations and moving their
+// unfinished work items to a target iteration
+type IterationRollover struct {
+	TargetID uuid.UUID
+	// Iterations are the IDs of the closed iterations
+	Iterations []uuid.UUID
+	// Moved are the IDs of the work items moved to the target iteration
+	Moved []uuid.UUID
+	// Remaining is the number of done work items which stayed in the closed
+	// iterations
+	Remaining int
+}
+
+// rolloverDoneStates returns the states in which the work items of the given
+// type are done, i.e. the states of the mResolved and mClosed meta-states. The
+// report.DoneStates are used for types without meta-states.
+func rolloverDoneStates(wit workitem.WorkItemType) []string {
+	var states []string
+	for _, metaState := range []string{"mResolved", "mClosed"} {
+		if state, err := wit.StateForMetaState(metaState); err == nil {
+			states = append(states, state)
+		}
+	}
+	if len(states) == 0 {
+		return report.DoneStates
+	}
+	return states
+}
+
+// isRolloverDone returns true if the given state is one of the done states
+func isRolloverDone(doneStates []string, state interface{}) bool {
+	s, _ := state.(string)
+	for _, done := range doneStates {
+		if strings.EqualFold(s, done) {
+			return true
+		}
+	}
+	return false
+}
+
+// Rollover runs the rollover action.
+func (c *IterationController) Rollover(ctx *app.RolloverIterationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	rel := ctx.Payload.Data.Relationships
+	if rel == nil || rel.Target == nil || rel.Target.Data == nil || rel.Target.Data.ID == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.target.data.id", nil).Expected("not nil"))
+	}
+	targetID, err := uuid.FromString(*rel.Target.Data.ID)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.target.data.id", *rel.Target.Data.ID).Expected("valid UUID"))
+	}
+	includeChildren := false
+	if attrs := ctx.Payload.Data.Attributes; attrs != nil && attrs.IncludeChildren != nil {
+		includeChildren = *attrs.IncludeChildren
+	}
+	result := IterationRollover{TargetID: targetID}
+	// everything happens in a single transaction so that the iterations are
+	// only closed if all their unfinished work items were moved
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		itr, err := appl.Iterations().Load(ctx, ctx.IterationID)
+		if err != nil {
+			return err
+		}
+		s, err := appl.Spaces().Load(ctx, itr.SpaceID)
+		if err != nil {
+			return err
+		}
+		authorized, err := authorizeSpacePermission(ctx, *currentUser, *s, authz.PermissionManageIterations)
+		if err != nil {
+			return errors.NewUnauthorizedError(err.Error())
+		}
+		if !authorized {
+			return errors.NewForbiddenError(fmt.Sprintf("only the space owner or planners can roll over an iteration and %s is neither for space %s", *currentUser, s.ID))
+		}
+		if itr.IsRoot(s.ID) {
+			return errors.NewForbiddenError("can not roll over the root iteration")
+		}
+		target, err := appl.Iterations().Load(ctx, targetID)
+		if err != nil {
+			return err
+		}
+		if target.SpaceID != itr.SpaceID {
+			return errors.NewBadParameterError("data.relationships.target.data.id", targetID).Expected("iteration of space " + itr.SpaceID.String())
+		}
+		if target.State == iteration.StateClose {
+			return errors.NewDataConflictError(fmt.Sprintf("the target iteration %s is already closed", target.ID))
+		}
+		ids := []uuid.UUID{itr.ID}
+		if includeChildren {
+			children, err := appl.Iterations().LoadChildren(ctx, itr.ID)
+			if err != nil {
+				return err
+			}
+			for _, child := range children {
+				ids = append(ids, child.ID)
+			}
+		}
+		for _, id := range ids {
+			if id == target.ID {
+				return errors.NewBadParameterError("data.relationships.target.data.id", targetID).Expected("iteration which isn't closed by the rollover")
+			}
+		}
+		// the locks hold back work items which are added to the iterations
+		// until they are closed, so none of them is missed
+		iterations, err := appl.Iterations().LockMultiple(ctx, ids)
+		if err != nil {
+			return err
+		}
+		for _, closing := range iterations {
+			if closing.ID == itr.ID && closing.State == iteration.StateClose {
+				return errors.NewDataConflictError(fmt.Sprintf("the iteration %s is already closed", itr.ID))
+			}
+		}
+		doneStates := map[uuid.UUID][]string{}
+		for _, closing := range iterations {
+			wis, err := appl.WorkItems().LoadByIteration(ctx, closing.ID)
+			if err != nil {
+				return err
+			}
+			for _, wi := range wis {
+				states, ok := doneStates[wi.Type]
+				if !ok {
+					wit, err := appl.WorkItemTypes().Load(ctx, wi.Type)
+					if err != nil {
+						return err
+					}
+					states = rolloverDoneStates(*wit)
+					doneStates[wi.Type] = states
+				}
+				if isRolloverDone(states, wi.Fields[workitem.SystemState]) {
+					result.Remaining++
+					continue
+				}
+				// saving the work item records a revision of the move
+				wi.Fields[workitem.SystemIteration] = targetID.String()
+				if _, err := appl.WorkItems().Save(ctx, wi.SpaceID, *wi, *currentUser); err != nil {
+					log.Error(ctx, map[string]interface{}{
+						"workitem_id": wi.ID,
+						"err":         err,
+					}, "unable to move the work item to the target iteration, the rollover is aborted")
+					return err
+				}
+				result.Moved = append(result.Moved, wi.ID)
+			}
+		}
+		for _, closing := range iterations {
+			closing.State = iteration.StateClose
+			if _, err := appl.Iterations().Save(ctx, closing); err != nil {
+				return err
+			}
+			result.Iterations = append(result.Iterations, closing.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"iteration_id": ctx.IterationID,
+		"target_id":    targetID,
+		"moved":        len(result.Moved),
+		"remaining":    result.Remaining,
+	}, "rolled over iteration")
+	return ctx.OK(&app.IterationRolloverSingle{
+		Data: ConvertIterationRollover(ctx.Request, result),
+	})
+}
+
+// ConvertIterationRollover converts from internal to external REST
+// representation
+func ConvertIterationRollover(request *http.Request, r IterationRollover) *app.IterationRollover {
+	targetData, targetLinks := ConvertIterationSimple(request, r.TargetID)
+	iterations := &app.RelationGenericList{
+		Data: make([]*app.GenericData, len(r.Iterations)),
+	}
+	for i, id := range r.Iterations {
+		iterations.Data[i], _ = ConvertIterationSimple(request, id)
+	}
+	workItems := convertRolloverWorkItems(request, r.Moved)
+	return &app.IterationRollover{
+		Type: APIStringTypeIterationRollover,
+		Attributes: &app.IterationRolloverAttributes{
+			MovedCount:     ptr.Int(len(r.Moved)),
+			RemainingCount: &r.Remaining,
+		},
+		Relationships: &app.IterationRolloverRelations{
+			Target: &app.RelationGeneric{
+				Data:  targetData,
+				Links: targetLinks,
+			},
+			Iterations: iterations,
+			Workitems:  workItems,
+		},
+	}
+}
+
+func convertRolloverWorkItems(request *http.Request, ids []uuid.UUID) *app.RelationGenericList {
+	workItems := &app.RelationGenericList{
+		Data: make([]*app.GenericData, len(ids)),
+	}
+	for i, id := range ids {
+		related := rest.AbsoluteURL(request, app.WorkitemHref(id.String()))
+		workItems.Data[i] = &app.GenericData{
+			Type:  ptr.String(APIStringTypeWorkItem),
+			ID:    ptr.String(id.String()),
+			Links: &app.GenericLinks{Related: &related},
+		}
+	}
+	return workItems
+}
@@ -0,0 +1,217 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// IterationScheduleController implements the iteration_schedule resource.
+type IterationScheduleController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewIterationScheduleController creates a iteration_schedule controller.
+func NewIterationScheduleController(service *goa.Service, db application.DB) *IterationScheduleController {
+	return &IterationScheduleController{
+		Controller: service.NewController("IterationScheduleController"),
+		db:         db,
+	}
+}
+
+// authorizeIterationScheduleManager returns a forbidden error unless the given
+// identity is allowed to manage the iterations of the space.
+func authorizeIterationScheduleManager(ctx context.Context, appl application.Application, spaceID uuid.UUID, identityID uuid.UUID) error {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	authorized, err := authorizeSpacePermission(ctx, identityID, *s, authz.PermissionManageIterations)
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error())
+	}
+	if !authorized {
+		return errors.NewForbiddenError(fmt.Sprintf("only the space owner or planners can manage iteration schedules and %s is neither for space %s", identityID, s.ID))
+	}
+	return nil
+}
+
+// generateScheduledIterations creates the upcoming iterations of the given
+// schedule and returns the schedule with its updated sequence
+func generateScheduledIterations(ctx context.Context, appl application.Application, id uuid.UUID) (*schedule.Schedule, error) {
+	created, err := schedule.Generate(ctx, appl.IterationSchedules(), appl.Iterations(), id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if len(created) > 0 {
+		log.Info(ctx, map[string]interface{}{
+			"schedule_id": id,
+			"created":     len(created),
+		}, "generated scheduled iterations")
+	}
+	return appl.IterationSchedules().Load(ctx, id)
+}
+
+// Show runs the show action.
+func (c *IterationScheduleController) Show(ctx *app.ShowIterationScheduleContext) error {
+	var s *schedule.Schedule
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		s, err = appl.IterationSchedules().Load(ctx, ctx.ScheduleID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.IterationScheduleSingle{
+		Data: ConvertIterationSchedule(ctx.Request, *s),
+	})
+}
+
+// Update runs the update action.
+func (c *IterationScheduleController) Update(ctx *app.UpdateIterationScheduleContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+	var s *schedule.Schedule
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		// the lock keeps the generator from advancing the schedule while its
+		// timing is checked
+		s, err = appl.IterationSchedules().LoadForUpdate(ctx, ctx.ScheduleID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeIterationScheduleManager(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		if s.Version != *attrs.Version {
+			return errors.NewVersionConflictError("version conflict")
+		}
+		existing := *s
+		if attrs.StartAt != nil {
+			s.StartAt = *attrs.StartAt
+		}
+		if attrs.CadenceDays != nil {
+			s.CadenceDays = *attrs.CadenceDays
+		}
+		if attrs.LengthDays != nil {
+			s.LengthDays = *attrs.LengthDays
+		}
+		if attrs.NamePattern != nil {
+			s.NamePattern = *attrs.NamePattern
+		}
+		if attrs.LookaheadDays != nil {
+			s.LookaheadDays = *attrs.LookaheadDays
+		}
+		if attrs.Active != nil {
+			s.Active = *attrs.Active
+		}
+		// the numbers and dates of the generated iterations follow from the
+		// timing, so it can't change once iterations were generated
+		timingChanged := !s.StartAt.Equal(existing.StartAt) || s.CadenceDays != existing.CadenceDays || s.LengthDays != existing.LengthDays
+		if timingChanged && s.NextSequence > 1 {
+			return errors.NewDataConflictError(fmt.Sprintf("the start, cadence and length of the iteration schedule %s can't be changed once it generated iterations", s.ID))
+		}
+		if _, err := appl.IterationSchedules().Save(ctx, *s); err != nil {
+			return err
+		}
+		s, err = generateScheduledIterations(ctx, appl, s.ID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.IterationScheduleSingle{
+		Data: ConvertIterationSchedule(ctx.Request, *s),
+	})
+}
+
+// Delete runs the delete action.
+func (c *IterationScheduleController) Delete(ctx *app.DeleteIterationScheduleContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.IterationSchedules().Load(ctx, ctx.ScheduleID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeIterationScheduleManager(ctx, appl, s.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		return appl.IterationSchedules().Delete(ctx, s.ID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertIterationSchedule converts from internal to external REST
+// representation
+func ConvertIterationSchedule(request *http.Request, s schedule.Schedule) *app.IterationSchedule {
+	spaceID := s.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.IterationScheduleHref(s.ID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	parentData, parentLinks := ConvertIterationSimple(request, s.ParentIterationID)
+	return &app.IterationSchedule{
+		Type: schedule.APIStringTypeIterationSchedules,
+		ID:   &s.ID,
+		Attributes: &app.IterationScheduleAttributes{
+			StartAt:       &s.StartAt,
+			CadenceDays:   &s.CadenceDays,
+			LengthDays:    &s.LengthDays,
+			NamePattern:   &s.NamePattern,
+			LookaheadDays: &s.LookaheadDays,
+			Active:        &s.Active,
+			NextSequence:  &s.NextSequence,
+			CreatedAt:     &s.CreatedAt,
+			UpdatedAt:     &s.UpdatedAt,
+			Version:       &s.Version,
+		},
+		Relationships: &app.IterationScheduleRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &space.SpaceType,
+					ID:   &spaceID,
+				},
+				Links: &app.GenericLinks{
+					Self:    &spaceRelatedURL,
+					Related: &spaceRelatedURL,
+				},
+			},
+			Parent: &app.RelationGeneric{
+				Data:  parentData,
+				Links: parentLinks,
+			},
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &selfURL,
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestIterationScheduleREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunIterationScheduleREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestIterationScheduleREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newCreateIterationSchedulePayload(startAt time.Time, parentID *uuid.UUID) *app.CreateSpaceIterationSchedulesPayload {
+	payload := &app.CreateSpaceIterationSchedulesPayload{
+		Data: &app.IterationSchedule{
+			Type: schedule.APIStringTypeIterationSchedules,
+			Attributes: &app.IterationScheduleAttributes{
+				StartAt:       &startAt,
+				CadenceDays:   ptr.Int(14),
+				NamePattern:   ptr.String("Sprint {n}"),
+				LookaheadDays: ptr.Int(20),
+			},
+		},
+	}
+	if parentID != nil {
+		payload.Data.Relationships = &app.IterationScheduleRelations{
+			Parent: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(iteration.APIStringTypeIteration),
+					ID:   ptr.String(parentID.String()),
+				},
+			},
+		}
+	}
+	return payload
+}
+
+func (rest *TestIterationScheduleREST) TestCreateAndManage() {
+	fxt := tf.NewTestFixture(rest.T(), rest.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("space owner", "other user")),
+		tf.Iterations(2, tf.SetIterationNames("root", "release 1"), func(fxt *tf.TestFixture, idx int) error {
+			if idx > 0 {
+				fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[0])
+			}
+			return nil
+		}))
+	owner := testsupport.ServiceAsUser("IterationSchedule-Service", *fxt.IdentityByUsername("space owner"))
+	other := testsupport.ServiceAsUser("IterationSchedule-Service", *fxt.IdentityByUsername("other user"))
+	spaceCtrl := NewSpaceIterationSchedulesController(owner, rest.GormDB)
+	ctrl := NewIterationScheduleController(owner, rest.GormDB)
+	iterations := iteration.NewIterationRepository(rest.DB)
+	// the first sprint is running, the second starts within the lookahead
+	startAt := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -7)
+
+	_, created := test.CreateSpaceIterationSchedulesCreated(rest.T(), owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, nil))
+	require.NotNil(rest.T(), created.Data.ID)
+	assert.Equal(rest.T(), 14, *created.Data.Attributes.LengthDays)
+	assert.True(rest.T(), *created.Data.Attributes.Active)
+	assert.Equal(rest.T(), 3, *created.Data.Attributes.NextSequence)
+	assert.Equal(rest.T(), fxt.IterationByName("root").ID.String(), *created.Data.Relationships.Parent.Data.ID)
+	children, err := iterations.LoadChildren(rest.Ctx, fxt.IterationByName("root").ID)
+	require.NoError(rest.T(), err)
+	names := []string{}
+	for _, itr := range children {
+		names = append(names, itr.Name)
+	}
+	assert.ElementsMatch(rest.T(), []string{"release 1", "Sprint 1", "Sprint 2"}, names)
+
+	rest.T().Run("one schedule per parent", func(t *testing.T) {
+		test.CreateSpaceIterationSchedulesConflict(t, owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, nil))
+	})
+	rest.T().Run("below another parent", func(t *testing.T) {
+		parentID := fxt.IterationByName("release 1").ID
+		_, res := test.CreateSpaceIterationSchedulesCreated(t, owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, &parentID))
+		assert.Equal(t, parentID.String(), *res.Data.Relationships.Parent.Data.ID)
+	})
+	rest.T().Run("list", func(t *testing.T) {
+		_, list := test.ListSpaceIterationSchedulesOK(t, owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID)
+		require.Len(t, list.Data, 2)
+		assert.Equal(t, *created.Data.ID, *list.Data[0].ID)
+	})
+	rest.T().Run("show", func(t *testing.T) {
+		_, shown := test.ShowIterationScheduleOK(t, owner.Context, owner, ctrl, *created.Data.ID)
+		assert.Equal(t, *created.Data.Attributes.NamePattern, *shown.Data.Attributes.NamePattern)
+	})
+	rest.T().Run("update", func(t *testing.T) {
+		payload := &app.UpdateIterationSchedulePayload{
+			Data: &app.IterationSchedule{
+				Type: schedule.APIStringTypeIterationSchedules,
+				ID:   created.Data.ID,
+				Attributes: &app.IterationScheduleAttributes{
+					LookaheadDays: ptr.Int(40),
+					Version:       created.Data.Attributes.Version,
+				},
+			},
+		}
+		_, updated := test.UpdateIterationScheduleOK(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+		assert.Equal(t, 40, *updated.Data.Attributes.LookaheadDays)
+		// the longer lookahead reaches the third and fourth sprint
+		assert.Equal(t, 5, *updated.Data.Attributes.NextSequence)
+		// the outdated version is rejected
+		test.UpdateIterationScheduleConflict(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+	})
+	rest.T().Run("timing of a schedule with iterations", func(t *testing.T) {
+		_, shown := test.ShowIterationScheduleOK(t, owner.Context, owner, ctrl, *created.Data.ID)
+		payload := &app.UpdateIterationSchedulePayload{
+			Data: &app.IterationSchedule{
+				Type: schedule.APIStringTypeIterationSchedules,
+				ID:   created.Data.ID,
+				Attributes: &app.IterationScheduleAttributes{
+					CadenceDays: ptr.Int(7),
+					Version:     shown.Data.Attributes.Version,
+				},
+			},
+		}
+		test.UpdateIterationScheduleConflict(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+		// the unchanged timing is accepted
+		payload.Data.Attributes.CadenceDays = shown.Data.Attributes.CadenceDays
+		payload.Data.Attributes.StartAt = shown.Data.Attributes.StartAt
+		test.UpdateIterationScheduleOK(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+	})
+	rest.T().Run("forbidden for other users", func(t *testing.T) {
+		otherSpaceCtrl := NewSpaceIterationSchedulesController(other, rest.GormDB)
+		otherCtrl := NewIterationScheduleController(other, rest.GormDB)
+		test.CreateSpaceIterationSchedulesForbidden(t, other.Context, other, otherSpaceCtrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, nil))
+		test.DeleteIterationScheduleForbidden(t, other.Context, other, otherCtrl, *created.Data.ID)
+	})
+	rest.T().Run("delete", func(t *testing.T) {
+		test.DeleteIterationScheduleNoContent(t, owner.Context, owner, ctrl, *created.Data.ID)
+		test.ShowIterationScheduleNotFound(t, owner.Context, owner, ctrl, *created.Data.ID)
+		test.DeleteIterationScheduleNotFound(t, owner.Context, owner, ctrl, uuid.NewV4())
+		// the generated iterations are kept
+		children, err := iterations.LoadChildren(rest.Ctx, fxt.IterationByName("root").ID)
+		require.NoError(t, err)
+		assert.True(t, len(children) >= 5)
+	})
+}
+
+func (rest *TestIterationScheduleREST) TestCreateInvalid() {
+	fxt := tf.NewTestFixture(rest.T(), rest.DB, tf.Identities(1), tf.Spaces(2), tf.Iterations(2, func(fxt *tf.TestFixture, idx int) error {
+		fxt.Iterations[idx].SpaceID = fxt.Spaces[idx].ID
+		return nil
+	}))
+	svc := testsupport.ServiceAsUser("IterationSchedule-Service", *fxt.Identities[0])
+	ctrl := NewSpaceIterationSchedulesController(svc, rest.GormDB)
+	startAt := time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	rest.T().Run("constant name", func(t *testing.T) {
+		payload := newCreateIterationSchedulePayload(startAt, nil)
+		payload.Data.Attributes.NamePattern = ptr.String("Sprint")
+		test.CreateSpaceIterationSchedulesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+	})
+	rest.T().Run("missing cadence", func(t *testing.T) {
+		payload := newCreateIterationSchedulePayload(startAt, nil)
+		payload.Data.Attributes.CadenceDays = nil
+		test.CreateSpaceIterationSchedulesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+	})
+	rest.T().Run("parent of another space", func(t *testing.T) {
+		test.CreateSpaceIterationSchedulesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, &fxt.Iterations[1].ID))
+	})
+	rest.T().Run("unknown space", func(t *testing.T) {
+		test.CreateSpaceIterationSchedulesNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), newCreateIterationSchedulePayload(startAt, nil))
+	})
+	rest.T().Run("unauthorized", func(t *testing.T) {
+		unauthorized := goa.New("IterationSchedule-Service")
+		unauthorizedCtrl := NewSpaceIterationSchedulesController(unauthorized, rest.GormDB)
+		test.CreateSpaceIterationSchedulesUnauthorized(t, unauthorized.Context, unauthorized, unauthorizedCtrl, fxt.Spaces[0].ID, newCreateIterationSchedulePayload(startAt, nil))
+	})
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -13,7 +14,9 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
 )
 
 // LabelController implements the label resource.
@@ -53,9 +56,26 @@ func (c *LabelController) Show(ctx *app.ShowLabelContext) error {
 	})
 }
 
+// authorizeLabelEditor returns a ForbiddenError unless the current user may
+// manage the labels of the space
+func authorizeLabelEditor(ctx context.Context, appl application.Application, currentUser, spaceID uuid.UUID) error {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	authorized, err := authorizeSpacePermission(ctx, currentUser, *s, authz.PermissionManageLabels)
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error())
+	}
+	if !authorized {
+		return errors.NewForbiddenError("user is not allowed to manage the labels of the space")
+	}
+	return nil
+}
+
 // Create runs the create action.
 func (c *LabelController) Create(ctx *app.CreateLabelContext) error {
-	_, err := login.ContextIdentity(ctx)
+	currentUser, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -76,6 +96,9 @@ func (c *LabelController) Create(ctx *app.CreateLabelContext) error {
 		lbl.BorderColor = *ctx.Payload.Data.Attributes.BorderColor
 	}
 	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeLabelEditor(ctx, appl, *currentUser, ctx.SpaceID); err != nil {
+			return err
+		}
 		return appl.Labels().Create(ctx, lbl)
 	})
 	if err != nil {
@@ -174,7 +197,7 @@ func ConvertLabelSimple(request *http.Request, labelID interface{}) *app.Generic
 
 // Update runs the update action.
 func (c *LabelController) Update(ctx *app.UpdateLabelContext) error {
-	_, err := login.ContextIdentity(ctx)
+	currentUser, err := login.ContextIdentity(ctx)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
 	}
@@ -188,6 +211,9 @@ func (c *LabelController) Update(ctx *app.UpdateLabelContext) error {
 		if err != nil {
 			return err
 		}
+		if err := authorizeLabelEditor(ctx, appl, *currentUser, lbl.SpaceID); err != nil {
+			return err
+		}
 		if lbl.Version != *ctx.Payload.Data.Attributes.Version {
 			return errors.NewVersionConflictError("version conflict")
 		}
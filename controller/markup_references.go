@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/reference"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// updateDescriptionReferences stores the references in the description of the
+// work item and returns the ones to new targets
+func updateDescriptionReferences(ctx context.Context, appl application.Application, wi workitem.WorkItem) ([]reference.Reference, error) {
+	var content, markup string
+	if description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription]); description != nil {
+		content, markup = description.Content, description.Markup
+	}
+	refs, err := appl.MarkupReferences().Update(ctx, reference.Description(wi.ID), wi.SpaceID, content, markup)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to update the references in the description of work item %s", wi.ID)
+	}
+	return refs, nil
+}
+
+// updateCommentReferences stores the references in the comment and returns the
+// ones to new targets. Work item numbers refer to the space of the commented
+// work item.
+func updateCommentReferences(ctx context.Context, appl application.Application, cmt comment.Comment) ([]reference.Reference, error) {
+	wi, err := appl.WorkItems().LoadByID(ctx, cmt.ParentID)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := appl.MarkupReferences().Update(ctx, reference.Comment(wi.ID, cmt.ID), wi.SpaceID, cmt.Body, cmt.Markup)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to update the references in comment %s", cmt.ID)
+	}
+	return refs, nil
+}
+
+// notifyMentions sends a message for every user mentioned in the given
+// references, except for the author who mentioned them
+func notifyMentions(ctx context.Context, channel notification.Channel, refs []reference.Reference, authorID uuid.UUID, newMessage func(mentionedID uuid.UUID) notification.Message) {
+	for _, ref := range refs {
+		if ref.TargetIdentityID.Valid && !uuid.Equal(ref.TargetIdentityID.UUID, authorID) {
+			channel.Send(ctx, newMessage(ref.TargetIdentityID.UUID))
+		}
+	}
+}
+
+// referenceResolver resolves the stored references in markup to the URLs of
+// the mentioned users and referenced work items
+func referenceResolver(request *http.Request, refs []reference.Reference) rendering.ReferenceResolver {
+	urls := make(map[string]string, len(refs))
+	for _, ref := range refs {
+		if ref.TargetIdentityID.Valid {
+			urls[ref.Text] = rest.AbsoluteURL(request, fmt.Sprintf("%s/%s", usersEndpoint, ref.TargetIdentityID.UUID))
+		} else {
+			urls[ref.Text] = rest.AbsoluteURL(request, app.WorkitemHref(ref.TargetWorkItemID.UUID))
+		}
+	}
+	return func(ref rendering.Reference) (string, bool) {
+		url, ok := urls[ref.Text]
+		return url, ok
+	}
+}
+
+// commentIncludeReferences renders the bodies of the given comments with links
+// for their references
+func commentIncludeReferences(ctx context.Context, appl application.Application, comments ...comment.Comment) (CommentConvertFunc, error) {
+	ids := make([]uuid.UUID, len(comments))
+	for i, cmt := range comments {
+		ids[i] = cmt.ID
+	}
+	refs, err := appl.MarkupReferences().ListByComments(ctx, ids...)
+	if err != nil {
+		return nil, errs.Wrap(err, "failed to list the references in the comments")
+	}
+	refsByComment := map[uuid.UUID][]reference.Reference{}
+	for _, ref := range refs {
+		refsByComment[ref.SourceCommentID.UUID] = append(refsByComment[ref.SourceCommentID.UUID], ref)
+	}
+	return func(request *http.Request, cmt *comment.Comment, data *app.Comment) {
+		refs := refsByComment[cmt.ID]
+		if len(refs) == 0 || data.Attributes == nil {
+			return
+		}
+		data.Attributes.BodyRendered = ptr.String(rendering.RenderMarkupToHTMLWithReferences(html.EscapeString(cmt.Body), cmt.Markup, referenceResolver(request, refs)))
+	}, nil
+}
+
+// workItemIncludeReferences renders the descriptions of the given work items
+// with links for their references
+func workItemIncludeReferences(ctx context.Context, db application.DB, workItemIDs ...uuid.UUID) WorkItemConvertFunc {
+	var refs []reference.Reference
+	err := application.Transactional(db, func(appl application.Application) error {
+		var err error
+		refs, err = appl.MarkupReferences().ListByDescription(ctx, workItemIDs...)
+		return err
+	})
+	refsByWorkItem := map[uuid.UUID][]reference.Reference{}
+	for _, ref := range refs {
+		refsByWorkItem[ref.SourceWorkItemID] = append(refsByWorkItem[ref.SourceWorkItemID], ref)
+	}
+	return func(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) error {
+		if err != nil {
+			return errs.Wrapf(err, "failed to list the references in the description of work item %s", wi.ID)
+		}
+		refs := refsByWorkItem[wi.ID]
+		description := rendering.NewMarkupContentFromValue(wi.Fields[workitem.SystemDescription])
+		if len(refs) == 0 || description == nil {
+			return nil
+		}
+		wi2.Attributes[workitem.SystemDescriptionRendered] = rendering.RenderMarkupToHTMLWithReferences(description.Content, description.Markup, referenceResolver(request, refs))
+		return nil
+	}
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeNotificationPreferences helps to avoid string literal
+const APIStringTypeNotificationPreferences = "notificationpreferences"
+
+// NotificationPreferencesController implements the notification_preferences resource.
+type NotificationPreferencesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewNotificationPreferencesController creates a notification_preferences controller.
+func NewNotificationPreferencesController(service *goa.Service, db application.DB) *NotificationPreferencesController {
+	return &NotificationPreferencesController{
+		Controller: service.NewController("NotificationPreferencesController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *NotificationPreferencesController) List(ctx *app.ListNotificationPreferencesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var preferences []subscription.Preference
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		preferences, err = appl.NotificationPreferences().List(ctx, *currentUser)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(ConvertNotificationPreferences(preferences))
+}
+
+// Update runs the update action.
+func (c *NotificationPreferencesController) Update(ctx *app.UpdateNotificationPreferencesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	preferences, err := convertNotificationPreferencesToModel(*currentUser, ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.NotificationPreferences().Save(ctx, preferences...); err != nil {
+			return err
+		}
+		preferences, err = appl.NotificationPreferences().List(ctx, *currentUser)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(ConvertNotificationPreferences(preferences))
+}
+
+// convertNotificationPreferencesToModel converts the preferences of the
+// payload to the model of the given identity
+func convertNotificationPreferencesToModel(identityID uuid.UUID, data []*app.NotificationPreference) ([]subscription.Preference, error) {
+	result := make([]subscription.Preference, 0, len(data))
+	for i, p := range data {
+		if p == nil || p.Attributes == nil {
+			return nil, errors.NewBadParameterError(fmt.Sprintf("data[%d].attributes", i), nil).Expected("not nil")
+		}
+		pref := subscription.Preference{
+			IdentityID: identityID,
+			EventType:  p.Attributes.EventType,
+			Channel:    p.Attributes.Channel,
+			Enabled:    p.Attributes.Enabled,
+		}
+		if err := pref.Validate(); err != nil {
+			return nil, err
+		}
+		result = append(result, pref)
+	}
+	return result, nil
+}
+
+// ConvertNotificationPreferences converts from internal to external REST
+// representation
+func ConvertNotificationPreferences(preferences []subscription.Preference) *app.NotificationPreferenceList {
+	res := &app.NotificationPreferenceList{
+		Data: make([]*app.NotificationPreference, len(preferences)),
+	}
+	for i, p := range preferences {
+		id := p.EventType + ":" + p.Channel
+		res.Data[i] = &app.NotificationPreference{
+			Type: APIStringTypeNotificationPreferences,
+			ID:   &id,
+			Attributes: &app.NotificationPreferenceAttributes{
+				EventType: p.EventType,
+				Channel:   p.Channel,
+				Enabled:   p.Enabled,
+			},
+		}
+	}
+	return res
+}
@@ -0,0 +1,87 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type notificationPreferencesSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestNotificationPreferences(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &notificationPreferencesSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newUpdateNotificationPreferencesPayload(eventType, channel string, enabled bool) *app.UpdateNotificationPreferencesPayload {
+	return &app.UpdateNotificationPreferencesPayload{
+		Data: []*app.NotificationPreference{
+			{
+				Type: APIStringTypeNotificationPreferences,
+				Attributes: &app.NotificationPreferenceAttributes{
+					EventType: eventType,
+					Channel:   channel,
+					Enabled:   enabled,
+				},
+			},
+		},
+	}
+}
+
+// enabledPreferences returns the IDs of the enabled preferences
+func enabledPreferences(list *app.NotificationPreferenceList) []string {
+	result := []string{}
+	for _, p := range list.Data {
+		if p.Attributes.Enabled {
+			result = append(result, *p.ID)
+		}
+	}
+	return result
+}
+
+func (s *notificationPreferencesSuite) TestPreferences() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1))
+	svc := testsupport.ServiceAsUser("NotificationPreferences-Service", *fxt.Identities[0])
+	ctrl := NewNotificationPreferencesController(svc, s.GormDB)
+
+	s.T().Run("defaults", func(t *testing.T) {
+		// when
+		_, list := test.ListNotificationPreferencesOK(t, svc.Context, svc, ctrl)
+		// then
+		require.Len(t, list.Data, 10)
+		assert.Len(t, enabledPreferences(list), 10)
+		assert.Equal(t, "assigned:email", *list.Data[0].ID)
+	})
+
+	s.T().Run("update", func(t *testing.T) {
+		// when
+		_, list := test.UpdateNotificationPreferencesOK(t, svc.Context, svc, ctrl, newUpdateNotificationPreferencesPayload("comment", "email", false))
+		// then
+		require.Len(t, list.Data, 10)
+		assert.NotContains(t, enabledPreferences(list), "comment:email")
+		assert.Contains(t, enabledPreferences(list), "comment:web")
+		_, list = test.ListNotificationPreferencesOK(t, svc.Context, svc, ctrl)
+		assert.Len(t, enabledPreferences(list), 9)
+	})
+
+	s.T().Run("unknown event type", func(t *testing.T) {
+		test.UpdateNotificationPreferencesBadRequest(t, svc.Context, svc, ctrl, newUpdateNotificationPreferencesPayload("deleted", "email", false))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("NotificationPreferences-Service")
+		test.ListNotificationPreferencesUnauthorized(t, svc.Context, svc, NewNotificationPreferencesController(svc, s.GormDB))
+	})
+}
@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// PersonalAccessTokensController implements the personal_access_tokens resource.
+type PersonalAccessTokensController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewPersonalAccessTokensController creates a personal_access_tokens controller.
+func NewPersonalAccessTokensController(service *goa.Service, db application.DB) *PersonalAccessTokensController {
+	return &PersonalAccessTokensController{
+		Controller: service.NewController("PersonalAccessTokensController"),
+		db:         db,
+	}
+}
+
+// forbidPersonalAccessToken returns a forbidden error if the request was made
+// with a personal access token. Tokens and service accounts can only be
+// managed with a JWT from the Auth service, so that a leaked token can't be
+// used to issue more tokens.
+func forbidPersonalAccessToken(ctx context.Context) error {
+	if _, ok := account.ContextPersonalAccessTokenScopes(ctx); ok {
+		return errors.NewForbiddenError("personal access tokens and service accounts can't be managed with a personal access token")
+	}
+	return nil
+}
+
+// List runs the list action.
+func (c *PersonalAccessTokensController) List(ctx *app.ListPersonalAccessTokensContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var tokens []account.PersonalAccessToken
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		tokens, err = appl.PersonalAccessTokens().List(ctx, *currentUser)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.PersonalAccessTokenList{
+		Data: make([]*app.PersonalAccessToken, len(tokens)),
+	}
+	for i, t := range tokens {
+		res.Data[i] = ConvertPersonalAccessToken(ctx.Request, t)
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *PersonalAccessTokensController) Create(ctx *app.CreatePersonalAccessTokensContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if err := forbidPersonalAccessToken(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Name == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name", nil).Expected("not nil"))
+	}
+	if attrs.ExpiresAt != nil && !attrs.ExpiresAt.After(time.Now()) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.expires-at", *attrs.ExpiresAt).Expected("time in the future"))
+	}
+	identityID := *currentUser
+	if rel := ctx.Payload.Data.Relationships; rel != nil && rel.Identity != nil && rel.Identity.Data != nil && rel.Identity.Data.ID != nil {
+		identityID, err = uuid.FromString(*rel.Identity.Data.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.identity.data.id", *rel.Identity.Data.ID).Expected("valid UUID"))
+		}
+	}
+	t := account.PersonalAccessToken{
+		IdentityID: identityID,
+		CreatorID:  *currentUser,
+		Name:       *attrs.Name,
+		Scopes:     attrs.Scopes,
+		ExpiresAt:  attrs.ExpiresAt,
+	}
+	var plain string
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if !uuid.Equal(identityID, *currentUser) {
+			identity, err := appl.Identities().Load(ctx, identityID)
+			if err != nil {
+				return err
+			}
+			if !identity.IsServiceAccount() || !uuid.Equal(identity.ServiceAccountOwnerID.UUID, *currentUser) {
+				log.Warn(ctx, map[string]interface{}{
+					"identity_id":  identityID,
+					"current_user": *currentUser,
+				}, "not allowed to create a personal access token for the identity")
+				return errors.NewForbiddenError(fmt.Sprintf("personal access tokens can only be created for the current user or its service accounts and %s is neither", identityID))
+			}
+		}
+		plain, err = appl.PersonalAccessTokens().Create(ctx, &t)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := ConvertPersonalAccessToken(ctx.Request, t)
+	res.Attributes.Token = &plain
+	return ctx.Created(&app.PersonalAccessTokenSingle{
+		Data: res,
+	})
+}
+
+// Revoke runs the revoke action.
+func (c *PersonalAccessTokensController) Revoke(ctx *app.RevokePersonalAccessTokensContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		t, err := appl.PersonalAccessTokens().Load(ctx, ctx.TokenID)
+		if err != nil {
+			return err
+		}
+		// a token can revoke itself but it can't revoke other tokens, not even
+		// those of the same identity
+		if tokenID, ok := account.ContextPersonalAccessTokenID(ctx); ok && !uuid.Equal(tokenID, t.ID) {
+			return errors.NewForbiddenError("a personal access token can only revoke itself")
+		}
+		if !uuid.Equal(t.CreatorID, *currentUser) && !uuid.Equal(t.IdentityID, *currentUser) {
+			return errors.NewForbiddenError(fmt.Sprintf("only the creator of the personal access token %s can revoke it", t.ID))
+		}
+		return appl.PersonalAccessTokens().Revoke(ctx, t.ID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertPersonalAccessToken converts from internal to external REST
+// representation. The token itself is never stored and hence not returned.
+func ConvertPersonalAccessToken(request *http.Request, t account.PersonalAccessToken) *app.PersonalAccessToken {
+	identityID := t.IdentityID.String()
+	creatorID := t.CreatorID.String()
+	identityRelatedURL := rest.AbsoluteURL(request, app.UsersHref(identityID))
+	creatorRelatedURL := rest.AbsoluteURL(request, app.UsersHref(creatorID))
+	scopes := []string(t.Scopes)
+	if scopes == nil {
+		scopes = []string{}
+	}
+	return &app.PersonalAccessToken{
+		Type: account.APIStringTypePersonalAccessTokens,
+		ID:   &t.ID,
+		Attributes: &app.PersonalAccessTokenAttributes{
+			Name:       &t.Name,
+			Scopes:     scopes,
+			ExpiresAt:  t.ExpiresAt,
+			LastUsedAt: t.LastUsedAt,
+			RevokedAt:  t.RevokedAt,
+			CreatedAt:  &t.CreatedAt,
+		},
+		Relationships: &app.PersonalAccessTokenRelations{
+			Identity: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   &identityID,
+				},
+				Links: &app.GenericLinks{
+					Related: &identityRelatedURL,
+				},
+			},
+			Creator: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   &creatorID,
+				},
+				Links: &app.GenericLinks{
+					Related: &creatorRelatedURL,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,189 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type personalAccessTokensSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestPersonalAccessTokensSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &personalAccessTokensSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newCreatePersonalAccessTokenPayload(name string, identityID *uuid.UUID, scopes ...string) *app.CreatePersonalAccessTokensPayload {
+	payload := &app.CreatePersonalAccessTokensPayload{
+		Data: &app.PersonalAccessToken{
+			Type: account.APIStringTypePersonalAccessTokens,
+			Attributes: &app.PersonalAccessTokenAttributes{
+				Name:   &name,
+				Scopes: scopes,
+			},
+		},
+	}
+	if identityID != nil {
+		payload.Data.Relationships = &app.PersonalAccessTokenRelations{
+			Identity: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   ptr.String(identityID.String()),
+				},
+			},
+		}
+	}
+	return payload
+}
+
+func newCreateServiceAccountPayload(username string) *app.CreateServiceAccountsPayload {
+	return &app.CreateServiceAccountsPayload{
+		Data: &app.ServiceAccount{
+			Type: APIStringTypeUser,
+			Attributes: &app.ServiceAccountAttributes{
+				Username: &username,
+			},
+		},
+	}
+}
+
+func (s *personalAccessTokensSuite) TestCreate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2, tf.SetIdentityUsernames("bob", "alice")))
+	svc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.IdentityByUsername("bob"))
+	ctrl := NewPersonalAccessTokensController(svc, s.GormDB)
+
+	s.T().Run("ok", func(t *testing.T) {
+		// when
+		_, res := test.CreatePersonalAccessTokensCreated(t, svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("ci", nil, account.ScopeReadOnly))
+		// then
+		require.NotNil(t, res.Data.Attributes.Token)
+		assert.True(t, account.IsPersonalAccessToken(*res.Data.Attributes.Token))
+		assert.Equal(t, []string{account.ScopeReadOnly}, res.Data.Attributes.Scopes)
+		assert.Equal(t, fxt.IdentityByUsername("bob").ID.String(), *res.Data.Relationships.Identity.Data.ID)
+		pat, err := s.GormDB.PersonalAccessTokens().Authenticate(s.Ctx, *res.Data.Attributes.Token)
+		require.NoError(t, err)
+		assert.Equal(t, *res.Data.ID, pat.ID)
+	})
+
+	s.T().Run("for a service account", func(t *testing.T) {
+		// given
+		saSvc := testsupport.ServiceAsUser("ServiceAccounts-Service", *fxt.IdentityByUsername("bob"))
+		saCtrl := NewServiceAccountsController(saSvc, s.GormDB)
+		_, sa := test.CreateServiceAccountsCreated(t, saSvc.Context, saSvc, saCtrl, newCreateServiceAccountPayload("release-bot"))
+		// when
+		_, res := test.CreatePersonalAccessTokensCreated(t, svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("release", sa.Data.ID, account.ScopeWorkItemWrite))
+		// then
+		assert.Equal(t, sa.Data.ID.String(), *res.Data.Relationships.Identity.Data.ID)
+		assert.Equal(t, fxt.IdentityByUsername("bob").ID.String(), *res.Data.Relationships.Creator.Data.ID)
+	})
+
+	s.T().Run("for another user", func(t *testing.T) {
+		test.CreatePersonalAccessTokensForbidden(t, svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("ci", &fxt.IdentityByUsername("alice").ID, account.ScopeReadOnly))
+	})
+
+	s.T().Run("unknown scope", func(t *testing.T) {
+		test.CreatePersonalAccessTokensBadRequest(t, svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("ci", nil, "foo"))
+	})
+
+	s.T().Run("expired", func(t *testing.T) {
+		payload := newCreatePersonalAccessTokenPayload("ci", nil, account.ScopeReadOnly)
+		expiresAt := time.Now().Add(-time.Hour)
+		payload.Data.Attributes.ExpiresAt = &expiresAt
+		test.CreatePersonalAccessTokensBadRequest(t, svc.Context, svc, ctrl, payload)
+	})
+
+	s.T().Run("with a personal access token", func(t *testing.T) {
+		patSvc := testsupport.ServiceAsPersonalAccessTokenUser("PersonalAccessTokens-Service", account.PersonalAccessToken{
+			ID:         uuid.NewV4(),
+			IdentityID: fxt.IdentityByUsername("bob").ID,
+			Scopes:     []string{account.ScopeSpaceAdmin},
+		})
+		test.CreatePersonalAccessTokensForbidden(t, patSvc.Context, patSvc, ctrl, newCreatePersonalAccessTokenPayload("ci", nil, account.ScopeSpaceAdmin))
+	})
+}
+
+func (s *personalAccessTokensSuite) TestListAndRevoke() {
+	// given
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2, tf.SetIdentityUsernames("bob", "alice")))
+	svc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.IdentityByUsername("bob"))
+	ctrl := NewPersonalAccessTokensController(svc, s.GormDB)
+	_, created := test.CreatePersonalAccessTokensCreated(s.T(), svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("ci", nil, account.ScopeReadOnly))
+
+	s.T().Run("list", func(t *testing.T) {
+		_, res := test.ListPersonalAccessTokensOK(t, svc.Context, svc, ctrl)
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, *created.Data.ID, *res.Data[0].ID)
+		assert.Nil(t, res.Data[0].Attributes.Token, "the token must only be returned on creation")
+	})
+
+	s.T().Run("other users can't revoke", func(t *testing.T) {
+		aliceSvc := testsupport.ServiceAsUser("PersonalAccessTokens-Service", *fxt.IdentityByUsername("alice"))
+		test.RevokePersonalAccessTokensForbidden(t, aliceSvc.Context, aliceSvc, ctrl, *created.Data.ID)
+	})
+
+	s.T().Run("a token can only revoke itself", func(t *testing.T) {
+		// given
+		_, other := test.CreatePersonalAccessTokensCreated(t, svc.Context, svc, ctrl, newCreatePersonalAccessTokenPayload("deploy", nil, account.ScopeReadOnly))
+		pat, err := s.GormDB.PersonalAccessTokens().Load(s.Ctx, *other.Data.ID)
+		require.NoError(t, err)
+		patSvc := testsupport.ServiceAsPersonalAccessTokenUser("PersonalAccessTokens-Service", *pat)
+		// when/then
+		test.RevokePersonalAccessTokensForbidden(t, patSvc.Context, patSvc, ctrl, *created.Data.ID)
+		test.RevokePersonalAccessTokensNoContent(t, patSvc.Context, patSvc, ctrl, *other.Data.ID)
+	})
+
+	s.T().Run("revoke", func(t *testing.T) {
+		// when
+		test.RevokePersonalAccessTokensNoContent(t, svc.Context, svc, ctrl, *created.Data.ID)
+		// then
+		_, res := test.ListPersonalAccessTokensOK(t, svc.Context, svc, ctrl)
+		require.Len(t, res.Data, 2)
+		for _, tok := range res.Data {
+			assert.NotNil(t, tok.Attributes.RevokedAt)
+		}
+		_, err := s.GormDB.PersonalAccessTokens().Authenticate(s.Ctx, *created.Data.Attributes.Token)
+		require.Error(t, err)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		test.RevokePersonalAccessTokensNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
+
+func (s *personalAccessTokensSuite) TestServiceAccounts() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2, tf.SetIdentityUsernames("bob", "alice")))
+	svc := testsupport.ServiceAsUser("ServiceAccounts-Service", *fxt.IdentityByUsername("bob"))
+	ctrl := NewServiceAccountsController(svc, s.GormDB)
+
+	s.T().Run("create and list", func(t *testing.T) {
+		// when
+		_, created := test.CreateServiceAccountsCreated(t, svc.Context, svc, ctrl, newCreateServiceAccountPayload("deploy-bot"))
+		// then
+		assert.Equal(t, "deploy-bot", *created.Data.Attributes.Username)
+		_, res := test.ListServiceAccountsOK(t, svc.Context, svc, ctrl)
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, *created.Data.ID, *res.Data[0].ID)
+		aliceSvc := testsupport.ServiceAsUser("ServiceAccounts-Service", *fxt.IdentityByUsername("alice"))
+		_, res = test.ListServiceAccountsOK(t, aliceSvc.Context, aliceSvc, ctrl)
+		assert.Empty(t, res.Data, "service accounts must only be listed for their owner")
+	})
+
+	s.T().Run("username taken", func(t *testing.T) {
+		test.CreateServiceAccountsConflict(t, svc.Context, svc, ctrl, newCreateServiceAccountPayload("alice"))
+	})
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// IterationReportController implements the iteration_report resource.
+type IterationReportController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewIterationReportController creates an iteration_report controller.
+func NewIterationReportController(service *goa.Service, db application.DB) *IterationReportController {
+	return &IterationReportController{
+		Controller: service.NewController("IterationReportController"),
+		db:         db,
+	}
+}
+
+// Burndown runs the burndown action.
+func (c *IterationReportController) Burndown(ctx *app.BurndownIterationReportContext) error {
+	var burndown *report.Burndown
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		burndown, err = appl.Reports().Burndown(ctx, ctx.IterationID, ctx.Field)
+		return errs.Wrapf(err, "failed to compute the burndown of iteration %s", ctx.IterationID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.BurndownSingle{
+		Data: ConvertBurndown(ctx.Request, *burndown),
+	})
+}
+
+// SpaceReportController implements the space_report resource.
+type SpaceReportController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceReportController creates a space_report controller.
+func NewSpaceReportController(service *goa.Service, db application.DB) *SpaceReportController {
+	return &SpaceReportController{
+		Controller: service.NewController("SpaceReportController"),
+		db:         db,
+	}
+}
+
+// Velocity runs the velocity action.
+func (c *SpaceReportController) Velocity(ctx *app.VelocitySpaceReportContext) error {
+	var velocity *report.Velocity
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		velocity, err = appl.Reports().Velocity(ctx, ctx.SpaceID, ctx.Field)
+		return errs.Wrapf(err, "failed to compute the velocity of space %s", ctx.SpaceID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.VelocitySingle{
+		Data: ConvertVelocity(ctx.Request, *velocity),
+	})
+}
+
+// ConvertBurndown converts from internal to external REST representation
+func ConvertBurndown(request *http.Request, burndown report.Burndown) *app.Burndown {
+	selfURL := rest.AbsoluteURL(request, app.IterationHref(burndown.IterationID)+"/burndown")
+	relatedURL := rest.AbsoluteURL(request, app.IterationHref(burndown.IterationID))
+	points := make([]*app.BurndownPoint, len(burndown.Points))
+	for i, p := range burndown.Points {
+		points[i] = &app.BurndownPoint{
+			Date:           p.Date,
+			TotalCount:     p.TotalCount,
+			TotalValue:     p.TotalValue,
+			RemainingCount: p.RemainingCount,
+			RemainingValue: p.RemainingValue,
+		}
+	}
+	return &app.Burndown{
+		Type: report.APIStringTypeBurndown,
+		ID:   &burndown.IterationID,
+		Attributes: &app.BurndownAttributes{
+			Field:   burndown.Field,
+			StartAt: burndown.StartAt,
+			EndAt:   burndown.EndAt,
+			Points:  points,
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &relatedURL,
+		},
+	}
+}
+
+// ConvertVelocity converts from internal to external REST representation
+func ConvertVelocity(request *http.Request, velocity report.Velocity) *app.Velocity {
+	selfURL := rest.AbsoluteURL(request, app.SpaceHref(velocity.SpaceID)+"/velocity")
+	relatedURL := rest.AbsoluteURL(request, app.SpaceHref(velocity.SpaceID))
+	iterations := make([]*app.IterationVelocity, len(velocity.Iterations))
+	for i, v := range velocity.Iterations {
+		iterations[i] = &app.IterationVelocity{
+			ID:             v.IterationID,
+			Name:           v.Name,
+			StartAt:        v.StartAt,
+			EndAt:          v.EndAt,
+			CommittedCount: v.CommittedCount,
+			CommittedValue: v.CommittedValue,
+			CompletedCount: v.CompletedCount,
+			CompletedValue: v.CompletedValue,
+		}
+	}
+	return &app.Velocity{
+		Type: report.APIStringTypeVelocity,
+		ID:   &velocity.SpaceID,
+		Attributes: &app.VelocityAttributes{
+			Field:        velocity.Field,
+			Iterations:   iterations,
+			AverageCount: velocity.AverageCount,
+			AverageValue: velocity.AverageValue,
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &relatedURL,
+		},
+	}
+}
@@ -0,0 +1,91 @@
+package controller_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestReportsREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunReportsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestReportsREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *TestReportsREST) TestBurndownAndVelocity() {
+	// given a closed iteration that started yesterday with two work items of
+	// which one is closed
+	startAt := time.Now().Add(-24 * time.Hour)
+	endAt := time.Now().Add(time.Hour)
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Iterations(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Iterations[idx].StartAt = &startAt
+			fxt.Iterations[idx].EndAt = &endAt
+			fxt.Iterations[idx].State = iteration.StateClose
+			return nil
+		}),
+		tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.Iterations[0].ID.String()
+			if idx == 0 {
+				fxt.WorkItems[idx].Fields[workitem.SystemState] = workitem.SystemStateClosed
+			}
+			return nil
+		}),
+	)
+	svc := goa.New("Reports-Service")
+
+	s.T().Run("burndown", func(t *testing.T) {
+		ctrl := NewIterationReportController(svc, s.GormDB)
+		_, burndown := test.BurndownIterationReportOK(t, svc.Context, svc, ctrl, fxt.Iterations[0].ID, report.DefaultField)
+		require.NotNil(t, burndown.Data.Attributes)
+		assert.Equal(t, fxt.Iterations[0].ID, *burndown.Data.ID)
+		assert.Equal(t, report.DefaultField, burndown.Data.Attributes.Field)
+		require.NotEmpty(t, burndown.Data.Attributes.Points)
+		last := burndown.Data.Attributes.Points[len(burndown.Data.Attributes.Points)-1]
+		assert.Equal(t, 2, last.TotalCount)
+		assert.Equal(t, 1, last.RemainingCount)
+	})
+
+	s.T().Run("burndown of unknown iteration", func(t *testing.T) {
+		ctrl := NewIterationReportController(svc, s.GormDB)
+		test.BurndownIterationReportNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), report.DefaultField)
+	})
+
+	s.T().Run("burndown of iteration without dates", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Iterations(1))
+		ctrl := NewIterationReportController(svc, s.GormDB)
+		test.BurndownIterationReportBadRequest(t, svc.Context, svc, ctrl, fxt.Iterations[0].ID, report.DefaultField)
+	})
+
+	s.T().Run("velocity", func(t *testing.T) {
+		ctrl := NewSpaceReportController(svc, s.GormDB)
+		_, velocity := test.VelocitySpaceReportOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, report.DefaultField)
+		require.NotNil(t, velocity.Data.Attributes)
+		assert.Equal(t, fxt.Spaces[0].ID, *velocity.Data.ID)
+		require.Len(t, velocity.Data.Attributes.Iterations, 1)
+		assert.Equal(t, fxt.Iterations[0].ID, velocity.Data.Attributes.Iterations[0].ID)
+		assert.Equal(t, 1, velocity.Data.Attributes.Iterations[0].CompletedCount)
+		assert.Equal(t, float64(1), velocity.Data.Attributes.AverageCount)
+	})
+
+	s.T().Run("velocity of unknown space", func(t *testing.T) {
+		ctrl := NewSpaceReportController(svc, s.GormDB)
+		test.VelocitySpaceReportNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), report.DefaultField)
+	})
+}
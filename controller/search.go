@@ -153,8 +153,25 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 		var ancestors link.AncestorList
 		var childLinks link.WorkItemLinkList
 		err := application.Transactional(c.db, func(appl application.Application) error {
-			var err error
-			result, count, ancestors, childLinks, err = appl.SearchItems().Filter(ctx.Context, *ctx.FilterExpression, ctx.FilterParentexists, &offset, &limit)
+			// custom fields are looked up in the work item types of the
+			// space to which the filter is restricted
+			var fields workitem.FieldDefinitionLoader
+			spaceID, err := search.FilterSpaceID(ctx, *ctx.FilterExpression)
+			if err != nil {
+				return err
+			}
+			if spaceID != nil {
+				s, err := appl.Spaces().Load(ctx, *spaceID)
+				if err != nil {
+					return err
+				}
+				fields = workitem.SpaceTemplateFields{Types: appl.WorkItemTypes(), SpaceTemplateID: s.SpaceTemplateID}
+			}
+			sortOrder, err := workitem.ParseSortWorkItemsBy(ctx, ctx.Sort, fields)
+			if err != nil {
+				return err
+			}
+			result, count, ancestors, childLinks, err = appl.SearchItems().Filter(ctx.Context, *ctx.FilterExpression, ctx.FilterParentexists, &offset, &limit, sortOrder)
 			if err != nil {
 				cause := errs.Cause(err)
 				switch cause.(type) {
@@ -201,12 +218,19 @@ func (c *SearchController) Show(ctx *app.ShowSearchContext) error {
 		if err != nil {
 			return errs.Wrap(err, "failed to enrich work item list")
 		}
-		setPagingLinks(response.Links, buildAbsoluteURL(ctx.Request), len(result), offset, limit, count, "filter[expression]="+*ctx.FilterExpression)
-
-		// Sort "data" by name or ID if no title given
-		var data WorkItemPtrSlice = response.Data
-		sort.Sort(data)
-		response.Data = data
+		additionalQuery := []string{"filter[expression]=" + *ctx.FilterExpression}
+		if ctx.Sort != nil {
+			additionalQuery = append(additionalQuery, "sort="+*ctx.Sort)
+		}
+		setPagingLinks(response.Links, buildAbsoluteURL(ctx.Request), len(result), offset, limit, count, additionalQuery...)
+
+		// Sort "data" by name or ID if no title given, unless an explicit sort
+		// order was requested which has already been applied by the database.
+		if ctx.Sort == nil {
+			var data WorkItemPtrSlice = response.Data
+			sort.Sort(data)
+			response.Data = data
+		}
 
 		// Sort work items in the "included" array by ID or title
 		var included WorkItemInterfaceSlice = response.Included
@@ -0,0 +1,98 @@
+package controller
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/goadesign/goa"
+)
+
+// ServiceAccountsController implements the service_accounts resource.
+type ServiceAccountsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewServiceAccountsController creates a service_accounts controller.
+func NewServiceAccountsController(service *goa.Service, db application.DB) *ServiceAccountsController {
+	return &ServiceAccountsController{
+		Controller: service.NewController("ServiceAccountsController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *ServiceAccountsController) List(ctx *app.ListServiceAccountsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var identities []account.Identity
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		identities, err = appl.Identities().Query(account.IdentityFilterByServiceAccountOwner(*currentUser))
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.ServiceAccountList{
+		Data: make([]*app.ServiceAccount, len(identities)),
+	}
+	for i, identity := range identities {
+		res.Data[i] = ConvertServiceAccount(identity)
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *ServiceAccountsController) Create(ctx *app.CreateServiceAccountsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if err := forbidPersonalAccessToken(ctx); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil || ctx.Payload.Data.Attributes.Username == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.username", nil).Expected("not nil"))
+	}
+	username := strings.TrimSpace(*ctx.Payload.Data.Attributes.Username)
+	if username == "" {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.username", username).Expected("non empty string"))
+	}
+	identity := account.NewServiceAccount(username, *currentUser)
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		existing, err := appl.Identities().Query(account.IdentityFilterByUsername(username))
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return errors.NewDataConflictError(fmt.Sprintf("an identity with the username %s already exists", username))
+		}
+		return appl.Identities().Create(ctx, &identity)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.Created(&app.ServiceAccountSingle{
+		Data: ConvertServiceAccount(identity),
+	})
+}
+
+// ConvertServiceAccount converts from internal to external REST representation
+func ConvertServiceAccount(identity account.Identity) *app.ServiceAccount {
+	return &app.ServiceAccount{
+		Type: APIStringTypeUser,
+		ID:   &identity.ID,
+		Attributes: &app.ServiceAccountAttributes{
+			Username:  &identity.Username,
+			CreatedAt: &identity.CreatedAt,
+		},
+	}
+}
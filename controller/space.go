@@ -20,6 +20,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
 
 	"github.com/goadesign/goa"
@@ -236,7 +237,7 @@ func (c *SpaceController) Delete(ctx *app.DeleteSpaceContext) error {
 		if err != nil {
 			return err
 		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
+		if !authz.AuthorizeOwner(ctx, *currentUser, s.OwnerID, authz.PermissionManageSpace) {
 			log.Warn(ctx, map[string]interface{}{
 				"space_id":     ctx.SpaceID,
 				"space_owner":  s.OwnerID,
@@ -518,7 +519,7 @@ func (c *SpaceController) Update(ctx *app.UpdateSpaceContext) error {
 			return err
 		}
 
-		if !uuid.Equal(*currentUser, s.OwnerID) {
+		if !authz.AuthorizeOwner(ctx, *currentUser, s.OwnerID, authz.PermissionManageSpace) {
 			log.Error(ctx, map[string]interface{}{"currentUser": *currentUser, "owner": s.OwnerID}, "Current user is not owner")
 			return goa.NewErrorClass("forbidden", 403)("User is not the space owner")
 		}
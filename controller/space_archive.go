@@ -0,0 +1,183 @@
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/archive"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeSpaceImport contains the JSON API type for space imports
+const APIStringTypeSpaceImport = "spaceimports"
+
+// errDryRun makes the transaction of a dry run import roll back
+var errDryRun = errs.New("dry run")
+
+// SpaceArchiveControllerConfiguration the configuration for the space archive
+// controller
+type SpaceArchiveControllerConfiguration interface {
+	GetSpaceArchiveMaxSize() int64
+}
+
+// SpaceArchiveController implements the space_archive resource.
+type SpaceArchiveController struct {
+	*goa.Controller
+	db     application.DB
+	config SpaceArchiveControllerConfiguration
+}
+
+// NewSpaceArchiveController creates a space_archive controller.
+func NewSpaceArchiveController(service *goa.Service, db application.DB, config SpaceArchiveControllerConfiguration) *SpaceArchiveController {
+	return &SpaceArchiveController{
+		Controller: service.NewController("SpaceArchiveController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// exportWriter sends the response header of an export right before the first
+// byte of the archive. An export which fails before can still respond with an
+// error.
+type exportWriter struct {
+	rw      *goa.ResponseData
+	spaceID uuid.UUID
+	written bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.written {
+		w.rw.Header().Set("Content-Type", "application/x-ndjson")
+		w.rw.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"space-%s.ndjson\"", w.spaceID))
+		w.rw.WriteHeader(http.StatusOK)
+		w.written = true
+	}
+	return w.rw.Write(p)
+}
+
+// Export runs the export action.
+func (c *SpaceArchiveController) Export(ctx *app.ExportSpaceArchiveContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	// the archive is streamed to the client while it is read from the
+	// database, the transaction keeps the archive consistent
+	w := &exportWriter{rw: ctx.ResponseData, spaceID: ctx.SpaceID}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		if !authz.AuthorizeOwner(ctx, *currentUser, s.OwnerID, authz.PermissionManageSpace) {
+			return errors.NewForbiddenError("only the owner of the space can export it")
+		}
+		return appl.SpaceArchives().Export(ctx, ctx.SpaceID, w)
+	})
+	if err != nil && !w.written {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if err != nil {
+		// the response is under way and can only be cut short
+		log.Error(ctx, map[string]interface{}{
+			"space_id": ctx.SpaceID,
+			"err":      err,
+		}, "failed to export the space, the archive is incomplete")
+	}
+	return nil
+}
+
+// Import runs the import action.
+func (c *SpaceArchiveController) Import(ctx *app.ImportSpaceArchiveContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Request.Body == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("body", nil).Expected("space archive"))
+	}
+	defer ctx.Request.Body.Close()
+	var result *archive.ImportResult
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		result, err = appl.SpaceArchives().Import(ctx, ctx.Request.Body, archive.ImportOptions{
+			OwnerID: *currentUser,
+			Name:    ctx.Name,
+			MaxSize: c.config.GetSpaceArchiveMaxSize(),
+		})
+		if err != nil {
+			return err
+		}
+		if ctx.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && errs.Cause(err) != errDryRun {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"space_id": result.SpaceID,
+		"dry_run":  ctx.DryRun,
+	}, "space archive imported")
+	res := &app.SpaceImportSingle{
+		Data: ConvertSpaceImport(ctx.Request, *result, ctx.DryRun),
+	}
+	if ctx.DryRun {
+		return ctx.OK(res)
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.SpaceHref(result.SpaceID)))
+	return ctx.Created(res)
+}
+
+// ConvertSpaceImport converts the result of a space import into its JSON API
+// representation. The space is left out for a dry run because it was never
+// kept.
+func ConvertSpaceImport(request *http.Request, result archive.ImportResult, dryRun bool) *app.SpaceImport {
+	counts := make(map[string]int, len(result.Counts))
+	for kind, n := range result.Counts {
+		counts[string(kind)] = n
+	}
+	renumbered := make(map[string]int, len(result.RenumberedWorkItems))
+	for from, to := range result.RenumberedWorkItems {
+		renumbered[strconv.Itoa(from)] = to
+	}
+	res := &app.SpaceImport{
+		Type: APIStringTypeSpaceImport,
+		Attributes: &app.SpaceImportAttributes{
+			DryRun:              dryRun,
+			Counts:              counts,
+			ReplacedIdentities:  result.ReplacedIdentities,
+			RenumberedWorkitems: renumbered,
+		},
+	}
+	if dryRun {
+		return res
+	}
+	spaceID := result.SpaceID
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	res.ID = &spaceID
+	res.Relationships = &app.SpaceImportRelations{
+		Space: &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APIStringTypeSpace),
+				ID:   ptr.String(spaceID.String()),
+			},
+			Links: &app.GenericLinks{
+				Related: &spaceRelatedURL,
+			},
+		},
+	}
+	return res
+}
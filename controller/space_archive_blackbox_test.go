@@ -0,0 +1,48 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type spaceArchiveSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSpaceArchiveSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &spaceArchiveSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *spaceArchiveSuite) TestExport() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2, tf.SetIdentityUsernames("bob", "alice")), tf.Spaces(1), tf.WorkItems(2))
+
+	s.T().Run("ok", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceArchive-Service", *fxt.IdentityByUsername("bob"))
+		test.ExportSpaceArchiveOK(t, svc.Context, svc, NewSpaceArchiveController(svc, s.GormDB, s.Configuration), fxt.Spaces[0].ID)
+	})
+
+	s.T().Run("not the owner", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceArchive-Service", *fxt.IdentityByUsername("alice"))
+		test.ExportSpaceArchiveForbidden(t, svc.Context, svc, NewSpaceArchiveController(svc, s.GormDB, s.Configuration), fxt.Spaces[0].ID)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("SpaceArchive-Service", *fxt.IdentityByUsername("bob"))
+		test.ExportSpaceArchiveNotFound(t, svc.Context, svc, NewSpaceArchiveController(svc, s.GormDB, s.Configuration), uuid.NewV4())
+	})
+}
+
+func (s *spaceArchiveSuite) TestImportWithoutArchive() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1))
+	svc := testsupport.ServiceAsUser("SpaceArchive-Service", *fxt.Identities[0])
+	test.ImportSpaceArchiveBadRequest(s.T(), svc.Context, svc, NewSpaceArchiveController(svc, s.GormDB, s.Configuration), false, nil)
+}
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// APIBoardColumnStates is the type constant used when referring to the number
+// of work items in a board column in JSONAPI
+var APIBoardColumnStates = "boardcolumnstates"
+
+// SpaceBoardStateController implements the space_board_state resource.
+type SpaceBoardStateController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceBoardStateController creates a space_board_state controller.
+func NewSpaceBoardStateController(service *goa.Service, db application.DB) *SpaceBoardStateController {
+	return &SpaceBoardStateController{
+		Controller: service.NewController("SpaceBoardStateController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceBoardStateController) List(ctx *app.ListSpaceBoardStateContext) error {
+	var boards []*workitem.Board
+	var counts map[string]int
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load space %s", ctx.SpaceID)
+		}
+		boards, err = appl.Boards().List(ctx, s.SpaceTemplateID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to list boards of space template %s", s.SpaceTemplateID)
+		}
+		counts, err = appl.WorkItems().GetCountsPerBoardColumn(ctx, ctx.SpaceID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to count work items per board column in space %s", ctx.SpaceID)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.BoardColumnStateList{
+		Data: []*app.BoardColumnStateData{},
+	}
+	for _, board := range boards {
+		for _, column := range board.Columns {
+			res.Data = append(res.Data, ConvertBoardColumnState(column, counts[column.ID.String()]))
+		}
+	}
+	return ctx.OK(res)
+}
+
+// ConvertBoardColumnState converts a board column and the number of work items
+// in it to a response resource object for jsonapi.org specification
+func ConvertBoardColumnState(column workitem.BoardColumn, count int) *app.BoardColumnStateData {
+	return &app.BoardColumnStateData{
+		ID:   column.ID,
+		Type: APIBoardColumnStates,
+		Attributes: &app.BoardColumnStateAttributes{
+			Name:             column.Name,
+			Order:            column.Order,
+			Count:            count,
+			WipLimit:         column.WIPLimit,
+			WipLimitEnforced: column.WIPLimitEnforced,
+			WipLimitExceeded: column.WIPLimit > 0 && count > column.WIPLimit,
+		},
+		Relationships: &app.BoardColumnStateRelationships{
+			Board: &app.RelationGeneric{
+				Data: &app.GenericData{
+					ID:   ptr.String(column.BoardID.String()),
+					Type: &APIWorkItemBoards,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type spaceBoardStateSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSpaceBoardStateSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &spaceBoardStateSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *spaceBoardStateSuite) TestList() {
+	svc := goa.New("SpaceBoardState-Service")
+	ctrl := NewSpaceBoardStateController(svc, gormapplication.NewGormDB(s.DB))
+
+	s.T().Run("ok", func(t *testing.T) {
+		// given a board whose first column allows only one work item
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItemBoards(1, func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItemBoards[idx].Columns[0].WIPLimit = 1
+				return nil
+			}),
+			tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItems[idx].Fields[workitem.SystemBoardcolumns] = []interface{}{fxt.WorkItemBoards[0].Columns[0].ID.String()}
+				return nil
+			}),
+		)
+		// when
+		_, res := test.ListSpaceBoardStateOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID)
+		// then
+		columns := fxt.WorkItemBoards[0].Columns
+		require.Len(t, res.Data, len(columns))
+		for i, column := range columns {
+			assert.Equal(t, column.ID, res.Data[i].ID)
+			assert.Equal(t, fxt.WorkItemBoards[0].ID.String(), *res.Data[i].Relationships.Board.Data.ID)
+		}
+		assert.Equal(t, 2, res.Data[0].Attributes.Count)
+		assert.Equal(t, 1, res.Data[0].Attributes.WipLimit)
+		assert.True(t, res.Data[0].Attributes.WipLimitExceeded)
+		assert.Equal(t, 0, res.Data[1].Attributes.Count)
+		assert.False(t, res.Data[1].Attributes.WipLimitExceeded)
+	})
+
+	s.T().Run("space not found", func(t *testing.T) {
+		test.ListSpaceBoardStateNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 
 	"github.com/goadesign/goa"
 )
@@ -52,7 +53,7 @@ func (c *SpaceCodebasesController) Create(ctx *app.CreateSpaceCodebasesContext)
 		if err != nil {
 			return err
 		}
-		if *identityID != sp.OwnerID {
+		if !authz.AuthorizeOwner(ctx, *identityID, sp.OwnerID, authz.PermissionManageSpace) {
 			return errors.NewForbiddenError("user is not the space owner")
 		}
 		cdb = &codebase.Codebase{
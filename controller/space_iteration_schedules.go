@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// defaultIterationScheduleLookaheadDays is the lookahead of an iteration
+// schedule when none is given
+const defaultIterationScheduleLookaheadDays = 30
+
+// SpaceIterationSchedulesController implements the space_iteration_schedules resource.
+type SpaceIterationSchedulesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceIterationSchedulesController creates a space_iteration_schedules controller.
+func NewSpaceIterationSchedulesController(service *goa.Service, db application.DB) *SpaceIterationSchedulesController {
+	return &SpaceIterationSchedulesController{
+		Controller: service.NewController("SpaceIterationSchedulesController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceIterationSchedulesController) List(ctx *app.ListSpaceIterationSchedulesContext) error {
+	var schedules []schedule.Schedule
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := appl.Spaces().Load(ctx, ctx.SpaceID); err != nil {
+			return err
+		}
+		var err error
+		schedules, err = appl.IterationSchedules().List(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.IterationScheduleList{
+		Data: []*app.IterationSchedule{},
+		Meta: &app.ListMeta{
+			TotalCount: len(schedules),
+		},
+	}
+	for _, s := range schedules {
+		res.Data = append(res.Data, ConvertIterationSchedule(ctx.Request, s))
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *SpaceIterationSchedulesController) Create(ctx *app.CreateSpaceIterationSchedulesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.StartAt == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.start-at", nil).Expected("not nil"))
+	}
+	if attrs.CadenceDays == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.cadence-days", nil).Expected("not nil"))
+	}
+	if attrs.NamePattern == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.name-pattern", nil).Expected("not nil"))
+	}
+	s := schedule.Schedule{
+		SpaceID:       ctx.SpaceID,
+		StartAt:       *attrs.StartAt,
+		CadenceDays:   *attrs.CadenceDays,
+		LengthDays:    *attrs.CadenceDays,
+		NamePattern:   *attrs.NamePattern,
+		LookaheadDays: defaultIterationScheduleLookaheadDays,
+		Active:        true,
+	}
+	if attrs.LengthDays != nil {
+		s.LengthDays = *attrs.LengthDays
+	}
+	if attrs.LookaheadDays != nil {
+		s.LookaheadDays = *attrs.LookaheadDays
+	}
+	if attrs.Active != nil {
+		s.Active = *attrs.Active
+	}
+	var parentID *uuid.UUID
+	if rel := ctx.Payload.Data.Relationships; rel != nil && rel.Parent != nil && rel.Parent.Data != nil && rel.Parent.Data.ID != nil {
+		id, err := uuid.FromString(*rel.Parent.Data.ID)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.relationships.parent.data.id", *rel.Parent.Data.ID).Expected("valid UUID"))
+		}
+		parentID = &id
+	}
+	var created *schedule.Schedule
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeIterationScheduleManager(ctx, appl, ctx.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		var parent *iteration.Iteration
+		if parentID != nil {
+			parent, err = appl.Iterations().Load(ctx, *parentID)
+			if err != nil {
+				return err
+			}
+			if parent.SpaceID != ctx.SpaceID {
+				return errors.NewBadParameterError("data.relationships.parent.data.id", *parentID).Expected("iteration of space " + ctx.SpaceID.String())
+			}
+		} else {
+			parent, err = appl.Iterations().Root(ctx, ctx.SpaceID)
+			if err != nil {
+				return err
+			}
+		}
+		s.ParentIterationID = parent.ID
+		if err := appl.IterationSchedules().Create(ctx, &s); err != nil {
+			return err
+		}
+		created, err = generateScheduledIterations(ctx, appl, s.ID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.IterationScheduleSingle{
+		Data: ConvertIterationSchedule(ctx.Request, *created),
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.IterationScheduleHref(created.ID)))
+	return ctx.Created(res)
+}
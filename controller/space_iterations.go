@@ -9,8 +9,8 @@ import (
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
 	"github.com/fabric8-services/fabric8-wit/workitem"
-	uuid "github.com/satori/go.uuid"
 
 	"github.com/goadesign/goa"
 )
@@ -53,7 +53,11 @@ func (c *SpaceIterationsController) Create(ctx *app.CreateSpaceIterationsContext
 		if err != nil {
 			return goa.ErrNotFound(err.Error())
 		}
-		if !uuid.Equal(*currentUser, s.OwnerID) {
+		authorized, err := authorizeSpacePermission(ctx, *currentUser, *s, authz.PermissionManageIterations)
+		if err != nil {
+			return errors.NewUnauthorizedError(err.Error())
+		}
+		if !authorized {
 			log.Warn(ctx, map[string]interface{}{
 				"space_id":     ctx.SpaceID,
 				"space_owner":  s.OwnerID,
@@ -0,0 +1,175 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+// spacePermissionsSuite checks that the controllers enforce the permissions
+// granted by the roles of users in a space
+type spacePermissionsSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSpacePermissionsSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &spacePermissionsSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *spacePermissionsSuite) TestSpaceIterations() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.CreateWorkItemEnvironment(), tf.Identities(3, tf.SetIdentityUsernames("owner", "planner", "contributor")))
+	roles := authz.NewLocalRoleService().
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("planner").ID, authz.RolePlanner).
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("contributor").ID, authz.RoleContributor)
+
+	s.T().Run("planner", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Iteration-Service", *fxt.IdentityByUsername("planner"), roles)
+		ctrl := NewSpaceIterationsController(svc, s.GormDB, s.Configuration)
+		test.CreateSpaceIterationsCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceIterationPayload("Sprint #1", nil))
+	})
+
+	s.T().Run("contributor", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Iteration-Service", *fxt.IdentityByUsername("contributor"), roles)
+		ctrl := NewSpaceIterationsController(svc, s.GormDB, s.Configuration)
+		test.CreateSpaceIterationsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceIterationPayload("Sprint #2", nil))
+	})
+}
+
+func (s *spacePermissionsSuite) TestAreas() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Areas(1), tf.Identities(3, tf.SetIdentityUsernames("owner", "planner", "contributor")))
+	roles := authz.NewLocalRoleService().
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("planner").ID, authz.RolePlanner).
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("contributor").ID, authz.RoleContributor)
+
+	s.T().Run("planner", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Area-Service", *fxt.IdentityByUsername("planner"), roles)
+		ctrl := NewAreaController(svc, s.GormDB, s.Configuration)
+		test.CreateChildAreaCreated(t, svc.Context, svc, ctrl, fxt.Areas[0].ID.String(), newCreateChildAreaPayload("Frontend"))
+	})
+
+	s.T().Run("contributor", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Area-Service", *fxt.IdentityByUsername("contributor"), roles)
+		ctrl := NewAreaController(svc, s.GormDB, s.Configuration)
+		test.CreateChildAreaForbidden(t, svc.Context, svc, ctrl, fxt.Areas[0].ID.String(), newCreateChildAreaPayload("Backend"))
+	})
+}
+
+func (s *spacePermissionsSuite) TestLabels() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1), tf.Identities(3, tf.SetIdentityUsernames("owner", "viewer", "contributor")))
+	roles := authz.NewLocalRoleService().
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("viewer").ID, authz.RoleViewer).
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("contributor").ID, authz.RoleContributor)
+	payload := func(name string) *app.CreateLabelPayload {
+		return &app.CreateLabelPayload{
+			Data: &app.Label{
+				Attributes: &app.LabelAttributes{Name: &name},
+				Type:       label.APIStringTypeLabels,
+			},
+		}
+	}
+
+	s.T().Run("contributor", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Label-Service", *fxt.IdentityByUsername("contributor"), roles)
+		ctrl := NewLabelController(svc, s.GormDB, s.Configuration)
+		test.CreateLabelCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload("bug"))
+	})
+
+	s.T().Run("viewer", func(t *testing.T) {
+		svc := testsupport.ServiceAsSpaceUser("Label-Service", *fxt.IdentityByUsername("viewer"), roles)
+		ctrl := NewLabelController(svc, s.GormDB, s.Configuration)
+		test.CreateLabelForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload("feature"))
+	})
+}
+
+func (s *spacePermissionsSuite) TestWorkItems() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.CreateWorkItemEnvironment(), tf.WorkItems(1), tf.WorkItemBoards(1), tf.Identities(2, tf.SetIdentityUsernames("owner", "viewer")))
+	roles := authz.NewLocalRoleService().
+		Assign(fxt.Spaces[0].ID, fxt.IdentityByUsername("viewer").ID, authz.RoleViewer)
+	svc := testsupport.ServiceAsSpaceUser("Workitem-Service", *fxt.IdentityByUsername("viewer"), roles)
+	ctrl := NewWorkitemController(svc, s.GormDB, s.Configuration)
+
+	s.T().Run("viewer cannot update", func(t *testing.T) {
+		u := app.UpdateWorkitemPayload{
+			Data: &app.WorkItem{
+				ID:   &fxt.WorkItems[0].ID,
+				Type: APIStringTypeWorkItem,
+				Attributes: map[string]interface{}{
+					"version":            fxt.WorkItems[0].Version,
+					workitem.SystemTitle: "updated title",
+				},
+			},
+		}
+		test.UpdateWorkitemForbidden(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+	})
+
+	s.T().Run("viewer cannot delete", func(t *testing.T) {
+		test.DeleteWorkitemForbidden(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID)
+	})
+
+	columns := &app.RelationGenericList{
+		Data: []*app.GenericData{{
+			ID:   ptr.String(fxt.WorkItemBoards[0].Columns[0].ID.String()),
+			Type: ptr.String("boardcolumns"),
+		}},
+	}
+	s.T().Run("creator without role cannot move on boards", func(t *testing.T) {
+		// the creator may edit the work item but needs the permission to move
+		// it on boards
+		svc := testsupport.ServiceAsSpaceUser("Workitem-Service", *fxt.Identities[0], authz.NewLocalRoleService())
+		ctrl := NewWorkitemController(svc, s.GormDB, s.Configuration)
+		u := app.UpdateWorkitemPayload{
+			Data: &app.WorkItem{
+				ID:   &fxt.WorkItems[0].ID,
+				Type: APIStringTypeWorkItem,
+				Attributes: map[string]interface{}{
+					"version": fxt.WorkItems[0].Version,
+				},
+				Relationships: &app.WorkItemRelationships{
+					SystemBoardcolumns: columns,
+				},
+			},
+		}
+		test.UpdateWorkitemForbidden(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+		// sending the current board columns doesn't move the work item
+		u.Data.Relationships.SystemBoardcolumns = &app.RelationGenericList{Data: []*app.GenericData{}}
+		test.UpdateWorkitemOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+	})
+
+	s.T().Run("token without board permission cannot create or bulk update on boards", func(t *testing.T) {
+		// the token of the owner may edit work items but not move them on boards
+		svc := testsupport.ServiceAsPersonalAccessTokenUser("Workitems-Service", account.PersonalAccessToken{
+			ID:         uuid.NewV4(),
+			IdentityID: fxt.IdentityByUsername("owner").ID,
+			Scopes:     pq.StringArray{account.ScopeWorkItemWrite},
+		})
+		ctrl := NewWorkitemsController(svc, s.GormDB, s.Configuration)
+		c := minimumRequiredCreateWithTypeAndSpace(fxt.WorkItemTypes[0].ID, fxt.Spaces[0].ID)
+		c.Data.Attributes[workitem.SystemTitle] = "on a board"
+		c.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+		c.Data.Relationships.SystemBoardcolumns = columns
+		test.CreateWorkitemsForbidden(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, &c)
+		payload := newBulkUpdatePayload(workitem.SystemStateResolved)
+		payload.Data.Relationships = &app.WorkItemRelationships{SystemBoardcolumns: columns}
+		payload.Items = []*app.WorkItemBulkUpdateItem{{ID: fxt.WorkItems[0].ID, Version: fxt.WorkItems[0].Version + 1}}
+		_, res := test.BulkUpdateWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, "forbidden", res.Data[0].Status)
+	})
+}
@@ -0,0 +1,328 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/templatemigration"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeTemplateMigration contains the JSON API type for template
+// migrations
+const APIStringTypeTemplateMigration = "templatemigrations"
+
+// SpaceTemplateMigrationController implements the space_template_migration resource.
+type SpaceTemplateMigrationController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceTemplateMigrationController creates a space_template_migration controller.
+func NewSpaceTemplateMigrationController(service *goa.Service, db application.DB) *SpaceTemplateMigrationController {
+	return &SpaceTemplateMigrationController{
+		Controller: service.NewController("SpaceTemplateMigrationController"),
+		db:         db,
+	}
+}
+
+// authorizeTemplateManagement loads the space and checks that the current user
+// may manage its space template.
+func authorizeTemplateManagement(ctx context.Context, appl application.Application, currentUser uuid.UUID, spaceID uuid.UUID) (*space.Space, error) {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	authorized, err := authorizeSpacePermission(ctx, currentUser, *s, authz.PermissionManageTemplate)
+	if err != nil {
+		return nil, errors.NewUnauthorizedError(err.Error())
+	}
+	if !authorized {
+		return nil, errors.NewForbiddenError("user is not allowed to manage the space template of the space")
+	}
+	return s, nil
+}
+
+// Clone runs the clone action.
+func (c *SpaceTemplateMigrationController) Clone(ctx *app.CloneSpaceTemplateMigrationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var clone *spacetemplate.SpaceTemplate
+	var res *app.SpaceTemplateSingle
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		s, err := authorizeTemplateManagement(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		current, err := appl.SpaceTemplates().Load(ctx, s.SpaceTemplateID)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s (%s)", current.Name, s.ID)
+		if ctx.Name != nil {
+			name = *ctx.Name
+		}
+		cloned, ids, err := appl.SpaceTemplateImporter().Clone(ctx, current.ID, spacetemplate.SpaceTemplate{
+			Name:        name,
+			Description: current.Description,
+			SpaceID:     id.NullUUID{UUID: s.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		clone = &cloned.Template
+		_, err = appl.SpaceTemplateMigrations().Apply(ctx, s.ID, *currentUser, templatemigration.Options{
+			TargetTemplateID: clone.ID,
+			TypeMapping:      ids,
+			LinkTypeMapping:  ids,
+		})
+		if err != nil {
+			return err
+		}
+		res = &app.SpaceTemplateSingle{
+			Data: ConvertSpaceTemplate(appl, ctx.Request, *clone),
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"space_id":          ctx.SpaceID,
+		"space_template_id": clone.ID,
+	}, "space template cloned into the space")
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.SpaceTemplateHref(clone.ID)))
+	return ctx.Created(res)
+}
+
+// Plan runs the plan action.
+func (c *SpaceTemplateMigrationController) Plan(ctx *app.PlanSpaceTemplateMigrationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	opts, err := ConvertTemplateMigrationToModel(*ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var plan *templatemigration.Plan
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := authorizeTemplateManagement(ctx, appl, *currentUser, ctx.SpaceID); err != nil {
+			return err
+		}
+		plan, err = appl.SpaceTemplateMigrations().Plan(ctx, ctx.SpaceID, *opts)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.TemplateMigrationSingle{
+		Data: ConvertTemplateMigration(ctx.Request, *opts, *plan),
+	})
+}
+
+// Apply runs the apply action.
+func (c *SpaceTemplateMigrationController) Apply(ctx *app.ApplySpaceTemplateMigrationContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	opts, err := ConvertTemplateMigrationToModel(*ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var plan *templatemigration.Plan
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if _, err := authorizeTemplateManagement(ctx, appl, *currentUser, ctx.SpaceID); err != nil {
+			return err
+		}
+		plan, err = appl.SpaceTemplateMigrations().Apply(ctx, ctx.SpaceID, *currentUser, *opts)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.TemplateMigrationSingle{
+		Data: ConvertTemplateMigration(ctx.Request, *opts, *plan),
+	})
+}
+
+// ConvertTemplateMigrationToModel converts the payload of a plan or apply
+// request into the options of a template migration.
+func ConvertTemplateMigrationToModel(in app.TemplateMigration) (*templatemigration.Options, error) {
+	if in.Relationships == nil || in.Relationships.TargetTemplate == nil || in.Relationships.TargetTemplate.Data == nil || in.Relationships.TargetTemplate.Data.ID == nil {
+		return nil, errors.NewBadParameterError("data.relationships.target-template", nil).Expected("not nil")
+	}
+	targetID, err := uuid.FromString(*in.Relationships.TargetTemplate.Data.ID)
+	if err != nil {
+		return nil, errors.NewBadParameterError("data.relationships.target-template.data.id", *in.Relationships.TargetTemplate.Data.ID).Expected("UUID")
+	}
+	opts := templatemigration.Options{
+		TargetTemplateID: targetID,
+	}
+	if in.Attributes == nil {
+		return &opts, nil
+	}
+	convertMapping := func(name string, mapping map[string]uuid.UUID) (map[uuid.UUID]uuid.UUID, error) {
+		res := make(map[uuid.UUID]uuid.UUID, len(mapping))
+		for from, to := range mapping {
+			fromID, err := uuid.FromString(from)
+			if err != nil {
+				return nil, errors.NewBadParameterError("data.attributes."+name, from).Expected("UUID keys")
+			}
+			res[fromID] = to
+		}
+		return res, nil
+	}
+	opts.TypeMapping, err = convertMapping("type-mapping", in.Attributes.TypeMapping)
+	if err != nil {
+		return nil, err
+	}
+	opts.LinkTypeMapping, err = convertMapping("link-type-mapping", in.Attributes.LinkTypeMapping)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range in.Attributes.FieldMappings {
+		if m == nil {
+			continue
+		}
+		fm := templatemigration.FieldMapping{
+			Field:  m.Field,
+			Values: m.Values,
+		}
+		if m.TypeID != nil {
+			fm.TypeID = *m.TypeID
+		}
+		opts.FieldMappings = append(opts.FieldMappings, fm)
+	}
+	return &opts, nil
+}
+
+// ConvertTemplateMigration converts the plan of a template migration into its
+// JSON API representation. The mappings of the request are sent back as they
+// were given.
+func ConvertTemplateMigration(request *http.Request, opts templatemigration.Options, plan templatemigration.Plan) *app.TemplateMigration {
+	typeMapping := make(map[string]uuid.UUID, len(opts.TypeMapping))
+	for from, to := range opts.TypeMapping {
+		typeMapping[from.String()] = to
+	}
+	linkTypeMapping := make(map[string]uuid.UUID, len(opts.LinkTypeMapping))
+	for from, to := range opts.LinkTypeMapping {
+		linkTypeMapping[from.String()] = to
+	}
+	fieldMappings := make([]*app.TemplateMigrationFieldMapping, len(opts.FieldMappings))
+	for i, m := range opts.FieldMappings {
+		fieldMappings[i] = &app.TemplateMigrationFieldMapping{
+			Field:  m.Field,
+			Values: m.Values,
+		}
+		if m.TypeID != uuid.Nil {
+			typeID := m.TypeID
+			fieldMappings[i].TypeID = &typeID
+		}
+	}
+	types := make([]*app.TemplateMigrationTypeChange, len(plan.Types))
+	for i, t := range plan.Types {
+		types[i] = &app.TemplateMigrationTypeChange{
+			SourceTypeID:   t.SourceTypeID,
+			SourceTypeName: t.SourceTypeName,
+			AddedFields:    t.AddedFields,
+			RemovedFields:  t.RemovedFields,
+			ChangedFields:  t.ChangedFields,
+			Workitems:      t.WorkItems,
+		}
+		if t.TargetTypeID != uuid.Nil {
+			targetID := t.TargetTypeID
+			types[i].TargetTypeID = &targetID
+			types[i].TargetTypeName = ptr.String(t.TargetTypeName)
+		}
+	}
+	linkTypes := make([]*app.TemplateMigrationLinkTypeChange, len(plan.LinkTypes))
+	for i, t := range plan.LinkTypes {
+		linkTypes[i] = &app.TemplateMigrationLinkTypeChange{
+			SourceLinkTypeID:   t.SourceLinkTypeID,
+			SourceLinkTypeName: t.SourceLinkTypeName,
+			Links:              t.Links,
+		}
+		if t.TargetLinkTypeID != uuid.Nil {
+			targetID := t.TargetLinkTypeID
+			linkTypes[i].TargetLinkTypeID = &targetID
+		}
+	}
+	workItems := make([]*app.TemplateMigrationWorkItemChange, len(plan.WorkItems))
+	for i, wi := range plan.WorkItems {
+		fields := make([]*app.TemplateMigrationFieldChange, len(wi.Fields))
+		for j, f := range wi.Fields {
+			fields[j] = &app.TemplateMigrationFieldChange{
+				Field: f.Field,
+				From:  f.From,
+				To:    f.To,
+			}
+		}
+		workItems[i] = &app.TemplateMigrationWorkItemChange{
+			ID:           wi.ID,
+			Number:       wi.Number,
+			SourceTypeID: wi.SourceTypeID,
+			TargetTypeID: wi.TargetTypeID,
+			Fields:       fields,
+		}
+		if wi.Title != "" {
+			workItems[i].Title = ptr.String(wi.Title)
+		}
+	}
+	convertNameDiff := func(diff templatemigration.NameDiff) *app.TemplateMigrationNameDiff {
+		return &app.TemplateMigrationNameDiff{
+			Added:   diff.Added,
+			Removed: diff.Removed,
+			Kept:    diff.Kept,
+		}
+	}
+	templateRelation := func(templateID uuid.UUID) *app.RelationGeneric {
+		related := rest.AbsoluteURL(request, app.SpaceTemplateHref(templateID))
+		return &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APISpaceTemplates),
+				ID:   ptr.String(templateID.String()),
+			},
+			Links: &app.GenericLinks{
+				Related: &related,
+			},
+		}
+	}
+	spaceID := plan.SpaceID
+	return &app.TemplateMigration{
+		Type: APIStringTypeTemplateMigration,
+		ID:   &spaceID,
+		Attributes: &app.TemplateMigrationAttributes{
+			TypeMapping:     typeMapping,
+			LinkTypeMapping: linkTypeMapping,
+			FieldMappings:   fieldMappings,
+			Types:           types,
+			LinkTypes:       linkTypes,
+			TypeGroups:      convertNameDiff(plan.TypeGroups),
+			Boards:          convertNameDiff(plan.Boards),
+			Workitems:       workItems,
+			Conflicts:       plan.Conflicts,
+		},
+		Relationships: &app.TemplateMigrationRelations{
+			SourceTemplate: templateRelation(plan.SourceTemplateID),
+			TargetTemplate: templateRelation(plan.TargetTemplateID),
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type spaceTemplateMigrationSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSpaceTemplateMigrationSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &spaceTemplateMigrationSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *spaceTemplateMigrationSuite) TestClone() {
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("SpaceTemplateMigration-Service", *fxt.Identities[0])
+		name := testsupport.CreateRandomValidTestName("clone ")
+		// when
+		_, res := test.CloneSpaceTemplateMigrationCreated(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, &name)
+		// then
+		require.NotNil(t, res.Data.ID)
+		require.NotEqual(t, fxt.SpaceTemplates[0].ID, *res.Data.ID)
+		require.Equal(t, name, *res.Data.Attributes.Name)
+		sp, err := s.GormDB.Spaces().Load(svc.Context, fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, *res.Data.ID, sp.SpaceTemplateID)
+	})
+
+	s.T().Run("planner", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		roles := authz.NewLocalRoleService().Assign(fxt.Spaces[0].ID, fxt.Identities[1].ID, authz.RolePlanner)
+		svc := testsupport.ServiceAsSpaceUser("SpaceTemplateMigration-Service", *fxt.Identities[1], roles)
+		test.CloneSpaceTemplateMigrationForbidden(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, nil)
+	})
+}
+
+func (s *spaceTemplateMigrationSuite) TestPlan() {
+	payload := func(fxt *tf.TestFixture) *app.PlanSpaceTemplateMigrationPayload {
+		return &app.PlanSpaceTemplateMigrationPayload{
+			Data: &app.TemplateMigration{
+				Type:       APIStringTypeTemplateMigration,
+				Attributes: &app.TemplateMigrationAttributes{},
+				Relationships: &app.TemplateMigrationRelations{
+					TargetTemplate: &app.RelationGeneric{
+						Data: &app.GenericData{
+							Type: ptr.String(APISpaceTemplates),
+							ID:   ptr.String(fxt.SpaceTemplates[0].ID.String()),
+						},
+					},
+				},
+			},
+		}
+	}
+
+	s.T().Run("ok", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(1))
+		svc := testsupport.ServiceAsUser("SpaceTemplateMigration-Service", *fxt.Identities[0])
+		// when
+		_, res := test.PlanSpaceTemplateMigrationOK(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, payload(fxt))
+		// then
+		require.Empty(t, res.Data.Attributes.Conflicts)
+		require.Empty(t, res.Data.Attributes.Workitems)
+		require.Equal(t, fxt.SpaceTemplates[0].ID.String(), *res.Data.Relationships.SourceTemplate.Data.ID)
+	})
+
+	s.T().Run("planner", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		roles := authz.NewLocalRoleService().Assign(fxt.Spaces[0].ID, fxt.Identities[1].ID, authz.RolePlanner)
+		svc := testsupport.ServiceAsSpaceUser("SpaceTemplateMigration-Service", *fxt.Identities[1], roles)
+		test.PlanSpaceTemplateMigrationForbidden(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, payload(fxt))
+	})
+}
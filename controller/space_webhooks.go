@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	"github.com/goadesign/goa"
+)
+
+// SpaceWebhooksController implements the space_webhooks resource.
+type SpaceWebhooksController struct {
+	*goa.Controller
+	db     application.DB
+	config WebhookControllerConfiguration
+}
+
+// NewSpaceWebhooksController creates a space_webhooks controller.
+func NewSpaceWebhooksController(service *goa.Service, db application.DB, config WebhookControllerConfiguration) *SpaceWebhooksController {
+	return &SpaceWebhooksController{
+		Controller: service.NewController("SpaceWebhooksController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// List runs the list action.
+func (c *SpaceWebhooksController) List(ctx *app.ListSpaceWebhooksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var hooks []webhook.Webhook
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeWebhookManager(ctx, appl, ctx.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		hooks, err = appl.Webhooks().List(ctx, ctx.SpaceID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.WebhookList{
+		Data: []*app.Webhook{},
+		Meta: &app.ListMeta{
+			TotalCount: len(hooks),
+		},
+	}
+	for _, w := range hooks {
+		res.Data = append(res.Data, ConvertWebhook(ctx.Request, w))
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *SpaceWebhooksController) Create(ctx *app.CreateSpaceWebhooksContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.URL == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.url", nil).Expected("not nil"))
+	}
+	if attrs.Secret == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.secret", nil).Expected("not nil"))
+	}
+	w := webhook.Webhook{
+		SpaceID: ctx.SpaceID,
+		URL:     *attrs.URL,
+		Secret:  *attrs.Secret,
+		Events:  attrs.Events,
+		Active:  true,
+	}
+	if attrs.Active != nil {
+		w.Active = *attrs.Active
+	}
+	// the host is resolved so that webhooks can't reach into the network of
+	// the service
+	if err := w.CheckAddress(ctx, c.config.GetWebhookAllowPrivateAddresses()); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := authorizeWebhookManager(ctx, appl, ctx.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		return appl.Webhooks().Create(ctx, &w)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.WebhookSingle{
+		Data: ConvertWebhook(ctx.Request, w),
+	}
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.WebhookHref(w.ID)))
+	return ctx.Created(res)
+}
@@ -0,0 +1,351 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SpaceWorkitemtypesController implements the space_workitemtypes resource.
+type SpaceWorkitemtypesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewSpaceWorkitemtypesController creates a space_workitemtypes controller.
+func NewSpaceWorkitemtypesController(service *goa.Service, db application.DB) *SpaceWorkitemtypesController {
+	return &SpaceWorkitemtypesController{
+		Controller: service.NewController("SpaceWorkitemtypesController"),
+		db:         db,
+	}
+}
+
+// loadCustomTemplate returns the space template of the space if the current
+// user may manage it and if no other space uses it.
+func loadCustomTemplate(ctx context.Context, appl application.Application, currentUser uuid.UUID, spaceID uuid.UUID) (*spacetemplate.SpaceTemplate, error) {
+	s, err := authorizeTemplateManagement(ctx, appl, currentUser, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	templ, err := appl.SpaceTemplates().Load(ctx, s.SpaceTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if err := templ.Validate(); err != nil {
+		return nil, err
+	}
+	if !templ.SpaceID.Valid || templ.SpaceID.UUID != s.ID {
+		return nil, errors.NewBadParameterErrorFromString(fmt.Sprintf("space template %s is shared with other spaces; clone it into the space before changing its work item types", templ.ID))
+	}
+	return templ, nil
+}
+
+// loadCustomType returns the work item type with the given ID if it belongs to
+// the given space template.
+func loadCustomType(ctx context.Context, appl application.Application, templ spacetemplate.SpaceTemplate, witID uuid.UUID) (*workitem.WorkItemType, error) {
+	wit, err := appl.WorkItemTypes().Load(ctx, witID)
+	if err != nil {
+		return nil, err
+	}
+	if wit.SpaceTemplateID != templ.ID {
+		return nil, errors.NewNotFoundError("work item type", witID.String())
+	}
+	return wit, nil
+}
+
+// checkCustomType checks that no other type of the space template has the name
+// of the given type and that its child types belong to the template.
+func checkCustomType(ctx context.Context, appl application.Application, templ spacetemplate.SpaceTemplate, wit workitem.WorkItemType) error {
+	wits, err := appl.WorkItemTypes().List(ctx, templ.ID)
+	if err != nil {
+		return err
+	}
+	known := map[uuid.UUID]struct{}{wit.ID: {}}
+	for _, other := range wits {
+		if other.ID != wit.ID && other.Name == wit.Name {
+			return errors.NewDataConflictError(fmt.Sprintf("work item type with name %q exists already in space template %s", wit.Name, templ.ID))
+		}
+		known[other.ID] = struct{}{}
+	}
+	for _, childID := range wit.ChildTypeIDs {
+		if _, ok := known[childID]; !ok {
+			return errors.NewBadParameterError("guidedChildTypes", childID).Expected("work item type of space template " + templ.ID.String())
+		}
+	}
+	return nil
+}
+
+// keepReadOnly copies the read-only flags of the given fields because the API
+// can't set them.
+func keepReadOnly(fields workitem.FieldDefinitions, from workitem.FieldDefinitions) {
+	for name, def := range fields {
+		if existing, ok := from[name]; ok {
+			def.ReadOnly = existing.ReadOnly
+			fields[name] = def
+		}
+	}
+}
+
+// copyFields returns a copy of the given fields which can be changed without
+// touching the cached work item type.
+func copyFields(fields workitem.FieldDefinitions) workitem.FieldDefinitions {
+	res := make(workitem.FieldDefinitions, len(fields))
+	for name, def := range fields {
+		res[name] = def
+	}
+	return res
+}
+
+// Create runs the create action.
+func (c *SpaceWorkitemtypesController) Create(ctx *app.CreateSpaceWorkitemtypesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	model, err := ConvertWorkItemTypeToModel(*ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		templ, err := loadCustomTemplate(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		model.SpaceTemplateID = templ.ID
+		if model.ID == uuid.Nil {
+			model.ID = uuid.NewV4()
+		}
+		if model.Extends == uuid.Nil {
+			model.Extends = workitem.SystemPlannerItem
+		}
+		extendedType, err := appl.WorkItemTypes().Load(ctx, model.Extends)
+		if err != nil {
+			return errors.NewBadParameterError("extendedTypeName", model.Extends).Expected("existing work item type")
+		}
+		if extendedType.SpaceTemplateID != templ.ID && extendedType.SpaceTemplateID != spacetemplate.SystemBaseTemplateID {
+			return errors.NewBadParameterError("extendedTypeName", model.Extends).Expected("work item type of the space template or of the base template")
+		}
+		keepReadOnly(model.Fields, extendedType.Fields)
+		if err := checkCustomType(ctx, appl, *templ, *model); err != nil {
+			return err
+		}
+		if err := model.Validate(); err != nil {
+			return err
+		}
+		wit, err = appl.WorkItemTypes().CreateFromModel(ctx, *model)
+		if err != nil {
+			return err
+		}
+		if err := appl.WorkItemTypes().AddChildTypes(ctx, wit.ID, model.ChildTypeIDs); err != nil {
+			return err
+		}
+		wit.ChildTypeIDs = model.ChildTypeIDs
+		return nil
+	})
+	// other requests may have cached work item types while the transaction was
+	// open, so the cache is cleared once the changes are visible
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := ConvertWorkItemTypeFromModel(ctx.Request, wit)
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.WorkitemtypeHref(wit.ID)))
+	return ctx.Created(&app.WorkItemTypeSingle{Data: &data})
+}
+
+// Update runs the update action.
+func (c *SpaceWorkitemtypesController) Update(ctx *app.UpdateSpaceWorkitemtypesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	model, err := ConvertWorkItemTypeToModel(*ctx.Payload.Data)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		templ, err := loadCustomTemplate(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		existing, err := loadCustomType(ctx, appl, *templ, ctx.WitID)
+		if err != nil {
+			return err
+		}
+		model.ID = existing.ID
+		model.SpaceTemplateID = existing.SpaceTemplateID
+		model.Transitions = existing.Transitions
+		if ctx.Payload.Data.Attributes.Version == nil {
+			model.Version = existing.Version
+		}
+		keepReadOnly(model.Fields, existing.Fields)
+		if err := checkCustomType(ctx, appl, *templ, *model); err != nil {
+			return err
+		}
+		wit, err = appl.WorkItemTypes().Save(ctx, *model)
+		return err
+	})
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := ConvertWorkItemTypeFromModel(ctx.Request, wit)
+	return ctx.OK(&app.WorkItemTypeSingle{Data: &data})
+}
+
+// Delete runs the delete action.
+func (c *SpaceWorkitemtypesController) Delete(ctx *app.DeleteSpaceWorkitemtypesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		templ, err := loadCustomTemplate(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		if _, err := loadCustomType(ctx, appl, *templ, ctx.WitID); err != nil {
+			return err
+		}
+		return appl.WorkItemTypes().Delete(ctx, ctx.WitID)
+	})
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// SetField runs the set-field action.
+func (c *SpaceWorkitemtypesController) SetField(ctx *app.SetFieldSpaceWorkitemtypesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	def, err := ConvertFieldDefinitionToModel(*ctx.Payload)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterErrorFromString(err.Error()))
+	}
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		templ, err := loadCustomTemplate(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		existing, err := loadCustomType(ctx, appl, *templ, ctx.WitID)
+		if err != nil {
+			return err
+		}
+		model := *existing
+		model.Fields = copyFields(existing.Fields)
+		model.Fields[ctx.FieldName] = *def
+		keepReadOnly(model.Fields, existing.Fields)
+		model.ChildTypeIDs = nil
+		wit, err = appl.WorkItemTypes().Save(ctx, model)
+		return err
+	})
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := ConvertWorkItemTypeFromModel(ctx.Request, wit)
+	return ctx.OK(&app.WorkItemTypeSingle{Data: &data})
+}
+
+// DeleteField runs the delete-field action.
+func (c *SpaceWorkitemtypesController) DeleteField(ctx *app.DeleteFieldSpaceWorkitemtypesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		templ, err := loadCustomTemplate(ctx, appl, *currentUser, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		existing, err := loadCustomType(ctx, appl, *templ, ctx.WitID)
+		if err != nil {
+			return err
+		}
+		if _, ok := existing.Fields[ctx.FieldName]; !ok {
+			return errors.NewNotFoundError("field", ctx.FieldName)
+		}
+		model := *existing
+		model.Fields = copyFields(existing.Fields)
+		delete(model.Fields, ctx.FieldName)
+		model.ChildTypeIDs = nil
+		wit, err = appl.WorkItemTypes().Save(ctx, model)
+		return err
+	})
+	workitem.ClearGlobalWorkItemTypeCache()
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	data := ConvertWorkItemTypeFromModel(ctx.Request, wit)
+	return ctx.OK(&app.WorkItemTypeSingle{Data: &data})
+}
+
+// ConvertWorkItemTypeToModel converts a work item type from the app
+// representation to the model. The space template is left out.
+func ConvertWorkItemTypeToModel(data app.WorkItemTypeData) (*workitem.WorkItemType, error) {
+	if data.Attributes == nil {
+		return nil, errors.NewBadParameterError("data.attributes", nil).Expected("not nil")
+	}
+	attrs := data.Attributes
+	res := workitem.WorkItemType{
+		Name:        attrs.Name,
+		Description: attrs.Description,
+		Icon:        attrs.Icon,
+		Fields:      workitem.FieldDefinitions{},
+	}
+	if data.ID != nil {
+		res.ID = *data.ID
+	}
+	if attrs.Version != nil {
+		res.Version = *attrs.Version
+	}
+	if attrs.CanConstruct != nil {
+		res.CanConstruct = *attrs.CanConstruct
+	}
+	if attrs.ExtendedTypeName != nil {
+		res.Extends = *attrs.ExtendedTypeName
+	}
+	for name, def := range attrs.Fields {
+		if def == nil {
+			return nil, errors.NewBadParameterError("data.attributes.fields."+name, nil).Expected("field definition")
+		}
+		converted, err := ConvertFieldDefinitionToModel(*def)
+		if err != nil {
+			return nil, errors.NewBadParameterErrorFromString(errs.Wrapf(err, "invalid definition of field %q", name).Error())
+		}
+		res.Fields[name] = *converted
+	}
+	if data.Relationships != nil && data.Relationships.GuidedChildTypes != nil {
+		res.ChildTypeIDs = []uuid.UUID{}
+		for _, child := range data.Relationships.GuidedChildTypes.Data {
+			if child == nil || child.ID == nil {
+				return nil, errors.NewBadParameterError("data.relationships.guidedChildTypes", nil).Expected("work item type IDs")
+			}
+			childID, err := uuid.FromString(*child.ID)
+			if err != nil {
+				return nil, errors.NewBadParameterError("data.relationships.guidedChildTypes", *child.ID).Expected("UUID")
+			}
+			res.ChildTypeIDs = append(res.ChildTypeIDs, childID)
+		}
+	}
+	return &res, nil
+}
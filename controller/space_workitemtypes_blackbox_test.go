@@ -0,0 +1,131 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type spaceWorkitemtypesSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSpaceWorkitemtypesSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &spaceWorkitemtypesSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newCreateSpaceWorkitemtypesPayload(name string) *app.CreateSpaceWorkitemtypesPayload {
+	return &app.CreateSpaceWorkitemtypesPayload{
+		Data: &app.WorkItemTypeData{
+			Type: APIStringTypeWorkItemType,
+			Attributes: &app.WorkItemTypeAttributes{
+				Name: name,
+				Icon: "fa-bug",
+				Fields: map[string]*app.FieldDefinition{
+					"customer": {
+						Label:       "Customer",
+						Description: "The customer who asked for it",
+						Type:        &app.FieldType{Kind: "string"},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (s *spaceWorkitemtypesSuite) TestCustomTypes() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given a space with its own template
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", *fxt.Identities[0])
+		_, templ := test.CloneSpaceTemplateMigrationCreated(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, nil)
+		ctrl := NewSpaceWorkitemtypesController(svc, s.GormDB)
+		name := testsupport.CreateRandomValidTestName("custom type ")
+
+		// when
+		_, created := test.CreateSpaceWorkitemtypesCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(name))
+		// then
+		require.Equal(t, name, created.Data.Attributes.Name)
+		require.Contains(t, created.Data.Attributes.Fields, "customer")
+		require.Contains(t, created.Data.Attributes.Fields, workitem.SystemTitle)
+		require.Equal(t, *templ.Data.ID, created.Data.Relationships.SpaceTemplate.Data.ID)
+		witID := *created.Data.ID
+
+		// when
+		_, changed := test.SetFieldSpaceWorkitemtypesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, witID, "priority", &app.FieldDefinition{
+			Label:       "Priority",
+			Description: "How urgent it is",
+			Type:        &app.FieldType{Kind: "enum", BaseType: ptr.String("string"), Values: []interface{}{"low", "high"}},
+		})
+		// then
+		require.Contains(t, changed.Data.Attributes.Fields, "priority")
+		require.Equal(t, *created.Data.Attributes.Version+1, *changed.Data.Attributes.Version)
+		wit, err := s.GormDB.WorkItemTypes().Load(svc.Context, witID)
+		require.NoError(t, err)
+		require.Contains(t, wit.Fields, "priority")
+
+		// when
+		_, changed = test.DeleteFieldSpaceWorkitemtypesOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, witID, "customer")
+		// then
+		require.NotContains(t, changed.Data.Attributes.Fields, "customer")
+
+		// when
+		test.DeleteSpaceWorkitemtypesNoContent(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, witID)
+		// then
+		_, err = s.GormDB.WorkItemTypes().Load(svc.Context, witID)
+		require.Error(t, err)
+	})
+
+	s.T().Run("inherited field", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", *fxt.Identities[0])
+		test.CloneSpaceTemplateMigrationCreated(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, nil)
+		ctrl := NewSpaceWorkitemtypesController(svc, s.GormDB)
+		_, created := test.CreateSpaceWorkitemtypesCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(testsupport.CreateRandomValidTestName("custom type ")))
+		test.DeleteFieldSpaceWorkitemtypesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, *created.Data.ID, workitem.SystemTitle)
+	})
+
+	s.T().Run("duplicate name", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", *fxt.Identities[0])
+		test.CloneSpaceTemplateMigrationCreated(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, nil)
+		ctrl := NewSpaceWorkitemtypesController(svc, s.GormDB)
+		name := testsupport.CreateRandomValidTestName("custom type ")
+		test.CreateSpaceWorkitemtypesCreated(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(name))
+		test.CreateSpaceWorkitemtypesConflict(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(name))
+	})
+
+	s.T().Run("shared template", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", *fxt.Identities[0])
+		ctrl := NewSpaceWorkitemtypesController(svc, s.GormDB)
+		test.CreateSpaceWorkitemtypesBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(testsupport.CreateRandomValidTestName("custom type ")))
+	})
+
+	s.T().Run("type of another template", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		svc := testsupport.ServiceAsUser("SpaceWorkitemtypes-Service", *fxt.Identities[0])
+		test.CloneSpaceTemplateMigrationCreated(t, svc.Context, svc, NewSpaceTemplateMigrationController(svc, s.GormDB), fxt.Spaces[0].ID, nil)
+		ctrl := NewSpaceWorkitemtypesController(svc, s.GormDB)
+		test.DeleteSpaceWorkitemtypesNotFound(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, fxt.WorkItemTypes[0].ID)
+	})
+
+	s.T().Run("planner", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Identities(2), tf.Spaces(1))
+		roles := authz.NewLocalRoleService().Assign(fxt.Spaces[0].ID, fxt.Identities[1].ID, authz.RolePlanner)
+		svc := testsupport.ServiceAsSpaceUser("SpaceWorkitemtypes-Service", *fxt.Identities[1], roles)
+		test.CreateSpaceWorkitemtypesForbidden(t, svc.Context, svc, NewSpaceWorkitemtypesController(svc, s.GormDB), fxt.Spaces[0].ID, newCreateSpaceWorkitemtypesPayload(testsupport.CreateRandomValidTestName("custom type ")))
+	})
+}
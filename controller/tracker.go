@@ -16,6 +16,8 @@ import (
 
 type trackerConfiguration interface {
 	GetGithubAuthToken() string
+	GetGitlabAuthToken() string
+	GetJiraAuthToken() string
 }
 
 // TrackerController implements the tracker resource.
@@ -29,7 +31,8 @@ type TrackerController struct {
 func GetAccessTokens(configuration trackerConfiguration) map[string]string {
 	tokens := map[string]string{
 		remoteworkitem.ProviderGithub: configuration.GetGithubAuthToken(),
-		// add tokens for other types
+		remoteworkitem.ProviderGitlab: configuration.GetGitlabAuthToken(),
+		remoteworkitem.ProviderJira:   configuration.GetJiraAuthToken(),
 	}
 	return tokens
 }
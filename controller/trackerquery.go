@@ -15,6 +15,8 @@ import (
 
 type trackerQueryConfiguration interface {
 	GetGithubAuthToken() string
+	GetGitlabAuthToken() string
+	GetJiraAuthToken() string
 }
 
 // TrackerqueryController implements the trackerquery resource.
@@ -28,7 +30,8 @@ type TrackerqueryController struct {
 func getAccessTokensForTrackerQuery(configuration trackerQueryConfiguration) map[string]string {
 	tokens := map[string]string{
 		remoteworkitem.ProviderGithub: configuration.GetGithubAuthToken(),
-		// add tokens for other types
+		remoteworkitem.ProviderGitlab: configuration.GetGitlabAuthToken(),
+		remoteworkitem.ProviderJira:   configuration.GetJiraAuthToken(),
 	}
 	return tokens
 }
@@ -41,7 +44,8 @@ func NewTrackerqueryController(service *goa.Service, db application.DB, schedule
 // Create runs the create action.
 func (c *TrackerqueryController) Create(ctx *app.CreateTrackerqueryContext) error {
 	err := application.Transactional(c.db, func(appl application.Application) error {
-		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, *ctx.Payload.Relationships.Space.Data.ID)
+		pushEnabled := ctx.Payload.PushEnabled != nil && *ctx.Payload.PushEnabled
+		tq, err := appl.TrackerQueries().Create(ctx.Context, ctx.Payload.Query, ctx.Payload.Schedule, ctx.Payload.TrackerID, *ctx.Payload.Relationships.Space.Data.ID, pushEnabled)
 		if err != nil {
 			cause := errs.Cause(err)
 			switch cause.(type) {
@@ -95,6 +99,7 @@ func (c *TrackerqueryController) Update(ctx *app.UpdateTrackerqueryContext) erro
 			Query:         ctx.Payload.Query,
 			Schedule:      ctx.Payload.Schedule,
 			TrackerID:     ctx.Payload.TrackerID,
+			PushEnabled:   ctx.Payload.PushEnabled != nil && *ctx.Payload.PushEnabled,
 			Relationships: ctx.Payload.Relationships,
 		}
 		tq, err := appl.TrackerQueries().Save(ctx.Context, toSave)
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWatches helps to avoid string literal
+const APIStringTypeWatches = "watches"
+
+// WatchesController implements the watches resource.
+type WatchesController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWatchesController creates a watches controller.
+func NewWatchesController(service *goa.Service, db application.DB) *WatchesController {
+	return &WatchesController{
+		Controller: service.NewController("WatchesController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *WatchesController) List(ctx *app.ListWatchesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var watches []subscription.Watch
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		watches, err = appl.Watches().List(ctx, *currentUser)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.WatchList{
+		Data: make([]*app.Watch, len(watches)),
+	}
+	for i, w := range watches {
+		res.Data[i] = ConvertWatch(ctx.Request, w)
+	}
+	return ctx.OK(res)
+}
+
+// Create runs the create action.
+func (c *WatchesController) Create(ctx *app.CreateWatchesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if err := subscription.ValidateTargetType(ctx.TargetType); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	w := subscription.Watch{
+		IdentityID: *currentUser,
+		TargetType: ctx.TargetType,
+		TargetID:   ctx.TargetID,
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		if err := checkWatchTargetExists(ctx, appl, w.TargetType, w.TargetID); err != nil {
+			return err
+		}
+		return appl.Watches().Watch(ctx, &w)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WatchSingle{
+		Data: ConvertWatch(ctx.Request, w),
+	})
+}
+
+// Delete runs the delete action.
+func (c *WatchesController) Delete(ctx *app.DeleteWatchesContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if err := subscription.ValidateTargetType(ctx.TargetType); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return appl.Watches().Unwatch(ctx, *currentUser, ctx.TargetType, ctx.TargetID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// checkWatchTargetExists returns a NotFoundError unless the work item,
+// iteration or space to watch exists
+func checkWatchTargetExists(ctx context.Context, appl application.Application, targetType string, targetID uuid.UUID) error {
+	switch targetType {
+	case subscription.TargetWorkItem:
+		return appl.WorkItems().CheckExists(ctx, targetID)
+	case subscription.TargetIteration:
+		return appl.Iterations().CheckExists(ctx, targetID)
+	default:
+		return appl.Spaces().CheckExists(ctx, targetID)
+	}
+}
+
+// ConvertWatch converts from internal to external REST representation
+func ConvertWatch(request *http.Request, w subscription.Watch) *app.Watch {
+	targetID := w.TargetID.String()
+	identityID := w.IdentityID.String()
+	var targetHref string
+	switch w.TargetType {
+	case subscription.TargetWorkItem:
+		targetHref = app.WorkitemHref(targetID)
+	case subscription.TargetIteration:
+		targetHref = app.IterationHref(targetID)
+	default:
+		targetHref = app.SpaceHref(targetID)
+	}
+	targetRelatedURL := rest.AbsoluteURL(request, targetHref)
+	identityRelatedURL := rest.AbsoluteURL(request, app.UsersHref(identityID))
+	return &app.Watch{
+		Type: APIStringTypeWatches,
+		ID:   &w.ID,
+		Attributes: &app.WatchAttributes{
+			CreatedAt: &w.CreatedAt,
+		},
+		Relationships: &app.WatchRelations{
+			Target: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(w.TargetType),
+					ID:   &targetID,
+				},
+				Links: &app.GenericLinks{
+					Related: &targetRelatedURL,
+				},
+			},
+			Identity: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeUser),
+					ID:   &identityID,
+				},
+				Links: &app.GenericLinks{
+					Related: &identityRelatedURL,
+				},
+			},
+		},
+	}
+}
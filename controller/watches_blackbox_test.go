@@ -0,0 +1,74 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type watchesSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestWatches(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &watchesSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *watchesSuite) TestWatches() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(1), tf.Iterations(1), tf.WorkItems(1))
+	svc := testsupport.ServiceAsUser("Watches-Service", *fxt.Identities[0])
+	ctrl := NewWatchesController(svc, s.GormDB)
+
+	s.T().Run("watch", func(t *testing.T) {
+		// when
+		_, w := test.CreateWatchesOK(t, svc.Context, svc, ctrl, "workitems", fxt.WorkItems[0].ID)
+		// then
+		assert.Equal(t, "workitems", *w.Data.Relationships.Target.Data.Type)
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), *w.Data.Relationships.Target.Data.ID)
+		assert.Equal(t, fxt.Identities[0].ID.String(), *w.Data.Relationships.Identity.Data.ID)
+		// watching twice returns the existing watch
+		_, again := test.CreateWatchesOK(t, svc.Context, svc, ctrl, "workitems", fxt.WorkItems[0].ID)
+		assert.Equal(t, *w.Data.ID, *again.Data.ID)
+		test.CreateWatchesOK(t, svc.Context, svc, ctrl, "iterations", fxt.Iterations[0].ID)
+		test.CreateWatchesOK(t, svc.Context, svc, ctrl, "spaces", fxt.Spaces[0].ID)
+	})
+
+	s.T().Run("list", func(t *testing.T) {
+		// when
+		_, list := test.ListWatchesOK(t, svc.Context, svc, ctrl)
+		// then
+		require.Len(t, list.Data, 3)
+		assert.Equal(t, "spaces", *list.Data[0].Relationships.Target.Data.Type)
+	})
+
+	s.T().Run("unwatch", func(t *testing.T) {
+		// when
+		test.DeleteWatchesNoContent(t, svc.Context, svc, ctrl, "spaces", fxt.Spaces[0].ID)
+		// then
+		_, list := test.ListWatchesOK(t, svc.Context, svc, ctrl)
+		assert.Len(t, list.Data, 2)
+		test.DeleteWatchesNotFound(t, svc.Context, svc, ctrl, "spaces", fxt.Spaces[0].ID)
+	})
+
+	s.T().Run("unknown target", func(t *testing.T) {
+		test.CreateWatchesNotFound(t, svc.Context, svc, ctrl, "workitems", uuid.NewV4())
+		test.CreateWatchesNotFound(t, svc.Context, svc, ctrl, "iterations", uuid.NewV4())
+		test.CreateWatchesNotFound(t, svc.Context, svc, ctrl, "spaces", uuid.NewV4())
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		svc := goa.New("Watches-Service")
+		test.CreateWatchesUnauthorized(t, svc.Context, svc, NewWatchesController(svc, s.GormDB), "workitems", fxt.WorkItems[0].ID)
+	})
+}
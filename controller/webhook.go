@@ -0,0 +1,199 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WebhookControllerConfiguration the configuration for the webhook and the
+// space webhooks controllers
+type WebhookControllerConfiguration interface {
+	GetWebhookAllowPrivateAddresses() bool
+}
+
+// WebhookController implements the webhook resource.
+type WebhookController struct {
+	*goa.Controller
+	db     application.DB
+	config WebhookControllerConfiguration
+}
+
+// NewWebhookController creates a webhook controller.
+func NewWebhookController(service *goa.Service, db application.DB, config WebhookControllerConfiguration) *WebhookController {
+	return &WebhookController{
+		Controller: service.NewController("WebhookController"),
+		db:         db,
+		config:     config,
+	}
+}
+
+// authorizeWebhookManager returns a forbidden error unless the given identity
+// owns the space. Webhooks carry secrets, hence only the space owner can see
+// and manage them.
+func authorizeWebhookManager(ctx context.Context, appl application.Application, spaceID uuid.UUID, identityID uuid.UUID) error {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	if !authz.AuthorizeOwner(ctx, identityID, s.OwnerID, authz.PermissionManageSpace) {
+		errorMsg := fmt.Sprintf("only the space owner can manage webhooks and %s is not the space owner of %s", identityID, s.ID)
+		log.Warn(ctx, map[string]interface{}{
+			"space_id":     s.ID,
+			"space_owner":  s.OwnerID,
+			"current_user": identityID,
+		}, errorMsg)
+		return errors.NewForbiddenError(errorMsg)
+	}
+	return nil
+}
+
+// Show runs the show action.
+func (c *WebhookController) Show(ctx *app.ShowWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var w *webhook.Webhook
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		w, err = appl.Webhooks().Load(ctx, ctx.WebhookID)
+		if err != nil {
+			return err
+		}
+		return authorizeWebhookManager(ctx, appl, w.SpaceID, *currentUser)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WebhookSingle{
+		Data: ConvertWebhook(ctx.Request, *w),
+	})
+}
+
+// Update runs the update action.
+func (c *WebhookController) Update(ctx *app.UpdateWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.Attributes == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes", nil).Expected("not nil"))
+	}
+	attrs := ctx.Payload.Data.Attributes
+	if attrs.Version == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("data.attributes.version", nil).Expected("not nil"))
+	}
+	if attrs.URL != nil {
+		if err := (webhook.Webhook{URL: *attrs.URL}).CheckAddress(ctx, c.config.GetWebhookAllowPrivateAddresses()); err != nil {
+			return jsonapi.JSONErrorResponse(ctx, err)
+		}
+	}
+	var w *webhook.Webhook
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		w, err = appl.Webhooks().Load(ctx, ctx.WebhookID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeWebhookManager(ctx, appl, w.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		if w.Version != *attrs.Version {
+			return errors.NewVersionConflictError("version conflict")
+		}
+		if attrs.URL != nil {
+			w.URL = *attrs.URL
+		}
+		if attrs.Secret != nil {
+			w.Secret = *attrs.Secret
+		}
+		if attrs.Events != nil {
+			w.Events = attrs.Events
+		}
+		if attrs.Active != nil {
+			w.Active = *attrs.Active
+		}
+		w, err = appl.Webhooks().Save(ctx, *w)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WebhookSingle{
+		Data: ConvertWebhook(ctx.Request, *w),
+	})
+}
+
+// Delete runs the delete action.
+func (c *WebhookController) Delete(ctx *app.DeleteWebhookContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		w, err := appl.Webhooks().Load(ctx, ctx.WebhookID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeWebhookManager(ctx, appl, w.SpaceID, *currentUser); err != nil {
+			return err
+		}
+		return appl.Webhooks().Delete(ctx, w.ID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.NoContent()
+}
+
+// ConvertWebhook converts from internal to external REST representation. The
+// secret is never returned.
+func ConvertWebhook(request *http.Request, w webhook.Webhook) *app.Webhook {
+	spaceID := w.SpaceID.String()
+	selfURL := rest.AbsoluteURL(request, app.WebhookHref(w.ID))
+	spaceRelatedURL := rest.AbsoluteURL(request, app.SpaceHref(spaceID))
+	events := []string(w.Events)
+	if events == nil {
+		events = []string{}
+	}
+	return &app.Webhook{
+		Type: webhook.APIStringTypeWebhooks,
+		ID:   &w.ID,
+		Attributes: &app.WebhookAttributes{
+			URL:       &w.URL,
+			Events:    events,
+			Active:    &w.Active,
+			CreatedAt: &w.CreatedAt,
+			UpdatedAt: &w.UpdatedAt,
+			Version:   &w.Version,
+		},
+		Relationships: &app.WebhookRelations{
+			Space: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: &space.SpaceType,
+					ID:   &spaceID,
+				},
+				Links: &app.GenericLinks{
+					Self:    &spaceRelatedURL,
+					Related: &spaceRelatedURL,
+				},
+			},
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &selfURL,
+		},
+	}
+}
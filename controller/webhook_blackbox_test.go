@@ -0,0 +1,148 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	"github.com/goadesign/goa"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWebhookREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWebhookREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWebhookREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newCreateWebhookPayload(url string, events ...string) *app.CreateSpaceWebhooksPayload {
+	secret := "s3cr3t"
+	return &app.CreateSpaceWebhooksPayload{
+		Data: &app.Webhook{
+			Type: webhook.APIStringTypeWebhooks,
+			Attributes: &app.WebhookAttributes{
+				URL:    &url,
+				Secret: &secret,
+				Events: events,
+			},
+		},
+	}
+}
+
+// webhookConfig decides whether webhooks may point to private addresses
+type webhookConfig struct {
+	allowPrivate bool
+}
+
+func (c webhookConfig) GetWebhookAllowPrivateAddresses() bool { return c.allowPrivate }
+
+func (rest *TestWebhookREST) TestCreateAndManage() {
+	fxt := tf.NewTestFixture(rest.T(), rest.DB, tf.Identities(2), tf.Spaces(1))
+	owner := testsupport.ServiceAsUser("Webhook-Service", *fxt.Identities[0])
+	other := testsupport.ServiceAsUser("Webhook-Service", *fxt.Identities[1])
+	spaceCtrl := NewSpaceWebhooksController(owner, rest.GormDB, rest.Configuration)
+	ctrl := NewWebhookController(owner, rest.GormDB, rest.Configuration)
+
+	_, created := test.CreateSpaceWebhooksCreated(rest.T(), owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID, newCreateWebhookPayload("https://ci.example.com/hook", webhook.EventWorkItemCreate))
+	require.NotNil(rest.T(), created.Data.ID)
+	assert.Equal(rest.T(), "https://ci.example.com/hook", *created.Data.Attributes.URL)
+	assert.Equal(rest.T(), []string{webhook.EventWorkItemCreate}, created.Data.Attributes.Events)
+	assert.True(rest.T(), *created.Data.Attributes.Active)
+	assert.Nil(rest.T(), created.Data.Attributes.Secret, "the secret must never be returned")
+
+	rest.T().Run("list", func(t *testing.T) {
+		_, list := test.ListSpaceWebhooksOK(t, owner.Context, owner, spaceCtrl, fxt.Spaces[0].ID)
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, *created.Data.ID, *list.Data[0].ID)
+	})
+	rest.T().Run("show", func(t *testing.T) {
+		_, shown := test.ShowWebhookOK(t, owner.Context, owner, ctrl, *created.Data.ID)
+		assert.Equal(t, *created.Data.Attributes.URL, *shown.Data.Attributes.URL)
+	})
+	rest.T().Run("update", func(t *testing.T) {
+		active := false
+		payload := &app.UpdateWebhookPayload{
+			Data: &app.Webhook{
+				Type: webhook.APIStringTypeWebhooks,
+				ID:   created.Data.ID,
+				Attributes: &app.WebhookAttributes{
+					Active:  &active,
+					Version: created.Data.Attributes.Version,
+				},
+			},
+		}
+		_, updated := test.UpdateWebhookOK(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+		assert.False(t, *updated.Data.Attributes.Active)
+		// the outdated version is rejected
+		test.UpdateWebhookConflict(t, owner.Context, owner, ctrl, *created.Data.ID, payload)
+	})
+	rest.T().Run("forbidden for non owners", func(t *testing.T) {
+		otherSpaceCtrl := NewSpaceWebhooksController(other, rest.GormDB, rest.Configuration)
+		otherCtrl := NewWebhookController(other, rest.GormDB, rest.Configuration)
+		test.CreateSpaceWebhooksForbidden(t, other.Context, other, otherSpaceCtrl, fxt.Spaces[0].ID, newCreateWebhookPayload("https://ci.example.com/other"))
+		test.ListSpaceWebhooksForbidden(t, other.Context, other, otherSpaceCtrl, fxt.Spaces[0].ID)
+		test.ShowWebhookForbidden(t, other.Context, other, otherCtrl, *created.Data.ID)
+		test.DeleteWebhookForbidden(t, other.Context, other, otherCtrl, *created.Data.ID)
+	})
+	rest.T().Run("limited by the token scopes of the owner", func(t *testing.T) {
+		limited := testsupport.ServiceAsPersonalAccessTokenUser("Webhook-Service", account.PersonalAccessToken{
+			ID:         uuid.NewV4(),
+			IdentityID: fxt.Identities[0].ID,
+			Scopes:     pq.StringArray{account.ScopeReadOnly, account.ScopeWorkItemWrite},
+		})
+		test.ListSpaceWebhooksForbidden(t, limited.Context, limited, NewSpaceWebhooksController(limited, rest.GormDB, rest.Configuration), fxt.Spaces[0].ID)
+		test.DeleteWebhookForbidden(t, limited.Context, limited, NewWebhookController(limited, rest.GormDB, rest.Configuration), *created.Data.ID)
+		spaceAdmin := testsupport.ServiceAsPersonalAccessTokenUser("Webhook-Service", account.PersonalAccessToken{
+			ID:         uuid.NewV4(),
+			IdentityID: fxt.Identities[0].ID,
+			Scopes:     pq.StringArray{account.ScopeSpaceAdmin},
+		})
+		test.ShowWebhookOK(t, spaceAdmin.Context, spaceAdmin, NewWebhookController(spaceAdmin, rest.GormDB, rest.Configuration), *created.Data.ID)
+	})
+	rest.T().Run("delete", func(t *testing.T) {
+		test.DeleteWebhookNoContent(t, owner.Context, owner, ctrl, *created.Data.ID)
+		test.ShowWebhookNotFound(t, owner.Context, owner, ctrl, *created.Data.ID)
+		test.DeleteWebhookNotFound(t, owner.Context, owner, ctrl, uuid.NewV4())
+	})
+}
+
+func (rest *TestWebhookREST) TestCreateInvalid() {
+	fxt := tf.NewTestFixture(rest.T(), rest.DB, tf.Identities(1), tf.Spaces(1))
+	svc := testsupport.ServiceAsUser("Webhook-Service", *fxt.Identities[0])
+	ctrl := NewSpaceWebhooksController(svc, rest.GormDB, rest.Configuration)
+
+	rest.T().Run("bad url", func(t *testing.T) {
+		test.CreateSpaceWebhooksBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateWebhookPayload("not-a-url"))
+	})
+	rest.T().Run("private address", func(t *testing.T) {
+		ctrl := NewSpaceWebhooksController(svc, rest.GormDB, webhookConfig{})
+		for _, url := range []string{"http://127.0.0.1:8080/hook", "http://169.254.169.254/latest/meta-data", "https://10.0.0.1/hook", "http://[::1]/hook"} {
+			test.CreateSpaceWebhooksBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateWebhookPayload(url))
+		}
+	})
+	rest.T().Run("unknown event", func(t *testing.T) {
+		test.CreateSpaceWebhooksBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newCreateWebhookPayload("https://ci.example.com/hook", "space.delete"))
+	})
+	rest.T().Run("unknown space", func(t *testing.T) {
+		test.CreateSpaceWebhooksNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), newCreateWebhookPayload("https://ci.example.com/hook"))
+	})
+	rest.T().Run("unauthorized", func(t *testing.T) {
+		unauthorized := goa.New("Webhook-Service")
+		unauthorizedCtrl := NewSpaceWebhooksController(unauthorized, rest.GormDB, rest.Configuration)
+		test.CreateSpaceWebhooksUnauthorized(t, unauthorized.Context, unauthorized, unauthorizedCtrl, fxt.Spaces[0].ID, newCreateWebhookPayload("https://ci.example.com/hook"))
+	})
+}
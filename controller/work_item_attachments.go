@@ -0,0 +1,201 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/attachment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// AttachmentsControllerConfiguration the configuration for the controllers of
+// attachments
+type AttachmentsControllerConfiguration interface {
+	GetAttachmentsMaxSize() int64
+	GetAttachmentsSpaceQuota() int64
+}
+
+// WorkItemAttachmentsController implements the work_item_attachments resource.
+type WorkItemAttachmentsController struct {
+	*goa.Controller
+	db     application.DB
+	store  attachment.BlobStore
+	config AttachmentsControllerConfiguration
+}
+
+// NewWorkItemAttachmentsController creates a work_item_attachments controller.
+func NewWorkItemAttachmentsController(service *goa.Service, db application.DB, store attachment.BlobStore, config AttachmentsControllerConfiguration) *WorkItemAttachmentsController {
+	return &WorkItemAttachmentsController{
+		Controller: service.NewController("WorkItemAttachmentsController"),
+		db:         db,
+		store:      store,
+		config:     config,
+	}
+}
+
+// authorizeAttachmentPermission returns a ForbiddenError unless the current
+// user has the given permission in the space of the attachments
+func authorizeAttachmentPermission(ctx context.Context, appl application.Application, currentUser, spaceID uuid.UUID, p authz.Permission) error {
+	s, err := appl.Spaces().Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	authorized, err := authorizeSpacePermission(ctx, currentUser, *s, p)
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error())
+	}
+	if !authorized {
+		return errors.NewForbiddenError(fmt.Sprintf("user is not allowed to %s in the space", p))
+	}
+	return nil
+}
+
+// List runs the list action.
+func (c *WorkItemAttachmentsController) List(ctx *app.ListWorkItemAttachmentsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	var attachments []attachment.Attachment
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeAttachmentPermission(ctx, appl, *currentUser, wi.SpaceID, authz.PermissionViewAttachments); err != nil {
+			return err
+		}
+		attachments, err = appl.Attachments().List(ctx, wi.ID, ctx.Comment)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	res := &app.AttachmentList{
+		Data: []*app.Attachment{},
+		Meta: &app.ListMeta{
+			TotalCount: len(attachments),
+		},
+	}
+	for _, a := range attachments {
+		res.Data = append(res.Data, ConvertAttachment(ctx.Request, a))
+	}
+	return ctx.OK(res)
+}
+
+// Upload runs the upload action.
+func (c *WorkItemAttachmentsController) Upload(ctx *app.UploadWorkItemAttachmentsContext) error {
+	currentUser, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, goa.ErrUnauthorized(err.Error()))
+	}
+	if ctx.Request.Body == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("body", nil).Expected("content of the file"))
+	}
+	defer ctx.Request.Body.Close()
+	a := attachment.Attachment{
+		WorkItemID:  ctx.WiID,
+		CreatorID:   *currentUser,
+		Filename:    ctx.Filename,
+		ContentType: ctx.Request.Header.Get("Content-Type"),
+	}
+	if ctx.Comment != nil {
+		a.CommentID = id.NullUUID{UUID: *ctx.Comment, Valid: true}
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		wi, err := appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		if err != nil {
+			return err
+		}
+		if err := authorizeAttachmentPermission(ctx, appl, *currentUser, wi.SpaceID, authz.PermissionEditWorkItem); err != nil {
+			return err
+		}
+		if ctx.Comment != nil {
+			cmt, err := appl.Comments().Load(ctx, *ctx.Comment)
+			if err != nil {
+				return err
+			}
+			if !uuid.Equal(cmt.ParentID, wi.ID) {
+				return errors.NewNotFoundError("comment", ctx.Comment.String())
+			}
+		}
+		a.SpaceID = wi.SpaceID
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	limits := attachment.Limits{
+		MaxSize:    c.config.GetAttachmentsMaxSize(),
+		SpaceQuota: c.config.GetAttachmentsSpaceQuota(),
+	}
+	// the content is uploaded before the space gets locked to check its quota
+	if err := attachment.PutContent(ctx, c.store, limits, &a, ctx.Request.Body); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		return attachment.CreateWithinQuota(ctx, appl.Attachments(), limits, &a)
+	})
+	if err != nil {
+		attachment.Remove(ctx, c.store, a)
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	log.Info(ctx, map[string]interface{}{
+		"attachment_id": a.ID,
+		"wi_id":         a.WorkItemID,
+		"size":          a.Size,
+	}, "file attached to work item")
+	ctx.ResponseData.Header().Set("Location", rest.AbsoluteURL(ctx.Request, app.AttachmentHref(a.ID)))
+	return ctx.Created(&app.AttachmentSingle{
+		Data: ConvertAttachment(ctx.Request, a),
+	})
+}
+
+// workItemIncludeAttachments adds the relationship to the attachments of the
+// work item and its comments
+func workItemIncludeAttachments(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) {
+	attachmentsRelated := rest.AbsoluteURL(request, app.WorkitemHref(wi.ID.String())) + "/attachments"
+	if wi2.Relationships.Attachments == nil {
+		wi2.Relationships.Attachments = &app.RelationGenericList{}
+	}
+	wi2.Relationships.Attachments.Links = &app.GenericLinks{
+		Related: &attachmentsRelated,
+	}
+}
+
+// workItemIncludeAttachmentList lists the attachments of the work item in its
+// relationships (include totalCount)
+func workItemIncludeAttachmentList(ctx context.Context, db application.DB, workItemID uuid.UUID) WorkItemConvertFunc {
+	var attachments []attachment.Attachment
+	err := application.Transactional(db, func(appl application.Application) error {
+		var err error
+		attachments, err = appl.Attachments().List(ctx, workItemID, nil)
+		return err
+	})
+	return func(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) error {
+		if err != nil {
+			return errs.Wrapf(err, "failed to list the attachments of work item %s", wi.ID)
+		}
+		workItemIncludeAttachments(request, wi, wi2)
+		for _, a := range attachments {
+			wi2.Relationships.Attachments.Data = append(wi2.Relationships.Attachments.Data, ConvertAttachmentSimple(request, a))
+		}
+		wi2.Relationships.Attachments.Meta = map[string]interface{}{
+			"totalCount": len(attachments),
+		}
+		return nil
+	}
+}
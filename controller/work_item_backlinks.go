@@ -0,0 +1,134 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/reference"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeBacklinks helps to avoid string literal
+const APIStringTypeBacklinks = "backlinks"
+
+// WorkItemBacklinksController implements the work_item_backlinks resource.
+type WorkItemBacklinksController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemBacklinksController creates a work_item_backlinks controller.
+func NewWorkItemBacklinksController(service *goa.Service, db application.DB) *WorkItemBacklinksController {
+	return &WorkItemBacklinksController{
+		Controller: service.NewController("WorkItemBacklinksController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemBacklinksController) List(ctx *app.ListWorkItemBacklinksContext) error {
+	var res *app.BacklinkList
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		if err := appl.WorkItems().CheckExists(ctx, ctx.WiID); err != nil {
+			return err
+		}
+		backlinks, err := appl.MarkupReferences().ListBacklinks(ctx, ctx.WiID)
+		if err != nil {
+			return err
+		}
+		res = &app.BacklinkList{
+			Data:     []*app.Backlink{},
+			Included: []interface{}{},
+			Meta: &app.ListMeta{
+				TotalCount: len(backlinks),
+			},
+		}
+		var sourceIDs []uuid.UUID
+		included := map[uuid.UUID]bool{}
+		for _, b := range backlinks {
+			res.Data = append(res.Data, ConvertBacklink(ctx.Request, b))
+			if !included[b.SourceWorkItemID] {
+				included[b.SourceWorkItemID] = true
+				sourceIDs = append(sourceIDs, b.SourceWorkItemID)
+			}
+		}
+		sources, err := appl.WorkItems().LoadBatchByID(ctx, sourceIDs)
+		if err != nil {
+			return errs.Wrap(err, "failed to load the mentioning work items")
+		}
+		wits, err := loadWorkItemTypesFromPtrArr(ctx, appl, sources)
+		if err != nil {
+			return err
+		}
+		for i, wi := range sources {
+			converted, err := ConvertWorkItem(ctx.Request, wits[i], *wi)
+			if err != nil {
+				return err
+			}
+			res.Included = append(res.Included, converted)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(res)
+}
+
+// ConvertBacklink converts a reference to a work item into the place in which
+// the work item is mentioned
+func ConvertBacklink(request *http.Request, ref reference.Reference) *app.Backlink {
+	workItemRelatedURL := rest.AbsoluteURL(request, app.WorkitemHref(ref.SourceWorkItemID.String()))
+	res := &app.Backlink{
+		Type: APIStringTypeBacklinks,
+		ID:   &ref.ID,
+		Attributes: &app.BacklinkAttributes{
+			Text:      ref.Text,
+			CreatedAt: &ref.CreatedAt,
+		},
+		Relationships: &app.BacklinkRelations{
+			Workitem: &app.RelationGeneric{
+				Data: &app.GenericData{
+					Type: ptr.String(APIStringTypeWorkItem),
+					ID:   ptr.String(ref.SourceWorkItemID.String()),
+				},
+				Links: &app.GenericLinks{
+					Self:    &workItemRelatedURL,
+					Related: &workItemRelatedURL,
+				},
+			},
+		},
+	}
+	if ref.SourceCommentID.Valid {
+		commentRelatedURL := rest.AbsoluteURL(request, app.CommentsHref(ref.SourceCommentID.UUID))
+		res.Relationships.Comment = &app.RelationGeneric{
+			Data: &app.GenericData{
+				Type: ptr.String(APIStringTypeComments),
+				ID:   ptr.String(ref.SourceCommentID.UUID.String()),
+			},
+			Links: &app.GenericLinks{
+				Self:    &commentRelatedURL,
+				Related: &commentRelatedURL,
+			},
+		}
+	}
+	return res
+}
+
+// workItemIncludeBacklinks adds the relationship to the descriptions and
+// comments in which the work item is mentioned
+func workItemIncludeBacklinks(request *http.Request, wi *workitem.WorkItem, wi2 *app.WorkItem) {
+	backlinksRelated := rest.AbsoluteURL(request, app.WorkitemHref(wi.ID.String())) + "/mentioned-in"
+	wi2.Relationships.MentionedIn = &app.RelationGeneric{
+		Links: &app.GenericLinks{
+			Related: &backlinksRelated,
+		},
+	}
+}
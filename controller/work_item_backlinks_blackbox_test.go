@@ -0,0 +1,111 @@
+package controller_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/reference"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	notificationsupport "github.com/fabric8-services/fabric8-wit/test/notification"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type workItemBacklinksSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestWorkItemBacklinks(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &workItemBacklinksSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *workItemBacklinksSuite) TestMentions() {
+	suffix := uuid.NewV4().String()
+	bob := "bob-" + suffix
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("alice-"+suffix, bob)),
+		tf.WorkItems(2, func(fxt *tf.TestFixture, idx int) error {
+			if idx == 0 {
+				fxt.WorkItems[idx].Fields[workitem.SystemDescription] = rendering.NewMarkupContent("cc @"+bob, rendering.SystemMarkupMarkdown)
+			}
+			return nil
+		}),
+	)
+	wi0, wi1 := fxt.WorkItems[0], fxt.WorkItems[1]
+	_, err := s.GormDB.MarkupReferences().Update(s.Ctx, reference.Description(wi0.ID), wi0.SpaceID, "cc @"+bob, rendering.SystemMarkupMarkdown)
+	require.NoError(s.T(), err)
+	channel := notificationsupport.FakeNotificationChannel{}
+	svc := testsupport.ServiceAsUser("WorkItemBacklinks-Service", *fxt.Identities[0])
+	commentsCtrl := NewNotifyingWorkItemCommentsController(svc, s.GormDB, &channel, s.Configuration)
+	ctrl := NewWorkItemBacklinksController(svc, s.GormDB)
+	var commentID uuid.UUID
+
+	s.T().Run("comment", func(t *testing.T) {
+		// when
+		markup := rendering.SystemMarkupMarkdown
+		_, c := test.CreateWorkItemCommentsOK(t, svc.Context, svc, commentsCtrl, wi0.ID, &app.CreateWorkItemCommentsPayload{
+			Data: &app.CreateComment{
+				Type: APIStringTypeComments,
+				Attributes: &app.CreateCommentAttributes{
+					Body:   fmt.Sprintf("@%s see #%d and #999999", bob, wi1.Number),
+					Markup: &markup,
+				},
+			},
+		})
+		// then
+		commentID = *c.Data.ID
+		rendered := *c.Data.Attributes.BodyRendered
+		assert.Contains(t, rendered, fmt.Sprintf(`/api/users/%s" class="mention"`, fxt.Identities[1].ID))
+		assert.Contains(t, rendered, fmt.Sprintf(`/api/workitems/%s" class="work-item-reference"`, wi1.ID))
+		assert.Contains(t, rendered, "and #999999")
+		require.Len(t, channel.Messages, 2)
+		assert.Equal(t, "comment.create", channel.Messages[0].MessageType)
+		mention := channel.Messages[1]
+		assert.Equal(t, "comment.mention", mention.MessageType)
+		assert.Equal(t, commentID.String(), mention.TargetID)
+		assert.Equal(t, map[string]interface{}{"mentioned_id": fxt.Identities[1].ID.String()}, mention.Custom)
+	})
+
+	s.T().Run("description", func(t *testing.T) {
+		// when
+		_, wi := test.ShowWorkitemOK(t, svc.Context, svc, NewWorkitemController(svc, s.GormDB, s.Configuration), wi0.ID, nil, nil)
+		// then
+		assert.Contains(t, wi.Data.Attributes[workitem.SystemDescriptionRendered], fmt.Sprintf(`/api/users/%s" class="mention"`, fxt.Identities[1].ID))
+		require.NotNil(t, wi.Data.Relationships.MentionedIn)
+		assert.Contains(t, *wi.Data.Relationships.MentionedIn.Links.Related, fmt.Sprintf("/api/workitems/%s/mentioned-in", wi0.ID))
+	})
+
+	s.T().Run("backlinks", func(t *testing.T) {
+		// when
+		_, list := test.ListWorkItemBacklinksOK(t, svc.Context, svc, ctrl, wi1.ID)
+		// then
+		require.Len(t, list.Data, 1)
+		assert.Equal(t, 1, list.Meta.TotalCount)
+		backlink := list.Data[0]
+		assert.Equal(t, fmt.Sprintf("#%d", wi1.Number), backlink.Attributes.Text)
+		assert.Equal(t, wi0.ID.String(), *backlink.Relationships.Workitem.Data.ID)
+		assert.Equal(t, commentID.String(), *backlink.Relationships.Comment.Data.ID)
+		require.Len(t, list.Included, 1)
+		assert.Equal(t, wi0.ID, *list.Included[0].(*app.WorkItem).ID)
+		// nothing mentions the first work item
+		_, list = test.ListWorkItemBacklinksOK(t, svc.Context, svc, ctrl, wi0.ID)
+		assert.Empty(t, list.Data)
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		svc := goa.New("WorkItemBacklinks-Service")
+		test.ListWorkItemBacklinksNotFound(t, svc.Context, svc, NewWorkItemBacklinksController(svc, s.GormDB), uuid.NewV4())
+	})
+}
@@ -62,7 +62,7 @@ func (c *WorkItemBoardController) Show(ctx *app.ShowWorkItemBoardContext) error
 // ConvertColumnsFromModel converts WorkitemTypeBoard model to a response
 // resource object for jsonapi.org specification
 func ConvertColumnsFromModel(request *http.Request, column workitem.BoardColumn) *app.WorkItemBoardColumnData {
-	return &app.WorkItemBoardColumnData{
+	res := &app.WorkItemBoardColumnData{
 		ID:   column.ID,
 		Type: APIBoardColumns,
 		Attributes: &app.WorkItemBoardColumnAttributes{
@@ -70,6 +70,12 @@ func ConvertColumnsFromModel(request *http.Request, column workitem.BoardColumn)
 			Order: &column.Order,
 		},
 	}
+	// columns without work in progress limit don't show it
+	if column.WIPLimit > 0 {
+		res.Attributes.WipLimit = &column.WIPLimit
+		res.Attributes.WipLimitEnforced = &column.WIPLimitEnforced
+	}
+	return res
 }
 
 // ConvertBoardFromModel converts WorkitemTypeBoard model to a response resource
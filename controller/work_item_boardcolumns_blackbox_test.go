@@ -15,6 +15,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/resource"
 	testsupport "github.com/fabric8-services/fabric8-wit/test"
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/goadesign/goa"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -227,6 +228,58 @@ func (l *TestWorkItemBoardcolumnREST) TestRemoveAllBoardcolumns() {
 	})
 }
 
+func (l *TestWorkItemBoardcolumnREST) TestMoveWIAppliesTransitionRule() {
+	fxt := tf.NewTestFixture(l.T(), l.DB, tf.CreateWorkItemEnvironment(), tf.WorkItems(1), tf.WorkItemBoards(1))
+	svc, ctrl := l.SecuredController(*fxt.Identities[0])
+	payload := func(version interface{}, columnIndex int) app.UpdateWorkitemPayload {
+		return app.UpdateWorkitemPayload{
+			Data: &app.WorkItem{
+				ID:   &fxt.WorkItems[0].ID,
+				Type: APIStringTypeWorkItem,
+				Attributes: map[string]interface{}{
+					"version": version,
+				},
+				Relationships: &app.WorkItemRelationships{
+					SystemBoardcolumns: &app.RelationGenericList{
+						Data: []*app.GenericData{
+							{
+								ID:   ptr.String(fxt.WorkItemBoards[0].Columns[columnIndex].ID.String()),
+								Type: ptr.String("boardcolumns"),
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	l.T().Run("move into the \"In Progress\" column", func(t *testing.T) {
+		// given
+		u := payload(fxt.WorkItems[0].Version, 1)
+		// when
+		_, updatedWI := test.UpdateWorkitemOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+		// then
+		assert.Equal(t, workitem.SystemStateInProgress, updatedWI.Data.Attributes[workitem.SystemState])
+
+		t.Run("move into the \"Resolved\" column with a contradicting state", func(t *testing.T) {
+			// given
+			u := payload(updatedWI.Data.Attributes["version"], 2)
+			u.Data.Attributes[workitem.SystemState] = workitem.SystemStateNew
+			// when
+			test.UpdateWorkitemBadRequest(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+		})
+
+		t.Run("move into the \"Resolved\" column", func(t *testing.T) {
+			// given
+			u := payload(updatedWI.Data.Attributes["version"], 2)
+			// when
+			_, updatedWI := test.UpdateWorkitemOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, &u)
+			// then
+			assert.Equal(t, workitem.SystemStateResolved, updatedWI.Data.Attributes[workitem.SystemState])
+		})
+	})
+}
+
 /* FIXME(michaelkleinhenz): Add tests as soon as isValid is added to workitem.go
 func (l *TestWorkItemBoardcolumnREST) TestFailInvalidLabel() {
 	fxt := tf.NewTestFixture(l.T(), l.DB, tf.Spaces(1), tf.Iterations(1), tf.Areas(1), tf.WorkItems(1))
@@ -11,6 +11,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/workitem"
@@ -54,6 +55,7 @@ func NewNotifyingWorkItemCommentsController(service *goa.Service, db application
 // Create runs the create action.
 func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsContext) error {
 	var newComment comment.Comment
+	var mentions []reference.Reference
 	err := application.Transactional(c.db, func(appl application.Application) error {
 		_, err := appl.WorkItems().LoadByID(ctx, ctx.WiID)
 		if err != nil {
@@ -90,9 +92,17 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 		if err != nil {
 			return goa.ErrInternal(err.Error())
 		}
+		mentions, err = updateCommentReferences(ctx, appl, newComment)
+		if err != nil {
+			return err
+		}
+		includeReferences, err := commentIncludeReferences(ctx, appl, newComment)
+		if err != nil {
+			return err
+		}
 
 		res := &app.CommentSingle{
-			Data: ConvertComment(ctx.Request, newComment),
+			Data: ConvertComment(ctx.Request, newComment, includeReferences),
 		}
 		return ctx.OK(res)
 	})
@@ -101,6 +111,9 @@ func (c *WorkItemCommentsController) Create(ctx *app.CreateWorkItemCommentsConte
 	}
 	if ctx.ResponseData.Status == 200 {
 		c.notification.Send(ctx, notification.NewCommentCreated(newComment.ID.String()))
+		notifyMentions(ctx, c.notification, mentions, newComment.Creator, func(mentionedID uuid.UUID) notification.Message {
+			return notification.NewCommentMention(newComment.ID.String(), mentionedID)
+		})
 	}
 	return nil
 }
@@ -118,11 +131,15 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 		if err != nil {
 			return goa.ErrInternal(err.Error())
 		}
+		includeReferences, err := commentIncludeReferences(ctx, appl, comments...)
+		if err != nil {
+			return err
+		}
 		return ctx.ConditionalEntities(comments, c.config.GetCacheControlComments, func() error {
 			res := &app.CommentList{}
 			res.Data = []*app.Comment{}
 			res.Meta = &app.CommentListMeta{TotalCount: count}
-			res.Data = ConvertComments(ctx.Request, comments)
+			res.Data = ConvertComments(ctx.Request, comments, includeReferences)
 			res.Links = &app.PagingLinks{}
 			setPagingLinks(res.Links, buildAbsoluteURL(ctx.Request), len(comments), offset, limit, count)
 			return ctx.OK(res)
@@ -134,6 +151,39 @@ func (c *WorkItemCommentsController) List(ctx *app.ListWorkItemCommentsContext)
 	return nil
 }
 
+// Threads runs the threads action.
+func (c *WorkItemCommentsController) Threads(ctx *app.ThreadsWorkItemCommentsContext) error {
+	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
+	var res *app.CommentList
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		err := appl.WorkItems().CheckExists(ctx, ctx.WiID)
+		if err != nil {
+			return goa.ErrNotFound(err.Error())
+		}
+		comments, tc, err := appl.Comments().ListThreads(ctx, ctx.WiID, &offset, &limit)
+		if err != nil {
+			return err
+		}
+		data, included, err := ConvertCommentThreads(ctx, appl, ctx.Request, comments)
+		if err != nil {
+			return err
+		}
+		count := int(tc)
+		res = &app.CommentList{
+			Data:     data,
+			Included: included,
+			Meta:     &app.CommentListMeta{TotalCount: count},
+			Links:    &app.PagingLinks{},
+		}
+		setPagingLinks(res.Links, buildAbsoluteURL(ctx.Request), len(comments), offset, limit, count)
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(res)
+}
+
 // Relations runs the relation action.
 // TODO: Should only return Resource Identifier Objects, not complete object (See List)
 func (c *WorkItemCommentsController) Relations(ctx *app.RelationsWorkItemCommentsContext) error {
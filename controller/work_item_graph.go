@@ -0,0 +1,176 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWorkItemGraph is the JSON-API type of work item graphs
+const APIStringTypeWorkItemGraph = "workitemgraphs"
+
+// WorkItemGraphController implements the work_item_graph resource.
+type WorkItemGraphController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemGraphController creates a work_item_graph controller.
+func NewWorkItemGraphController(service *goa.Service, db application.DB) *WorkItemGraphController {
+	return &WorkItemGraphController{
+		Controller: service.NewController("WorkItemGraphController"),
+		db:         db,
+	}
+}
+
+// workItemGraph is a graph of work item links together with the work items of
+// the graph and, if requested, its critical path
+type workItemGraph struct {
+	link.Graph
+	workItems    map[uuid.UUID]workitem.WorkItem
+	estimate     string
+	criticalPath *link.CriticalPath
+}
+
+// loadWorkItemGraph builds the graph of the given link type from the given
+// work item and computes its critical path if requested
+func (c *WorkItemGraphController) loadWorkItemGraph(ctx context.Context, wiID, linkTypeID uuid.UUID, direction string, depth int, criticalPath bool, estimate string) (*workItemGraph, error) {
+	result := workItemGraph{workItems: map[uuid.UUID]workitem.WorkItem{}, estimate: estimate}
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		graph, err := appl.WorkItemLinks().GetGraph(ctx, linkTypeID, wiID, link.GraphDirection(direction), depth)
+		if err != nil {
+			return errs.Wrapf(err, "failed to build the graph of work item %s", wiID)
+		}
+		result.Graph = *graph
+		ids := make([]uuid.UUID, len(graph.Nodes))
+		for i, n := range graph.Nodes {
+			ids[i] = n.ID
+		}
+		wis, err := appl.WorkItems().LoadBatchByID(ctx, ids)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load the work items of the graph of work item %s", wiID)
+		}
+		for _, wi := range wis {
+			result.workItems[wi.ID] = *wi
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if criticalPath {
+		result.criticalPath, err = result.CriticalPath(result.weight)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &result, nil
+}
+
+// weight returns the estimate of the given work item or 0 if it has none
+func (g workItemGraph) weight(id uuid.UUID) float64 {
+	switch v := g.workItems[id].Fields[g.estimate].(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+// label returns the number and title of the given work item
+func (g workItemGraph) label(id uuid.UUID) string {
+	wi, ok := g.workItems[id]
+	if !ok {
+		return id.String()
+	}
+	return fmt.Sprintf("#%d %v", wi.Number, wi.Fields[workitem.SystemTitle])
+}
+
+// Show runs the show action.
+func (c *WorkItemGraphController) Show(ctx *app.ShowWorkItemGraphContext) error {
+	graph, err := c.loadWorkItemGraph(ctx, ctx.WiID, ctx.LinkType, ctx.Direction, ctx.Depth, ctx.CriticalPath, ctx.Estimate)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkItemGraphSingle{
+		Data: convertWorkItemGraph(ctx.Request, *graph),
+	})
+}
+
+// Dot runs the dot action.
+func (c *WorkItemGraphController) Dot(ctx *app.DotWorkItemGraphContext) error {
+	graph, err := c.loadWorkItemGraph(ctx, ctx.WiID, ctx.LinkType, ctx.Direction, ctx.Depth, ctx.CriticalPath, ctx.Estimate)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK([]byte(graph.DOT(graph.label, graph.criticalPath)))
+}
+
+// convertWorkItemGraph converts from internal to external REST representation
+func convertWorkItemGraph(request *http.Request, graph workItemGraph) *app.WorkItemGraph {
+	selfURL := rest.AbsoluteURL(request, app.WorkItemGraphHref(graph.RootID))
+	relatedURL := rest.AbsoluteURL(request, app.WorkitemHref(graph.RootID))
+	nodes := make([]*app.WorkItemGraphNode, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		node := &app.WorkItemGraphNode{
+			ID:    n.ID,
+			Depth: n.Depth,
+		}
+		if wi, ok := graph.workItems[n.ID]; ok {
+			number := wi.Number
+			node.Number = &number
+			if title, ok := wi.Fields[workitem.SystemTitle].(string); ok {
+				node.Title = &title
+			}
+		}
+		if graph.criticalPath != nil {
+			estimate := graph.weight(n.ID)
+			node.Estimate = &estimate
+		}
+		nodes[i] = node
+	}
+	edges := make([]*app.WorkItemGraphEdge, len(graph.Edges))
+	for i, e := range graph.Edges {
+		edges[i] = &app.WorkItemGraphEdge{
+			ID:     e.ID,
+			Source: e.SourceID,
+			Target: e.TargetID,
+		}
+	}
+	attributes := &app.WorkItemGraphAttributes{
+		LinkType:  graph.LinkTypeID,
+		Direction: graph.Direction.String(),
+		Nodes:     nodes,
+		Edges:     edges,
+	}
+	if graph.criticalPath != nil {
+		attributes.CriticalPath = &app.WorkItemCriticalPath{
+			WorkItems: graph.criticalPath.IDs,
+			Estimate:  graph.criticalPath.Weight,
+		}
+	}
+	return &app.WorkItemGraph{
+		Type:       APIStringTypeWorkItemGraph,
+		ID:         &graph.RootID,
+		Attributes: attributes,
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &relatedURL,
+		},
+	}
+}
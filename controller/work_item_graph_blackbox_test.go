@@ -0,0 +1,90 @@
+package controller_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemGraphREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkItemGraphREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemGraphREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *TestWorkItemGraphREST) TestShowAndDot() {
+	// given "A blocks B", "B blocks D", "A blocks C" and "C blocks D"
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItems(4, tf.SetWorkItemTitles("A", "B", "C", "D")),
+		tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyDependency)),
+		tf.WorkItemLinks(4, tf.BuildLinks(tf.L("A", "B"), tf.L("B", "D"), tf.L("A", "C"), tf.L("C", "D"))),
+	)
+	svc := goa.New("WorkItemGraph-Service")
+	ctrl := NewWorkItemGraphController(svc, s.GormDB)
+	linkTypeID := fxt.WorkItemLinkTypes[0].ID
+	D := fxt.WorkItemByTitle("D")
+
+	s.T().Run("show", func(t *testing.T) {
+		_, graph := test.ShowWorkItemGraphOK(t, svc.Context, svc, ctrl, D.ID, false, -1, "reverse", "effort", linkTypeID)
+		require.NotNil(t, graph.Data.Attributes)
+		assert.Equal(t, D.ID, *graph.Data.ID)
+		require.Len(t, graph.Data.Attributes.Nodes, 4)
+		assert.Equal(t, D.ID, graph.Data.Attributes.Nodes[0].ID)
+		assert.Equal(t, 0, graph.Data.Attributes.Nodes[0].Depth)
+		require.NotNil(t, graph.Data.Attributes.Nodes[0].Title)
+		assert.Equal(t, "D", *graph.Data.Attributes.Nodes[0].Title)
+		assert.Nil(t, graph.Data.Attributes.Nodes[0].Estimate)
+		assert.Len(t, graph.Data.Attributes.Edges, 4)
+		assert.Nil(t, graph.Data.Attributes.CriticalPath)
+	})
+
+	s.T().Run("show critical path", func(t *testing.T) {
+		// the work item numbers serve as estimates
+		_, graph := test.ShowWorkItemGraphOK(t, svc.Context, svc, ctrl, D.ID, true, -1, "reverse", workitem.SystemNumber, linkTypeID)
+		require.NotNil(t, graph.Data.Attributes.CriticalPath)
+		path := graph.Data.Attributes.CriticalPath
+		C := fxt.WorkItemByTitle("C")
+		assert.Equal(t, []uuid.UUID{fxt.WorkItemByTitle("A").ID, C.ID, D.ID}, path.WorkItems)
+		assert.Equal(t, float64(fxt.WorkItemByTitle("A").Number+C.Number+D.Number), path.Estimate)
+		require.NotNil(t, graph.Data.Attributes.Nodes[0].Estimate)
+		assert.Equal(t, float64(D.Number), *graph.Data.Attributes.Nodes[0].Estimate)
+	})
+
+	s.T().Run("dot", func(t *testing.T) {
+		rw := test.DotWorkItemGraphOK(t, svc.Context, svc, ctrl, D.ID, true, 1, "reverse", workitem.SystemNumber, linkTypeID)
+		body := rw.(*httptest.ResponseRecorder).Body.String()
+		assert.True(t, strings.HasPrefix(body, `digraph "`+D.ID.String()+`" {`), body)
+		assert.Contains(t, body, `"`+fxt.WorkItemByTitle("C").ID.String()+`" -> "`+D.ID.String()+`" [color=red, penwidth=2];`)
+		assert.Contains(t, body, `"`+fxt.WorkItemByTitle("B").ID.String()+`" -> "`+D.ID.String()+`";`)
+		assert.NotContains(t, body, fxt.WorkItemByTitle("A").ID.String())
+	})
+
+	s.T().Run("unknown work item", func(t *testing.T) {
+		test.ShowWorkItemGraphNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), false, -1, "forward", "effort", linkTypeID)
+	})
+
+	s.T().Run("critical path with cycle", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItems(2, tf.SetWorkItemTitles("X", "Y")),
+			tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyNetwork)),
+			tf.WorkItemLinks(2, tf.BuildLinks(tf.L("X", "Y"), tf.L("Y", "X"))),
+		)
+		test.ShowWorkItemGraphBadRequest(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, true, -1, "forward", "effort", fxt.WorkItemLinkTypes[0].ID)
+	})
+}
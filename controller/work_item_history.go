@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/event"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// WorkItemHistoryController implements the work_item_history resource.
+type WorkItemHistoryController struct {
+	*goa.Controller
+	db           application.DB
+	config       WorkItemHistoryControllerConfig
+	notification notification.Channel
+}
+
+// WorkItemHistoryControllerConfig the config interface for the WorkItemHistoryController
+type WorkItemHistoryControllerConfig interface {
+	GetCacheControlEvents() string
+}
+
+// NewWorkItemHistoryController creates a work_item_history controller.
+func NewWorkItemHistoryController(service *goa.Service, db application.DB, config WorkItemHistoryControllerConfig) *WorkItemHistoryController {
+	return NewNotifyingWorkItemHistoryController(service, db, &notification.DevNullChannel{}, config)
+}
+
+// NewNotifyingWorkItemHistoryController creates a work_item_history controller with notification broadcast.
+func NewNotifyingWorkItemHistoryController(service *goa.Service, db application.DB, notificationChannel notification.Channel, config WorkItemHistoryControllerConfig) *WorkItemHistoryController {
+	n := notificationChannel
+	if n == nil {
+		n = &notification.DevNullChannel{}
+	}
+	return &WorkItemHistoryController{
+		Controller:   service.NewController("WorkItemHistoryController"),
+		db:           db,
+		notification: n,
+		config:       config}
+}
+
+// Show runs the show action.
+func (c *WorkItemHistoryController) Show(ctx *app.ShowWorkItemHistoryContext) error {
+	var history *event.History
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		history, err = appl.Events().History(ctx, ctx.WiID, ctx.From, ctx.To)
+		return errs.Wrap(err, "work item history model failed")
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.ConditionalRequest(*history, c.config.GetCacheControlEvents, func() error {
+		return ctx.OK(&app.WorkItemHistorySingle{
+			Data: ConvertWorkItemHistory(ctx.Request, *history),
+		})
+	})
+}
+
+// Restore runs the restore action.
+func (c *WorkItemHistoryController) Restore(ctx *app.RestoreWorkItemHistoryContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var wi *workitem.WorkItem
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		wi, err = appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	creator := wi.Fields[workitem.SystemCreator]
+	if creator == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, errs.New("work item doesn't have creator")))
+	}
+	authorized, err := authorizeWorkitemEditor(ctx, c.db, wi.SpaceID, creator.(string), currentUserIdentityID.String())
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if !authorized {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
+	}
+	var wit *workitem.WorkItemType
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		wi, err = appl.WorkItems().Restore(ctx, ctx.WiID, ctx.Version, *currentUserIdentityID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to restore work item %s to version %d", ctx.WiID, ctx.Version)
+		}
+		wit, err = appl.WorkItemTypes().Load(ctx.Context, wi.Type)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load work item type: %s", wi.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	c.notification.Send(ctx, notification.NewWorkItemUpdated(ctx.WiID.String()))
+	converted, err := ConvertWorkItem(ctx.Request, *wit, *wi, workItemIncludeHasChildren(ctx, c.db))
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
+	return ctx.OK(&app.WorkItemSingle{
+		Data: converted,
+		Links: &app.WorkItemLinks{
+			Self: buildAbsoluteURL(ctx.Request),
+		},
+	})
+}
+
+// ConvertWorkItemHistory converts from internal to external REST representation
+func ConvertWorkItemHistory(request *http.Request, history event.History) *app.WorkItemHistory {
+	selfURL := rest.AbsoluteURL(request, app.WorkItemHistoryHref(history.WorkItemID))
+	relatedURL := rest.AbsoluteURL(request, app.WorkitemHref(history.WorkItemID))
+	fields := []*app.WorkItemFieldChange{}
+	for _, f := range history.Fields {
+		fields = append(fields, &app.WorkItemFieldChange{
+			Name:     f.Name,
+			OldValue: f.Old,
+			NewValue: f.New,
+			Added:    f.Added,
+			Removed:  f.Removed,
+		})
+	}
+	links := []*app.WorkItemLinkChange{}
+	for _, l := range history.Links {
+		links = append(links, &app.WorkItemLinkChange{
+			ID:        l.LinkID,
+			Change:    l.Change,
+			LinkType:  l.LinkTypeID,
+			Source:    l.SourceID,
+			Target:    l.TargetID,
+			Timestamp: l.Timestamp,
+			Modifier:  l.Modifier,
+		})
+	}
+	return &app.WorkItemHistory{
+		Type: event.APIStringTypeHistory,
+		ID:   &history.WorkItemID,
+		Attributes: &app.WorkItemHistoryAttributes{
+			From:          history.FromVersion,
+			To:            history.ToVersion,
+			FromTimestamp: &history.FromTime,
+			ToTimestamp:   &history.ToTime,
+			Fields:        fields,
+			Links:         links,
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &relatedURL,
+		},
+	}
+}
@@ -0,0 +1,68 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemHistoryREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkItemHistoryREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemHistoryREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *TestWorkItemHistoryREST) TestShowAndRestore() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.CreateWorkItemEnvironment(), tf.WorkItems(1, func(fxt *tf.TestFixture, idx int) error {
+		fxt.WorkItems[idx].Fields[workitem.SystemTitle] = "old title"
+		return nil
+	}))
+	oldVersion := fxt.WorkItems[0].Version
+	fxt.WorkItems[0].Fields[workitem.SystemTitle] = "new title"
+	_, err := workitem.NewWorkItemRepository(s.DB).Save(s.Ctx, fxt.WorkItems[0].SpaceID, *fxt.WorkItems[0], fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	svc := testsupport.ServiceAsSpaceUser("History-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+	ctrl := NewWorkItemHistoryController(svc, s.GormDB, s.Configuration)
+
+	s.T().Run("show", func(t *testing.T) {
+		_, history := test.ShowWorkItemHistoryOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, nil, nil, nil, nil)
+		require.NotNil(t, history.Data.Attributes)
+		assert.Equal(t, oldVersion, history.Data.Attributes.From)
+		assert.Equal(t, oldVersion+1, history.Data.Attributes.To)
+		require.Len(t, history.Data.Attributes.Fields, 1)
+		assert.Equal(t, workitem.SystemTitle, history.Data.Attributes.Fields[0].Name)
+		assert.Equal(t, "old title", history.Data.Attributes.Fields[0].OldValue)
+		assert.Equal(t, "new title", history.Data.Attributes.Fields[0].NewValue)
+		assert.Empty(t, history.Data.Attributes.Links)
+	})
+
+	s.T().Run("show unknown version", func(t *testing.T) {
+		to := 42
+		test.ShowWorkItemHistoryNotFound(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, nil, &to, nil, nil)
+	})
+
+	s.T().Run("restore unauthorized", func(t *testing.T) {
+		unauthorized := goa.New("History-Service")
+		unauthorizedCtrl := NewWorkItemHistoryController(unauthorized, s.GormDB, s.Configuration)
+		test.RestoreWorkItemHistoryUnauthorized(t, unauthorized.Context, unauthorized, unauthorizedCtrl, fxt.WorkItems[0].ID, oldVersion)
+	})
+
+	s.T().Run("restore", func(t *testing.T) {
+		_, wi := test.RestoreWorkItemHistoryOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID, oldVersion)
+		assert.Equal(t, "old title", wi.Data.Attributes[workitem.SystemTitle])
+		assert.Equal(t, oldVersion+2, wi.Data.Attributes[workitem.SystemVersion])
+	})
+}
@@ -288,12 +288,15 @@ func (c *WorkItemLinkController) checkWorkItemCreatorOrSpaceOwner(ctx context.Co
 	if err != nil {
 		return false, nil, err
 	}
-	creator := wi.Fields[workitem.SystemCreator]
-	if currentIdentityID.String() == creator {
+	creator, _ := wi.Fields[workitem.SystemCreator].(string)
+	if authz.AuthorizeOwner(ctx, currentIdentityID, uuid.FromStringOrNil(creator), authz.PermissionEditWorkItem) {
 		return true, nil, nil
 	}
 	space, err := appl.Spaces().Load(ctx, wi.SpaceID)
-	return currentIdentityID == space.OwnerID, &wi.SpaceID, nil
+	if err != nil {
+		return false, nil, err
+	}
+	return authz.AuthorizeOwner(ctx, currentIdentityID, space.OwnerID, authz.PermissionEditWorkItem), &wi.SpaceID, nil
 }
 
 type deleteWorkItemLinkFuncs interface {
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/rest"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWorkItemSyncStatus is the JSON-API type of work item sync
+// statuses
+const APIStringTypeWorkItemSyncStatus = "workitemsyncstatus"
+
+// WorkItemSyncController implements the work_item_sync resource.
+type WorkItemSyncController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemSyncController creates a work_item_sync controller.
+func NewWorkItemSyncController(service *goa.Service, db application.DB) *WorkItemSyncController {
+	return &WorkItemSyncController{
+		Controller: service.NewController("WorkItemSyncController"),
+		db:         db,
+	}
+}
+
+// Show runs the show action.
+func (c *WorkItemSyncController) Show(ctx *app.ShowWorkItemSyncContext) error {
+	var ti *remoteworkitem.TrackerItem
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		ti, err = appl.SyncStatuses().Load(ctx, ctx.WiID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkItemSyncStatusSingle{
+		Data: ConvertWorkItemSyncStatus(ctx.Request, ctx.WiID, *ti),
+	})
+}
+
+// Resolve runs the resolve action.
+func (c *WorkItemSyncController) Resolve(ctx *app.ResolveWorkItemSyncContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	var wi *workitem.WorkItem
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		wi, err = appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		return err
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	creator, _ := wi.Fields[workitem.SystemCreator].(string)
+	authorized, err := authorizeWorkitemEditor(ctx, c.db, wi.SpaceID, creator, currentUserIdentityID.String())
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if !authorized {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
+	}
+	var ti *remoteworkitem.TrackerItem
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		ti, err = appl.SyncStatuses().Resolve(ctx, ctx.WiID, ctx.Keep == "local")
+		return errs.Wrapf(err, "failed to resolve the sync conflict of work item %s", ctx.WiID)
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	return ctx.OK(&app.WorkItemSyncStatusSingle{
+		Data: ConvertWorkItemSyncStatus(ctx.Request, ctx.WiID, *ti),
+	})
+}
+
+// ConvertWorkItemSyncStatus converts from internal to external REST
+// representation
+func ConvertWorkItemSyncStatus(request *http.Request, workItemID uuid.UUID, ti remoteworkitem.TrackerItem) *app.WorkItemSyncStatus {
+	selfURL := rest.AbsoluteURL(request, app.WorkItemSyncHref(workItemID))
+	relatedURL := rest.AbsoluteURL(request, app.WorkitemHref(workItemID))
+	return &app.WorkItemSyncStatus{
+		Type: APIStringTypeWorkItemSyncStatus,
+		ID:   &workItemID,
+		Attributes: &app.WorkItemSyncStatusAttributes{
+			RemoteItemID:    ti.RemoteItemID,
+			SyncedVersion:   ti.SyncedVersion,
+			RemoteUpdatedAt: ti.RemoteUpdatedAt,
+			LastPulledAt:    ti.LastPulledAt,
+			LastPushedAt:    ti.LastPushedAt,
+			Conflict:        ti.SyncConflict,
+			Error:           ti.SyncError,
+		},
+		Links: &app.GenericLinks{
+			Self:    &selfURL,
+			Related: &relatedURL,
+		},
+	}
+}
@@ -0,0 +1,108 @@
+package controller_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type workItemSyncSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkItemSyncREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &workItemSyncSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// recordingPusher accepts every push
+type recordingPusher struct {
+	pushes int
+}
+
+func (p *recordingPusher) Push(item remoteworkitem.AttributeAccessor, changes remoteworkitem.RemoteChanges) (*time.Time, error) {
+	p.pushes++
+	return nil, nil
+}
+
+func syncedGithubIssue(title string, updatedAt time.Time) remoteworkitem.TrackerItemContent {
+	return remoteworkitem.TrackerItemContent{
+		ID: "https://api.github.com/repos/sync/controller/issues/1",
+		Content: []byte(fmt.Sprintf(`{
+			"title": "%s",
+			"url": "https://api.github.com/repos/sync/controller/issues/1",
+			"state": "open",
+			"updated_at": "%s",
+			"user": {"login": "jdoe", "url": "https://api.github.com/users/jdoe"},
+			"assignees": []
+		}`, title, updatedAt.Format(time.RFC3339))),
+	}
+}
+
+func (s *workItemSyncSuite) TestShowAndResolve() {
+	// given a work item whose remote item and local copy both changed
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Identities(2))
+	trackerID := fxt.Trackers[0].ID
+	t1 := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	pusher := &recordingPusher{}
+	ti, err := remoteworkitem.Sync(s.Ctx, s.DB, trackerID, syncedGithubIssue("remote title", t1), remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	wi.Fields[workitem.SystemTitle] = "local title"
+	_, err = wiRepo.Save(s.Ctx, wi.SpaceID, *wi, fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	_, err = remoteworkitem.Sync(s.Ctx, s.DB, trackerID, syncedGithubIssue("another remote title", t1.Add(time.Hour)), remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+
+	svc := testsupport.ServiceAsSpaceUser("Sync-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+	ctrl := NewWorkItemSyncController(svc, s.GormDB)
+
+	s.T().Run("show", func(t *testing.T) {
+		_, res := test.ShowWorkItemSyncOK(t, svc.Context, svc, ctrl, wi.ID)
+		assert.Equal(t, wi.ID, *res.Data.ID)
+		assert.Equal(t, ti.RemoteItemID, res.Data.Attributes.RemoteItemID)
+		assert.NotNil(t, res.Data.Attributes.Conflict)
+		assert.NotNil(t, res.Data.Attributes.LastPulledAt)
+	})
+	s.T().Run("show unknown work item", func(t *testing.T) {
+		test.ShowWorkItemSyncNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+	s.T().Run("resolve forbidden for other users", func(t *testing.T) {
+		other := testsupport.ServiceAsSpaceUser("Sync-Service", *fxt.Identities[1], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		otherCtrl := NewWorkItemSyncController(other, s.GormDB)
+		test.ResolveWorkItemSyncForbidden(t, other.Context, other, otherCtrl, wi.ID, "remote")
+	})
+	s.T().Run("resolve unauthorized", func(t *testing.T) {
+		unauthorized := goa.New("Sync-Service")
+		unauthorizedCtrl := NewWorkItemSyncController(unauthorized, s.GormDB)
+		test.ResolveWorkItemSyncUnauthorized(t, unauthorized.Context, unauthorized, unauthorizedCtrl, wi.ID, "remote")
+	})
+	s.T().Run("resolve keeping the remote side", func(t *testing.T) {
+		_, res := test.ResolveWorkItemSyncOK(t, svc.Context, svc, ctrl, wi.ID, "remote")
+		assert.Nil(t, res.Data.Attributes.Conflict)
+		loaded, err := wiRepo.LoadByID(s.Ctx, wi.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "another remote title", loaded.Fields[workitem.SystemTitle])
+		assert.Equal(t, 0, pusher.pushes)
+	})
+	s.T().Run("resolve unknown work item", func(t *testing.T) {
+		test.ResolveWorkItemSyncNotFound(t, svc.Context, svc, ctrl, uuid.NewV4(), "local")
+	})
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/jsonapi"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+)
+
+// APIStringTypeWorkItemTransition is the JSON-API type of work item transitions
+const APIStringTypeWorkItemTransition = "workitemtransitions"
+
+// WorkItemTransitionsController implements the work_item_transitions resource.
+type WorkItemTransitionsController struct {
+	*goa.Controller
+	db application.DB
+}
+
+// NewWorkItemTransitionsController creates a work_item_transitions controller.
+func NewWorkItemTransitionsController(service *goa.Service, db application.DB) *WorkItemTransitionsController {
+	return &WorkItemTransitionsController{
+		Controller: service.NewController("WorkItemTransitionsController"),
+		db:         db,
+	}
+}
+
+// List runs the list action.
+func (c *WorkItemTransitionsController) List(ctx *app.ListWorkItemTransitionsContext) error {
+	var wi *workitem.WorkItem
+	var wit *workitem.WorkItemType
+	err := application.Transactional(c.db, func(appl application.Application) error {
+		var err error
+		wi, err = appl.WorkItems().LoadByID(ctx, ctx.WiID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load work item %s", ctx.WiID)
+		}
+		wit, err = appl.WorkItemTypes().Load(ctx, wi.Type)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load work item type %s", wi.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	state, _ := wi.Fields[workitem.SystemState].(string)
+	res := &app.WorkItemTransitionList{
+		Data: []*app.WorkItemTransition{},
+	}
+	for _, t := range wit.TransitionsFrom(state) {
+		res.Data = append(res.Data, ConvertWorkItemTransition(state, t, wi.Fields))
+	}
+	return ctx.OK(res)
+}
+
+// ConvertWorkItemTransition converts from internal to external REST
+// representation
+func ConvertWorkItemTransition(state string, t workitem.Transition, fields workitem.Fields) *app.WorkItemTransition {
+	requiredFields := t.RequiredFields
+	if requiredFields == nil {
+		requiredFields = []string{}
+	}
+	missingFields := t.MissingFields(fields)
+	return &app.WorkItemTransition{
+		Type: APIStringTypeWorkItemTransition,
+		Attributes: &app.WorkItemTransitionAttributes{
+			From:           state,
+			To:             t.To,
+			RequiredFields: requiredFields,
+			MissingFields:  missingFields,
+			Available:      len(missingFields) == 0,
+		},
+	}
+}
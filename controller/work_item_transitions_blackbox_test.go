@@ -0,0 +1,75 @@
+package controller_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type workItemTransitionsSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkItemTransitionsREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &workItemTransitionsSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *workItemTransitionsSuite) TestList() {
+	// given a type whose work items can only be resolved when they are open
+	// and assigned
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Transitions = workitem.Transitions{
+				{From: workitem.SystemStateNew, To: workitem.SystemStateOpen},
+				{From: workitem.SystemStateOpen, To: workitem.SystemStateResolved, RequiredFields: []string{workitem.SystemAssignees}},
+				{From: workitem.TransitionFromAnyState, To: workitem.SystemStateClosed},
+			}
+			return nil
+		}),
+		tf.WorkItems(1),
+	)
+	svc := goa.New("WorkItemTransitions-Service")
+	ctrl := NewWorkItemTransitionsController(svc, s.GormDB)
+
+	s.T().Run("ok", func(t *testing.T) {
+		_, res := test.ListWorkItemTransitionsOK(t, svc.Context, svc, ctrl, fxt.WorkItems[0].ID)
+		require.Len(t, res.Data, 2)
+		assert.Equal(t, workitem.SystemStateNew, res.Data[0].Attributes.From)
+		assert.Equal(t, workitem.SystemStateOpen, res.Data[0].Attributes.To)
+		assert.True(t, res.Data[0].Attributes.Available)
+		assert.Equal(t, workitem.SystemStateClosed, res.Data[1].Attributes.To)
+	})
+
+	s.T().Run("not found", func(t *testing.T) {
+		test.ListWorkItemTransitionsNotFound(t, svc.Context, svc, ctrl, uuid.NewV4())
+	})
+
+	s.T().Run("update rejects a disallowed state", func(t *testing.T) {
+		svc := testsupport.ServiceAsUser("TestUpdateWI-Service", *fxt.Identities[0])
+		workitemCtrl := NewWorkitemController(svc, s.GormDB, s.Configuration)
+		u := app.UpdateWorkitemPayload{
+			Data: &app.WorkItem{
+				ID:   &fxt.WorkItems[0].ID,
+				Type: APIStringTypeWorkItem,
+				Attributes: map[string]interface{}{
+					"version":            fxt.WorkItems[0].Version,
+					workitem.SystemState: workitem.SystemStateResolved,
+				},
+			},
+		}
+		test.UpdateWorkitemBadRequest(t, svc.Context, svc, workitemCtrl, fxt.WorkItems[0].ID, &u)
+	})
+}
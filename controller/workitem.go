@@ -21,6 +21,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/space/authz"
@@ -71,18 +72,65 @@ func NewNotifyingWorkitemController(service *goa.Service, db application.DB, not
 		config:       config}
 }
 
-// Returns true if the user is the work item creator or space collaborator
+// Returns true if the user is the work item creator or if one of the roles of
+// the user in the space allows to edit work items
 func authorizeWorkitemEditor(ctx context.Context, db application.DB, spaceID uuid.UUID, creatorID string, editorID string) (bool, error) {
 	if editorID == creatorID {
 		return true, nil
 	}
-	authorized, err := authz.Authorize(ctx, spaceID.String())
+	authorized, err := authz.AuthorizePermission(ctx, spaceID.String(), authz.PermissionEditWorkItem)
 	if err != nil {
 		return false, errors.NewUnauthorizedError(err.Error())
 	}
 	return authorized, nil
 }
 
+// boardColumnsChanged returns true if the given work item sets other board
+// columns than the current ones, which are nil for a new work item
+func boardColumnsChanged(source app.WorkItem, current interface{}) bool {
+	if source.Relationships == nil || source.Relationships.SystemBoardcolumns == nil {
+		return false
+	}
+	// the board columns are stored like the assignees as a list of IDs
+	existing := map[string]struct{}{}
+	for _, id := range assigneeIDs(current) {
+		existing[id] = struct{}{}
+	}
+	requested := map[string]struct{}{}
+	for _, d := range source.Relationships.SystemBoardcolumns.Data {
+		if d == nil || d.ID == nil {
+			return true
+		}
+		requested[*d.ID] = struct{}{}
+	}
+	if len(requested) != len(existing) {
+		return true
+	}
+	for id := range requested {
+		if _, ok := existing[id]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// authorizeBoardColumns returns an error unless the current user may move work
+// items on the boards of the space, provided that the given work item changes
+// its current board columns.
+func authorizeBoardColumns(ctx context.Context, spaceID uuid.UUID, source app.WorkItem, current interface{}) error {
+	if !boardColumnsChanged(source, current) {
+		return nil
+	}
+	authorized, err := authz.AuthorizePermission(ctx, spaceID.String(), authz.PermissionManageBoards)
+	if err != nil {
+		return errors.NewUnauthorizedError(err.Error())
+	}
+	if !authorized {
+		return errors.NewForbiddenError("user is not allowed to move work items on the boards of the space")
+	}
+	return nil
+}
+
 // Update does PATCH workitem
 func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	if ctx.Payload == nil || ctx.Payload.Data == nil || ctx.Payload.Data.ID == nil {
@@ -111,12 +159,20 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	if !authorized {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewForbiddenError("user is not authorized to access the space"))
 	}
+	if err := authorizeBoardColumns(ctx, wi.SpaceID, *ctx.Payload.Data, wi.Fields[workitem.SystemBoardcolumns]); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	var mentions []reference.Reference
+	var oldState interface{}
+	var oldAssignees []string
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		// The Number and Type of a work item are not allowed to be changed
 		// which is why we overwrite those values with their old value after the
 		// work item was converted.
 		oldNumber := wi.Number
 		oldType := wi.Type
+		oldState = wi.Fields[workitem.SystemState]
+		oldAssignees = assigneeIDs(wi.Fields[workitem.SystemAssignees])
 		err = ConvertJSONAPIToWorkItem(ctx, ctx.Method, appl, *ctx.Payload.Data, wi, wi.Type, wi.SpaceID)
 		if err != nil {
 			return err
@@ -127,7 +183,8 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 		if err != nil {
 			return errs.Wrap(err, "Error updating work item")
 		}
-		return nil
+		mentions, err = updateDescriptionReferences(ctx, appl, *wi)
+		return err
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
@@ -136,8 +193,13 @@ func (c *WorkitemController) Update(ctx *app.UpdateWorkitemContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errs.Wrapf(err, "failed to load work item type: %s", wi.Type))
 	}
-	c.notification.Send(ctx, notification.NewWorkItemUpdated(ctx.Payload.Data.ID.String()))
-	converted, err := ConvertWorkItem(ctx.Request, *wit, *wi, workItemIncludeHasChildren(ctx, c.db))
+	msg := notification.NewWorkItemUpdated(ctx.Payload.Data.ID.String())
+	msg.Custom = workItemChanges(oldState, oldAssignees, *wi)
+	c.notification.Send(ctx, msg)
+	notifyMentions(ctx, c.notification, mentions, *currentUserIdentityID, func(mentionedID uuid.UUID) notification.Message {
+		return notification.NewWorkItemMention(wi.ID.String(), mentionedID)
+	})
+	converted, err := ConvertWorkItem(ctx.Request, *wit, *wi, workItemIncludeHasChildren(ctx, c.db), workItemIncludeReferences(ctx, c.db, wi.ID))
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
@@ -173,7 +235,9 @@ func (c *WorkitemController) Show(ctx *app.ShowWorkitemContext) error {
 	return ctx.ConditionalRequest(*wi, c.config.GetCacheControlWorkItem, func() error {
 		comments := workItemIncludeCommentsAndTotal(ctx, c.db, ctx.WiID)
 		hasChildren := workItemIncludeHasChildren(ctx, c.db)
-		wi2, err := ConvertWorkItem(ctx.Request, *wit, *wi, comments, hasChildren)
+		attachments := workItemIncludeAttachmentList(ctx, c.db, ctx.WiID)
+		references := workItemIncludeReferences(ctx, c.db, ctx.WiID)
+		wi2, err := ConvertWorkItem(ctx.Request, *wit, *wi, comments, hasChildren, attachments, references)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, err)
 		}
@@ -205,7 +269,7 @@ func (c *WorkitemController) Delete(ctx *app.DeleteWorkitemContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	authorized, err := authz.Authorize(ctx, wi.SpaceID.String())
+	authorized, err := authz.AuthorizePermission(ctx, wi.SpaceID.String(), authz.PermissionDeleteWorkItem)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
@@ -255,6 +319,51 @@ func findLastModified(wis []workitem.WorkItem) time.Time {
 	return t
 }
 
+// assigneeIDs returns the IDs of the assignees stored in the system.assignees
+// field of a work item
+func assigneeIDs(field interface{}) []string {
+	switch assignees := field.(type) {
+	case []string:
+		return assignees
+	case []interface{}:
+		ids := make([]string, 0, len(assignees))
+		for _, a := range assignees {
+			if id, ok := a.(string); ok {
+				ids = append(ids, id)
+			}
+		}
+		return ids
+	}
+	return nil
+}
+
+// workItemChanges returns the details of a notification about the given work
+// item which tell whether its state changed and who was newly assigned. A
+// created work item has neither an old state nor old assignees.
+func workItemChanges(oldState interface{}, oldAssignees []string, wi workitem.WorkItem) map[string]interface{} {
+	custom := map[string]interface{}{}
+	if oldState != nil && oldState != wi.Fields[workitem.SystemState] {
+		custom[notification.CustomStateChanged] = true
+	}
+	wasAssigned := map[string]bool{}
+	for _, id := range oldAssignees {
+		wasAssigned[id] = true
+	}
+	var assigned []string
+	for _, id := range assigneeIDs(wi.Fields[workitem.SystemAssignees]) {
+		if !wasAssigned[id] {
+			assigned = append(assigned, id)
+		}
+	}
+	if len(assigned) > 0 {
+		custom[notification.CustomAssignedIDs] = assigned
+	}
+	if len(custom) == 0 {
+		return nil
+	}
+	return custom
+}
+
 // ConvertJSONAPIToWorkItem is responsible for converting given WorkItem model object into a
 // response resource object by jsonapi.org specifications
 func ConvertJSONAPIToWorkItem(ctx context.Context, method string, appl application.Application, source app.WorkItem, target *workitem.WorkItem, witID uuid.UUID, spaceID uuid.UUID) error {
@@ -561,6 +670,9 @@ func ConvertWorkItem(request *http.Request, wit workitem.WorkItemType, wi workit
 			Related: &relatedURL,
 		},
 	}
+	if len(wi.Warnings) > 0 {
+		op.Meta = map[string]interface{}{"warnings": wi.Warnings}
+	}
 
 	// Move fields into Relationships or Attributes as needed
 	// TODO(kwk): Loop based on WorkItemType and match against Field.Type instead of directly to field value
@@ -653,6 +765,8 @@ func ConvertWorkItem(request *http.Request, wit workitem.WorkItemType, wi workit
 	workItemIncludeComments(request, &wi, op)
 	workItemIncludeChildren(request, &wi, op)
 	workItemIncludeEvents(request, &wi, op)
+	workItemIncludeAttachments(request, &wi, op)
+	workItemIncludeBacklinks(request, &wi, op)
 	for _, add := range additional {
 		if err := add(request, &wi, op); err != nil {
 			return nil, errs.Wrap(err, "failed to run additional conversion function")
@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	"github.com/fabric8-services/fabric8-wit/app"
@@ -13,6 +15,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/login"
 	"github.com/fabric8-services/fabric8-wit/notification"
 	query "github.com/fabric8-services/fabric8-wit/query/simple"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	"github.com/fabric8-services/fabric8-wit/search"
 	"github.com/fabric8-services/fabric8-wit/space"
@@ -81,7 +84,7 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	spaceOwnerID := space.OwnerID.String()
 	// check both the "openshiftio" user and the "test" user from the test realm.
 	if "7b50ddb4-5e12-4031-bca7-3b88f92e2339" != spaceOwnerID && "ae68a343-c866-430c-b6ce-a36f0b38d8e5" != spaceOwnerID {
-		authorized, err := authz.Authorize(ctx, ctx.SpaceID.String())
+		authorized, err := authz.AuthorizePermission(ctx, ctx.SpaceID.String(), authz.PermissionEditWorkItem)
 		if err != nil {
 			return jsonapi.JSONErrorResponse(ctx, errors.NewInternalError(ctx, err))
 		}
@@ -99,6 +102,9 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	if wit == nil { // TODO Figure out path source etc. Should be a required relation
 		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("Data.Relationships.BaseType.Data.ID", err))
 	}
+	if err := authorizeBoardColumns(ctx, ctx.SpaceID, *ctx.Payload.Data, nil); err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
 
 	// Set the space to the Payload
 	if ctx.Payload.Data != nil && ctx.Payload.Data.Relationships != nil {
@@ -109,6 +115,7 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	wi := &workitem.WorkItem{
 		Fields: make(map[string]interface{}),
 	}
+	var mentions []reference.Reference
 	err = application.Transactional(c.db, func(appl application.Application) error {
 		//verify spaceID:
 		// To be removed once we have endpoint like - /api/space/{spaceID}/workitems
@@ -127,7 +134,8 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 		if err != nil {
 			return errs.Wrap(err, fmt.Sprintf("Error creating work item"))
 		}
-		return nil
+		mentions, err = updateDescriptionReferences(ctx, appl, *wi)
+		return err
 	})
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
@@ -137,7 +145,8 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
-	wi2, err := ConvertWorkItem(ctx.Request, *workItemType, *wi, hasChildren)
+	references := workItemIncludeReferences(ctx, c.db, wi.ID)
+	wi2, err := ConvertWorkItem(ctx.Request, *workItemType, *wi, hasChildren, references)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, err)
 	}
@@ -149,7 +158,12 @@ func (c *WorkitemsController) Create(ctx *app.CreateWorkitemsContext) error {
 	}
 	ctx.ResponseData.Header().Set("Last-Modified", lastModified(*wi))
 	ctx.ResponseData.Header().Set("Location", app.WorkitemHref(wi2.ID))
-	c.notification.Send(ctx, notification.NewWorkItemCreated(wi.ID.String()))
+	msg := notification.NewWorkItemCreated(wi.ID.String())
+	msg.Custom = workItemChanges(nil, nil, *wi)
+	c.notification.Send(ctx, msg)
+	notifyMentions(ctx, c.notification, mentions, *currentUserIdentityID, func(mentionedID uuid.UUID) notification.Message {
+		return notification.NewWorkItemMention(wi.ID.String(), mentionedID)
+	})
 	return ctx.Created(resp)
 }
 
@@ -225,13 +239,15 @@ func (c *WorkitemsController) List(ctx *app.ListWorkitemsContext) error {
 	offset, limit := computePagingLimits(ctx.PageOffset, ctx.PageLimit)
 	var workitems []workitem.WorkItem
 	var count int
-	sort, err := workitem.ParseSortWorkItemsBy(ctx.Sort)
-	if err != nil {
-		return jsonapi.JSONErrorResponse(ctx, err)
-	}
-
 	err = application.Transactional(c.db, func(tx application.Application) error {
-		var err error
+		s, err := tx.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		sort, err := workitem.ParseSortWorkItemsBy(ctx, ctx.Sort, workitem.SpaceTemplateFields{Types: tx.WorkItemTypes(), SpaceTemplateID: s.SpaceTemplateID})
+		if err != nil {
+			return err
+		}
 		workitems, count, err = tx.WorkItems().List(ctx.Context, ctx.SpaceID, exp, ctx.FilterParentexists, &offset, &limit, sort)
 		if err != nil {
 			return errs.Wrap(err, "Error listing work items")
@@ -268,7 +284,7 @@ func (c *WorkitemsController) Reorder(ctx *app.ReorderWorkitemsContext) error {
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
-	authorized, err := authz.Authorize(ctx, ctx.SpaceID.String())
+	authorized, err := authz.AuthorizePermission(ctx, ctx.SpaceID.String(), authz.PermissionEditWorkItem)
 	if err != nil {
 		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
 	}
@@ -296,6 +312,9 @@ func (c *WorkitemsController) Reorder(ctx *app.ReorderWorkitemsContext) error {
 				}, "unable to load workitem")
 				return errors.NewNotFoundError("work item", strconv.Itoa(wi.Number))
 			}
+			if err := authorizeBoardColumns(ctx, ctx.SpaceID, *ctx.Payload.Data[i], wi.Fields[workitem.SystemBoardcolumns]); err != nil {
+				return err
+			}
 
 			err = ConvertJSONAPIToWorkItem(ctx, ctx.Method, appl, *ctx.Payload.Data[i], wi, wi.Type, ctx.SpaceID)
 			if err != nil {
@@ -327,3 +346,183 @@ func (c *WorkitemsController) Reorder(ctx *app.ReorderWorkitemsContext) error {
 	}
 	return ctx.OK(resp)
 }
+
+// maxBulkUpdateItems limits the number of work items changed by a single bulk
+// update
+const maxBulkUpdateItems = 500
+
+// Possible outcomes of the update of a single work item in a bulk update
+const (
+	bulkUpdateStatusOK           = "ok"
+	bulkUpdateStatusConflict     = "conflict"
+	bulkUpdateStatusNotFound     = "not-found"
+	bulkUpdateStatusForbidden    = "forbidden"
+	bulkUpdateStatusBadParameter = "bad-parameter"
+)
+
+// BulkUpdate applies the same changes to a list of work items, or to all work
+// items matching a filter, in a single transaction. Listed work items are
+// checked against the version given for them, whereas work items matched by the
+// filter are updated in their current version. Every work item gets its own
+// status in the response. Failures which are specific to one work item (e.g. a
+// version conflict) do not prevent the others from being updated, whereas any
+// other error rolls back the whole batch. A single notification is sent for the
+// whole batch once it is committed.
+func (c *WorkitemsController) BulkUpdate(ctx *app.BulkUpdateWorkitemsContext) error {
+	currentUserIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	if ctx.Payload == nil || ctx.Payload.Data == nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("missing data element in request", nil))
+	}
+	if (len(ctx.Payload.Items) == 0) == (ctx.Payload.Filter == nil) {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("items", ctx.Payload.Items).Expected("either a list of items or a filter"))
+	}
+	if len(ctx.Payload.Items) > maxBulkUpdateItems {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewBadParameterError("items", len(ctx.Payload.Items)).Expected(fmt.Sprintf("at most %d items", maxBulkUpdateItems)))
+	}
+	spaceAuthorized, err := authz.AuthorizePermission(ctx, ctx.SpaceID.String(), authz.PermissionEditWorkItem)
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+	}
+	// the same board columns are set on every work item, so the permission to
+	// move work items on the boards is looked up once
+	boardsAuthorized := true
+	if ctx.Payload.Data.Relationships != nil && ctx.Payload.Data.Relationships.SystemBoardcolumns != nil {
+		boardsAuthorized, err = authz.AuthorizePermission(ctx, ctx.SpaceID.String(), authz.PermissionManageBoards)
+		if err != nil {
+			return jsonapi.JSONErrorResponse(ctx, errors.NewUnauthorizedError(err.Error()))
+		}
+	}
+	results := []*app.WorkItemBulkUpdateResult{}
+	var updatedIDs, assignedIDs []string
+	stateChanged := false
+	err = application.Transactional(c.db, func(appl application.Application) error {
+		s, err := appl.Spaces().Load(ctx, ctx.SpaceID)
+		if err != nil {
+			return err
+		}
+		items := ctx.Payload.Items
+		if ctx.Payload.Filter != nil {
+			exp, _, err := search.ParseFilterString(ctx, *ctx.Payload.Filter, workitem.SpaceTemplateFields{Types: appl.WorkItemTypes(), SpaceTemplateID: s.SpaceTemplateID})
+			if err != nil {
+				return err
+			}
+			limit := maxBulkUpdateItems + 1
+			matches, _, err := appl.WorkItems().List(ctx, ctx.SpaceID, exp, nil, nil, &limit, workitem.SortWorkItemsByDefault)
+			if err != nil {
+				return errs.Wrap(err, "failed to list the work items to update")
+			}
+			if len(matches) > maxBulkUpdateItems {
+				return errors.NewBadParameterError("filter", *ctx.Payload.Filter).Expected(fmt.Sprintf("a filter matching at most %d work items", maxBulkUpdateItems))
+			}
+			for _, wi := range matches {
+				items = append(items, &app.WorkItemBulkUpdateItem{ID: wi.ID, Version: wi.Version})
+			}
+		}
+		for _, item := range items {
+			res, changes, err := bulkUpdateWorkItem(ctx, appl, ctx.SpaceID, *ctx.Payload.Data, *item, spaceAuthorized, boardsAuthorized, *currentUserIdentityID)
+			if err != nil {
+				return err
+			}
+			if res.Status == bulkUpdateStatusOK {
+				updatedIDs = append(updatedIDs, item.ID.String())
+				if changed, _ := changes[notification.CustomStateChanged].(bool); changed {
+					stateChanged = true
+				}
+				assigned, _ := changes[notification.CustomAssignedIDs].([]string)
+				assignedIDs = append(assignedIDs, assigned...)
+			}
+			results = append(results, res)
+		}
+		return nil
+	})
+	if err != nil {
+		return jsonapi.JSONErrorResponse(ctx, err)
+	}
+	if len(updatedIDs) > 0 {
+		msg := notification.NewWorkItemsBulkUpdated(ctx.SpaceID.String(), updatedIDs)
+		if stateChanged {
+			msg.Custom[notification.CustomStateChanged] = true
+		}
+		if len(assignedIDs) > 0 {
+			msg.Custom[notification.CustomAssignedIDs] = assignedIDs
+		}
+		c.notification.Send(ctx, msg)
+	}
+	return ctx.OK(&app.WorkItemBulkUpdateResults{
+		Data: results,
+		Meta: &app.WorkItemBulkUpdateMeta{
+			Updated: len(updatedIDs),
+			Failed:  len(results) - len(updatedIDs),
+		},
+	})
+}
+
+// bulkUpdateWorkItem applies the changes of a bulk update to a single work
+// item and returns the notification details of the update (see
+// workItemChanges). Errors that only concern this work item are reported in the
+// returned result, all other errors are returned.
+func bulkUpdateWorkItem(ctx context.Context, appl application.Application, spaceID uuid.UUID, changes app.WorkItem, item app.WorkItemBulkUpdateItem, spaceAuthorized, boardsAuthorized bool, modifierID uuid.UUID) (*app.WorkItemBulkUpdateResult, map[string]interface{}, error) {
+	res := &app.WorkItemBulkUpdateResult{ID: item.ID}
+	failed := func(err error) (*app.WorkItemBulkUpdateResult, map[string]interface{}, error) {
+		if ok, _ := errors.IsVersionConflictError(err); ok {
+			res.Status = bulkUpdateStatusConflict
+		} else if ok, _ := errors.IsNotFoundError(err); ok {
+			res.Status = bulkUpdateStatusNotFound
+		} else if ok, _ := errors.IsForbiddenError(err); ok {
+			res.Status = bulkUpdateStatusForbidden
+		} else if ok, _ := errors.IsBadParameterError(err); ok {
+			res.Status = bulkUpdateStatusBadParameter
+		} else if ok, _ := errors.IsConversionError(err); ok {
+			res.Status = bulkUpdateStatusBadParameter
+		} else {
+			return nil, nil, err
+		}
+		msg := err.Error()
+		res.Error = &msg
+		return res, nil, nil
+	}
+	wi, err := appl.WorkItems().LoadByID(ctx, item.ID)
+	if err != nil {
+		return failed(err)
+	}
+	if !uuid.Equal(wi.SpaceID, spaceID) {
+		return failed(errors.NewNotFoundError("work item", item.ID.String()))
+	}
+	if !spaceAuthorized && wi.Fields[workitem.SystemCreator] != modifierID.String() {
+		return failed(errors.NewForbiddenError("user is not authorized to access the space"))
+	}
+	if !boardsAuthorized && boardColumnsChanged(changes, wi.Fields[workitem.SystemBoardcolumns]) {
+		return failed(errors.NewForbiddenError("user is not allowed to move work items on the boards of the space"))
+	}
+	// every work item is checked against its own version
+	attributes := make(map[string]interface{}, len(changes.Attributes)+1)
+	for k, v := range changes.Attributes {
+		attributes[k] = v
+	}
+	attributes[workitem.SystemVersion] = item.Version
+	changes.Attributes = attributes
+	// The Number and Type of a work item are not allowed to be changed
+	oldNumber := wi.Number
+	oldType := wi.Type
+	oldState := wi.Fields[workitem.SystemState]
+	oldAssignees := assigneeIDs(wi.Fields[workitem.SystemAssignees])
+	if err := ConvertJSONAPIToWorkItem(ctx, http.MethodPatch, appl, changes, wi, wi.Type, spaceID); err != nil {
+		return failed(err)
+	}
+	wi.Number = oldNumber
+	wi.Type = oldType
+	wi, err = appl.WorkItems().Save(ctx, spaceID, *wi, modifierID)
+	if err != nil {
+		return failed(err)
+	}
+	if _, err := updateDescriptionReferences(ctx, appl, *wi); err != nil {
+		return nil, nil, err
+	}
+	res.Status = bulkUpdateStatusOK
+	res.Version = &wi.Version
+	res.Warnings = wi.Warnings
+	return res, workItemChanges(oldState, oldAssignees, *wi), nil
+}
@@ -0,0 +1,139 @@
+package controller_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/app"
+	"github.com/fabric8-services/fabric8-wit/app/test"
+	. "github.com/fabric8-services/fabric8-wit/controller"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type TestWorkItemBulkUpdateREST struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRunWorkItemBulkUpdateREST(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &TestWorkItemBulkUpdateREST{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// bulkUpdateChannel records the notifications sent by bulk updates
+type bulkUpdateChannel struct {
+	messages []notification.Message
+}
+
+func (c *bulkUpdateChannel) Send(ctx context.Context, msg notification.Message) {
+	c.messages = append(c.messages, msg)
+}
+
+func newBulkUpdatePayload(state string) *app.BulkUpdateWorkitemsPayload {
+	return &app.BulkUpdateWorkitemsPayload{
+		Data: &app.WorkItem{
+			Type: APIStringTypeWorkItem,
+			Attributes: map[string]interface{}{
+				workitem.SystemState: state,
+			},
+		},
+	}
+}
+
+func (s *TestWorkItemBulkUpdateREST) TestBulkUpdate() {
+	s.T().Run("items", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment(), tf.WorkItems(3))
+		svc := testsupport.ServiceAsSpaceUser("Bulk-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		channel := &bulkUpdateChannel{}
+		ctrl := NewNotifyingWorkitemsController(svc, s.GormDB, channel, s.Configuration)
+		payload := newBulkUpdatePayload(workitem.SystemStateResolved)
+		payload.Items = []*app.WorkItemBulkUpdateItem{
+			{ID: fxt.WorkItems[0].ID, Version: fxt.WorkItems[0].Version},
+			{ID: fxt.WorkItems[1].ID, Version: fxt.WorkItems[1].Version + 1},
+			{ID: uuid.NewV4(), Version: 0},
+		}
+		_, res := test.BulkUpdateWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+		require.Len(t, res.Data, 3)
+		assert.Equal(t, 1, res.Meta.Updated)
+		assert.Equal(t, 2, res.Meta.Failed)
+		assert.Equal(t, "ok", res.Data[0].Status)
+		require.NotNil(t, res.Data[0].Version)
+		assert.Equal(t, fxt.WorkItems[0].Version+1, *res.Data[0].Version)
+		assert.Equal(t, "conflict", res.Data[1].Status)
+		assert.NotNil(t, res.Data[1].Error)
+		assert.Equal(t, "not-found", res.Data[2].Status)
+		// only the updated work item is part of the single notification
+		require.Len(t, channel.messages, 1)
+		assert.Equal(t, []string{fxt.WorkItems[0].ID.String()}, channel.messages[0].Custom[notification.CustomWorkItemIDs])
+
+		wi, err := workitem.NewWorkItemRepository(s.DB).LoadByID(s.Ctx, fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.SystemStateResolved, wi.Fields[workitem.SystemState])
+		wi, err = workitem.NewWorkItemRepository(s.DB).LoadByID(s.Ctx, fxt.WorkItems[1].ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, workitem.SystemStateResolved, wi.Fields[workitem.SystemState])
+	})
+
+	s.T().Run("filter", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment(), tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			if idx < 2 {
+				fxt.WorkItems[idx].Fields[workitem.SystemState] = workitem.SystemStateOpen
+			}
+			return nil
+		}))
+		svc := testsupport.ServiceAsSpaceUser("Bulk-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		channel := &bulkUpdateChannel{}
+		ctrl := NewNotifyingWorkitemsController(svc, s.GormDB, channel, s.Configuration)
+		payload := newBulkUpdatePayload(workitem.SystemStateClosed)
+		filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}, {"state": "%s"}]}`, fxt.Spaces[0].ID, workitem.SystemStateOpen)
+		payload.Filter = &filter
+		_, res := test.BulkUpdateWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+		require.Len(t, res.Data, 2)
+		assert.Equal(t, 2, res.Meta.Updated)
+		for _, r := range res.Data {
+			assert.Equal(t, "ok", r.Status)
+		}
+		// a single notification is sent for the whole batch
+		require.Len(t, channel.messages, 1)
+		msg := channel.messages[0]
+		assert.Equal(t, "workitem.bulkupdate", msg.MessageType)
+		assert.Equal(t, fxt.Spaces[0].ID.String(), msg.TargetID)
+		assert.ElementsMatch(t, []string{res.Data[0].ID.String(), res.Data[1].ID.String()}, msg.Custom[notification.CustomWorkItemIDs])
+		assert.Equal(t, true, msg.Custom[notification.CustomStateChanged])
+	})
+
+	s.T().Run("forbidden items", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment(), tf.Identities(2), tf.WorkItems(1))
+		svc := testsupport.ServiceAsSpaceUser("Bulk-Service", *fxt.Identities[1], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkitemsController(svc, s.GormDB, s.Configuration)
+		payload := newBulkUpdatePayload(workitem.SystemStateResolved)
+		payload.Items = []*app.WorkItemBulkUpdateItem{{ID: fxt.WorkItems[0].ID, Version: fxt.WorkItems[0].Version}}
+		_, res := test.BulkUpdateWorkitemsOK(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, payload)
+		require.Len(t, res.Data, 1)
+		assert.Equal(t, "forbidden", res.Data[0].Status)
+	})
+
+	s.T().Run("neither items nor filter", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment())
+		svc := testsupport.ServiceAsSpaceUser("Bulk-Service", *fxt.Identities[0], &TestSpaceAuthzService{*fxt.Identities[0], ""})
+		ctrl := NewWorkitemsController(svc, s.GormDB, s.Configuration)
+		test.BulkUpdateWorkitemsBadRequest(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newBulkUpdatePayload(workitem.SystemStateResolved))
+	})
+
+	s.T().Run("unauthorized", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.CreateWorkItemEnvironment())
+		svc := goa.New("Bulk-Service")
+		ctrl := NewWorkitemsController(svc, s.GormDB, s.Configuration)
+		test.BulkUpdateWorkitemsUnauthorized(t, svc.Context, svc, ctrl, fxt.Spaces[0].ID, newBulkUpdatePayload(workitem.SystemStateResolved))
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/ptr"
 	"github.com/fabric8-services/fabric8-wit/rest"
@@ -111,6 +112,13 @@ func ConvertFieldTypeFromModel(t workitem.FieldType) app.FieldType {
 	case workitem.EnumType:
 		result.BaseType = ptr.String(string(t2.BaseType.GetKind()))
 		result.Values = t2.Values
+	case workitem.ComputedType:
+		result.ResultType = ptr.String(string(t2.ResultType.GetKind()))
+		result.Formula = &app.FieldFormula{
+			Function: string(t2.Formula.Function),
+			Fields:   t2.Formula.Fields,
+			Children: ptr.Bool(t2.Formula.Children),
+		}
 	}
 
 	return result
@@ -123,6 +131,9 @@ func ConvertFieldTypeToModel(t app.FieldType) (workitem.FieldType, error) {
 	}
 	switch *kind {
 	case workitem.KindList:
+		if t.ComponentType == nil {
+			return nil, errors.NewBadParameterError("componentType", nil).Expected("simple type")
+		}
 		componentType, err := workitem.ConvertAnyToKind(*t.ComponentType)
 		if err != nil {
 			return nil, errs.WithStack(err)
@@ -132,6 +143,9 @@ func ConvertFieldTypeToModel(t app.FieldType) (workitem.FieldType, error) {
 		}
 		return workitem.ListType{workitem.SimpleType{*kind}, workitem.SimpleType{*componentType}}, nil
 	case workitem.KindEnum:
+		if t.BaseType == nil {
+			return nil, errors.NewBadParameterError("baseType", nil).Expected("simple type")
+		}
 		bt, err := workitem.ConvertAnyToKind(*t.BaseType)
 		if err != nil {
 			return nil, errs.WithStack(err)
@@ -155,6 +169,29 @@ func ConvertFieldTypeToModel(t app.FieldType) (workitem.FieldType, error) {
 			BaseType: baseType,
 			Values:   converted,
 		}, nil
+	case workitem.KindComputed:
+		if t.ResultType == nil {
+			return nil, errors.NewBadParameterError("resultType", nil).Expected("integer or float")
+		}
+		if t.Formula == nil {
+			return nil, errors.NewBadParameterError("formula", nil).Expected("formula")
+		}
+		resultType, err := workitem.ConvertAnyToKind(*t.ResultType)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		res := workitem.ComputedType{
+			SimpleType: workitem.SimpleType{Kind: *kind},
+			ResultType: workitem.SimpleType{Kind: *resultType},
+			Formula: workitem.Formula{
+				Function: workitem.FormulaFunction(t.Formula.Function),
+				Fields:   t.Formula.Fields,
+			},
+		}
+		if t.Formula.Children != nil {
+			res.Formula.Children = *t.Formula.Children
+		}
+		return res, nil
 	default:
 		return workitem.SimpleType{*kind}, nil
 	}
@@ -164,17 +201,29 @@ func ConvertFieldDefinitionsToModel(fields map[string]app.FieldDefinition) (map[
 	modelFields := map[string]workitem.FieldDefinition{}
 	// now process new fields, checking whether they are ok to add.
 	for field, definition := range fields {
-		ct, err := ConvertFieldTypeToModel(*definition.Type)
+		converted, err := ConvertFieldDefinitionToModel(definition)
 		if err != nil {
 			return nil, errs.WithStack(err)
 		}
-		converted := workitem.FieldDefinition{
-			Label:       definition.Label,
-			Description: definition.Description,
-			Required:    definition.Required,
-			Type:        ct,
-		}
-		modelFields[field] = converted
+		modelFields[field] = *converted
 	}
 	return modelFields, nil
 }
+
+// ConvertFieldDefinitionToModel converts a single field definition from the
+// app representation to the model
+func ConvertFieldDefinitionToModel(definition app.FieldDefinition) (*workitem.FieldDefinition, error) {
+	if definition.Type == nil {
+		return nil, errors.NewBadParameterError("type", nil).Expected("field type")
+	}
+	ct, err := ConvertFieldTypeToModel(*definition.Type)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return &workitem.FieldDefinition{
+		Label:       definition.Label,
+		Description: definition.Description,
+		Required:    definition.Required,
+		Type:        ct,
+	}, nil
+}
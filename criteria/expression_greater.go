@@ -0,0 +1,20 @@
+package criteria
+
+// GreaterExpression represents the "greater than" operator
+type GreaterExpression struct {
+	binaryExpression
+}
+
+// Ensure GreaterExpression implements the Expression interface
+var _ Expression = &GreaterExpression{}
+var _ Expression = (*GreaterExpression)(nil)
+
+// Accept implements ExpressionVisitor
+func (t *GreaterExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Greater(t)
+}
+
+// Greater constructs a GreaterExpression
+func Greater(left Expression, right Expression) Expression {
+	return reparent(&GreaterExpression{binaryExpression{expression{}, left, right}})
+}
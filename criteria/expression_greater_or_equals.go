@@ -0,0 +1,20 @@
+package criteria
+
+// GreaterOrEqualsExpression represents the "greater than or equal to" operator
+type GreaterOrEqualsExpression struct {
+	binaryExpression
+}
+
+// Ensure GreaterOrEqualsExpression implements the Expression interface
+var _ Expression = &GreaterOrEqualsExpression{}
+var _ Expression = (*GreaterOrEqualsExpression)(nil)
+
+// Accept implements ExpressionVisitor
+func (t *GreaterOrEqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.GreaterOrEquals(t)
+}
+
+// GreaterOrEquals constructs a GreaterOrEqualsExpression
+func GreaterOrEquals(left Expression, right Expression) Expression {
+	return reparent(&GreaterOrEqualsExpression{binaryExpression{expression{}, left, right}})
+}
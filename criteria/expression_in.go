@@ -0,0 +1,21 @@
+package criteria
+
+// InExpression represents the set membership operator
+type InExpression struct {
+	binaryExpression
+}
+
+// Ensure InExpression implements the Expression interface
+var _ Expression = &InExpression{}
+var _ Expression = (*InExpression)(nil)
+
+// Accept implements ExpressionVisitor
+func (t *InExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.In(t)
+}
+
+// In constructs an InExpression. The right hand side is expected to be a
+// literal holding a slice of values.
+func In(left Expression, right Expression) Expression {
+	return reparent(&InExpression{binaryExpression{expression{}, left, right}})
+}
@@ -0,0 +1,20 @@
+package criteria
+
+// LessExpression represents the "less than" operator
+type LessExpression struct {
+	binaryExpression
+}
+
+// Ensure LessExpression implements the Expression interface
+var _ Expression = &LessExpression{}
+var _ Expression = (*LessExpression)(nil)
+
+// Accept implements ExpressionVisitor
+func (t *LessExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.Less(t)
+}
+
+// Less constructs a LessExpression
+func Less(left Expression, right Expression) Expression {
+	return reparent(&LessExpression{binaryExpression{expression{}, left, right}})
+}
@@ -0,0 +1,20 @@
+package criteria
+
+// LessOrEqualsExpression represents the "less than or equal to" operator
+type LessOrEqualsExpression struct {
+	binaryExpression
+}
+
+// Ensure LessOrEqualsExpression implements the Expression interface
+var _ Expression = &LessOrEqualsExpression{}
+var _ Expression = (*LessOrEqualsExpression)(nil)
+
+// Accept implements ExpressionVisitor
+func (t *LessOrEqualsExpression) Accept(visitor ExpressionVisitor) interface{} {
+	return visitor.LessOrEquals(t)
+}
+
+// LessOrEquals constructs a LessOrEqualsExpression
+func LessOrEquals(left Expression, right Expression) Expression {
+	return reparent(&LessOrEqualsExpression{binaryExpression{expression{}, left, right}})
+}
@@ -11,4 +11,9 @@ type ExpressionVisitor interface {
 	Literal(c *LiteralExpression) interface{}
 	Not(e *NotExpression) interface{}
 	IsNull(e *IsNullExpression) interface{}
+	Greater(e *GreaterExpression) interface{}
+	GreaterOrEquals(e *GreaterOrEqualsExpression) interface{}
+	Less(e *LessExpression) interface{}
+	LessOrEquals(e *LessOrEqualsExpression) interface{}
+	In(e *InExpression) interface{}
 }
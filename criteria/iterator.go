@@ -50,6 +50,26 @@ func (i *postOrderIterator) IsNull(exp *IsNullExpression) interface{} {
 	return i.visit(exp)
 }
 
+func (i *postOrderIterator) Greater(exp *GreaterExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) GreaterOrEquals(exp *GreaterOrEqualsExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) Less(exp *LessExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) LessOrEquals(exp *LessOrEqualsExpression) interface{} {
+	return i.binary(exp)
+}
+
+func (i *postOrderIterator) In(exp *InExpression) interface{} {
+	return i.binary(exp)
+}
+
 func (i *postOrderIterator) binary(exp BinaryExpression) bool {
 	if exp.Left().Accept(i) == false {
 		return false
@@ -0,0 +1,167 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var attachment = a.Type("Attachment", func() {
+	a.Description(`JSONAPI store for the metadata of a file attached to a work item or a comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("attachments")
+	})
+	a.Attribute("id", d.UUID, "ID of the attachment", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", attachmentAttributes)
+	a.Attribute("relationships", attachmentRelationships)
+	a.Attribute("links", attachmentLinks)
+	a.Required("type", "attributes")
+})
+
+var attachmentAttributes = a.Type("AttachmentAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an attachment. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("filename", d.String, "The name of the attached file", func() {
+		a.Example("screenshot.png")
+	})
+	a.Attribute("content-type", d.String, "The media type of the attached file", func() {
+		a.Example("image/png")
+	})
+	a.Attribute("size", d.Integer, "The size of the attached file in bytes", func() {
+		a.Example(48213)
+	})
+	a.Attribute("checksum", d.String, "The hex encoded SHA-256 hash of the content", func() {
+		a.Example("9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08")
+	})
+	a.Attribute("markdown", d.String, "The Markdown link to the attachment for descriptions and comments", func() {
+		a.Example("[screenshot.png](https://api.openshift.io/api/attachments/40bbdd3d-8b5d-4fd6-ac90-7236b669af04/content)")
+	})
+	a.Attribute("created-at", d.DateTime, "When the file was attached", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("filename", "content-type", "size", "checksum", "markdown")
+})
+
+var attachmentRelationships = a.Type("AttachmentRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("workitem", relationGeneric, "This defines the work item to which the file is attached")
+	a.Attribute("comment", relationGeneric, "This defines the comment to which the file is attached, if any")
+	a.Attribute("creator", relationGeneric, "This defines the user who attached the file")
+})
+
+var attachmentLinks = a.Type("AttachmentLinks", func() {
+	a.Attribute("self", d.String)
+	a.Attribute("related", d.String)
+	a.Attribute("download", d.String, "The URL from which the content of the file is downloaded")
+})
+
+var attachmentList = JSONList(
+	"Attachment", "Holds the list of attachments",
+	attachment,
+	pagingLinks,
+	listMeta)
+
+var attachmentSingle = JSONSingle(
+	"Attachment", "Holds a single attachment",
+	attachment,
+	nil)
+
+var _ = a.Resource("work_item_attachments", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("attachments"),
+		)
+		a.Description("List the files attached to the work item and its comments.")
+		a.Params(func() {
+			a.Param("comment", d.UUID, "Only list the files attached to this comment of the work item")
+		})
+		a.Response(d.OK, attachmentList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("upload", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("attachments"),
+		)
+		a.Description(`Attach a file to the work item or to one of its comments.
+The request body is the content of the file and the Content-Type header its media type.
+Uploads fail if the file is larger than allowed or if the attachments of the space would exceed its quota.`)
+		a.Params(func() {
+			a.Param("filename", d.String, "The name of the file")
+			a.Param("comment", d.UUID, "The comment of the work item to which the file is attached")
+			a.Required("filename")
+		})
+		a.Response(d.Created, "/attachments/.*", func() {
+			a.Media(attachmentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("attachment", func() {
+	a.BasePath("/attachments")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:attachmentID"),
+		)
+		a.Description("Retrieve the metadata of the attachment for the given ID.")
+		a.Params(func() {
+			a.Param("attachmentID", d.UUID, "ID of the attachment")
+		})
+		a.Response(d.OK, attachmentSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("download", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:attachmentID/content"),
+		)
+		a.Description(`Download the content of the attachment for the given ID.
+The response has the media type of the attached file.`)
+		a.Params(func() {
+			a.Param("attachmentID", d.UUID, "ID of the attachment")
+		})
+		a.Response(d.OK, "application/octet-stream")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:attachmentID"),
+		)
+		a.Description("Delete the attachment for the given ID together with its content.")
+		a.Params(func() {
+			a.Param("attachmentID", d.UUID, "ID of the attachment to delete")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,57 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var backlink = a.Type("Backlink", func() {
+	a.Description(`JSONAPI store for a reference to a work item in the description of a work item or in a comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("backlinks")
+	})
+	a.Attribute("id", d.UUID, "ID of the reference", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", backlinkAttributes)
+	a.Attribute("relationships", backlinkRelationships)
+	a.Required("type", "attributes")
+})
+
+var backlinkAttributes = a.Type("BacklinkAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a backlink. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("text", d.String, "The reference as written in the markup", func() {
+		a.Example("#12")
+	})
+	a.Attribute("created-at", d.DateTime, "When the reference was made", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("text")
+})
+
+var backlinkRelationships = a.Type("BacklinkRelations", func() {
+	a.Attribute("workitem", relationGeneric, "This defines the work item in whose description or comment the reference is made")
+	a.Attribute("comment", relationGeneric, "This defines the comment in which the reference is made, if any")
+})
+
+var backlinkList = JSONList(
+	"Backlink", "Holds the list of places in which a work item is mentioned",
+	backlink,
+	nil,
+	listMeta)
+
+var _ = a.Resource("work_item_backlinks", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("mentioned-in"),
+		)
+		a.Description(`List the descriptions of work items and the comments in which the given work item is mentioned, most recent first.
+The mentioning work items are included.`)
+		a.Response(d.OK, backlinkList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -46,6 +46,7 @@ var commentAttributes = a.Type("CommentAttributes", func() {
 	a.Attribute("markup", d.String, "The comment markup associated with the body", func() {
 		a.Example("Markdown")
 	})
+	a.Attribute("reactions", a.HashOf(d.String, d.Integer), "The number of reactions to the comment per emoji. Only set when comments are listed as threads.")
 })
 
 var createCommentAttributes = a.Type("CreateCommentAttributes", func() {
@@ -71,6 +72,7 @@ var commentRelationships = a.Type("CommentRelations", func() {
 	a.Attribute("created-by", commentCreatedBy, "DEPRECATED. This defines the creator of the comment.")
 	a.Attribute("parent", relationGeneric, "This defines the owning resource of the comment.")
 	a.Attribute("parent-comment", relationGeneric, "This defines the parent comment resource.")
+	a.Attribute("replies", relationGenericList, "The direct replies to the comment. Only set when comments are listed as threads.")
 })
 
 var commentCreatedBy = a.Type("CommentCreatedBy", func() {
@@ -226,4 +228,207 @@ var _ = a.Resource("work_item_comments", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
+
+	a.Action("threads", func() {
+		a.Routing(
+			a.GET("comments/threads"),
+		)
+		a.Description(`List the comments associated with the given work item which aren't replies. All their replies are
+		included and referenced from the "replies" relationship of the comment they reply to.`)
+		a.Params(func() {
+			a.Param("page[offset]", d.String, `Paging start position is a string pointing to
+			the beginning of pagination.  The value starts from 0 onwards.`)
+			a.Param("page[limit]", d.Integer, `Paging size is the number of items in a page`)
+		})
+		a.Response(d.OK, commentArray)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("comment_replies", func() {
+	a.Parent("comments")
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("replies"),
+		)
+		a.Description("Reply to the given comment. The reply belongs to the same work item as the comment.")
+		a.Payload(createSingleComment)
+		a.Response(d.Created, "/comments/.*", func() {
+			a.Media(commentSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("thread", func() {
+		a.Routing(
+			a.GET("thread"),
+		)
+		a.Description(`Show the given comment with all replies to it and to its replies. The replies are included and
+		referenced from the "replies" relationship of the comment they reply to.`)
+		a.Response(d.OK, commentSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+})
+
+var commentReaction = a.Type("CommentReaction", func() {
+	a.Description(`JSONAPI store for the emoji with which a user reacted to a comment`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("comment-reactions")
+	})
+	a.Attribute("attributes", commentReactionAttributes)
+	a.Attribute("relationships", commentReactionRelationships)
+	a.Required("type", "attributes")
+})
+
+var commentReactionAttributes = a.Type("CommentReactionAttributes", func() {
+	a.Attribute("emoji", d.String, "The emoji or its short name", func() {
+		a.Example("+1")
+	})
+	a.Attribute("created-at", d.DateTime, "When the user reacted", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Required("emoji")
+})
+
+var commentReactionRelationships = a.Type("CommentReactionRelations", func() {
+	a.Attribute("creator", relationGeneric, "The user who reacted")
+	a.Attribute("comment", relationGeneric, "The comment to which the user reacted")
+})
+
+var commentReactionListMeta = a.Type("CommentReactionListMeta", func() {
+	a.Attribute("totalCount", d.Integer)
+	a.Attribute("counts", a.HashOf(d.String, d.Integer), "The number of reactions per emoji")
+	a.Required("totalCount", "counts")
+})
+
+var commentReactionList = JSONList(
+	"CommentReaction", "Holds the reactions to a comment",
+	commentReaction,
+	nil,
+	commentReactionListMeta,
+)
+
+var commentReactionSingle = JSONSingle(
+	"CommentReaction", "Holds a single reaction to a comment",
+	commentReaction,
+	nil,
+)
+
+var _ = a.Resource("comment_reactions", func() {
+	a.Parent("comments")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("reactions"),
+		)
+		a.Description("List the reactions to the given comment")
+		a.Response(d.OK, commentReactionList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("reactions/:emoji"),
+		)
+		a.Description(`React with the given emoji to the comment. Responds with 201 when the reaction is added.
+			Reacting twice with the same emoji has no effect and responds with 200.`)
+		a.Params(func() {
+			a.Param("emoji", d.String, "The emoji or one of the short names +1, -1, laugh, confused, heart, hooray, rocket and eyes")
+		})
+		a.Response(d.Created, "/comments/.*/reactions/.*", func() {
+			a.Media(commentReactionSingle)
+		})
+		a.Response(d.OK, commentReactionSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("reactions/:emoji"),
+		)
+		a.Description("Remove the reaction of the current user with the given emoji from the comment")
+		a.Params(func() {
+			a.Param("emoji", d.String, "The emoji or its short name")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var commentRevision = a.Type("CommentRevision", func() {
+	a.Description(`JSONAPI store for a version of a comment. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("comment-revisions")
+	})
+	a.Attribute("id", d.UUID, "ID of the revision", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", commentRevisionAttributes)
+	a.Attribute("relationships", commentRevisionRelationships)
+	a.Required("type", "attributes")
+})
+
+var commentRevisionAttributes = a.Type("CommentRevisionAttributes", func() {
+	a.Attribute("revision-type", d.String, "The change which led to the revision", func() {
+		a.Enum("create", "update", "delete")
+	})
+	a.Attribute("time", d.DateTime, "When the comment was changed", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("body", d.String, "The comment body of the revision", func() {
+		a.Example("This is really interesting")
+	})
+	a.Attribute("body.rendered", d.String, "The comment body of the revision rendered in HTML", func() {
+		a.Example("<p>This is really interesting</p>\n")
+	})
+	a.Attribute("markup", d.String, "The comment markup of the revision", func() {
+		a.Example("Markdown")
+	})
+	a.Required("revision-type", "time")
+})
+
+var commentRevisionRelationships = a.Type("CommentRevisionRelations", func() {
+	a.Attribute("modifier", relationGeneric, "The user who changed the comment")
+	a.Attribute("comment", relationGeneric, "The comment which was changed")
+})
+
+var commentRevisionList = JSONList(
+	"CommentRevision", "Holds the edit history of a comment",
+	commentRevision,
+	nil,
+	commentListMeta,
+)
+
+var _ = a.Resource("comment_revisions", func() {
+	a.Parent("comments")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("revisions"),
+		)
+		a.Description("List the edit history of the given comment, oldest revision first")
+		a.Response(d.OK, commentRevisionList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+	})
 })
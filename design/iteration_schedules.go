@@ -0,0 +1,163 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var iterationSchedule = a.Type("IterationSchedule", func() {
+	a.Description(`JSONAPI store for the data of a recurring iteration schedule. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("iterationschedules")
+	})
+	a.Attribute("id", d.UUID, mandatoryOnUpdate("ID of the iteration schedule"), func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", iterationScheduleAttributes)
+	a.Attribute("relationships", iterationScheduleRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var iterationScheduleAttributes = a.Type("IterationScheduleAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an iteration schedule. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("start-at", d.DateTime, mandatoryOnCreate("When the first iteration of the schedule starts"), func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("cadence-days", d.Integer, mandatoryOnCreate("The number of days between the starts of two iterations"), func() {
+		a.Minimum(1)
+		a.Example(14)
+	})
+	a.Attribute("length-days", d.Integer, "The number of days an iteration lasts (defaults to the cadence)", func() {
+		a.Minimum(1)
+		a.Example(14)
+	})
+	a.Attribute("name-pattern", d.String, mandatoryOnCreate("The name of the generated iterations. {n} is replaced with the number of the iteration, {start} and {end} with its dates."), func() {
+		a.Example("Sprint {n}")
+	})
+	a.Attribute("lookahead-days", d.Integer, "How many days in advance iterations are generated (defaults to 30)", func() {
+		a.Minimum(0)
+		a.Maximum(366)
+		a.Example(30)
+	})
+	a.Attribute("active", d.Boolean, "Whether iterations are generated (defaults to true)")
+	a.Attribute("next-sequence", d.Integer, "The number of the next iteration to generate (read-only)", func() {
+		a.Example(3)
+	})
+	a.Attribute("created-at", d.DateTime, "When the iteration schedule was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the iteration schedule was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(23)
+	})
+})
+
+var iterationScheduleRelationships = a.Type("IterationScheduleRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+	a.Attribute("parent", relationGeneric, "The iteration below which the iterations are generated (defaults to the root iteration of the space and can't be changed)")
+})
+
+var iterationScheduleList = JSONList(
+	"IterationSchedule", "Holds the list of iteration schedules",
+	iterationSchedule,
+	pagingLinks,
+	listMeta)
+
+var iterationScheduleSingle = JSONSingle(
+	"IterationSchedule", "Holds a single iteration schedule",
+	iterationSchedule,
+	nil)
+
+var _ = a.Resource("space_iteration_schedules", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("iteration-schedules"),
+		)
+		a.Description("List the iteration schedules of a space.")
+		a.Response(d.OK, iterationScheduleList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("iteration-schedules"),
+		)
+		a.Description("Create an iteration schedule in the space. The iterations within the lookahead window are generated right away.")
+		a.Payload(iterationScheduleSingle)
+		a.Response(d.Created, "/iterationschedules/.*", func() {
+			a.Media(iterationScheduleSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("iteration_schedule", func() {
+	a.BasePath("/iterationschedules")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:scheduleID"),
+		)
+		a.Description("Retrieve the iteration schedule for the given ID.")
+		a.Params(func() {
+			a.Param("scheduleID", d.UUID, "ID of the iteration schedule")
+		})
+		a.Response(d.OK, iterationScheduleSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:scheduleID"),
+		)
+		a.Description("Update the iteration schedule for the given ID. Iterations which were already generated are left alone. The start, cadence and length can only be changed as long as the schedule generated no iterations.")
+		a.Params(func() {
+			a.Param("scheduleID", d.UUID, "ID of the iteration schedule to update")
+		})
+		a.Payload(iterationScheduleSingle)
+		a.Response(d.OK, iterationScheduleSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:scheduleID"),
+		)
+		a.Description("Delete the iteration schedule for the given ID. The iterations it generated are kept.")
+		a.Params(func() {
+			a.Param("scheduleID", d.UUID, "ID of the iteration schedule to delete")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
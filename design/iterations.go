@@ -69,6 +69,34 @@ var iterationSingle = JSONSingle(
 	iteration,
 	nil)
 
+var iterationRollover = a.Type("IterationRollover", func() {
+	a.Description(`JSONAPI store for the data of an iteration rollover, i.e. closing an iteration and moving its unfinished work items to another iteration. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("iterationrollovers")
+	})
+	a.Attribute("attributes", iterationRolloverAttributes)
+	a.Attribute("relationships", iterationRolloverRelationships)
+	a.Required("type")
+})
+
+var iterationRolloverAttributes = a.Type("IterationRolloverAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of an iteration rollover. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("include-children", d.Boolean, "Whether the child iterations are closed and rolled over as well. Defaults to false.")
+	a.Attribute("moved-count", d.Integer, "The number of unfinished work items which were moved to the target iteration")
+	a.Attribute("remaining-count", d.Integer, "The number of finished work items which stayed in the closed iterations")
+})
+
+var iterationRolloverRelationships = a.Type("IterationRolloverRelations", func() {
+	a.Attribute("target", relationGeneric, mandatoryOnCreate("The iteration to which the unfinished work items are moved"))
+	a.Attribute("iterations", relationGenericList, "The iterations which were closed")
+	a.Attribute("workitems", relationGenericList, "The work items which were moved")
+})
+
+var iterationRolloverSingle = JSONSingle(
+	"IterationRollover", "Holds a single iteration rollover",
+	iterationRollover,
+	nil)
+
 // new version of "list" for migration
 var _ = a.Resource("iteration", func() {
 	a.BasePath("/iterations")
@@ -142,6 +170,24 @@ var _ = a.Resource("iteration", func() {
 		a.Response(d.Forbidden, JSONAPIErrors)
 		a.Response(d.NoContent)
 	})
+	a.Action("rollover", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("/:iterationID/rollover"),
+		)
+		a.Description("Close the iteration and move its work items which aren't done yet to the target iteration. Nothing is changed if one of the work items can't be moved.")
+		a.Params(func() {
+			a.Param("iterationID", d.UUID, "ID of the iteration to close")
+		})
+		a.Payload(iterationRolloverSingle)
+		a.Response(d.OK, iterationRolloverSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+	})
 })
 
 // new version of "list" for migration
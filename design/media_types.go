@@ -37,6 +37,12 @@ var meta = a.Type("workItemListResponseMeta", func() {
 	a.Required("totalCount")
 })
 
+// listMeta holds the meta data of lists which aren't lists of work items
+var listMeta = a.Type("ListMeta", func() {
+	a.Attribute("totalCount", d.Integer)
+	a.Required("totalCount")
+})
+
 // position represents the ID of the workitem above which the to-be-reordered workitem(s) should be placed
 var position = a.Type("workItemReorderPosition", func() {
 	a.Description("Position represents the ID of the workitem above which the to-be-reordered workitem(s) should be placed")
@@ -56,12 +62,14 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 	a.Attribute("query", d.String, "Search query")
 	a.Attribute("schedule", d.String, "Schedule for fetch and import")
 	a.Attribute("trackerID", d.UUID, "Tracker ID")
+	a.Attribute("pushEnabled", d.Boolean, "Write changes of the imported work items back to the remote tracker")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("id")
 	a.Required("query")
 	a.Required("schedule")
 	a.Required("trackerID")
+	a.Required("pushEnabled")
 	a.Required("relationships")
 
 	a.View("default", func() {
@@ -69,6 +77,7 @@ var TrackerQuery = a.MediaType("application/vnd.trackerquery+json", func() {
 		a.Attribute("query")
 		a.Attribute("schedule")
 		a.Attribute("trackerID")
+		a.Attribute("pushEnabled")
 		a.Attribute("relationships")
 	})
 })
@@ -0,0 +1,162 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var watch = a.Type("Watch", func() {
+	a.Description(`JSONAPI store for the data of a watch, i.e. a work item, iteration or space about which the user wants to be notified. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("watches")
+	})
+	a.Attribute("id", d.UUID, "ID of the watch", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", watchAttributes)
+	a.Attribute("relationships", watchRelationships)
+	a.Required("type", "attributes")
+})
+
+var watchAttributes = a.Type("WatchAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a watch. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("created-at", d.DateTime, "When the user started to watch the target", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var watchRelationships = a.Type("WatchRelations", func() {
+	a.Attribute("target", relationGeneric, "The watched work item, iteration or space")
+	a.Attribute("identity", relationGeneric, "The identity which watches the target")
+})
+
+var watchList = JSONList(
+	"Watch", "Holds the list of watches",
+	watch,
+	nil,
+	nil)
+
+var watchSingle = JSONSingle(
+	"Watch", "Holds a single watch",
+	watch,
+	nil)
+
+var _ = a.Resource("watches", func() {
+	a.Parent("user")
+	a.BasePath("/watches")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the work items, iterations and spaces watched by the authenticated user")
+		a.Response(d.OK, watchList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:targetType/:targetID"),
+		)
+		a.Description("Watch a work item, iteration or space. Watching a target twice has no effect.")
+		a.Params(func() {
+			a.Param("targetType", d.String, "Type of the target to watch", func() {
+				a.Enum("workitems", "iterations", "spaces")
+			})
+			a.Param("targetID", d.UUID, "ID of the target to watch")
+		})
+		a.Response(d.OK, watchSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:targetType/:targetID"),
+		)
+		a.Description("Stop watching a work item, iteration or space")
+		a.Params(func() {
+			a.Param("targetType", d.String, "Type of the watched target", func() {
+				a.Enum("workitems", "iterations", "spaces")
+			})
+			a.Param("targetID", d.UUID, "ID of the watched target")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
+
+var notificationPreference = a.Type("NotificationPreference", func() {
+	a.Description(`JSONAPI store for the data of a notification preference. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("notificationpreferences")
+	})
+	a.Attribute("id", d.String, "ID of the preference made of its event type and channel", func() {
+		a.Example("assigned:email")
+	})
+	a.Attribute("attributes", notificationPreferenceAttributes)
+	a.Required("type", "attributes")
+})
+
+var notificationPreferenceAttributes = a.Type("NotificationPreferenceAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a notification preference. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("event-type", d.String, "The event type: assigned, mentioned, state-change, comment or update", func() {
+		a.Enum("assigned", "mentioned", "state-change", "comment", "update")
+	})
+	a.Attribute("channel", d.String, "The channel on which notifications are delivered", func() {
+		a.Enum("email", "web")
+	})
+	a.Attribute("enabled", d.Boolean, "Whether the user wants to be notified about the event type on the channel", func() {
+		a.Example(true)
+	})
+	a.Required("event-type", "channel", "enabled")
+})
+
+var notificationPreferenceList = JSONList(
+	"NotificationPreference", "Holds the notification preferences for every event type and channel",
+	notificationPreference,
+	nil,
+	nil)
+
+var updateNotificationPreferencesPayload = a.Type("UpdateNotificationPreferencesPayload", func() {
+	a.Attribute("data", a.ArrayOf(notificationPreference))
+	a.Required("data")
+})
+
+var _ = a.Resource("notification_preferences", func() {
+	a.Parent("user")
+	a.BasePath("/notification-preferences")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the notification preferences of the authenticated user. Without a stored preference the user is notified.")
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH(""),
+		)
+		a.Description("Update the given notification preferences of the authenticated user and return all of them")
+		a.Payload(updateNotificationPreferencesPayload)
+		a.Response(d.OK, notificationPreferenceList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+})
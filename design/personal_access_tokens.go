@@ -0,0 +1,172 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var personalAccessToken = a.Type("PersonalAccessToken", func() {
+	a.Description(`JSONAPI store for the data of a personal access token. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("personalaccesstokens")
+	})
+	a.Attribute("id", d.UUID, "ID of the personal access token", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", personalAccessTokenAttributes)
+	a.Attribute("relationships", personalAccessTokenRelationships)
+	a.Required("type", "attributes")
+})
+
+var personalAccessTokenAttributes = a.Type("PersonalAccessTokenAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a personal access token. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("name", d.String, mandatoryOnCreate("The name which tells what the token is used for"), func() {
+		a.Example("release bot")
+	})
+	a.Attribute("scopes", a.ArrayOf(d.String), mandatoryOnCreate("What the token allows to do: read-only, workitem-write and/or space-admin"), func() {
+		a.Example([]string{"workitem-write"})
+	})
+	a.Attribute("token", d.String, "The token to put in the Authorization header. It is only returned when the token is created.", func() {
+		a.Example("witpat_0nsO1v1rvT1HjKOiz0hVv8pp7ahyqmCgJL4R7XBHwBk")
+	})
+	a.Attribute("expires-at", d.DateTime, "When the token expires. A token without expiry time stays valid until it is revoked.", func() {
+		a.Example("2018-11-29T23:18:14Z")
+	})
+	a.Attribute("last-used-at", d.DateTime, "When the token was last used", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("revoked-at", d.DateTime, "When the token was revoked", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("created-at", d.DateTime, "When the token was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var personalAccessTokenRelationships = a.Type("PersonalAccessTokenRelations", func() {
+	a.Attribute("identity", relationGeneric, "The identity on whose behalf the token acts. Tokens can be created for the current user or one of its service accounts.")
+	a.Attribute("creator", relationGeneric, "The identity which created the token")
+})
+
+var personalAccessTokenList = JSONList(
+	"PersonalAccessToken", "Holds the list of personal access tokens",
+	personalAccessToken,
+	nil,
+	nil)
+
+var personalAccessTokenSingle = JSONSingle(
+	"PersonalAccessToken", "Holds a single personal access token",
+	personalAccessToken,
+	nil)
+
+var _ = a.Resource("personal_access_tokens", func() {
+	a.Parent("user")
+	a.BasePath("/tokens")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the personal access tokens created by the authenticated user")
+		a.Response(d.OK, personalAccessTokenList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a personal access token for the authenticated user or one of its service accounts")
+		a.Payload(personalAccessTokenSingle)
+		a.Response(d.Created, personalAccessTokenSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("revoke", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:tokenID"),
+		)
+		a.Description("Revoke the personal access token with the given ID")
+		a.Params(func() {
+			a.Param("tokenID", d.UUID, "ID of the personal access token to revoke")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var serviceAccount = a.Type("ServiceAccount", func() {
+	a.Description(`JSONAPI store for the data of a service account, i.e. an identity of a bot which authenticates with personal access tokens. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("identities")
+	})
+	a.Attribute("id", d.UUID, "ID of the service account identity", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", serviceAccountAttributes)
+	a.Required("type", "attributes")
+})
+
+var serviceAccountAttributes = a.Type("ServiceAccountAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a service account. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("username", d.String, mandatoryOnCreate("The username of the service account"), func() {
+		a.Example("release-bot")
+	})
+	a.Attribute("created-at", d.DateTime, "When the service account was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+})
+
+var serviceAccountList = JSONList(
+	"ServiceAccount", "Holds the list of service accounts",
+	serviceAccount,
+	nil,
+	nil)
+
+var serviceAccountSingle = JSONSingle(
+	"ServiceAccount", "Holds a single service account",
+	serviceAccount,
+	nil)
+
+var _ = a.Resource("service_accounts", func() {
+	a.Parent("user")
+	a.BasePath("/serviceaccounts")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET(""),
+		)
+		a.Description("List the service accounts owned by the authenticated user")
+		a.Response(d.OK, serviceAccountList)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a service account owned by the authenticated user")
+		a.Payload(serviceAccountSingle)
+		a.Response(d.Created, serviceAccountSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
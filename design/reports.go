@@ -0,0 +1,145 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var burndown = a.Type("Burndown", func() {
+	a.Description(`JSONAPI store for the daily remaining work of an iteration. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("burndowns")
+	})
+	a.Attribute("id", d.UUID, "ID of the iteration", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", burndownAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var burndownAttributes = a.Type("BurndownAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a burndown. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("field", d.String, "The numeric work item field that is summed up", func() {
+		a.Example("storypoints")
+	})
+	a.Attribute("startAt", d.DateTime, "When the iteration starts", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("endAt", d.DateTime, "When the iteration ends", func() {
+		a.Example("2016-12-13T23:18:14Z")
+	})
+	a.Attribute("points", a.ArrayOf(burndownPoint), "The work at the end of each day from the start of the iteration until its end (or today)")
+	a.Required("field", "startAt", "endAt", "points")
+})
+
+var burndownPoint = a.Type("BurndownPoint", func() {
+	a.Description(`The work in an iteration at the end of a day`)
+	a.Attribute("date", d.DateTime, "The beginning of the day (UTC)", func() {
+		a.Example("2016-11-29T00:00:00Z")
+	})
+	a.Attribute("totalCount", d.Integer, "The number of work items in the iteration")
+	a.Attribute("totalValue", d.Number, "The sum of the field over the work items in the iteration")
+	a.Attribute("remainingCount", d.Integer, "The number of work items in the iteration which are not done")
+	a.Attribute("remainingValue", d.Number, "The sum of the field over the work items in the iteration which are not done")
+	a.Required("date", "totalCount", "totalValue", "remainingCount", "remainingValue")
+})
+
+var velocity = a.Type("Velocity", func() {
+	a.Description(`JSONAPI store for the completed work of the closed iterations of a space. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("velocities")
+	})
+	a.Attribute("id", d.UUID, "ID of the space", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", velocityAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var velocityAttributes = a.Type("VelocityAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a velocity. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("field", d.String, "The numeric work item field that is summed up", func() {
+		a.Example("storypoints")
+	})
+	a.Attribute("iterations", a.ArrayOf(iterationVelocity), "The closed iterations of the space sorted by their end")
+	a.Attribute("averageCount", d.Number, "The average number of work items completed per iteration")
+	a.Attribute("averageValue", d.Number, "The average sum of the field over the work items completed per iteration")
+	a.Required("field", "iterations", "averageCount", "averageValue")
+})
+
+var iterationVelocity = a.Type("IterationVelocity", func() {
+	a.Description(`The work committed to and completed in a closed iteration`)
+	a.Attribute("id", d.UUID, "ID of the iteration", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("name", d.String, "The name of the iteration", func() {
+		a.Example("Sprint #24")
+	})
+	a.Attribute("startAt", d.DateTime, "When the iteration started", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("endAt", d.DateTime, "When the iteration ended", func() {
+		a.Example("2016-12-13T23:18:14Z")
+	})
+	a.Attribute("committedCount", d.Integer, "The number of work items in the iteration at its start")
+	a.Attribute("committedValue", d.Number, "The sum of the field over the work items in the iteration at its start")
+	a.Attribute("completedCount", d.Integer, "The number of work items in the iteration which were done at its end")
+	a.Attribute("completedValue", d.Number, "The sum of the field over the work items in the iteration which were done at its end")
+	a.Required("id", "name", "startAt", "endAt", "committedCount", "committedValue", "completedCount", "completedValue")
+})
+
+var burndownSingle = JSONSingle(
+	"Burndown", "Holds the daily remaining work of an iteration",
+	burndown,
+	nil)
+
+var velocitySingle = JSONSingle(
+	"Velocity", "Holds the completed work of the closed iterations of a space",
+	velocity,
+	nil)
+
+// reportParams are the parameters of all the report actions
+func reportParams() {
+	a.Params(func() {
+		a.Param("field", d.String, "Name of the numeric work item field to sum up", func() {
+			a.Default("storypoints")
+		})
+	})
+}
+
+var _ = a.Resource("iteration_report", func() {
+	a.BasePath("/iterations")
+
+	a.Action("burndown", func() {
+		a.Routing(
+			a.GET("/:iterationID/burndown"),
+		)
+		a.Description("Show the daily remaining work of the iteration and its child iterations, rebuilt from the revisions of the work items")
+		a.Params(func() {
+			a.Param("iterationID", d.UUID, "ID of the iteration")
+		})
+		reportParams()
+		a.Response(d.OK, burndownSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("space_report", func() {
+	a.Parent("space")
+
+	a.Action("velocity", func() {
+		a.Routing(
+			a.GET("velocity"),
+		)
+		a.Description("Show the work committed to and completed in each closed iteration of the space, rebuilt from the revisions of the work items")
+		reportParams()
+		a.Response(d.OK, velocitySingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -39,6 +39,10 @@ var _ = a.Resource("search", func() {
 				a.Example(`{$AND: [{"space": "f73988a2-1916-4572-910b-2df23df4dcc3"}, {"state": "NEW"}]}`)
 			})
 			a.Param("spaceID", d.String, "The optional space ID of the space to be searched in, if the filter[expression] query parameter is not provided")
+			a.Param("sort", d.String, `Comma separated list of fields to sort the results of a filter[expression] by.
+				A field prefixed with "-" is sorted in descending order (e.g. "-system.updated_at,priority").
+				Custom fields can only be used when the filter is restricted to a single space and enum fields
+				are sorted by the order of their values. When not given, the work items are sorted by title.`)
 		})
 		a.Response(d.OK, func() {
 			a.Media(searchWorkItemList)
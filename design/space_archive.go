@@ -0,0 +1,89 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var spaceImport = a.Type("SpaceImport", func() {
+	a.Description(`JSONAPI store for the report of a space import. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("spaceimports")
+	})
+	a.Attribute("id", d.UUID, "ID of the imported space", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", spaceImportAttributes)
+	a.Attribute("relationships", spaceImportRelationships)
+	a.Required("type", "attributes")
+})
+
+var spaceImportAttributes = a.Type("SpaceImportAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a space import. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("dry-run", d.Boolean, "Whether the import was rolled back after checking the archive")
+	a.Attribute("counts", a.HashOf(d.String, d.Integer), "The number of imported records per kind", func() {
+		a.Example(map[string]int{"workitem": 42, "comment": 7})
+	})
+	a.Attribute("replaced-identities", a.ArrayOf(d.String), "The usernames of the archive other than the one of the importing user. The importing user stands in for them.", func() {
+		a.Example([]string{"alice"})
+	})
+	a.Attribute("renumbered-workitems", a.HashOf(d.String, d.Integer), "The numbers of the work items in the archive which could not be kept mapped to their new numbers", func() {
+		a.Example(map[string]int{"12": 43})
+	})
+	a.Required("dry-run", "counts", "replaced-identities", "renumbered-workitems")
+})
+
+var spaceImportRelationships = a.Type("SpaceImportRelations", func() {
+	a.Attribute("space", relationGeneric, "The imported space. It is left out for a dry run.")
+})
+
+var spaceImportSingle = JSONSingle(
+	"SpaceImport", "Holds the report of a space import",
+	spaceImport,
+	nil)
+
+var _ = a.Resource("space_archive", func() {
+	a.BasePath("/spacearchives")
+
+	a.Action("export", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:spaceID"),
+		)
+		a.Description(`Export the space with all its content as an archive of JSON records, one per line.
+Only the owner of the space can export it.`)
+		a.Params(func() {
+			a.Param("spaceID", d.UUID, "ID of the space to export")
+		})
+		a.Response(d.OK, "application/x-ndjson")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("import", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description(`Import an archive written by the export into a new space owned by the current user.
+The request body is the archive and its size is limited. The import either succeeds as a whole or changes nothing.
+All the work item types of the archive must belong to the space template of the exported space. The content of the
+archive is attributed to the importing user because the import can't act on behalf of other users.`)
+		a.Params(func() {
+			a.Param("name", d.String, "Name of the new space; defaults to the name of the exported space")
+			a.Param("dryRun", d.Boolean, "Check the archive and report what would be imported without keeping anything", func() {
+				a.Default(false)
+			})
+		})
+		a.Response(d.OK, spaceImportSingle)
+		a.Response(d.Created, spaceImportSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,154 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var templateMigration = a.Type("TemplateMigration", func() {
+	a.Description(`JSONAPI store for the migration of a space to another space template. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("templatemigrations")
+	})
+	a.Attribute("id", d.UUID, "ID of the migrated space", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", templateMigrationAttributes)
+	a.Attribute("relationships", templateMigrationRelationships)
+	a.Required("type", "attributes")
+})
+
+var templateMigrationAttributes = a.Type("TemplateMigrationAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a template migration. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("type-mapping", a.HashOf(d.String, d.UUID), "Maps work item types of the current template to the ones of the target template. Types which aren't mapped are matched by ID and then by name.")
+	a.Attribute("link-type-mapping", a.HashOf(d.String, d.UUID), "Maps work item link types of the current template to the ones of the target template. Link types which aren't mapped are matched by name.")
+	a.Attribute("field-mappings", a.ArrayOf(templateMigrationFieldMapping), "Replace field values which don't fit the target template")
+	a.Attribute("types", a.ArrayOf(templateMigrationTypeChange), "How the work item types of the current template map to the target template (read-only)")
+	a.Attribute("link-types", a.ArrayOf(templateMigrationLinkTypeChange), "How the work item link types of the current template map to the target template (read-only)")
+	a.Attribute("type-groups", templateMigrationNameDiff, "The work item type groups which the target template adds, removes or keeps (read-only)")
+	a.Attribute("boards", templateMigrationNameDiff, "The boards which the target template adds, removes or keeps (read-only)")
+	a.Attribute("workitems", a.ArrayOf(templateMigrationWorkItemChange), "The work items whose type or field values change (read-only)")
+	a.Attribute("conflicts", a.ArrayOf(d.String), "Why the space can't be migrated as planned (read-only)", func() {
+		a.Example([]string{`the target template has no work item type for the 3 work items of type "Bug"`})
+	})
+})
+
+var templateMigrationFieldMapping = a.Type("TemplateMigrationFieldMapping", func() {
+	a.Attribute("type-id", d.UUID, "Limits the mapping to work items of this type of the current template")
+	a.Attribute("field", d.String, "Name of the field", func() {
+		a.Example("system.state")
+	})
+	a.Attribute("values", a.HashOf(d.String, d.Any), "Maps the current values to the new ones", func() {
+		a.Example(map[string]interface{}{"Resolved": "Done"})
+	})
+	a.Required("field", "values")
+})
+
+var templateMigrationTypeChange = a.Type("TemplateMigrationTypeChange", func() {
+	a.Attribute("source-type-id", d.UUID, "ID of the work item type of the current template")
+	a.Attribute("source-type-name", d.String, "Name of the work item type of the current template")
+	a.Attribute("target-type-id", d.UUID, "ID of the matching work item type of the target template, if any")
+	a.Attribute("target-type-name", d.String, "Name of the matching work item type of the target template, if any")
+	a.Attribute("added-fields", a.ArrayOf(d.String), "Fields which only the target type has")
+	a.Attribute("removed-fields", a.ArrayOf(d.String), "Fields which only the current type has")
+	a.Attribute("changed-fields", a.ArrayOf(d.String), "Fields whose type changes")
+	a.Attribute("workitems", d.Integer, "Number of work items of the type in the space")
+	a.Required("source-type-id", "source-type-name", "added-fields", "removed-fields", "changed-fields", "workitems")
+})
+
+var templateMigrationLinkTypeChange = a.Type("TemplateMigrationLinkTypeChange", func() {
+	a.Attribute("source-link-type-id", d.UUID, "ID of the link type of the current template")
+	a.Attribute("source-link-type-name", d.String, "Name of the link type of the current template")
+	a.Attribute("target-link-type-id", d.UUID, "ID of the matching link type of the target template, if any")
+	a.Attribute("links", d.Integer, "Number of links of the type in the space")
+	a.Required("source-link-type-id", "source-link-type-name", "links")
+})
+
+var templateMigrationNameDiff = a.Type("TemplateMigrationNameDiff", func() {
+	a.Attribute("added", a.ArrayOf(d.String), "Names which only the target template has")
+	a.Attribute("removed", a.ArrayOf(d.String), "Names which only the current template has")
+	a.Attribute("kept", a.ArrayOf(d.String), "Names which both templates have")
+	a.Required("added", "removed", "kept")
+})
+
+var templateMigrationWorkItemChange = a.Type("TemplateMigrationWorkItemChange", func() {
+	a.Attribute("id", d.UUID, "ID of the work item")
+	a.Attribute("number", d.Integer, "Number of the work item")
+	a.Attribute("title", d.String, "Title of the work item")
+	a.Attribute("source-type-id", d.UUID, "Current type of the work item")
+	a.Attribute("target-type-id", d.UUID, "Type of the work item after the migration")
+	a.Attribute("fields", a.ArrayOf(templateMigrationFieldChange), "The field values which change")
+	a.Required("id", "number", "source-type-id", "target-type-id", "fields")
+})
+
+var templateMigrationFieldChange = a.Type("TemplateMigrationFieldChange", func() {
+	a.Attribute("field", d.String, "Name of the field")
+	a.Attribute("from", d.Any, "The current value as it is stored")
+	a.Attribute("to", d.Any, "The value after the migration as it is stored")
+	a.Required("field")
+})
+
+var templateMigrationRelationships = a.Type("TemplateMigrationRelations", func() {
+	a.Attribute("source-template", relationGeneric, "The current space template of the space (read-only)")
+	a.Attribute("target-template", relationGeneric, "The space template to migrate to")
+})
+
+var templateMigrationSingle = JSONSingle(
+	"TemplateMigration", "Holds the plan of a template migration",
+	templateMigration,
+	nil)
+
+var _ = a.Resource("space_template_migration", func() {
+	a.Parent("space")
+
+	a.Action("clone", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("template/clone"),
+		)
+		a.Description(`Clone the space template of the space into a template which belongs to the space
+and migrate the space to it. The clone can then be changed without affecting other spaces.`)
+		a.Params(func() {
+			a.Param("name", d.String, "Name of the cloned template; defaults to the name of the current template and the space ID")
+		})
+		a.Response(d.Created, spaceTemplateSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("plan", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("template/migration/plan"),
+		)
+		a.Description("Preview the migration of the space to another space template without changing anything")
+		a.Payload(templateMigrationSingle)
+		a.Response(d.OK, templateMigrationSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("apply", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("template/migration"),
+		)
+		a.Description(`Migrate the space to another space template. The work items get their new types and field values.
+The migration is rejected if its plan has conflicts.`)
+		a.Payload(templateMigrationSingle)
+		a.Response(d.OK, templateMigrationSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -31,7 +31,7 @@ var trackerAttributes = a.Type("TrackerAttributes", func() {
 		a.Example("#ffa7cb")
 	})
 	a.Attribute("Type", d.String, "Type of the tracker", func() {
-		a.Enum("github", "jira")
+		a.Enum("github", "jira", "gitlab")
 	})
 	a.Required("URL", "Type")
 })
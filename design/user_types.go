@@ -47,6 +47,7 @@ var CreateTrackerQueryAlternatePayload = a.Type("CreateTrackerQueryAlternatePayl
 		a.MinLength(1)
 	})
 	a.Attribute("trackerID", d.UUID, "Tracker ID")
+	a.Attribute("pushEnabled", d.Boolean, "Write changes of the imported work items back to the remote tracker (defaults to false)")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("query", "schedule", "trackerID")
@@ -64,6 +65,7 @@ var UpdateTrackerQueryAlternatePayload = a.Type("UpdateTrackerQueryAlternatePayl
 		a.MinLength(1)
 	})
 	a.Attribute("trackerID", d.UUID, "Tracker ID")
+	a.Attribute("pushEnabled", d.Boolean, "Write changes of the imported work items back to the remote tracker (defaults to false)")
 	a.Attribute("relationships", trackerQueryRelationships)
 
 	a.Required("query", "schedule", "trackerID")
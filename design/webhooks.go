@@ -0,0 +1,150 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var webhook = a.Type("Webhook", func() {
+	a.Description(`JSONAPI store for the data of a webhook subscription. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("webhooks")
+	})
+	a.Attribute("id", d.UUID, mandatoryOnUpdate("ID of the webhook"), func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", webhookAttributes)
+	a.Attribute("relationships", webhookRelationships)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var webhookAttributes = a.Type("WebhookAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a webhook. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("url", d.String, mandatoryOnCreate("The URL to which the events are posted"), func() {
+		a.Example("https://ci.example.com/hooks/wit")
+	})
+	a.Attribute("secret", d.String, mandatoryOnCreate("The secret used to sign the payloads with HMAC-SHA256. It is never returned."), func() {
+		a.Example("s3cr3t")
+	})
+	a.Attribute("events", a.ArrayOf(d.String), "The event types to deliver. An empty list subscribes to all events.", func() {
+		a.Example([]string{"workitem.create", "workitem.update", "comment.create", "comment.update"})
+	})
+	a.Attribute("active", d.Boolean, "Whether events are delivered to the webhook (defaults to true)")
+	a.Attribute("created-at", d.DateTime, "When the webhook was created", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("updated-at", d.DateTime, "When the webhook was updated", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("version", d.Integer, "Version for optimistic concurrency control (optional during creating)", func() {
+		a.Example(23)
+	})
+})
+
+var webhookRelationships = a.Type("WebhookRelations", func() {
+	a.Attribute("space", relationGeneric, "This defines the owning space")
+})
+
+var webhookList = JSONList(
+	"Webhook", "Holds the list of webhooks",
+	webhook,
+	pagingLinks,
+	listMeta)
+
+var webhookSingle = JSONSingle(
+	"Webhook", "Holds a single webhook",
+	webhook,
+	nil)
+
+var _ = a.Resource("space_webhooks", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("webhooks"),
+		)
+		a.Description("List the webhooks of a space. Only the space owner can see them.")
+		a.Response(d.OK, webhookList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("webhooks"),
+		)
+		a.Description("Create a webhook in the space. Only the space owner can create webhooks.")
+		a.Payload(webhookSingle)
+		a.Response(d.Created, "/webhooks/.*", func() {
+			a.Media(webhookSingle)
+		})
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
+
+var _ = a.Resource("webhook", func() {
+	a.BasePath("/webhooks")
+
+	a.Action("show", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.GET("/:webhookID"),
+		)
+		a.Description("Retrieve the webhook for the given ID.")
+		a.Params(func() {
+			a.Param("webhookID", d.UUID, "ID of the webhook")
+		})
+		a.Response(d.OK, webhookSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:webhookID"),
+		)
+		a.Description("Update the webhook for the given ID.")
+		a.Params(func() {
+			a.Param("webhookID", d.UUID, "ID of the webhook to update")
+		})
+		a.Payload(webhookSingle)
+		a.Response(d.OK, webhookSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:webhookID"),
+		)
+		a.Description("Delete the webhook for the given ID.")
+		a.Params(func() {
+			a.Param("webhookID", d.UUID, "ID of the webhook to delete")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -42,6 +42,8 @@ var workItemBoardColumnData = a.Type("WorkItemBoardColumnData", func() {
 var workItemBoardColumnAttributes = a.Type("WorkItemBoardColumnAttributes", func() {
 	a.Attribute("name", d.String)
 	a.Attribute("order", d.Integer)
+	a.Attribute("wipLimit", d.Integer, "Maximum number of work items of a space in this column; 0 means no limit")
+	a.Attribute("wipLimitEnforced", d.Boolean, "Whether moves exceeding the work in progress limit are rejected instead of only warned about")
 	// TODO(michaelkleinhenz): as soon as we allow column customization, we need
 	// to also provide transRuleKey and transRuleArguments.
 	a.Required("name")
@@ -110,3 +112,53 @@ var _ = a.Resource("work_item_board", func() {
 		a.Response(d.NotFound, JSONAPIErrors)
 	})
 })
+
+var boardColumnStateList = JSONList(
+	"BoardColumnState",
+	`Holds the number of work items of a space in the columns of its boards`,
+	boardColumnStateData,
+	nil,
+	nil,
+)
+
+var boardColumnStateData = a.Type("BoardColumnStateData", func() {
+	a.Description(`the number of work items of a space in a board column`)
+	a.Attribute("type", d.String, "The type string of the board column state", func() {
+		a.Enum("boardcolumnstates")
+	})
+	a.Attribute("id", d.UUID, "ID of the work item board column", func() {
+		a.Example("712f20e4-2202-4469-9a02-b892b7051b2b")
+	})
+	a.Attribute("attributes", boardColumnStateAttributes)
+	a.Attribute("relationships", boardColumnStateRelationships)
+	a.Required("id", "type", "attributes", "relationships")
+})
+
+var boardColumnStateAttributes = a.Type("BoardColumnStateAttributes", func() {
+	a.Attribute("name", d.String)
+	a.Attribute("order", d.Integer)
+	a.Attribute("count", d.Integer, "Number of work items of the space in the column")
+	a.Attribute("wipLimit", d.Integer, "Maximum number of work items of a space in this column; 0 means no limit")
+	a.Attribute("wipLimitEnforced", d.Boolean, "Whether moves exceeding the work in progress limit are rejected instead of only warned about")
+	a.Attribute("wipLimitExceeded", d.Boolean, "Whether the column holds more work items than its work in progress limit")
+	a.Required("name", "order", "count", "wipLimit", "wipLimitEnforced", "wipLimitExceeded")
+})
+
+var boardColumnStateRelationships = a.Type("BoardColumnStateRelationships", func() {
+	a.Attribute("board", relationGeneric, "The work item board to which the column belongs")
+	a.Required("board")
+})
+
+var _ = a.Resource("space_board_state", func() {
+	a.Parent("space")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("boardstate"),
+		)
+		a.Description("List the number of work items of the space per column of the boards of its space template")
+		a.Response(d.OK, boardColumnStateList)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,117 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemGraph = a.Type("WorkItemGraph", func() {
+	a.Description(`JSONAPI store for the graph of the work items linked to a work item. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemgraphs")
+	})
+	a.Attribute("id", d.UUID, "ID of the root work item", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemGraphAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var workItemGraphAttributes = a.Type("WorkItemGraphAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item graph. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("link-type", d.UUID, "ID of the link type that was followed")
+	a.Attribute("direction", d.String, "Whether the links were followed from source to target (forward) or from target to source (reverse)", func() {
+		a.Enum("forward", "reverse")
+	})
+	a.Attribute("nodes", a.ArrayOf(workItemGraphNode), "The work items of the graph, starting with the root work item")
+	a.Attribute("edges", a.ArrayOf(workItemGraphEdge), "The links between the work items of the graph")
+	a.Attribute("critical-path", workItemCriticalPath, "The chain of linked work items with the highest total estimate. Only given if requested.")
+	a.Required("link-type", "direction", "nodes", "edges")
+})
+
+var workItemGraphNode = a.Type("WorkItemGraphNode", func() {
+	a.Description(`A work item in a work item graph`)
+	a.Attribute("id", d.UUID, "ID of the work item", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("number", d.Integer, "The number of the work item in its space", func() {
+		a.Example(42)
+	})
+	a.Attribute("title", d.String, "The title of the work item")
+	a.Attribute("depth", d.Integer, "The minimal number of links between the root work item and this work item", func() {
+		a.Example(1)
+	})
+	a.Attribute("estimate", d.Number, "The value of the estimate field of the work item. Only given if the critical path was requested.")
+	a.Required("id", "depth")
+})
+
+var workItemGraphEdge = a.Type("WorkItemGraphEdge", func() {
+	a.Description(`A link in a work item graph`)
+	a.Attribute("id", d.UUID, "ID of the link")
+	a.Attribute("source", d.UUID, "ID of the source work item")
+	a.Attribute("target", d.UUID, "ID of the target work item")
+	a.Required("id", "source", "target")
+})
+
+var workItemCriticalPath = a.Type("WorkItemCriticalPath", func() {
+	a.Description(`The chain of linked work items with the highest total estimate`)
+	a.Attribute("work-items", a.ArrayOf(d.UUID), "IDs of the work items on the path in link order (source before target)")
+	a.Attribute("estimate", d.Number, "The sum of the estimates of the work items on the path")
+	a.Required("work-items", "estimate")
+})
+
+var workItemGraphSingle = JSONSingle(
+	"WorkItemGraph", "Holds the graph of the work items linked to a work item",
+	workItemGraph,
+	nil)
+
+// workItemGraphParams are the parameters of all the work item graph actions
+func workItemGraphParams() {
+	a.Params(func() {
+		a.Param("linkType", d.UUID, "ID of the link type to follow")
+		a.Param("direction", d.String, "Follow the links from source to target (forward) or from target to source (reverse)", func() {
+			a.Enum("forward", "reverse")
+			a.Default("forward")
+		})
+		a.Param("depth", d.Integer, "Maximum number of links between the root work item and the work items of the graph (-1 follows all links)", func() {
+			a.Minimum(-1)
+			a.Default(-1)
+		})
+		a.Param("criticalPath", d.Boolean, "Compute the chain of linked work items with the highest total estimate. The links must not form cycles.", func() {
+			a.Default(false)
+		})
+		a.Param("estimate", d.String, "Name of the numeric work item field that holds the estimate of the work items", func() {
+			a.Default("effort")
+		})
+		a.Required("linkType")
+	})
+}
+
+var _ = a.Resource("work_item_graph", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("graph"),
+		)
+		a.Description("Show the transitive closure of a link type from the work item as nodes and edges")
+		workItemGraphParams()
+		a.Response(d.OK, workItemGraphSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("dot", func() {
+		a.Routing(
+			a.GET("graph.dot"),
+		)
+		a.Description("Show the transitive closure of a link type from the work item in the Graphviz DOT language. The links of the critical path are highlighted.")
+		workItemGraphParams()
+		a.Response(d.OK, "text/vnd.graphviz")
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
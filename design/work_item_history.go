@@ -0,0 +1,112 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemHistory = a.Type("WorkItemHistory", func() {
+	a.Description(`JSONAPI store for the differences of a work item between two of its revisions. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemhistory")
+	})
+	a.Attribute("id", d.UUID, "ID of the work item", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemHistoryAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var workItemHistoryAttributes = a.Type("WorkItemHistoryAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item history. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("from", d.Integer, "The work item version of the older revision", func() {
+		a.Example(1)
+	})
+	a.Attribute("to", d.Integer, "The work item version of the newer revision", func() {
+		a.Example(4)
+	})
+	a.Attribute("from-timestamp", d.DateTime, "When the older revision was written", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("to-timestamp", d.DateTime, "When the newer revision was written", func() {
+		a.Example("2016-11-30T23:18:14Z")
+	})
+	a.Attribute("fields", a.ArrayOf(workItemFieldChange), "The fields whose values differ between the two revisions")
+	a.Attribute("links", a.ArrayOf(workItemLinkChange), "The links that were created or removed between the two revisions")
+	a.Required("from", "to", "fields", "links")
+})
+
+var workItemFieldChange = a.Type("WorkItemFieldChange", func() {
+	a.Description(`The difference of a single work item field between two revisions`)
+	a.Attribute("name", d.String, "The name of the field", func() {
+		a.Example("system.labels")
+	})
+	a.Attribute("oldValue", d.Any, "The value in the older revision. Markup fields are given with their content and markup.")
+	a.Attribute("newValue", d.Any, "The value in the newer revision. Markup fields are given with their content and markup.")
+	a.Attribute("added", a.ArrayOf(d.Any), "Only for list fields: the elements which were added")
+	a.Attribute("removed", a.ArrayOf(d.Any), "Only for list fields: the elements which were removed")
+	a.Required("name")
+})
+
+var workItemLinkChange = a.Type("WorkItemLinkChange", func() {
+	a.Description(`A link of the work item that was created or removed between two revisions`)
+	a.Attribute("id", d.UUID, "ID of the link", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("change", d.String, "Whether the link was added or removed", func() {
+		a.Enum("added", "removed")
+	})
+	a.Attribute("link-type", d.UUID, "ID of the link type")
+	a.Attribute("source", d.UUID, "ID of the source work item")
+	a.Attribute("target", d.UUID, "ID of the target work item")
+	a.Attribute("timestamp", d.DateTime, "When the link was last changed", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("modifier", d.UUID, "ID of the identity who last changed the link")
+	a.Required("id", "change", "link-type", "source", "target", "timestamp", "modifier")
+})
+
+var workItemHistorySingle = JSONSingle(
+	"WorkItemHistory", "Holds the differences of a work item between two revisions",
+	workItemHistory,
+	nil)
+
+var _ = a.Resource("work_item_history", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("history"),
+		)
+		a.Description("Show the differences of the work item between two of its revisions")
+		a.Params(func() {
+			a.Param("from", d.Integer, "Work item version of the older revision (defaults to the first revision)")
+			a.Param("to", d.Integer, "Work item version of the newer revision (defaults to the latest revision)")
+		})
+		a.UseTrait("conditional") // Refer: goasupport/conditional_request/generator.go
+		a.Response(d.OK, workItemHistorySingle)
+		a.Response(d.NotModified)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("restore", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("history/:version/restore"),
+		)
+		a.Description("Restore the fields of the work item to the values of the given revision. This writes a new revision.")
+		a.Params(func() {
+			a.Param("version", d.Integer, "Work item version of the revision to restore")
+		})
+		a.Response(d.OK, workItemSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
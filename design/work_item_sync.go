@@ -0,0 +1,81 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemSyncStatus = a.Type("WorkItemSyncStatus", func() {
+	a.Description(`JSONAPI store for the synchronization of a work item with the remote tracker item it was imported from. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemsyncstatus")
+	})
+	a.Attribute("id", d.UUID, "ID of the work item", func() {
+		a.Example("40bbdd3d-8b5d-4fd6-ac90-7236b669af04")
+	})
+	a.Attribute("attributes", workItemSyncStatusAttributes)
+	a.Attribute("links", genericLinks)
+	a.Required("type", "attributes")
+})
+
+var workItemSyncStatusAttributes = a.Type("WorkItemSyncStatusAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item sync status. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("remote-item-id", d.String, "ID of the remote tracker item", func() {
+		a.Example("https://api.github.com/repos/fabric8-services/fabric8-wit/issues/1")
+	})
+	a.Attribute("synced-version", d.Integer, "The work item version at the last successful pull or push", func() {
+		a.Example(3)
+	})
+	a.Attribute("remote-updated-at", d.DateTime, "When the remote item was updated at the last successful pull or push", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("last-pulled-at", d.DateTime, "When the remote item was last written to the work item", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("last-pushed-at", d.DateTime, "When the work item was last written to the remote item", func() {
+		a.Example("2016-11-29T23:18:14Z")
+	})
+	a.Attribute("conflict", d.String, "Why the work item and the remote item could not be synchronized")
+	a.Attribute("error", d.String, "The error of the last failed push")
+	a.Required("remote-item-id")
+})
+
+var workItemSyncStatusSingle = JSONSingle(
+	"WorkItemSyncStatus", "Holds the synchronization of a work item with its remote tracker item",
+	workItemSyncStatus,
+	nil)
+
+var _ = a.Resource("work_item_sync", func() {
+	a.Parent("workitem")
+
+	a.Action("show", func() {
+		a.Routing(
+			a.GET("sync"),
+		)
+		a.Description("Show the synchronization of the work item with the remote tracker item it was imported from")
+		a.Response(d.OK, workItemSyncStatusSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+
+	a.Action("resolve", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST("sync/resolve"),
+		)
+		a.Description(`Resolve the sync conflict of the work item. Keeping the "local" side pushes the work item to the remote tracker on the next sync, keeping the "remote" side pulls the last fetched remote item into the work item right away.`)
+		a.Params(func() {
+			a.Param("keep", d.String, "The side of the conflict to keep", func() {
+				a.Enum("local", "remote")
+			})
+			a.Required("keep")
+		})
+		a.Response(d.OK, workItemSyncStatusSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
@@ -0,0 +1,50 @@
+package design
+
+import (
+	d "github.com/goadesign/goa/design"
+	a "github.com/goadesign/goa/design/apidsl"
+)
+
+var workItemTransition = a.Type("WorkItemTransition", func() {
+	a.Description(`JSONAPI store for a change of the state of a work item that its type allows. See also http://jsonapi.org/format/#document-resource-object`)
+	a.Attribute("type", d.String, func() {
+		a.Enum("workitemtransitions")
+	})
+	a.Attribute("attributes", workItemTransitionAttributes)
+	a.Required("type", "attributes")
+})
+
+var workItemTransitionAttributes = a.Type("WorkItemTransitionAttributes", func() {
+	a.Description(`JSONAPI store for all the "attributes" of a work item transition. See also http://jsonapi.org/format/#document-resource-object-attributes`)
+	a.Attribute("from", d.String, "The current state of the work item", func() {
+		a.Example("in progress")
+	})
+	a.Attribute("to", d.String, "The state the work item can change to", func() {
+		a.Example("resolved")
+	})
+	a.Attribute("required-fields", a.ArrayOf(d.String), "The fields that must be set before the state can change")
+	a.Attribute("missing-fields", a.ArrayOf(d.String), "The required fields that are not set on the work item yet")
+	a.Attribute("available", d.Boolean, "Whether the state can change right now, i.e. no required field is missing")
+	a.Required("from", "to", "required-fields", "missing-fields", "available")
+})
+
+var workItemTransitionList = JSONList(
+	"WorkItemTransition", "Holds the list of the state changes of a work item that its type allows",
+	workItemTransition,
+	nil,
+	nil)
+
+var _ = a.Resource("work_item_transitions", func() {
+	a.Parent("workitem")
+
+	a.Action("list", func() {
+		a.Routing(
+			a.GET("transitions"),
+		)
+		a.Description("List the state changes of the work item that the workflow of its type allows")
+		a.Response(d.OK, workItemTransitionList)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+	})
+})
@@ -28,6 +28,7 @@ var workItem = a.Type("WorkItem", func() {
 	})
 	a.Attribute("relationships", workItemRelationships)
 	a.Attribute("links", genericLinksForWorkItem)
+	a.Attribute("meta", a.HashOf(d.String, d.Any), "Holds the \"warnings\" about problems which didn't keep the work item from being created or updated, e.g. an exceeded work in progress limit which isn't enforced")
 	a.Required("type", "attributes")
 })
 
@@ -46,6 +47,8 @@ var workItemRelationships = a.Type("WorkItemRelationships", func() {
 	a.Attribute("parent", relationKindUUID, "This defines the parent of this work item.")
 	a.Attribute("workItemLinks", relationGeneric, "List of links in which this work item is involved")
 	a.Attribute("events", relationGeneric, "List of events in which this work item is involved")
+	a.Attribute("attachments", relationGenericList, "List of files attached to the Work Item and its comments")
+	a.Attribute("mentionedIn", relationGeneric, "List of descriptions and comments in which this work item is mentioned")
 })
 
 // relationBaseType is top level block for WorkItemType relationship
@@ -106,6 +109,55 @@ var workItemReorder = Reorder(
 	workItem,
 	position)
 
+// workItemBulkUpdate is the payload of a bulk update of work items
+var workItemBulkUpdate = a.Type("WorkItemBulkUpdate", func() {
+	a.Description("Changes to apply to a set of work items which is given either as a list of IDs or as a filter expression")
+	a.Attribute("data", workItem, "The changes to apply to each work item. The version attribute is ignored, see items.")
+	a.Attribute("items", a.ArrayOf(workItemBulkUpdateItem), "The work items to update along with the version they are expected to have")
+	a.Attribute("filter", d.String, "A query language expression (see /search?filter) selecting the work items to update in their current version", func() {
+		a.Example(`{"iteration": "f73988a2-1916-4572-910b-2df23df4dcc3"}`)
+	})
+	a.Required("data")
+})
+
+var workItemBulkUpdateItem = a.Type("WorkItemBulkUpdateItem", func() {
+	a.Attribute("id", d.UUID, "ID of the work item to update")
+	a.Attribute("version", d.Integer, "Version of the work item for optimistic concurrency control")
+	a.Required("id", "version")
+})
+
+var workItemBulkUpdateResult = a.Type("WorkItemBulkUpdateResult", func() {
+	a.Attribute("id", d.UUID, "ID of the work item")
+	a.Attribute("status", d.String, "Outcome of the update of the work item", func() {
+		a.Enum("ok", "conflict", "not-found", "forbidden", "bad-parameter")
+	})
+	a.Attribute("version", d.Integer, "The new version of the work item (only when the update succeeded)")
+	a.Attribute("error", d.String, "Why the work item was not updated")
+	a.Attribute("warnings", a.ArrayOf(d.String), "Problems which didn't keep the work item from being updated, e.g. an exceeded work in progress limit which isn't enforced")
+	a.Required("id", "status")
+})
+
+var workItemBulkUpdateMeta = a.Type("WorkItemBulkUpdateMeta", func() {
+	a.Attribute("updated", d.Integer, "Number of updated work items")
+	a.Attribute("failed", d.Integer, "Number of work items which were not updated")
+	a.Required("updated", "failed")
+})
+
+// workItemBulkUpdateResults is the media type for the outcome of a bulk update of work items
+var workItemBulkUpdateResults = a.MediaType("application/vnd.workitembulkupdateresults+json", func() {
+	a.UseTrait("jsonapi-media-type")
+	a.TypeName("WorkItemBulkUpdateResults")
+	a.Description("Holds the outcome of a bulk update of work items")
+	a.Attribute("data", a.ArrayOf(workItemBulkUpdateResult))
+	a.Attribute("meta", workItemBulkUpdateMeta)
+	a.Required("data", "meta")
+	a.View("default", func() {
+		a.Attribute("data")
+		a.Attribute("meta")
+		a.Required("data", "meta")
+	})
+})
+
 // endpoints that DO NOT depend on the space id (ie, when the work item ID is specified in the URL, there's no need to pass the space ID)
 var _ = a.Resource("workitem", func() {
 	a.BasePath("/workitems")
@@ -205,10 +257,10 @@ var _ = a.Resource("workitems", func() {
 			a.Param("filter[expression]", d.String, "accepts query in JSON format and redirects to /api/search? API", func() {
 				a.Example(`{$AND: [{"space": "f73988a2-1916-4572-910b-2df23df4dcc3"}, {"state": "NEW"}]}`)
 			})
-			a.Param("sort", d.String, func() {
-				a.Enum("execution", "created", "updated",
-					"-execution", "-created", "-updated")
-			})
+			a.Param("sort", d.String, `Comma separated list of fields to sort by. Besides "execution", "created",
+				"updated" and "number" any field of the work item type can be used (e.g. "system.title").
+				A field prefixed with "-" is sorted in descending order (e.g. "-priority,created").
+				Enum fields are sorted by the order of their values.`)
 		})
 		a.UseTrait("conditional")
 		a.Response(d.OK, workItemList)
@@ -252,6 +304,24 @@ var _ = a.Resource("workitems", func() {
 		a.Response(d.Unauthorized, JSONAPIErrors)
 		a.Response(d.Forbidden, JSONAPIErrors)
 	})
+
+	a.Action("bulk-update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/bulk"),
+		)
+		a.Description(`apply the same changes to several work items in a single transaction.
+			Listed work items are checked against the version given for them, work items matched
+			by a filter are updated in their current version. Every work item gets its own status
+			in the response.`)
+		a.Payload(workItemBulkUpdate)
+		a.Response(d.OK, workItemBulkUpdateResults)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
 })
 
 var _ = a.Resource("planner_backlog", func() {
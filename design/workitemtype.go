@@ -12,10 +12,25 @@ var fieldType = a.Type("fieldType", func() {
 	a.Attribute("componentType", d.String, "The kind of type of the individual elements for a list type. Required for list types. Must be a simple type, not  enum or list")
 	a.Attribute("baseType", d.String, "The kind of type of the enumeration values for an enum type. Required for enum types. Must be a simple type, not  enum or list")
 	a.Attribute("values", a.ArrayOf(d.Any), "The possible values for an enum type. The values must be of a type convertible to the base type")
+	a.Attribute("resultType", d.String, "The kind of type of the values of a computed type. Required for computed types. Must be 'integer' or 'float'")
+	a.Attribute("formula", fieldFormula, "How the values of a computed type are derived. Required for computed types")
 
 	a.Required("kind")
 })
 
+// fieldFormula tells how the value of a computed field is derived
+var fieldFormula = a.Type("fieldFormula", func() {
+	a.Description("A fieldFormula derives the value of a computed field from other fields of the work item or of its children")
+	a.Attribute("function", d.String, "How the values are combined", func() {
+		a.Enum("sum", "min", "max", "avg", "count", "days_since")
+	})
+	a.Attribute("fields", a.ArrayOf(d.String), "The fields whose values are combined", func() {
+		a.Example([]string{"system.created_at"})
+	})
+	a.Attribute("children", d.Boolean, "Whether the values of the child work items are aggregated instead of the values of the work item itself")
+	a.Required("function")
+})
+
 // fieldDefinition defines the possible values for a field in a work item type
 var fieldDefinition = a.Type("fieldDefinition", func() {
 	a.Description("A fieldDefinition aggregates a fieldType and additional field metadata")
@@ -148,3 +163,104 @@ var _ = a.Resource("workitemtypes", func() {
 		a.Response(d.InternalServerError, JSONAPIErrors)
 	})
 })
+
+var _ = a.Resource("space_workitemtypes", func() {
+	a.Parent("space")
+	a.BasePath("/template/workitemtypes")
+	a.Description(`Manage the work item types of the space template which belongs to the space.
+A space gets its own template by cloning the template it uses.`)
+
+	a.Action("create", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.POST(""),
+		)
+		a.Description("Create a work item type in the space template of the space. The type extends the planner item unless it names another type to extend.")
+		a.Payload(workItemTypeSingle)
+		a.Response(d.Created, workItemTypeSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("update", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PATCH("/:witID"),
+		)
+		a.Description("Update a work item type of the space template of the space. The fields replace the current ones and are passed on to the types which extend it.")
+		a.Params(func() {
+			a.Param("witID", d.UUID, "ID of the work item type")
+		})
+		a.Payload(workItemTypeSingle)
+		a.Response(d.OK, workItemTypeSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:witID"),
+		)
+		a.Description("Delete a work item type of the space template of the space which neither work items nor other types use")
+		a.Params(func() {
+			a.Param("witID", d.UUID, "ID of the work item type")
+		})
+		a.Response(d.NoContent)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("set-field", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.PUT("/:witID/fields/:fieldName"),
+		)
+		a.Description("Add a field to a work item type of the space template of the space or replace its definition")
+		a.Params(func() {
+			a.Param("witID", d.UUID, "ID of the work item type")
+			a.Param("fieldName", d.String, "Name of the field", func() {
+				a.Example("customer")
+			})
+		})
+		a.Payload(fieldDefinition)
+		a.Response(d.OK, workItemTypeSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+
+	a.Action("delete-field", func() {
+		a.Security("jwt")
+		a.Routing(
+			a.DELETE("/:witID/fields/:fieldName"),
+		)
+		a.Description("Remove a field from a work item type of the space template of the space as long as no work item has a value for it")
+		a.Params(func() {
+			a.Param("witID", d.UUID, "ID of the work item type")
+			a.Param("fieldName", d.String, "Name of the field")
+		})
+		a.Response(d.OK, workItemTypeSingle)
+		a.Response(d.BadRequest, JSONAPIErrors)
+		a.Response(d.NotFound, JSONAPIErrors)
+		a.Response(d.Conflict, JSONAPIErrors)
+		a.Response(d.InternalServerError, JSONAPIErrors)
+		a.Response(d.Unauthorized, JSONAPIErrors)
+		a.Response(d.Forbidden, JSONAPIErrors)
+	})
+})
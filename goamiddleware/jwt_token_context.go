@@ -10,6 +10,7 @@ import (
 	"context"
 
 	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/goadesign/goa"
 	goajwt "github.com/goadesign/goa/middleware/security/jwt"
@@ -36,6 +37,10 @@ func TokenContext(validationKeys interface{}, validationFunc goa.Middleware, sch
 			if val != "" && strings.HasPrefix(strings.ToLower(val), "bearer ") {
 				log.Debug(ctx, nil, "found header 'Authorization: Bearer JWT-token...'")
 				incomingToken := strings.Split(val, " ")[1]
+				if account.IsPersonalAccessToken(incomingToken) {
+					// handled by the PersonalAccessTokenContext middleware
+					return nextHandler(ctx, rw, req)
+				}
 				log.Debug(ctx, nil, "extracted the incoming token %v ", incomingToken)
 
 				var (
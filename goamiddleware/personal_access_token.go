@@ -0,0 +1,81 @@
+package goamiddleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+)
+
+// PersonalAccessTokenAuthenticator looks up the active personal access token
+// with the given plain value
+type PersonalAccessTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*account.PersonalAccessToken, error)
+}
+
+// PersonalAccessTokenContext is a goa middleware which accepts personal access
+// tokens in the Authorization header alongside JWTs. A valid token is stored
+// in the context as an unsigned JWT carrying the identity and the scopes of the
+// token, so that the identity can be located like for any JWT. Unknown,
+// revoked and expired tokens are rejected, and so are requests which would
+// change something with a read-only token.
+func PersonalAccessTokenContext(authenticator PersonalAccessTokenAuthenticator, scheme *goa.JWTSecurity) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if scheme.In != goa.LocHeader {
+				log.Error(ctx, nil, fmt.Sprintf("whoops, security scheme with location (in) %q not supported", scheme.In))
+				return fmt.Errorf("whoops, security scheme with location (in) %q not supported", scheme.In)
+			}
+			val := req.Header.Get(scheme.Name)
+			if val == "" || !strings.HasPrefix(strings.ToLower(val), "bearer ") {
+				return nextHandler(ctx, rw, req)
+			}
+			incomingToken := strings.Split(val, " ")[1]
+			if !account.IsPersonalAccessToken(incomingToken) {
+				return nextHandler(ctx, rw, req)
+			}
+			pat, err := authenticator.Authenticate(ctx, incomingToken)
+			if err != nil {
+				return err
+			}
+			if !pat.HasScope(account.ScopeWorkItemWrite) && !pat.HasScope(account.ScopeSpaceAdmin) && !isSafeMethod(req.Method) {
+				log.Warn(ctx, map[string]interface{}{
+					"token_id":    pat.ID,
+					"identity_id": pat.IdentityID,
+					"method":      req.Method,
+				}, "rejected a request with a read-only personal access token")
+				return errors.NewForbiddenError("the personal access token only allows to read")
+			}
+			log.Debug(ctx, map[string]interface{}{
+				"token_id":    pat.ID,
+				"identity_id": pat.IdentityID,
+			}, "authenticated with a personal access token")
+			return nextHandler(goajwt.WithJWT(ctx, pat.JWT()), rw, req)
+		}
+	}
+}
+
+// JWTOrPersonalAccessToken wraps the given JWT security middleware so that it
+// is skipped for requests which were authenticated with a personal access
+// token by the PersonalAccessTokenContext middleware.
+func JWTOrPersonalAccessToken(jwtMiddleware goa.Middleware) goa.Middleware {
+	return func(nextHandler goa.Handler) goa.Handler {
+		jwtHandler := jwtMiddleware(nextHandler)
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			if _, ok := account.ContextPersonalAccessTokenScopes(ctx); ok {
+				return nextHandler(ctx, rw, req)
+			}
+			return jwtHandler(ctx, rw, req)
+		}
+	}
+}
+
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
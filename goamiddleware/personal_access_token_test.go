@@ -0,0 +1,133 @@
+package goamiddleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	. "github.com/fabric8-services/fabric8-wit/goamiddleware"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/goadesign/goa"
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
+	"github.com/lib/pq"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthenticator knows a single personal access token
+type fakeAuthenticator struct {
+	token string
+	pat   account.PersonalAccessToken
+}
+
+func (a fakeAuthenticator) Authenticate(ctx context.Context, token string) (*account.PersonalAccessToken, error) {
+	if token != a.token {
+		return nil, errors.NewUnauthorizedError("unknown personal access token")
+	}
+	return &a.pat, nil
+}
+
+func TestPersonalAccessTokenContext(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	scheme := &goa.JWTSecurity{In: goa.LocHeader, Name: "Authorization"}
+	newAuthenticator := func(scopes ...string) fakeAuthenticator {
+		return fakeAuthenticator{
+			token: account.PersonalAccessTokenPrefix + "secret",
+			pat: account.PersonalAccessToken{
+				ID:         uuid.NewV4(),
+				IdentityID: uuid.NewV4(),
+				Scopes:     pq.StringArray(scopes),
+			},
+		}
+	}
+	// serve runs the middleware and returns the context that reached the
+	// handler, if any
+	serve := func(authenticator fakeAuthenticator, method, authorization string) (context.Context, error) {
+		var handled context.Context
+		h := PersonalAccessTokenContext(authenticator, scheme)(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			handled = ctx
+			return nil
+		})
+		req := httptest.NewRequest(method, "/api/workitems", nil)
+		if authorization != "" {
+			req.Header.Set("Authorization", authorization)
+		}
+		err := h(context.Background(), httptest.NewRecorder(), req)
+		return handled, err
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		// given
+		authenticator := newAuthenticator(account.ScopeWorkItemWrite)
+		// when
+		ctx, err := serve(authenticator, http.MethodPost, "Bearer "+authenticator.token)
+		// then
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+		token := goajwt.ContextJWT(ctx)
+		require.NotNil(t, token)
+		assert.Equal(t, authenticator.pat.IdentityID.String(), token.Claims.(jwt.MapClaims)["sub"])
+	})
+
+	t.Run("read-only token can read", func(t *testing.T) {
+		authenticator := newAuthenticator(account.ScopeReadOnly)
+		ctx, err := serve(authenticator, http.MethodGet, "Bearer "+authenticator.token)
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+	})
+
+	t.Run("read-only token can't write", func(t *testing.T) {
+		authenticator := newAuthenticator(account.ScopeReadOnly)
+		ctx, err := serve(authenticator, http.MethodPatch, "Bearer "+authenticator.token)
+		require.Error(t, err)
+		assert.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+		assert.Nil(t, ctx)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		authenticator := newAuthenticator(account.ScopeReadOnly)
+		ctx, err := serve(authenticator, http.MethodGet, "Bearer "+account.PersonalAccessTokenPrefix+"foo")
+		require.Error(t, err)
+		assert.IsType(t, errors.UnauthorizedError{}, errs.Cause(err))
+		assert.Nil(t, ctx)
+	})
+
+	t.Run("JWT and missing header are passed on", func(t *testing.T) {
+		authenticator := newAuthenticator(account.ScopeReadOnly)
+		for _, authorization := range []string{"", "Bearer eyJhbGciOiJSUzI1NiJ9.e30.c2lnbmF0dXJl"} {
+			ctx, err := serve(authenticator, http.MethodPost, authorization)
+			require.NoError(t, err)
+			require.NotNil(t, ctx)
+			assert.Nil(t, goajwt.ContextJWT(ctx))
+		}
+	})
+}
+
+func TestJWTOrPersonalAccessToken(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	jwtErr := errors.NewUnauthorizedError("invalid JWT")
+	h := JWTOrPersonalAccessToken(func(h goa.Handler) goa.Handler {
+		return func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+			return jwtErr
+		}
+	})(func(ctx context.Context, rw http.ResponseWriter, req *http.Request) error {
+		return nil
+	})
+	req := httptest.NewRequest(http.MethodGet, "/api/workitems", nil)
+
+	t.Run("personal access token skips the JWT middleware", func(t *testing.T) {
+		pat := account.PersonalAccessToken{ID: uuid.NewV4(), IdentityID: uuid.NewV4(), Scopes: pq.StringArray{account.ScopeReadOnly}}
+		ctx := goajwt.WithJWT(context.Background(), pat.JWT())
+		require.NoError(t, h(ctx, httptest.NewRecorder(), req))
+	})
+
+	t.Run("other requests go through the JWT middleware", func(t *testing.T) {
+		require.Equal(t, jwtErr, h(context.Background(), httptest.NewRecorder(), req))
+	})
+}
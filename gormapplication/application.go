@@ -7,18 +7,27 @@ import (
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/application"
 	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/attachment"
 	"github.com/fabric8-services/fabric8-wit/codebase"
 	"github.com/fabric8-services/fabric8-wit/comment"
 	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
 	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
 	"github.com/fabric8-services/fabric8-wit/query"
+	"github.com/fabric8-services/fabric8-wit/reference"
 	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
 	"github.com/fabric8-services/fabric8-wit/search"
 	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/space/archive"
 	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/importer"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/templatemigration"
+	"github.com/fabric8-services/fabric8-wit/webhook"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/event"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
 )
@@ -88,6 +97,11 @@ func (g *GormBase) TrackerQueries() remoteworkitem.TrackerQueryRepository {
 	return remoteworkitem.NewTrackerQueryRepository(g.db)
 }
 
+// SyncStatuses returns a sync status repository
+func (g *GormBase) SyncStatuses() remoteworkitem.SyncStatusRepository {
+	return remoteworkitem.NewSyncStatusRepository(g.db)
+}
+
 func (g *GormBase) SearchItems() application.SearchRepository {
 	return search.NewGormSearchRepository(g.db)
 }
@@ -122,6 +136,16 @@ func (g *GormBase) Comments() comment.Repository {
 	return comment.NewRepository(g.db)
 }
 
+// CommentRevisions returns a comment revisions repository
+func (g *GormBase) CommentRevisions() comment.RevisionRepository {
+	return comment.NewRevisionRepository(g.db)
+}
+
+// CommentReactions returns a comment reactions repository
+func (g *GormBase) CommentReactions() comment.ReactionRepository {
+	return comment.NewReactionRepository(g.db)
+}
+
 // Iterations returns a iteration repository
 func (g *GormBase) Iterations() iteration.Repository {
 	return iteration.NewIterationRepository(g.db)
@@ -167,6 +191,66 @@ func (g *GormBase) Boards() workitem.BoardRepository {
 	return workitem.NewBoardRepository(g.db)
 }
 
+// Webhooks returns a webhook repository
+func (g *GormBase) Webhooks() webhook.Repository {
+	return webhook.NewRepository(g.db)
+}
+
+// WebhookDeliveries returns a webhook delivery repository
+func (g *GormBase) WebhookDeliveries() webhook.DeliveryRepository {
+	return webhook.NewDeliveryRepository(g.db)
+}
+
+// Reports returns an iteration report repository
+func (g *GormBase) Reports() report.Repository {
+	return report.NewReportRepository(g.db)
+}
+
+// PersonalAccessTokens returns a personal access token repository
+func (g *GormBase) PersonalAccessTokens() account.PersonalAccessTokenRepository {
+	return account.NewPersonalAccessTokenRepository(g.db)
+}
+
+// SpaceArchives returns a space archive repository
+func (g *GormBase) SpaceArchives() archive.Repository {
+	return archive.NewRepository(g.db)
+}
+
+// SpaceTemplateImporter returns a space template importer repository
+func (g *GormBase) SpaceTemplateImporter() importer.Repository {
+	return importer.NewRepository(g.db)
+}
+
+// SpaceTemplateMigrations returns a space template migration repository
+func (g *GormBase) SpaceTemplateMigrations() templatemigration.Repository {
+	return templatemigration.NewRepository(g.db)
+}
+
+// Attachments returns an attachment repository
+func (g *GormBase) Attachments() attachment.Repository {
+	return attachment.NewRepository(g.db)
+}
+
+// MarkupReferences returns a repository of the references in markup
+func (g *GormBase) MarkupReferences() reference.Repository {
+	return reference.NewRepository(g.db)
+}
+
+// Watches returns a repository of the watched work items, iterations and spaces
+func (g *GormBase) Watches() subscription.WatchRepository {
+	return subscription.NewWatchRepository(g.db)
+}
+
+// NotificationPreferences returns a notification preference repository
+func (g *GormBase) NotificationPreferences() subscription.PreferenceRepository {
+	return subscription.NewPreferenceRepository(g.db)
+}
+
+// IterationSchedules returns an iteration schedule repository
+func (g *GormBase) IterationSchedules() schedule.Repository {
+	return schedule.NewRepository(g.db)
+}
+
 func (g *GormBase) DB() *gorm.DB {
 	return g.db
 }
@@ -100,6 +100,7 @@ type Repository interface {
 	Save(ctx context.Context, i Iteration) (*Iteration, error)
 	CanStart(ctx context.Context, i *Iteration) (bool, error)
 	LoadMultiple(ctx context.Context, ids []uuid.UUID) ([]Iteration, error)
+	LockMultiple(ctx context.Context, ids []uuid.UUID) ([]Iteration, error)
 	LoadChildren(ctx context.Context, parentIterationID uuid.UUID) ([]Iteration, error)
 	Delete(ctx context.Context, ID uuid.UUID) error
 }
@@ -131,6 +132,26 @@ func (m *GormIterationRepository) LoadMultiple(ctx context.Context, ids []uuid.U
 	return objs, nil
 }
 
+// LockMultiple loads the iterations with the given IDs and locks them until
+// the end of the surrounding transaction. Work items which are moved into a
+// locked iteration wait for the lock (see workitem.GormWorkItemRepository).
+func (m *GormIterationRepository) LockMultiple(ctx context.Context, ids []uuid.UUID) ([]Iteration, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration", "lockmultiple"}, time.Now())
+	var objs []Iteration
+	if len(ids) == 0 {
+		return objs, nil
+	}
+	err := m.db.Set("gorm:query_option", "FOR UPDATE").Where("id IN (?)", ids).Order("id").Find(&objs).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"iteration_ids": ids,
+			"err":           err,
+		}, "unable to lock the iterations")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
 // Create creates a new record.
 func (m *GormIterationRepository) Create(ctx context.Context, u *Iteration) error {
 	defer goa.MeasureSince([]string{"goa", "db", "iteration", "create"}, time.Now())
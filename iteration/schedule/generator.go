@@ -0,0 +1,163 @@
+package schedule
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Generate creates the iterations of the given schedule which start before
+// the end of its lookahead window. Iterations which already ended are
+// skipped, as are names which already exist below the parent iteration, so
+// running it again doesn't create any duplicates. A schedule whose parent
+// iteration was deleted is deactivated. The schedule is locked until the end
+// of the surrounding transaction.
+func Generate(ctx context.Context, schedules Repository, iterations iteration.Repository, id uuid.UUID, now time.Time) ([]iteration.Iteration, error) {
+	s, err := schedules.LoadForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Active {
+		return nil, nil
+	}
+	parent, err := iterations.Load(ctx, s.ParentIterationID)
+	if notFound, _ := errors.IsNotFoundError(err); notFound {
+		log.Warn(ctx, map[string]interface{}{
+			"schedule_id":  s.ID,
+			"iteration_id": s.ParentIterationID,
+		}, "the parent iteration of the schedule no longer exists, the schedule is deactivated")
+		s.Active = false
+		if _, err := schedules.Save(ctx, *s); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	horizon := now.AddDate(0, 0, s.LookaheadDays)
+	var created []iteration.Iteration
+	sequence := s.NextSequence
+	for ; ; sequence++ {
+		start, end := s.Occurrence(sequence)
+		if start.After(horizon) {
+			break
+		}
+		if !end.After(now) {
+			continue
+		}
+		itr := iteration.Iteration{
+			SpaceID: s.SpaceID,
+			Name:    s.Name(sequence),
+			StartAt: &start,
+			EndAt:   &end,
+		}
+		itr.MakeChildOf(*parent)
+		exists, err := schedules.IterationExists(ctx, itr.SpaceID, itr.Path, itr.Name)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			continue
+		}
+		if err := iterations.Create(ctx, &itr); err != nil {
+			return nil, err
+		}
+		created = append(created, itr)
+	}
+	if sequence != s.NextSequence {
+		if err := schedules.Advance(ctx, s.ID, sequence); err != nil {
+			return nil, err
+		}
+	}
+	return created, nil
+}
+
+// GeneratorConfiguration holds the settings of the Generator
+type GeneratorConfiguration interface {
+	GetIterationScheduleInterval() time.Duration
+}
+
+// Generator periodically creates the upcoming iterations of all active
+// schedules
+type Generator struct {
+	db     *gorm.DB
+	config GeneratorConfiguration
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewGenerator creates a new Generator. The configured interval must be
+// positive.
+func NewGenerator(db *gorm.DB, config GeneratorConfiguration) (*Generator, error) {
+	if interval := config.GetIterationScheduleInterval(); interval <= 0 {
+		return nil, errs.Errorf("the interval of the iteration schedule generator must be positive: %s", interval)
+	}
+	return &Generator{
+		db:     db,
+		config: config,
+		stop:   make(chan struct{}),
+	}, nil
+}
+
+// Start runs the generation loop in the background until Stop is called
+func (g *Generator) Start(ctx context.Context) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		ticker := time.NewTicker(g.config.GetIterationScheduleInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-g.stop:
+				return
+			case <-ticker.C:
+				if _, err := g.GenerateDue(ctx, time.Now()); err != nil {
+					log.Error(ctx, map[string]interface{}{
+						"err": err,
+					}, "failed to generate scheduled iterations")
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the generation loop and waits for the current run to
+// finish. This should be called only from main
+func (g *Generator) Stop() {
+	close(g.stop)
+	g.wg.Wait()
+}
+
+// GenerateDue creates the upcoming iterations of all active schedules and
+// returns how many iterations were created. Each schedule is handled in its
+// own transaction so that a failing schedule doesn't hold back the others.
+func (g *Generator) GenerateDue(ctx context.Context, now time.Time) (int, error) {
+	ids, err := NewRepository(g.db).ListActive(ctx)
+	if err != nil {
+		return 0, err
+	}
+	generated := 0
+	for _, id := range ids {
+		err := models.Transactional(g.db, func(tx *gorm.DB) error {
+			created, err := Generate(ctx, NewRepository(tx), iteration.NewIterationRepository(tx), id, now)
+			generated += len(created)
+			return err
+		})
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"schedule_id": id,
+				"err":         err,
+			}, "unable to generate the iterations of the schedule")
+		}
+	}
+	return generated, nil
+}
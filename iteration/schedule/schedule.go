@@ -0,0 +1,307 @@
+package schedule
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/path"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeIterationSchedules helps to avoid string literal
+const APIStringTypeIterationSchedules = "iterationschedules"
+
+// The placeholders of a name pattern
+const (
+	// PlaceholderNumber is replaced with the number of the iteration in the
+	// schedule, starting with 1
+	PlaceholderNumber = "{n}"
+	// PlaceholderStart is replaced with the start date of the iteration
+	PlaceholderStart = "{start}"
+	// PlaceholderEnd is replaced with the end date of the iteration
+	PlaceholderEnd = "{end}"
+)
+
+// MaxLookaheadDays is the longest time in advance for which iterations are
+// generated
+const MaxLookaheadDays = 366
+
+// dateLayout is the format of the dates in the names of the iterations
+const dateLayout = "2006-01-02"
+
+// Schedule describes recurring iterations which are generated below a parent
+// iteration, e.g. two-week sprints named "Sprint {n}"
+type Schedule struct {
+	gormsupport.Lifecycle
+	ID                uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID           uuid.UUID `sql:"type:uuid"`
+	ParentIterationID uuid.UUID `sql:"type:uuid"`
+	// StartAt is the start of the first iteration
+	StartAt time.Time
+	// CadenceDays is the number of days between the starts of two iterations
+	CadenceDays int
+	// LengthDays is the number of days an iteration lasts
+	LengthDays int
+	// NamePattern is the name of the iterations with placeholders for their
+	// number and dates
+	NamePattern string
+	// LookaheadDays tells how many days in advance iterations are generated
+	LookaheadDays int
+	Active        bool
+	// NextSequence is the number of the next iteration to generate
+	NextSequence int
+	Version      int
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (m Schedule) GetETagData() []interface{} {
+	return []interface{}{m.ID, m.Version}
+}
+
+// GetLastModified returns the last modification time
+func (m Schedule) GetLastModified() time.Time {
+	return m.UpdatedAt.Truncate(time.Second)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Schedule) TableName() string {
+	return "iteration_schedules"
+}
+
+// Validate checks that the schedule generates iterations with distinct names
+// at a sensible pace.
+func (m Schedule) Validate() error {
+	if m.StartAt.IsZero() {
+		return errors.NewBadParameterError("start-at", m.StartAt).Expected("not zero")
+	}
+	if m.CadenceDays < 1 {
+		return errors.NewBadParameterError("cadence-days", m.CadenceDays).Expected("at least 1")
+	}
+	if m.LengthDays < 1 {
+		return errors.NewBadParameterError("length-days", m.LengthDays).Expected("at least 1")
+	}
+	if m.LookaheadDays < 0 || m.LookaheadDays > MaxLookaheadDays {
+		return errors.NewBadParameterError("lookahead-days", m.LookaheadDays).Expected("between 0 and " + strconv.Itoa(MaxLookaheadDays))
+	}
+	if strings.TrimSpace(m.NamePattern) == "" {
+		return errors.NewBadParameterError("name-pattern", m.NamePattern).Expected("non empty string")
+	}
+	if !strings.Contains(m.NamePattern, PlaceholderNumber) && !strings.Contains(m.NamePattern, PlaceholderStart) {
+		return errors.NewBadParameterError("name-pattern", m.NamePattern).Expected("pattern containing " + PlaceholderNumber + " or " + PlaceholderStart)
+	}
+	return nil
+}
+
+// Occurrence returns the start and end of the iteration with the given number
+func (m Schedule) Occurrence(sequence int) (time.Time, time.Time) {
+	start := m.StartAt.AddDate(0, 0, (sequence-1)*m.CadenceDays)
+	return start, start.AddDate(0, 0, m.LengthDays)
+}
+
+// Name returns the name of the iteration with the given number
+func (m Schedule) Name(sequence int) string {
+	start, end := m.Occurrence(sequence)
+	return strings.NewReplacer(
+		PlaceholderNumber, strconv.Itoa(sequence),
+		PlaceholderStart, start.Format(dateLayout),
+		PlaceholderEnd, end.Format(dateLayout),
+	).Replace(m.NamePattern)
+}
+
+// Repository describes interactions with iteration schedules
+type Repository interface {
+	Create(ctx context.Context, s *Schedule) error
+	Load(ctx context.Context, id uuid.UUID) (*Schedule, error)
+	// LoadForUpdate loads the schedule and locks it until the end of the
+	// transaction, so that its iterations are only generated once
+	LoadForUpdate(ctx context.Context, id uuid.UUID) (*Schedule, error)
+	List(ctx context.Context, spaceID uuid.UUID) ([]Schedule, error)
+	// ListActive returns the IDs of all active schedules
+	ListActive(ctx context.Context) ([]uuid.UUID, error)
+	Save(ctx context.Context, s Schedule) (*Schedule, error)
+	// Advance stores the number of the next iteration to generate without
+	// changing the version of the schedule
+	Advance(ctx context.Context, id uuid.UUID, nextSequence int) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// IterationExists tells whether an iteration with the given name exists
+	// at the given path, including deleted iterations
+	IterationExists(ctx context.Context, spaceID uuid.UUID, p path.Path, name string) (bool, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for iteration
+// schedules.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create a new iteration schedule
+func (r *GormRepository) Create(ctx context.Context, s *Schedule) error {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "create"}, time.Now())
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	var count int
+	if err := r.db.Model(&Schedule{}).Where("parent_iteration_id = ?", s.ParentIterationID).Count(&count).Error; err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	if count > 0 {
+		return errors.NewDataConflictError("the iteration " + s.ParentIterationID.String() + " already has a schedule")
+	}
+	s.ID = uuid.NewV4()
+	if s.NextSequence < 1 {
+		s.NextSequence = 1
+	}
+	if err := r.db.Create(s).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id":     s.SpaceID,
+			"iteration_id": s.ParentIterationID,
+			"err":          err,
+		}, "unable to create the iteration schedule")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Load returns the iteration schedule with the given ID
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "show"}, time.Now())
+	return r.load(ctx, r.db, id)
+}
+
+// LoadForUpdate returns the iteration schedule with the given ID and locks it
+// until the end of the transaction
+func (r *GormRepository) LoadForUpdate(ctx context.Context, id uuid.UUID) (*Schedule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "load_for_update"}, time.Now())
+	return r.load(ctx, r.db.Set("gorm:query_option", "FOR UPDATE"), id)
+}
+
+func (r *GormRepository) load(ctx context.Context, db *gorm.DB, id uuid.UUID) (*Schedule, error) {
+	s := Schedule{}
+	tx := db.Where("id = ?", id).First(&s)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("iteration schedule", id.String())
+	}
+	if tx.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"schedule_id": id,
+			"err":         tx.Error,
+		}, "unable to load the iteration schedule by ID")
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	return &s, nil
+}
+
+// List returns all iteration schedules of a space
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]Schedule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "list"}, time.Now())
+	var objs []Schedule
+	err := r.db.Where("space_id = ?", spaceID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// ListActive returns the IDs of all active iteration schedules
+func (r *GormRepository) ListActive(ctx context.Context) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "listactive"}, time.Now())
+	var ids []uuid.UUID
+	err := r.db.Model(&Schedule{}).Where("active").Order("created_at").Pluck("id", &ids).Error
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return ids, nil
+}
+
+// Save updates the given iteration schedule
+func (r *GormRepository) Save(ctx context.Context, s Schedule) (*Schedule, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "save"}, time.Now())
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+	existing := Schedule{}
+	tx := r.db.Where("id = ?", s.ID).First(&existing)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("iteration schedule", s.ID.String())
+	}
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	oldVersion := s.Version
+	s.Version = existing.Version + 1
+	tx = r.db.Where("version = ?", oldVersion).Save(&s)
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"schedule_id": s.ID,
+			"err":         err,
+		}, "unable to save the iteration schedule")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &s, nil
+}
+
+// Advance stores the number of the next iteration to generate. The version is
+// left alone so that clients editing the schedule don't run into conflicts
+// because iterations were generated in the meantime.
+func (r *GormRepository) Advance(ctx context.Context, id uuid.UUID, nextSequence int) error {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "advance"}, time.Now())
+	tx := r.db.Model(&Schedule{}).Where("id = ?", id).UpdateColumn("next_sequence", nextSequence)
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"schedule_id": id,
+			"err":         err,
+		}, "unable to advance the iteration schedule")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("iteration schedule", id.String())
+	}
+	return nil
+}
+
+// Delete removes the iteration schedule with the given ID. The iterations it
+// generated are kept.
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "delete"}, time.Now())
+	tx := r.db.Delete(Schedule{ID: id})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"schedule_id": id,
+			"err":         err,
+		}, "unable to delete the iteration schedule")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("iteration schedule", id.String())
+	}
+	return nil
+}
+
+// IterationExists tells whether an iteration with the given name exists at the
+// given path. Deleted iterations count as well as their names stay reserved.
+func (r *GormRepository) IterationExists(ctx context.Context, spaceID uuid.UUID, p path.Path, name string) (bool, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "iteration_schedule", "iteration_exists"}, time.Now())
+	var count int
+	err := r.db.Unscoped().Model(&iteration.Iteration{}).Where("space_id = ? AND path = ? AND name = ?", spaceID, p, name).Count(&count).Error
+	if err != nil {
+		return false, errors.NewInternalError(ctx, err)
+	}
+	return count > 0, nil
+}
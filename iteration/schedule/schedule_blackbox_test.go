@@ -0,0 +1,286 @@
+package schedule_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestName(t *testing.T) {
+	s := schedule.Schedule{
+		StartAt:     time.Date(2018, 1, 1, 9, 0, 0, 0, time.UTC),
+		CadenceDays: 14,
+		LengthDays:  10,
+		NamePattern: "Sprint {n} ({start} - {end})",
+	}
+	start, end := s.Occurrence(2)
+	assert.Equal(t, time.Date(2018, 1, 15, 9, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2018, 1, 25, 9, 0, 0, 0, time.UTC), end)
+	assert.Equal(t, "Sprint 1 (2018-01-01 - 2018-01-11)", s.Name(1))
+	assert.Equal(t, "Sprint 2 (2018-01-15 - 2018-01-25)", s.Name(2))
+}
+
+func TestValidate(t *testing.T) {
+	valid := schedule.Schedule{
+		StartAt:       time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		CadenceDays:   14,
+		LengthDays:    14,
+		NamePattern:   "Sprint {n}",
+		LookaheadDays: 30,
+	}
+	require.NoError(t, valid.Validate())
+	for name, fn := range map[string]func(s *schedule.Schedule){
+		"no start":           func(s *schedule.Schedule) { s.StartAt = time.Time{} },
+		"no cadence":         func(s *schedule.Schedule) { s.CadenceDays = 0 },
+		"no length":          func(s *schedule.Schedule) { s.LengthDays = 0 },
+		"negative lookahead": func(s *schedule.Schedule) { s.LookaheadDays = -1 },
+		"too long lookahead": func(s *schedule.Schedule) { s.LookaheadDays = schedule.MaxLookaheadDays + 1 },
+		"empty pattern":      func(s *schedule.Schedule) { s.NamePattern = " " },
+		"constant pattern":   func(s *schedule.Schedule) { s.NamePattern = "Sprint" },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := valid
+			fn(&s)
+			require.IsType(t, errors.BadParameterError{}, s.Validate())
+		})
+	}
+}
+
+type scheduleSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSchedule(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &scheduleSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func newSchedule(fxt *tf.TestFixture) *schedule.Schedule {
+	return &schedule.Schedule{
+		SpaceID:           fxt.Spaces[0].ID,
+		ParentIterationID: fxt.Iterations[0].ID,
+		StartAt:           time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC),
+		CadenceDays:       14,
+		LengthDays:        14,
+		NamePattern:       "Sprint {n}",
+		LookaheadDays:     30,
+		Active:            true,
+	}
+}
+
+func (s *scheduleSuite) TestRepository() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Iterations(1))
+	repo := schedule.NewRepository(s.DB)
+	sched := newSchedule(fxt)
+
+	s.T().Run("create", func(t *testing.T) {
+		require.NoError(t, repo.Create(s.Ctx, sched))
+		assert.NotEqual(t, uuid.Nil, sched.ID)
+		assert.Equal(t, 1, sched.NextSequence)
+		// a parent iteration only has one schedule
+		err := repo.Create(s.Ctx, newSchedule(fxt))
+		require.IsType(t, errors.DataConflictError{}, err)
+	})
+
+	s.T().Run("load and list", func(t *testing.T) {
+		loaded, err := repo.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sched.NamePattern, loaded.NamePattern)
+		list, err := repo.List(s.Ctx, fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, sched.ID, list[0].ID)
+		_, err = repo.Load(s.Ctx, uuid.NewV4())
+		require.IsType(t, errors.NotFoundError{}, err)
+	})
+
+	s.T().Run("save", func(t *testing.T) {
+		// given
+		toSave := *sched
+		toSave.LookaheadDays = 60
+		// when
+		saved, err := repo.Save(s.Ctx, toSave)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, sched.Version+1, saved.Version)
+		assert.Equal(t, 60, saved.LookaheadDays)
+		// the outdated version is rejected
+		_, err = repo.Save(s.Ctx, toSave)
+		require.IsType(t, errors.VersionConflictError{}, err)
+	})
+
+	s.T().Run("advance keeps the version", func(t *testing.T) {
+		before, err := repo.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		require.NoError(t, repo.Advance(s.Ctx, sched.ID, 4))
+		after, err := repo.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, after.NextSequence)
+		assert.Equal(t, before.Version, after.Version)
+	})
+
+	s.T().Run("list active", func(t *testing.T) {
+		ids, err := repo.ListActive(s.Ctx)
+		require.NoError(t, err)
+		assert.Contains(t, ids, sched.ID)
+	})
+
+	s.T().Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(s.Ctx, sched.ID))
+		_, err := repo.Load(s.Ctx, sched.ID)
+		require.IsType(t, errors.NotFoundError{}, err)
+		err = repo.Delete(s.Ctx, sched.ID)
+		require.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *scheduleSuite) TestGenerate() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Iterations(1))
+	schedules := schedule.NewRepository(s.DB)
+	iterations := iteration.NewIterationRepository(s.DB)
+	parent := fxt.Iterations[0]
+	sched := newSchedule(fxt)
+	require.NoError(s.T(), schedules.Create(s.Ctx, sched))
+	children := func(t *testing.T) []string {
+		itrs, err := iterations.LoadChildren(s.Ctx, parent.ID)
+		require.NoError(t, err)
+		names := []string{}
+		for _, itr := range itrs {
+			names = append(names, itr.Name)
+		}
+		return names
+	}
+
+	s.T().Run("within the lookahead", func(t *testing.T) {
+		// when
+		created, err := schedule.Generate(s.Ctx, schedules, iterations, sched.ID, time.Date(2018, 1, 21, 0, 0, 0, 0, time.UTC))
+		// then the first sprint already ended and the fifth starts after
+		// the lookahead window
+		require.NoError(t, err)
+		require.Len(t, created, 3)
+		assert.Equal(t, "Sprint 2", created[0].Name)
+		assert.Equal(t, time.Date(2018, 1, 15, 0, 0, 0, 0, time.UTC), created[0].StartAt.UTC())
+		assert.Equal(t, time.Date(2018, 1, 29, 0, 0, 0, 0, time.UTC), created[0].EndAt.UTC())
+		assert.Equal(t, parent.ID, created[0].Path.This())
+		assert.ElementsMatch(t, []string{"Sprint 2", "Sprint 3", "Sprint 4"}, children(t))
+		loaded, err := schedules.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 5, loaded.NextSequence)
+	})
+
+	s.T().Run("idempotent", func(t *testing.T) {
+		created, err := schedule.Generate(s.Ctx, schedules, iterations, sched.ID, time.Date(2018, 1, 21, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Empty(t, created)
+		assert.Len(t, children(t), 3)
+	})
+
+	s.T().Run("existing names are skipped", func(t *testing.T) {
+		// given
+		existing := iteration.Iteration{SpaceID: parent.SpaceID, Name: "Sprint 5"}
+		existing.MakeChildOf(*parent)
+		require.NoError(t, iterations.Create(s.Ctx, &existing))
+		// when
+		created, err := schedule.Generate(s.Ctx, schedules, iterations, sched.ID, time.Date(2018, 2, 4, 0, 0, 0, 0, time.UTC))
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, created)
+		assert.ElementsMatch(t, []string{"Sprint 2", "Sprint 3", "Sprint 4", "Sprint 5"}, children(t))
+		loaded, err := schedules.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 6, loaded.NextSequence)
+	})
+
+	s.T().Run("inactive", func(t *testing.T) {
+		// given
+		loaded, err := schedules.Load(s.Ctx, sched.ID)
+		require.NoError(t, err)
+		loaded.Active = false
+		_, err = schedules.Save(s.Ctx, *loaded)
+		require.NoError(t, err)
+		// when
+		created, err := schedule.Generate(s.Ctx, schedules, iterations, sched.ID, time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC))
+		// then
+		require.NoError(t, err)
+		assert.Empty(t, created)
+		assert.Len(t, children(t), 4)
+	})
+}
+
+func (s *scheduleSuite) TestGenerateDeletedParent() {
+	// given a schedule whose parent iteration was deleted
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Iterations(2, func(fxt *tf.TestFixture, idx int) error {
+		if idx > 0 {
+			fxt.Iterations[idx].MakeChildOf(*fxt.Iterations[0])
+		}
+		return nil
+	}))
+	schedules := schedule.NewRepository(s.DB)
+	iterations := iteration.NewIterationRepository(s.DB)
+	sched := newSchedule(fxt)
+	sched.ParentIterationID = fxt.Iterations[1].ID
+	require.NoError(s.T(), schedules.Create(s.Ctx, sched))
+	require.NoError(s.T(), iterations.Delete(s.Ctx, fxt.Iterations[1].ID))
+	// when
+	created, err := schedule.Generate(s.Ctx, schedules, iterations, sched.ID, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	// then
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), created)
+	loaded, err := schedules.Load(s.Ctx, sched.ID)
+	require.NoError(s.T(), err)
+	assert.False(s.T(), loaded.Active)
+	ids, err := schedules.ListActive(s.Ctx)
+	require.NoError(s.T(), err)
+	assert.NotContains(s.T(), ids, sched.ID)
+}
+
+// generatorConfig is the configuration of the generator in tests
+type generatorConfig struct {
+	interval time.Duration
+}
+
+func (c generatorConfig) GetIterationScheduleInterval() time.Duration {
+	return c.interval
+}
+
+func TestNewGenerator(t *testing.T) {
+	_, err := schedule.NewGenerator(nil, generatorConfig{interval: time.Hour})
+	require.NoError(t, err)
+	_, err = schedule.NewGenerator(nil, generatorConfig{})
+	require.Error(t, err)
+	_, err = schedule.NewGenerator(nil, generatorConfig{interval: -time.Hour})
+	require.Error(t, err)
+}
+
+func (s *scheduleSuite) TestGenerateDue() {
+	// given
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Iterations(1))
+	schedules := schedule.NewRepository(s.DB)
+	sched := newSchedule(fxt)
+	sched.NamePattern = "{start}"
+	require.NoError(s.T(), schedules.Create(s.Ctx, sched))
+	generator, err := schedule.NewGenerator(s.DB, generatorConfig{interval: time.Hour})
+	require.NoError(s.T(), err)
+	// when
+	generated, err := generator.GenerateDue(s.Ctx, time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC))
+	// then
+	require.NoError(s.T(), err)
+	assert.True(s.T(), generated >= 3)
+	children, err := iteration.NewIterationRepository(s.DB).LoadChildren(s.Ctx, fxt.Iterations[0].ID)
+	require.NoError(s.T(), err)
+	names := []string{}
+	for _, itr := range children {
+		names = append(names, itr.Name)
+	}
+	assert.ElementsMatch(s.T(), []string{"2018-01-01", "2018-01-15", "2018-01-29"}, names)
+}
@@ -14,12 +14,14 @@ import (
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/app"
 	"github.com/fabric8-services/fabric8-wit/application"
+	"github.com/fabric8-services/fabric8-wit/attachment"
 	"github.com/fabric8-services/fabric8-wit/auth"
 	"github.com/fabric8-services/fabric8-wit/closeable"
 	"github.com/fabric8-services/fabric8-wit/configuration"
 	"github.com/fabric8-services/fabric8-wit/controller"
 	witmiddleware "github.com/fabric8-services/fabric8-wit/goamiddleware"
 	"github.com/fabric8-services/fabric8-wit/gormapplication"
+	"github.com/fabric8-services/fabric8-wit/iteration/schedule"
 	"github.com/fabric8-services/fabric8-wit/jsonapi"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/login"
@@ -33,6 +35,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/space/authz"
 	"github.com/fabric8-services/fabric8-wit/swagger"
 	"github.com/fabric8-services/fabric8-wit/token"
+	"github.com/fabric8-services/fabric8-wit/webhook"
 	"github.com/goadesign/goa"
 	"github.com/goadesign/goa/logging/logrus"
 	"github.com/goadesign/goa/middleware"
@@ -182,11 +185,41 @@ func main() {
 				"url": config.GetNotificationServiceURL(),
 			}, "failed to parse notification service url")
 		}
-		notificationChannel = channel
+		// The notification service only gets the users who want to be notified
+		notificationChannel = notification.NewRecipientChannel(db, channel)
 	}
+	// Events are also delivered to the webhooks of the space in which they happen
+	webhookChannel := notification.NewWebhookChannel(db)
+	defer webhookChannel.Wait()
+	notificationChannel = notification.NewMultiChannel(notificationChannel, webhookChannel)
+	webhookDispatcher := webhook.NewDispatcher(db, config)
+	webhookDispatcher.Start(service.Context)
+	defer webhookDispatcher.Stop()
+	// Upcoming iterations of the iteration schedules are generated in the background
+	iterationScheduleGenerator, err := schedule.NewGenerator(db, config)
+	if err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"interval": config.GetIterationScheduleInterval(),
+			"err":      err,
+		}, "failed to create the iteration schedule generator")
+	}
+	iterationScheduleGenerator.Start(service.Context)
+	defer iterationScheduleGenerator.Stop()
 
 	appDB := gormapplication.NewGormDB(db)
 
+	attachmentStore, err := attachment.NewFileStore(config.GetAttachmentsStoreDir())
+	if err != nil {
+		log.Panic(nil, map[string]interface{}{
+			"dir": config.GetAttachmentsStoreDir(),
+			"err": err,
+		}, "failed to create the attachment store")
+	}
+	// Content of deleted attachments is removed from the store in the background
+	attachmentCleaner := attachment.NewCleaner(db, attachmentStore, config)
+	attachmentCleaner.Start(service.Context)
+	defer attachmentCleaner.Stop()
+
 	tokenManager, err := token.NewManager(config)
 	if err != nil {
 		log.Panic(nil, map[string]interface{}{
@@ -196,12 +229,16 @@ func main() {
 	// Middleware that extracts and stores the token in the context
 	jwtMiddlewareTokenContext := witmiddleware.TokenContext(tokenManager.PublicKeys(), nil, app.NewJWTSecurity())
 	service.Use(jwtMiddlewareTokenContext)
+	// Middleware that accepts personal access tokens alongside the JWTs
+	service.Use(witmiddleware.PersonalAccessTokenContext(account.NewPersonalAccessTokenRepository(db), app.NewJWTSecurity()))
 
 	service.Use(login.InjectTokenManager(tokenManager))
 	service.Use(log.LogRequest(config.IsPostgresDeveloperModeEnabled()))
-	app.UseJWTMiddleware(service, goajwt.New(tokenManager.PublicKeys(), nil, app.NewJWTSecurity()))
+	app.UseJWTMiddleware(service, witmiddleware.JWTOrPersonalAccessToken(goajwt.New(tokenManager.PublicKeys(), nil, app.NewJWTSecurity())))
 
 	spaceAuthzService := authz.NewAuthzService(config)
+	// Requests made with personal access tokens get their roles from the database
+	spaceAuthzService.Local = authz.NewLocalRoleResolver(db)
 	service.Use(authz.InjectAuthzService(spaceAuthzService))
 
 	service.Use(metric.Recorder())
@@ -269,6 +306,18 @@ func main() {
 	commentsCtrl := controller.NewNotifyingCommentsController(service, appDB, notificationChannel, config)
 	app.MountCommentsController(service, commentsCtrl)
 
+	// Mount "comment replies" controller
+	commentRepliesCtrl := controller.NewNotifyingCommentRepliesController(service, appDB, notificationChannel)
+	app.MountCommentRepliesController(service, commentRepliesCtrl)
+
+	// Mount "comment reactions" controller
+	commentReactionsCtrl := controller.NewCommentReactionsController(service, appDB)
+	app.MountCommentReactionsController(service, commentReactionsCtrl)
+
+	// Mount "comment revisions" controller
+	commentRevisionsCtrl := controller.NewCommentRevisionsController(service, appDB)
+	app.MountCommentRevisionsController(service, commentRevisionsCtrl)
+
 	// Mount "work item labels relationships" controller
 	workItemLabelCtrl := controller.NewWorkItemLabelsController(service, appDB, config)
 	app.MountWorkItemLabelsController(service, workItemLabelCtrl)
@@ -277,6 +326,44 @@ func main() {
 	workItemEventsCtrl := controller.NewEventsController(service, appDB, config)
 	app.MountWorkItemEventsController(service, workItemEventsCtrl)
 
+	// Mount "work item history" controller
+	workItemHistoryCtrl := controller.NewNotifyingWorkItemHistoryController(service, appDB, notificationChannel, config)
+	app.MountWorkItemHistoryController(service, workItemHistoryCtrl)
+
+	// Mount "work item graph" controller
+	workItemGraphCtrl := controller.NewWorkItemGraphController(service, appDB)
+	app.MountWorkItemGraphController(service, workItemGraphCtrl)
+
+	// Mount "work item transitions" controller
+	workItemTransitionsCtrl := controller.NewWorkItemTransitionsController(service, appDB)
+	app.MountWorkItemTransitionsController(service, workItemTransitionsCtrl)
+
+	// Mount "work item sync" controller
+	workItemSyncCtrl := controller.NewWorkItemSyncController(service, appDB)
+	app.MountWorkItemSyncController(service, workItemSyncCtrl)
+
+	// Mount "iteration_report" and "space_report" controllers
+	iterationReportCtrl := controller.NewIterationReportController(service, appDB)
+	app.MountIterationReportController(service, iterationReportCtrl)
+	spaceReportCtrl := controller.NewSpaceReportController(service, appDB)
+	app.MountSpaceReportController(service, spaceReportCtrl)
+
+	// Mount "space webhooks" controller
+	spaceWebhooksCtrl := controller.NewSpaceWebhooksController(service, appDB, config)
+	app.MountSpaceWebhooksController(service, spaceWebhooksCtrl)
+
+	// Mount "webhook" controller
+	webhookCtrl := controller.NewWebhookController(service, appDB, config)
+	app.MountWebhookController(service, webhookCtrl)
+
+	// Mount "space iteration schedules" controller
+	spaceIterationSchedulesCtrl := controller.NewSpaceIterationSchedulesController(service, appDB)
+	app.MountSpaceIterationSchedulesController(service, spaceIterationSchedulesCtrl)
+
+	// Mount "iteration schedule" controller
+	iterationScheduleCtrl := controller.NewIterationScheduleController(service, appDB)
+	app.MountIterationScheduleController(service, iterationScheduleCtrl)
+
 	if config.GetFeatureWorkitemRemote() {
 		// Scheduler to fetch and import remote tracker items
 		scheduler = remoteworkitem.NewScheduler(db)
@@ -398,6 +485,50 @@ func main() {
 	workItemBoardsCtrl := controller.NewWorkItemBoardsController(service, appDB)
 	app.MountWorkItemBoardsController(service, workItemBoardsCtrl)
 
+	// Mount "space_board_state" controller with "list" action
+	spaceBoardStateCtrl := controller.NewSpaceBoardStateController(service, appDB)
+	app.MountSpaceBoardStateController(service, spaceBoardStateCtrl)
+
+	// Mount "personal_access_tokens" controller
+	personalAccessTokensCtrl := controller.NewPersonalAccessTokensController(service, appDB)
+	app.MountPersonalAccessTokensController(service, personalAccessTokensCtrl)
+
+	// Mount "watches" controller
+	watchesCtrl := controller.NewWatchesController(service, appDB)
+	app.MountWatchesController(service, watchesCtrl)
+
+	// Mount "notification_preferences" controller
+	notificationPreferencesCtrl := controller.NewNotificationPreferencesController(service, appDB)
+	app.MountNotificationPreferencesController(service, notificationPreferencesCtrl)
+
+	// Mount "service_accounts" controller
+	serviceAccountsCtrl := controller.NewServiceAccountsController(service, appDB)
+	app.MountServiceAccountsController(service, serviceAccountsCtrl)
+
+	// Mount "space_archive" controller
+	spaceArchiveCtrl := controller.NewSpaceArchiveController(service, appDB, config)
+	app.MountSpaceArchiveController(service, spaceArchiveCtrl)
+
+	// Mount "space_template_migration" controller
+	spaceTemplateMigrationCtrl := controller.NewSpaceTemplateMigrationController(service, appDB)
+	app.MountSpaceTemplateMigrationController(service, spaceTemplateMigrationCtrl)
+
+	// Mount "space_workitemtypes" controller
+	spaceWorkitemtypesCtrl := controller.NewSpaceWorkitemtypesController(service, appDB)
+	app.MountSpaceWorkitemtypesController(service, spaceWorkitemtypesCtrl)
+
+	// Mount "work_item_attachments" controller
+	workItemAttachmentsCtrl := controller.NewWorkItemAttachmentsController(service, appDB, attachmentStore, config)
+	app.MountWorkItemAttachmentsController(service, workItemAttachmentsCtrl)
+
+	// Mount "attachment" controller
+	attachmentCtrl := controller.NewAttachmentController(service, appDB, attachmentStore)
+	app.MountAttachmentController(service, attachmentCtrl)
+
+	// Mount "work_item_backlinks" controller
+	workItemBacklinksCtrl := controller.NewWorkItemBacklinksController(service, appDB)
+	app.MountWorkItemBacklinksController(service, workItemBacklinksCtrl)
+
 	// Mount "queries" controller
 	queriesCtrl := controller.NewQueryController(service, appDB, config)
 	app.MountQueryController(service, queriesCtrl)
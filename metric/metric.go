@@ -45,6 +45,30 @@ var (
 		Help:      "Bucketed histogram of the HTTP request sizes in bytes.",
 		Buckets:   []float64{1000, 5000, 10000, 20000, 30000, 40000, 50000},
 	}, reqLabels)
+
+	fetchLabels = []string{"provider"}
+
+	fetchItemsCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "remote_fetch_items_total",
+		Help:      "Counter of items fetched from remote trackers.",
+	}, fetchLabels)
+
+	fetchErrorsCnt = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "remote_fetch_errors_total",
+		Help:      "Counter of errors while fetching and importing items from remote trackers.",
+	}, fetchLabels)
+
+	fetchDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: subsystem,
+		Name:      "remote_fetch_duration_seconds",
+		Help:      "Bucketed histogram of the duration (s) of fetches from remote trackers.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 10),
+	}, fetchLabels)
 )
 
 func registerMetrics() {
@@ -52,6 +76,9 @@ func registerMetrics() {
 	reqDuration = register(reqDuration, "request_duration_seconds").(*prometheus.HistogramVec)
 	resSize = register(resSize, "response_size_bytes").(*prometheus.HistogramVec)
 	reqSize = register(reqSize, "request_size_bytes").(*prometheus.HistogramVec)
+	fetchItemsCnt = register(fetchItemsCnt, "remote_fetch_items_total").(*prometheus.CounterVec)
+	fetchErrorsCnt = register(fetchErrorsCnt, "remote_fetch_errors_total").(*prometheus.CounterVec)
+	fetchDuration = register(fetchDuration, "remote_fetch_duration_seconds").(*prometheus.HistogramVec)
 	log.Info(nil, nil, "metrics registered successfully")
 }
 
@@ -95,3 +122,14 @@ func reportRequestSize(method, entity, code string, size int64) {
 		reqSize.WithLabelValues(method, entity, code).Observe(float64(size))
 	}
 }
+
+// ReportRemoteFetch records the number of items fetched from a remote tracker of
+// the given provider type, the number of errors and how long the fetch took.
+func ReportRemoteFetch(provider string, items, errors int, duration time.Duration) {
+	if provider == "" {
+		return
+	}
+	fetchItemsCnt.WithLabelValues(provider).Add(float64(items))
+	fetchErrorsCnt.WithLabelValues(provider).Add(float64(errors))
+	fetchDuration.WithLabelValues(provider).Observe(duration.Seconds())
+}
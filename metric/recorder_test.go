@@ -111,6 +111,26 @@ func TestReqSizeMetric(t *testing.T) {
 	checkHistogram(t, m, uint64(len(reqSizes)), expectedBound, expectedCnt)
 }
 
+func TestRemoteFetchMetric(t *testing.T) {
+	ReportRemoteFetch("github", 3, 0, 1500*time.Millisecond)
+	ReportRemoteFetch("github", 2, 1, 5*time.Second)
+	ReportRemoteFetch("jira", 1, 0, time.Second)
+
+	// validate
+	m := &dto.Metric{}
+	itemsMetric, _ := fetchItemsCnt.GetMetricWithLabelValues("github")
+	itemsMetric.Write(m)
+	assert.Equal(t, float64(5), m.Counter.GetValue())
+	m = &dto.Metric{}
+	errorsMetric, _ := fetchErrorsCnt.GetMetricWithLabelValues("github")
+	errorsMetric.Write(m)
+	assert.Equal(t, float64(1), m.Counter.GetValue())
+	m = &dto.Metric{}
+	durationMetric, _ := fetchDuration.GetMetricWithLabelValues("github")
+	durationMetric.Write(m)
+	checkHistogram(t, m, 2, []float64{1, 2, 4, 8, 16, 32, 64, 128, 256, 512}, []uint64{0, 1, 1, 2, 2, 2, 2, 2, 2, 2})
+}
+
 func TestLabelsVal(t *testing.T) {
 	svc := goa.New("metric")
 	ctrl := svc.NewController(dummyCtrl)
@@ -446,6 +446,51 @@ func GetMigrations() Migrations {
 	// Version 102
 	m = append(m, steps{ExecuteSQLFile("102-add-forward-and-reverse-link-type-descriptions.sql")})
 
+	// Version 103
+	m = append(m, steps{ExecuteSQLFile("103-webhooks.sql")})
+
+	// Version 104
+	m = append(m, steps{ExecuteSQLFile("104-tracker-sync-status.sql")})
+
+	// Version 105
+	m = append(m, steps{ExecuteSQLFile("105-tracker-query-high-water-mark.sql")})
+
+	// Version 106
+	m = append(m, steps{ExecuteSQLFile("106-work-item-type-transitions.sql")})
+
+	// Version 107
+	m = append(m, steps{ExecuteSQLFile("107-board-column-wip-limits.sql")})
+
+	// Version 108
+	m = append(m, steps{ExecuteSQLFile("108-personal-access-tokens.sql")})
+
+	// Version 109
+	m = append(m, steps{ExecuteSQLFile("109-space-owned-templates.sql")})
+
+	// Version 110
+	m = append(m, steps{ExecuteSQLFile("110-attachments.sql")})
+
+	// Version 111
+	m = append(m, steps{ExecuteSQLFile("111-comment-reactions.sql")})
+
+	// Version 112
+	m = append(m, steps{ExecuteSQLFile("112-markup-references.sql")})
+
+	// Version 113
+	m = append(m, steps{ExecuteSQLFile("113-watches-and-notification-preferences.sql")})
+
+	// Version 114
+	m = append(m, steps{ExecuteSQLFile("114-iteration-schedules.sql")})
+
+	// Version 115
+	m = append(m, steps{ExecuteSQLFile("115-space-roles.sql")})
+
+	// Version 116
+	m = append(m, steps{ExecuteSQLFile("116-attachment-orphans.sql")})
+
+	// Version 117
+	m = append(m, steps{ExecuteSQLFile("117-tracker-item-retries.sql")})
+
 	// Version N
 	//
 	// In order to add an upgrade, simply append an array of MigrationFunc to the
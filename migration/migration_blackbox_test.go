@@ -152,6 +152,21 @@ func TestMigrations(t *testing.T) {
 	t.Run("TestMigration100", testDropUserspacedataTable)
 	t.Run("TestMigration101", testTypeGroupHasDescriptionField)
 	t.Run("TestMigration102", testLinkTypeDescriptionFields)
+	t.Run("TestMigration103", testWebhookTables)
+	t.Run("TestMigration104", testTrackerSyncStatus)
+	t.Run("TestMigration105", testTrackerQueryHighWaterMark)
+	t.Run("TestMigration106", testWorkItemTypeTransitions)
+	t.Run("TestMigration107", testBoardColumnWIPLimits)
+	t.Run("TestMigration108", testPersonalAccessTokens)
+	t.Run("TestMigration109", testSpaceOwnedTemplates)
+	t.Run("TestMigration110", testAttachments)
+	t.Run("TestMigration111", testCommentReactions)
+	t.Run("TestMigration112", testMarkupReferences)
+	t.Run("TestMigration113", testWatchesAndNotificationPreferences)
+	t.Run("TestMigration114", testIterationSchedules)
+	t.Run("TestMigration115", testSpaceRoles)
+	t.Run("TestMigration116", testAttachmentOrphans)
+	t.Run("TestMigration117", testTrackerItemRetries)
 
 	// Perform the migration
 	err = migration.Migrate(sqlDB, databaseName)
@@ -1264,6 +1279,125 @@ func testLinkTypeDescriptionFields(t *testing.T) {
 	require.True(t, dialect.HasColumn("work_item_link_types", "reverse_description"))
 }
 
+// testWebhookTables checks that the webhooks and webhook_deliveries tables
+// exist after updating to DB version 103.
+func testWebhookTables(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:104], 104)
+	require.True(t, dialect.HasTable("webhooks"))
+	require.True(t, dialect.HasColumn("webhooks", "secret"))
+	require.True(t, dialect.HasColumn("webhooks", "events"))
+	require.True(t, dialect.HasTable("webhook_deliveries"))
+	require.True(t, dialect.HasColumn("webhook_deliveries", "next_attempt_at"))
+	require.True(t, dialect.HasColumn("webhook_deliveries", "payload"))
+}
+
+// testTrackerSyncStatus checks that the push flag of tracker queries and the
+// sync status columns of tracker items exist after updating to DB version 104.
+func testTrackerSyncStatus(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:105], 105)
+	require.True(t, dialect.HasColumn("tracker_queries", "push_enabled"))
+	require.True(t, dialect.HasColumn("tracker_items", "work_item_id"))
+	require.True(t, dialect.HasColumn("tracker_items", "remote_updated_at"))
+	require.True(t, dialect.HasColumn("tracker_items", "synced_version"))
+	require.True(t, dialect.HasColumn("tracker_items", "last_pulled_at"))
+	require.True(t, dialect.HasColumn("tracker_items", "last_pushed_at"))
+	require.True(t, dialect.HasColumn("tracker_items", "sync_conflict"))
+	require.True(t, dialect.HasColumn("tracker_items", "sync_error"))
+}
+
+// testTrackerQueryHighWaterMark checks that the high-water mark of tracker
+// queries exists after updating to DB version 105.
+func testTrackerQueryHighWaterMark(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:106], 106)
+	require.True(t, dialect.HasColumn("tracker_queries", "last_remote_updated_at"))
+}
+
+// testWorkItemTypeTransitions checks that the state transitions of work item
+// types exist after updating to DB version 106.
+func testWorkItemTypeTransitions(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:107], 107)
+	require.True(t, dialect.HasColumn("work_item_types", "transitions"))
+}
+
+// testBoardColumnWIPLimits checks that the work in progress limits of board
+// columns exist after updating to DB version 107.
+func testBoardColumnWIPLimits(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:108], 108)
+	require.True(t, dialect.HasColumn("work_item_board_columns", "wip_limit"))
+	require.True(t, dialect.HasColumn("work_item_board_columns", "wip_limit_enforced"))
+}
+
+// testPersonalAccessTokens checks that personal access tokens and the owners of
+// service accounts can be stored
+func testPersonalAccessTokens(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:109], 109)
+	require.True(t, dialect.HasTable("personal_access_tokens"))
+	require.True(t, dialect.HasColumn("identities", "service_account_owner_id"))
+}
+
+func testSpaceOwnedTemplates(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:110], 110)
+	require.True(t, dialect.HasColumn("space_templates", "space_id"))
+	require.True(t, dialect.HasIndex("space_templates", "space_templates_space_id_idx"))
+}
+
+// testAttachments checks that the attachments table exists after updating to
+// DB version 110.
+func testAttachments(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:111], 111)
+	require.True(t, dialect.HasTable("attachments"))
+	require.True(t, dialect.HasColumn("attachments", "storage_key"))
+	require.True(t, dialect.HasColumn("attachments", "comment_id"))
+	require.True(t, dialect.HasIndex("attachments", "attachments_space_id_idx"))
+}
+
+func testCommentReactions(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:112], 112)
+	require.True(t, dialect.HasTable("comment_reactions"))
+	require.True(t, dialect.HasColumn("comment_reactions", "emoji"))
+	require.True(t, dialect.HasIndex("comments", "comments_parent_comment_id_idx"))
+}
+
+func testMarkupReferences(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:113], 113)
+	require.True(t, dialect.HasTable("markup_references"))
+	require.True(t, dialect.HasColumn("markup_references", "target_work_item_id"))
+	require.True(t, dialect.HasIndex("markup_references", "markup_references_target_work_item_id_idx"))
+}
+
+func testWatchesAndNotificationPreferences(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:114], 114)
+	require.True(t, dialect.HasTable("watches"))
+	require.True(t, dialect.HasIndex("watches", "watches_target_idx"))
+	require.True(t, dialect.HasTable("notification_preferences"))
+	require.True(t, dialect.HasColumn("notification_preferences", "enabled"))
+}
+
+func testIterationSchedules(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:115], 115)
+	require.True(t, dialect.HasTable("iteration_schedules"))
+	require.True(t, dialect.HasIndex("iteration_schedules", "iteration_schedules_parent_idx"))
+	require.True(t, dialect.HasColumn("iteration_schedules", "next_sequence"))
+}
+
+func testSpaceRoles(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:116], 116)
+	require.True(t, dialect.HasTable("space_roles"))
+	require.True(t, dialect.HasIndex("space_roles", "space_roles_identity_idx"))
+}
+
+func testAttachmentOrphans(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:117], 117)
+	require.True(t, dialect.HasTable("attachment_orphans"))
+	require.True(t, dialect.HasColumn("attachment_orphans", "storage_key"))
+}
+
+func testTrackerItemRetries(t *testing.T) {
+	migrateToVersion(t, sqlDB, migrations[:118], 118)
+	require.True(t, dialect.HasTable("tracker_item_retries"))
+	require.True(t, dialect.HasColumn("tracker_item_retries", "attempts"))
+}
+
 // migrateToVersion runs the migration of all the scripts to a certain version
 func migrateToVersion(t *testing.T, db *sql.DB, m migration.Migrations, version int64) {
 	var err error
@@ -0,0 +1,36 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- webhook subscriptions of a space
+CREATE TABLE webhooks (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    version integer DEFAULT 0 NOT NULL,
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    url text NOT NULL CHECK(url <> ''),
+    secret text NOT NULL CHECK(secret <> ''),
+    events text[] NOT NULL DEFAULT '{}',
+    active boolean NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX webhooks_space_id_idx ON webhooks (space_id) WHERE deleted_at IS NULL;
+
+-- outbox of webhook deliveries that are yet to be sent or retried
+CREATE TABLE webhook_deliveries (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    webhook_id uuid NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    message_id uuid NOT NULL,
+    event_type text NOT NULL CHECK(event_type <> ''),
+    payload jsonb NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    next_attempt_at timestamp with time zone NOT NULL DEFAULT now(),
+    delivered_at timestamp with time zone,
+    failed_at timestamp with time zone,
+    last_error text,
+    CONSTRAINT webhook_deliveries_webhook_id_message_id_unique UNIQUE(webhook_id, message_id)
+);
+
+CREATE INDEX webhook_deliveries_pending_idx ON webhook_deliveries (next_attempt_at) WHERE delivered_at IS NULL AND failed_at IS NULL;
@@ -0,0 +1,12 @@
+-- opt-in for pushing local changes back to the remote tracker
+ALTER TABLE tracker_queries ADD COLUMN push_enabled boolean NOT NULL DEFAULT FALSE;
+
+-- sync status of each remote tracker item
+ALTER TABLE tracker_items ADD COLUMN work_item_id uuid REFERENCES work_items(id) ON DELETE SET NULL;
+ALTER TABLE tracker_items ADD COLUMN remote_updated_at timestamp with time zone;
+ALTER TABLE tracker_items ADD COLUMN synced_version integer;
+ALTER TABLE tracker_items ADD COLUMN last_pulled_at timestamp with time zone;
+ALTER TABLE tracker_items ADD COLUMN last_pushed_at timestamp with time zone;
+ALTER TABLE tracker_items ADD COLUMN sync_conflict text;
+ALTER TABLE tracker_items ADD COLUMN sync_error text;
+CREATE INDEX tracker_items_work_item_id_idx ON tracker_items USING btree (work_item_id);
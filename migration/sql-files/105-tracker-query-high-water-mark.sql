@@ -0,0 +1,2 @@
+-- the newest update timestamp of the remote items fetched by a tracker query
+ALTER TABLE tracker_queries ADD COLUMN last_remote_updated_at timestamp with time zone;
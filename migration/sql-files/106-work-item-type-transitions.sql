@@ -0,0 +1,3 @@
+-- the allowed transitions between the values of the state field of a work item
+-- type (NULL allows all transitions)
+ALTER TABLE work_item_types ADD COLUMN transitions jsonb;
@@ -0,0 +1,4 @@
+-- the optional work in progress limit of board columns (0 means no limit) and
+-- whether moves exceeding it are rejected or only warned about
+ALTER TABLE work_item_board_columns ADD COLUMN wip_limit integer NOT NULL DEFAULT 0 CHECK (wip_limit >= 0);
+ALTER TABLE work_item_board_columns ADD COLUMN wip_limit_enforced boolean NOT NULL DEFAULT FALSE;
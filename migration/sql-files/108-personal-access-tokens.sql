@@ -0,0 +1,25 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- service accounts are identities without a user which belong to the identity
+-- that created them
+ALTER TABLE identities ADD COLUMN service_account_owner_id uuid REFERENCES identities(id) ON DELETE CASCADE;
+
+-- locally issued tokens which let an identity call the API without a JWT from
+-- the Auth service. Only the SHA-256 hash of a token is stored.
+CREATE TABLE personal_access_tokens (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    creator_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    name text NOT NULL CHECK(name <> ''),
+    token_hash text NOT NULL CHECK(token_hash <> ''),
+    scopes text[] NOT NULL DEFAULT '{}',
+    expires_at timestamp with time zone,
+    last_used_at timestamp with time zone,
+    revoked_at timestamp with time zone,
+    CONSTRAINT personal_access_tokens_token_hash_unique UNIQUE(token_hash)
+);
+
+CREATE INDEX personal_access_tokens_creator_id_idx ON personal_access_tokens (creator_id) WHERE deleted_at IS NULL;
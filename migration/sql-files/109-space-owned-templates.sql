@@ -0,0 +1,4 @@
+-- a space template cloned for a single space belongs to that space and is
+-- removed with it
+ALTER TABLE space_templates ADD COLUMN space_id uuid REFERENCES spaces(id) ON DELETE CASCADE;
+CREATE INDEX space_templates_space_id_idx ON space_templates (space_id);
@@ -0,0 +1,23 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- files attached to work items and their comments. The content is kept in a
+-- blob store under the storage key; only the metadata lives here.
+CREATE TABLE attachments (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    work_item_id uuid NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    comment_id uuid REFERENCES comments(id) ON DELETE CASCADE,
+    creator_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    filename text NOT NULL CHECK(filename <> ''),
+    content_type text NOT NULL CHECK(content_type <> ''),
+    size bigint NOT NULL CHECK(size >= 0),
+    checksum text NOT NULL,
+    storage_key text NOT NULL CHECK(storage_key <> '')
+);
+
+CREATE INDEX attachments_work_item_id_idx ON attachments (work_item_id) WHERE deleted_at IS NULL;
+-- the size of all attachments of a space is summed up to enforce its quota
+CREATE INDEX attachments_space_id_idx ON attachments (space_id) WHERE deleted_at IS NULL;
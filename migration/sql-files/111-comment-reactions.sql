@@ -0,0 +1,12 @@
+-- replies are looked up by their parent comment when a thread is listed
+CREATE INDEX comments_parent_comment_id_idx ON comments (parent_comment_id) WHERE deleted_at IS NULL;
+
+-- emoji reactions to comments. Each identity reacts at most once with the same
+-- emoji to a comment.
+CREATE TABLE comment_reactions (
+    created_at timestamp with time zone,
+    comment_id uuid NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    emoji text NOT NULL CHECK(emoji <> ''),
+    PRIMARY KEY (comment_id, identity_id, emoji)
+);
@@ -0,0 +1,18 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- the resolved @mentions and #references in the descriptions of work items
+-- and in their comments
+CREATE TABLE markup_references (
+    created_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source_work_item_id uuid NOT NULL REFERENCES work_items(id) ON DELETE CASCADE,
+    source_comment_id uuid REFERENCES comments(id) ON DELETE CASCADE,
+    text text NOT NULL CHECK(text <> ''),
+    target_identity_id uuid REFERENCES identities(id) ON DELETE CASCADE,
+    target_work_item_id uuid REFERENCES work_items(id) ON DELETE CASCADE,
+    CHECK((target_identity_id IS NULL) <> (target_work_item_id IS NULL))
+);
+
+CREATE INDEX markup_references_source_idx ON markup_references (source_work_item_id, source_comment_id);
+-- the backlinks of a work item are the references to it
+CREATE INDEX markup_references_target_work_item_id_idx ON markup_references (target_work_item_id) WHERE target_work_item_id IS NOT NULL;
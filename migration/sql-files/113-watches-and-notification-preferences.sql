@@ -0,0 +1,24 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- the work items, iterations and spaces about which users want to be notified
+CREATE TABLE watches (
+    created_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    target_type text NOT NULL CHECK(target_type IN ('workitems', 'iterations', 'spaces')),
+    target_id uuid NOT NULL,
+    UNIQUE(identity_id, target_type, target_id)
+);
+
+CREATE INDEX watches_target_idx ON watches (target_type, target_id);
+
+-- the choices of users which differ from the default of being notified about
+-- every event type on every channel
+CREATE TABLE notification_preferences (
+    updated_at timestamp with time zone,
+    identity_id uuid NOT NULL REFERENCES identities(id) ON DELETE CASCADE,
+    event_type text NOT NULL CHECK(event_type <> ''),
+    channel text NOT NULL CHECK(channel <> ''),
+    enabled boolean NOT NULL,
+    PRIMARY KEY(identity_id, event_type, channel)
+);
@@ -0,0 +1,24 @@
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- recurring iterations which are generated below a parent iteration
+CREATE TABLE iteration_schedules (
+    created_at timestamp with time zone,
+    updated_at timestamp with time zone,
+    deleted_at timestamp with time zone,
+    id uuid PRIMARY KEY DEFAULT uuid_generate_v4(),
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    parent_iteration_id uuid NOT NULL REFERENCES iterations(id) ON DELETE CASCADE,
+    start_at timestamp with time zone NOT NULL,
+    cadence_days integer NOT NULL CHECK(cadence_days > 0),
+    length_days integer NOT NULL CHECK(length_days > 0),
+    name_pattern text NOT NULL CHECK(name_pattern <> ''),
+    lookahead_days integer NOT NULL CHECK(lookahead_days >= 0),
+    active boolean NOT NULL DEFAULT TRUE,
+    -- the number of the next iteration to generate, starting with 1
+    next_sequence integer NOT NULL DEFAULT 1,
+    version integer NOT NULL DEFAULT 0
+);
+
+-- a parent iteration has at most one schedule
+CREATE UNIQUE INDEX iteration_schedules_parent_idx ON iteration_schedules (parent_iteration_id) WHERE deleted_at IS NULL;
+CREATE INDEX iteration_schedules_space_idx ON iteration_schedules (space_id);
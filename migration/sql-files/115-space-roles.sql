@@ -0,0 +1,12 @@
+-- the roles of the space collaborators as last reported by the Auth service,
+-- the roles of requests made with a personal access token are resolved from
+-- them
+CREATE TABLE space_roles (
+    space_id uuid NOT NULL REFERENCES spaces(id) ON DELETE CASCADE,
+    identity_id uuid NOT NULL,
+    role_name text NOT NULL,
+    recorded_at timestamp with time zone NOT NULL DEFAULT now(),
+    PRIMARY KEY (space_id, identity_id, role_name)
+);
+
+CREATE INDEX space_roles_identity_idx ON space_roles (identity_id);
@@ -0,0 +1,66 @@
+-- the storage keys of deleted attachments whose content still has to be
+-- removed from the blob store by the attachment cleaner
+CREATE TABLE attachment_orphans (
+    storage_key text PRIMARY KEY,
+    created_at timestamp with time zone NOT NULL DEFAULT now()
+);
+
+-- the content of an attachment becomes an orphan when the attachment is soft
+-- deleted or removed along with its work item, comment or space
+CREATE FUNCTION orphan_attachment() RETURNS trigger AS $orphan_attachment$
+    BEGIN
+        INSERT INTO attachment_orphans (storage_key) VALUES (OLD.storage_key) ON CONFLICT DO NOTHING;
+        RETURN NULL;
+    END;
+$orphan_attachment$ LANGUAGE plpgsql;
+
+CREATE TRIGGER orphan_attachment_after_soft_delete_trigger
+AFTER UPDATE OF deleted_at ON attachments
+FOR EACH ROW
+WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+EXECUTE PROCEDURE orphan_attachment();
+
+CREATE TRIGGER orphan_attachment_after_delete_trigger
+AFTER DELETE ON attachments
+FOR EACH ROW
+EXECUTE PROCEDURE orphan_attachment();
+
+-- soft deleting a work item, comment or space soft deletes its attachments
+CREATE FUNCTION soft_delete_attachments_of_work_item() RETURNS trigger AS $soft_delete_attachments_of_work_item$
+    BEGIN
+        UPDATE attachments SET deleted_at = NEW.deleted_at WHERE work_item_id = NEW.id AND deleted_at IS NULL;
+        RETURN NULL;
+    END;
+$soft_delete_attachments_of_work_item$ LANGUAGE plpgsql;
+
+CREATE TRIGGER soft_delete_attachments_of_work_item_trigger
+AFTER UPDATE OF deleted_at ON work_items
+FOR EACH ROW
+WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+EXECUTE PROCEDURE soft_delete_attachments_of_work_item();
+
+CREATE FUNCTION soft_delete_attachments_of_comment() RETURNS trigger AS $soft_delete_attachments_of_comment$
+    BEGIN
+        UPDATE attachments SET deleted_at = NEW.deleted_at WHERE comment_id = NEW.id AND deleted_at IS NULL;
+        RETURN NULL;
+    END;
+$soft_delete_attachments_of_comment$ LANGUAGE plpgsql;
+
+CREATE TRIGGER soft_delete_attachments_of_comment_trigger
+AFTER UPDATE OF deleted_at ON comments
+FOR EACH ROW
+WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+EXECUTE PROCEDURE soft_delete_attachments_of_comment();
+
+CREATE FUNCTION soft_delete_attachments_of_space() RETURNS trigger AS $soft_delete_attachments_of_space$
+    BEGIN
+        UPDATE attachments SET deleted_at = NEW.deleted_at WHERE space_id = NEW.id AND deleted_at IS NULL;
+        RETURN NULL;
+    END;
+$soft_delete_attachments_of_space$ LANGUAGE plpgsql;
+
+CREATE TRIGGER soft_delete_attachments_of_space_trigger
+AFTER UPDATE OF deleted_at ON spaces
+FOR EACH ROW
+WHEN (OLD.deleted_at IS NULL AND NEW.deleted_at IS NOT NULL)
+EXECUTE PROCEDURE soft_delete_attachments_of_space();
@@ -0,0 +1,13 @@
+-- the remote items of a tracker query which failed to be synchronized. The
+-- high-water mark of the query moves past them, so they are retried from the
+-- stored content until they are synchronized or run out of attempts.
+CREATE TABLE tracker_item_retries (
+    created_at timestamp with time zone NOT NULL DEFAULT now(),
+    updated_at timestamp with time zone NOT NULL DEFAULT now(),
+    tracker_query_id bigint NOT NULL REFERENCES tracker_queries(id) ON DELETE CASCADE,
+    remote_item_id text NOT NULL,
+    item text NOT NULL,
+    attempts integer NOT NULL DEFAULT 1,
+    last_error text,
+    PRIMARY KEY (tracker_query_id, remote_item_id)
+);
@@ -29,6 +29,33 @@ type Message struct {
 	UserID      *string
 	TargetID    string
 	MessageType string
+	// Custom holds additional details of the event, e.g. the mentioned user
+	Custom map[string]interface{}
+	// Recipients are the users to notify about the event. They are resolved
+	// from the watchers, assignees and creators of the target and from their
+	// notification preferences before the message is sent.
+	Recipients []Recipient
+}
+
+// Keys of the Custom details of a message
+const (
+	// CustomMentionedID is the ID of the user mentioned in a work item or comment
+	CustomMentionedID = "mentioned_id"
+	// CustomAssignedIDs are the IDs of the users newly assigned to a work item
+	CustomAssignedIDs = "assigned_ids"
+	// CustomStateChanged tells whether the state of a work item changed
+	CustomStateChanged = "state_changed"
+	// CustomWorkItemIDs are the IDs of the work items changed by a bulk update
+	CustomWorkItemIDs = "workitem_ids"
+	// CustomRecipients holds the recipients sent to the notification service
+	CustomRecipients = "recipients"
+)
+
+// Recipient is a user to notify along with the channels on which to deliver
+// the notification
+type Recipient struct {
+	IdentityID uuid.UUID `json:"id"`
+	Channels   []string  `json:"channels"`
 }
 
 func (m Message) String() string {
@@ -45,6 +72,13 @@ func NewWorkItemUpdated(workitemID string) Message {
 	return Message{MessageID: uuid.NewV4(), MessageType: "workitem.update", TargetID: workitemID}
 }
 
+// NewWorkItemsBulkUpdated creates a new message instance for a bulk update of
+// the given work items in the given SpaceID
+func NewWorkItemsBulkUpdated(spaceID string, workitemIDs []string) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: "workitem.bulkupdate", TargetID: spaceID,
+		Custom: map[string]interface{}{CustomWorkItemIDs: workitemIDs}}
+}
+
 // NewCommentCreated creates a new message instance for the newly created CommentID
 func NewCommentCreated(commentID string) Message {
 	return Message{MessageID: uuid.NewV4(), MessageType: "comment.create", TargetID: commentID}
@@ -55,6 +89,36 @@ func NewCommentUpdated(commentID string) Message {
 	return Message{MessageID: uuid.NewV4(), MessageType: "comment.update", TargetID: commentID}
 }
 
+// NewWorkItemMention creates a new message instance for the user who was
+// mentioned in the description of the WorkItemID
+func NewWorkItemMention(workitemID string, mentionedID uuid.UUID) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: "workitem.mention", TargetID: workitemID,
+		Custom: map[string]interface{}{CustomMentionedID: mentionedID.String()}}
+}
+
+// NewCommentMention creates a new message instance for the user who was
+// mentioned in the CommentID
+func NewCommentMention(commentID string, mentionedID uuid.UUID) Message {
+	return Message{MessageID: uuid.NewV4(), MessageType: "comment.mention", TargetID: commentID,
+		Custom: map[string]interface{}{CustomMentionedID: mentionedID.String()}}
+}
+
+// customWithRecipients returns the Custom details of the message along with
+// its recipients. The recipients are always set, even if there are none, so
+// that the notification service doesn't fall back to notifying everybody.
+func customWithRecipients(msg Message) map[string]interface{} {
+	custom := make(map[string]interface{}, len(msg.Custom)+1)
+	for k, v := range msg.Custom {
+		custom[k] = v
+	}
+	recipients := msg.Recipients
+	if recipients == nil {
+		recipients = []Recipient{}
+	}
+	custom[CustomRecipients] = recipients
+	return custom
+}
+
 func setCurrentIdentity(ctx context.Context, msg *Message) {
 	currentUserIdentityID, err := login.ContextIdentity(ctx)
 	if err != nil {
@@ -69,6 +133,21 @@ type DevNullChannel struct{}
 // Send NO-OP
 func (d *DevNullChannel) Send(context.Context, Message) {}
 
+// MultiChannel forwards every message to all of its channels
+type MultiChannel []Channel
+
+// NewMultiChannel creates a channel that fans out to the given channels
+func NewMultiChannel(channels ...Channel) Channel {
+	return MultiChannel(channels)
+}
+
+// Send forwards the message to each channel
+func (m MultiChannel) Send(ctx context.Context, msg Message) {
+	for _, c := range m {
+		c.Send(ctx, msg)
+	}
+}
+
 // ServiceConfiguration holds configuration options required to interact with the fabric8-notification API
 type ServiceConfiguration interface {
 	GetNotificationServiceURL() string
@@ -124,8 +203,9 @@ func (s *Service) Send(ctx context.Context, msg Message) {
 					Type: "notifications",
 					ID:   &msgID,
 					Attributes: &client.NotificationAttributes{
-						Type: msg.MessageType,
-						ID:   msg.TargetID,
+						Type:   msg.MessageType,
+						ID:     msg.TargetID,
+						Custom: customWithRecipients(msg),
 					},
 				},
 			},
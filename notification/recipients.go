@@ -0,0 +1,224 @@
+package notification
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// RecipientChannel is a notification channel which resolves the recipients of
+// every message before passing it on to the next channel
+type RecipientChannel struct {
+	db   *gorm.DB
+	next Channel
+}
+
+var _ Channel = &RecipientChannel{}
+
+// NewRecipientChannel creates a notification channel which resolves the
+// recipients of the messages sent to the given channel
+func NewRecipientChannel(db *gorm.DB, next Channel) *RecipientChannel {
+	return &RecipientChannel{db: db, next: next}
+}
+
+// Send resolves the recipients of the message and passes it on. A message
+// whose recipients can't be resolved is dropped, as passing it on without
+// recipients would notify nobody or, for older consumers, everybody.
+func (c *RecipientChannel) Send(ctx context.Context, msg Message) {
+	recipients, err := ResolveRecipients(ctx, c.db, msg)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"message_id": msg.MessageID,
+			"type":       msg.MessageType,
+			"target_id":  msg.TargetID,
+			"err":        err,
+		}, "unable to resolve the recipients of the notification, the notification is dropped")
+		return
+	}
+	msg.Recipients = recipients
+	c.next.Send(ctx, msg)
+}
+
+// ResolveRecipients returns the users to notify about the event of the message
+// along with the channels they chose for its event type. Mentions go to the
+// mentioned user and assignments to the newly assigned users. State changes,
+// other updates and comments go to the watchers of the work item, of its
+// iteration and of its space as well as to its assignees and its creator. Bulk
+// updates go to the combined audience of the updated work items. The user who
+// caused the event isn't notified. The result is never nil, so that an empty result can be told apart
+// from unresolved recipients.
+func ResolveRecipients(ctx context.Context, db *gorm.DB, msg Message) ([]Recipient, error) {
+	candidates := map[string][]uuid.UUID{}
+	switch msg.MessageType {
+	case "workitem.mention", "comment.mention":
+		if mentioned, ok := msg.Custom[CustomMentionedID].(string); ok {
+			candidates[subscription.EventMentioned] = uuids(mentioned)
+		}
+	case "workitem.create", "workitem.update":
+		assigned, _ := msg.Custom[CustomAssignedIDs].([]string)
+		if len(assigned) > 0 {
+			candidates[subscription.EventAssigned] = uuids(assigned...)
+		}
+		changed, _ := msg.Custom[CustomStateChanged].(bool)
+		// any other update of a work item concerns its whole audience
+		if changed || (msg.MessageType == "workitem.update" && len(assigned) == 0) {
+			wiID, err := uuid.FromString(msg.TargetID)
+			if err != nil {
+				return nil, errs.Wrapf(err, "invalid work item ID %s", msg.TargetID)
+			}
+			audience, err := workItemAudience(ctx, db, wiID)
+			if err != nil {
+				return nil, err
+			}
+			if changed {
+				candidates[subscription.EventStateChange] = audience
+			} else {
+				candidates[subscription.EventUpdate] = audience
+			}
+		}
+	case "workitem.bulkupdate":
+		if assigned, _ := msg.Custom[CustomAssignedIDs].([]string); len(assigned) > 0 {
+			candidates[subscription.EventAssigned] = uuids(assigned...)
+		}
+		ids, _ := msg.Custom[CustomWorkItemIDs].([]string)
+		var audience []uuid.UUID
+		for _, wiID := range uuids(ids...) {
+			wiAudience, err := workItemAudience(ctx, db, wiID)
+			if err != nil {
+				return nil, err
+			}
+			audience = append(audience, wiAudience...)
+		}
+		if changed, _ := msg.Custom[CustomStateChanged].(bool); changed {
+			candidates[subscription.EventStateChange] = distinct(audience)
+		} else {
+			candidates[subscription.EventUpdate] = distinct(audience)
+		}
+	case "comment.create", "comment.update":
+		commentID, err := uuid.FromString(msg.TargetID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "invalid comment ID %s", msg.TargetID)
+		}
+		cmt, err := comment.NewRepository(db).Load(ctx, commentID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to load comment %s", commentID)
+		}
+		audience, err := workItemAudience(ctx, db, cmt.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		candidates[subscription.EventComment] = audience
+	}
+	actor, _ := login.ContextIdentity(ctx)
+	result := []Recipient{}
+	index := map[uuid.UUID]int{}
+	preferences := subscription.NewPreferenceRepository(db)
+	// the order of the event types keeps the result stable
+	for _, eventType := range subscription.EventTypes {
+		identityIDs := candidates[eventType]
+		if len(identityIDs) == 0 {
+			continue
+		}
+		channels, err := preferences.Channels(ctx, eventType, identityIDs...)
+		if err != nil {
+			return nil, err
+		}
+		for _, identityID := range identityIDs {
+			if (actor != nil && uuid.Equal(identityID, *actor)) || len(channels[identityID]) == 0 {
+				continue
+			}
+			i, ok := index[identityID]
+			if !ok {
+				i = len(result)
+				index[identityID] = i
+				result = append(result, Recipient{IdentityID: identityID})
+			}
+			for _, channel := range channels[identityID] {
+				if !containsString(result[i].Channels, channel) {
+					result[i].Channels = append(result[i].Channels, channel)
+				}
+			}
+		}
+	}
+	return result, nil
+}
+
+// workItemAudience returns the distinct identities which watch the work item,
+// its iteration or its space, followed by its assignees and its creator
+func workItemAudience(ctx context.Context, db *gorm.DB, wiID uuid.UUID) ([]uuid.UUID, error) {
+	wi, err := workitem.NewWorkItemRepository(db).LoadByID(ctx, wiID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item %s", wiID)
+	}
+	targets := map[string]uuid.UUID{
+		subscription.TargetWorkItem: wi.ID,
+		subscription.TargetSpace:    wi.SpaceID,
+	}
+	if iterationID, ok := wi.Fields[workitem.SystemIteration].(string); ok {
+		targets[subscription.TargetIteration] = uuid.FromStringOrNil(iterationID)
+	}
+	var audience []uuid.UUID
+	watches := subscription.NewWatchRepository(db)
+	for _, targetType := range subscription.TargetTypes {
+		targetID, ok := targets[targetType]
+		if !ok {
+			continue
+		}
+		watchers, err := watches.ListWatchers(ctx, targetType, targetID)
+		if err != nil {
+			return nil, err
+		}
+		audience = append(audience, watchers...)
+	}
+	if assignees, ok := wi.Fields[workitem.SystemAssignees].([]interface{}); ok {
+		for _, a := range assignees {
+			if s, ok := a.(string); ok {
+				audience = append(audience, uuids(s)...)
+			}
+		}
+	}
+	if creator, ok := wi.Fields[workitem.SystemCreator].(string); ok {
+		audience = append(audience, uuids(creator)...)
+	}
+	return distinct(audience), nil
+}
+
+// uuids parses the given IDs and skips the invalid ones
+func uuids(ids ...string) []uuid.UUID {
+	var result []uuid.UUID
+	for _, s := range ids {
+		if id, err := uuid.FromString(s); err == nil {
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+// distinct returns the IDs without duplicates in their original order
+func distinct(ids []uuid.UUID) []uuid.UUID {
+	seen := map[uuid.UUID]bool{}
+	var result []uuid.UUID
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
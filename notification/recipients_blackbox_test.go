@@ -0,0 +1,154 @@
+package notification_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type recipientsSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRecipients(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &recipientsSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// recordingChannel remembers the messages sent to it
+type recordingChannel struct {
+	messages []notification.Message
+}
+
+func (c *recordingChannel) Send(_ context.Context, msg notification.Message) {
+	c.messages = append(c.messages, msg)
+}
+
+func (s *recipientsSuite) TestResolveRecipients() {
+	// identity 0 creates the work item, identity 1 is assigned to it,
+	// identity 2 watches its iteration and identity 3 its space
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(4), tf.Iterations(1), tf.Comments(1),
+		tf.WorkItems(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.Iterations[0].ID.String()
+			fxt.WorkItems[idx].Fields[workitem.SystemAssignees] = []string{fxt.Identities[1].ID.String()}
+			return nil
+		}),
+	)
+	creator, assignee, iterationWatcher, spaceWatcher := fxt.Identities[0].ID, fxt.Identities[1].ID, fxt.Identities[2].ID, fxt.Identities[3].ID
+	watches := subscription.NewWatchRepository(s.DB)
+	require.NoError(s.T(), watches.Watch(s.Ctx, &subscription.Watch{IdentityID: iterationWatcher, TargetType: subscription.TargetIteration, TargetID: fxt.Iterations[0].ID}))
+	require.NoError(s.T(), watches.Watch(s.Ctx, &subscription.Watch{IdentityID: spaceWatcher, TargetType: subscription.TargetSpace, TargetID: fxt.Spaces[0].ID}))
+	// the space watcher only wants to read about comments on the web
+	require.NoError(s.T(), subscription.NewPreferenceRepository(s.DB).Save(s.Ctx, subscription.Preference{
+		IdentityID: spaceWatcher, EventType: subscription.EventComment, Channel: subscription.ChannelEmail, Enabled: false,
+	}))
+	all := []string{subscription.ChannelEmail, subscription.ChannelWeb}
+
+	s.T().Run("comment", func(t *testing.T) {
+		// when
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewCommentCreated(fxt.Comments[0].ID.String()))
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []notification.Recipient{
+			{IdentityID: iterationWatcher, Channels: all},
+			{IdentityID: spaceWatcher, Channels: []string{subscription.ChannelWeb}},
+			{IdentityID: assignee, Channels: all},
+			{IdentityID: creator, Channels: all},
+		}, recipients)
+	})
+
+	s.T().Run("assignment", func(t *testing.T) {
+		// given
+		msg := notification.NewWorkItemUpdated(fxt.WorkItems[0].ID.String())
+		msg.Custom = map[string]interface{}{notification.CustomAssignedIDs: []string{assignee.String()}}
+		// when
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, msg)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []notification.Recipient{{IdentityID: assignee, Channels: all}}, recipients)
+	})
+
+	s.T().Run("state change", func(t *testing.T) {
+		// given
+		msg := notification.NewWorkItemUpdated(fxt.WorkItems[0].ID.String())
+		msg.Custom = map[string]interface{}{notification.CustomStateChanged: true}
+		// when
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, msg)
+		// then
+		require.NoError(t, err)
+		require.Len(t, recipients, 4)
+		assert.Equal(t, spaceWatcher, recipients[1].IdentityID)
+		assert.Equal(t, all, recipients[1].Channels)
+	})
+
+	s.T().Run("mention", func(t *testing.T) {
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewWorkItemMention(fxt.WorkItems[0].ID.String(), spaceWatcher))
+		require.NoError(t, err)
+		assert.Equal(t, []notification.Recipient{{IdentityID: spaceWatcher, Channels: all}}, recipients)
+	})
+
+	s.T().Run("plain update", func(t *testing.T) {
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewWorkItemUpdated(fxt.WorkItems[0].ID.String()))
+		require.NoError(t, err)
+		assert.Equal(t, []notification.Recipient{
+			{IdentityID: iterationWatcher, Channels: all},
+			{IdentityID: spaceWatcher, Channels: all},
+			{IdentityID: assignee, Channels: all},
+			{IdentityID: creator, Channels: all},
+		}, recipients)
+	})
+
+	s.T().Run("bulk update", func(t *testing.T) {
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewWorkItemsBulkUpdated(fxt.Spaces[0].ID.String(), []string{fxt.WorkItems[0].ID.String()}))
+		require.NoError(t, err)
+		assert.Equal(t, []notification.Recipient{
+			{IdentityID: iterationWatcher, Channels: all},
+			{IdentityID: spaceWatcher, Channels: all},
+			{IdentityID: assignee, Channels: all},
+			{IdentityID: creator, Channels: all},
+		}, recipients)
+	})
+
+	s.T().Run("nobody to notify", func(t *testing.T) {
+		recipients, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewWorkItemCreated(fxt.WorkItems[0].ID.String()))
+		require.NoError(t, err)
+		assert.NotNil(t, recipients)
+		assert.Empty(t, recipients)
+	})
+
+	s.T().Run("channel", func(t *testing.T) {
+		// given
+		next := &recordingChannel{}
+		channel := notification.NewRecipientChannel(s.DB, next)
+		// when
+		channel.Send(s.Ctx, notification.NewWorkItemMention(fxt.WorkItems[0].ID.String(), creator))
+		// then
+		require.Len(t, next.messages, 1)
+		assert.Equal(t, []notification.Recipient{{IdentityID: creator, Channels: all}}, next.messages[0].Recipients)
+	})
+
+	s.T().Run("unknown comment", func(t *testing.T) {
+		_, err := notification.ResolveRecipients(s.Ctx, s.DB, notification.NewCommentCreated(uuid.NewV4().String()))
+		require.Error(t, err)
+	})
+
+	s.T().Run("channel drops unresolved messages", func(t *testing.T) {
+		// given
+		next := &recordingChannel{}
+		channel := notification.NewRecipientChannel(s.DB, next)
+		// when
+		channel.Send(s.Ctx, notification.NewCommentCreated(uuid.NewV4().String()))
+		// then
+		assert.Empty(t, next.messages)
+	})
+}
@@ -0,0 +1,5 @@
+// Package subscription stores who wants to be notified about what: users watch
+// work items, iterations and spaces and choose per event type and delivery
+// channel whether they want to be notified. The notification package resolves
+// the recipients of a message from them.
+package subscription
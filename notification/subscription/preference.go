@@ -0,0 +1,185 @@
+package subscription
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/closeable"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const preferenceTableName = "notification_preferences"
+
+// The event types for which users choose whether they want to be notified
+const (
+	// EventAssigned is the assignment of a work item to the user
+	EventAssigned = "assigned"
+	// EventMentioned is an @mention of the user in a description or comment
+	EventMentioned = "mentioned"
+	// EventStateChange is a change of the state of a work item
+	EventStateChange = "state-change"
+	// EventComment is a new or edited comment on a work item
+	EventComment = "comment"
+	// EventUpdate is any other change of a work item
+	EventUpdate = "update"
+)
+
+// EventTypes lists all the event types for which preferences are set
+var EventTypes = []string{EventAssigned, EventMentioned, EventStateChange, EventComment, EventUpdate}
+
+// The channels on which notifications are delivered
+const (
+	ChannelEmail = "email"
+	ChannelWeb   = "web"
+)
+
+// Channels lists all the delivery channels
+var Channels = []string{ChannelEmail, ChannelWeb}
+
+// Preference tells whether an identity wants to be notified about an event
+// type on a channel. Without a stored preference the identity is notified.
+type Preference struct {
+	UpdatedAt  time.Time
+	IdentityID uuid.UUID `sql:"type:uuid" gorm:"primary_key"`
+	EventType  string    `gorm:"primary_key"`
+	Channel    string    `gorm:"primary_key"`
+	Enabled    bool
+}
+
+// TableName implements gorm.tabler
+func (p Preference) TableName() string {
+	return preferenceTableName
+}
+
+// Validate returns a BadParameterError if the event type or the channel of the
+// preference is unknown
+func (p Preference) Validate() error {
+	if !contains(EventTypes, p.EventType) {
+		return errors.NewBadParameterError("event type", p.EventType).Expected(strings.Join(EventTypes, ", "))
+	}
+	if !contains(Channels, p.Channel) {
+		return errors.NewBadParameterError("channel", p.Channel).Expected(strings.Join(Channels, ", "))
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// PreferenceRepository encapsulates storage & retrieval of notification
+// preferences
+type PreferenceRepository interface {
+	// List returns the preferences of the identity for every event type and
+	// channel, including the default ones
+	List(ctx context.Context, identityID uuid.UUID) ([]Preference, error)
+	// Save stores the given preferences
+	Save(ctx context.Context, preferences ...Preference) error
+	// Channels returns the channels on which each of the given identities
+	// wants to be notified about the event type. Identities which don't want
+	// to be notified at all are left out.
+	Channels(ctx context.Context, eventType string, identityIDs ...uuid.UUID) (map[uuid.UUID][]string, error)
+}
+
+// NewPreferenceRepository creates a GormPreferenceRepository
+func NewPreferenceRepository(db *gorm.DB) *GormPreferenceRepository {
+	return &GormPreferenceRepository{db: db}
+}
+
+// GormPreferenceRepository implements PreferenceRepository using gorm
+type GormPreferenceRepository struct {
+	db *gorm.DB
+}
+
+// List returns the preferences of the identity for every event type and
+// channel, in the order of EventTypes and Channels
+func (r *GormPreferenceRepository) List(ctx context.Context, identityID uuid.UUID) ([]Preference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notification_preference", "list"}, time.Now())
+	var stored []Preference
+	if err := r.db.Where("identity_id = ?", identityID).Find(&stored).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the notification preferences"))
+	}
+	result := []Preference{}
+	for _, eventType := range EventTypes {
+		for _, channel := range Channels {
+			p := Preference{IdentityID: identityID, EventType: eventType, Channel: channel, Enabled: true}
+			for _, s := range stored {
+				if s.EventType == eventType && s.Channel == channel {
+					p = s
+				}
+			}
+			result = append(result, p)
+		}
+	}
+	return result, nil
+}
+
+// Save stores the given preferences
+func (r *GormPreferenceRepository) Save(ctx context.Context, preferences ...Preference) error {
+	defer goa.MeasureSince([]string{"goa", "db", "notification_preference", "save"}, time.Now())
+	for _, p := range preferences {
+		if err := p.Validate(); err != nil {
+			return err
+		}
+		db := r.db.Exec(`INSERT INTO `+preferenceTableName+` (updated_at, identity_id, event_type, channel, enabled)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT (identity_id, event_type, channel) DO UPDATE SET updated_at = EXCLUDED.updated_at, enabled = EXCLUDED.enabled`,
+			time.Now(), p.IdentityID, p.EventType, p.Channel, p.Enabled)
+		if db.Error != nil {
+			log.Error(ctx, map[string]interface{}{
+				"identity_id": p.IdentityID,
+				"event_type":  p.EventType,
+				"channel":     p.Channel,
+				"err":         db.Error,
+			}, "unable to save the notification preference")
+			return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to save the notification preference"))
+		}
+	}
+	return nil
+}
+
+// Channels returns the channels on which each of the given identities wants to
+// be notified about the event type, in the order of Channels
+func (r *GormPreferenceRepository) Channels(ctx context.Context, eventType string, identityIDs ...uuid.UUID) (map[uuid.UUID][]string, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "notification_preference", "channels"}, time.Now())
+	result := map[uuid.UUID][]string{}
+	if len(identityIDs) == 0 {
+		return result, nil
+	}
+	rows, err := r.db.Model(&Preference{}).Select("identity_id, channel").Where("event_type = ? AND identity_id IN (?) AND NOT enabled", eventType, identityIDs).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to load the notification preferences"))
+	}
+	defer closeable.Close(ctx, rows)
+	disabled := map[uuid.UUID]map[string]bool{}
+	for rows.Next() {
+		var identityID uuid.UUID
+		var channel string
+		if err := rows.Scan(&identityID, &channel); err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to load the notification preferences"))
+		}
+		if disabled[identityID] == nil {
+			disabled[identityID] = map[string]bool{}
+		}
+		disabled[identityID][channel] = true
+	}
+	for _, identityID := range identityIDs {
+		for _, channel := range Channels {
+			if !disabled[identityID][channel] {
+				result[identityID] = append(result[identityID], channel)
+			}
+		}
+	}
+	return result, nil
+}
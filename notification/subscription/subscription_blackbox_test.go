@@ -0,0 +1,137 @@
+package subscription_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification/subscription"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type subscriptionSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestSubscription(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &subscriptionSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *subscriptionSuite) TestWatches() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2), tf.WorkItems(1))
+	repo := subscription.NewWatchRepository(s.DB)
+	alice, bob := fxt.Identities[0].ID, fxt.Identities[1].ID
+	spaceID := fxt.Spaces[0].ID
+
+	s.T().Run("watch", func(t *testing.T) {
+		// given
+		w := subscription.Watch{IdentityID: alice, TargetType: subscription.TargetWorkItem, TargetID: fxt.WorkItems[0].ID}
+		// when
+		require.NoError(t, repo.Watch(s.Ctx, &w))
+		// then
+		assert.NotEqual(t, uuid.Nil, w.ID)
+		assert.False(t, w.CreatedAt.IsZero())
+		// watching twice keeps the existing watch
+		again := subscription.Watch{IdentityID: alice, TargetType: subscription.TargetWorkItem, TargetID: fxt.WorkItems[0].ID}
+		require.NoError(t, repo.Watch(s.Ctx, &again))
+		assert.Equal(t, w.ID, again.ID)
+		require.NoError(t, repo.Watch(s.Ctx, &subscription.Watch{IdentityID: alice, TargetType: subscription.TargetSpace, TargetID: spaceID}))
+		require.NoError(t, repo.Watch(s.Ctx, &subscription.Watch{IdentityID: bob, TargetType: subscription.TargetSpace, TargetID: spaceID}))
+	})
+
+	s.T().Run("unknown target type", func(t *testing.T) {
+		err := repo.Watch(s.Ctx, &subscription.Watch{IdentityID: alice, TargetType: "labels", TargetID: uuid.NewV4()})
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("list", func(t *testing.T) {
+		// when
+		watches, err := repo.List(s.Ctx, alice)
+		// then
+		require.NoError(t, err)
+		require.Len(t, watches, 2)
+		assert.Equal(t, subscription.TargetSpace, watches[0].TargetType)
+		assert.Equal(t, subscription.TargetWorkItem, watches[1].TargetType)
+	})
+
+	s.T().Run("list watchers", func(t *testing.T) {
+		// when
+		watchers, err := repo.ListWatchers(s.Ctx, subscription.TargetSpace, spaceID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{alice, bob}, watchers)
+	})
+
+	s.T().Run("unwatch", func(t *testing.T) {
+		// when
+		require.NoError(t, repo.Unwatch(s.Ctx, bob, subscription.TargetSpace, spaceID))
+		// then
+		watchers, err := repo.ListWatchers(s.Ctx, subscription.TargetSpace, spaceID)
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{alice}, watchers)
+		err = repo.Unwatch(s.Ctx, bob, subscription.TargetSpace, spaceID)
+		require.IsType(t, errors.NotFoundError{}, err)
+	})
+}
+
+func (s *subscriptionSuite) TestPreferences() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(2))
+	repo := subscription.NewPreferenceRepository(s.DB)
+	alice, bob := fxt.Identities[0].ID, fxt.Identities[1].ID
+
+	s.T().Run("defaults", func(t *testing.T) {
+		// when
+		prefs, err := repo.List(s.Ctx, alice)
+		// then
+		require.NoError(t, err)
+		require.Len(t, prefs, len(subscription.EventTypes)*len(subscription.Channels))
+		for _, p := range prefs {
+			assert.True(t, p.Enabled, "%s on %s", p.EventType, p.Channel)
+		}
+	})
+
+	s.T().Run("save", func(t *testing.T) {
+		// when
+		require.NoError(t, repo.Save(s.Ctx,
+			subscription.Preference{IdentityID: alice, EventType: subscription.EventComment, Channel: subscription.ChannelEmail, Enabled: false},
+			subscription.Preference{IdentityID: bob, EventType: subscription.EventComment, Channel: subscription.ChannelEmail, Enabled: false},
+			subscription.Preference{IdentityID: bob, EventType: subscription.EventComment, Channel: subscription.ChannelWeb, Enabled: false},
+		))
+		// saving again updates the preference
+		require.NoError(t, repo.Save(s.Ctx, subscription.Preference{IdentityID: bob, EventType: subscription.EventComment, Channel: subscription.ChannelEmail, Enabled: true}))
+		// then
+		prefs, err := repo.List(s.Ctx, alice)
+		require.NoError(t, err)
+		for _, p := range prefs {
+			expected := !(p.EventType == subscription.EventComment && p.Channel == subscription.ChannelEmail)
+			assert.Equal(t, expected, p.Enabled, "%s on %s", p.EventType, p.Channel)
+		}
+	})
+
+	s.T().Run("invalid", func(t *testing.T) {
+		err := repo.Save(s.Ctx, subscription.Preference{IdentityID: alice, EventType: "deleted", Channel: subscription.ChannelEmail})
+		require.IsType(t, errors.BadParameterError{}, err)
+		err = repo.Save(s.Ctx, subscription.Preference{IdentityID: alice, EventType: subscription.EventComment, Channel: "sms"})
+		require.IsType(t, errors.BadParameterError{}, err)
+	})
+
+	s.T().Run("channels", func(t *testing.T) {
+		// when
+		channels, err := repo.Channels(s.Ctx, subscription.EventComment, alice, bob)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID][]string{
+			alice: {subscription.ChannelWeb},
+			bob:   {subscription.ChannelEmail},
+		}, channels)
+		channels, err = repo.Channels(s.Ctx, subscription.EventMentioned, alice)
+		require.NoError(t, err)
+		assert.Equal(t, []string{subscription.ChannelEmail, subscription.ChannelWeb}, channels[alice])
+	})
+}
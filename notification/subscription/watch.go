@@ -0,0 +1,136 @@
+package subscription
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+const watchTableName = "watches"
+
+// The kinds of things which can be watched. They match the JSONAPI types of
+// the targets.
+const (
+	TargetWorkItem  = "workitems"
+	TargetIteration = "iterations"
+	TargetSpace     = "spaces"
+)
+
+// TargetTypes lists all the kinds of things which can be watched
+var TargetTypes = []string{TargetWorkItem, TargetIteration, TargetSpace}
+
+// Watch means that an identity wants to be notified about the events
+// concerning a work item, an iteration or a space
+type Watch struct {
+	CreatedAt  time.Time
+	ID         uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	IdentityID uuid.UUID `sql:"type:uuid"`
+	TargetType string
+	TargetID   uuid.UUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (w Watch) TableName() string {
+	return watchTableName
+}
+
+// ValidateTargetType returns a BadParameterError unless the given type is one
+// of the TargetTypes
+func ValidateTargetType(targetType string) error {
+	for _, t := range TargetTypes {
+		if targetType == t {
+			return nil
+		}
+	}
+	return errors.NewBadParameterError("target type", targetType).Expected(strings.Join(TargetTypes, ", "))
+}
+
+// WatchRepository encapsulates storage & retrieval of watches
+type WatchRepository interface {
+	// Watch stores the watch unless the identity already watches the target
+	Watch(ctx context.Context, w *Watch) error
+	// Unwatch deletes the watch of the target by the identity
+	Unwatch(ctx context.Context, identityID uuid.UUID, targetType string, targetID uuid.UUID) error
+	// List returns the watches of an identity, most recent first
+	List(ctx context.Context, identityID uuid.UUID) ([]Watch, error)
+	// ListWatchers returns the identities watching the target
+	ListWatchers(ctx context.Context, targetType string, targetID uuid.UUID) ([]uuid.UUID, error)
+}
+
+// NewWatchRepository creates a GormWatchRepository
+func NewWatchRepository(db *gorm.DB) *GormWatchRepository {
+	return &GormWatchRepository{db: db}
+}
+
+// GormWatchRepository implements WatchRepository using gorm
+type GormWatchRepository struct {
+	db *gorm.DB
+}
+
+// Watch stores the watch unless the identity already watches the target. The
+// watch is updated with the stored one.
+func (r *GormWatchRepository) Watch(ctx context.Context, w *Watch) error {
+	defer goa.MeasureSince([]string{"goa", "db", "watch", "watch"}, time.Now())
+	if err := ValidateTargetType(w.TargetType); err != nil {
+		return err
+	}
+	db := r.db.Exec(`INSERT INTO `+watchTableName+` (created_at, identity_id, target_type, target_id)
+		VALUES (?, ?, ?, ?) ON CONFLICT DO NOTHING`,
+		time.Now(), w.IdentityID, w.TargetType, w.TargetID)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"identity_id": w.IdentityID,
+			"target_type": w.TargetType,
+			"target_id":   w.TargetID,
+			"err":         db.Error,
+		}, "unable to store the watch")
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to store the watch"))
+	}
+	// an existing watch keeps the time when it was created
+	db = r.db.Where("identity_id = ? AND target_type = ? AND target_id = ?", w.IdentityID, w.TargetType, w.TargetID).First(w)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to load the watch"))
+	}
+	return nil
+}
+
+// Unwatch deletes the watch of the target by the identity
+func (r *GormWatchRepository) Unwatch(ctx context.Context, identityID uuid.UUID, targetType string, targetID uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "watch", "unwatch"}, time.Now())
+	db := r.db.Where("identity_id = ? AND target_type = ? AND target_id = ?", identityID, targetType, targetID).Delete(&Watch{})
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to delete the watch"))
+	}
+	if db.RowsAffected == 0 {
+		return errors.NewNotFoundError("watch", targetID.String())
+	}
+	return nil
+}
+
+// List returns the watches of an identity, most recent first
+func (r *GormWatchRepository) List(ctx context.Context, identityID uuid.UUID) ([]Watch, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watch", "list"}, time.Now())
+	var watches []Watch
+	if err := r.db.Where("identity_id = ?", identityID).Order("created_at desc").Find(&watches).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the watches"))
+	}
+	return watches, nil
+}
+
+// ListWatchers returns the identities watching the target
+func (r *GormWatchRepository) ListWatchers(ctx context.Context, targetType string, targetID uuid.UUID) ([]uuid.UUID, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "watch", "list_watchers"}, time.Now())
+	var watchers []uuid.UUID
+	err := r.db.Model(&Watch{}).Where("target_type = ? AND target_id = ?", targetType, targetID).Order("created_at").Pluck("identity_id", &watchers).Error
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the watchers"))
+	}
+	return watchers, nil
+}
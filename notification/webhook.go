@@ -0,0 +1,134 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login"
+	"github.com/fabric8-services/fabric8-wit/models"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// WebhookChannel is a notification channel which stores a delivery in the
+// webhook outbox for every active webhook of the space in which an event
+// happened. The deliveries are sent later on by the webhook.Dispatcher. The
+// space and its webhooks are looked up in the background so that requests
+// don't wait for them.
+type WebhookChannel struct {
+	db *gorm.DB
+	wg sync.WaitGroup
+}
+
+var _ Channel = &WebhookChannel{}
+
+// NewWebhookChannel creates a notification channel feeding the webhook outbox
+func NewWebhookChannel(db *gorm.DB) *WebhookChannel {
+	return &WebhookChannel{db: db}
+}
+
+// Send enqueues a delivery of the message for each matching webhook in the
+// background. Errors are logged and not returned as the notification has no way
+// to report them.
+func (c *WebhookChannel) Send(ctx context.Context, msg Message) {
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		c.enqueue(ctx, msg)
+	}()
+}
+
+// Wait blocks until the deliveries of all sent messages are enqueued
+func (c *WebhookChannel) Wait() {
+	c.wg.Wait()
+}
+
+func (c *WebhookChannel) enqueue(ctx context.Context, msg Message) {
+	err := models.Transactional(c.db, func(tx *gorm.DB) error {
+		spaceID, err := resolveSpaceID(tx, msg)
+		if err != nil {
+			return err
+		}
+		hooks, err := webhook.NewRepository(tx).ListActive(ctx, spaceID, msg.MessageType)
+		if err != nil {
+			return err
+		}
+		if len(hooks) == 0 {
+			return nil
+		}
+		p := webhook.Payload{
+			ID:        msg.MessageID,
+			Event:     msg.MessageType,
+			SpaceID:   spaceID,
+			TargetID:  msg.TargetID,
+			UserID:    msg.UserID,
+			Timestamp: time.Now().UTC(),
+			Custom:    msg.Custom,
+		}
+		if p.UserID == nil {
+			if identityID, err := login.ContextIdentity(ctx); err == nil && identityID != nil {
+				id := identityID.String()
+				p.UserID = &id
+			}
+		}
+		body, err := json.Marshal(p)
+		if err != nil {
+			return errs.Wrap(err, "failed to marshal the webhook payload")
+		}
+		deliveries := webhook.NewDeliveryRepository(tx)
+		for _, h := range hooks {
+			err := deliveries.Enqueue(ctx, &webhook.Delivery{
+				WebhookID: h.ID,
+				MessageID: msg.MessageID,
+				EventType: msg.MessageType,
+				Payload:   string(body),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"message_id": msg.MessageID,
+			"type":       msg.MessageType,
+			"target_id":  msg.TargetID,
+			"err":        err,
+		}, "unable to enqueue webhook deliveries")
+	}
+}
+
+// resolveSpaceID looks up the space to which the target of the message
+// belongs
+func resolveSpaceID(db *gorm.DB, msg Message) (uuid.UUID, error) {
+	var query string
+	switch {
+	case msg.MessageType == webhook.EventWorkItemBulkUpdate:
+		// the target of a bulk update is the space itself
+		query = `SELECT id AS space_id FROM spaces WHERE id = ?`
+	case strings.HasPrefix(msg.MessageType, "workitem."):
+		query = `SELECT space_id FROM work_items WHERE id = ?`
+	case strings.HasPrefix(msg.MessageType, "comment."):
+		query = `SELECT wi.space_id FROM comments c JOIN work_items wi ON wi.id = c.parent_id WHERE c.id = ?`
+	default:
+		return uuid.Nil, errs.Errorf("unknown message type %s", msg.MessageType)
+	}
+	targetID, err := uuid.FromString(msg.TargetID)
+	if err != nil {
+		return uuid.Nil, errs.Wrapf(err, "invalid target ID %s", msg.TargetID)
+	}
+	var res struct {
+		SpaceID uuid.UUID
+	}
+	if err := db.Raw(query, targetID).Scan(&res).Error; err != nil {
+		return uuid.Nil, errs.Wrapf(err, "failed to find the space of %s %s", msg.MessageType, msg.TargetID)
+	}
+	return res.SpaceID, nil
+}
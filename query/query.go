@@ -13,6 +13,8 @@ import (
 	"github.com/fabric8-services/fabric8-wit/gormsupport"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/search"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	uuid "github.com/satori/go.uuid"
@@ -89,8 +91,13 @@ func (r *GormQueryRepository) Create(ctx context.Context, q *Query) error {
 	if err := json.Unmarshal([]byte(q.Fields), &v); err != nil {
 		return errors.NewBadParameterError("query field is invalid JSON syntax", q.Fields).Expected("valid JSON")
 	}
+	s, err := space.NewRepository(r.db).Load(ctx, q.SpaceID)
+	if err != nil {
+		return err
+	}
 	// Parse fields to make sure that query is valid
-	exp, _, err := search.ParseFilterString(ctx, q.Fields)
+	fields := workitem.SpaceTemplateFields{Types: workitem.NewWorkItemTypeRepository(r.db), SpaceTemplateID: s.SpaceTemplateID}
+	exp, _, err := search.ParseFilterString(ctx, q.Fields, fields)
 	if err != nil || exp == nil {
 		log.Error(ctx, map[string]interface{}{
 			"space_id": q.SpaceID,
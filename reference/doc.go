@@ -0,0 +1,5 @@
+// Package reference stores the @mentions of users and the #references to work
+// items in the markup of work item descriptions and comments, so that the
+// markup can be rendered with links and work items can list where they are
+// mentioned.
+package reference
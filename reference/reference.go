@@ -0,0 +1,58 @@
+package reference
+
+import (
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/id"
+	uuid "github.com/satori/go.uuid"
+)
+
+const tableName = "markup_references"
+
+// Source is the markup in which references are made: either the description
+// of a work item or one of its comments
+type Source struct {
+	WorkItemID uuid.UUID
+	// CommentID is only valid for a comment
+	CommentID id.NullUUID
+}
+
+// Description returns the source for the description of the work item
+func Description(workItemID uuid.UUID) Source {
+	return Source{WorkItemID: workItemID}
+}
+
+// Comment returns the source for the comment on the work item
+func Comment(workItemID, commentID uuid.UUID) Source {
+	return Source{WorkItemID: workItemID, CommentID: id.NullUUID{UUID: commentID, Valid: true}}
+}
+
+// Reference is a resolved @mention of a user or #reference to a work item.
+// Exactly one of the targets is valid.
+type Reference struct {
+	CreatedAt        time.Time
+	ID               uuid.UUID   `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"`
+	SourceWorkItemID uuid.UUID   `sql:"type:uuid"`
+	SourceCommentID  id.NullUUID `sql:"type:uuid"`
+	Text             string
+	TargetIdentityID id.NullUUID `sql:"type:uuid"`
+	TargetWorkItemID id.NullUUID `sql:"type:uuid"`
+}
+
+// TableName implements gorm.tabler
+func (r Reference) TableName() string {
+	return tableName
+}
+
+// Source returns the markup in which the reference is made
+func (r Reference) Source() Source {
+	return Source{WorkItemID: r.SourceWorkItemID, CommentID: r.SourceCommentID}
+}
+
+// target returns the ID of the mentioned identity or referenced work item
+func (r Reference) target() uuid.UUID {
+	if r.TargetIdentityID.Valid {
+		return r.TargetIdentityID.UUID
+	}
+	return r.TargetWorkItemID.UUID
+}
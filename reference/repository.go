@@ -0,0 +1,211 @@
+package reference
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Repository encapsulates storage & retrieval of the references in markup
+type Repository interface {
+	// Update replaces the references of the source with the resolved
+	// references in the given content. Work item numbers without a space
+	// refer to the given space. Returns the references whose targets the
+	// source didn't reference before.
+	Update(ctx context.Context, source Source, spaceID uuid.UUID, content, markup string) ([]Reference, error)
+	// ListByDescription returns the references in the descriptions of the
+	// given work items
+	ListByDescription(ctx context.Context, workItemIDs ...uuid.UUID) ([]Reference, error)
+	// ListByComments returns the references in the given comments
+	ListByComments(ctx context.Context, commentIDs ...uuid.UUID) ([]Reference, error)
+	// ListBacklinks returns the references to the given work item, one per
+	// description or comment which isn't deleted, most recent first
+	ListBacklinks(ctx context.Context, workItemID uuid.UUID) ([]Reference, error)
+}
+
+// NewRepository creates a GormRepository
+func NewRepository(db *gorm.DB) *GormRepository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository implements Repository using gorm
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Update replaces the references of the source with the resolved references in
+// the given content. References which don't resolve are ignored.
+func (r *GormRepository) Update(ctx context.Context, source Source, spaceID uuid.UUID, content, markup string) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "markup_reference", "update"}, time.Now())
+	references, err := r.resolve(ctx, spaceID, rendering.FindReferences(content, markup))
+	if err != nil {
+		return nil, err
+	}
+	var existing []Reference
+	if err := r.whereSource(source).Find(&existing).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to load the references"))
+	}
+	if err := r.whereSource(source).Delete(&Reference{}).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to delete the references"))
+	}
+	referenced := map[uuid.UUID]bool{}
+	for _, ref := range existing {
+		referenced[ref.target()] = true
+	}
+	var added []Reference
+	for i := range references {
+		ref := &references[i]
+		ref.SourceWorkItemID = source.WorkItemID
+		ref.SourceCommentID = source.CommentID
+		if err := r.db.Create(ref).Error; err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"work_item_id": source.WorkItemID,
+				"comment_id":   source.CommentID.UUID,
+				"text":         ref.Text,
+				"err":          err,
+			}, "unable to store the reference")
+			return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to store the reference"))
+		}
+		if !referenced[ref.target()] {
+			referenced[ref.target()] = true
+			added = append(added, *ref)
+		}
+	}
+	return added, nil
+}
+
+// whereSource restricts a query to the references of the source
+func (r *GormRepository) whereSource(source Source) *gorm.DB {
+	if source.CommentID.Valid {
+		return r.db.Where("source_work_item_id = ? AND source_comment_id = ?", source.WorkItemID, source.CommentID.UUID)
+	}
+	return r.db.Where("source_work_item_id = ? AND source_comment_id IS NULL", source.WorkItemID)
+}
+
+// resolve looks up the mentioned users and referenced work items and returns
+// the references to the ones which exist
+func (r *GormRepository) resolve(ctx context.Context, spaceID uuid.UUID, refs []rendering.Reference) ([]Reference, error) {
+	identities := account.NewIdentityRepository(r.db)
+	workItems := workitem.NewWorkItemRepository(r.db)
+	// the names of the space are only needed for references to other spaces
+	var ownerName string
+	spaceOwnerName := func() (string, error) {
+		if ownerName != "" {
+			return ownerName, nil
+		}
+		s, err := space.NewRepository(r.db).Load(ctx, spaceID)
+		if err != nil {
+			return "", err
+		}
+		owner, err := identities.Load(ctx, s.OwnerID)
+		if err != nil {
+			return "", err
+		}
+		ownerName = owner.Username
+		return ownerName, nil
+	}
+	var result []Reference
+	for _, ref := range refs {
+		var target uuid.UUID
+		var err error
+		switch {
+		case ref.Kind == rendering.ReferenceUser:
+			var found []account.Identity
+			found, err = identities.Query(account.IdentityFilterByUsername(ref.Username))
+			if err == nil && len(found) == 0 {
+				continue
+			}
+			if err == nil {
+				target = found[0].ID
+			}
+		case ref.SpaceName == "":
+			var wi *workitem.WorkItem
+			wi, err = workItems.Load(ctx, spaceID, ref.Number)
+			if err == nil {
+				target = wi.ID
+			}
+		default:
+			owner := ref.OwnerName
+			if owner == "" {
+				owner, err = spaceOwnerName()
+			}
+			if err == nil {
+				var wiID *uuid.UUID
+				wiID, _, err = workItems.LookupIDByNamedSpaceAndNumber(ctx, owner, ref.SpaceName, ref.Number)
+				if err == nil {
+					target = *wiID
+				}
+			}
+		}
+		if ok, _ := errors.IsNotFoundError(err); ok {
+			continue
+		}
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to resolve the reference %s", ref.Text)
+		}
+		resolved := Reference{Text: ref.Text}
+		if ref.Kind == rendering.ReferenceUser {
+			resolved.TargetIdentityID = id.NullUUID{UUID: target, Valid: true}
+		} else {
+			resolved.TargetWorkItemID = id.NullUUID{UUID: target, Valid: true}
+		}
+		result = append(result, resolved)
+	}
+	return result, nil
+}
+
+// ListByDescription returns the references in the descriptions of the given
+// work items
+func (r *GormRepository) ListByDescription(ctx context.Context, workItemIDs ...uuid.UUID) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "markup_reference", "list_by_description"}, time.Now())
+	var result []Reference
+	if len(workItemIDs) == 0 {
+		return result, nil
+	}
+	if err := r.db.Where("source_work_item_id IN (?) AND source_comment_id IS NULL", workItemIDs).Find(&result).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the references"))
+	}
+	return result, nil
+}
+
+// ListByComments returns the references in the given comments
+func (r *GormRepository) ListByComments(ctx context.Context, commentIDs ...uuid.UUID) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "markup_reference", "list_by_comments"}, time.Now())
+	var result []Reference
+	if len(commentIDs) == 0 {
+		return result, nil
+	}
+	if err := r.db.Where("source_comment_id IN (?)", commentIDs).Find(&result).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to list the references"))
+	}
+	return result, nil
+}
+
+// ListBacklinks returns the references to the given work item, one per
+// description or comment which isn't deleted, most recent first
+func (r *GormRepository) ListBacklinks(ctx context.Context, workItemID uuid.UUID) ([]Reference, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "markup_reference", "list_backlinks"}, time.Now())
+	var result []Reference
+	db := r.db.Raw(`SELECT * FROM (
+			SELECT DISTINCT ON (r.source_work_item_id, r.source_comment_id) r.* FROM `+tableName+` r
+			JOIN work_items wi ON wi.id = r.source_work_item_id AND wi.deleted_at IS NULL
+			LEFT JOIN comments c ON c.id = r.source_comment_id
+			WHERE r.target_work_item_id = ? AND c.deleted_at IS NULL
+			ORDER BY r.source_work_item_id, r.source_comment_id, r.created_at
+		) backlinks ORDER BY created_at DESC`, workItemID).Scan(&result)
+	if db.Error != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to list the backlinks"))
+	}
+	return result, nil
+}
@@ -0,0 +1,125 @@
+package reference_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/reference"
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type repositorySuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &repositorySuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// texts returns the texts of the references
+func texts(refs []reference.Reference) []string {
+	result := []string{}
+	for _, r := range refs {
+		result = append(result, r.Text)
+	}
+	return result
+}
+
+func (s *repositorySuite) TestUpdateAndList() {
+	suffix := uuid.NewV4().String()
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("alice-"+suffix, "bob-"+suffix)),
+		tf.Spaces(2, func(fxt *tf.TestFixture, idx int) error {
+			fxt.Spaces[idx].Name = fmt.Sprintf("space%d-%s", idx, suffix)
+			return nil
+		}),
+		tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			if idx == 2 {
+				fxt.WorkItems[idx].SpaceID = fxt.Spaces[1].ID
+			}
+			return nil
+		}),
+		tf.Comments(1),
+	)
+	repo := reference.NewRepository(s.DB)
+	wi0, wi1, wi2 := fxt.WorkItems[0], fxt.WorkItems[1], fxt.WorkItems[2]
+	alice, bob := fxt.Identities[0], fxt.Identities[1]
+	description := reference.Description(wi0.ID)
+
+	s.T().Run("update", func(t *testing.T) {
+		// when
+		content := fmt.Sprintf("@%s fixed #%d and %s#%d, not #999999 nor @nobody-%s",
+			alice.Username, wi1.Number, fxt.Spaces[1].Name, wi2.Number, suffix)
+		added, err := repo.Update(s.Ctx, description, wi0.SpaceID, content, rendering.SystemMarkupMarkdown)
+		// then
+		require.NoError(t, err)
+		require.Len(t, added, 3)
+		assert.Equal(t, alice.ID, added[0].TargetIdentityID.UUID)
+		assert.False(t, added[0].TargetWorkItemID.Valid)
+		assert.Equal(t, wi1.ID, added[1].TargetWorkItemID.UUID)
+		assert.Equal(t, wi2.ID, added[2].TargetWorkItemID.UUID)
+		assert.Equal(t, description, added[2].Source())
+	})
+
+	s.T().Run("update returns the new targets only", func(t *testing.T) {
+		// when
+		content := fmt.Sprintf("@%s and @%s fixed #%d", alice.Username, bob.Username, wi1.Number)
+		added, err := repo.Update(s.Ctx, description, wi0.SpaceID, content, rendering.SystemMarkupPlainText)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"@" + bob.Username}, texts(added))
+		refs, err := repo.ListByDescription(s.Ctx, wi0.ID)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"@" + alice.Username, "@" + bob.Username, fmt.Sprintf("#%d", wi1.Number)}, texts(refs))
+	})
+
+	s.T().Run("comments", func(t *testing.T) {
+		// when
+		content := fmt.Sprintf("see %s/#%d", fxt.Spaces[0].Name, wi1.Number)
+		added, err := repo.Update(s.Ctx, reference.Comment(wi0.ID, fxt.Comments[0].ID), wi0.SpaceID, content, rendering.SystemMarkupMarkdown)
+		// then
+		require.NoError(t, err)
+		require.Len(t, added, 1)
+		refs, err := repo.ListByComments(s.Ctx, fxt.Comments[0].ID)
+		require.NoError(t, err)
+		require.Len(t, refs, 1)
+		assert.Equal(t, wi1.ID, refs[0].TargetWorkItemID.UUID)
+		assert.Equal(t, fxt.Comments[0].ID, refs[0].SourceCommentID.UUID)
+		// the description keeps its references
+		refs, err = repo.ListByDescription(s.Ctx, wi0.ID)
+		require.NoError(t, err)
+		assert.Len(t, refs, 3)
+	})
+
+	s.T().Run("backlinks", func(t *testing.T) {
+		// when
+		refs, err := repo.ListBacklinks(s.Ctx, wi1.ID)
+		// then
+		require.NoError(t, err)
+		require.Len(t, refs, 2)
+		assert.Equal(t, fxt.Comments[0].ID, refs[0].SourceCommentID.UUID)
+		assert.Equal(t, description, refs[1].Source())
+		// the work item in the other space is no longer referenced
+		refs, err = repo.ListBacklinks(s.Ctx, wi2.ID)
+		require.NoError(t, err)
+		assert.Empty(t, refs)
+	})
+
+	s.T().Run("backlinks of deleted comments", func(t *testing.T) {
+		// when
+		require.NoError(t, s.GormDB.Comments().Delete(s.Ctx, fxt.Comments[0].ID, alice.ID))
+		// then
+		refs, err := repo.ListBacklinks(s.Ctx, wi1.ID)
+		require.NoError(t, err)
+		require.Len(t, refs, 1)
+		assert.Equal(t, description, refs[0].Source())
+	})
+}
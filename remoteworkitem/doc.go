@@ -1,3 +1,3 @@
 // Package remoteworkitem contains all the code that tracks the work items created
-// in remote systems such as jira, github.
+// in remote systems such as jira, github, gitlab.
 package remoteworkitem
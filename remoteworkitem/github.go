@@ -2,6 +2,9 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/fabric8-services/fabric8-wit/log"
 
@@ -18,6 +21,9 @@ type githubFetcher interface {
 type GithubTracker struct {
 	URL   string
 	Query string
+	// Since restricts the fetch to the issues updated since then
+	Since *time.Time
+	err   error
 }
 
 // GithubIssueFetcher fetch issues from github
@@ -41,22 +47,44 @@ func (g *GithubTracker) Fetch(githubAuthToken string) chan TrackerItemContent {
 	return g.fetch(&f)
 }
 
+// Err returns the error that ended the last fetch early
+func (g *GithubTracker) Err() error {
+	return g.err
+}
+
 func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
+	g.err = nil
 	go func() {
+		defer close(item)
+		query := g.Query
+		if g.Since != nil {
+			query = fmt.Sprintf("%s updated:>=%s", query, g.Since.UTC().Format(time.RFC3339))
+		}
 		opts := &github.SearchOptions{
 			ListOptions: github.ListOptions{
 				PerPage: 20,
 			},
 		}
 		for {
-			result, response, err := f.listIssues(g.Query, opts)
-			if _, ok := err.(*github.RateLimitError); ok {
-				log.Warn(nil, map[string]interface{}{
-					"query": g.Query,
+			var result *github.IssuesSearchResult
+			var response *github.Response
+			err := retryOnRateLimit(map[string]interface{}{"query": query, "opts": opts}, func() (bool, time.Duration, error) {
+				var err error
+				result, response, err = f.listIssues(query, opts)
+				if e, ok := err.(*github.RateLimitError); ok {
+					return true, e.Rate.Reset.Time.Sub(time.Now()), err
+				}
+				return false, 0, err
+			})
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"err":   err,
+					"query": query,
 					"opts":  opts,
-				}, "reached rate limit when listing Github issues")
-				break
+				}, "unable to list Github issues")
+				g.err = err
+				return
 			}
 			issues := result.Issues
 			for _, l := range issues {
@@ -65,11 +93,57 @@ func (g *GithubTracker) fetch(f githubFetcher) chan TrackerItemContent {
 				item <- TrackerItemContent{ID: string(id), Content: content}
 			}
 			if response.NextPage == 0 {
-				break
+				return
 			}
 			opts.ListOptions.Page = response.NextPage
+			// respect the X-RateLimit-* headers before asking for the next page
+			if response.Rate.Remaining == 0 && !response.Rate.Reset.Time.IsZero() {
+				wait := boundedRateLimitWait(response.Rate.Reset.Time.Sub(time.Now()))
+				log.Warn(nil, map[string]interface{}{
+					"query": query,
+					"wait":  wait.String(),
+				}, "Github rate limit exhausted, waiting for the reset")
+				sleep(wait)
+			}
 		}
-		close(item)
 	}()
 	return item
 }
+
+// githubPusher writes work item changes back to GitHub issues
+type githubPusher struct {
+	client *http.Client
+	token  string
+}
+
+// Push updates the title, state and assignees of the GitHub issue
+func (p *githubPusher) Push(item AttributeAccessor, changes RemoteChanges) (*time.Time, error) {
+	issueURL, _ := item.Get(GithubID).(string)
+	if issueURL == "" {
+		return nil, BadParameterError{parameter: GithubID, value: item.Get(GithubID)}
+	}
+	body := map[string]interface{}{}
+	if changes.Title != nil {
+		body["title"] = *changes.Title
+	}
+	if changes.State != nil {
+		body["state"] = "open"
+		if isClosedState(*changes.State) {
+			body["state"] = "closed"
+		}
+	}
+	if changes.Assignees != nil {
+		body["assignees"] = changes.Assignees
+	}
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("Authorization", "token "+p.token)
+	}
+	var issue struct {
+		UpdatedAt *time.Time `json:"updated_at"`
+	}
+	if err := doJSON(p.client, "PATCH", issueURL, header, body, &issue); err != nil {
+		return nil, err
+	}
+	return issue.UpdatedAt, nil
+}
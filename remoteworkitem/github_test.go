@@ -43,10 +43,13 @@ func TestGithubFetch(t *testing.T) {
 
 }
 
-type fakeGithubIssueFetcherWithRateLimit struct{}
+type fakeGithubIssueFetcherWithRateLimit struct {
+	calls int
+}
 
 // ListIssues list all issues
 func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	f.calls++
 	isr := &github.IssuesSearchResult{}
 	r := &github.Response{}
 	r.NextPage = 0
@@ -57,12 +60,69 @@ func (f *fakeGithubIssueFetcherWithRateLimit) listIssues(query string, opts *git
 func TestGithubFetchWithRateLimit(t *testing.T) {
 	// given
 	resource.Require(t, resource.UnitTest)
+	var waits []time.Duration
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
 	f := fakeGithubIssueFetcherWithRateLimit{}
 	g := GithubTracker{URL: "", Query: ""}
 	// when
 	fetch := g.fetch(&f)
 	// then
-	assert.Equal(t, 0, len(fetch))
+	_, ok := <-fetch
+	assert.False(t, ok)
+	assert.Equal(t, maxRateLimitRetries+1, f.calls)
+	require.Len(t, waits, maxRateLimitRetries)
+	assert.Equal(t, defaultRateLimitWait, waits[0])
+	assert.Error(t, g.Err())
+}
+
+type fakeGithubIssueFetcherWithReset struct {
+	queries []string
+	reset   time.Time
+}
+
+// ListIssues list all issues
+func (f *fakeGithubIssueFetcherWithReset) listIssues(query string, opts *github.SearchOptions) (*github.IssuesSearchResult, *github.Response, error) {
+	f.queries = append(f.queries, query)
+	switch len(f.queries) {
+	case 1:
+		// rate limit reached, retry after the reset
+		return nil, nil, &github.RateLimitError{Rate: github.Rate{Reset: github.Timestamp{Time: f.reset}}, Message: "rate limit"}
+	case 2:
+		// first page, the rate limit is exhausted again
+		one := 1
+		r := &github.Response{NextPage: 2}
+		r.Rate = github.Rate{Remaining: 0, Reset: github.Timestamp{Time: f.reset}}
+		return &github.IssuesSearchResult{Issues: []github.Issue{{ID: &one}}}, r, nil
+	default:
+		two := 2
+		return &github.IssuesSearchResult{Issues: []github.Issue{{ID: &two}}}, &github.Response{}, nil
+	}
+}
+
+func TestGithubFetchBacksOffUntilReset(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	var waits []time.Duration
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	since := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	f := fakeGithubIssueFetcherWithReset{reset: time.Now().Add(10 * time.Minute)}
+	g := GithubTracker{URL: "", Query: "is:issue", Since: &since}
+	// when
+	var items []TrackerItemContent
+	for i := range g.fetch(&f) {
+		items = append(items, i)
+	}
+	// then
+	require.NoError(t, g.Err())
+	require.Len(t, items, 2)
+	require.Len(t, waits, 2)
+	for _, w := range waits {
+		assert.True(t, w > 9*time.Minute && w <= 10*time.Minute, "unexpected wait: %s", w)
+	}
+	require.Len(t, f.queries, 3)
+	assert.Equal(t, "is:issue updated:>=2017-08-10T07:00:00Z", f.queries[0])
 }
 
 func TestGithubFetchWithRecording(t *testing.T) {
@@ -0,0 +1,256 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+
+	"github.com/pkg/errors"
+)
+
+const gitlabIssuesPerPage = 20
+
+// gitlabFetcher provides issue listing
+type gitlabFetcher interface {
+	// listIssues returns the issues of the given page together with the number
+	// of the next page, which is 0 if there are no more pages.
+	listIssues(project string, params url.Values, page int) ([]json.RawMessage, int, error)
+}
+
+// GitlabTracker represents the GitLab tracker provider. The query is the path
+// of the project (e.g. "group/project"), optionally followed by the parameters
+// of the GitLab issues API (e.g. "group/project?state=opened&labels=bug").
+type GitlabTracker struct {
+	URL   string
+	Query string
+	// Since restricts the fetch to the issues updated since then
+	Since *time.Time
+	err   error
+}
+
+// gitlabRateLimitError is returned when GitLab rejects a request because the
+// rate limit was reached
+type gitlabRateLimitError struct {
+	retryAfter time.Duration
+}
+
+func (err gitlabRateLimitError) Error() string {
+	return fmt.Sprintf("GitLab rate limit reached, retry after %s", err.retryAfter)
+}
+
+// gitlabIssueFetcher fetch issues from the GitLab REST API (v4)
+type gitlabIssueFetcher struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// listIssues list the issues of a project
+func (f *gitlabIssueFetcher) listIssues(project string, params url.Values, page int) ([]json.RawMessage, int, error) {
+	q := url.Values{}
+	for k, v := range params {
+		q[k] = v
+	}
+	q.Set("per_page", strconv.Itoa(gitlabIssuesPerPage))
+	if page > 0 {
+		q.Set("page", strconv.Itoa(page))
+	}
+	issuesURL := fmt.Sprintf("%s/api/v4/projects/%s/issues?%s", strings.TrimSuffix(f.baseURL, "/"), url.PathEscape(project), q.Encode())
+	req, err := http.NewRequest("GET", issuesURL, nil)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	if f.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", f.token)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, 0, gitlabRateLimitError{retryAfter: retryAfter(resp.Header)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, errors.Errorf("unexpected response status when listing GitLab issues of project '%s': %s", project, resp.Status)
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, errors.WithStack(err)
+	}
+	var issues []json.RawMessage
+	if err := json.Unmarshal(body, &issues); err != nil {
+		return nil, 0, errors.Wrapf(err, "failed to decode the GitLab issues of project '%s'", project)
+	}
+	nextPage := 0
+	if next := resp.Header.Get("X-Next-Page"); next != "" {
+		nextPage, err = strconv.Atoi(next)
+		if err != nil {
+			return nil, 0, errors.Wrapf(err, "invalid X-Next-Page header: '%s'", next)
+		}
+	}
+	return issues, nextPage, nil
+}
+
+// Fetch tracker items from GitLab
+func (g *GitlabTracker) Fetch(gitlabAuthToken string) chan TrackerItemContent {
+	f := gitlabIssueFetcher{
+		client:  &http.Client{Timeout: 30 * time.Second},
+		baseURL: g.URL,
+		token:   gitlabAuthToken,
+	}
+	return g.fetch(&f)
+}
+
+// Err returns the error that ended the last fetch early
+func (g *GitlabTracker) Err() error {
+	return g.err
+}
+
+func (g *GitlabTracker) fetch(f gitlabFetcher) chan TrackerItemContent {
+	item := make(chan TrackerItemContent)
+	g.err = nil
+	go func() {
+		defer close(item)
+		project, params, err := parseGitlabQuery(g.Query)
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err":   err,
+				"query": g.Query,
+			}, "invalid GitLab tracker query")
+			g.err = err
+			return
+		}
+		if g.Since != nil {
+			params.Set("updated_after", g.Since.UTC().Format(time.RFC3339))
+		}
+		page := 0
+		for {
+			var issues []json.RawMessage
+			var nextPage int
+			err := retryOnRateLimit(map[string]interface{}{"query": g.Query, "page": page}, func() (bool, time.Duration, error) {
+				var err error
+				issues, nextPage, err = f.listIssues(project, params, page)
+				if e, ok := err.(gitlabRateLimitError); ok {
+					return true, e.retryAfter, err
+				}
+				return false, 0, err
+			})
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"err":   err,
+					"query": g.Query,
+					"page":  page,
+				}, "unable to list GitLab issues")
+				g.err = err
+				return
+			}
+			for _, l := range issues {
+				var issue struct {
+					WebURL string `json:"web_url"`
+				}
+				if err := json.Unmarshal(l, &issue); err != nil {
+					log.Error(nil, map[string]interface{}{
+						"err": err,
+					}, "unable to decode GitLab issue")
+					continue
+				}
+				id, _ := json.Marshal(issue.WebURL)
+				item <- TrackerItemContent{ID: string(id), Content: l}
+			}
+			if nextPage == 0 {
+				return
+			}
+			page = nextPage
+		}
+	}()
+	return item
+}
+
+// parseGitlabQuery splits a GitLab tracker query into the project path and the
+// issues API parameters
+func parseGitlabQuery(query string) (string, url.Values, error) {
+	parts := strings.SplitN(strings.TrimSpace(query), "?", 2)
+	project := strings.Trim(parts[0], "/")
+	if project == "" {
+		return "", nil, BadParameterError{parameter: "query", value: query}
+	}
+	params := url.Values{}
+	if len(parts) == 2 {
+		var err error
+		params, err = url.ParseQuery(parts[1])
+		if err != nil {
+			return "", nil, BadParameterError{parameter: "query", value: query}
+		}
+	}
+	return project, params, nil
+}
+
+// gitlabPusher writes work item changes back to GitLab issues
+type gitlabPusher struct {
+	client  *http.Client
+	baseURL string
+	token   string
+}
+
+// Push updates the title, state and assignees of the GitLab issue
+func (p *gitlabPusher) Push(item AttributeAccessor, changes RemoteChanges) (*time.Time, error) {
+	projectID, ok := item.Get(GitlabProjectID).(float64)
+	if !ok {
+		return nil, BadParameterError{parameter: GitlabProjectID, value: item.Get(GitlabProjectID)}
+	}
+	iid, ok := item.Get(GitlabIID).(float64)
+	if !ok {
+		return nil, BadParameterError{parameter: GitlabIID, value: item.Get(GitlabIID)}
+	}
+	apiURL := strings.TrimSuffix(p.baseURL, "/") + "/api/v4"
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("PRIVATE-TOKEN", p.token)
+	}
+	body := map[string]interface{}{}
+	if changes.Title != nil {
+		body["title"] = *changes.Title
+	}
+	if changes.State != nil {
+		closed := item.Get(GitlabState) == "closed"
+		if isClosedState(*changes.State) && !closed {
+			body["state_event"] = "close"
+		} else if !isClosedState(*changes.State) && closed {
+			body["state_event"] = "reopen"
+		}
+	}
+	if changes.Assignees != nil {
+		// GitLab only accepts user IDs for assignees
+		assigneeIDs := []int{}
+		for _, login := range changes.Assignees {
+			var users []struct {
+				ID int `json:"id"`
+			}
+			usersURL := fmt.Sprintf("%s/users?username=%s", apiURL, url.QueryEscape(login))
+			if err := doJSON(p.client, "GET", usersURL, header, nil, &users); err != nil {
+				return nil, err
+			}
+			if len(users) == 0 {
+				return nil, NotFoundError{entity: "GitLab user", ID: login}
+			}
+			assigneeIDs = append(assigneeIDs, users[0].ID)
+		}
+		body["assignee_ids"] = assigneeIDs
+	}
+	var issue struct {
+		UpdatedAt *time.Time `json:"updated_at"`
+	}
+	issueURL := fmt.Sprintf("%s/projects/%d/issues/%d", apiURL, int64(projectID), int64(iid))
+	if err := doJSON(p.client, "PUT", issueURL, header, body, &issue); err != nil {
+		return nil, err
+	}
+	return issue.UpdatedAt, nil
+}
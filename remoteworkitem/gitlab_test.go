@@ -0,0 +1,219 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGitlabIssueFetcher struct {
+	project string
+	params  url.Values
+}
+
+// listIssues list all issues
+func (f *fakeGitlabIssueFetcher) listIssues(project string, params url.Values, page int) ([]json.RawMessage, int, error) {
+	f.project = project
+	f.params = params
+	if page == 0 {
+		return []json.RawMessage{json.RawMessage(`{"id":1,"web_url":"https://gitlab.example.com/group/project/issues/1"}`)}, 2, nil
+	}
+	return []json.RawMessage{json.RawMessage(`{"id":2,"web_url":"https://gitlab.example.com/group/project/issues/2"}`)}, 0, nil
+}
+
+func TestGitlabFetch(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	f := fakeGitlabIssueFetcher{}
+	g := GitlabTracker{URL: "https://gitlab.example.com", Query: "group/project?state=opened"}
+	// when
+	var items []TrackerItemContent
+	for i := range g.fetch(&f) {
+		items = append(items, i)
+	}
+	// then
+	require.Len(t, items, 2)
+	assert.Equal(t, `"https://gitlab.example.com/group/project/issues/1"`, items[0].ID)
+	assert.Equal(t, `{"id":1,"web_url":"https://gitlab.example.com/group/project/issues/1"}`, string(items[0].Content))
+	assert.Equal(t, `"https://gitlab.example.com/group/project/issues/2"`, items[1].ID)
+	assert.Equal(t, "group/project", f.project)
+	assert.Equal(t, "opened", f.params.Get("state"))
+}
+
+type fakeGitlabIssueFetcherWithRateLimit struct {
+	calls  int
+	params []url.Values
+}
+
+// listIssues list all issues
+func (f *fakeGitlabIssueFetcherWithRateLimit) listIssues(project string, params url.Values, page int) ([]json.RawMessage, int, error) {
+	f.calls++
+	f.params = append(f.params, params)
+	return nil, 0, gitlabRateLimitError{retryAfter: 60 * time.Second}
+}
+
+func TestGitlabFetchWithRateLimit(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	var waits []time.Duration
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	since := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	f := fakeGitlabIssueFetcherWithRateLimit{}
+	g := GitlabTracker{URL: "https://gitlab.example.com", Query: "group/project", Since: &since}
+	// when
+	_, ok := <-g.fetch(&f)
+	// then
+	assert.False(t, ok)
+	assert.Equal(t, maxRateLimitRetries+1, f.calls)
+	require.Len(t, waits, maxRateLimitRetries)
+	assert.Equal(t, 60*time.Second, waits[0])
+	assert.Equal(t, "2017-08-10T07:00:00Z", f.params[0].Get("updated_after"))
+	assert.IsType(t, gitlabRateLimitError{}, errors.Cause(g.Err()))
+}
+
+func TestGitlabFetchFromServer(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given a stand-in for the GitLab issues API with two pages
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/group%2Fproject/issues" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("PRIVATE-TOKEN") != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "opened", r.URL.Query().Get("state"))
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("X-Next-Page", "2")
+			fmt.Fprint(w, `[{"iid":1,"title":"first","web_url":"http://gitlab/group/project/issues/1"}]`)
+		case "2":
+			w.Header().Set("X-Next-Page", "")
+			fmt.Fprint(w, `[{"iid":2,"title":"second","web_url":"http://gitlab/group/project/issues/2"}]`)
+		default:
+			t.Errorf("unexpected page: %s", r.URL.Query().Get("page"))
+		}
+	}))
+	defer ts.Close()
+	g := &GitlabTracker{URL: ts.URL, Query: "group/project?state=opened"}
+
+	t.Run("all pages", func(t *testing.T) {
+		// when
+		var items []TrackerItemContent
+		for i := range g.Fetch("secret") {
+			items = append(items, i)
+		}
+		// then
+		require.Len(t, items, 2)
+		assert.Equal(t, `"http://gitlab/group/project/issues/1"`, items[0].ID)
+		assert.Contains(t, string(items[0].Content), `"title":"first"`)
+		assert.Equal(t, `"http://gitlab/group/project/issues/2"`, items[1].ID)
+		assert.Contains(t, string(items[1].Content), `"title":"second"`)
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		// when
+		_, ok := <-g.Fetch("wrong")
+		// then
+		assert.False(t, ok)
+	})
+}
+
+func TestGitlabIssueMapping(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	content := `{
+		"iid": 3,
+		"title": "some title",
+		"description": "some *description*",
+		"state": "opened",
+		"web_url": "https://gitlab.example.com/group/project/issues/3",
+		"author": {"username": "jdoe", "web_url": "https://gitlab.example.com/jdoe"},
+		"assignees": [
+			{"username": "alice", "web_url": "https://gitlab.example.com/alice"},
+			{"username": "bob", "web_url": "https://gitlab.example.com/bob"}
+		],
+		"labels": ["bug", "backend"]
+	}`
+	trackerItem := TrackerItem{Item: content, RemoteItemID: "xyz", TrackerID: uuid.NewV4()}
+	issue, err := RemoteWorkItemImplRegistry[ProviderGitlab](trackerItem)
+	require.NoError(t, err)
+	// when
+	wi, err := Map(issue, RemoteWorkItemKeyMaps[ProviderGitlab])
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, "some title", wi.Fields[workitem.SystemTitle])
+	assert.Equal(t, rendering.NewMarkupContent("some *description*", rendering.SystemMarkupMarkdown), wi.Fields[workitem.SystemDescription])
+	assert.Equal(t, workitem.SystemStateOpen, wi.Fields[workitem.SystemState])
+	assert.Equal(t, "https://gitlab.example.com/group/project/issues/3", wi.Fields[workitem.SystemRemoteItemID])
+	assert.Equal(t, "jdoe", wi.Fields[remoteCreatorLogin])
+	assert.Equal(t, "https://gitlab.example.com/jdoe", wi.Fields[remoteCreatorProfileURL])
+	assert.Equal(t, []string{"alice", "bob"}, wi.Fields[RemoteAssigneeLogins])
+	assert.Equal(t, []string{"https://gitlab.example.com/alice", "https://gitlab.example.com/bob"}, wi.Fields[RemoteAssigneeProfileURLs])
+	assert.Equal(t, []string{"bug", "backend"}, wi.Fields[RemoteLabelNames])
+}
+
+func TestGitlabStateConverter(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	c := GitlabStateConverter{}
+	for remote, local := range map[string]string{
+		"opened":   workitem.SystemStateOpen,
+		"reopened": workitem.SystemStateOpen,
+		"closed":   workitem.SystemStateClosed,
+		"other":    "other",
+	} {
+		v, err := c.Convert(remote, nil)
+		require.NoError(t, err)
+		assert.Equal(t, local, v, "remote state %s", remote)
+	}
+}
+
+func TestTrackerValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("valid", func(t *testing.T) {
+		for _, tr := range []Tracker{
+			{URL: "https://api.github.com", Type: ProviderGithub},
+			{URL: "http://issues.jboss.com", Type: ProviderJira},
+			{URL: "https://gitlab.example.com", Type: ProviderGitlab},
+			{URL: "http://gitlab.internal:8080/gitlab", Type: ProviderGitlab},
+		} {
+			assert.NoError(t, tr.Validate(), "tracker %+v", tr)
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, tr := range []Tracker{
+			{URL: "url", Type: ProviderGithub},
+			{URL: "https://gitlab.example.com", Type: "unknown"},
+			{URL: "gitlab.example.com", Type: ProviderGitlab},
+			{URL: "ftp://gitlab.example.com", Type: ProviderGitlab},
+		} {
+			assert.IsType(t, BadParameterError{}, tr.Validate(), "tracker %+v", tr)
+		}
+	})
+}
+
+func TestParseGitlabQuery(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	project, params, err := parseGitlabQuery("/group/sub/project?state=opened&labels=bug,ui")
+	require.NoError(t, err)
+	assert.Equal(t, "group/sub/project", project)
+	assert.Equal(t, "opened", params.Get("state"))
+	assert.Equal(t, "bug,ui", params.Get("labels"))
+
+	_, _, err = parseGitlabQuery("?state=opened")
+	assert.IsType(t, BadParameterError{}, err)
+}
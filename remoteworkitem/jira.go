@@ -2,6 +2,12 @@ package remoteworkitem
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
 
 	jira "github.com/andygrunwald/go-jira"
 )
@@ -10,6 +16,9 @@ import (
 type JiraTracker struct {
 	URL   string
 	Query string
+	// Since restricts the fetch to the issues updated since then
+	Since *time.Time
+	err   error
 }
 
 type jiraFetcher interface {
@@ -37,17 +46,157 @@ func (j *JiraTracker) Fetch(authToken string) chan TrackerItemContent {
 	return j.fetch(&f)
 }
 
+// Err returns the error that ended the last fetch early
+func (j *JiraTracker) Err() error {
+	return j.err
+}
+
+// jiraRateLimited returns whether Jira rejected the request because of its rate
+// limit and how long to wait before retrying
+func jiraRateLimited(resp *jira.Response) (bool, time.Duration) {
+	if resp == nil || resp.Response == nil || resp.StatusCode != http.StatusTooManyRequests {
+		return false, 0
+	}
+	return true, retryAfter(resp.Header)
+}
+
 func (j *JiraTracker) fetch(f jiraFetcher) chan TrackerItemContent {
 	item := make(chan TrackerItemContent)
+	j.err = nil
 	go func() {
-		issues, _, _ := f.listIssues(j.Query, nil)
+		defer close(item)
+		jql := j.Query
+		if j.Since != nil {
+			jql = jqlUpdatedSince(jql, *j.Since)
+		}
+		var issues []jira.Issue
+		err := retryOnRateLimit(map[string]interface{}{"jql": jql}, func() (bool, time.Duration, error) {
+			var resp *jira.Response
+			var err error
+			issues, resp, err = f.listIssues(jql, nil)
+			limited, wait := jiraRateLimited(resp)
+			return limited, wait, err
+		})
+		if err != nil {
+			log.Error(nil, map[string]interface{}{
+				"err": err,
+				"jql": jql,
+			}, "unable to list Jira issues")
+			j.err = err
+			return
+		}
 		for _, l := range issues {
 			id, _ := json.Marshal(l.Key)
-			issue, _, _ := f.getIssue(l.Key)
+			var issue *jira.Issue
+			err := retryOnRateLimit(map[string]interface{}{"issue": l.Key}, func() (bool, time.Duration, error) {
+				var resp *jira.Response
+				var err error
+				issue, resp, err = f.getIssue(l.Key)
+				limited, wait := jiraRateLimited(resp)
+				return limited, wait, err
+			})
+			if err != nil {
+				log.Error(nil, map[string]interface{}{
+					"err":   err,
+					"issue": l.Key,
+				}, "unable to get Jira issue")
+				j.err = err
+				continue
+			}
 			content, _ := json.Marshal(issue)
 			item <- TrackerItemContent{ID: string(id), Content: content}
 		}
-		close(item)
 	}()
 	return item
 }
+
+// jqlUpdatedSince restricts the JQL query to the issues updated since the given
+// time. JQL only accepts dates in the time zone of the Jira user, so the time is
+// given relative to now in minutes, rounded up so that no update is missed.
+func jqlUpdatedSince(jql string, since time.Time) string {
+	orderBy := ""
+	if i := strings.Index(strings.ToUpper(jql), " ORDER BY "); i >= 0 {
+		jql, orderBy = jql[:i], jql[i:]
+	}
+	minutes := int64(time.Since(since)/time.Minute) + 1
+	if strings.TrimSpace(jql) == "" {
+		return fmt.Sprintf(`updated >= "-%dm"%s`, minutes, orderBy)
+	}
+	return fmt.Sprintf(`(%s) AND updated >= "-%dm"%s`, jql, minutes, orderBy)
+}
+
+// jiraPusher writes work item changes back to Jira issues
+type jiraPusher struct {
+	client *http.Client
+	token  string
+}
+
+// Push updates the summary, status and assignee of the Jira issue. Jira issues
+// have a single assignee, so only the first assignee is written. The status is
+// changed with the transition that leads to the status named like the work
+// item state.
+func (p *jiraPusher) Push(item AttributeAccessor, changes RemoteChanges) (*time.Time, error) {
+	issueURL, _ := item.Get(JiraID).(string)
+	if issueURL == "" {
+		return nil, BadParameterError{parameter: JiraID, value: item.Get(JiraID)}
+	}
+	header := http.Header{}
+	if p.token != "" {
+		header.Set("Authorization", "Bearer "+p.token)
+	}
+	fields := map[string]interface{}{}
+	if changes.Title != nil {
+		fields["summary"] = *changes.Title
+	}
+	if changes.Assignees != nil {
+		fields["assignee"] = nil
+		if len(changes.Assignees) > 0 {
+			fields["assignee"] = map[string]string{"name": changes.Assignees[0]}
+		}
+	}
+	if len(fields) > 0 {
+		if err := doJSON(p.client, "PUT", issueURL, header, map[string]interface{}{"fields": fields}, nil); err != nil {
+			return nil, err
+		}
+	}
+	if changes.State != nil {
+		var transitions struct {
+			Transitions []struct {
+				ID string `json:"id"`
+				To struct {
+					Name string `json:"name"`
+				} `json:"to"`
+			} `json:"transitions"`
+		}
+		if err := doJSON(p.client, "GET", issueURL+"/transitions", header, nil, &transitions); err != nil {
+			return nil, err
+		}
+		transitionID := ""
+		for _, t := range transitions.Transitions {
+			if strings.EqualFold(t.To.Name, *changes.State) {
+				transitionID = t.ID
+				break
+			}
+		}
+		if transitionID == "" {
+			return nil, BadParameterError{parameter: "state", value: *changes.State}
+		}
+		body := map[string]interface{}{"transition": map[string]string{"id": transitionID}}
+		if err := doJSON(p.client, "POST", issueURL+"/transitions", header, body, nil); err != nil {
+			return nil, err
+		}
+	}
+	var issue struct {
+		Fields struct {
+			Updated string `json:"updated"`
+		} `json:"fields"`
+	}
+	if err := doJSON(p.client, "GET", issueURL+"?fields=updated", header, nil, &issue); err != nil {
+		return nil, err
+	}
+	updatedAt, err := time.Parse(jiraTimeLayout, issue.Fields.Updated)
+	if err != nil {
+		return nil, ConversionError{simpleError{fmt.Sprintf("invalid update timestamp of Jira issue: '%s'", issue.Fields.Updated)}}
+	}
+	return &updatedAt, nil
+}
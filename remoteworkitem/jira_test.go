@@ -8,6 +8,7 @@ import (
 	jira "github.com/andygrunwald/go-jira"
 	"github.com/dnaeon/go-vcr/recorder"
 	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -64,3 +65,50 @@ func TestJiraFetchWithRecording(t *testing.T) {
 	assert.Equal(t, `"ARQ-2009"`, trackerItemContents[3].ID)
 	assert.Equal(t, `"ARQ-2010"`, trackerItemContents[4].ID)
 }
+
+type fakeJiraIssueFetcherWithRateLimit struct {
+	calls int
+}
+
+func (f *fakeJiraIssueFetcherWithRateLimit) listIssues(jql string, options *jira.SearchOptions) ([]jira.Issue, *jira.Response, error) {
+	f.calls++
+	if f.calls == 1 {
+		h := http.Header{}
+		h.Set("Retry-After", "30")
+		resp := &jira.Response{Response: &http.Response{StatusCode: http.StatusTooManyRequests, Header: h}}
+		return nil, resp, errors.New("429 Too Many Requests")
+	}
+	return []jira.Issue{{Key: "ARQ-1"}}, &jira.Response{}, nil
+}
+
+func (f *fakeJiraIssueFetcherWithRateLimit) getIssue(issueID string) (*jira.Issue, *jira.Response, error) {
+	return &jira.Issue{ID: "1"}, &jira.Response{}, nil
+}
+
+func TestJiraFetchWithRateLimit(t *testing.T) {
+	// given
+	resource.Require(t, resource.UnitTest)
+	var waits []time.Duration
+	defer func(s func(time.Duration)) { sleep = s }(sleep)
+	sleep = func(d time.Duration) { waits = append(waits, d) }
+	f := fakeJiraIssueFetcherWithRateLimit{}
+	j := JiraTracker{URL: "", Query: ""}
+	// when
+	var items []TrackerItemContent
+	for i := range j.fetch(&f) {
+		items = append(items, i)
+	}
+	// then
+	require.NoError(t, j.Err())
+	require.Len(t, items, 1)
+	assert.Equal(t, `"ARQ-1"`, items[0].ID)
+	assert.Equal(t, []time.Duration{30 * time.Second}, waits)
+}
+
+func TestJQLUpdatedSince(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	since := time.Now().Add(-90 * time.Minute)
+	assert.Equal(t, `(project = ARQ) AND updated >= "-91m" ORDER BY created ASC`, jqlUpdatedSince("project = ARQ ORDER BY created ASC", since))
+	assert.Equal(t, `(project = ARQ) AND updated >= "-91m"`, jqlUpdatedSince("project = ARQ", since))
+	assert.Equal(t, `updated >= "-91m" order by key`, jqlUpdatedSince(" order by key", since))
+}
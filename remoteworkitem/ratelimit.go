@@ -0,0 +1,74 @@
+package remoteworkitem
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/log"
+
+	"github.com/pkg/errors"
+)
+
+// Back-off settings for remote trackers that reject requests because of their
+// rate limits
+const (
+	maxRateLimitRetries  = 5
+	defaultRateLimitWait = time.Minute
+	maxRateLimitWait     = 15 * time.Minute
+)
+
+// sleep pauses the fetch, it is replaced in tests
+var sleep = time.Sleep
+
+// boundedRateLimitWait returns the given time to wait for a rate limit to be
+// reset, limited to maxRateLimitWait. Unknown waits default to
+// defaultRateLimitWait.
+func boundedRateLimitWait(d time.Duration) time.Duration {
+	if d <= 0 {
+		return defaultRateLimitWait
+	}
+	if d > maxRateLimitWait {
+		return maxRateLimitWait
+	}
+	return d
+}
+
+// retryAfter returns the wait given in seconds in the Retry-After header or 0
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// retryOnRateLimit calls fn until it succeeds, fails for another reason than
+// a rate limit or maxRateLimitRetries are exhausted. If the remote tracker
+// rejected the request because of its rate limit, fn returns true and how long
+// to wait before retrying.
+func retryOnRateLimit(fields map[string]interface{}, fn func() (bool, time.Duration, error)) error {
+	for retries := 0; ; retries++ {
+		limited, wait, err := fn()
+		if !limited {
+			return err
+		}
+		if err == nil {
+			err = errors.New("rate limit reached")
+		}
+		if retries >= maxRateLimitRetries {
+			return errors.Wrapf(err, "rate limit still reached after %d retries", retries)
+		}
+		wait = boundedRateLimitWait(wait)
+		logFields := map[string]interface{}{
+			"err":   err,
+			"wait":  wait.String(),
+			"retry": retries + 1,
+		}
+		for k, v := range fields {
+			logFields[k] = v
+		}
+		log.Warn(nil, logFields, "reached rate limit of the remote tracker, backing off")
+		sleep(wait)
+	}
+}
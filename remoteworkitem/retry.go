@@ -0,0 +1,72 @@
+package remoteworkitem
+
+import (
+	"context"
+	"time"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+)
+
+// MaxSyncAttempts is the number of times the synchronization of a remote item
+// is attempted before it is given up
+const MaxSyncAttempts = 5
+
+// SyncRetry is a remote item of a tracker query which failed to be
+// synchronized. The high-water mark of the query moves past it, so it is
+// retried from the stored content instead of being fetched again.
+type SyncRetry struct {
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	TrackerQueryID uint64
+	RemoteItemID   string
+	// Item is the content of the remote item as it was last fetched
+	Item string
+	// Attempts is the number of failed synchronizations
+	Attempts  int
+	LastError string
+}
+
+// TableName implements gorm.tabler
+func (r SyncRetry) TableName() string {
+	return "tracker_item_retries"
+}
+
+// RecordSyncFailure stores the content of a remote item which failed to be
+// synchronized and returns how many times its synchronization failed so far.
+// The count starts over when the content of the remote item changed.
+func RecordSyncFailure(ctx context.Context, db *gorm.DB, trackerQueryID uint64, item TrackerItemContent, syncErr error) (int, error) {
+	var attempts int
+	err := db.Raw(`INSERT INTO tracker_item_retries (tracker_query_id, remote_item_id, item, last_error)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (tracker_query_id, remote_item_id) DO UPDATE
+		SET item = EXCLUDED.item, last_error = EXCLUDED.last_error, attempts = CASE WHEN tracker_item_retries.item = EXCLUDED.item THEN tracker_item_retries.attempts + 1 ELSE 1 END, updated_at = now()
+		RETURNING attempts`, trackerQueryID, item.ID, string(item.Content), syncErr.Error()).Row().Scan(&attempts)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to record the failed synchronization of remote item %s", item.ID)
+	}
+	return attempts, nil
+}
+
+// ForgetSyncFailure removes the recorded failure of a remote item once it
+// was synchronized
+func ForgetSyncFailure(ctx context.Context, db *gorm.DB, trackerQueryID uint64, remoteItemID string) error {
+	err := db.Exec("DELETE FROM tracker_item_retries WHERE tracker_query_id = ? AND remote_item_id = ?", trackerQueryID, remoteItemID).Error
+	if err != nil {
+		return errors.Wrapf(err, "failed to forget the failed synchronization of remote item %s", remoteItemID)
+	}
+	return nil
+}
+
+// PendingRetries returns the remote items of the given tracker query which
+// failed to be synchronized and have attempts left, oldest failures first
+func PendingRetries(ctx context.Context, db *gorm.DB, trackerQueryID uint64) ([]SyncRetry, error) {
+	var retries []SyncRetry
+	err := db.Where("tracker_query_id = ? AND attempts < ?", trackerQueryID, MaxSyncAttempts).
+		Order("updated_at").
+		Find(&retries).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the remote items to retry of tracker query %d", trackerQueryID)
+	}
+	return retries, nil
+}
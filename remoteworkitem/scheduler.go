@@ -2,9 +2,11 @@ package remoteworkitem
 
 import (
 	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/metric"
 	"github.com/fabric8-services/fabric8-wit/models"
 
 	"context"
+	"time"
 
 	"github.com/jinzhu/gorm"
 	"github.com/pkg/errors"
@@ -14,12 +16,15 @@ import (
 
 // TrackerSchedule capture all configuration
 type trackerSchedule struct {
-	TrackerID   uuid.UUID
-	URL         string
-	TrackerType string
-	Query       string
-	Schedule    string
-	SpaceID     uuid.UUID
+	TrackerID           uuid.UUID
+	TrackerQueryID      uint64
+	URL                 string
+	TrackerType         string
+	Query               string
+	Schedule            string
+	SpaceID             uuid.UUID
+	PushEnabled         bool
+	LastRemoteUpdatedAt *time.Time
 }
 
 // Scheduler represents scheduler
@@ -52,33 +57,178 @@ func (s *Scheduler) ScheduleAllQueries(ctx context.Context, accessTokens map[str
 
 	trackerQueries := fetchTrackerQueries(s.db)
 	for _, tq := range trackerQueries {
+		tq := tq
 		cr.AddFunc(tq.Schedule, func() {
-			tr := lookupProvider(tq)
-			authToken := accessTokens[tq.TrackerType]
-
-			// In case of Jira, no auth token is needed hence the map wouldnt
-			// return anything. So effectively the authToken is optional.
-
-			for i := range tr.Fetch(authToken) {
-				models.Transactional(s.db, func(tx *gorm.DB) error {
-					// Save the remote items in a 'temporary' table.
-					err := Upload(tx, tq.TrackerID, i)
-					if err != nil {
-						return errors.WithStack(err)
-					}
-					// Convert the remote item into a local work item and persist in the DB.
-					_, err = ConvertToWorkItemModel(ctx, tx, tq.TrackerID, i, tq.TrackerType, tq.SpaceID)
-					return errors.WithStack(err)
-				})
-			}
+			s.runQuery(ctx, tq, accessTokens[tq.TrackerType])
 		})
 	}
 	cr.Start()
 }
 
+// runQuery fetches the remote items that changed since the last run of the
+// tracker query and synchronizes them with the work items
+func (s *Scheduler) runQuery(ctx context.Context, tq trackerSchedule, authToken string) {
+	// In case of Jira, no auth token is needed hence the map wouldnt
+	// return anything. So effectively the authToken is optional.
+	start := time.Now()
+	items, errs := 0, 0
+	// the schedule may be older than the last run, so the high-water mark is
+	// read again
+	var highWaterMark *time.Time
+	err := s.db.Table(trackerQueriesTableName).Where("id = ?", tq.TrackerQueryID).Select("last_remote_updated_at").Row().Scan(&highWaterMark)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":              err,
+			"tracker_query_id": tq.TrackerQueryID,
+		}, "unable to load the high-water mark of the tracker query")
+		return
+	}
+	tq.LastRemoteUpdatedAt = highWaterMark
+	tr := lookupProvider(tq)
+
+	// Local changes are only written back if the query opted in.
+	var pusher TrackerPusher
+	if tq.PushEnabled {
+		pusher = lookupPusher(tq.TrackerType, tq.URL, authToken)
+	}
+	fetched := map[string]bool{}
+	for i := range tr.Fetch(authToken) {
+		items++
+		fetched[i.ID] = true
+		// The high-water mark moves past items which fail to be synchronized,
+		// they are retried from their stored content instead.
+		updatedAt, err := fetchedUpdatedAt(tq, i)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":              err,
+				"tracker_query_id": tq.TrackerQueryID,
+				"remote_item_id":   i.ID,
+			}, "unable to read the update timestamp of the remote item")
+		}
+		if updatedAt != nil && (highWaterMark == nil || updatedAt.After(*highWaterMark)) {
+			highWaterMark = updatedAt
+		}
+		if !s.syncItem(ctx, tq, i, pusher) {
+			errs++
+		}
+	}
+	fetchErr := tr.Err()
+	if fetchErr != nil {
+		errs++
+	}
+
+	// Remote items that failed to be synchronized before aren't fetched again
+	// unless they changed, so they are retried from their stored content.
+	retries, err := PendingRetries(ctx, s.db, tq.TrackerQueryID)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":              err,
+			"tracker_query_id": tq.TrackerQueryID,
+		}, "unable to list the remote items to retry")
+		errs++
+	}
+	for _, r := range retries {
+		if fetched[r.RemoteItemID] {
+			continue
+		}
+		fetched[r.RemoteItemID] = true
+		if !s.syncItem(ctx, tq, TrackerItemContent{ID: r.RemoteItemID, Content: []byte(r.Item)}, pusher) {
+			errs++
+		}
+	}
+
+	// Remote items that didn't change aren't fetched again, so local changes
+	// of their work items are pushed from the stored remote items.
+	if pusher != nil {
+		pending, err := PendingPushes(ctx, s.db, tq.TrackerID, tq.SpaceID)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":              err,
+				"tracker_query_id": tq.TrackerQueryID,
+			}, "unable to list the tracker items with pending pushes")
+			errs++
+		}
+		for _, ti := range pending {
+			if fetched[ti.RemoteItemID] {
+				continue
+			}
+			err := models.Transactional(s.db, func(tx *gorm.DB) error {
+				_, err := Sync(ctx, tx, tq.TrackerID, TrackerItemContent{ID: ti.RemoteItemID, Content: []byte(ti.Item)}, tq.TrackerType, tq.SpaceID, pusher)
+				return errors.WithStack(err)
+			})
+			if err != nil {
+				errs++
+			}
+		}
+	}
+	metric.ReportRemoteFetch(tq.TrackerType, items, errs, time.Since(start))
+
+	// The high-water mark doesn't move if the fetch ended early, the remaining
+	// remote items are fetched again on the next run.
+	if fetchErr != nil || highWaterMark == nil {
+		return
+	}
+	err = s.db.Table(trackerQueriesTableName).Where("id = ?", tq.TrackerQueryID).Update("last_remote_updated_at", *highWaterMark).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":              err,
+			"tracker_query_id": tq.TrackerQueryID,
+		}, "unable to store the high-water mark of the tracker query")
+	}
+}
+
+// syncItem synchronizes a remote item with its work item and returns false if
+// it failed. Failures are recorded so that the item is retried on the next
+// runs until it runs out of attempts, a successful synchronization clears
+// them.
+func (s *Scheduler) syncItem(ctx context.Context, tq trackerSchedule, item TrackerItemContent, pusher TrackerPusher) bool {
+	err := models.Transactional(s.db, func(tx *gorm.DB) error {
+		// Save the remote item in a 'temporary' table and either pull it
+		// into the local work item or push the local changes to the
+		// remote tracker.
+		if _, err := Sync(ctx, tx, tq.TrackerID, item, tq.TrackerType, tq.SpaceID, pusher); err != nil {
+			return errors.WithStack(err)
+		}
+		return ForgetSyncFailure(ctx, tx, tq.TrackerQueryID, item.ID)
+	})
+	if err == nil {
+		return true
+	}
+	attempts, recordErr := RecordSyncFailure(ctx, s.db, tq.TrackerQueryID, item, err)
+	if recordErr != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":              recordErr,
+			"tracker_query_id": tq.TrackerQueryID,
+			"remote_item_id":   item.ID,
+		}, "unable to record the failed synchronization of the remote item")
+		return false
+	}
+	fields := map[string]interface{}{
+		"err":              err,
+		"tracker_query_id": tq.TrackerQueryID,
+		"remote_item_id":   item.ID,
+		"attempts":         attempts,
+	}
+	if attempts >= MaxSyncAttempts {
+		log.Error(ctx, fields, "giving up on synchronizing the remote item until it changes")
+	} else {
+		log.Warn(ctx, fields, "unable to synchronize the remote item, it is retried on the next run")
+	}
+	return false
+}
+
+// fetchedUpdatedAt returns when the fetched remote item was last updated
+func fetchedUpdatedAt(tq trackerSchedule, item TrackerItemContent) (*time.Time, error) {
+	remoteItem, err := RemoteWorkItemImplRegistry[tq.TrackerType](TrackerItem{RemoteItemID: item.ID, Item: string(item.Content), TrackerID: tq.TrackerID})
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return RemoteUpdatedAt(tq.TrackerType, remoteItem)
+}
+
 func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 	tsList := []trackerSchedule{}
-	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule, tracker_queries.space_id").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
+	err := db.Table("tracker_queries").Select("trackers.id as tracker_id, tracker_queries.id as tracker_query_id, trackers.url, trackers.type as tracker_type, tracker_queries.query, tracker_queries.schedule, tracker_queries.space_id, tracker_queries.push_enabled, tracker_queries.last_remote_updated_at").Joins("left join trackers on tracker_queries.tracker_id = trackers.id").Where("trackers.deleted_at is NULL AND tracker_queries.deleted_at is NULL").Scan(&tsList).Error
 	if err != nil {
 		log.Error(nil, map[string]interface{}{
 			"err": err,
@@ -91,9 +241,11 @@ func fetchTrackerQueries(db *gorm.DB) []trackerSchedule {
 func lookupProvider(ts trackerSchedule) TrackerProvider {
 	switch ts.TrackerType {
 	case ProviderGithub:
-		return &GithubTracker{URL: ts.URL, Query: ts.Query}
+		return &GithubTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastRemoteUpdatedAt}
 	case ProviderJira:
-		return &JiraTracker{URL: ts.URL, Query: ts.Query}
+		return &JiraTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastRemoteUpdatedAt}
+	case ProviderGitlab:
+		return &GitlabTracker{URL: ts.URL, Query: ts.Query, Since: ts.LastRemoteUpdatedAt}
 	}
 	return nil
 }
@@ -107,6 +259,8 @@ type TrackerItemContent struct {
 // TrackerProvider represents a remote tracker
 type TrackerProvider interface {
 	Fetch(authToken string) chan TrackerItemContent // TODO: Change to an interface to enforce the contract
+	// Err returns the error that ended the last fetch early, if any
+	Err() error
 }
 
 func init() {
@@ -19,6 +19,10 @@ func TestLookupProvider(t *testing.T) {
 	tp2 := lookupProvider(ts2)
 	require.NotNil(t, tp2)
 
+	ts4 := trackerSchedule{TrackerType: ProviderGitlab}
+	tp4 := lookupProvider(ts4)
+	require.NotNil(t, tp4)
+
 	ts3 := trackerSchedule{TrackerType: "unknown"}
 	tp3 := lookupProvider(ts3)
 	require.Nil(t, tp3)
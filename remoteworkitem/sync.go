@@ -0,0 +1,380 @@
+package remoteworkitem
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+
+	"github.com/jinzhu/gorm"
+	"github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// jiraTimeLayout is the layout of the timestamps in the Jira REST API
+const jiraTimeLayout = "2006-01-02T15:04:05.000-0700"
+
+// RemoteUpdatedAtKeys relate the providers to the key of the update timestamp
+// in the flattened remote item
+var RemoteUpdatedAtKeys = map[string]AttributeExpression{
+	ProviderGithub: GithubUpdatedAt,
+	ProviderJira:   JiraUpdatedAt,
+	ProviderGitlab: GitlabUpdatedAt,
+}
+
+// RemoteUpdatedAt returns when the remote item was last updated or nil if the
+// remote item has no update timestamp
+func RemoteUpdatedAt(providerType string, item AttributeAccessor) (*time.Time, error) {
+	key, ok := RemoteUpdatedAtKeys[providerType]
+	if !ok {
+		return nil, BadParameterError{parameter: "providerType", value: providerType}
+	}
+	value, _ := item.Get(key).(string)
+	if value == "" {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, jiraTimeLayout} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, ConversionError{simpleError{fmt.Sprintf("invalid update timestamp of remote item: '%s'", value)}}
+}
+
+// RemoteChanges holds the values of a work item that differ from the remote
+// item and that need to be written back to the remote tracker. Nil values are
+// left unchanged.
+type RemoteChanges struct {
+	Title *string
+	State *string
+	// Assignees holds the logins of the assignees on the remote tracker
+	Assignees []string
+}
+
+// IsEmpty returns true if there is nothing to write back
+func (c RemoteChanges) IsEmpty() bool {
+	return c.Title == nil && c.State == nil && c.Assignees == nil
+}
+
+// TrackerPusher writes local changes back to a remote tracker
+type TrackerPusher interface {
+	// Push writes the changes to the given remote item and returns the update
+	// timestamp of the remote item after the change
+	Push(item AttributeAccessor, changes RemoteChanges) (*time.Time, error)
+}
+
+// lookupPusher provides the respective pusher based on the tracker type
+func lookupPusher(trackerType, trackerURL, authToken string) TrackerPusher {
+	client := &http.Client{Timeout: 30 * time.Second}
+	switch trackerType {
+	case ProviderGithub:
+		return &githubPusher{client: client, token: authToken}
+	case ProviderJira:
+		return &jiraPusher{client: client, token: authToken}
+	case ProviderGitlab:
+		return &gitlabPusher{client: client, baseURL: trackerURL, token: authToken}
+	}
+	return nil
+}
+
+// isClosedState tells if the given local work item state corresponds to a
+// closed issue on trackers that only know open and closed issues
+func isClosedState(state string) bool {
+	return state == workitem.SystemStateClosed || state == workitem.SystemStateResolved
+}
+
+// Sync synchronizes a fetched remote item with the work item it was imported
+// into and records the outcome in the sync status of the tracker item.
+//
+// Without a pusher, or if the work item didn't change since the last sync, the
+// remote item is pulled into the work item. If only the work item changed, its
+// title, state and assignees are pushed to the remote tracker. If both changed,
+// a conflict is recorded and neither side is modified until the conflict is
+// resolved with ResolveSyncConflict.
+func Sync(ctx context.Context, db *gorm.DB, trackerID uuid.UUID, item TrackerItemContent, providerType string, spaceID uuid.UUID, pusher TrackerPusher) (*TrackerItem, error) {
+	var ti TrackerItem
+	tx := db.Where("remote_item_id = ? AND tracker_id = ?", item.ID, trackerID).Find(&ti)
+	if tx.RecordNotFound() {
+		ti = TrackerItem{RemoteItemID: item.ID, TrackerID: trackerID}
+	} else if tx.Error != nil {
+		return nil, errors.WithStack(tx.Error)
+	}
+	ti.Item = string(item.Content)
+	remoteTrackerItemConvertFunc, ok := RemoteWorkItemImplRegistry[providerType]
+	if !ok {
+		return nil, BadParameterError{parameter: providerType, value: providerType}
+	}
+	remoteItem, err := remoteTrackerItemConvertFunc(ti)
+	if err != nil {
+		return nil, InternalError{simpleError{message: fmt.Sprintf("Error parsing the tracker data: %s", err.Error())}}
+	}
+	remoteUpdatedAt, err := RemoteUpdatedAt(providerType, remoteItem)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	remoteChanged := ti.RemoteUpdatedAt == nil || remoteUpdatedAt == nil || remoteUpdatedAt.After(*ti.RemoteUpdatedAt)
+
+	var wi *workitem.WorkItem
+	if pusher != nil && ti.WorkItemID != nil && ti.SyncedVersion != nil {
+		wi, err = workitem.NewWorkItemRepository(db).LoadByID(ctx, *ti.WorkItemID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load work item %s", *ti.WorkItemID)
+		}
+	}
+	localChanged := wi != nil && wi.Version > *ti.SyncedVersion
+
+	now := time.Now()
+	switch {
+	case localChanged && remoteChanged:
+		conflict := fmt.Sprintf("both the work item (version %d) and the remote item changed since the last sync", wi.Version)
+		ti.SyncConflict = &conflict
+		log.Warn(ctx, map[string]interface{}{
+			"wi_id":          wi.ID,
+			"remote_item_id": ti.RemoteItemID,
+		}, "conflict when synchronizing remote item")
+	case localChanged:
+		remoteWorkItem, err := Map(remoteItem, RemoteWorkItemKeyMaps[providerType])
+		if err != nil {
+			return nil, ConversionError{simpleError{message: fmt.Sprintf("Error mapping to local work item: %s", err.Error())}}
+		}
+		changes, err := localChanges(ctx, db, providerType, *wi, remoteWorkItem)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if !changes.IsEmpty() {
+			pushedAt, err := pusher.Push(remoteItem, changes)
+			if err != nil {
+				log.Error(ctx, map[string]interface{}{
+					"err":            err,
+					"wi_id":          wi.ID,
+					"remote_item_id": ti.RemoteItemID,
+				}, "unable to push work item changes to the remote tracker")
+				syncError := err.Error()
+				ti.SyncError = &syncError
+				break
+			}
+			if pushedAt != nil {
+				remoteUpdatedAt = pushedAt
+			}
+			ti.LastPushedAt = &now
+		}
+		ti.RemoteUpdatedAt = remoteUpdatedAt
+		ti.SyncedVersion = &wi.Version
+		ti.SyncConflict = nil
+		ti.SyncError = nil
+	case wi != nil && !remoteChanged:
+		// neither side changed since the last sync
+	default:
+		wi, err = ConvertToWorkItemModel(ctx, db, trackerID, item, providerType, spaceID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ti.WorkItemID = &wi.ID
+		ti.SyncedVersion = &wi.Version
+		ti.RemoteUpdatedAt = remoteUpdatedAt
+		ti.LastPulledAt = &now
+		ti.SyncConflict = nil
+		ti.SyncError = nil
+	}
+
+	if ti.ID == 0 {
+		err = db.Create(&ti).Error
+	} else {
+		err = db.Save(&ti).Error
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to save the sync status of the tracker item")
+	}
+	return &ti, nil
+}
+
+// localChanges compares the title, state and assignees of the work item with
+// the mapped remote item. Assignees without an identity on the remote tracker
+// are ignored.
+func localChanges(ctx context.Context, db *gorm.DB, providerType string, wi workitem.WorkItem, remote RemoteWorkItem) (RemoteChanges, error) {
+	changes := RemoteChanges{}
+	if title, ok := wi.Fields[workitem.SystemTitle].(string); ok && title != remote.Fields[remoteTitle] {
+		changes.Title = &title
+	}
+	remoteStateValue, _ := remote.Fields[remoteState].(string)
+	if state, ok := wi.Fields[workitem.SystemState].(string); ok && !strings.EqualFold(state, remoteStateValue) {
+		changes.State = &state
+	}
+	identityRepository := account.NewIdentityRepository(db)
+	logins := []string{}
+	for _, id := range toStrings(wi.Fields[workitem.SystemAssignees]) {
+		identityID, err := uuid.FromString(id)
+		if err != nil {
+			return changes, errors.Wrapf(err, "invalid assignee: %s", id)
+		}
+		identity, err := identityRepository.Load(ctx, identityID)
+		if err != nil {
+			return changes, errors.Wrapf(err, "failed to load assignee %s", id)
+		}
+		if identity.ProviderType == providerType {
+			logins = append(logins, identity.Username)
+		}
+	}
+	remoteLogins, _ := remote.Fields[RemoteAssigneeLogins].([]string)
+	if !sameElements(logins, remoteLogins) {
+		changes.Assignees = logins
+	}
+	return changes, nil
+}
+
+// ResolveSyncConflict resolves the sync conflict of the given tracker item. If
+// keepLocal is true, the work item is pushed to the remote tracker on the next
+// sync, otherwise the last fetched remote item is pulled into the work item
+// right away. Remote items are only fetched again once they change, so the
+// pull can't wait for the next sync.
+func ResolveSyncConflict(ctx context.Context, db *gorm.DB, trackerItemID uint64, keepLocal bool) (*TrackerItem, error) {
+	var ti TrackerItem
+	tx := db.First(&ti, trackerItemID)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{entity: "tracker item", ID: fmt.Sprint(trackerItemID)}
+	}
+	if tx.Error != nil {
+		return nil, errors.WithStack(tx.Error)
+	}
+	if ti.SyncConflict == nil || ti.WorkItemID == nil {
+		return &ti, nil
+	}
+	var providerType string
+	if err := db.Table(trackersTableName).Where("id = ?", ti.TrackerID).Select("type").Row().Scan(&providerType); err != nil {
+		return nil, errors.Wrapf(err, "failed to load the tracker %s", ti.TrackerID)
+	}
+	if !keepLocal {
+		wi, err := workitem.NewWorkItemRepository(db).LoadByID(ctx, *ti.WorkItemID)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		// without a pusher the remote item is always pulled
+		return Sync(ctx, db, ti.TrackerID, TrackerItemContent{ID: ti.RemoteItemID, Content: []byte(ti.Item)}, providerType, wi.SpaceID, nil)
+	}
+	remoteItem, err := RemoteWorkItemImplRegistry[providerType](ti)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	// the last fetched remote state counts as synced
+	ti.RemoteUpdatedAt, err = RemoteUpdatedAt(providerType, remoteItem)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ti.SyncConflict = nil
+	if err := db.Save(&ti).Error; err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &ti, nil
+}
+
+// PendingPushes returns the tracker items of the given tracker and space whose
+// work items changed since the last sync. They need to be synchronized even if
+// the remote items didn't change and weren't fetched again.
+func PendingPushes(ctx context.Context, db *gorm.DB, trackerID uuid.UUID, spaceID uuid.UUID) ([]TrackerItem, error) {
+	var items []TrackerItem
+	err := db.Select("tracker_items.*").
+		Joins("JOIN work_items ON work_items.id = tracker_items.work_item_id AND work_items.deleted_at IS NULL").
+		Where("tracker_items.tracker_id = ? AND work_items.space_id = ?", trackerID, spaceID).
+		Where("tracker_items.sync_conflict IS NULL AND work_items.version > tracker_items.synced_version").
+		Find(&items).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list the tracker items with pending pushes of tracker %s", trackerID)
+	}
+	return items, nil
+}
+
+// LoadSyncStatus returns the tracker item the given work item was imported
+// from, including its sync status
+func LoadSyncStatus(ctx context.Context, db *gorm.DB, workItemID uuid.UUID) (*TrackerItem, error) {
+	var ti TrackerItem
+	tx := db.Where("work_item_id = ?", workItemID).First(&ti)
+	if tx.RecordNotFound() {
+		return nil, NotFoundError{entity: "tracker item for work item", ID: workItemID.String()}
+	}
+	if tx.Error != nil {
+		return nil, errors.WithStack(tx.Error)
+	}
+	return &ti, nil
+}
+
+// toStrings converts a stored list field value into a slice of strings
+func toStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return []string{}
+}
+
+// sameElements tells if both slices contain the same elements regardless of
+// their order
+func sameElements(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	x := append([]string{}, a...)
+	y := append([]string{}, b...)
+	sort.Strings(x)
+	sort.Strings(y)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// doJSON sends the request with the given body encoded as JSON and decodes the
+// JSON response into result unless it is nil
+func doJSON(client *http.Client, method, url string, header http.Header, body, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return errors.Errorf("%s %s failed with status '%s': %s", method, url, resp.Status, msg)
+	}
+	if result == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(result), "failed to decode the response of %s %s", method, url)
+}
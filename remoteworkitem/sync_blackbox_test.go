@@ -0,0 +1,313 @@
+package remoteworkitem_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/remoteworkitem"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+func TestSuiteSync(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &syncSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+type syncSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+// fakePusher records the pushed changes
+type fakePusher struct {
+	changes   []remoteworkitem.RemoteChanges
+	updatedAt time.Time
+}
+
+func (p *fakePusher) Push(item remoteworkitem.AttributeAccessor, changes remoteworkitem.RemoteChanges) (*time.Time, error) {
+	p.changes = append(p.changes, changes)
+	return &p.updatedAt, nil
+}
+
+func githubIssue(title string, updatedAt time.Time) remoteworkitem.TrackerItemContent {
+	return remoteworkitem.TrackerItemContent{
+		ID: "https://api.github.com/repos/sync/test/issues/1",
+		Content: []byte(fmt.Sprintf(`{
+			"title": "%s",
+			"url": "https://api.github.com/repos/sync/test/issues/1",
+			"state": "open",
+			"body": "body of issue",
+			"updated_at": "%s",
+			"user": {
+				"login": "jdoe",
+				"url": "https://api.github.com/users/jdoe"
+			},
+			"assignees": []
+		}`, title, updatedAt.UTC().Format(time.RFC3339))),
+	}
+}
+
+func (s *syncSuite) TestSync() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Identities(1))
+	trackerID := fxt.Trackers[0].ID
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	t1 := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	t3 := t2.Add(time.Hour)
+	pusher := &fakePusher{updatedAt: t2}
+
+	sync := func(content remoteworkitem.TrackerItemContent) *remoteworkitem.TrackerItem {
+		ti, err := remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+		require.NoError(s.T(), err)
+		return ti
+	}
+	rename := func(ti *remoteworkitem.TrackerItem, title string) {
+		wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+		require.NoError(s.T(), err)
+		wi.Fields[workitem.SystemTitle] = title
+		_, err = wiRepo.Save(s.Ctx, wi.SpaceID, *wi, fxt.Identities[0].ID)
+		require.NoError(s.T(), err)
+	}
+	title := func(ti *remoteworkitem.TrackerItem) interface{} {
+		wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+		require.NoError(s.T(), err)
+		return wi.Fields[workitem.SystemTitle]
+	}
+
+	// the first sync pulls the remote item
+	ti := sync(githubIssue("remote title", t1))
+	require.NotNil(s.T(), ti.WorkItemID)
+	require.NotNil(s.T(), ti.LastPulledAt)
+	assert.Nil(s.T(), ti.LastPushedAt)
+	assert.Equal(s.T(), "remote title", title(ti))
+	lastPulledAt := *ti.LastPulledAt
+
+	// a local change is pushed if the remote item didn't change
+	rename(ti, "local title")
+	ti = sync(githubIssue("remote title", t1))
+	require.Len(s.T(), pusher.changes, 1)
+	require.NotNil(s.T(), pusher.changes[0].Title)
+	assert.Equal(s.T(), "local title", *pusher.changes[0].Title)
+	assert.Nil(s.T(), pusher.changes[0].State)
+	assert.Nil(s.T(), pusher.changes[0].Assignees)
+	require.NotNil(s.T(), ti.LastPushedAt)
+	require.NotNil(s.T(), ti.RemoteUpdatedAt)
+	assert.True(s.T(), t2.Equal(*ti.RemoteUpdatedAt))
+	assert.Equal(s.T(), "local title", title(ti))
+
+	// nothing happens if neither side changed
+	ti = sync(githubIssue("local title", t2))
+	require.Len(s.T(), pusher.changes, 1)
+	assert.True(s.T(), lastPulledAt.Equal(*ti.LastPulledAt))
+
+	// a conflict is recorded if both sides changed
+	rename(ti, "another local title")
+	ti = sync(githubIssue("another remote title", t3))
+	require.NotNil(s.T(), ti.SyncConflict)
+	require.Len(s.T(), pusher.changes, 1)
+	assert.Equal(s.T(), "another local title", title(ti))
+
+	// the status can be loaded by work item
+	status, err := remoteworkitem.LoadSyncStatus(s.Ctx, s.DB, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), ti.ID, status.ID)
+	assert.NotNil(s.T(), status.SyncConflict)
+
+	// keeping the remote side pulls the stored remote item right away
+	ti, err = remoteworkitem.ResolveSyncConflict(s.Ctx, s.DB, ti.ID, false)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), ti.SyncConflict)
+	assert.Equal(s.T(), "another remote title", title(ti))
+	assert.True(s.T(), ti.LastPulledAt.After(lastPulledAt))
+	require.NotNil(s.T(), ti.RemoteUpdatedAt)
+	assert.True(s.T(), t3.Equal(*ti.RemoteUpdatedAt))
+	pending, err := remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
+
+	// the next sync of the unchanged remote item does nothing
+	ti = sync(githubIssue("another remote title", t3))
+	assert.Nil(s.T(), ti.SyncConflict)
+	require.Len(s.T(), pusher.changes, 1)
+}
+
+func (s *syncSuite) TestPendingPushes() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Identities(1))
+	trackerID := fxt.Trackers[0].ID
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	t1 := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	pusher := &fakePusher{updatedAt: t1.Add(time.Hour)}
+	content := githubIssue("remote title", t1)
+	content.ID = "https://api.github.com/repos/sync/test/issues/3"
+	ti, err := remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+
+	// nothing is pending right after the pull
+	pending, err := remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
+
+	// a local change is pending until it is pushed
+	wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	wi.Fields[workitem.SystemTitle] = "local title"
+	_, err = wiRepo.Save(s.Ctx, wi.SpaceID, *wi, fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	pending, err = remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), pending, 1)
+	assert.Equal(s.T(), ti.ID, pending[0].ID)
+	_, err = remoteworkitem.Sync(s.Ctx, s.DB, trackerID, remoteworkitem.TrackerItemContent{ID: pending[0].RemoteItemID, Content: []byte(pending[0].Item)}, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), pusher.changes, 1)
+	pending, err = remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
+}
+
+func (s *syncSuite) TestResolveKeepLocal() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Identities(1))
+	trackerID := fxt.Trackers[0].ID
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	repo := remoteworkitem.NewSyncStatusRepository(s.DB)
+	t1 := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	pusher := &fakePusher{updatedAt: t2.Add(time.Hour)}
+	content := githubIssue("remote title", t1)
+	content.ID = "https://api.github.com/repos/sync/test/issues/4"
+	ti, err := remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+	wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	wi.Fields[workitem.SystemTitle] = "local title"
+	_, err = wiRepo.Save(s.Ctx, wi.SpaceID, *wi, fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	content = githubIssue("another remote title", t2)
+	content.ID = "https://api.github.com/repos/sync/test/issues/4"
+	ti, err = remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), ti.SyncConflict)
+
+	// conflicted items are not pushed
+	pending, err := remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	assert.Empty(s.T(), pending)
+
+	// keeping the local side makes the push pending although the remote
+	// item isn't fetched again
+	ti, err = repo.Resolve(s.Ctx, wi.ID, true)
+	require.NoError(s.T(), err)
+	assert.Nil(s.T(), ti.SyncConflict)
+	pending, err = remoteworkitem.PendingPushes(s.Ctx, s.DB, trackerID, space.SystemSpace)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), pending, 1)
+	ti, err = remoteworkitem.Sync(s.Ctx, s.DB, trackerID, remoteworkitem.TrackerItemContent{ID: pending[0].RemoteItemID, Content: []byte(pending[0].Item)}, remoteworkitem.ProviderGithub, space.SystemSpace, pusher)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), pusher.changes, 1)
+	require.NotNil(s.T(), pusher.changes[0].Title)
+	assert.Equal(s.T(), "local title", *pusher.changes[0].Title)
+	assert.NotNil(s.T(), ti.LastPushedAt)
+	assert.Nil(s.T(), ti.SyncConflict)
+
+	// work items that weren't imported have no sync status
+	_, err = repo.Resolve(s.Ctx, uuid.NewV4(), true)
+	require.IsType(s.T(), errors.NotFoundError{}, err)
+}
+
+func (s *syncSuite) TestSyncWithoutPush() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Identities(1))
+	trackerID := fxt.Trackers[0].ID
+	t1 := time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC)
+	content := remoteworkitem.TrackerItemContent{
+		ID: "https://api.github.com/repos/sync/test/issues/2",
+		Content: []byte(fmt.Sprintf(`{
+			"title": "remote title",
+			"url": "https://api.github.com/repos/sync/test/issues/2",
+			"state": "open",
+			"updated_at": "%s",
+			"user": {"login": "jdoe", "url": "https://api.github.com/users/jdoe"}
+		}`, t1.Format(time.RFC3339))),
+	}
+	ti, err := remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, nil)
+	require.NoError(s.T(), err)
+	require.NotNil(s.T(), ti.WorkItemID)
+
+	// local changes are overwritten on every sync
+	wiRepo := workitem.NewWorkItemRepository(s.DB)
+	wi, err := wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	wi.Fields[workitem.SystemTitle] = "local title"
+	_, err = wiRepo.Save(s.Ctx, wi.SpaceID, *wi, fxt.Identities[0].ID)
+	require.NoError(s.T(), err)
+	ti, err = remoteworkitem.Sync(s.Ctx, s.DB, trackerID, content, remoteworkitem.ProviderGithub, space.SystemSpace, nil)
+	require.NoError(s.T(), err)
+	wi, err = wiRepo.LoadByID(s.Ctx, *ti.WorkItemID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), "remote title", wi.Fields[workitem.SystemTitle])
+	assert.Nil(s.T(), ti.SyncConflict)
+}
+
+func (s *syncSuite) TestSyncRetries() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Trackers(1), tf.Spaces(1))
+	tq := remoteworkitem.TrackerQuery{
+		Query:     "is:open",
+		Schedule:  "15 * * * * *",
+		TrackerID: fxt.Trackers[0].ID,
+		SpaceID:   fxt.Spaces[0].ID,
+	}
+	require.NoError(s.T(), s.DB.Create(&tq).Error)
+	item := githubIssue("remote title", time.Date(2017, 8, 10, 7, 0, 0, 0, time.UTC))
+
+	s.T().Run("failures are counted", func(t *testing.T) {
+		attempts, err := remoteworkitem.RecordSyncFailure(s.Ctx, s.DB, tq.ID, item, fmt.Errorf("first failure"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		attempts, err = remoteworkitem.RecordSyncFailure(s.Ctx, s.DB, tq.ID, item, fmt.Errorf("second failure"))
+		require.NoError(t, err)
+		assert.Equal(t, 2, attempts)
+		retries, err := remoteworkitem.PendingRetries(s.Ctx, s.DB, tq.ID)
+		require.NoError(t, err)
+		require.Len(t, retries, 1)
+		assert.Equal(t, item.ID, retries[0].RemoteItemID)
+		assert.Equal(t, string(item.Content), retries[0].Item)
+		assert.Equal(t, "second failure", retries[0].LastError)
+	})
+	s.T().Run("items without attempts left are not retried", func(t *testing.T) {
+		for attempts := 3; attempts <= remoteworkitem.MaxSyncAttempts; attempts++ {
+			_, err := remoteworkitem.RecordSyncFailure(s.Ctx, s.DB, tq.ID, item, fmt.Errorf("failure"))
+			require.NoError(t, err)
+		}
+		retries, err := remoteworkitem.PendingRetries(s.Ctx, s.DB, tq.ID)
+		require.NoError(t, err)
+		assert.Empty(t, retries)
+	})
+	s.T().Run("changed items are retried again", func(t *testing.T) {
+		changed := githubIssue("changed title", time.Date(2017, 8, 11, 7, 0, 0, 0, time.UTC))
+		attempts, err := remoteworkitem.RecordSyncFailure(s.Ctx, s.DB, tq.ID, changed, fmt.Errorf("failure"))
+		require.NoError(t, err)
+		assert.Equal(t, 1, attempts)
+		retries, err := remoteworkitem.PendingRetries(s.Ctx, s.DB, tq.ID)
+		require.NoError(t, err)
+		require.Len(t, retries, 1)
+		assert.Equal(t, string(changed.Content), retries[0].Item)
+		require.NoError(t, remoteworkitem.ForgetSyncFailure(s.Ctx, s.DB, tq.ID, changed.ID))
+	})
+	s.T().Run("synchronized items are forgotten", func(t *testing.T) {
+		other := remoteworkitem.TrackerItemContent{ID: "https://api.github.com/repos/sync/test/issues/3", Content: item.Content}
+		_, err := remoteworkitem.RecordSyncFailure(s.Ctx, s.DB, tq.ID, other, fmt.Errorf("failure"))
+		require.NoError(t, err)
+		require.NoError(t, remoteworkitem.ForgetSyncFailure(s.Ctx, s.DB, tq.ID, other.ID))
+		retries, err := remoteworkitem.PendingRetries(s.Ctx, s.DB, tq.ID)
+		require.NoError(t, err)
+		assert.Empty(t, retries)
+	})
+}
@@ -0,0 +1,70 @@
+package remoteworkitem
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// SyncStatusRepository gives access to the sync status of the work items
+// imported from remote trackers
+type SyncStatusRepository interface {
+	Load(ctx context.Context, workItemID uuid.UUID) (*TrackerItem, error)
+	Resolve(ctx context.Context, workItemID uuid.UUID, keepLocal bool) (*TrackerItem, error)
+}
+
+// NewSyncStatusRepository constructs a SyncStatusRepository
+func NewSyncStatusRepository(db *gorm.DB) *GormSyncStatusRepository {
+	return &GormSyncStatusRepository{db: db}
+}
+
+// GormSyncStatusRepository implements SyncStatusRepository using gorm
+type GormSyncStatusRepository struct {
+	db *gorm.DB
+}
+
+// Load returns the tracker item the given work item was imported from
+// returns NotFoundError or InternalError
+func (r *GormSyncStatusRepository) Load(ctx context.Context, workItemID uuid.UUID) (*TrackerItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "sync_status", "load"}, time.Now())
+	ti, err := LoadSyncStatus(ctx, r.db, workItemID)
+	if err != nil {
+		if _, ok := errs.Cause(err).(NotFoundError); ok {
+			return nil, errors.NewNotFoundError("tracker item for work item", workItemID.String())
+		}
+		log.Error(ctx, map[string]interface{}{
+			"err":   err,
+			"wi_id": workItemID,
+		}, "unable to load the sync status of the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return ti, nil
+}
+
+// Resolve resolves the sync conflict of the given work item, see
+// ResolveSyncConflict. The sync status is returned unchanged if there is no
+// conflict.
+// returns NotFoundError or InternalError
+func (r *GormSyncStatusRepository) Resolve(ctx context.Context, workItemID uuid.UUID, keepLocal bool) (*TrackerItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "sync_status", "resolve"}, time.Now())
+	ti, err := r.Load(ctx, workItemID)
+	if err != nil {
+		return nil, err
+	}
+	ti, err = ResolveSyncConflict(ctx, r.db, ti.ID, keepLocal)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":        err,
+			"wi_id":      workItemID,
+			"keep_local": keepLocal,
+		}, "unable to resolve the sync conflict of the work item")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return ti, nil
+}
@@ -0,0 +1,171 @@
+package remoteworkitem
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoteUpdatedAt(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("github", func(t *testing.T) {
+		u, err := RemoteUpdatedAt(ProviderGithub, GitHubRemoteWorkItem{issue: map[string]interface{}{"updated_at": "2017-08-10T07:39:52Z"}})
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.True(t, time.Date(2017, 8, 10, 7, 39, 52, 0, time.UTC).Equal(*u))
+	})
+	t.Run("jira", func(t *testing.T) {
+		u, err := RemoteUpdatedAt(ProviderJira, JiraRemoteWorkItem{issue: map[string]interface{}{"fields.updated": "2017-08-10T09:39:52.000+0200"}})
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.True(t, time.Date(2017, 8, 10, 7, 39, 52, 0, time.UTC).Equal(*u))
+	})
+	t.Run("gitlab", func(t *testing.T) {
+		u, err := RemoteUpdatedAt(ProviderGitlab, GitlabRemoteWorkItem{issue: map[string]interface{}{"updated_at": "2017-08-10T07:39:52.123Z"}})
+		require.NoError(t, err)
+		require.NotNil(t, u)
+		assert.True(t, time.Date(2017, 8, 10, 7, 39, 52, 123000000, time.UTC).Equal(*u))
+	})
+	t.Run("missing", func(t *testing.T) {
+		u, err := RemoteUpdatedAt(ProviderGithub, GitHubRemoteWorkItem{issue: map[string]interface{}{}})
+		require.NoError(t, err)
+		assert.Nil(t, u)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		_, err := RemoteUpdatedAt(ProviderGithub, GitHubRemoteWorkItem{issue: map[string]interface{}{"updated_at": "yesterday"}})
+		assert.IsType(t, ConversionError{}, err)
+	})
+}
+
+func TestGithubPush(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "PATCH", r.Method)
+		assert.Equal(t, "/repos/owner/repo/issues/1", r.URL.Path)
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, map[string]interface{}{
+			"title":     "new title",
+			"state":     "closed",
+			"assignees": []interface{}{"jdoe"},
+		}, body)
+		fmt.Fprint(w, `{"updated_at":"2017-08-10T07:39:52Z"}`)
+	}))
+	defer ts.Close()
+	p := githubPusher{client: &http.Client{}, token: "secret"}
+	item := GitHubRemoteWorkItem{issue: map[string]interface{}{GithubID: ts.URL + "/repos/owner/repo/issues/1"}}
+	title := "new title"
+	state := workitem.SystemStateResolved
+	// when
+	u, err := p.Push(item, RemoteChanges{Title: &title, State: &state, Assignees: []string{"jdoe"}})
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, u)
+	assert.True(t, time.Date(2017, 8, 10, 7, 39, 52, 0, time.UTC).Equal(*u))
+}
+
+func TestGitlabPush(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret", r.Header.Get("PRIVATE-TOKEN"))
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v4/users":
+			assert.Equal(t, "alice", r.URL.Query().Get("username"))
+			fmt.Fprint(w, `[{"id":42,"username":"alice"}]`)
+		case r.Method == "PUT" && r.URL.Path == "/api/v4/projects/7/issues/3":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{
+				"state_event":  "reopen",
+				"assignee_ids": []interface{}{float64(42)},
+			}, body)
+			fmt.Fprint(w, `{"updated_at":"2017-08-10T07:39:52.000Z"}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer ts.Close()
+	p := gitlabPusher{client: &http.Client{}, baseURL: ts.URL, token: "secret"}
+	item := GitlabRemoteWorkItem{issue: map[string]interface{}{
+		GitlabProjectID: float64(7),
+		GitlabIID:       float64(3),
+		GitlabState:     "closed",
+	}}
+	state := workitem.SystemStateOpen
+	// when
+	u, err := p.Push(item, RemoteChanges{State: &state, Assignees: []string{"alice"}})
+	// then
+	require.NoError(t, err)
+	require.NotNil(t, u)
+}
+
+func TestJiraPush(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	transitioned := false
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/rest/api/2/issue/10002":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{
+				"fields": map[string]interface{}{"summary": "new title"},
+			}, body)
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == "/rest/api/2/issue/10002/transitions":
+			fmt.Fprint(w, `{"transitions":[{"id":"11","to":{"name":"Open"}},{"id":"21","to":{"name":"In Progress"}}]}`)
+		case r.Method == "POST" && r.URL.Path == "/rest/api/2/issue/10002/transitions":
+			var body map[string]interface{}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			assert.Equal(t, map[string]interface{}{"transition": map[string]interface{}{"id": "21"}}, body)
+			transitioned = true
+			w.WriteHeader(http.StatusNoContent)
+		case r.Method == "GET" && r.URL.Path == "/rest/api/2/issue/10002":
+			fmt.Fprint(w, `{"fields":{"updated":"2017-08-10T09:39:52.000+0200"}}`)
+		default:
+			t.Errorf("unexpected request: %s %s", r.Method, r.URL)
+		}
+	}))
+	defer ts.Close()
+	p := jiraPusher{client: &http.Client{}}
+	item := JiraRemoteWorkItem{issue: map[string]interface{}{JiraID: ts.URL + "/rest/api/2/issue/10002"}}
+	title := "new title"
+	state := workitem.SystemStateInProgress
+
+	t.Run("ok", func(t *testing.T) {
+		// when
+		u, err := p.Push(item, RemoteChanges{Title: &title, State: &state})
+		// then
+		require.NoError(t, err)
+		assert.True(t, transitioned)
+		require.NotNil(t, u)
+		assert.True(t, time.Date(2017, 8, 10, 7, 39, 52, 0, time.UTC).Equal(*u))
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		// when
+		unknown := "frozen"
+		_, err := p.Push(item, RemoteChanges{State: &unknown})
+		// then
+		assert.IsType(t, BadParameterError{}, err)
+	})
+}
+
+func TestSameElements(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.True(t, sameElements([]string{}, nil))
+	assert.True(t, sameElements([]string{"a", "b"}, []string{"b", "a"}))
+	assert.False(t, sameElements([]string{"a"}, []string{"b"}))
+	assert.False(t, sameElements([]string{"a"}, []string{"a", "b"}))
+}
@@ -1,8 +1,11 @@
 package remoteworkitem
 
 import (
+	"net/url"
+
 	"github.com/fabric8-services/fabric8-wit/gormsupport"
 	uuid "github.com/satori/go.uuid"
+	govalidator "gopkg.in/asaskevich/govalidator.v4"
 )
 
 // Tracker represents tracker configuration
@@ -14,3 +17,24 @@ type Tracker struct {
 	// Type of the tracker (jira, github, bugzilla, trello etc.)
 	Type string
 }
+
+// Validate checks that the tracker URL is valid and that the tracker type is
+// supported. Self-managed GitLab instances are addressed by their base URL, so
+// for GitLab trackers an absolute http(s) URL is required.
+// returns BadParameterError
+func (t Tracker) Validate() error {
+	if !govalidator.IsURL(t.URL) {
+		return BadParameterError{parameter: "url", value: t.URL}
+	}
+	// Ensure we support this remote tracker.
+	if _, present := RemoteWorkItemImplRegistry[t.Type]; !present {
+		return BadParameterError{parameter: "type", value: t.Type}
+	}
+	if t.Type == ProviderGitlab {
+		u, err := url.Parse(t.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return BadParameterError{parameter: "url", value: t.URL}
+		}
+	}
+	return nil
+}
@@ -11,7 +11,6 @@ import (
 	"github.com/goadesign/goa"
 	"github.com/jinzhu/gorm"
 	uuid "github.com/satori/go.uuid"
-	govalidator "gopkg.in/asaskevich/govalidator.v4"
 )
 
 // APIStringTypeTracker helps to avoid string literal
@@ -42,16 +41,8 @@ func NewTrackerRepository(db *gorm.DB) *GormTrackerRepository {
 // Create creates a new tracker configuration in the repository
 // returns BadParameterError, ConversionError or InternalError
 func (r *GormTrackerRepository) Create(ctx context.Context, t *Tracker) error {
-	//URL Validation
-	isValid := govalidator.IsURL(t.URL)
-	if isValid != true {
-		return BadParameterError{parameter: "url", value: t.URL}
-	}
-
-	_, present := RemoteWorkItemImplRegistry[t.Type]
-	// Ensure we support this remote tracker.
-	if present != true {
-		return BadParameterError{parameter: "type", value: t.Type}
+	if err := t.Validate(); err != nil {
+		return err
 	}
 	if err := r.db.Create(&t).Error; err != nil {
 		return InternalError{simpleError{err.Error()}}
@@ -116,10 +107,11 @@ func (r *GormTrackerRepository) Save(ctx context.Context, t *Tracker) (*Tracker,
 		}, "tracker repository not found")
 		return nil, errors.NewNotFoundError("tracker", t.ID.String())
 	}
-	_, present := RemoteWorkItemImplRegistry[t.Type]
-	// Ensure we support this remote tracker.
-	if present != true {
-		return nil, errors.NewBadParameterError("type", t.Type)
+	if err := t.Validate(); err != nil {
+		if e, ok := err.(BadParameterError); ok {
+			return nil, errors.NewBadParameterError(e.parameter, e.value)
+		}
+		return nil, err
 	}
 
 	if err := tx.Save(&t).Error; err != nil {
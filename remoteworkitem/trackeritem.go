@@ -1,6 +1,8 @@
 package remoteworkitem
 
 import (
+	"time"
+
 	"github.com/fabric8-services/fabric8-wit/gormsupport"
 	uuid "github.com/satori/go.uuid"
 )
@@ -16,4 +18,27 @@ type TrackerItem struct {
 	Item string
 	// FK to tracker
 	TrackerID uuid.UUID `gorm:"ForeignKey:Tracker"`
+	SyncStatus
+}
+
+// SyncStatus records the last synchronization between a remote tracker item
+// and the local work item it was imported into
+type SyncStatus struct {
+	// WorkItemID is the ID of the local work item
+	WorkItemID *uuid.UUID `sql:"type:uuid"`
+	// RemoteUpdatedAt is the update timestamp of the remote item at the last
+	// successful pull or push
+	RemoteUpdatedAt *time.Time
+	// SyncedVersion is the version of the local work item at the last
+	// successful pull or push
+	SyncedVersion *int
+	// LastPulledAt is when the remote item was last written to the work item
+	LastPulledAt *time.Time
+	// LastPushedAt is when the work item was last written to the remote item
+	LastPushedAt *time.Time
+	// SyncConflict describes why the remote item and the work item could not
+	// be synchronized because both changed since the last sync
+	SyncConflict *string
+	// SyncError holds the error of the last failed push
+	SyncError *string
 }
@@ -2,11 +2,13 @@ package remoteworkitem
 
 import (
 	"fmt"
+	"strings"
 
 	"context"
 
 	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/criteria"
+	"github.com/fabric8-services/fabric8-wit/label"
 	"github.com/fabric8-services/fabric8-wit/log"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 
@@ -107,6 +109,18 @@ func lookupIdentities(ctx context.Context, db *gorm.DB, remoteWorkItem RemoteWor
 			workItem.Fields[workitem.SystemAssignees] = identities
 		} else if fieldName == RemoteAssigneeProfileURLs {
 			// ignore here, it is being processed above
+		} else
+		// labels
+		if fieldName == RemoteLabelNames {
+			if fieldValue == nil {
+				workItem.Fields[workitem.SystemLabels] = make([]string, 0)
+				continue
+			}
+			labelIDs, err := lookupLabels(ctx, db, spaceID, fieldValue.([]string))
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to create labels during lookup")
+			}
+			workItem.Fields[workitem.SystemLabels] = labelIDs
 		} else {
 			// copy other fields
 			workItem.Fields[fieldName] = fieldValue
@@ -115,6 +129,37 @@ func lookupIdentities(ctx context.Context, db *gorm.DB, remoteWorkItem RemoteWor
 	return &workItem, nil
 }
 
+// lookupLabels looks up the labels with the given names in the space and
+// creates the ones that don't exist yet. It returns the IDs of the labels.
+func lookupLabels(ctx context.Context, db *gorm.DB, spaceID uuid.UUID, names []string) ([]string, error) {
+	labelRepository := label.NewLabelRepository(db)
+	existing, err := labelRepository.List(ctx, spaceID)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	labelIDs := map[string]uuid.UUID{}
+	for _, l := range existing {
+		labelIDs[l.Name] = l.ID
+	}
+	result := make([]string, 0, len(names))
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			continue
+		}
+		id, ok := labelIDs[name]
+		if !ok {
+			l := label.Label{SpaceID: spaceID, Name: name}
+			if err := labelRepository.Create(ctx, &l); err != nil {
+				return nil, errors.WithStack(err)
+			}
+			id = l.ID
+			labelIDs[name] = id
+		}
+		result = append(result, id.String())
+	}
+	return result, nil
+}
+
 func upsert(ctx context.Context, db *gorm.DB, workItem workitem.WorkItem) (*workitem.WorkItem, error) {
 	wir := workitem.NewWorkItemRepository(db)
 	// Get the remote item identifier ( which is currently the url ) to check if the work item exists in the database.
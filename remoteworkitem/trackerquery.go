@@ -1,6 +1,8 @@
 package remoteworkitem
 
 import (
+	"time"
+
 	"github.com/fabric8-services/fabric8-wit/gormsupport"
 
 	uuid "github.com/satori/go.uuid"
@@ -18,4 +20,10 @@ type TrackerQuery struct {
 	TrackerID uuid.UUID `gorm:"ForeignKey:Tracker"`
 	// SpaceID is a foreign key for a space
 	SpaceID uuid.UUID `gorm:"ForeignKey:Space"`
+	// PushEnabled tells if local changes of the imported work items are
+	// written back to the remote tracker
+	PushEnabled bool
+	// LastRemoteUpdatedAt is the newest update timestamp of the remote items
+	// fetched so far. Only items updated since then are fetched.
+	LastRemoteUpdatedAt *time.Time
 }
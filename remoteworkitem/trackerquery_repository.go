@@ -31,7 +31,7 @@ func NewTrackerQueryRepository(db *gorm.DB) *GormTrackerQueryRepository {
 // TrackerQueryRepository encapsulate storage & retrieval of tracker queries
 type TrackerQueryRepository interface {
 	CheckExists(ctx context.Context, id string) error
-	Create(ctx context.Context, query string, schedule string, tracker uuid.UUID, spaceID uuid.UUID) (*app.TrackerQuery, error)
+	Create(ctx context.Context, query string, schedule string, tracker uuid.UUID, spaceID uuid.UUID, pushEnabled bool) (*app.TrackerQuery, error)
 	Save(ctx context.Context, tq app.TrackerQuery) (*app.TrackerQuery, error)
 	Load(ctx context.Context, ID string) (*app.TrackerQuery, error)
 	Delete(ctx context.Context, ID string) error
@@ -40,12 +40,13 @@ type TrackerQueryRepository interface {
 
 // Create creates a new tracker query in the repository
 // returns BadParameterError, ConversionError or InternalError
-func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, trackerID uuid.UUID, spaceID uuid.UUID) (*app.TrackerQuery, error) {
+func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, schedule string, trackerID uuid.UUID, spaceID uuid.UUID, pushEnabled bool) (*app.TrackerQuery, error) {
 	tq := TrackerQuery{
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: trackerID,
-		SpaceID:   spaceID,
+		Query:       query,
+		Schedule:    schedule,
+		TrackerID:   trackerID,
+		SpaceID:     spaceID,
+		PushEnabled: pushEnabled,
 	}
 	tx := r.db
 	if err := tx.Create(&tq).Error; err != nil {
@@ -59,10 +60,11 @@ func (r *GormTrackerQueryRepository) Create(ctx context.Context, query string, s
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx).Request, app.SpaceHref(spaceID.String()))
 	tq2 := app.TrackerQuery{
-		ID:        strconv.FormatUint(tq.ID, 10),
-		Query:     query,
-		Schedule:  schedule,
-		TrackerID: trackerID,
+		ID:          strconv.FormatUint(tq.ID, 10),
+		Query:       query,
+		Schedule:    schedule,
+		TrackerID:   trackerID,
+		PushEnabled: pushEnabled,
 		Relationships: &app.TrackerQueryRelationships{
 			Space: app.NewSpaceRelation(spaceID, spaceSelfURL),
 		},
@@ -99,10 +101,11 @@ func (r *GormTrackerQueryRepository) Load(ctx context.Context, ID string) (*app.
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx).Request, app.SpaceHref(res.SpaceID.String()))
 	tq := app.TrackerQuery{
-		ID:        strconv.FormatUint(res.ID, 10),
-		Query:     res.Query,
-		Schedule:  res.Schedule,
-		TrackerID: res.TrackerID,
+		ID:          strconv.FormatUint(res.ID, 10),
+		Query:       res.Query,
+		Schedule:    res.Schedule,
+		TrackerID:   res.TrackerID,
+		PushEnabled: res.PushEnabled,
 		Relationships: &app.TrackerQueryRelationships{
 			Space: app.NewSpaceRelation(res.SpaceID, spaceSelfURL),
 		},
@@ -156,11 +159,16 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 	}
 
 	newTq := TrackerQuery{
-		ID:        id,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID,
-		SpaceID:   *tq.Relationships.Space.Data.ID,
+		ID:          id,
+		Schedule:    tq.Schedule,
+		Query:       tq.Query,
+		TrackerID:   tq.TrackerID,
+		SpaceID:     *tq.Relationships.Space.Data.ID,
+		PushEnabled: tq.PushEnabled,
+	}
+	// items that match the new query may not have been fetched yet
+	if res.Query == newTq.Query && res.TrackerID == newTq.TrackerID {
+		newTq.LastRemoteUpdatedAt = res.LastRemoteUpdatedAt
 	}
 
 	if err := tx.Save(&newTq).Error; err != nil {
@@ -178,10 +186,11 @@ func (r *GormTrackerQueryRepository) Save(ctx context.Context, tq app.TrackerQue
 
 	spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx).Request, app.SpaceHref(tq.Relationships.Space.Data.ID.String()))
 	t2 := app.TrackerQuery{
-		ID:        tq.ID,
-		Schedule:  tq.Schedule,
-		Query:     tq.Query,
-		TrackerID: tq.TrackerID,
+		ID:          tq.ID,
+		Schedule:    tq.Schedule,
+		Query:       tq.Query,
+		TrackerID:   tq.TrackerID,
+		PushEnabled: tq.PushEnabled,
 		Relationships: &app.TrackerQueryRelationships{
 			Space: app.NewSpaceRelation(*tq.Relationships.Space.Data.ID, spaceSelfURL),
 		},
@@ -222,10 +231,11 @@ func (r *GormTrackerQueryRepository) List(ctx context.Context) ([]*app.TrackerQu
 	for i, tq := range rows {
 		spaceSelfURL := rest.AbsoluteURL(goa.ContextRequest(ctx).Request, app.SpaceHref(tq.SpaceID.String()))
 		t := app.TrackerQuery{
-			ID:        strconv.FormatUint(tq.ID, 10),
-			Schedule:  tq.Schedule,
-			Query:     tq.Query,
-			TrackerID: tq.TrackerID,
+			ID:          strconv.FormatUint(tq.ID, 10),
+			Schedule:    tq.Schedule,
+			Query:       tq.Query,
+			TrackerID:   tq.TrackerID,
+			PushEnabled: tq.PushEnabled,
 			Relationships: &app.TrackerQueryRelationships{
 				Space: app.NewSpaceRelation(tq.SpaceID, spaceSelfURL),
 			},
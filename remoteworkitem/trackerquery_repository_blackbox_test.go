@@ -34,7 +34,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailDeleteZeroID() {
 		s.Ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		fxt.Trackers[0].ID, fxt.Spaces[0].ID)
+		fxt.Trackers[0].ID, fxt.Spaces[0].ID, false)
 	require.NoError(s.T(), err)
 
 	err = s.repo.Delete(s.Ctx, "0")
@@ -49,7 +49,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFailSaveZeroID() {
 		s.Ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		fxt.Trackers[0].ID, fxt.Spaces[0].ID)
+		fxt.Trackers[0].ID, fxt.Spaces[0].ID, false)
 	require.NoError(s.T(), err)
 	tq.ID = "0"
 
@@ -65,7 +65,7 @@ func (s *trackerQueryRepoBlackBoxTest) TestFaiLoadZeroID() {
 		s.Ctx,
 		"project = ARQ AND text ~ 'arquillian'",
 		"15 * * * * *",
-		fxt.Trackers[0].ID, fxt.Spaces[0].ID)
+		fxt.Trackers[0].ID, fxt.Spaces[0].ID, false)
 	require.NoError(s.T(), err)
 
 	_, err = s.repo.Load(s.Ctx, "0")
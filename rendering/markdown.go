@@ -34,7 +34,13 @@ const (
 // MarkdownCommonHighlighter uses the blackfriday.MarkdownCommon setup but also includes
 // code-prettify formatting of BlockCode segments
 func MarkdownCommonHighlighter(input []byte) []byte {
-	renderer := highlightHTMLRenderer{blackfriday.HtmlRenderer(commonHTMLFlags, "", ""), 0}
+	return markdownCommonHighlighter(input, nil)
+}
+
+// markdownCommonHighlighter renders like MarkdownCommonHighlighter and links
+// the references which the given resolver resolves
+func markdownCommonHighlighter(input []byte, resolve ReferenceResolver) []byte {
+	renderer := highlightHTMLRenderer{blackfriday.HtmlRenderer(commonHTMLFlags, "", ""), 0, resolve}
 	return blackfriday.MarkdownOptions(input, &renderer, blackfriday.Options{
 		Extensions: commonExtensions})
 }
@@ -42,6 +48,31 @@ func MarkdownCommonHighlighter(input []byte) []byte {
 type highlightHTMLRenderer struct {
 	blackfriday.Renderer
 	checkboxIndex int8
+	resolve       ReferenceResolver
+}
+
+// NormalText overrides the default NormalText render to link the references
+// in the text. Code is not rendered through here so references in code stay
+// as they are.
+func (h *highlightHTMLRenderer) NormalText(out *bytes.Buffer, text []byte) {
+	if h.resolve == nil {
+		h.Renderer.NormalText(out, text)
+		return
+	}
+	writeReferenceLinks(out, string(text), h.resolve, func(out *bytes.Buffer, text string) {
+		if text != "" {
+			h.Renderer.NormalText(out, []byte(text))
+		}
+	})
+}
+
+// Link overrides the default Link render to avoid links to references within
+// the content of a link
+func (h *highlightHTMLRenderer) Link(out *bytes.Buffer, link []byte, title []byte, content []byte) {
+	if h.resolve != nil {
+		content = referenceLinkPattern.ReplaceAll(content, []byte("$1"))
+	}
+	h.Renderer.Link(out, link, title, content)
 }
 
 // ListItem overrides the default ListItem render and adds support for GH-style
@@ -1,6 +1,7 @@
 package rendering
 
 import (
+	"bytes"
 	"html"
 	"regexp"
 
@@ -18,14 +19,29 @@ func IsMarkupSupported(markup string) bool {
 // RenderMarkupToHTML converts the given `content` in HTML using the markup tool corresponding to the given `markup` argument
 // or return nil if no tool for the given `markup` is available, or returns an `error` if the command was not found or failed.
 func RenderMarkupToHTML(content, markup string) string {
+	return RenderMarkupToHTMLWithReferences(content, markup, nil)
+}
+
+// RenderMarkupToHTMLWithReferences converts the given `content` in HTML like
+// RenderMarkupToHTML and renders the @mentions and #references which the given
+// resolver resolves as links. A nil resolver leaves all references as text.
+func RenderMarkupToHTMLWithReferences(content, markup string, resolve ReferenceResolver) string {
 	switch markup {
 	case SystemMarkupPlainText:
-		return html.EscapeString(content)
+		if resolve == nil {
+			return html.EscapeString(content)
+		}
+		var out bytes.Buffer
+		writeReferenceLinks(&out, content, resolve, func(out *bytes.Buffer, text string) {
+			out.WriteString(html.EscapeString(text))
+		})
+		return out.String()
 	case SystemMarkupMarkdown:
-		unsafe := MarkdownCommonHighlighter([]byte(content))
+		unsafe := markdownCommonHighlighter([]byte(content), resolve)
 		p := bluemonday.UGCPolicy()
 		p.AllowAttrs("class").Matching(regexp.MustCompile("^language-[a-zA-Z0-9]+$|prettyprint")).OnElements("code")
 		p.AllowAttrs("class").OnElements("span")
+		p.AllowAttrs("class").Matching(regexp.MustCompile("^(mention|work-item-reference)$")).OnElements("a")
 		p.AllowElements("input")
 		p.AllowAttrs("type").OnElements("input")
 		p.AllowAttrs("checked").OnElements("input")
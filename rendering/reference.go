@@ -0,0 +1,122 @@
+package rendering
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+)
+
+// The kinds of references in markup
+const (
+	// ReferenceUser is an @mention of a user
+	ReferenceUser = "user"
+	// ReferenceWorkItem is a #reference to a work item
+	ReferenceWorkItem = "workitem"
+)
+
+// Reference is an @mention of a user or a #reference to a work item in markup
+type Reference struct {
+	// Text is the reference as written, e.g. "@alice", "#12" or "alice/space#12"
+	Text string
+	// Kind is either ReferenceUser or ReferenceWorkItem
+	Kind string
+	// Username is the name of the mentioned user
+	Username string
+	// OwnerName and SpaceName identify the space of the referenced work item.
+	// Both are empty for the space of the markup. Only the OwnerName is empty
+	// for another space of the same owner.
+	OwnerName string
+	SpaceName string
+	// Number is the number of the referenced work item in its space
+	Number int
+}
+
+// ReferenceResolver returns the URL to which a reference links or false if
+// the reference doesn't resolve and is rendered as text
+type ReferenceResolver func(Reference) (string, bool)
+
+// referencePattern matches "@username", "#12", "space#12", "space/#12",
+// "owner/space#12" and "owner/space/#12". E-mail addresses, URL fragments and
+// HTML entities like "&#39;" don't match because of the character which
+// precedes them.
+var referencePattern = regexp.MustCompile(`(^|[^\w&#/.@-])(?:@([A-Za-z0-9](?:[\w.-]*\w)?)|(?:([\w.-]+)/)?(?:([\w.-]+)/?)?#(\d+)\b)`)
+
+// referenceLinkPattern matches the links rendered for references
+var referenceLinkPattern = regexp.MustCompile(`<a href="[^"]*" class="(?:mention|work-item-reference)">([^<]*)</a>`)
+
+// referenceMatch is a reference along with its position in a text
+type referenceMatch struct {
+	Reference
+	start, end int
+}
+
+// findReferenceMatches returns the references in the given text in their
+// order of appearance
+func findReferenceMatches(text string) []referenceMatch {
+	var result []referenceMatch
+	for _, m := range referencePattern.FindAllStringSubmatchIndex(text, -1) {
+		// skip the character preceding the reference
+		start := m[3]
+		ref := Reference{Text: text[start:m[1]]}
+		if m[4] >= 0 {
+			ref.Kind = ReferenceUser
+			ref.Username = text[m[4]:m[5]]
+		} else {
+			number, err := strconv.Atoi(text[m[10]:m[11]])
+			if err != nil {
+				continue // too large to be a work item number
+			}
+			ref.Kind = ReferenceWorkItem
+			ref.Number = number
+			switch {
+			case m[6] >= 0 && m[8] >= 0:
+				ref.OwnerName = text[m[6]:m[7]]
+				ref.SpaceName = text[m[8]:m[9]]
+			case m[6] >= 0:
+				ref.SpaceName = text[m[6]:m[7]]
+			case m[8] >= 0:
+				ref.SpaceName = text[m[8]:m[9]]
+			}
+		}
+		result = append(result, referenceMatch{Reference: ref, start: start, end: m[1]})
+	}
+	return result
+}
+
+// writeReferenceLinks writes the text to the buffer with the resolved
+// references as links. The other parts are written by the given function.
+func writeReferenceLinks(out *bytes.Buffer, text string, resolve ReferenceResolver, writeText func(out *bytes.Buffer, text string)) {
+	pos := 0
+	for _, m := range findReferenceMatches(text) {
+		url, ok := resolve(m.Reference)
+		if !ok {
+			continue
+		}
+		writeText(out, text[pos:m.start])
+		class := "mention"
+		if m.Kind == ReferenceWorkItem {
+			class = "work-item-reference"
+		}
+		fmt.Fprintf(out, `<a href="%s" class="%s">%s</a>`, html.EscapeString(url), class, html.EscapeString(m.Text))
+		pos = m.end
+	}
+	writeText(out, text[pos:])
+}
+
+// FindReferences returns the distinct references in the given content in their
+// order of appearance. References in Markdown code are ignored.
+func FindReferences(content, markup string) []Reference {
+	var result []Reference
+	seen := map[string]bool{}
+	collect := func(ref Reference) (string, bool) {
+		if !seen[ref.Text] {
+			seen[ref.Text] = true
+			result = append(result, ref)
+		}
+		return "", false
+	}
+	RenderMarkupToHTMLWithReferences(content, markup, collect)
+	return result
+}
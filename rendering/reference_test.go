@@ -0,0 +1,69 @@
+package rendering_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindReferences(t *testing.T) {
+	t.Run("markdown", func(t *testing.T) {
+		content := "@alice, see #12 and space#3, space/#4, owner/space#5 or owner/space/#6. Again @alice!\n\n" +
+			"Not `@bob` nor bob@example.com, http://example.com/#7, it&#39;s #8a or [#9](http://example.com/#9)\n\n" +
+			"```\n@carol #10\n```"
+		// when
+		refs := rendering.FindReferences(content, rendering.SystemMarkupMarkdown)
+		// then
+		assert.Equal(t, []rendering.Reference{
+			{Text: "@alice", Kind: rendering.ReferenceUser, Username: "alice"},
+			{Text: "#12", Kind: rendering.ReferenceWorkItem, Number: 12},
+			{Text: "space#3", Kind: rendering.ReferenceWorkItem, SpaceName: "space", Number: 3},
+			{Text: "space/#4", Kind: rendering.ReferenceWorkItem, SpaceName: "space", Number: 4},
+			{Text: "owner/space#5", Kind: rendering.ReferenceWorkItem, OwnerName: "owner", SpaceName: "space", Number: 5},
+			{Text: "owner/space/#6", Kind: rendering.ReferenceWorkItem, OwnerName: "owner", SpaceName: "space", Number: 6},
+			{Text: "#9", Kind: rendering.ReferenceWorkItem, Number: 9},
+		}, refs)
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		refs := rendering.FindReferences("`@bob` #1", rendering.SystemMarkupPlainText)
+		assert.Equal(t, []rendering.Reference{
+			{Text: "@bob", Kind: rendering.ReferenceUser, Username: "bob"},
+			{Text: "#1", Kind: rendering.ReferenceWorkItem, Number: 1},
+		}, refs)
+	})
+}
+
+func TestRenderMarkupToHTMLWithReferences(t *testing.T) {
+	resolve := func(ref rendering.Reference) (string, bool) {
+		switch ref.Text {
+		case "@alice":
+			return "https://api.example.com/api/users/1", true
+		case "#12":
+			return "https://api.example.com/api/workitems/2", true
+		}
+		return "", false
+	}
+
+	t.Run("markdown", func(t *testing.T) {
+		// when
+		result := rendering.RenderMarkupToHTMLWithReferences("@alice fixed #12, not #13 or [#12](http://example.com)", rendering.SystemMarkupMarkdown, resolve)
+		// then
+		assert.Contains(t, result, `<a href="https://api.example.com/api/users/1" class="mention"`)
+		assert.Contains(t, result, `<a href="https://api.example.com/api/workitems/2" class="work-item-reference"`)
+		assert.Contains(t, result, "not #13 or")
+		assert.Contains(t, result, `>#12</a></p>`)
+		assert.Equal(t, 3, strings.Count(result, "<a "), "no link within a link")
+	})
+
+	t.Run("plain text", func(t *testing.T) {
+		result := rendering.RenderMarkupToHTMLWithReferences("<b>@alice</b>", rendering.SystemMarkupPlainText, resolve)
+		assert.Equal(t, `&lt;b&gt;<a href="https://api.example.com/api/users/1" class="mention">@alice</a>&lt;/b&gt;`, result)
+	})
+
+	t.Run("without resolver", func(t *testing.T) {
+		assert.Equal(t, "<p>@alice fixed #12</p>\n", rendering.RenderMarkupToHTMLWithReferences("@alice fixed #12", rendering.SystemMarkupMarkdown, nil))
+	})
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"runtime/debug"
 	"testing"
+	"time"
 
 	c "github.com/fabric8-services/fabric8-wit/criteria"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/resource"
 	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -307,15 +311,70 @@ func TestParseMap(t *testing.T) {
 		actualQuery := Query{}
 		parseMap(fm, &actualQuery)
 		// then
-		new := "NEW"
-		open := "OPEN"
-		expectedQuery := Query{Name: OR, Children: []Query{
-			{Name: "status", Value: &new},
-			{Name: "status", Value: &open}},
-		}
+		expectedQuery := Query{Name: "status", In: []string{"NEW", "OPEN"}}
 		assert.Equal(t, expectedQuery, actualQuery)
 	})
 
+	for _, op := range []string{GT, GTE, LT, LTE} {
+		op := op
+		t.Run(op, func(t *testing.T) {
+			t.Parallel()
+			// given
+			input := fmt.Sprintf(`{"created": { "%s": "2026-01-01T00:00:00Z"}}`, op)
+			// Parsing/Unmarshalling JSON encoding/json
+			fm := map[string]interface{}{}
+			err := json.Unmarshal([]byte(input), &fm)
+			require.NoError(t, err)
+			// when
+			actualQuery := Query{}
+			parseMap(fm, &actualQuery)
+			// then
+			created := "2026-01-01T00:00:00Z"
+			expectedQuery := Query{Name: "created", Value: &created, Comparison: op}
+			assert.Equal(t, expectedQuery, actualQuery)
+		})
+	}
+
+	for _, op := range []string{NE, GT, LTE} {
+		op := op
+		t.Run(op+" with a number", func(t *testing.T) {
+			t.Parallel()
+			// given
+			input := fmt.Sprintf(`{"number": { "%s": 42}}`, op)
+			fm := map[string]interface{}{}
+			err := json.Unmarshal([]byte(input), &fm)
+			require.NoError(t, err)
+			// when
+			actualQuery := Query{}
+			err = parseMap(fm, &actualQuery)
+			// then
+			require.NoError(t, err)
+			require.NotNil(t, actualQuery.Value)
+			assert.Equal(t, "42", *actualQuery.Value)
+		})
+	}
+
+	for _, input := range []string{
+		`{"title": { "$SUBSTR": true}}`,
+		`{"number": { "$GT": {"value": 1}}}`,
+		`{"state": { "$IN": "new"}}`,
+		`{"state": { "$IN": ["new", false]}}`,
+	} {
+		input := input
+		t.Run("unsupported value "+input, func(t *testing.T) {
+			t.Parallel()
+			// given
+			fm := map[string]interface{}{}
+			err := json.Unmarshal([]byte(input), &fm)
+			require.NoError(t, err)
+			// when
+			err = parseMap(fm, &Query{})
+			// then
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, err)
+		})
+	}
+
 	t.Run(OPTS, func(t *testing.T) {
 		t.Parallel()
 		// given
@@ -366,7 +425,7 @@ func TestParseFilterString(t *testing.T) {
 	t.Run("OPTS with other query", func(t *testing.T) {
 
 		input := fmt.Sprintf(`{"$AND":[{"title":"some"},{"state":"new"}],"%s": {"parent-exists": true, "tree-view": true}}`, OPTS)
-		actualExpr, options, err := ParseFilterString(context.Background(), input)
+		actualExpr, options, err := ParseFilterString(context.Background(), input, nil)
 		expectedExpr := c.And(
 			c.Equals(
 				c.Field("system.title"),
@@ -384,6 +443,41 @@ func TestParseFilterString(t *testing.T) {
 	})
 }
 
+func TestFilterSpaceID(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	spaceID := uuid.NewV4()
+	t.Run("single space", func(t *testing.T) {
+		for _, input := range []string{
+			fmt.Sprintf(`{"space":"%s"}`, spaceID),
+			fmt.Sprintf(`{"space":{"$EQ":"%s"}}`, spaceID),
+			fmt.Sprintf(`{"$AND":[{"title":"some"},{"space":"%s"}]}`, spaceID),
+			fmt.Sprintf(`{"$AND":[{"$AND":[{"space":"%s"}]},{"state":"new"}]}`, spaceID),
+		} {
+			actual, err := FilterSpaceID(context.Background(), input)
+			require.NoError(t, err, input)
+			require.NotNil(t, actual, input)
+			assert.Equal(t, spaceID, *actual, input)
+		}
+	})
+	t.Run("no single space", func(t *testing.T) {
+		for _, input := range []string{
+			`{"title":"some"}`,
+			fmt.Sprintf(`{"space":{"$NE":"%s"}}`, spaceID),
+			fmt.Sprintf(`{"$OR":[{"space":"%s"},{"space":"%s"}]}`, spaceID, uuid.NewV4()),
+			`{"space":"not a UUID"}`,
+		} {
+			actual, err := FilterSpaceID(context.Background(), input)
+			require.NoError(t, err, input)
+			assert.Nil(t, actual, input)
+		}
+	})
+	t.Run("invalid JSON", func(t *testing.T) {
+		_, err := FilterSpaceID(context.Background(), `{"space":`)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
 func TestGenerateExpression(t *testing.T) {
 	resource.Require(t, resource.UnitTest)
 	t.Parallel()
@@ -393,7 +487,7 @@ func TestGenerateExpression(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "space", Value: &spaceName}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.Equals(
 			c.Field("SpaceID"),
@@ -408,7 +502,7 @@ func TestGenerateExpression(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "space", Value: &spaceName, Negate: true}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.Not(
 			c.Field("SpaceID"),
@@ -429,7 +523,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -457,7 +551,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.Or(
 			c.Equals(
@@ -485,7 +579,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.And(
 			c.Not(
@@ -512,7 +606,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -532,7 +626,7 @@ func TestGenerateExpression(t *testing.T) {
 			Name: "assignee", Value: nil,
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.IsNull("system.assignees")
 
@@ -546,7 +640,7 @@ func TestGenerateExpression(t *testing.T) {
 			Name: "assignee", Value: nil, Negate: true,
 		}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(context.Background(), nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -565,7 +659,7 @@ func TestGenerateExpression(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(context.Background(), nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -586,6 +680,214 @@ func expectEqualExpr(t *testing.T, expectedExpr, actualExpr c.Expression) {
 	require.Equal(t, expectedJoins, actualJoins, "joins differ")
 }
 
+// fieldResolver resolves the fields of a fixed set of definitions
+type fieldResolver map[string]workitem.FieldDefinition
+
+func (r fieldResolver) LoadFieldDefinition(ctx context.Context, name string) (*workitem.FieldDefinition, error) {
+	def, ok := r[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("work item field", name)
+	}
+	return &def, nil
+}
+
+func TestGenerateInExpression(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	t.Run("field", func(t *testing.T) {
+		t.Parallel()
+		// given
+		q := Query{Name: "state", In: []string{"new", "open"}}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.In(
+			c.Field(workitem.SystemState),
+			c.Literal([]interface{}{"new", "open"}),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+	t.Run("typed column", func(t *testing.T) {
+		t.Parallel()
+		// given
+		q := Query{Name: "number", In: []string{"1", "2"}}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.In(
+			c.Field("Number"),
+			c.Literal([]interface{}{int64(1), int64(2)}),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+	t.Run("list", func(t *testing.T) {
+		t.Parallel()
+		// given
+		q := Query{Name: "label", In: []string{"a", "b"}}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.Or(
+			c.Equals(c.Field(workitem.SystemLabels), c.Literal([]string{"a"})),
+			c.Equals(c.Field(workitem.SystemLabels), c.Literal([]string{"b"})),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+}
+
+func TestGenerateCustomFieldExpression(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	fields := fieldResolver{
+		"effort":      {Type: workitem.SimpleType{Kind: workitem.KindFloat}},
+		"target_date": {Type: workitem.SimpleType{Kind: workitem.KindInstant}},
+		"severity": {Type: workitem.EnumType{
+			SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+			BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+			Values:     []interface{}{"high", "low"},
+		}},
+		"age": {Type: workitem.ComputedType{
+			SimpleType: workitem.SimpleType{Kind: workitem.KindComputed},
+			ResultType: workitem.SimpleType{Kind: workitem.KindInteger},
+			Formula:    workitem.Formula{Function: workitem.FormulaDaysSince, Fields: []string{workitem.SystemCreatedAt}},
+		}},
+	}
+	t.Run("equality", func(t *testing.T) {
+		t.Parallel()
+		// given
+		effort := "3.5"
+		q := Query{Name: "effort", Value: &effort}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.NoError(t, err)
+		expectEqualExpr(t, c.Equals(workitem.JSONField("effort"), c.Literal(3.5)), actualExpr)
+	})
+	t.Run("instant comparison", func(t *testing.T) {
+		t.Parallel()
+		// given
+		targetDate := "2026-01-01T00:00:00Z"
+		q := Query{Name: "target_date", Value: &targetDate, Comparison: LT}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.Less(
+			workitem.JSONField("target_date"),
+			c.Literal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+	t.Run("enum", func(t *testing.T) {
+		t.Parallel()
+		// given
+		q := Query{Name: "severity", In: []string{"high", "low"}}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.NoError(t, err)
+		expectEqualExpr(t, c.In(workitem.JSONField("severity"), c.Literal([]interface{}{"high", "low"})), actualExpr)
+	})
+	t.Run("value of the wrong kind", func(t *testing.T) {
+		t.Parallel()
+		// given
+		effort := "a lot"
+		q := Query{Name: "effort", Value: &effort, Comparison: GT}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+		require.Nil(t, actualExpr)
+	})
+	t.Run("field which changes as time passes", func(t *testing.T) {
+		t.Parallel()
+		// given
+		age := "10"
+		q := Query{Name: "age", Value: &age, Comparison: GT}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+		require.Nil(t, actualExpr)
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		t.Parallel()
+		// given
+		effort := "3"
+		q := Query{Name: "unknown", Value: &effort}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), fields)
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, err)
+		require.Nil(t, actualExpr)
+	})
+}
+
+func TestGenerateComparisonExpression(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Parallel()
+	t.Run("instant", func(t *testing.T) {
+		t.Parallel()
+		// given
+		created := "2026-01-01T00:00:00Z"
+		q := Query{Name: "created", Value: &created, Comparison: GT}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.Greater(
+			c.Field("CreatedAt"),
+			c.Literal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+	t.Run("number within $AND", func(t *testing.T) {
+		t.Parallel()
+		// given
+		spaceName := "openshiftio"
+		number := "5"
+		q := Query{
+			Name: AND,
+			Children: []Query{
+				{Name: "space", Value: &spaceName},
+				{Name: "number", Value: &number, Comparison: LTE},
+			},
+		}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.NoError(t, err)
+		expectedExpr := c.And(
+			c.Equals(
+				c.Field("SpaceID"),
+				c.Literal(spaceName),
+			),
+			c.LessOrEquals(
+				c.Field("Number"),
+				c.Literal(int64(5)),
+			),
+		)
+		expectEqualExpr(t, expectedExpr, actualExpr)
+	})
+	t.Run("unknown operator", func(t *testing.T) {
+		t.Parallel()
+		// given
+		number := "5"
+		q := Query{Name: "number", Value: &number, Comparison: "$FOO"}
+		// when
+		actualExpr, err := q.generateExpression(context.Background(), nil)
+		// then
+		require.Error(t, err)
+		require.Nil(t, actualExpr)
+	})
+}
+
 func TestWorkItemNumber(t *testing.T) {
 	t.Run("search by number", func(t *testing.T) {
 		// given
@@ -600,7 +902,7 @@ func TestWorkItemNumber(t *testing.T) {
 			},
 		}
 		// when
-		actualExpr, _ := q.generateExpression()
+		actualExpr, _ := q.generateExpression(context.Background(), nil)
 		// then
 		expectedExpr := c.And(
 			c.Equals(
@@ -624,7 +926,7 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		// given
 		q := Query{}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(context.Background(), nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -635,7 +937,7 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "", Value: &spaceName}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(context.Background(), nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
@@ -647,7 +949,7 @@ func TestGenerateExpressionWithNonExistingKey(t *testing.T) {
 		spaceName := "openshiftio"
 		q := Query{Name: "nonexistingkey", Value: &spaceName}
 		// when
-		actualExpr, err := q.generateExpression()
+		actualExpr, err := q.generateExpression(context.Background(), nil)
 		// then
 		require.Error(t, err)
 		require.Nil(t, actualExpr)
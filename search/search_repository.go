@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/fabric8-services/fabric8-wit/closeable"
 
@@ -17,6 +19,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/id"
 	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	"github.com/jinzhu/gorm"
@@ -37,6 +40,10 @@ const (
 	IN     = "$IN"
 	SUBSTR = "$SUBSTR"
 	OPTS   = "$OPTS"
+	GT     = "$GT"
+	GTE    = "$GTE"
+	LT     = "$LT"
+	LTE    = "$LTE"
 
 	// This is the replacement for $WITGROUP.
 	TypeGroupName = "typegroup.name"
@@ -265,12 +272,29 @@ func parseSearchString(ctx context.Context, rawSearchString string) (searchKeywo
 	return res, nil
 }
 
-func parseMap(queryMap map[string]interface{}, q *Query) {
+// queryValue converts the operand of an operator to the string held by a
+// Query. Strings are taken as they are and numbers are formatted without
+// loss; other types can't be compared with a field.
+func queryValue(op string, v interface{}) (*string, error) {
+	switch concreteVal := v.(type) {
+	case string:
+		return &concreteVal, nil
+	case float64:
+		s := strconv.FormatFloat(concreteVal, 'f', -1, 64)
+		return &s, nil
+	default:
+		return nil, errors.NewBadParameterError(op, v).Expected("string or number")
+	}
+}
+
+func parseMap(queryMap map[string]interface{}, q *Query) error {
 	for key, val := range queryMap {
 		switch concreteVal := val.(type) {
 		case []interface{}:
 			q.Name = key
-			parseArray(val.([]interface{}), &q.Children)
+			if err := parseArray(val.([]interface{}), &q.Children); err != nil {
+				return err
+			}
 		case string:
 			q.Name = key
 			s := string(concreteVal)
@@ -287,36 +311,59 @@ func parseMap(queryMap map[string]interface{}, q *Query) {
 			}
 			q.Name = key
 			if v, ok := concreteVal[IN]; ok {
-				q.Name = OR
-				c := &q.Children
-				for _, vl := range v.([]interface{}) {
-					sq := Query{}
-					sq.Name = key
-					t := vl.(string)
-					sq.Value = &t
-					*c = append(*c, sq)
+				values, ok := v.([]interface{})
+				if !ok || len(values) == 0 {
+					return errors.NewBadParameterError(IN, v).Expected("non-empty array")
+				}
+				for _, vl := range values {
+					t, err := queryValue(IN, vl)
+					if err != nil {
+						return err
+					}
+					q.In = append(q.In, *t)
 				}
 			} else if v, ok := concreteVal[EQ]; ok {
-				switch v.(type) {
-				case string:
-					s := v.(string)
-					q.Value = &s
-				case nil:
+				if v == nil {
 					q.Value = nil
+					continue
+				}
+				s, err := queryValue(EQ, v)
+				if err != nil {
+					return err
 				}
+				q.Value = s
 			} else if v, ok := concreteVal[NE]; ok {
-				s := v.(string)
-				q.Value = &s
+				s, err := queryValue(NE, v)
+				if err != nil {
+					return err
+				}
+				q.Value = s
 				q.Negate = true
 			} else if v, ok := concreteVal[SUBSTR]; ok {
-				s := v.(string)
-				q.Value = &s
+				s, err := queryValue(SUBSTR, v)
+				if err != nil {
+					return err
+				}
+				q.Value = s
 				q.Substring = true
+			} else {
+				for _, op := range []string{GT, GTE, LT, LTE} {
+					if v, ok := concreteVal[op]; ok {
+						s, err := queryValue(op, v)
+						if err != nil {
+							return err
+						}
+						q.Value = s
+						q.Comparison = op
+						break
+					}
+				}
 			}
 		default:
 			log.Error(nil, nil, "Unexpected value: %#v", val)
 		}
 	}
+	return nil
 }
 
 func parseOptions(queryMap map[string]interface{}) *QueryOptions {
@@ -337,14 +384,17 @@ func parseOptions(queryMap map[string]interface{}) *QueryOptions {
 	return nil
 }
 
-func parseArray(anArray []interface{}, l *[]Query) {
+func parseArray(anArray []interface{}, l *[]Query) error {
 	for _, val := range anArray {
 		if o, ok := val.(map[string]interface{}); ok {
 			q := Query{}
-			parseMap(o, &q)
+			if err := parseMap(o, &q); err != nil {
+				return err
+			}
 			*l = append(*l, q)
 		}
 	}
+	return nil
 }
 
 // QueryOptions represents all options provided user
@@ -372,6 +422,13 @@ type Query struct {
 	// If Substring is true, instead of exact match, anything that matches partially
 	// will be considered.
 	Substring bool
+	// Comparison holds one of the ordering operators "$GT", "$GTE", "$LT" or
+	// "$LTE" if the Value shall not be checked for equality but compared
+	// against the field.
+	Comparison string
+	// In holds the values of the "$IN" operator, one of which the field has
+	// to match. The Value is nil then.
+	In []string
 	// A Query is expected to have child queries only if the Name field contains
 	// an operator like "$AND", or "$OR". If the Name is not an operator, the
 	// Children slice MUST be empty.
@@ -397,97 +454,266 @@ var searchKeyMap = map[string]string{
 	"workitemtype": "Type", // same as 'type' - added for compatibility. (Ref. #1564)
 	"space":        "SpaceID",
 	"number":       "Number",
+	"created":      "CreatedAt",
+	"updated":      "UpdatedAt",
 }
 
-func (q Query) determineLiteralType(key string, val string) criteria.Expression {
-	switch key {
-	case workitem.SystemAssignees, workitem.SystemLabels, workitem.SystemBoardcolumns, workitem.SystemBoard:
-		return criteria.Literal([]string{val})
+// searchKeyKinds holds the kinds of the values of the keys in searchKeyMap
+// which aren't strings
+var searchKeyKinds = map[string]workitem.Kind{
+	"Number":    workitem.KindInteger,
+	"CreatedAt": workitem.KindInstant,
+	"UpdatedAt": workitem.KindInstant,
+}
+
+// FieldResolver looks up the definition of a work item field by its name. It
+// allows filter queries to refer to custom and computed fields of the work
+// item types of the queried space (see workitem.SpaceTemplateFields).
+type FieldResolver interface {
+	LoadFieldDefinition(ctx context.Context, name string) (*workitem.FieldDefinition, error)
+}
+
+// queryField is the work item field a key of the query language refers to
+type queryField struct {
+	name string
+	// kind is the kind of the single values of the field. It is empty for
+	// fields of joined tables, whose values are compared as they look like.
+	kind workitem.Kind
+	// list is true if the field holds a list of values
+	list bool
+	// custom is true for fields resolved through the work item types, which
+	// are stored in the jsonb "fields" column
+	custom bool
+}
+
+// resolveField returns the field the given key of the query language refers
+// to. Keys which are neither known to the query language nor handled by one of
+// the default table joins are looked up in the work item types.
+func resolveField(ctx context.Context, fields FieldResolver, key string) (*queryField, error) {
+	if name, ok := searchKeyMap[key]; ok {
+		kind, ok := searchKeyKinds[name]
+		if !ok {
+			kind = workitem.KindString
+		}
+		switch name {
+		case workitem.SystemAssignees, workitem.SystemLabels, workitem.SystemBoardcolumns, workitem.SystemBoard:
+			return &queryField{name: name, kind: kind, list: true}, nil
+		}
+		return &queryField{name: name, kind: kind}, nil
+	}
+	// check that none of the default table joins handles this column:
+	for _, j := range workitem.DefaultTableJoins() {
+		if j.HandlesFieldName(key) {
+			return &queryField{name: key}, nil
+		}
+	}
+	if fields == nil || key == "" {
+		return nil, errors.NewBadParameterError("key not found", key)
+	}
+	def, err := fields.LoadFieldDefinition(ctx, key)
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			return nil, errors.NewBadParameterError("key not found", key)
+		}
+		return nil, err
+	}
+	if def.DependsOnTime() {
+		// the stored value is outdated as soon as time passes
+		return nil, errors.NewBadParameterError("key", key).Expected("field whose value doesn't change as time passes")
+	}
+	return &queryField{
+		name:   key,
+		kind:   def.ValueKind(),
+		list:   def.Type.GetKind() == workitem.KindList,
+		custom: true,
+	}, nil
+}
+
+// expression returns a new field expression for the field
+func (f queryField) expression() criteria.Expression {
+	if f.custom {
+		return workitem.JSONField(f.name)
+	}
+	return criteria.Field(f.name)
+}
+
+// isNull returns a new expression checking that the field has no value
+func (f queryField) isNull() criteria.Expression {
+	if f.custom {
+		return workitem.JSONFieldIsNull(f.name)
+	}
+	return criteria.IsNull(f.name)
+}
+
+// value converts the given value of the query into a value of the kind of
+// the field. Values for fields of joined tables are converted by
+// comparableValue.
+func (f queryField) value(val string) (interface{}, error) {
+	var res interface{}
+	var err error
+	switch f.kind {
+	case "":
+		return comparableValue(val), nil
+	case workitem.KindInstant:
+		res, err = time.Parse(time.RFC3339, val)
+	case workitem.KindInteger:
+		res, err = strconv.ParseInt(val, 10, 64)
+	case workitem.KindFloat:
+		res, err = strconv.ParseFloat(val, 64)
+	case workitem.KindDuration:
+		res, err = time.ParseDuration(val)
+	case workitem.KindBoolean:
+		res, err = strconv.ParseBool(val)
 	default:
-		return criteria.Literal(val)
+		return val, nil
 	}
+	if err != nil {
+		return nil, errors.NewBadParameterError(f.name, val).Expected(string(f.kind))
+	}
+	return res, nil
 }
 
-func (q Query) generateExpression() (criteria.Expression, error) {
-	var myexpr []criteria.Expression
-	currentOperator := q.Name
+// equalityLiteral returns the literal to check the field for equality with
+// the given value. Lists are checked for containing the value. Values of
+// custom fields are converted to the representation used in the jsonb
+// "fields" column; other fields are checked with the given value as it is.
+func (f queryField) equalityLiteral(val string) (criteria.Expression, error) {
+	if f.list {
+		return criteria.Literal([]string{val}), nil
+	}
+	if !f.custom {
+		return criteria.Literal(val), nil
+	}
+	v, err := f.value(val)
+	if err != nil {
+		return nil, err
+	}
+	switch t := v.(type) {
+	case time.Time:
+		return criteria.Literal(t.UnixNano()), nil
+	case time.Duration:
+		return criteria.Literal(int64(t)), nil
+	default:
+		return criteria.Literal(v), nil
+	}
+}
 
-	if !isOperator(currentOperator) || currentOperator == OPTS {
-		key, ok := searchKeyMap[q.Name]
-		// check that none of the default table joins handles this column:
-		var handledByJoin bool
-		joins := workitem.DefaultTableJoins()
-		for _, j := range joins {
-			if j.HandlesFieldName(q.Name) {
-				handledByJoin = true
-				key = q.Name
-				break
+// comparableValue converts the given value for comparison with a column of
+// a joined table, whose type isn't described by any work item type. Values
+// are tried as RFC3339 timestamps and numbers in that order; otherwise the
+// value is compared as a string.
+func comparableValue(val string) interface{} {
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return t
+	}
+	if f, err := strconv.ParseFloat(val, 64); err == nil {
+		return f
+	}
+	return val
+}
+
+// comparisonExpression returns the expression for the given ordering operator.
+func comparisonExpression(op string, f queryField, val string) (criteria.Expression, error) {
+	v, err := f.value(val)
+	if err != nil {
+		return nil, err
+	}
+	left, right := f.expression(), criteria.Literal(v)
+	switch op {
+	case GT:
+		return criteria.Greater(left, right), nil
+	case GTE:
+		return criteria.GreaterOrEquals(left, right), nil
+	case LT:
+		return criteria.Less(left, right), nil
+	case LTE:
+		return criteria.LessOrEquals(left, right), nil
+	default:
+		return nil, errors.NewBadParameterError("operator", op)
+	}
+}
+
+// inExpression returns the expression for the "$IN" operator. Lists can't be
+// checked with the SQL IN operator, so they are checked for containing any of
+// the values instead.
+func inExpression(f queryField, vals []string) (criteria.Expression, error) {
+	if f.list {
+		var res criteria.Expression
+		for _, val := range vals {
+			exp := criteria.Equals(f.expression(), criteria.Literal([]string{val}))
+			if res == nil {
+				res = exp
+			} else {
+				res = criteria.Or(res, exp)
 			}
 		}
-		if !ok && !handledByJoin {
-			return nil, errors.NewBadParameterError("key not found", q.Name)
+		return res, nil
+	}
+	values := make([]interface{}, len(vals))
+	for i, val := range vals {
+		v, err := f.value(val)
+		if err != nil {
+			return nil, err
 		}
-		left := criteria.Field(key)
-		if q.Value != nil {
-			right := q.determineLiteralType(key, *q.Value)
-			if q.Negate {
-				myexpr = append(myexpr, criteria.Not(left, right))
-			} else {
-				if q.Substring {
-					myexpr = append(myexpr, criteria.Substring(left, right))
-				} else {
-					myexpr = append(myexpr, criteria.Equals(left, right))
-				}
-			}
-		} else {
-			if q.Negate {
-				return nil, errors.NewBadParameterError("negate for null not supported", q.Name)
-			}
-			myexpr = append(myexpr, criteria.IsNull(key))
+		values[i] = v
+	}
+	return criteria.In(f.expression(), criteria.Literal(values)), nil
+}
+
+// fieldExpression returns the expression for a query whose name is not an
+// operator
+func (q Query) fieldExpression(ctx context.Context, fields FieldResolver) (criteria.Expression, error) {
+	f, err := resolveField(ctx, fields, q.Name)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(q.In) > 0:
+		return inExpression(*f, q.In)
+	case q.Value != nil && q.Comparison != "":
+		return comparisonExpression(q.Comparison, *f, *q.Value)
+	case q.Value != nil && q.Substring && !q.Negate:
+		return criteria.Substring(f.expression(), criteria.Literal(*q.Value)), nil
+	case q.Value != nil:
+		right, err := f.equalityLiteral(*q.Value)
+		if err != nil {
+			return nil, err
+		}
+		if q.Negate {
+			return criteria.Not(f.expression(), right), nil
+		}
+		return criteria.Equals(f.expression(), right), nil
+	default:
+		if q.Negate {
+			return nil, errors.NewBadParameterError("negate for null not supported", q.Name)
+		}
+		return f.isNull(), nil
+	}
+}
+
+func (q Query) generateExpression(ctx context.Context, fields FieldResolver) (criteria.Expression, error) {
+	var myexpr []criteria.Expression
+	currentOperator := q.Name
+
+	if !isOperator(currentOperator) || currentOperator == OPTS {
+		exp, err := q.fieldExpression(ctx, fields)
+		if err != nil {
+			return nil, err
 		}
+		myexpr = append(myexpr, exp)
 	}
 	for _, child := range q.Children {
+		var exp criteria.Expression
+		var err error
 		if isOperator(child.Name) || currentOperator == OPTS {
-			exp, err := child.generateExpression()
-			if err != nil {
-				return nil, err
-			}
-			myexpr = append(myexpr, exp)
+			exp, err = child.generateExpression(ctx, fields)
 		} else {
-			key, ok := searchKeyMap[child.Name]
-			// check that none of the default table joins handles this column:
-			var handledByJoin bool
-			joins := workitem.DefaultTableJoins()
-			for _, j := range joins {
-				if j.HandlesFieldName(child.Name) {
-					handledByJoin = true
-					key = child.Name
-					break
-				}
-			}
-			if !ok && !handledByJoin {
-				return nil, errors.NewBadParameterError("key not found", child.Name)
-			}
-			left := criteria.Field(key)
-			if child.Value != nil {
-				right := q.determineLiteralType(key, *child.Value)
-				if child.Negate {
-					myexpr = append(myexpr, criteria.Not(left, right))
-				} else {
-					if child.Substring {
-						myexpr = append(myexpr, criteria.Substring(left, right))
-					} else {
-						myexpr = append(myexpr, criteria.Equals(left, right))
-					}
-				}
-			} else {
-				if child.Negate {
-					return nil, errors.NewBadParameterError("negate for null not supported", child.Name)
-				}
-				myexpr = append(myexpr, criteria.IsNull(key))
-
-			}
+			exp, err = child.fieldExpression(ctx, fields)
+		}
+		if err != nil {
+			return nil, err
 		}
+		myexpr = append(myexpr, exp)
 	}
 	var res criteria.Expression
 	switch currentOperator {
@@ -517,8 +743,47 @@ func (q Query) generateExpression() (criteria.Expression, error) {
 	return res, nil
 }
 
-// ParseFilterString accepts a raw string and generates a criteria expression
-func ParseFilterString(ctx context.Context, rawSearchString string) (criteria.Expression, *QueryOptions, error) {
+// spaceID returns the ID of the space to which the query restricts the work
+// items, i.e. the space which is required by the query itself or by one of the
+// children of an "$AND" query. It returns nil if the query doesn't restrict
+// the work items to a single space.
+func (q Query) spaceID() *uuid.UUID {
+	switch {
+	case q.Name == AND:
+		for _, child := range q.Children {
+			if id := child.spaceID(); id != nil {
+				return id
+			}
+		}
+	case searchKeyMap[q.Name] == "SpaceID" && q.Value != nil && !q.Negate && !q.Substring && q.Comparison == "":
+		if id, err := uuid.FromString(*q.Value); err == nil {
+			return &id
+		}
+	}
+	return nil
+}
+
+// FilterSpaceID returns the ID of the space to which the given filter
+// restricts the work items or nil if the filter doesn't restrict them to a
+// single space. The custom fields of a filter can only be resolved in the work
+// item types of that space.
+func FilterSpaceID(ctx context.Context, rawFilterString string) (*uuid.UUID, error) {
+	fm := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(rawFilterString), &fm); err != nil {
+		return nil, errors.NewBadParameterError("expression", rawFilterString+": "+err.Error())
+	}
+	q := Query{}
+	if err := parseMap(fm, &q); err != nil {
+		return nil, err
+	}
+	return q.spaceID(), nil
+}
+
+// ParseFilterString accepts a raw string and generates a criteria expression.
+// Keys which aren't known to the query language are resolved as custom fields
+// of the work item types with the given FieldResolver; without one they are
+// rejected.
+func ParseFilterString(ctx context.Context, rawSearchString string, fields FieldResolver) (criteria.Expression, *QueryOptions, error) {
 	fm := map[string]interface{}{}
 	// Parsing/Unmarshalling JSON encoding/json
 	err := json.Unmarshal([]byte(rawSearchString), &fm)
@@ -531,11 +796,13 @@ func ParseFilterString(ctx context.Context, rawSearchString string) (criteria.Ex
 		return nil, nil, errors.NewBadParameterError("expression", rawSearchString+": "+err.Error())
 	}
 	q := Query{}
-	parseMap(fm, &q)
+	if err := parseMap(fm, &q); err != nil {
+		return nil, nil, err
+	}
 
 	q.Options = parseOptions(fm)
 
-	exp, err := q.generateExpression()
+	exp, err := q.generateExpression(ctx, fields)
 	return exp, q.Options, err
 }
 
@@ -676,7 +943,7 @@ func (r *GormSearchRepository) SearchFullText(ctx context.Context, rawSearchStri
 	return result, count, nil
 }
 
-func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, parentExists *bool, start *int, limit *int) ([]workitem.WorkItemStorage, int, error) {
+func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria criteria.Expression, parentExists *bool, start *int, limit *int, sort workitem.SortWorkItemsBy) ([]workitem.WorkItemStorage, int, error) {
 	where, parameters, joins, compileError := workitem.Compile(criteria)
 	if compileError != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -717,7 +984,7 @@ func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria cri
 		db = db.Limit(*limit)
 	}
 
-	db = db.Select("count(*) over () as cnt2 , *").Order("execution_order desc")
+	db = db.Select("count(*) over () as cnt2 , *").Order(string(sort))
 
 	rows, err := db.Rows()
 	defer closeable.Close(ctx, rows)
@@ -775,17 +1042,41 @@ func (r *GormSearchRepository) listItemsFromDB(ctx context.Context, criteria cri
 	return result, count, nil
 }
 
+// filterFields returns the FieldResolver for the work item types of the space
+// to which the given filter restricts the work items. It returns nil if there
+// is no such space, so that custom fields are rejected.
+func (r *GormSearchRepository) filterFields(ctx context.Context, rawFilterString string) (FieldResolver, error) {
+	spaceID, err := FilterSpaceID(ctx, rawFilterString)
+	if err != nil || spaceID == nil {
+		return nil, err
+	}
+	s, err := space.NewRepository(r.db).Load(ctx, *spaceID)
+	if err != nil {
+		if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+			// the filter matches no work items anyway
+			return nil, nil
+		}
+		return nil, err
+	}
+	return workitem.SpaceTemplateFields{Types: r.witr, SpaceTemplateID: s.SpaceTemplateID}, nil
+}
+
 // Filter returns the work items matching the search as well as their count. If
 // the filter did specify the "tree-view" option to be "true", then we will also
 // create a list of ancestors as well as a list of links. The ancestors exist in
 // order to list the parent of each matching work item up to its root work item.
 // The child links are there in order to know what siblings to load for matching
-// work items.
-func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString string, parentExists *bool, start *int, limit *int) (matches []workitem.WorkItem, count int, ancestors link.AncestorList, childLinks link.WorkItemLinkList, err error) {
+// work items. The matches are ordered by the given sort order which is applied
+// in the database so that paging returns consistent results.
+func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString string, parentExists *bool, start *int, limit *int, sort workitem.SortWorkItemsBy) (matches []workitem.WorkItem, count int, ancestors link.AncestorList, childLinks link.WorkItemLinkList, err error) {
 	// parse
 	// generateSearchQuery
 	// ....
-	exp, opts, err := ParseFilterString(ctx, rawFilterString)
+	fields, err := r.filterFields(ctx, rawFilterString)
+	if err != nil {
+		return nil, 0, nil, nil, errs.Wrap(err, "failed to parse filter string")
+	}
+	exp, opts, err := ParseFilterString(ctx, rawFilterString, fields)
 	if err != nil {
 		return nil, 0, nil, nil, errs.Wrap(err, "failed to parse filter string")
 	}
@@ -802,7 +1093,7 @@ func (r *GormSearchRepository) Filter(ctx context.Context, rawFilterString strin
 		return nil, 0, nil, nil, errors.NewBadParameterError("rawFilterString", rawFilterString)
 	}
 
-	result, count, err := r.listItemsFromDB(ctx, exp, parentExists, start, limit)
+	result, count, err := r.listItemsFromDB(ctx, exp, parentExists, start, limit, sort)
 	if err != nil {
 		return nil, 0, nil, nil, errs.WithStack(err)
 	}
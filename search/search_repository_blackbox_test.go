@@ -86,7 +86,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchWithJoin() {
 		t.Run("matching name", func(t *testing.T) {
 			// when
 			filter := fmt.Sprintf(`{"iteration.name": "%s"}`, fxt.Iterations[0].Name)
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			// then
 			require.NoError(t, err)
 			assert.Equal(t, 7, count)
@@ -127,7 +127,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchWithJoin() {
 		t.Run("matching name", func(t *testing.T) {
 			// when
 			filter := fmt.Sprintf(`{"typegroup.name": "%s"}`, fxt.WorkItemTypeGroups[0].Name)
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			// then
 			require.NoError(t, err)
 			toBeFound := id.MapFromSlice(id.Slice{
@@ -174,7 +174,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchBoardColumnID() {
 		)
 		t.Run("single match", func(t *testing.T) {
 			filter := fmt.Sprintf(`{"boardcolumn": "%s"}`, fxt.WorkItemBoards[1].Columns[0].ID.String())
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			require.NoError(t, err)
 			require.Equal(t, 1, count)
 			require.Len(t, res, count)
@@ -182,7 +182,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchBoardColumnID() {
 		})
 		t.Run("multiple match, atomic expression", func(t *testing.T) {
 			filter := fmt.Sprintf(`{"boardcolumn": "%s"}`, fxt.WorkItemBoards[1].Columns[1].ID.String())
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			require.NoError(t, err)
 			require.Equal(t, 2, count)
 			require.Len(t, res, count)
@@ -201,7 +201,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchBoardColumnID() {
 				fxt.WorkItemBoards[1].Columns[0].ID.String(),
 				fxt.WorkItemBoards[0].Columns[1].ID.String(),
 			)
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			require.NoError(t, err)
 			require.Equal(t, 1, count)
 			require.Len(t, res, count)
@@ -213,7 +213,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchBoardColumnID() {
 				fxt.WorkItemBoards[0].Columns[0].ID.String(),
 				fxt.WorkItemBoards[1].Columns[1].ID.String(),
 			)
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			require.NoError(t, err)
 			require.Equal(t, 2, count)
 			require.Len(t, res, count)
@@ -255,7 +255,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchBoardID() {
 		)
 		t.Run("multiple match, atomic expression", func(t *testing.T) {
 			filter := fmt.Sprintf(`{"board.id": "%s"}`, fxt.WorkItemBoards[0].ID.String())
-			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, _, _, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			require.NoError(t, err)
 			require.Equal(t, 2, count)
 			require.Len(t, res, count)
@@ -377,7 +377,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			fxt := s.getTestFixture()
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, nil, workitem.SortWorkItemsByDefault)
 			// when
 			require.NoError(t, err)
 			assert.Equal(t, 2, count)
@@ -392,7 +392,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
 			start := 3
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, &start, nil)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, &start, nil, workitem.SortWorkItemsByDefault)
 			// then
 			require.NoError(t, err)
 			assert.Equal(t, 2, count)
@@ -407,7 +407,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
 			limit := 1
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, &limit)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, nil, nil, &limit, workitem.SortWorkItemsByDefault)
 			// then
 			require.NoError(s.T(), err)
 			assert.Equal(t, 2, count)
@@ -425,7 +425,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
 			parentExists := false
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil, workitem.SortWorkItemsByDefault)
 			// then both work items should be returned
 			require.NoError(t, err)
 			assert.Equal(t, 3, count)
@@ -446,7 +446,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
 			parentExists := false
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil, workitem.SortWorkItemsByDefault)
 			// then only parent work item should be returned
 			require.NoError(t, err)
 			assert.Equal(t, 2, count)
@@ -472,7 +472,7 @@ func (s *searchRepositoryBlackboxTest) TestSearchFullText() {
 			// when
 			filter := fmt.Sprintf(`{"$AND": [{"space": "%s"}]}`, fxt.Spaces[0].ID)
 			parentExists := false
-			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil)
+			res, count, ancestors, childLinks, err := s.searchRepo.Filter(context.Background(), filter, &parentExists, nil, nil, workitem.SortWorkItemsByDefault)
 			// then both work items should be returned
 			require.NoError(t, err)
 			assert.Equal(t, 3, count)
@@ -0,0 +1,197 @@
+// Package archive exports a space with all its content into a portable
+// archive and imports such an archive into a new space, e.g. to move a space
+// between clusters or to take a snapshot of it.
+//
+// An archive is a stream of JSON values, one per line. Each line is a Record
+// whose kind tells how to read its data. The first record is the Header, the
+// identities follow and then the entities in the order in which they depend on
+// each other: iterations, areas, labels, codebases, work items, their
+// revisions, comments and links and finally queries.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// FormatVersion is the version of the archive format written by the export.
+// The import rejects archives with a newer version.
+const FormatVersion = 1
+
+// RecordKind tells what kind of data a record holds
+type RecordKind string
+
+// The kinds of records in an archive
+const (
+	KindHeader    RecordKind = "header"
+	KindIdentity  RecordKind = "identity"
+	KindIteration RecordKind = "iteration"
+	KindArea      RecordKind = "area"
+	KindLabel     RecordKind = "label"
+	KindCodebase  RecordKind = "codebase"
+	KindWorkItem  RecordKind = "workitem"
+	KindRevision  RecordKind = "revision"
+	KindComment   RecordKind = "comment"
+	KindLink      RecordKind = "link"
+	KindQuery     RecordKind = "query"
+)
+
+// Record is a single line of an archive
+type Record struct {
+	Kind RecordKind      `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// Header is the data of the first record of an archive
+type Header struct {
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Space      SpaceRecord `json:"space"`
+}
+
+// SpaceRecord holds the exported space
+type SpaceRecord struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	Description     string    `json:"description"`
+	OwnerID         uuid.UUID `json:"owner_id"`
+	SpaceTemplateID uuid.UUID `json:"space_template_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// IdentityRecord holds an identity which is referred to in the archive. The
+// import maps it to the identity with the same username.
+type IdentityRecord struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	ProviderType string    `json:"provider_type"`
+}
+
+// IterationRecord holds an iteration. The path lists the IDs of the ancestors
+// starting with the root iteration.
+type IterationRecord struct {
+	ID          uuid.UUID   `json:"id"`
+	Path        []uuid.UUID `json:"path"`
+	Name        string      `json:"name"`
+	Description *string     `json:"description,omitempty"`
+	StartAt     *time.Time  `json:"start_at,omitempty"`
+	EndAt       *time.Time  `json:"end_at,omitempty"`
+	State       string      `json:"state"`
+	UserActive  bool        `json:"user_active"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   time.Time   `json:"updated_at"`
+}
+
+// AreaRecord holds an area. The path lists the IDs of the ancestors starting
+// with the root area.
+type AreaRecord struct {
+	ID        uuid.UUID   `json:"id"`
+	Path      []uuid.UUID `json:"path"`
+	Name      string      `json:"name"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// LabelRecord holds a label
+type LabelRecord struct {
+	ID              uuid.UUID `json:"id"`
+	Name            string    `json:"name"`
+	TextColor       string    `json:"text_color"`
+	BackgroundColor string    `json:"background_color"`
+	BorderColor     string    `json:"border_color"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+// CodebaseRecord holds a codebase
+type CodebaseRecord struct {
+	ID                uuid.UUID `json:"id"`
+	Type              string    `json:"type"`
+	URL               string    `json:"url"`
+	StackID           *string   `json:"stack_id,omitempty"`
+	LastUsedWorkspace string    `json:"last_used_workspace"`
+	CVEScan           bool      `json:"cve_scan"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// WorkItemRecord holds a work item with its fields as they are stored
+type WorkItemRecord struct {
+	ID             uuid.UUID       `json:"id"`
+	Number         int             `json:"number"`
+	TypeID         uuid.UUID       `json:"type_id"`
+	Version        int             `json:"version"`
+	Fields         workitem.Fields `json:"fields"`
+	ExecutionOrder float64         `json:"execution_order"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+// RevisionRecord holds a revision of a work item
+type RevisionRecord struct {
+	ID              uuid.UUID             `json:"id"`
+	Time            time.Time             `json:"time"`
+	Type            workitem.RevisionType `json:"type"`
+	ModifierID      uuid.UUID             `json:"modifier_id"`
+	WorkItemID      uuid.UUID             `json:"workitem_id"`
+	WorkItemTypeID  uuid.UUID             `json:"workitem_type_id"`
+	WorkItemVersion int                   `json:"workitem_version"`
+	Fields          workitem.Fields       `json:"fields"`
+}
+
+// CommentRecord holds a comment on a work item
+type CommentRecord struct {
+	ID              uuid.UUID  `json:"id"`
+	WorkItemID      uuid.UUID  `json:"workitem_id"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id,omitempty"`
+	CreatorID       uuid.UUID  `json:"creator_id"`
+	Body            string     `json:"body"`
+	Markup          string     `json:"markup"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// LinkRecord holds a link between two work items of the space. The link type
+// is expected to exist wherever the archive is imported.
+type LinkRecord struct {
+	ID         uuid.UUID `json:"id"`
+	SourceID   uuid.UUID `json:"source_id"`
+	TargetID   uuid.UUID `json:"target_id"`
+	LinkTypeID uuid.UUID `json:"link_type_id"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// QueryRecord holds a saved query
+type QueryRecord struct {
+	ID        uuid.UUID `json:"id"`
+	CreatorID uuid.UUID `json:"creator_id"`
+	Title     string    `json:"title"`
+	Fields    string    `json:"fields"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Repository exports and imports spaces
+type Repository interface {
+	// Export writes the archive of the space with the given ID
+	Export(ctx context.Context, spaceID uuid.UUID, w io.Writer) error
+	// Import reads an archive and creates a new space from it
+	Import(ctx context.Context, r io.Reader, opts ImportOptions) (*ImportResult, error)
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the archive repository using gorm
+type GormRepository struct {
+	db *gorm.DB
+}
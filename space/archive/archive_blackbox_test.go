@@ -0,0 +1,195 @@
+package archive_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/ptr"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	. "github.com/fabric8-services/fabric8-wit/space/archive"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	numbersequence "github.com/fabric8-services/fabric8-wit/workitem/number_sequence"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type archiveSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestArchiveSuite(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &archiveSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// records splits an archive into its records
+func records(t *testing.T, archive []byte) []Record {
+	var res []Record
+	scanner := bufio.NewScanner(bytes.NewReader(archive))
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &rec))
+		res = append(res, rec)
+	}
+	require.NoError(t, scanner.Err())
+	return res
+}
+
+func (s *archiveSuite) TestExportImport() {
+	// given
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Identities(2, tf.SetIdentityUsernames("bob", "alice")),
+		tf.Spaces(1),
+		tf.Iterations(2, tf.SetIterationNames("root", "sprint 1")),
+		tf.Areas(2),
+		tf.Labels(1),
+		tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.IterationByName("sprint 1").ID.String()
+			fxt.WorkItems[idx].Fields[workitem.SystemCreator] = fxt.IdentityByUsername("alice").ID.String()
+			return nil
+		}),
+		tf.Comments(2),
+		tf.WorkItemLinks(1),
+		tf.Queries(1),
+	)
+	repo := NewRepository(s.DB)
+	var buf bytes.Buffer
+	require.NoError(s.T(), repo.Export(s.Ctx, fxt.Spaces[0].ID, &buf))
+	exported := buf.Bytes()
+
+	s.T().Run("export", func(t *testing.T) {
+		recs := records(t, exported)
+		require.NotEmpty(t, recs)
+		assert.Equal(t, KindHeader, recs[0].Kind)
+		counts := map[RecordKind]int{}
+		for _, rec := range recs {
+			counts[rec.Kind]++
+		}
+		assert.Equal(t, 2, counts[KindIteration])
+		assert.Equal(t, 2, counts[KindArea])
+		assert.Equal(t, 1, counts[KindLabel])
+		assert.Equal(t, 3, counts[KindWorkItem])
+		assert.True(t, counts[KindRevision] >= 3)
+		assert.Equal(t, 2, counts[KindComment])
+		assert.Equal(t, 1, counts[KindLink])
+		assert.Equal(t, 1, counts[KindQuery])
+	})
+
+	s.T().Run("import", func(t *testing.T) {
+		// when
+		result, err := repo.Import(s.Ctx, bytes.NewReader(exported), ImportOptions{
+			OwnerID: fxt.IdentityByUsername("bob").ID,
+			Name:    ptr.String("imported"),
+		})
+		// then
+		require.NoError(t, err)
+		require.NotEqual(t, fxt.Spaces[0].ID, result.SpaceID)
+		assert.Equal(t, 3, result.Counts[KindWorkItem])
+		assert.Equal(t, []string{"alice"}, result.ReplacedIdentities)
+		assert.Empty(t, result.RenumberedWorkItems)
+		imported, err := s.GormDB.Spaces().Load(s.Ctx, result.SpaceID)
+		require.NoError(t, err)
+		assert.Equal(t, "imported", imported.Name)
+		assert.Equal(t, fxt.IdentityByUsername("bob").ID, imported.OwnerID)
+
+		iterations, err := s.GormDB.Iterations().List(s.Ctx, result.SpaceID)
+		require.NoError(t, err)
+		require.Len(t, iterations, 2)
+		var sprint *iteration.Iteration
+		for i := range iterations {
+			if iterations[i].Name == "sprint 1" {
+				sprint = &iterations[i]
+			}
+		}
+		require.NotNil(t, sprint)
+		require.Len(t, sprint.Path, 1, "the path must point to the new root iteration")
+		assert.NotEqual(t, fxt.IterationByName("root").ID, sprint.Path[0])
+
+		for _, wi := range fxt.WorkItems {
+			loaded, err := s.GormDB.WorkItems().Load(s.Ctx, result.SpaceID, wi.Number)
+			require.NoError(t, err)
+			assert.NotEqual(t, wi.ID, loaded.ID)
+			assert.Equal(t, wi.Fields[workitem.SystemTitle], loaded.Fields[workitem.SystemTitle])
+			assert.Equal(t, sprint.ID.String(), loaded.Fields[workitem.SystemIteration])
+			assert.Equal(t, fxt.IdentityByUsername("bob").ID.String(), loaded.Fields[workitem.SystemCreator], "alice is replaced by the importer")
+			comments, _, err := s.GormDB.Comments().List(s.Ctx, loaded.ID, nil, nil)
+			require.NoError(t, err)
+			origComments, _, err := s.GormDB.Comments().List(s.Ctx, wi.ID, nil, nil)
+			require.NoError(t, err)
+			assert.Len(t, comments, len(origComments))
+		}
+
+		next, err := numbersequence.NewWorkItemNumberSequenceRepository(s.DB).NextVal(s.Ctx, result.SpaceID)
+		require.NoError(t, err)
+		assert.Equal(t, fxt.WorkItems[2].Number+1, *next)
+	})
+
+	s.T().Run("the importer keeps their own identity", func(t *testing.T) {
+		// when
+		result, err := repo.Import(s.Ctx, bytes.NewReader(exported), ImportOptions{
+			OwnerID: fxt.IdentityByUsername("alice").ID,
+			Name:    ptr.String("imported by alice"),
+		})
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"bob"}, result.ReplacedIdentities)
+		loaded, err := s.GormDB.WorkItems().Load(s.Ctx, result.SpaceID, fxt.WorkItems[0].Number)
+		require.NoError(t, err)
+		assert.Equal(t, fxt.IdentityByUsername("alice").ID.String(), loaded.Fields[workitem.SystemCreator])
+	})
+
+	s.T().Run("work item types of another space template", func(t *testing.T) {
+		// given
+		other := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		archive := strings.Replace(string(exported), fxt.WorkItemTypes[0].ID.String(), other.WorkItemTypes[0].ID.String(), -1)
+		// when
+		_, err := repo.Import(s.Ctx, strings.NewReader(archive), ImportOptions{
+			OwnerID: fxt.IdentityByUsername("bob").ID,
+			Name:    ptr.String("foreign types " + uuid.NewV4().String()),
+		})
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("too large archive", func(t *testing.T) {
+		// when
+		_, err := repo.Import(s.Ctx, bytes.NewReader(exported), ImportOptions{
+			OwnerID: fxt.IdentityByUsername("bob").ID,
+			Name:    ptr.String("too large " + uuid.NewV4().String()),
+			MaxSize: int64(len(exported) - 1),
+		})
+		// then
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("invalid archives", func(t *testing.T) {
+		for name, archive := range map[string]string{
+			"empty":          "",
+			"not JSON":       "foo\n",
+			"missing header": strings.SplitN(string(exported), "\n", 2)[1],
+			"newer version":  `{"kind":"header","data":{"version":99,"space":{"name":"future"}}}` + "\n",
+			"unknown kind":   strings.SplitN(string(exported), "\n", 2)[0] + "\n" + `{"kind":"foo","data":{}}` + "\n",
+		} {
+			t.Run(name, func(t *testing.T) {
+				_, err := repo.Import(s.Ctx, strings.NewReader(archive), ImportOptions{
+					OwnerID: fxt.IdentityByUsername("bob").ID,
+					Name:    ptr.String("invalid " + uuid.NewV4().String()),
+				})
+				require.Error(t, err)
+			})
+		}
+	})
+}
@@ -0,0 +1,287 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/query"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Export writes the archive of the space with the given ID. Links to work
+// items in other spaces are left out. The entities are streamed from the
+// database to the writer so that large spaces don't have to fit in memory.
+func (r *GormRepository) Export(ctx context.Context, spaceID uuid.UUID, w io.Writer) error {
+	defer goa.MeasureSince([]string{"goa", "db", "space_archive", "export"}, time.Now())
+	s, err := space.NewRepository(r.db).Load(ctx, spaceID)
+	if err != nil {
+		return err
+	}
+	// the work item types are loaded up front because no query can run while
+	// the rows of the space are read
+	wits := witCache{repo: workitem.NewWorkItemTypeRepository(r.db), spaceTemplateID: s.SpaceTemplateID}
+	if err := wits.preload(ctx); err != nil {
+		return errors.NewInternalError(ctx, err)
+	}
+	queries := r.queries(*s)
+	identities, err := r.loadIdentities(ctx, *s, queries, &wits)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to load the identities of the space to export")
+		return errors.NewInternalError(ctx, err)
+	}
+	enc := json.NewEncoder(w)
+	write := func(kind RecordKind, data interface{}) error {
+		raw, err := json.Marshal(data)
+		if err != nil {
+			return errs.Wrapf(err, "failed to marshal %s record", kind)
+		}
+		return errs.Wrapf(enc.Encode(Record{Kind: kind, Data: raw}), "failed to write %s record", kind)
+	}
+	if err := write(KindHeader, Header{
+		Version:    FormatVersion,
+		ExportedAt: time.Now(),
+		Space: SpaceRecord{
+			ID:              s.ID,
+			Name:            s.Name,
+			Description:     s.Description,
+			OwnerID:         s.OwnerID,
+			SpaceTemplateID: s.SpaceTemplateID,
+			CreatedAt:       s.CreatedAt,
+		},
+	}); err != nil {
+		return err
+	}
+	for _, i := range identities {
+		if err := write(KindIdentity, IdentityRecord{ID: i.ID, Username: i.Username, ProviderType: i.ProviderType}); err != nil {
+			return err
+		}
+	}
+	for _, q := range queries {
+		kind := q.kind
+		if err := r.each(q, func(row interface{}) error {
+			return write(kind, record(row))
+		}); err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"space_id": spaceID,
+				"err":      err,
+			}, "unable to export the content of the space")
+			return err
+		}
+	}
+	return nil
+}
+
+// exportQuery selects the rows of one kind of record of the exported space
+type exportQuery struct {
+	kind   RecordKind
+	newRow func() interface{}
+	where  string
+	args   []interface{}
+	order  string
+}
+
+// queries returns the queries of the exported entities in the order in which
+// they are written to the archive. The revisions, comments and links of
+// deleted work items are left out.
+func (r *GormRepository) queries(s space.Space) []exportQuery {
+	workItemIDs := fmt.Sprintf("(SELECT id FROM %s WHERE space_id = ? AND deleted_at IS NULL)", workitem.WorkItemStorage{}.TableName())
+	return []exportQuery{
+		{KindIteration, func() interface{} { return &iteration.Iteration{} }, "space_id = ?", []interface{}{s.ID}, "created_at"},
+		{KindArea, func() interface{} { return &area.Area{} }, "space_id = ?", []interface{}{s.ID}, "created_at"},
+		{KindLabel, func() interface{} { return &label.Label{} }, "space_id = ?", []interface{}{s.ID}, "created_at"},
+		{KindCodebase, func() interface{} { return &codebase.Codebase{} }, "space_id = ?", []interface{}{s.ID}, "created_at"},
+		{KindWorkItem, func() interface{} { return &workitem.WorkItemStorage{} }, "space_id = ?", []interface{}{s.ID}, "number"},
+		{KindRevision, func() interface{} { return &workitem.Revision{} }, "work_item_id IN " + workItemIDs, []interface{}{s.ID}, "revision_time"},
+		{KindComment, func() interface{} { return &comment.Comment{} }, "parent_id IN " + workItemIDs, []interface{}{s.ID}, "created_at"},
+		{KindLink, func() interface{} { return &link.WorkItemLink{} }, "source_id IN " + workItemIDs + " AND target_id IN " + workItemIDs, []interface{}{s.ID, s.ID}, "created_at"},
+		{KindQuery, func() interface{} { return &query.Query{} }, "space_id = ?", []interface{}{s.ID}, "created_at"},
+	}
+}
+
+// each calls f with every row which the query selects. The rows are read one
+// at a time so that the export never holds the whole space in memory. No
+// other query may run in f because the rows keep the connection busy.
+func (r *GormRepository) each(q exportQuery, f func(row interface{}) error) error {
+	db := r.db.Model(q.newRow()).Where(q.where, q.args...).Order(q.order)
+	rows, err := db.Rows()
+	if err != nil {
+		return errs.Wrapf(err, "failed to load the %s records", q.kind)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		row := q.newRow()
+		if err := db.ScanRows(rows, row); err != nil {
+			return errs.Wrapf(err, "failed to read a %s record", q.kind)
+		}
+		if err := f(row); err != nil {
+			return err
+		}
+	}
+	return errs.Wrapf(rows.Err(), "failed to load the %s records", q.kind)
+}
+
+// loadIdentities returns the identities which are referred to in the exported
+// space
+func (r *GormRepository) loadIdentities(ctx context.Context, s space.Space, queries []exportQuery, wits *witCache) ([]account.Identity, error) {
+	ids := map[uuid.UUID]struct{}{s.OwnerID: {}}
+	collect := func(typeID uuid.UUID, fields workitem.Fields) error {
+		wit, err := wits.get(typeID)
+		if err != nil {
+			return err
+		}
+		mapFieldValues(*wit, fields, func(kind workitem.Kind, value interface{}) interface{} {
+			if kind == workitem.KindUser {
+				if id, err := uuid.FromString(toString(value)); err == nil {
+					ids[id] = struct{}{}
+				}
+			}
+			return value
+		})
+		return nil
+	}
+	for _, q := range queries {
+		err := r.each(q, func(row interface{}) error {
+			switch v := row.(type) {
+			case *workitem.WorkItemStorage:
+				return collect(v.Type, v.Fields)
+			case *workitem.Revision:
+				ids[v.ModifierIdentity] = struct{}{}
+				return collect(v.WorkItemTypeID, v.WorkItemFields)
+			case *comment.Comment:
+				ids[v.Creator] = struct{}{}
+			case *query.Query:
+				ids[v.Creator] = struct{}{}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	list := make([]uuid.UUID, 0, len(ids))
+	for id := range ids {
+		list = append(list, id)
+	}
+	var identities []account.Identity
+	if err := r.db.Where("id IN (?)", list).Order("username").Find(&identities).Error; err != nil {
+		return nil, errs.Wrap(err, "failed to load the identities of the exported space")
+	}
+	return identities, nil
+}
+
+// record converts a row of the exported space into its archive record
+func record(row interface{}) interface{} {
+	switch v := row.(type) {
+	case *iteration.Iteration:
+		return IterationRecord{
+			ID:          v.ID,
+			Path:        v.Path,
+			Name:        v.Name,
+			Description: v.Description,
+			StartAt:     v.StartAt,
+			EndAt:       v.EndAt,
+			State:       v.State.String(),
+			UserActive:  v.UserActive,
+			CreatedAt:   v.CreatedAt,
+			UpdatedAt:   v.UpdatedAt,
+		}
+	case *area.Area:
+		return AreaRecord{ID: v.ID, Path: v.Path, Name: v.Name, CreatedAt: v.CreatedAt, UpdatedAt: v.UpdatedAt}
+	case *label.Label:
+		return LabelRecord{
+			ID:              v.ID,
+			Name:            v.Name,
+			TextColor:       v.TextColor,
+			BackgroundColor: v.BackgroundColor,
+			BorderColor:     v.BorderColor,
+			CreatedAt:       v.CreatedAt,
+			UpdatedAt:       v.UpdatedAt,
+		}
+	case *codebase.Codebase:
+		return CodebaseRecord{
+			ID:                v.ID,
+			Type:              v.Type,
+			URL:               v.URL,
+			StackID:           v.StackID,
+			LastUsedWorkspace: v.LastUsedWorkspace,
+			CVEScan:           v.CVEScan,
+			CreatedAt:         v.CreatedAt,
+			UpdatedAt:         v.UpdatedAt,
+		}
+	case *workitem.WorkItemStorage:
+		return WorkItemRecord{
+			ID:             v.ID,
+			Number:         v.Number,
+			TypeID:         v.Type,
+			Version:        v.Version,
+			Fields:         v.Fields,
+			ExecutionOrder: v.ExecutionOrder,
+			CreatedAt:      v.CreatedAt,
+			UpdatedAt:      v.UpdatedAt,
+		}
+	case *workitem.Revision:
+		return RevisionRecord{
+			ID:              v.ID,
+			Time:            v.Time,
+			Type:            v.Type,
+			ModifierID:      v.ModifierIdentity,
+			WorkItemID:      v.WorkItemID,
+			WorkItemTypeID:  v.WorkItemTypeID,
+			WorkItemVersion: v.WorkItemVersion,
+			Fields:          v.WorkItemFields,
+		}
+	case *comment.Comment:
+		rec := CommentRecord{
+			ID:         v.ID,
+			WorkItemID: v.ParentID,
+			CreatorID:  v.Creator,
+			Body:       v.Body,
+			Markup:     v.Markup,
+			CreatedAt:  v.CreatedAt,
+			UpdatedAt:  v.UpdatedAt,
+		}
+		if v.ParentCommentID.Valid {
+			parentCommentID := v.ParentCommentID.UUID
+			rec.ParentCommentID = &parentCommentID
+		}
+		return rec
+	case *link.WorkItemLink:
+		return LinkRecord{
+			ID:         v.ID,
+			SourceID:   v.SourceID,
+			TargetID:   v.TargetID,
+			LinkTypeID: v.LinkTypeID,
+			CreatedAt:  v.CreatedAt,
+			UpdatedAt:  v.UpdatedAt,
+		}
+	case *query.Query:
+		return QueryRecord{
+			ID:        v.ID,
+			CreatorID: v.Creator,
+			Title:     v.Title,
+			Fields:    v.Fields,
+			CreatedAt: v.CreatedAt,
+			UpdatedAt: v.UpdatedAt,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,563 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/area"
+	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/comment"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/label"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/path"
+	"github.com/fabric8-services/fabric8-wit/query"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	numbersequence "github.com/fabric8-services/fabric8-wit/workitem/number_sequence"
+	"github.com/goadesign/goa"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// ImportOptions tell how to import an archive
+type ImportOptions struct {
+	// OwnerID is the identity which owns the imported space. It also stands in
+	// for all the other identities of the archive because the import must not
+	// create content on behalf of other users.
+	OwnerID uuid.UUID
+	// Name overrides the name of the exported space if it is set
+	Name *string
+	// MaxSize is the maximum number of bytes read from the archive, there is
+	// no limit if it is 0
+	MaxSize int64
+}
+
+// ImportResult tells what was imported
+type ImportResult struct {
+	// SpaceID is the ID of the new space
+	SpaceID uuid.UUID
+	// Counts holds the number of imported records per kind
+	Counts map[RecordKind]int
+	// ReplacedIdentities lists the usernames of the identities of the archive
+	// which were replaced by the owner of the new space
+	ReplacedIdentities []string
+	// RenumberedWorkItems maps the numbers of the work items which could not be
+	// kept to their new numbers
+	RenumberedWorkItems map[int]int
+}
+
+// importer holds the state of a single import
+type importer struct {
+	r      *GormRepository
+	opts   ImportOptions
+	result *ImportResult
+	// ids maps the IDs of the archive to the new IDs
+	ids map[uuid.UUID]uuid.UUID
+	// owner is the identity which owns the new space
+	owner *account.Identity
+	// identities maps the identity IDs of the archive to the identities here
+	identities map[uuid.UUID]uuid.UUID
+	wits       witCache
+	// numbers holds the work item numbers in use in the new space
+	numbers   map[int]struct{}
+	maxNumber int
+}
+
+// Import reads an archive and creates a new space from it. All the entities
+// get new IDs, the owner of the new space stands in for the identities of the
+// archive and work items keep their numbers unless the archive uses a number
+// twice. Import should run in a
+// transaction so that nothing is left behind when it fails.
+func (r *GormRepository) Import(ctx context.Context, reader io.Reader, opts ImportOptions) (*ImportResult, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "space_archive", "import"}, time.Now())
+	imp := importer{
+		r:    r,
+		opts: opts,
+		result: &ImportResult{
+			Counts:              map[RecordKind]int{},
+			ReplacedIdentities:  []string{},
+			RenumberedWorkItems: map[int]int{},
+		},
+		ids:        map[uuid.UUID]uuid.UUID{},
+		identities: map[uuid.UUID]uuid.UUID{},
+		wits:       witCache{repo: workitem.NewWorkItemTypeRepository(r.db)},
+		numbers:    map[int]struct{}{},
+	}
+	owner, err := account.NewIdentityRepository(r.db).Load(ctx, opts.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	imp.owner = owner
+	var limited *countingReader
+	if opts.MaxSize > 0 {
+		// one byte more than allowed is read to tell that the archive is too large
+		limited = &countingReader{r: io.LimitReader(reader, opts.MaxSize+1)}
+		reader = limited
+	}
+	tooLarge := func() bool {
+		return limited != nil && limited.n > opts.MaxSize
+	}
+	dec := json.NewDecoder(reader)
+	for line := 1; ; line++ {
+		var rec Record
+		err := dec.Decode(&rec)
+		if tooLarge() {
+			return nil, errors.NewBadParameterError("archive", fmt.Sprintf("more than %d bytes", opts.MaxSize)).Expected(fmt.Sprintf("at most %d bytes", opts.MaxSize))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.NewBadParameterError("archive", fmt.Sprintf("record %d", line)).Expected("JSON record")
+		}
+		if line == 1 && rec.Kind != KindHeader {
+			return nil, errors.NewBadParameterError("archive", rec.Kind).Expected("header as first record")
+		}
+		if line > 1 && rec.Kind == KindHeader {
+			return nil, errors.NewBadParameterError("archive", rec.Kind).Expected("single header")
+		}
+		if err := imp.importRecord(ctx, rec); err != nil {
+			return nil, errs.Wrapf(err, "failed to import record %d (%s)", line, rec.Kind)
+		}
+		imp.result.Counts[rec.Kind]++
+	}
+	if imp.result.SpaceID == uuid.Nil {
+		return nil, errors.NewBadParameterError("archive", "empty").Expected("header as first record")
+	}
+	if imp.maxNumber > 0 {
+		if err := numbersequence.NewWorkItemNumberSequenceRepository(r.db).Reserve(ctx, imp.result.SpaceID, imp.maxNumber); err != nil {
+			return nil, errors.NewInternalError(ctx, err)
+		}
+	}
+	log.Info(ctx, map[string]interface{}{
+		"space_id": imp.result.SpaceID,
+		"counts":   imp.result.Counts,
+	}, "space imported")
+	return imp.result, nil
+}
+
+func (imp *importer) importRecord(ctx context.Context, rec Record) error {
+	switch rec.Kind {
+	case KindHeader:
+		var h Header
+		if err := unmarshal(rec, &h); err != nil {
+			return err
+		}
+		return imp.importHeader(ctx, h)
+	case KindIdentity:
+		var i IdentityRecord
+		if err := unmarshal(rec, &i); err != nil {
+			return err
+		}
+		return imp.importIdentity(ctx, i)
+	case KindIteration:
+		var i IterationRecord
+		if err := unmarshal(rec, &i); err != nil {
+			return err
+		}
+		return imp.create(ctx, &iteration.Iteration{
+			Lifecycle:   lifecycle(i.CreatedAt, i.UpdatedAt),
+			ID:          imp.newID(i.ID),
+			SpaceID:     imp.result.SpaceID,
+			Path:        imp.path(i.Path),
+			Name:        i.Name,
+			Description: i.Description,
+			StartAt:     i.StartAt,
+			EndAt:       i.EndAt,
+			State:       iteration.State(i.State),
+			UserActive:  i.UserActive,
+		})
+	case KindArea:
+		var a AreaRecord
+		if err := unmarshal(rec, &a); err != nil {
+			return err
+		}
+		return imp.create(ctx, &area.Area{
+			Lifecycle: lifecycle(a.CreatedAt, a.UpdatedAt),
+			ID:        imp.newID(a.ID),
+			SpaceID:   imp.result.SpaceID,
+			Path:      imp.path(a.Path),
+			Name:      a.Name,
+		})
+	case KindLabel:
+		var l LabelRecord
+		if err := unmarshal(rec, &l); err != nil {
+			return err
+		}
+		return imp.create(ctx, &label.Label{
+			Lifecycle:       lifecycle(l.CreatedAt, l.UpdatedAt),
+			ID:              imp.newID(l.ID),
+			SpaceID:         imp.result.SpaceID,
+			Name:            l.Name,
+			TextColor:       l.TextColor,
+			BackgroundColor: l.BackgroundColor,
+			BorderColor:     l.BorderColor,
+		})
+	case KindCodebase:
+		var c CodebaseRecord
+		if err := unmarshal(rec, &c); err != nil {
+			return err
+		}
+		return imp.create(ctx, &codebase.Codebase{
+			Lifecycle:         lifecycle(c.CreatedAt, c.UpdatedAt),
+			ID:                imp.newID(c.ID),
+			SpaceID:           imp.result.SpaceID,
+			Type:              c.Type,
+			URL:               c.URL,
+			StackID:           c.StackID,
+			LastUsedWorkspace: c.LastUsedWorkspace,
+			CVEScan:           c.CVEScan,
+		})
+	case KindWorkItem:
+		var wi WorkItemRecord
+		if err := unmarshal(rec, &wi); err != nil {
+			return err
+		}
+		return imp.importWorkItem(ctx, wi)
+	case KindRevision:
+		var rev RevisionRecord
+		if err := unmarshal(rec, &rev); err != nil {
+			return err
+		}
+		return imp.importRevision(ctx, rev)
+	case KindComment:
+		var c CommentRecord
+		if err := unmarshal(rec, &c); err != nil {
+			return err
+		}
+		return imp.importComment(ctx, c)
+	case KindLink:
+		var l LinkRecord
+		if err := unmarshal(rec, &l); err != nil {
+			return err
+		}
+		sourceID, err := imp.existingID("source_id", l.SourceID)
+		if err != nil {
+			return err
+		}
+		targetID, err := imp.existingID("target_id", l.TargetID)
+		if err != nil {
+			return err
+		}
+		return imp.create(ctx, &link.WorkItemLink{
+			Lifecycle:  lifecycle(l.CreatedAt, l.UpdatedAt),
+			ID:         imp.newID(l.ID),
+			SourceID:   sourceID,
+			TargetID:   targetID,
+			LinkTypeID: l.LinkTypeID,
+		})
+	case KindQuery:
+		var q QueryRecord
+		if err := unmarshal(rec, &q); err != nil {
+			return err
+		}
+		return imp.create(ctx, &query.Query{
+			Lifecycle: lifecycle(q.CreatedAt, q.UpdatedAt),
+			ID:        imp.newID(q.ID),
+			SpaceID:   imp.result.SpaceID,
+			Creator:   imp.identity(q.CreatorID),
+			Title:     q.Title,
+			Fields:    imp.replaceIDs(q.Fields),
+		})
+	default:
+		return errors.NewBadParameterError("kind", rec.Kind).Expected("known record kind")
+	}
+}
+
+func (imp *importer) importHeader(ctx context.Context, h Header) error {
+	if h.Version > FormatVersion {
+		return errors.NewBadParameterError("version", h.Version).Expected(fmt.Sprintf("archive version up to %d", FormatVersion))
+	}
+	name := h.Space.Name
+	if imp.opts.Name != nil {
+		name = *imp.opts.Name
+	}
+	s, err := space.NewRepository(imp.r.db).Create(ctx, &space.Space{
+		Name:            name,
+		Description:     h.Space.Description,
+		OwnerID:         imp.opts.OwnerID,
+		SpaceTemplateID: h.Space.SpaceTemplateID,
+	})
+	if err != nil {
+		return err
+	}
+	imp.ids[h.Space.ID] = s.ID
+	imp.wits.spaceTemplateID = s.SpaceTemplateID
+	imp.identities[h.Space.OwnerID] = imp.opts.OwnerID
+	imp.result.SpaceID = s.ID
+	return nil
+}
+
+// importIdentity maps the identity of the archive to the owner of the new
+// space. Only the owner's own identity is kept, every other identity is
+// replaced and reported because the import must not attribute content to
+// users who didn't import it.
+func (imp *importer) importIdentity(ctx context.Context, i IdentityRecord) error {
+	imp.identities[i.ID] = imp.owner.ID
+	if i.Username != imp.owner.Username {
+		imp.result.ReplacedIdentities = append(imp.result.ReplacedIdentities, i.Username)
+	}
+	return nil
+}
+
+func (imp *importer) importWorkItem(ctx context.Context, rec WorkItemRecord) error {
+	wit, err := imp.wits.load(ctx, rec.TypeID)
+	if err != nil {
+		return err
+	}
+	number := rec.Number
+	if _, used := imp.numbers[number]; used || number <= 0 {
+		number = imp.maxNumber + 1
+		imp.result.RenumberedWorkItems[rec.Number] = number
+	}
+	imp.numbers[number] = struct{}{}
+	if number > imp.maxNumber {
+		imp.maxNumber = number
+	}
+	return imp.create(ctx, &workitem.WorkItemStorage{
+		Lifecycle:      lifecycle(rec.CreatedAt, rec.UpdatedAt),
+		ID:             imp.newID(rec.ID),
+		Number:         number,
+		Type:           rec.TypeID,
+		Version:        rec.Version,
+		Fields:         imp.fields(*wit, rec.Fields),
+		ExecutionOrder: rec.ExecutionOrder,
+		SpaceID:        imp.result.SpaceID,
+	})
+}
+
+func (imp *importer) importRevision(ctx context.Context, rec RevisionRecord) error {
+	workItemID, err := imp.existingID("workitem_id", rec.WorkItemID)
+	if err != nil {
+		return err
+	}
+	wit, err := imp.wits.load(ctx, rec.WorkItemTypeID)
+	if err != nil {
+		return err
+	}
+	return imp.create(ctx, &workitem.Revision{
+		ID:               imp.newID(rec.ID),
+		Time:             rec.Time,
+		Type:             rec.Type,
+		ModifierIdentity: imp.identity(rec.ModifierID),
+		WorkItemID:       workItemID,
+		WorkItemTypeID:   rec.WorkItemTypeID,
+		WorkItemVersion:  rec.WorkItemVersion,
+		WorkItemFields:   imp.fields(*wit, rec.Fields),
+	})
+}
+
+func (imp *importer) importComment(ctx context.Context, rec CommentRecord) error {
+	workItemID, err := imp.existingID("workitem_id", rec.WorkItemID)
+	if err != nil {
+		return err
+	}
+	c := comment.Comment{
+		Lifecycle: lifecycle(rec.CreatedAt, rec.UpdatedAt),
+		ID:        imp.newID(rec.ID),
+		ParentID:  workItemID,
+		Creator:   imp.identity(rec.CreatorID),
+		Body:      imp.replaceIDs(rec.Body),
+		Markup:    rec.Markup,
+	}
+	if rec.ParentCommentID != nil {
+		parentCommentID, err := imp.existingID("parent_comment_id", *rec.ParentCommentID)
+		if err != nil {
+			return err
+		}
+		c.ParentCommentID = id.NullUUID{UUID: parentCommentID, Valid: true}
+	}
+	return imp.create(ctx, &c)
+}
+
+// create inserts the given entity as it is, i.e. with its ID and timestamps
+func (imp *importer) create(ctx context.Context, entity interface{}) error {
+	if err := imp.r.db.Create(entity).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": imp.result.SpaceID,
+			"err":      err,
+		}, "unable to import %T", entity)
+		return errors.NewBadParameterErrorFromString(fmt.Sprintf("failed to import %T: %s", entity, err))
+	}
+	return nil
+}
+
+// newID returns the new ID for the given ID of the archive
+func (imp *importer) newID(oldID uuid.UUID) uuid.UUID {
+	if newID, ok := imp.ids[oldID]; ok {
+		return newID
+	}
+	newID := uuid.NewV4()
+	imp.ids[oldID] = newID
+	return newID
+}
+
+// existingID returns the new ID of an entity which must have been imported
+// before
+func (imp *importer) existingID(field string, oldID uuid.UUID) (uuid.UUID, error) {
+	newID, ok := imp.ids[oldID]
+	if !ok {
+		return uuid.Nil, errors.NewBadParameterError(field, oldID).Expected("ID of an entity earlier in the archive")
+	}
+	return newID, nil
+}
+
+// identity returns the identity here for the given identity ID of the archive
+func (imp *importer) identity(oldID uuid.UUID) uuid.UUID {
+	if newID, ok := imp.identities[oldID]; ok {
+		return newID
+	}
+	return imp.opts.OwnerID
+}
+
+func (imp *importer) path(p []uuid.UUID) path.Path {
+	res := make(path.Path, len(p))
+	for i, oldID := range p {
+		res[i] = imp.newID(oldID)
+	}
+	return res
+}
+
+// fields returns the given work item fields with the references to entities
+// and identities of the archive replaced
+func (imp *importer) fields(wit workitem.WorkItemType, fields workitem.Fields) workitem.Fields {
+	return mapFieldValues(wit, fields, func(kind workitem.Kind, value interface{}) interface{} {
+		switch kind {
+		case workitem.KindUser:
+			if oldID, err := uuid.FromString(toString(value)); err == nil {
+				return imp.identity(oldID).String()
+			}
+		case workitem.KindIteration, workitem.KindArea, workitem.KindLabel:
+			if oldID, err := uuid.FromString(toString(value)); err == nil {
+				if newID, ok := imp.ids[oldID]; ok {
+					return newID.String()
+				}
+			}
+		case workitem.KindCodebase:
+			if content, ok := value.(map[string]interface{}); ok {
+				if oldID, err := uuid.FromString(toString(content[codebase.CodebaseIDKey])); err == nil {
+					if newID, ok := imp.ids[oldID]; ok {
+						content[codebase.CodebaseIDKey] = newID.String()
+					}
+				}
+			}
+		}
+		return value
+	})
+}
+
+// replaceIDs replaces the IDs of the archive in the given text, e.g. the
+// space and iteration IDs in the filters of a query
+func (imp *importer) replaceIDs(text string) string {
+	for oldID, newID := range imp.ids {
+		text = strings.Replace(text, oldID.String(), newID.String(), -1)
+	}
+	return text
+}
+
+func unmarshal(rec Record, v interface{}) error {
+	if err := json.Unmarshal(rec.Data, v); err != nil {
+		return errors.NewBadParameterError("data", string(rec.Data)).Expected(fmt.Sprintf("%s record", rec.Kind))
+	}
+	return nil
+}
+
+func lifecycle(createdAt, updatedAt time.Time) gormsupport.Lifecycle {
+	return gormsupport.Lifecycle{CreatedAt: createdAt, UpdatedAt: updatedAt}
+}
+
+func toString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
+
+// mapFieldValues calls f for every value of the fields of the given type and
+// stores what f returns. The values of list fields are passed one by one with
+// the kind of the list components.
+func mapFieldValues(wit workitem.WorkItemType, fields workitem.Fields, f func(kind workitem.Kind, value interface{}) interface{}) workitem.Fields {
+	for name, def := range wit.Fields {
+		value, ok := fields[name]
+		if !ok || value == nil {
+			continue
+		}
+		if listType, isList := def.Type.(workitem.ListType); isList {
+			if values, ok := value.([]interface{}); ok {
+				for i, v := range values {
+					values[i] = f(listType.ComponentType.GetKind(), v)
+				}
+			}
+			continue
+		}
+		fields[name] = f(def.Type.GetKind(), value)
+	}
+	return fields
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// witCache loads each work item type once. Only the work item types of the
+// space template are accepted.
+type witCache struct {
+	repo            *workitem.GormWorkItemTypeRepository
+	spaceTemplateID uuid.UUID
+	types           map[uuid.UUID]*workitem.WorkItemType
+}
+
+// preload loads all the work item types of the space template
+func (c *witCache) preload(ctx context.Context) error {
+	wits, err := c.repo.List(ctx, c.spaceTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load the work item types of space template %s", c.spaceTemplateID)
+	}
+	c.types = make(map[uuid.UUID]*workitem.WorkItemType, len(wits))
+	for i := range wits {
+		c.types[wits[i].ID] = &wits[i]
+	}
+	return nil
+}
+
+// get returns a work item type which was loaded before
+func (c *witCache) get(typeID uuid.UUID) (*workitem.WorkItemType, error) {
+	if wit, ok := c.types[typeID]; ok {
+		return wit, nil
+	}
+	return nil, errors.NewBadParameterError("work_item_type_id", typeID).Expected("work item type of space template " + c.spaceTemplateID.String())
+}
+
+func (c *witCache) load(ctx context.Context, typeID uuid.UUID) (*workitem.WorkItemType, error) {
+	if wit, ok := c.types[typeID]; ok {
+		return wit, nil
+	}
+	wit, err := c.repo.Load(ctx, typeID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "unknown work item type %s", typeID)
+	}
+	if wit.SpaceTemplateID != c.spaceTemplateID {
+		return nil, errors.NewBadParameterError("work_item_type_id", typeID).Expected("work item type of space template " + c.spaceTemplateID.String())
+	}
+	if c.types == nil {
+		c.types = map[uuid.UUID]*workitem.WorkItemType{}
+	}
+	c.types[typeID] = wit
+	return wit, nil
+}
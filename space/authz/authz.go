@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sync"
+	"time"
 
+	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/auth"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/log"
@@ -44,27 +47,84 @@ func (m *AuthzServiceManagerWrapper) AuthzService() AuthzService {
 	return m.Service
 }
 
+// DefaultRoleCacheTTL is the time for which the roles of a user in a space
+// are cached by the AuthzRoleService
+const DefaultRoleCacheTTL = 30 * time.Second
+
 // AuthzRoleService is an implementation of a space authorization service based or user roles
 // loaded from the native Auth service
 type AuthzRoleService struct {
 	Config auth.ServiceConfiguration
 	Doer   rest.HttpDoer
+	// Local resolves the roles for requests made with a personal access
+	// token. Without it such requests have no role. If it is a RoleRecorder
+	// the roles reported by the Auth service are recorded with it.
+	Local LocalRoleResolver
+	cache *roleCache
 }
 
+// Ensure AuthzRoleService implements the RoleService interface
+var _ RoleService = &AuthzRoleService{}
+
 // NewAuthzService constructs a new AuthzRoleService
 func NewAuthzService(config auth.ServiceConfiguration) *AuthzRoleService {
-	return &AuthzRoleService{Config: config, Doer: rest.DefaultHttpDoer()}
+	return &AuthzRoleService{Config: config, Doer: rest.DefaultHttpDoer(), cache: newRoleCache(DefaultRoleCacheTTL)}
 }
 
-// Authorize returns true if the current user is among the space collaborators
+// Authorize returns true if the current user is among the space collaborators,
+// i.e. if one of the roles of the user allows to edit work items.
 func (s *AuthzRoleService) Authorize(ctx context.Context, spaceID string) (bool, error) {
+	roles, err := s.Roles(ctx, spaceID)
+	if err != nil {
+		return false, err
+	}
+	return HasPermission(roles, PermissionEditWorkItem), nil
+}
+
+// Roles returns the names of the roles of the current user in the space. The
+// roles are loaded from the Auth service and cached for a short time. The
+// roles for requests made with a personal access token are resolved locally
+// as there is no signed token to present to the Auth service.
+func (s *AuthzRoleService) Roles(ctx context.Context, spaceID string) ([]string, error) {
 	jwttoken := goajwt.ContextJWT(ctx)
 	if jwttoken == nil {
-		return false, errors.NewUnauthorizedError("missing token")
+		return nil, errors.NewUnauthorizedError("missing token")
+	}
+	if _, ok := account.ContextPersonalAccessTokenScopes(ctx); ok {
+		return s.localRoles(ctx, spaceID)
 	}
 	return s.checkRole(ctx, *jwttoken, spaceID)
 }
 
+// localRoles returns the roles of the current user in the space from the
+// LocalRoleResolver
+func (s *AuthzRoleService) localRoles(ctx context.Context, spaceID string) ([]string, error) {
+	if !s.Config.IsAuthorizationEnabled() {
+		return []string{RoleAdmin}, nil
+	}
+	currentIdentityID, err := login.ContextIdentity(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the key differs from the one used for the roles from the Auth service
+	id := "pat:" + currentIdentityID.String()
+	if roles, ok := s.cache.get(spaceID, id); ok {
+		return roles, nil
+	}
+	if s.Local == nil {
+		log.Warn(ctx, map[string]interface{}{
+			"space_id": spaceID,
+		}, "no local role resolver for requests made with a personal access token")
+		return []string{}, nil
+	}
+	roles, err := s.Local.Roles(ctx, *currentIdentityID, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	s.cache.put(spaceID, id, roles)
+	return roles, nil
+}
+
 // Configuration returns auth service configuration
 func (s *AuthzRoleService) Configuration() auth.ServiceConfiguration {
 	return s.Config
@@ -79,22 +139,26 @@ type Role struct {
 	AssigneeID string `json:"assignee_id"`
 }
 
-func (s *AuthzRoleService) checkRole(ctx context.Context, token jwt.Token, spaceID string) (bool, error) {
+func (s *AuthzRoleService) checkRole(ctx context.Context, token jwt.Token, spaceID string) ([]string, error) {
 	if !s.Config.IsAuthorizationEnabled() {
 		// authorization is disabled by default in Developer Mode
 		log.Warn(ctx, map[string]interface{}{
 			"space_id": spaceID,
 		}, "Authorization is disabled. All users are allowed to operate the space")
-		return true, nil
+		return []string{RoleAdmin}, nil
 	}
 	currentIdentityID, err := login.ContextIdentity(ctx)
 	if err != nil {
-		return false, err
+		return nil, err
+	}
+	id := currentIdentityID.String()
+	if roles, ok := s.cache.get(spaceID, id); ok {
+		return roles, nil
 	}
 
 	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/resources/%s/roles", s.Config.GetAuthServiceURL(), spaceID), nil)
 	if err != nil {
-		return false, err
+		return nil, err
 	}
 
 	reqID := middleware.ContextRequestID(ctx)
@@ -107,32 +171,91 @@ func (s *AuthzRoleService) checkRole(ctx context.Context, token jwt.Token, space
 	req.Header.Set("Authorization", "Bearer "+token.Raw)
 	res, err := s.Doer.Do(ctx, req)
 	if err != nil {
-		return false, errors.NewInternalError(ctx, err)
+		return nil, errors.NewInternalError(ctx, err)
 	}
 	defer rest.CloseResponse(res)
 	bodyString := rest.ReadBody(res.Body)
 
 	if res.StatusCode == http.StatusForbidden {
 		// The current identity doesn't have permissions to view the list of assigned roles for the space
-		return false, nil
+		s.cache.put(spaceID, id, []string{})
+		return []string{}, nil
 	}
 	if res.StatusCode != http.StatusOK {
-		return false, errors.NewInternalError(ctx, errs.New("unable to get space roles. Response status: "+res.Status+". Response body: "+bodyString))
+		return nil, errors.NewInternalError(ctx, errs.New("unable to get space roles. Response status: "+res.Status+". Response body: "+bodyString))
 	}
 
 	var roles Roles
 	err = json.Unmarshal([]byte(bodyString), &roles)
 	if err != nil {
-		return false, errors.NewInternalError(ctx, err)
+		return nil, errors.NewInternalError(ctx, err)
 	}
 
-	id := currentIdentityID.String()
+	// the roles of all the collaborators are recorded so that the roles of
+	// their requests made with personal access tokens can be resolved later
+	if recorder, ok := s.Local.(RoleRecorder); ok {
+		if err := recorder.Record(ctx, spaceID, roles.Data); err != nil {
+			log.Warn(ctx, map[string]interface{}{
+				"space_id": spaceID,
+				"err":      err,
+			}, "unable to record the roles of the space")
+		}
+	}
+	result := []string{}
 	for _, r := range roles.Data {
-		if r.AssigneeID == id && (r.RoleName == "admin" || r.RoleName == "contributor") {
-			return true, nil
+		if r.AssigneeID == id {
+			result = append(result, r.RoleName)
 		}
 	}
-	return false, nil
+	s.cache.put(spaceID, id, result)
+	return result, nil
+}
+
+// roleCache holds the roles of users in spaces for a limited time
+type roleCache struct {
+	ttl     time.Duration
+	lock    sync.RWMutex
+	entries map[string]roleCacheEntry
+}
+
+type roleCacheEntry struct {
+	roles     []string
+	expiresAt time.Time
+}
+
+func newRoleCache(ttl time.Duration) *roleCache {
+	return &roleCache{ttl: ttl, entries: map[string]roleCacheEntry{}}
+}
+
+// get returns the cached roles of the identity in the space, if any. A nil
+// cache never holds roles.
+func (c *roleCache) get(spaceID, identityID string) ([]string, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, ok := c.entries[spaceID+"/"+identityID]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.roles, true
+}
+
+// put caches the roles of the identity in the space
+func (c *roleCache) put(spaceID, identityID string, roles []string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	now := time.Now()
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.entries[spaceID+"/"+identityID] = roleCacheEntry{roles: roles, expiresAt: now.Add(c.ttl)}
 }
 
 // InjectAuthzService is a middleware responsible for setting up AuthzService in the context for every request.
@@ -146,7 +269,11 @@ func InjectAuthzService(service AuthzService) goa.Middleware {
 }
 
 // Authorize returns true and the corresponding Requesting Party Token if the current user is among the space collaborators
+// and, for requests made with a personal access token, its scopes allow to edit work items
 func Authorize(ctx context.Context, spaceID string) (bool, error) {
+	if !allowedByTokenScopes(ctx, PermissionEditWorkItem) {
+		return false, nil
+	}
 	srv := tokencontext.ReadSpaceAuthzServiceFromContext(ctx)
 	if srv == nil {
 		log.Error(ctx, map[string]interface{}{
@@ -9,8 +9,10 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/fabric8-services/fabric8-wit/account"
 	"github.com/fabric8-services/fabric8-wit/auth"
 	witerrors "github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/login/tokencontext"
 	"github.com/fabric8-services/fabric8-wit/resource"
 	"github.com/fabric8-services/fabric8-wit/rest"
 	. "github.com/fabric8-services/fabric8-wit/space/authz"
@@ -18,6 +20,7 @@ import (
 	testsuite "github.com/fabric8-services/fabric8-wit/test/suite"
 	"github.com/fabric8-services/fabric8-wit/test/token"
 
+	goajwt "github.com/goadesign/goa/middleware/security/jwt"
 	errs "github.com/pkg/errors"
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -146,6 +149,28 @@ func (s *TestAuthzSuite) TestAuthorizeFailWithError() {
 	testsupport.AssertError(s.T(), err, witerrors.InternalError{}, "unable to get space roles. Response status: 500. Response body: ")
 }
 
+func (s *TestAuthzSuite) TestRolesAreCached() {
+	ctx, identityID, _, _ := token.ContextWithTokenAndRequestID(s.T())
+	spaceID := uuid.NewV4().String()
+	s.doer.Client.AssertRequest = nil
+	s.doer.Client.Error = nil
+	body := ioutil.NopCloser(bytes.NewReader([]byte(fmt.Sprintf("{\"data\":[{\"role_name\":\"planner\",\"assignee_id\":\"%s\"}]}", identityID.String()))))
+	s.doer.Client.Response = &http.Response{Body: body, StatusCode: http.StatusOK}
+
+	roles, err := s.authzService.Roles(ctx, spaceID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"planner"}, roles)
+
+	// the Auth service isn't asked again
+	s.doer.Client.Error = errors.New("oopsie woopsie")
+	roles, err = s.authzService.Roles(ctx, spaceID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{"planner"}, roles)
+	ok, err := s.authzService.Authorize(ctx, spaceID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), ok)
+}
+
 func (s *TestAuthzSuite) checkAuthorize(ctx context.Context, token, reqID, responsePayload string, expectedAllowed bool) {
 	spaceID := uuid.NewV4().String()
 
@@ -184,3 +209,84 @@ func (c *authURLConfig) GetAuthShortServiceHostName() string {
 func (c *authURLConfig) IsAuthorizationEnabled() bool {
 	return !c.disabled
 }
+
+// staticRoleResolver resolves the same roles for everybody
+type staticRoleResolver struct {
+	roles []string
+}
+
+func (r staticRoleResolver) Roles(ctx context.Context, identityID uuid.UUID, spaceID string) ([]string, error) {
+	return r.roles, nil
+}
+
+func (s *TestAuthzSuite) TestPersonalAccessTokenRolesAreResolvedLocally() {
+	pat := account.PersonalAccessToken{ID: uuid.NewV4(), IdentityID: uuid.NewV4(), Scopes: []string{account.ScopeSpaceAdmin}}
+	ctx := goajwt.WithJWT(context.Background(), pat.JWT())
+	ctx = tokencontext.ContextWithTokenManager(ctx, token.TokenManager)
+	as := NewAuthzService(&authURLConfig{authURL: "https://some.auth.io"})
+	doer := testsupport.NewDummyHttpDoer()
+	// the Auth service must not be asked
+	doer.Client.Error = errors.New("oopsie woopsie")
+	as.Doer = doer
+
+	s.T().Run("without resolver", func(t *testing.T) {
+		roles, err := as.Roles(ctx, uuid.NewV4().String())
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+	s.T().Run("with resolver", func(t *testing.T) {
+		as.Local = staticRoleResolver{roles: []string{RolePlanner}}
+		roles, err := as.Roles(ctx, uuid.NewV4().String())
+		require.NoError(t, err)
+		assert.Equal(t, []string{RolePlanner}, roles)
+	})
+}
+
+// recordingRoleResolver resolves the roles which were recorded last
+type recordingRoleResolver struct {
+	roles []Role
+}
+
+func (r *recordingRoleResolver) Roles(ctx context.Context, identityID uuid.UUID, spaceID string) ([]string, error) {
+	result := []string{}
+	for _, role := range r.roles {
+		if role.AssigneeID == identityID.String() {
+			result = append(result, role.RoleName)
+		}
+	}
+	return result, nil
+}
+
+func (r *recordingRoleResolver) Record(ctx context.Context, spaceID string, roles []Role) error {
+	r.roles = roles
+	return nil
+}
+
+func (s *TestAuthzSuite) TestCollaboratorWithPersonalAccessToken() {
+	// given
+	as := NewAuthzService(&authURLConfig{authURL: "https://some.auth.io"})
+	doer := testsupport.NewDummyHttpDoer()
+	as.Doer = doer
+	resolver := &recordingRoleResolver{}
+	as.Local = resolver
+	spaceID := uuid.NewV4().String()
+	collaboratorID := uuid.NewV4()
+	// the roles of the space are looked up with a token of the owner
+	ownerCtx, ownerID, _, _ := token.ContextWithTokenAndRequestID(s.T())
+	body := ioutil.NopCloser(bytes.NewReader([]byte(fmt.Sprintf("{\"data\":[{\"role_name\":\"admin\",\"assignee_id\":\"%s\"},{\"role_name\":\"contributor\",\"assignee_id\":\"%s\"}]}", ownerID, collaboratorID))))
+	doer.Client.Response = &http.Response{Body: body, StatusCode: http.StatusOK}
+	_, err := as.Roles(ownerCtx, spaceID)
+	require.NoError(s.T(), err)
+	// when
+	doer.Client.Error = errors.New("oopsie woopsie")
+	pat := account.PersonalAccessToken{ID: uuid.NewV4(), IdentityID: collaboratorID, Scopes: []string{account.ScopeWorkItemWrite}}
+	ctx := goajwt.WithJWT(context.Background(), pat.JWT())
+	ctx = tokencontext.ContextWithTokenManager(ctx, token.TokenManager)
+	roles, err := as.Roles(ctx, spaceID)
+	// then
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), []string{RoleContributor}, roles)
+	ok, err := as.Authorize(ctx, spaceID)
+	require.NoError(s.T(), err)
+	assert.True(s.T(), ok)
+}
@@ -0,0 +1,194 @@
+package authz
+
+import (
+	"context"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/login/tokencontext"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Permission is an operation in a space which is granted by the roles of a
+// user in that space
+type Permission string
+
+// Permissions that the roles of a user in a space can grant
+const (
+	// PermissionEditWorkItem allows to create and update work items
+	PermissionEditWorkItem Permission = "edit_workitem"
+	// PermissionDeleteWorkItem allows to delete work items
+	PermissionDeleteWorkItem Permission = "delete_workitem"
+	// PermissionEditIteration allows to update iterations and to create child
+	// iterations
+	PermissionEditIteration Permission = "edit_iteration"
+	// PermissionManageIterations allows to create top-level iterations and to
+	// delete iterations
+	PermissionManageIterations Permission = "manage_iterations"
+	// PermissionEditArea allows to create areas
+	PermissionEditArea Permission = "edit_area"
+	// PermissionManageLabels allows to create and update labels
+	PermissionManageLabels Permission = "manage_labels"
+	// PermissionManageBoards allows to move work items between board columns
+	PermissionManageBoards Permission = "manage_boards"
+	// PermissionManageTemplate allows to clone the space template and to
+	// migrate the space to another template
+	PermissionManageTemplate Permission = "manage_template"
+	// PermissionViewAttachments allows to list and download the attachments
+	// of work items
+	PermissionViewAttachments Permission = "view_attachments"
+	// PermissionManageSpace allows to update, export and delete the space and
+	// to manage its codebases and webhooks. No role grants it, only the space
+	// owner can do this.
+	PermissionManageSpace Permission = "manage_space"
+)
+
+// Roles that a user can have in a space
+const (
+	RoleViewer      = "viewer"
+	RoleContributor = "contributor"
+	RolePlanner     = "planner"
+	RoleAdmin       = "admin"
+)
+
+// RolePermissions holds the permissions granted by each role. Unknown roles
+// grant no permission.
+var RolePermissions = map[string][]Permission{
+	RoleViewer: {
+		PermissionViewAttachments,
+	},
+	RoleContributor: {
+		PermissionEditWorkItem,
+		PermissionDeleteWorkItem,
+		PermissionEditIteration,
+		PermissionManageLabels,
+		PermissionManageBoards,
+		PermissionViewAttachments,
+	},
+	RolePlanner: {
+		PermissionEditWorkItem,
+		PermissionDeleteWorkItem,
+		PermissionEditIteration,
+		PermissionManageIterations,
+		PermissionEditArea,
+		PermissionManageLabels,
+		PermissionManageBoards,
+		PermissionViewAttachments,
+	},
+	RoleAdmin: {
+		PermissionEditWorkItem,
+		PermissionDeleteWorkItem,
+		PermissionEditIteration,
+		PermissionManageIterations,
+		PermissionEditArea,
+		PermissionManageLabels,
+		PermissionManageBoards,
+		PermissionManageTemplate,
+		PermissionViewAttachments,
+	},
+}
+
+// ScopePermissions holds the permissions which the scopes of a personal access
+// token leave to the roles of its identity. Unknown scopes leave none.
+var ScopePermissions = map[string][]Permission{
+	account.ScopeReadOnly: {
+		PermissionViewAttachments,
+	},
+	account.ScopeWorkItemWrite: {
+		PermissionEditWorkItem,
+		PermissionDeleteWorkItem,
+		PermissionViewAttachments,
+	},
+	account.ScopeSpaceAdmin: append([]Permission{PermissionManageSpace}, RolePermissions[RoleAdmin]...),
+}
+
+// allowedByTokenScopes returns false if the request was made with a personal
+// access token whose scopes don't include the permission
+func allowedByTokenScopes(ctx context.Context, p Permission) bool {
+	scopes, ok := account.ContextPersonalAccessTokenScopes(ctx)
+	if !ok {
+		return true
+	}
+	for _, scope := range scopes {
+		for _, allowed := range ScopePermissions[scope] {
+			if allowed == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AuthorizeOwner returns true if the identity is the given owner, e.g. of a
+// space, and the owner may use the permission. Requests made with a personal
+// access token are limited to the permissions its scopes allow, even for the
+// owner.
+func AuthorizeOwner(ctx context.Context, identityID, ownerID uuid.UUID, p Permission) bool {
+	if !uuid.Equal(identityID, ownerID) {
+		return false
+	}
+	if !allowedByTokenScopes(ctx, p) {
+		log.Warn(ctx, map[string]interface{}{
+			"owner_id":   ownerID,
+			"permission": p,
+		}, "the scopes of the personal access token don't allow the operation")
+		return false
+	}
+	return true
+}
+
+// HasPermission returns true if one of the given roles grants the permission
+func HasPermission(roles []string, p Permission) bool {
+	for _, role := range roles {
+		for _, granted := range RolePermissions[role] {
+			if granted == p {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// RoleService is implemented by space authorization services which know the
+// roles of the current user in a space
+type RoleService interface {
+	// Roles returns the names of the roles of the current user in the space
+	Roles(ctx context.Context, spaceID string) ([]string, error)
+}
+
+// AuthorizePermission returns true if one of the roles of the current user in
+// the space grants the given permission. Services which don't implement
+// RoleService only tell space collaborators apart, who are treated as
+// contributors. Requests made with a personal access token are further
+// limited to the permissions its scopes allow.
+func AuthorizePermission(ctx context.Context, spaceID string, p Permission) (bool, error) {
+	if !allowedByTokenScopes(ctx, p) {
+		log.Warn(ctx, map[string]interface{}{
+			"space_id":   spaceID,
+			"permission": p,
+		}, "the scopes of the personal access token don't allow the operation")
+		return false, nil
+	}
+	srv := tokencontext.ReadSpaceAuthzServiceFromContext(ctx)
+	if srv == nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+		}, "Missing space authz service")
+
+		return false, errs.New("missing space authz service")
+	}
+	service := srv.(AuthzServiceManager).AuthzService()
+	if roleService, ok := service.(RoleService); ok {
+		roles, err := roleService.Roles(ctx, spaceID)
+		if err != nil {
+			return false, errs.WithStack(err)
+		}
+		return HasPermission(roles, p), nil
+	}
+	collaborator, err := service.Authorize(ctx, spaceID)
+	if err != nil || !collaborator {
+		return false, err
+	}
+	return HasPermission([]string{RoleContributor}, p), nil
+}
@@ -0,0 +1,135 @@
+package authz_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/auth"
+	"github.com/fabric8-services/fabric8-wit/login/tokencontext"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	. "github.com/fabric8-services/fabric8-wit/space/authz"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHasPermission(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.False(t, HasPermission(nil, PermissionEditWorkItem))
+	assert.False(t, HasPermission([]string{RoleViewer}, PermissionEditWorkItem))
+	assert.False(t, HasPermission([]string{"foo"}, PermissionEditWorkItem))
+	assert.True(t, HasPermission([]string{RoleContributor}, PermissionEditWorkItem))
+	assert.False(t, HasPermission([]string{RoleContributor}, PermissionManageIterations))
+	assert.True(t, HasPermission([]string{RoleViewer, RolePlanner}, PermissionManageIterations))
+	for _, p := range []Permission{
+		PermissionEditWorkItem,
+		PermissionDeleteWorkItem,
+		PermissionEditIteration,
+		PermissionManageIterations,
+		PermissionEditArea,
+		PermissionManageLabels,
+		PermissionManageBoards,
+		PermissionManageTemplate,
+		PermissionViewAttachments,
+	} {
+		assert.True(t, HasPermission([]string{RoleAdmin}, p), "admin must have permission %s", p)
+	}
+	assert.False(t, HasPermission([]string{RolePlanner}, PermissionManageTemplate))
+	assert.True(t, HasPermission([]string{RoleViewer}, PermissionViewAttachments))
+}
+
+func TestAuthorizePermission(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	spaceID := uuid.NewV4()
+	identity := testsupport.TestIdentity
+
+	t.Run("local roles", func(t *testing.T) {
+		// given
+		service := NewLocalRoleService().Assign(spaceID, identity.ID, RolePlanner)
+		ctx := testsupport.ServiceAsSpaceUser("Authz-Service", identity, service).Context
+		// when
+		roles, err := service.Roles(ctx, spaceID.String())
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, []string{RolePlanner}, roles)
+		ok, err := AuthorizePermission(ctx, spaceID.String(), PermissionManageIterations)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = AuthorizePermission(ctx, uuid.NewV4().String(), PermissionEditWorkItem)
+		require.NoError(t, err)
+		assert.False(t, ok, "roles must not apply to other spaces")
+	})
+
+	t.Run("collaborators are contributors", func(t *testing.T) {
+		// given
+		ctx := testsupport.ServiceAsSpaceUser("Authz-Service", identity, &collaboratorService{collaborator: true}).Context
+		// when
+		ok, err := AuthorizePermission(ctx, spaceID.String(), PermissionEditWorkItem)
+		// then
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = AuthorizePermission(ctx, spaceID.String(), PermissionEditArea)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("non-collaborators have no permission", func(t *testing.T) {
+		// given
+		ctx := testsupport.ServiceAsSpaceUser("Authz-Service", identity, &collaboratorService{}).Context
+		// when
+		ok, err := AuthorizePermission(ctx, spaceID.String(), PermissionEditWorkItem)
+		// then
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("personal access token scopes", func(t *testing.T) {
+		// given
+		service := NewLocalRoleService().Assign(spaceID, identity.ID, RoleAdmin)
+		ctxWithScopes := func(scopes ...string) context.Context {
+			svc := testsupport.ServiceAsPersonalAccessTokenUser("Authz-Service", account.PersonalAccessToken{
+				ID:         uuid.NewV4(),
+				IdentityID: identity.ID,
+				Scopes:     scopes,
+			})
+			return tokencontext.ContextWithSpaceAuthzService(svc.Context, &AuthzServiceManagerWrapper{Service: service})
+		}
+		// when/then
+		ok, err := AuthorizePermission(ctxWithScopes(account.ScopeReadOnly), spaceID.String(), PermissionEditWorkItem)
+		require.NoError(t, err)
+		assert.False(t, ok, "read-only tokens must not edit work items")
+		ok, err = AuthorizePermission(ctxWithScopes(account.ScopeWorkItemWrite), spaceID.String(), PermissionEditWorkItem)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = AuthorizePermission(ctxWithScopes(account.ScopeWorkItemWrite), spaceID.String(), PermissionManageIterations)
+		require.NoError(t, err)
+		assert.False(t, ok, "work item tokens must not manage iterations")
+		ok, err = AuthorizePermission(ctxWithScopes(account.ScopeSpaceAdmin), spaceID.String(), PermissionManageIterations)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = Authorize(ctxWithScopes(account.ScopeReadOnly), spaceID.String())
+		require.NoError(t, err)
+		assert.False(t, ok, "read-only tokens must not act as collaborators")
+	})
+
+	t.Run("missing service", func(t *testing.T) {
+		_, err := AuthorizePermission(context.Background(), spaceID.String(), PermissionEditWorkItem)
+		require.Error(t, err)
+	})
+}
+
+// collaboratorService is a space authorization service which only tells
+// collaborators apart
+type collaboratorService struct {
+	collaborator bool
+}
+
+func (s *collaboratorService) Authorize(ctx context.Context, spaceID string) (bool, error) {
+	return s.collaborator, nil
+}
+
+func (s *collaboratorService) Configuration() auth.ServiceConfiguration {
+	return nil
+}
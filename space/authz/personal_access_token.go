@@ -0,0 +1,106 @@
+package authz
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/models"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DefaultLocalRoleMaxAge is the time for which the roles recorded from the
+// Auth service are used to resolve the roles locally
+const DefaultLocalRoleMaxAge = 24 * time.Hour
+
+// LocalRoleResolver knows the roles of identities which can't be looked up in
+// the Auth service. Requests made with a personal access token don't carry a
+// token signed by the Auth service and service accounts are unknown to it, so
+// their roles are resolved locally.
+type LocalRoleResolver interface {
+	// Roles returns the names of the roles of the identity in the space
+	Roles(ctx context.Context, identityID uuid.UUID, spaceID string) ([]string, error)
+}
+
+// RoleRecorder keeps the roles of the space collaborators which the Auth
+// service reported so that they can be resolved locally later on
+type RoleRecorder interface {
+	// Record replaces the recorded roles of the space
+	Record(ctx context.Context, spaceID string, roles []Role) error
+}
+
+// NewLocalRoleResolver creates a LocalRoleResolver backed by the database
+func NewLocalRoleResolver(db *gorm.DB) *GormLocalRoleResolver {
+	return &GormLocalRoleResolver{db: db, MaxAge: DefaultLocalRoleMaxAge}
+}
+
+// Ensure GormLocalRoleResolver implements the LocalRoleResolver and
+// RoleRecorder interfaces
+var _ LocalRoleResolver = &GormLocalRoleResolver{}
+var _ RoleRecorder = &GormLocalRoleResolver{}
+
+// GormLocalRoleResolver grants the admin role to the owner of a space. The
+// collaborators of the space get the roles which the Auth service reported
+// for them the last time the roles of the space were looked up with a token
+// signed by the Auth service. Service accounts have the roles of their owner.
+// The scopes of the personal access token still limit what the identity can
+// do.
+type GormLocalRoleResolver struct {
+	db *gorm.DB
+	// MaxAge is the time after which recorded roles are no longer used
+	MaxAge time.Duration
+}
+
+// Roles returns the names of the roles of the identity in the space
+func (r *GormLocalRoleResolver) Roles(ctx context.Context, identityID uuid.UUID, spaceID string) ([]string, error) {
+	var rows []struct {
+		OwnerID               uuid.UUID
+		ServiceAccountOwnerID id.NullUUID
+	}
+	err := r.db.Raw(`SELECT s.owner_id, i.service_account_owner_id
+		FROM spaces s, identities i
+		WHERE s.id = ? AND i.id = ? AND s.deleted_at IS NULL AND i.deleted_at IS NULL`, spaceID, identityID).Scan(&rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if len(rows) == 0 {
+		return []string{}, nil
+	}
+	row := rows[0]
+	// a service account acts on behalf of its owner
+	assigneeID := identityID
+	if row.ServiceAccountOwnerID.Valid {
+		assigneeID = row.ServiceAccountOwnerID.UUID
+	}
+	if uuid.Equal(row.OwnerID, assigneeID) {
+		return []string{RoleAdmin}, nil
+	}
+	roles := []string{}
+	err = r.db.Table("space_roles").
+		Where("space_id = ? AND identity_id = ? AND recorded_at > ?", spaceID, assigneeID, time.Now().Add(-r.MaxAge)).
+		Order("role_name").
+		Pluck("role_name", &roles).Error
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return roles, nil
+}
+
+// Record replaces the recorded roles of the space with the given roles
+func (r *GormLocalRoleResolver) Record(ctx context.Context, spaceID string, roles []Role) error {
+	return models.Transactional(r.db, func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM space_roles WHERE space_id = ?", spaceID).Error; err != nil {
+			return errors.NewInternalError(ctx, err)
+		}
+		for _, role := range roles {
+			err := tx.Exec(`INSERT INTO space_roles (space_id, identity_id, role_name, recorded_at)
+				VALUES (?, ?, ?, now()) ON CONFLICT DO NOTHING`, spaceID, role.AssigneeID, role.RoleName).Error
+			if err != nil {
+				return errors.NewInternalError(ctx, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,80 @@
+package authz_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/account"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space/authz"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type localRoleResolverSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestLocalRoleResolver(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &localRoleResolverSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *localRoleResolverSuite) TestRoles() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Identities(3), tf.Spaces(1))
+	owner, collaborator, other := fxt.Identities[0], fxt.Identities[1], fxt.Identities[2]
+	ownerBot := account.NewServiceAccount("owner-bot", owner.ID)
+	collaboratorBot := account.NewServiceAccount("collaborator-bot", collaborator.ID)
+	otherBot := account.NewServiceAccount("other-bot", other.ID)
+	identities := account.NewIdentityRepository(s.DB)
+	require.NoError(s.T(), identities.Create(s.Ctx, &ownerBot))
+	require.NoError(s.T(), identities.Create(s.Ctx, &collaboratorBot))
+	require.NoError(s.T(), identities.Create(s.Ctx, &otherBot))
+	resolver := authz.NewLocalRoleResolver(s.DB)
+	spaceID := fxt.Spaces[0].ID.String()
+	// the roles of the space as reported by the Auth service
+	require.NoError(s.T(), resolver.Record(s.Ctx, spaceID, []authz.Role{
+		{RoleName: authz.RoleAdmin, AssigneeID: owner.ID.String()},
+		{RoleName: authz.RoleContributor, AssigneeID: collaborator.ID.String()},
+	}))
+
+	for name, tc := range map[string]struct {
+		identity account.Identity
+		expected []string
+	}{
+		"space owner":                      {*owner, []string{authz.RoleAdmin}},
+		"service account of space owner":   {ownerBot, []string{authz.RoleAdmin}},
+		"collaborator":                     {*collaborator, []string{authz.RoleContributor}},
+		"service account of collaborator":  {collaboratorBot, []string{authz.RoleContributor}},
+		"other user":                       {*other, []string{}},
+		"service account of another owner": {otherBot, []string{}},
+	} {
+		s.T().Run(name, func(t *testing.T) {
+			roles, err := resolver.Roles(s.Ctx, tc.identity.ID, spaceID)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, roles)
+		})
+	}
+
+	s.T().Run("recording replaces the roles of the space", func(t *testing.T) {
+		require.NoError(t, resolver.Record(s.Ctx, spaceID, []authz.Role{
+			{RoleName: authz.RoleAdmin, AssigneeID: owner.ID.String()},
+		}))
+		roles, err := resolver.Roles(s.Ctx, collaborator.ID, spaceID)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+
+	s.T().Run("outdated roles are not used", func(t *testing.T) {
+		require.NoError(t, resolver.Record(s.Ctx, spaceID, []authz.Role{
+			{RoleName: authz.RoleContributor, AssigneeID: collaborator.ID.String()},
+		}))
+		outdated := authz.NewLocalRoleResolver(s.DB)
+		outdated.MaxAge = 0
+		roles, err := outdated.Roles(s.Ctx, collaborator.ID, spaceID)
+		require.NoError(t, err)
+		assert.Empty(t, roles)
+	})
+}
@@ -0,0 +1,146 @@
+package importer
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Load returns the space template with the given ID together with all its
+// artifacts as they are stored in the system.
+func (r *GormRepository) Load(ctx context.Context, templateID uuid.UUID) (*ImportHelper, error) {
+	templ, err := spacetemplate.NewRepository(r.db).Load(ctx, templateID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	res := ImportHelper{Template: *templ}
+	wits, err := workitem.NewWorkItemTypeRepository(r.db).List(ctx, templateID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item types of space template %s", templateID)
+	}
+	for i := range wits {
+		wit := wits[i]
+		wit.Extends = wit.ExtendedTypeID()
+		res.WITs = append(res.WITs, &wit)
+	}
+	// list the link types of the template itself, not the ones of the base
+	// template
+	wilts, err := link.NewWorkItemLinkTypeRepository(r.db).List(ctx, templateID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item link types of space template %s", templateID)
+	}
+	for i := range wilts {
+		if wilts[i].SpaceTemplateID == templateID {
+			wilt := wilts[i]
+			res.WILTs = append(res.WILTs, &wilt)
+		}
+	}
+	res.WITGs, err = workitem.NewWorkItemTypeGroupRepository(r.db).List(ctx, templateID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item type groups of space template %s", templateID)
+	}
+	res.WIBs, err = workitem.NewBoardRepository(r.db).List(ctx, templateID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "failed to load work item boards of space template %s", templateID)
+	}
+	return &res, nil
+}
+
+// Clone copies the space template with the given ID and all its artifacts
+// into a new space template with the name, description and space of the given
+// template. All artifacts of the clone get new IDs; the returned map tells the
+// new ID of each artifact by its old one. The clone can't construct spaces.
+func (r *GormRepository) Clone(ctx context.Context, templateID uuid.UUID, clone spacetemplate.SpaceTemplate) (*ImportHelper, map[uuid.UUID]uuid.UUID, error) {
+	s, err := r.Load(ctx, templateID)
+	if err != nil {
+		return nil, nil, errs.WithStack(err)
+	}
+	ids := map[uuid.UUID]uuid.UUID{}
+	newID := func(oldID uuid.UUID) uuid.UUID {
+		if _, ok := ids[oldID]; !ok {
+			ids[oldID] = uuid.NewV4()
+		}
+		return ids[oldID]
+	}
+	// known returns the new ID of an artifact of the template or the given ID
+	// if it refers to something outside of it, e.g. a type of the base template
+	known := func(oldID uuid.UUID) uuid.UUID {
+		if id, ok := ids[oldID]; ok {
+			return id
+		}
+		return oldID
+	}
+
+	s.Template.Name = clone.Name
+	s.Template.Description = clone.Description
+	s.Template.SpaceID = clone.SpaceID
+	s.Template.CanConstruct = false
+	s.Template.Version = 0
+	s.Template.Lifecycle = gormsupport.Lifecycle{}
+	s.Template.ID = clone.ID
+	if s.Template.ID == uuid.Nil {
+		s.Template.ID = uuid.NewV4()
+	}
+	ids[templateID] = s.Template.ID
+
+	for _, wit := range s.WITs {
+		newID(wit.ID)
+	}
+	// a type must be created after the type it extends
+	sort.SliceStable(s.WITs, func(i, j int) bool {
+		return strings.Count(s.WITs[i].Path, workitem.GetTypePathSeparator()) < strings.Count(s.WITs[j].Path, workitem.GetTypePathSeparator())
+	})
+	for _, wit := range s.WITs {
+		wit.ID = ids[wit.ID]
+		wit.Extends = known(wit.Extends)
+		wit.Version = 0
+		wit.Path = ""
+		wit.Lifecycle = gormsupport.Lifecycle{}
+		for i, childID := range wit.ChildTypeIDs {
+			wit.ChildTypeIDs[i] = known(childID)
+		}
+	}
+	for _, wilt := range s.WILTs {
+		wilt.ID = newID(wilt.ID)
+		wilt.Version = 0
+		wilt.Lifecycle = gormsupport.Lifecycle{}
+	}
+	for _, group := range s.WITGs {
+		group.ID = newID(group.ID)
+		group.Lifecycle = gormsupport.Lifecycle{}
+		for i, typeID := range group.TypeList {
+			group.TypeList[i] = known(typeID)
+		}
+	}
+	for _, board := range s.WIBs {
+		board.ID = newID(board.ID)
+		board.Lifecycle = gormsupport.Lifecycle{}
+		if contextID, err := uuid.FromString(board.Context); err == nil {
+			board.Context = known(contextID).String()
+		}
+		for i := range board.Columns {
+			board.Columns[i].ID = newID(board.Columns[i].ID)
+			board.Columns[i].BoardID = board.ID
+			board.Columns[i].Lifecycle = gormsupport.Lifecycle{}
+		}
+	}
+	s.SetID(s.Template.ID)
+
+	res, err := r.Import(ctx, *s)
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_template_id": templateID,
+			"err":               err,
+		}, "failed to clone space template")
+		return nil, nil, errs.Wrapf(err, "failed to clone space template %s", templateID)
+	}
+	return res, ids, nil
+}
@@ -34,6 +34,17 @@ func (s *ImportHelper) Validate() error {
 		if wit.SpaceTemplateID != s.Template.ID {
 			return errors.NewBadParameterError("work item types's space template ID", wit.SpaceTemplateID.String()).Expected(s.Template.ID.String())
 		}
+		if err := wit.Transitions.Validate(); err != nil {
+			return errs.Wrapf(err, "invalid transitions of work item type \"%s\"", wit.Name)
+		}
+		for name, def := range wit.Fields {
+			if !def.IsComputed() {
+				continue
+			}
+			if err := def.Validate(name); err != nil {
+				return errs.Wrapf(err, "invalid computed field of work item type \"%s\"", wit.Name)
+			}
+		}
 	}
 	for _, wilt := range s.WILTs {
 		if wilt.SpaceTemplateID != s.Template.ID {
@@ -49,6 +60,9 @@ func (s *ImportHelper) Validate() error {
 		if wibs.SpaceTemplateID != s.Template.ID {
 			return errors.NewBadParameterError("work item board's space template ID", wibs.SpaceTemplateID.String()).Expected(s.Template.ID.String())
 		}
+		if err := wibs.Validate(); err != nil {
+			return errs.WithStack(err)
+		}
 	}
 
 	return nil
@@ -243,6 +243,17 @@ func Test_ImportHelper_Validate(t *testing.T) {
 			// then
 			require.Error(t, templ.Validate())
 		})
+
+		t.Run("invalid transition on WIT", func(t *testing.T) {
+			t.Parallel()
+			// given: valid empty template
+			spaceTemplateID := uuid.NewV4()
+			templ := getValidTestTemplateParsed(t, spaceTemplateID, uuid.NewV4(), uuid.NewV4(), uuid.NewV4(), uuid.NewV4())
+			// when
+			templ.WITs[0].Transitions = workitem.Transitions{{From: "new", To: workitem.TransitionFromAnyState}}
+			// then
+			require.Error(t, templ.Validate())
+		})
 	})
 }
 
@@ -334,13 +345,13 @@ work_item_boards:
       name: "New"
       order: 0
       trans_rule_key: "updateStateFromColumnMove"
-      trans_rule_argument: "{ 'metastate': 'mNew' }"
+      trans_rule_argument: '{ "metaState": "mNew" }'
     - id: "` + colID2 + `"
       board_id: "` + wibID.String() + `"
       name: "Done"
       order: 1
       trans_rule_key: "updateStateFromColumnMove"
-      trans_rule_argument: "{ 'metastate': 'mDone' }"
+      trans_rule_argument: '{ "metaState": "mDone" }'
 `
 }
 
@@ -445,7 +456,7 @@ func getValidTestTemplateParsed(t *testing.T, spaceTemplateID, witID, wiltID uui
 						Name:              "New",
 						Order:             0,
 						TransRuleKey:      "updateStateFromColumnMove",
-						TransRuleArgument: "{ 'metastate': 'mNew' }",
+						TransRuleArgument: `{ "metaState": "mNew" }`,
 						BoardID:           wibID,
 					},
 					{
@@ -453,7 +464,7 @@ func getValidTestTemplateParsed(t *testing.T, spaceTemplateID, witID, wiltID uui
 						Name:              "Done",
 						Order:             1,
 						TransRuleKey:      "updateStateFromColumnMove",
-						TransRuleArgument: "{ 'metastate': 'mDone' }",
+						TransRuleArgument: `{ "metaState": "mDone" }`,
 						BoardID:           wibID,
 					},
 				},
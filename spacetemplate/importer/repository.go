@@ -22,6 +22,11 @@ type Repository interface {
 	// template or a work item exists, we will update its description, label,
 	// icon, title. We don't touch the work item type fields or IDs of any kind.
 	Import(ctx context.Context, template ImportHelper) (*ImportHelper, error)
+	// Load returns a space template together with all its artifacts.
+	Load(ctx context.Context, templateID uuid.UUID) (*ImportHelper, error)
+	// Clone copies a space template and all its artifacts into a new space
+	// template and returns the new IDs of the artifacts by their old ones.
+	Clone(ctx context.Context, templateID uuid.UUID, clone spacetemplate.SpaceTemplate) (*ImportHelper, map[uuid.UUID]uuid.UUID, error)
 }
 
 // NewRepository creates a new importer repository
@@ -195,6 +200,10 @@ func (r *GormRepository) createOrUpdateWITs(ctx context.Context, s *ImportHelper
 			for name, field := range wit.Fields {
 				loadedWIT.Fields[name] = field
 			}
+			loadedWIT.Transitions = wit.Transitions
+			if err := loadedWIT.ValidateTransitions(); err != nil {
+				return errs.Wrapf(err, "invalid transitions of work item type %s", wit.ID)
+			}
 			db := r.db.Save(&loadedWIT)
 			if err := db.Error; err != nil {
 				return errs.Wrapf(err, "failed to update work item type %s", wit.ID)
@@ -14,6 +14,7 @@ import (
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/sergi/go-diff/diffmatchpatch"
 	"github.com/stretchr/testify/assert"
@@ -424,3 +425,81 @@ func (s *repoSuite) TestRepository_List() {
 		require.Len(t, spaceTemplatesToBeFound, 0, "these space templates where not found", spaceTemplatesToBeFound)
 	})
 }
+
+func (s *repoSuite) TestClone() {
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.Spaces(1),
+		tf.WorkItemTypes(2),
+		tf.WorkItemLinkTypes(1),
+		tf.WorkItemTypeGroups(1),
+		tf.WorkItemBoards(1),
+	)
+	templ := *fxt.SpaceTemplates[0]
+
+	s.T().Run("ok", func(t *testing.T) {
+		// when
+		clone, ids, err := s.importerRepo.Clone(s.Ctx, templ.ID, spacetemplate.SpaceTemplate{
+			Name:    testsupport.CreateRandomValidTestName("clone "),
+			SpaceID: id.NullUUID{UUID: fxt.Spaces[0].ID, Valid: true},
+		})
+		// then
+		require.NoError(t, err)
+		require.NotEqual(t, templ.ID, clone.Template.ID)
+		require.Equal(t, clone.Template.ID, ids[templ.ID])
+		loaded, err := s.spaceTemplateRepo.Load(s.Ctx, clone.Template.ID)
+		require.NoError(t, err)
+		require.True(t, loaded.SpaceID.Valid)
+		require.Equal(t, fxt.Spaces[0].ID, loaded.SpaceID.UUID)
+		require.False(t, loaded.CanConstruct)
+
+		wits, err := s.witRepo.List(s.Ctx, clone.Template.ID)
+		require.NoError(t, err)
+		require.Len(t, wits, len(fxt.WorkItemTypes))
+		for _, wit := range fxt.WorkItemTypes {
+			cloneID, ok := ids[wit.ID]
+			require.True(t, ok, "no clone of work item type %s", wit.ID)
+			cloned, err := s.witRepo.Load(s.Ctx, cloneID)
+			require.NoError(t, err)
+			require.Equal(t, wit.Name, cloned.Name)
+			require.Equal(t, clone.Template.ID, cloned.SpaceTemplateID)
+			// the clone still extends the planner item of the base template
+			require.Contains(t, cloned.Path, workitem.LtreeSafeID(workitem.SystemPlannerItem))
+			require.Equal(t, len(wit.Fields), len(cloned.Fields))
+		}
+
+		wilt, err := s.wiltRepo.Load(s.Ctx, ids[fxt.WorkItemLinkTypes[0].ID])
+		require.NoError(t, err)
+		require.Equal(t, fxt.WorkItemLinkTypes[0].Name, wilt.Name)
+		require.Equal(t, clone.Template.ID, wilt.SpaceTemplateID)
+
+		group, err := s.witgRepo.Load(s.Ctx, ids[fxt.WorkItemTypeGroups[0].ID])
+		require.NoError(t, err)
+		require.Equal(t, []uuid.UUID{ids[fxt.WorkItemTypes[0].ID]}, group.TypeList)
+
+		board, err := s.wibRepo.Load(s.Ctx, ids[fxt.WorkItemBoards[0].ID])
+		require.NoError(t, err)
+		require.Equal(t, group.ID.String(), board.Context)
+		require.Len(t, board.Columns, len(fxt.WorkItemBoards[0].Columns))
+
+		// the original is left untouched
+		board, err = s.wibRepo.Load(s.Ctx, fxt.WorkItemBoards[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, templ.ID, board.SpaceTemplateID)
+	})
+
+	s.T().Run("space owned templates are not listed", func(t *testing.T) {
+		templates, err := s.spaceTemplateRepo.List(s.Ctx)
+		require.NoError(t, err)
+		for _, st := range templates {
+			require.False(t, st.SpaceID.Valid, "space template %s belongs to a space", st.ID)
+		}
+	})
+
+	s.T().Run("unknown template", func(t *testing.T) {
+		_, _, err := s.importerRepo.Clone(s.Ctx, uuid.NewV4(), spacetemplate.SpaceTemplate{
+			Name: testsupport.CreateRandomValidTestName("clone "),
+		})
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
@@ -18,7 +18,8 @@ type Repository interface {
 	// Create creates a new space template and all the artifacts (e.g. work item
 	// types, work item link types) in the system.
 	Create(ctx context.Context, template SpaceTemplate) (*SpaceTemplate, error)
-	// List returns an array with all space templates in it
+	// List returns an array with all space templates in it except for the
+	// ones cloned for a single space
 	List(ctx context.Context) ([]SpaceTemplate, error)
 	// Load returns a single space template by a given ID
 	Load(ctx context.Context, templateID uuid.UUID) (*SpaceTemplate, error)
@@ -40,10 +41,11 @@ func (r *GormRepository) CheckExists(ctx context.Context, id uuid.UUID) error {
 	return repository.CheckExists(ctx, r.db, SpaceTemplate{}.TableName(), id)
 }
 
-// List returns an array with all space templates in it
+// List returns an array with all space templates in it except for the ones
+// cloned for a single space
 func (r *GormRepository) List(ctx context.Context) ([]SpaceTemplate, error) {
 	var objs []SpaceTemplate
-	err := r.db.Find(&objs).Error
+	err := r.db.Where("space_id IS NULL").Find(&objs).Error
 	if err != nil && err != gorm.ErrRecordNotFound {
 		return nil, errs.Wrap(err, "failed to list space templates")
 	}
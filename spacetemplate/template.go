@@ -6,6 +6,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/convert"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
 	uuid "github.com/satori/go.uuid"
 )
 
@@ -28,6 +29,9 @@ type SpaceTemplate struct {
 	Name                  string    `json:"name"`
 	Description           *string   `json:"description,omitempty"`
 	CanConstruct          bool      `gorm:"can_construct" json:"can_construct"`
+	// SpaceID is set for a template which was cloned for a single space. Such a
+	// template can only be used by that space.
+	SpaceID id.NullUUID `sql:"type:uuid" json:"-"`
 }
 
 // Validate ensures that all inner-document references of the given space
@@ -0,0 +1,597 @@
+// Package templatemigration moves a space from its space template to another
+// one, e.g. to a clone of the template which is owned by the space or to a
+// newer version of the template whose work item types gained fields.
+//
+// A migration is first planned: the work item types, type groups and boards of
+// both templates are compared and every work item whose type or field values
+// change is listed. Field values which don't fit the new types can be mapped
+// to new values. A plan without conflicts can then be applied.
+package templatemigration
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Options tell how to migrate a space to another space template
+type Options struct {
+	// TargetTemplateID is the ID of the space template to migrate to
+	TargetTemplateID uuid.UUID
+	// TypeMapping maps work item types of the current template to the ones of
+	// the target template. Types which aren't mapped are matched by ID and
+	// then by name.
+	TypeMapping map[uuid.UUID]uuid.UUID
+	// LinkTypeMapping maps work item link types of the current template to the
+	// ones of the target template. Link types which aren't mapped are matched
+	// by name.
+	LinkTypeMapping map[uuid.UUID]uuid.UUID
+	// FieldMappings replace field values which don't fit the target template
+	FieldMappings []FieldMapping
+}
+
+// FieldMapping replaces the values of a field of the work items of a space
+// when the space is migrated
+type FieldMapping struct {
+	// TypeID limits the mapping to work items of this type of the current
+	// template. The mapping applies to all work items if it is not set.
+	TypeID uuid.UUID
+	// Field is the name of the field
+	Field string
+	// Values maps the current values, formatted as strings, to the new ones.
+	// The elements of list values are mapped one by one.
+	Values map[string]interface{}
+}
+
+// Plan describes what changes when a space is migrated to another template
+type Plan struct {
+	SpaceID          uuid.UUID
+	SourceTemplateID uuid.UUID
+	TargetTemplateID uuid.UUID
+	Types            []TypeChange
+	LinkTypes        []LinkTypeChange
+	TypeGroups       NameDiff
+	Boards           NameDiff
+	// WorkItems lists the work items whose type or field values change
+	WorkItems []WorkItemChange
+	// Conflicts tell why the space can't be migrated as planned. A plan with
+	// conflicts can't be applied.
+	Conflicts []string
+}
+
+// TypeChange describes how a work item type of the current template maps to
+// the target template
+type TypeChange struct {
+	SourceTypeID   uuid.UUID
+	SourceTypeName string
+	// TargetTypeID is nil if the target template has no matching type
+	TargetTypeID   uuid.UUID
+	TargetTypeName string
+	AddedFields    []string
+	RemovedFields  []string
+	ChangedFields  []string
+	// WorkItems is the number of work items of the type in the space
+	WorkItems int
+}
+
+// LinkTypeChange describes how a work item link type of the current template
+// maps to the target template
+type LinkTypeChange struct {
+	SourceLinkTypeID   uuid.UUID
+	SourceLinkTypeName string
+	// TargetLinkTypeID is nil if the target template has no matching type
+	TargetLinkTypeID uuid.UUID
+	// Links is the number of links of the type in the space
+	Links int
+}
+
+// NameDiff lists the names of artifacts which the target template adds,
+// removes or keeps compared to the current template
+type NameDiff struct {
+	Added   []string
+	Removed []string
+	Kept    []string
+}
+
+// WorkItemChange describes how a work item changes when the space is
+// migrated
+type WorkItemChange struct {
+	ID           uuid.UUID
+	Number       int
+	Title        string
+	SourceTypeID uuid.UUID
+	TargetTypeID uuid.UUID
+	Fields       []FieldChange
+	// newFields holds all the field values after the migration
+	newFields workitem.Fields
+}
+
+// FieldChange is the change of a single field value
+type FieldChange struct {
+	Field string
+	From  interface{}
+	To    interface{}
+}
+
+// Repository plans and applies migrations of spaces to other space templates
+type Repository interface {
+	// Plan returns what changes when the space is migrated
+	Plan(ctx context.Context, spaceID uuid.UUID, opts Options) (*Plan, error)
+	// Apply migrates the space unless its plan has conflicts
+	Apply(ctx context.Context, spaceID uuid.UUID, modifierID uuid.UUID, opts Options) (*Plan, error)
+}
+
+// NewRepository creates a new template migration repository
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the template migration repository
+// using gorm
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Plan returns what changes when the space is migrated
+func (r *GormRepository) Plan(ctx context.Context, spaceID uuid.UUID, opts Options) (*Plan, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "templatemigration", "plan"}, time.Now())
+	s, err := space.NewRepository(r.db).Load(ctx, spaceID)
+	if err != nil {
+		return nil, err
+	}
+	target, err := spacetemplate.NewRepository(r.db).Load(ctx, opts.TargetTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	if target.SpaceID.Valid && target.SpaceID.UUID != spaceID {
+		return nil, errors.NewForbiddenError(fmt.Sprintf("space template %s belongs to another space", target.ID))
+	}
+	if !target.SpaceID.Valid && !target.CanConstruct && target.ID != s.SpaceTemplateID {
+		return nil, errors.NewBadParameterError("target template", target.ID).Expected("space template which can construct spaces")
+	}
+	p := planner{
+		r:    r,
+		opts: opts,
+		plan: &Plan{
+			SpaceID:          spaceID,
+			SourceTemplateID: s.SpaceTemplateID,
+			TargetTemplateID: target.ID,
+		},
+		sourceTypes: map[uuid.UUID]*workitem.WorkItemType{},
+		typeMap:     map[uuid.UUID]*workitem.WorkItemType{},
+		columnMap:   map[string]string{},
+	}
+	if err := p.planTypes(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.planTypeGroups(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.planBoards(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.planWorkItems(ctx); err != nil {
+		return nil, err
+	}
+	if err := p.planLinkTypes(ctx); err != nil {
+		return nil, err
+	}
+	return p.plan, nil
+}
+
+// Apply migrates the space unless its plan has conflicts. The work items get
+// their new types and field values and a revision by the given modifier.
+func (r *GormRepository) Apply(ctx context.Context, spaceID uuid.UUID, modifierID uuid.UUID, opts Options) (*Plan, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "templatemigration", "apply"}, time.Now())
+	plan, err := r.Plan(ctx, spaceID, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(plan.Conflicts) > 0 {
+		return plan, errors.NewDataConflictError(fmt.Sprintf("space %s can't be migrated to space template %s: %s", spaceID, plan.TargetTemplateID, strings.Join(plan.Conflicts, "; ")))
+	}
+	revisionRepo := workitem.NewRevisionRepository(r.db)
+	migrated := make([]uuid.UUID, 0, len(plan.WorkItems))
+	for _, change := range plan.WorkItems {
+		var wi workitem.WorkItemStorage
+		if err := r.db.Where("id = ?", change.ID).First(&wi).Error; err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(err, "failed to load work item %s", change.ID))
+		}
+		wi.Type = change.TargetTypeID
+		wi.Fields = change.newFields
+		wi.Version = wi.Version + 1
+		if err := r.db.Save(&wi).Error; err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(err, "failed to migrate work item %s", change.ID))
+		}
+		if err := revisionRepo.Create(ctx, modifierID, workitem.RevisionTypeUpdate, wi); err != nil {
+			return nil, errs.Wrapf(err, "failed to create revision of work item %s", change.ID)
+		}
+		migrated = append(migrated, change.ID)
+	}
+	// the computed fields of the new types derive their values anew
+	if err := workitem.NewWorkItemRepository(r.db).RecomputeFields(ctx, migrated...); err != nil {
+		return nil, errs.Wrap(err, "failed to compute the fields of the migrated work items")
+	}
+	for _, change := range plan.LinkTypes {
+		if change.TargetLinkTypeID == change.SourceLinkTypeID {
+			continue
+		}
+		db := r.db.Model(&link.WorkItemLink{}).
+			Where(fmt.Sprintf("link_type_id = ? AND source_id IN (SELECT id FROM %s WHERE space_id = ?)", workitem.WorkItemStorage{}.TableName()), change.SourceLinkTypeID, spaceID).
+			UpdateColumn("link_type_id", change.TargetLinkTypeID)
+		if db.Error != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to migrate links of type %s", change.SourceLinkTypeID))
+		}
+	}
+	db := r.db.Model(&space.Space{}).Where("id = ?", spaceID).Updates(map[string]interface{}{
+		"space_template_id": plan.TargetTemplateID,
+		"version":           gorm.Expr("version + 1"),
+	})
+	if db.Error != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to update the space template of space %s", spaceID))
+	}
+	log.Info(ctx, map[string]interface{}{
+		"space_id":           spaceID,
+		"source_template_id": plan.SourceTemplateID,
+		"target_template_id": plan.TargetTemplateID,
+		"work_items":         len(plan.WorkItems),
+	}, "space migrated to another space template")
+	return plan, nil
+}
+
+// planner holds the state while a plan is computed
+type planner struct {
+	r    *GormRepository
+	opts Options
+	plan *Plan
+	// sourceTypes holds the types of the current template and the types of
+	// the work items of the space by their IDs
+	sourceTypes map[uuid.UUID]*workitem.WorkItemType
+	// typeMap holds the target types by the IDs of the source types
+	typeMap map[uuid.UUID]*workitem.WorkItemType
+	// columnMap holds the IDs of the target board columns by the IDs of the
+	// source columns with the same board and column names
+	columnMap map[string]string
+}
+
+func (p *planner) conflict(format string, args ...interface{}) {
+	p.plan.Conflicts = append(p.plan.Conflicts, fmt.Sprintf(format, args...))
+}
+
+func (p *planner) planTypes(ctx context.Context) error {
+	witRepo := workitem.NewWorkItemTypeRepository(p.r.db)
+	sourceWITs, err := witRepo.List(ctx, p.plan.SourceTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to list the work item types of space template %s", p.plan.SourceTemplateID)
+	}
+	targetWITs, err := witRepo.List(ctx, p.plan.TargetTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to list the work item types of space template %s", p.plan.TargetTemplateID)
+	}
+	var sourceIDs []uuid.UUID
+	for i := range sourceWITs {
+		p.sourceTypes[sourceWITs[i].ID] = &sourceWITs[i]
+		sourceIDs = append(sourceIDs, sourceWITs[i].ID)
+	}
+	// work items may have types which don't belong to the current template
+	var usedTypes []struct {
+		Type  uuid.UUID `gorm:"column:type"`
+		Count int       `gorm:"column:count"`
+	}
+	db := p.r.db.Model(&workitem.WorkItemStorage{}).Select("type, count(*) AS count").Where("space_id = ?", p.plan.SpaceID).Group("type").Scan(&usedTypes)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to count the work items per type"))
+	}
+	counts := map[uuid.UUID]int{}
+	for _, used := range usedTypes {
+		counts[used.Type] = used.Count
+		if _, ok := p.sourceTypes[used.Type]; !ok {
+			wit, err := witRepo.Load(ctx, used.Type)
+			if err != nil {
+				return errs.Wrapf(err, "failed to load work item type %s", used.Type)
+			}
+			p.sourceTypes[wit.ID] = wit
+			sourceIDs = append(sourceIDs, wit.ID)
+		}
+	}
+	byID := map[uuid.UUID]*workitem.WorkItemType{}
+	byName := map[string]*workitem.WorkItemType{}
+	for i := range targetWITs {
+		byID[targetWITs[i].ID] = &targetWITs[i]
+		byName[targetWITs[i].Name] = &targetWITs[i]
+	}
+	for _, sourceID := range sourceIDs {
+		source := p.sourceTypes[sourceID]
+		change := TypeChange{
+			SourceTypeID:   source.ID,
+			SourceTypeName: source.Name,
+			WorkItems:      counts[source.ID],
+			AddedFields:    []string{},
+			RemovedFields:  []string{},
+			ChangedFields:  []string{},
+		}
+		var target *workitem.WorkItemType
+		if mappedID, ok := p.opts.TypeMapping[source.ID]; ok {
+			if target, ok = byID[mappedID]; !ok {
+				p.conflict("work item type %q is mapped to %s which is no type of the target template", source.Name, mappedID)
+			}
+		} else if t, ok := byID[source.ID]; ok {
+			target = t
+		} else if t, ok := byName[source.Name]; ok {
+			target = t
+		}
+		if target == nil {
+			if change.WorkItems > 0 {
+				p.conflict("the target template has no work item type for the %d work items of type %q", change.WorkItems, source.Name)
+			}
+			p.plan.Types = append(p.plan.Types, change)
+			continue
+		}
+		p.typeMap[source.ID] = target
+		change.TargetTypeID = target.ID
+		change.TargetTypeName = target.Name
+		for name, def := range target.Fields {
+			sourceDef, ok := source.Fields[name]
+			if !ok {
+				change.AddedFields = append(change.AddedFields, name)
+			} else if !sourceDef.Type.Equal(def.Type) {
+				change.ChangedFields = append(change.ChangedFields, name)
+			}
+		}
+		for name := range source.Fields {
+			if _, ok := target.Fields[name]; !ok {
+				change.RemovedFields = append(change.RemovedFields, name)
+			}
+		}
+		sort.Strings(change.AddedFields)
+		sort.Strings(change.ChangedFields)
+		sort.Strings(change.RemovedFields)
+		p.plan.Types = append(p.plan.Types, change)
+	}
+	return nil
+}
+
+func (p *planner) planTypeGroups(ctx context.Context) error {
+	repo := workitem.NewWorkItemTypeGroupRepository(p.r.db)
+	names := func(templateID uuid.UUID) ([]string, error) {
+		groups, err := repo.List(ctx, templateID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to list the work item type groups of space template %s", templateID)
+		}
+		res := make([]string, len(groups))
+		for i, group := range groups {
+			res[i] = group.Name
+		}
+		return res, nil
+	}
+	source, err := names(p.plan.SourceTemplateID)
+	if err != nil {
+		return err
+	}
+	target, err := names(p.plan.TargetTemplateID)
+	if err != nil {
+		return err
+	}
+	p.plan.TypeGroups = diffNames(source, target)
+	return nil
+}
+
+func (p *planner) planBoards(ctx context.Context) error {
+	repo := workitem.NewBoardRepository(p.r.db)
+	sourceBoards, err := repo.List(ctx, p.plan.SourceTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to list the boards of space template %s", p.plan.SourceTemplateID)
+	}
+	targetBoards, err := repo.List(ctx, p.plan.TargetTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to list the boards of space template %s", p.plan.TargetTemplateID)
+	}
+	targetColumns := map[string]string{}
+	var source, target []string
+	for _, board := range targetBoards {
+		target = append(target, board.Name)
+		for _, column := range board.Columns {
+			targetColumns[board.Name+"/"+column.Name] = column.ID.String()
+		}
+	}
+	for _, board := range sourceBoards {
+		source = append(source, board.Name)
+		for _, column := range board.Columns {
+			if id, ok := targetColumns[board.Name+"/"+column.Name]; ok {
+				p.columnMap[column.ID.String()] = id
+			}
+		}
+	}
+	p.plan.Boards = diffNames(source, target)
+	return nil
+}
+
+func (p *planner) planWorkItems(ctx context.Context) error {
+	var workItems []workitem.WorkItemStorage
+	if err := p.r.db.Where("space_id = ?", p.plan.SpaceID).Order("number").Find(&workItems).Error; err != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(err, "failed to load the work items of the space"))
+	}
+	for _, wi := range workItems {
+		source := p.sourceTypes[wi.Type]
+		target, ok := p.typeMap[wi.Type]
+		if source == nil || !ok {
+			// already reported as conflict of the type
+			continue
+		}
+		change := WorkItemChange{
+			ID:           wi.ID,
+			Number:       wi.Number,
+			SourceTypeID: source.ID,
+			TargetTypeID: target.ID,
+			newFields:    workitem.Fields{},
+		}
+		if title, ok := wi.Fields[workitem.SystemTitle].(string); ok {
+			change.Title = title
+		}
+		for name, def := range target.Fields {
+			value, mapped := p.mapValue(source.ID, name, wi.Fields[name])
+			sourceDef, known := source.Fields[name]
+			if !def.ReadOnly && (mapped || !known || !sourceDef.Type.Equal(def.Type) || (def.Required && value == nil)) {
+				converted, err := def.ConvertToModel(name, value)
+				if err != nil {
+					p.conflict("the value %v of field %q of work item %d doesn't fit work item type %q: %s", value, name, wi.Number, target.Name, err)
+					continue
+				}
+				value = converted
+			}
+			if value != nil {
+				change.newFields[name] = value
+			}
+		}
+		names := map[string]struct{}{}
+		for name := range wi.Fields {
+			names[name] = struct{}{}
+		}
+		for name := range change.newFields {
+			names[name] = struct{}{}
+		}
+		for name := range names {
+			from, to := wi.Fields[name], change.newFields[name]
+			if !reflect.DeepEqual(from, to) {
+				change.Fields = append(change.Fields, FieldChange{Field: name, From: from, To: to})
+			}
+		}
+		if len(change.Fields) == 0 && source.ID == target.ID {
+			continue
+		}
+		sort.Slice(change.Fields, func(i, j int) bool { return change.Fields[i].Field < change.Fields[j].Field })
+		p.plan.WorkItems = append(p.plan.WorkItems, change)
+	}
+	return nil
+}
+
+// mapValue applies the field mappings and moves board columns to the columns
+// of the target template with the same names. It returns true if the value
+// was changed.
+func (p *planner) mapValue(typeID uuid.UUID, field string, value interface{}) (interface{}, bool) {
+	if value == nil {
+		return nil, false
+	}
+	mapped := false
+	mapOne := func(v interface{}) interface{} {
+		for _, m := range p.opts.FieldMappings {
+			if m.Field != field || (m.TypeID != uuid.Nil && m.TypeID != typeID) {
+				continue
+			}
+			if newValue, ok := m.Values[fmt.Sprint(v)]; ok {
+				mapped = true
+				return newValue
+			}
+		}
+		if field == workitem.SystemBoardcolumns {
+			if id, ok := p.columnMap[fmt.Sprint(v)]; ok {
+				if id != fmt.Sprint(v) {
+					mapped = true
+				}
+				return id
+			}
+		}
+		return v
+	}
+	if values, ok := value.([]interface{}); ok {
+		res := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			res = append(res, mapOne(v))
+		}
+		return res, mapped
+	}
+	return mapOne(value), mapped
+}
+
+func (p *planner) planLinkTypes(ctx context.Context) error {
+	if p.plan.SourceTemplateID == p.plan.TargetTemplateID {
+		return nil
+	}
+	var used []struct {
+		LinkTypeID uuid.UUID `gorm:"column:link_type_id"`
+		Count      int       `gorm:"column:count"`
+	}
+	db := p.r.db.Model(&link.WorkItemLink{}).
+		Select("link_type_id, count(*) AS count").
+		Where(fmt.Sprintf("source_id IN (SELECT id FROM %s WHERE space_id = ?)", workitem.WorkItemStorage{}.TableName()), p.plan.SpaceID).
+		Group("link_type_id").
+		Scan(&used)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to count the links per type"))
+	}
+	repo := link.NewWorkItemLinkTypeRepository(p.r.db)
+	targetTypes, err := repo.List(ctx, p.plan.TargetTemplateID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to list the link types of space template %s", p.plan.TargetTemplateID)
+	}
+	byID := map[uuid.UUID]link.WorkItemLinkType{}
+	byName := map[string]link.WorkItemLinkType{}
+	for _, t := range targetTypes {
+		byID[t.ID] = t
+		byName[t.Name] = t
+	}
+	for _, u := range used {
+		source, err := repo.Load(ctx, u.LinkTypeID)
+		if err != nil {
+			return errs.Wrapf(err, "failed to load work item link type %s", u.LinkTypeID)
+		}
+		if source.SpaceTemplateID != p.plan.SourceTemplateID {
+			// e.g. the link types of the base template which every space can use
+			continue
+		}
+		change := LinkTypeChange{
+			SourceLinkTypeID:   source.ID,
+			SourceLinkTypeName: source.Name,
+			Links:              u.Count,
+		}
+		if mappedID, ok := p.opts.LinkTypeMapping[source.ID]; ok {
+			if _, ok := byID[mappedID]; ok {
+				change.TargetLinkTypeID = mappedID
+			}
+		} else if t, ok := byName[source.Name]; ok {
+			change.TargetLinkTypeID = t.ID
+		}
+		if change.TargetLinkTypeID == uuid.Nil {
+			p.conflict("the target template has no link type for the %d links of type %q", change.Links, source.Name)
+		}
+		p.plan.LinkTypes = append(p.plan.LinkTypes, change)
+	}
+	return nil
+}
+
+func diffNames(source, target []string) NameDiff {
+	res := NameDiff{Added: []string{}, Removed: []string{}, Kept: []string{}}
+	inSource := map[string]struct{}{}
+	for _, name := range source {
+		inSource[name] = struct{}{}
+	}
+	inTarget := map[string]struct{}{}
+	for _, name := range target {
+		inTarget[name] = struct{}{}
+		if _, ok := inSource[name]; ok {
+			res.Kept = append(res.Kept, name)
+		} else {
+			res.Added = append(res.Added, name)
+		}
+	}
+	for _, name := range source {
+		if _, ok := inTarget[name]; !ok {
+			res.Removed = append(res.Removed, name)
+		}
+	}
+	return res
+}
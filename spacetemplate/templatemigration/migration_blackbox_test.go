@@ -0,0 +1,173 @@
+package templatemigration_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/importer"
+	"github.com/fabric8-services/fabric8-wit/spacetemplate/templatemigration"
+	testsupport "github.com/fabric8-services/fabric8-wit/test"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type migrationSuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestMigration(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &migrationSuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+// cloneFor clones the template of the fixture into a template of the given
+// space
+func (s *migrationSuite) cloneFor(t *testing.T, fxt *tf.TestFixture, spaceID uuid.UUID) (*importer.ImportHelper, map[uuid.UUID]uuid.UUID) {
+	clone, ids, err := importer.NewRepository(s.DB).Clone(s.Ctx, fxt.SpaceTemplates[0].ID, spacetemplate.SpaceTemplate{
+		Name:    testsupport.CreateRandomValidTestName("clone "),
+		SpaceID: id.NullUUID{UUID: spaceID, Valid: true},
+	})
+	require.NoError(t, err)
+	return clone, ids
+}
+
+func (s *migrationSuite) TestPlan() {
+	repo := templatemigration.NewRepository(s.DB)
+
+	s.T().Run("clone matched by name", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(2), tf.WorkItemLinks(1))
+		clone, _ := s.cloneFor(t, fxt, fxt.Spaces[0].ID)
+		// when
+		plan, err := repo.Plan(s.Ctx, fxt.Spaces[0].ID, templatemigration.Options{
+			TargetTemplateID: clone.Template.ID,
+		})
+		// then
+		require.NoError(t, err)
+		require.Empty(t, plan.Conflicts)
+		require.Equal(t, fxt.SpaceTemplates[0].ID, plan.SourceTemplateID)
+		require.Equal(t, clone.Template.ID, plan.TargetTemplateID)
+		require.Len(t, plan.WorkItems, 2)
+		for _, wi := range plan.WorkItems {
+			require.Equal(t, fxt.WorkItemTypes[0].ID, wi.SourceTypeID)
+			require.NotEqual(t, fxt.WorkItemTypes[0].ID, wi.TargetTypeID)
+			require.Empty(t, wi.Fields)
+		}
+		require.Len(t, plan.LinkTypes, 1)
+		require.Equal(t, fxt.WorkItemLinkTypes[0].ID, plan.LinkTypes[0].SourceLinkTypeID)
+		require.NotEqual(t, uuid.Nil, plan.LinkTypes[0].TargetLinkTypeID)
+		require.Equal(t, 1, plan.LinkTypes[0].Links)
+		// nothing is changed by a plan
+		wi, err := workitem.NewWorkItemRepository(s.DB).LoadByID(s.Ctx, fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, fxt.WorkItemTypes[0].ID, wi.Type)
+	})
+
+	s.T().Run("template without matching types", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(1))
+		other := tf.NewTestFixture(t, s.DB, tf.SpaceTemplates(1))
+		// when
+		plan, err := repo.Plan(s.Ctx, fxt.Spaces[0].ID, templatemigration.Options{
+			TargetTemplateID: other.SpaceTemplates[0].ID,
+		})
+		// then
+		require.NoError(t, err)
+		require.Len(t, plan.Conflicts, 1)
+		require.Contains(t, plan.Conflicts[0], fxt.WorkItemTypes[0].Name)
+		require.Len(t, plan.Types, 1)
+		require.Equal(t, uuid.Nil, plan.Types[0].TargetTypeID)
+		require.Equal(t, 1, plan.Types[0].WorkItems)
+		require.Empty(t, plan.WorkItems)
+	})
+
+	s.T().Run("template of another space", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(2), tf.WorkItemTypes(1))
+		clone, _ := s.cloneFor(t, fxt, fxt.Spaces[1].ID)
+		// when
+		_, err := repo.Plan(s.Ctx, fxt.Spaces[0].ID, templatemigration.Options{
+			TargetTemplateID: clone.Template.ID,
+		})
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.ForbiddenError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown template", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Spaces(1))
+		// when
+		_, err := repo.Plan(s.Ctx, fxt.Spaces[0].ID, templatemigration.Options{
+			TargetTemplateID: uuid.NewV4(),
+		})
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *migrationSuite) TestApply() {
+	repo := templatemigration.NewRepository(s.DB)
+
+	s.T().Run("ok with field mapping", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(2), tf.WorkItemLinks(1))
+		clone, ids := s.cloneFor(t, fxt, fxt.Spaces[0].ID)
+		// when
+		plan, err := repo.Apply(s.Ctx, fxt.Spaces[0].ID, fxt.Identities[0].ID, templatemigration.Options{
+			TargetTemplateID: clone.Template.ID,
+			TypeMapping:      ids,
+			LinkTypeMapping:  ids,
+			FieldMappings: []templatemigration.FieldMapping{
+				{Field: workitem.SystemState, Values: map[string]interface{}{workitem.SystemStateNew: workitem.SystemStateOpen}},
+			},
+		})
+		// then
+		require.NoError(t, err)
+		require.Len(t, plan.WorkItems, 2)
+		for _, wi := range plan.WorkItems {
+			require.Equal(t, []templatemigration.FieldChange{
+				{Field: workitem.SystemState, From: workitem.SystemStateNew, To: workitem.SystemStateOpen},
+			}, wi.Fields)
+		}
+		for _, w := range fxt.WorkItems {
+			wi, err := workitem.NewWorkItemRepository(s.DB).LoadByID(s.Ctx, w.ID)
+			require.NoError(t, err)
+			require.Equal(t, ids[fxt.WorkItemTypes[0].ID], wi.Type)
+			require.Equal(t, workitem.SystemStateOpen, wi.Fields[workitem.SystemState])
+			require.Equal(t, w.Version+1, wi.Version)
+		}
+		sp, err := space.NewRepository(s.DB).Load(s.Ctx, fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, clone.Template.ID, sp.SpaceTemplateID)
+		l, err := link.NewWorkItemLinkRepository(s.DB).Load(s.Ctx, fxt.WorkItemLinks[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, ids[fxt.WorkItemLinkTypes[0].ID], l.LinkTypeID)
+	})
+
+	s.T().Run("invalid field value", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(1))
+		clone, ids := s.cloneFor(t, fxt, fxt.Spaces[0].ID)
+		// when
+		plan, err := repo.Apply(s.Ctx, fxt.Spaces[0].ID, fxt.Identities[0].ID, templatemigration.Options{
+			TargetTemplateID: clone.Template.ID,
+			TypeMapping:      ids,
+			FieldMappings: []templatemigration.FieldMapping{
+				{Field: workitem.SystemState, Values: map[string]interface{}{workitem.SystemStateNew: "unknown state"}},
+			},
+		})
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+		require.Len(t, plan.Conflicts, 1)
+		sp, err := space.NewRepository(s.DB).Load(s.Ctx, fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, fxt.SpaceTemplates[0].ID, sp.SpaceTemplateID)
+	})
+}
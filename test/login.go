@@ -104,3 +104,13 @@ func ServiceAsSpaceUser(serviceName string, u account.Identity, authzSrv authz.A
 	svc.Context = tokencontext.ContextWithSpaceAuthzService(svc.Context, &authz.AuthzServiceManagerWrapper{Service: authzSrv})
 	return svc
 }
+
+// ServiceAsPersonalAccessTokenUser creates a new service and fills the context
+// with the JWT that stands in for the given personal access token
+func ServiceAsPersonalAccessTokenUser(serviceName string, pat account.PersonalAccessToken) *goa.Service {
+	svc := goa.New(serviceName)
+	svc.Context = goajwt.WithJWT(svc.Context, pat.JWT())
+	svc.Context = tokencontext.ContextWithTokenManager(svc.Context, testtoken.TokenManager)
+	svc.Context = tokencontext.ContextWithSpaceAuthzService(svc.Context, &authz.AuthzServiceManagerWrapper{Service: &dummySpaceAuthzService{}})
+	return svc
+}
@@ -0,0 +1,85 @@
+package webhook
+
+import (
+	"context"
+	"net"
+	"net/url"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+)
+
+// privateNetworks are the address ranges of the network the service runs in,
+// i.e. the unspecified, loopback, link-local and private addresses. Webhooks
+// must not reach into them.
+var privateNetworks = parseNetworks(
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+)
+
+func parseNetworks(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		res[i] = n
+	}
+	return res
+}
+
+// isPrivateIP returns true if the given address belongs to one of the private
+// networks or is a multicast address
+func isPrivateIP(ip net.IP) bool {
+	if ip.IsMulticast() {
+		return true
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupPublicIPs resolves the given host. A BadParameterError is returned if
+// the host can't be resolved or if one of its addresses is private.
+func lookupPublicIPs(ctx context.Context, host string) ([]net.IP, error) {
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return nil, errors.NewBadParameterError("url", host).Expected("resolvable host")
+	}
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		if isPrivateIP(addr.IP) {
+			return nil, errors.NewBadParameterError("url", host).Expected("host with public addresses only")
+		}
+		ips[i] = addr.IP
+	}
+	return ips, nil
+}
+
+// CheckAddress resolves the host of the webhook URL and returns a
+// BadParameterError if it points to a loopback, link-local or private address
+// unless those are allowed, e.g. for development. As the addresses of a host
+// may change, the Sender checks them again for every delivery.
+func (m Webhook) CheckAddress(ctx context.Context, allowPrivate bool) error {
+	if allowPrivate {
+		return nil
+	}
+	u, err := url.Parse(m.URL)
+	if err != nil {
+		return errors.NewBadParameterError("url", m.URL).Expected("absolute http or https URL")
+	}
+	_, err = lookupPublicIPs(ctx, u.Hostname())
+	return err
+}
@@ -0,0 +1,201 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// minBackoff is the delay before the first retry of a failed delivery
+	minBackoff = 30 * time.Second
+	// maxBackoff caps the delay between two attempts of the same delivery
+	maxBackoff = 1 * time.Hour
+)
+
+// Delivery is an entry of the webhook outbox. It holds a payload that has to
+// be sent to a webhook and keeps track of the attempts made so far.
+type Delivery struct {
+	ID            uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	WebhookID     uuid.UUID `sql:"type:uuid"`
+	MessageID     uuid.UUID `sql:"type:uuid"`
+	EventType     string
+	Payload       string `sql:"type:jsonb"`
+	Attempts      int
+	NextAttemptAt time.Time
+	DeliveredAt   *time.Time
+	FailedAt      *time.Time
+	LastError     *string
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (d Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// Backoff returns the delay to wait before the next attempt after the given
+// number of failed attempts. The delay doubles with every attempt, starting
+// at 30 seconds and capped at one hour.
+func Backoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	d := minBackoff
+	for i := 1; i < attempts; i++ {
+		d *= 2
+		if d >= maxBackoff {
+			return maxBackoff
+		}
+	}
+	return d
+}
+
+// DeliveryRepository describes interactions with the webhook outbox
+type DeliveryRepository interface {
+	Enqueue(ctx context.Context, d *Delivery) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]Delivery, error)
+	Claim(ctx context.Context, ids []uuid.UUID, until time.Time) error
+	MarkDelivered(ctx context.Context, id uuid.UUID, at time.Time) error
+	MarkFailed(ctx context.Context, d *Delivery, cause error, at time.Time, maxAttempts int) error
+	List(ctx context.Context, webhookID uuid.UUID) ([]Delivery, error)
+}
+
+// NewDeliveryRepository creates a new storage type.
+func NewDeliveryRepository(db *gorm.DB) DeliveryRepository {
+	return &GormDeliveryRepository{db: db}
+}
+
+// GormDeliveryRepository is the implementation of the storage interface for
+// webhook deliveries.
+type GormDeliveryRepository struct {
+	db *gorm.DB
+}
+
+// Enqueue stores a new delivery which is due immediately. Enqueuing the same
+// message twice for the same webhook is a no-op.
+func (r *GormDeliveryRepository) Enqueue(ctx context.Context, d *Delivery) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "enqueue"}, time.Now())
+	d.ID = uuid.NewV4()
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = time.Now()
+	}
+	err := r.db.Create(d).Error
+	if err != nil {
+		if gormsupport.IsUniqueViolation(err, "webhook_deliveries_webhook_id_message_id_unique") {
+			log.Debug(ctx, map[string]interface{}{
+				"webhook_id": d.WebhookID,
+				"message_id": d.MessageID,
+			}, "webhook delivery already enqueued")
+			return nil
+		}
+		log.Error(ctx, map[string]interface{}{
+			"webhook_id": d.WebhookID,
+			"message_id": d.MessageID,
+			"err":        err,
+		}, "unable to enqueue the webhook delivery")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// ListDue returns up to limit deliveries that are neither delivered nor given
+// up and whose next attempt is due. The rows are locked for the surrounding
+// transaction and rows locked by others are skipped, so that several
+// dispatchers can work on the same outbox.
+func (r *GormDeliveryRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]Delivery, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "listdue"}, time.Now())
+	var objs []Delivery
+	err := r.db.Set("gorm:query_option", "FOR UPDATE SKIP LOCKED").
+		Where("delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", now).
+		Order("next_attempt_at").
+		Limit(limit).
+		Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Claim postpones the next attempt of the given deliveries until the given
+// time, so that no other dispatcher picks them up while they are being sent.
+// Should the dispatcher die before recording the results, the deliveries are
+// due again once the claim expires.
+func (r *GormDeliveryRepository) Claim(ctx context.Context, ids []uuid.UUID, until time.Time) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "claim"}, time.Now())
+	if len(ids) == 0 {
+		return nil
+	}
+	err := r.db.Model(&Delivery{}).Where("id IN (?)", ids).UpdateColumn("next_attempt_at", until).Error
+	if err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err": err,
+		}, "unable to claim the webhook deliveries")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// List returns the deliveries of a webhook, most recent first
+func (r *GormDeliveryRepository) List(ctx context.Context, webhookID uuid.UUID) ([]Delivery, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "list"}, time.Now())
+	var objs []Delivery
+	err := r.db.Where("webhook_id = ?", webhookID).Order("created_at DESC").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// MarkDelivered records the successful delivery
+func (r *GormDeliveryRepository) MarkDelivered(ctx context.Context, id uuid.UUID, at time.Time) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "delivered"}, time.Now())
+	tx := r.db.Model(&Delivery{ID: id}).Updates(map[string]interface{}{
+		"delivered_at": at,
+		"attempts":     gorm.Expr("attempts + 1"),
+		"last_error":   nil,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(ctx, tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook delivery", id.String())
+	}
+	return nil
+}
+
+// MarkFailed records a failed attempt. The next attempt is scheduled with an
+// exponential backoff unless maxAttempts is reached, in which case the
+// delivery is given up.
+func (r *GormDeliveryRepository) MarkFailed(ctx context.Context, d *Delivery, cause error, at time.Time, maxAttempts int) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook_delivery", "failed"}, time.Now())
+	d.Attempts++
+	msg := cause.Error()
+	d.LastError = &msg
+	if d.Attempts >= maxAttempts {
+		d.FailedAt = &at
+	} else {
+		d.NextAttemptAt = at.Add(Backoff(d.Attempts))
+	}
+	tx := r.db.Model(&Delivery{ID: d.ID}).Updates(map[string]interface{}{
+		"attempts":        d.Attempts,
+		"last_error":      d.LastError,
+		"failed_at":       d.FailedAt,
+		"next_attempt_at": d.NextAttemptAt,
+	})
+	if tx.Error != nil {
+		return errors.NewInternalError(ctx, tx.Error)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook delivery", d.ID.String())
+	}
+	return nil
+}
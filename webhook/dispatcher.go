@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/models"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// dispatchBatchSize is the maximum number of deliveries sent per tick
+const dispatchBatchSize = 50
+
+// DispatcherConfiguration holds the settings of the Dispatcher
+type DispatcherConfiguration interface {
+	GetWebhookDispatchInterval() time.Duration
+	GetWebhookMaxAttempts() int
+	GetWebhookHTTPTimeout() time.Duration
+	GetWebhookAllowPrivateAddresses() bool
+}
+
+// Dispatcher periodically sends the due deliveries of the webhook outbox
+type Dispatcher struct {
+	db     *gorm.DB
+	config DispatcherConfiguration
+	sender *Sender
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher creates a new Dispatcher
+func NewDispatcher(db *gorm.DB, config DispatcherConfiguration) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		config: config,
+		sender: NewSender(config.GetWebhookHTTPTimeout(), config.GetWebhookAllowPrivateAddresses()),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Start runs the dispatch loop in the background until Stop is called
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ticker := time.NewTicker(d.config.GetWebhookDispatchInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-d.stop:
+				return
+			case <-ticker.C:
+				if _, err := d.DispatchDue(ctx, time.Now()); err != nil {
+					log.Error(ctx, map[string]interface{}{
+						"err": err,
+					}, "failed to dispatch webhook deliveries")
+				}
+			}
+		}
+	}()
+}
+
+// Stop terminates the dispatch loop and waits for the current batch to finish.
+// This should be called only from main
+func (d *Dispatcher) Stop() {
+	close(d.stop)
+	d.wg.Wait()
+}
+
+// DispatchDue sends one batch of due deliveries and returns how many of them
+// were delivered successfully. The deliveries are claimed in a short
+// transaction, sent outside of it and the result of each delivery is recorded
+// in its own transaction, so that a failure never rolls back the deliveries
+// which already succeeded.
+func (d *Dispatcher) DispatchDue(ctx context.Context, now time.Time) (int, error) {
+	var due []Delivery
+	err := models.Transactional(d.db, func(tx *gorm.DB) error {
+		deliveries := NewDeliveryRepository(tx)
+		var err error
+		due, err = deliveries.ListDue(ctx, now, dispatchBatchSize)
+		if err != nil {
+			return err
+		}
+		ids := make([]uuid.UUID, len(due))
+		for i, dl := range due {
+			ids[i] = dl.ID
+		}
+		// the claim outlasts the sending of the whole batch
+		return deliveries.Claim(ctx, ids, now.Add(dispatchBatchSize*d.config.GetWebhookHTTPTimeout()+time.Minute))
+	})
+	if err != nil {
+		return 0, err
+	}
+	delivered := 0
+	var firstErr error
+	hooks := map[uuid.UUID]*Webhook{}
+	for i := range due {
+		dl := due[i]
+		ok, err := d.dispatch(ctx, hooks, &dl)
+		if err != nil {
+			log.Error(ctx, map[string]interface{}{
+				"webhook_id":  dl.WebhookID,
+				"delivery_id": dl.ID,
+				"err":         err,
+			}, "unable to record the result of the webhook delivery")
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, firstErr
+}
+
+// dispatch sends a single delivery and records the result. It returns true if
+// the delivery succeeded.
+func (d *Dispatcher) dispatch(ctx context.Context, hooks map[uuid.UUID]*Webhook, dl *Delivery) (bool, error) {
+	h, ok := hooks[dl.WebhookID]
+	if !ok {
+		var err error
+		h, err = NewRepository(d.db).Load(ctx, dl.WebhookID)
+		if notFound, _ := errors.IsNotFoundError(err); notFound {
+			h, err = nil, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		hooks[dl.WebhookID] = h
+	}
+	if h == nil || !h.Active {
+		// the webhook was removed or deactivated in the meantime, so there is
+		// no point in retrying this delivery
+		return false, models.Transactional(d.db, func(tx *gorm.DB) error {
+			return NewDeliveryRepository(tx).MarkFailed(ctx, dl, errs.New("webhook is no longer active"), time.Now(), 0)
+		})
+	}
+	if sendErr := d.sender.Send(ctx, *h, *dl); sendErr != nil {
+		log.Warn(ctx, map[string]interface{}{
+			"webhook_id":  h.ID,
+			"delivery_id": dl.ID,
+			"attempts":    dl.Attempts + 1,
+			"err":         sendErr,
+		}, "webhook delivery failed")
+		return false, models.Transactional(d.db, func(tx *gorm.DB) error {
+			return NewDeliveryRepository(tx).MarkFailed(ctx, dl, sendErr, time.Now(), d.config.GetWebhookMaxAttempts())
+		})
+	}
+	err := models.Transactional(d.db, func(tx *gorm.DB) error {
+		return NewDeliveryRepository(tx).MarkDelivered(ctx, dl.ID, time.Now())
+	})
+	return err == nil, err
+}
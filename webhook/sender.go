@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/rest"
+	errs "github.com/pkg/errors"
+)
+
+const (
+	// SignatureHeader holds the HMAC-SHA256 signature of the request body,
+	// prefixed with "sha256="
+	SignatureHeader = "X-Fabric8-Signature"
+	// EventHeader holds the type of the event that triggered the delivery
+	EventHeader = "X-Fabric8-Event"
+	// DeliveryHeader holds the ID of the delivery. It is stable across
+	// retries so that receivers can detect duplicates.
+	DeliveryHeader = "X-Fabric8-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+// Sign computes the value of the signature header for the given payload
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature returns true if signature is a valid signature of the
+// payload for the given secret.
+func VerifySignature(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, payload)), []byte(signature))
+}
+
+// Sender sends signed webhook payloads over HTTP
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender whose requests time out after the given duration.
+// Unless private addresses are allowed, the host of every request (including
+// redirects) is resolved and rejected if it points to a loopback, link-local
+// or private address. The checked address is the one that is connected to, so
+// a changed DNS answer can't sneak in another one.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	dialer := &net.Dialer{Timeout: timeout}
+	transport := &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			if allowPrivate {
+				return dialer.DialContext(ctx, network, addr)
+			}
+			host, port, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			ips, err := lookupPublicIPs(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+			return dialer.DialContext(ctx, network, net.JoinHostPort(ips[0].String(), port))
+		},
+		TLSHandshakeTimeout: timeout,
+	}
+	return &Sender{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// Send posts the delivery payload to the webhook URL. Any response status
+// outside of the 2xx range is reported as an error.
+func (s *Sender) Send(ctx context.Context, w Webhook, d Delivery) error {
+	body := []byte(d.Payload)
+	req, err := http.NewRequest(http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return errs.Wrapf(err, "failed to create request for webhook %s", w.ID)
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "fabric8-wit-webhook")
+	req.Header.Set(EventHeader, d.EventType)
+	req.Header.Set(DeliveryHeader, d.ID.String())
+	req.Header.Set(SignatureHeader, Sign(w.Secret, body))
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errs.Wrapf(err, "failed to send delivery %s to webhook %s", d.ID, w.ID)
+	}
+	defer rest.CloseResponse(resp)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errs.Errorf("webhook %s responded with status %d", w.ID, resp.StatusCode)
+	}
+	return nil
+}
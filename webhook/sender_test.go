@@ -0,0 +1,187 @@
+package webhook_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	payload := []byte(`{"event":"workitem.create"}`)
+
+	t.Run("known signature", func(t *testing.T) {
+		t.Parallel()
+		// echo -n '{"event":"workitem.create"}' | openssl dgst -sha256 -hmac secret
+		assert.Equal(t, "sha256=6d8ba482df18ae40bb543ec898c52cbf8d8b2de15884536789c7e34d5cc1d8e5", webhook.Sign("secret", payload))
+	})
+	t.Run("verify", func(t *testing.T) {
+		t.Parallel()
+		sig := webhook.Sign("secret", payload)
+		assert.True(t, webhook.VerifySignature("secret", payload, sig))
+		assert.False(t, webhook.VerifySignature("other", payload, sig))
+		assert.False(t, webhook.VerifySignature("secret", []byte(`{}`), sig))
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	testData := map[int]time.Duration{
+		0:  0,
+		1:  30 * time.Second,
+		2:  1 * time.Minute,
+		3:  2 * time.Minute,
+		7:  32 * time.Minute,
+		8:  1 * time.Hour,
+		50: 1 * time.Hour,
+	}
+	for attempts, expected := range testData {
+		assert.Equal(t, expected, webhook.Backoff(attempts), "attempts: %d", attempts)
+	}
+}
+
+func TestSender(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	w := webhook.Webhook{ID: uuid.NewV4(), Secret: "secret"}
+	d := webhook.Delivery{
+		ID:        uuid.NewV4(),
+		EventType: webhook.EventWorkItemCreate,
+		Payload:   `{"event":"workitem.create"}`,
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		t.Parallel()
+		var received *http.Request
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			received = req
+			body, _ = ioutil.ReadAll(req.Body)
+			rw.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+		hook := w
+		hook.URL = srv.URL
+		err := webhook.NewSender(time.Second, true).Send(context.Background(), hook, d)
+		require.NoError(t, err)
+		require.NotNil(t, received)
+		assert.Equal(t, http.MethodPost, received.Method)
+		assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+		assert.Equal(t, webhook.EventWorkItemCreate, received.Header.Get(webhook.EventHeader))
+		assert.Equal(t, d.ID.String(), received.Header.Get(webhook.DeliveryHeader))
+		assert.Equal(t, d.Payload, string(body))
+		assert.True(t, webhook.VerifySignature("secret", body, received.Header.Get(webhook.SignatureHeader)))
+	})
+
+	t.Run("error status", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			rw.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+		hook := w
+		hook.URL = srv.URL
+		err := webhook.NewSender(time.Second, true).Send(context.Background(), hook, d)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+
+	t.Run("private address", func(t *testing.T) {
+		t.Parallel()
+		sent := false
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+			sent = true
+		}))
+		defer srv.Close()
+		hook := w
+		hook.URL = srv.URL
+		err := webhook.NewSender(time.Second, false).Send(context.Background(), hook, d)
+		require.Error(t, err)
+		assert.False(t, sent)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		t.Parallel()
+		srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {}))
+		hook := w
+		hook.URL = srv.URL
+		srv.Close()
+		err := webhook.NewSender(time.Second, true).Send(context.Background(), hook, d)
+		require.Error(t, err)
+	})
+}
+
+func TestWebhookValidate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	valid := webhook.Webhook{URL: "https://ci.example.com/hook", Secret: "s3cr3t"}
+	require.NoError(t, valid.Validate())
+
+	testData := map[string]webhook.Webhook{
+		"relative url":     {URL: "/hook", Secret: "s3cr3t"},
+		"ftp url":          {URL: "ftp://ci.example.com/hook", Secret: "s3cr3t"},
+		"empty secret":     {URL: "https://ci.example.com/hook", Secret: " "},
+		"unknown event":    {URL: "https://ci.example.com/hook", Secret: "s3cr3t", Events: []string{"space.delete"}},
+		"malformed url":    {URL: "https://%zz", Secret: "s3cr3t"},
+		"url without host": {URL: "https://", Secret: "s3cr3t"},
+	}
+	for name, w := range testData {
+		t.Run(name, func(t *testing.T) {
+			assert.Error(t, w.Validate())
+		})
+	}
+}
+
+func TestWebhookCheckAddress(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	public := webhook.Webhook{URL: "https://93.184.216.34/hook"}
+	require.NoError(t, public.CheckAddress(context.Background(), false))
+
+	testData := map[string]string{
+		"loopback":        "http://127.0.0.1:8080/hook",
+		"localhost":       "http://localhost/hook",
+		"ipv6 loopback":   "http://[::1]/hook",
+		"link-local":      "http://169.254.169.254/latest/meta-data",
+		"private":         "https://10.1.2.3/hook",
+		"private 172":     "https://172.16.0.1/hook",
+		"private 192":     "https://192.168.1.1/hook",
+		"unspecified":     "http://0.0.0.0/hook",
+		"ipv4 mapped":     "http://[::ffff:127.0.0.1]/hook",
+		"unique local v6": "http://[fd00::1]/hook",
+	}
+	for name, u := range testData {
+		t.Run(name, func(t *testing.T) {
+			w := webhook.Webhook{URL: u}
+			err := w.CheckAddress(context.Background(), false)
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+			// private addresses can be allowed for development
+			assert.NoError(t, w.CheckAddress(context.Background(), true))
+		})
+	}
+}
+
+func TestWebhookSubscribes(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	all := webhook.Webhook{}
+	assert.True(t, all.Subscribes(webhook.EventCommentUpdate))
+	some := webhook.Webhook{Events: []string{webhook.EventWorkItemCreate}}
+	assert.True(t, some.Subscribes(webhook.EventWorkItemCreate))
+	assert.False(t, some.Subscribes(webhook.EventCommentCreate))
+}
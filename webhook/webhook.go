@@ -0,0 +1,256 @@
+package webhook
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormsupport"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	"github.com/lib/pq"
+	uuid "github.com/satori/go.uuid"
+)
+
+// APIStringTypeWebhooks helps to avoid string literal
+const APIStringTypeWebhooks = "webhooks"
+
+// Event types a webhook can subscribe to. They match the message types of the
+// notification package.
+const (
+	EventWorkItemCreate     = "workitem.create"
+	EventWorkItemUpdate     = "workitem.update"
+	EventWorkItemBulkUpdate = "workitem.bulkupdate"
+	EventWorkItemMention    = "workitem.mention"
+	EventCommentCreate      = "comment.create"
+	EventCommentUpdate      = "comment.update"
+	EventCommentMention     = "comment.mention"
+)
+
+// KnownEvents lists all the event types a webhook can subscribe to
+var KnownEvents = []string{
+	EventWorkItemCreate,
+	EventWorkItemUpdate,
+	EventWorkItemBulkUpdate,
+	EventWorkItemMention,
+	EventCommentCreate,
+	EventCommentUpdate,
+	EventCommentMention,
+}
+
+// Webhook describes a subscription of an external HTTP endpoint to the events
+// happening in a space
+type Webhook struct {
+	gormsupport.Lifecycle
+	ID      uuid.UUID `sql:"type:uuid default uuid_generate_v4()" gorm:"primary_key"` // This is the ID PK field
+	SpaceID uuid.UUID `sql:"type:uuid"`
+	URL     string
+	// Secret is used to sign the payloads sent to the URL
+	Secret string
+	// Events holds the event types to deliver. An empty list means all events.
+	Events  pq.StringArray `sql:"type:text[]"`
+	Active  bool
+	Version int
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (m Webhook) GetETagData() []interface{} {
+	return []interface{}{m.ID, m.Version}
+}
+
+// GetLastModified returns the last modification time
+func (m Webhook) GetLastModified() time.Time {
+	return m.UpdatedAt.Truncate(time.Second)
+}
+
+// TableName overrides the table name settings in Gorm to force a specific table name
+// in the database.
+func (m Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribes returns true if the webhook wants to receive events of the given
+// type.
+func (m Webhook) Subscribes(eventType string) bool {
+	if len(m.Events) == 0 {
+		return true
+	}
+	for _, e := range m.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that the webhook has a usable URL, a secret and only
+// subscribes to known events.
+func (m Webhook) Validate() error {
+	u, err := url.Parse(m.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.NewBadParameterError("url", m.URL).Expected("absolute http or https URL")
+	}
+	if strings.TrimSpace(m.Secret) == "" {
+		return errors.NewBadParameterError("secret", m.Secret).Expected("non empty string")
+	}
+	for _, e := range m.Events {
+		known := false
+		for _, k := range KnownEvents {
+			if e == k {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return errors.NewBadParameterError("events", e).Expected(strings.Join(KnownEvents, ", "))
+		}
+	}
+	return nil
+}
+
+// Repository describes interactions with webhooks
+type Repository interface {
+	Create(ctx context.Context, w *Webhook) error
+	Load(ctx context.Context, id uuid.UUID) (*Webhook, error)
+	List(ctx context.Context, spaceID uuid.UUID) ([]Webhook, error)
+	ListActive(ctx context.Context, spaceID uuid.UUID, eventType string) ([]Webhook, error)
+	Save(ctx context.Context, w Webhook) (*Webhook, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// NewRepository creates a new storage type.
+func NewRepository(db *gorm.DB) Repository {
+	return &GormRepository{db: db}
+}
+
+// GormRepository is the implementation of the storage interface for webhooks.
+type GormRepository struct {
+	db *gorm.DB
+}
+
+// Create a new webhook
+func (r *GormRepository) Create(ctx context.Context, w *Webhook) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "create"}, time.Now())
+	if err := w.Validate(); err != nil {
+		return err
+	}
+	w.ID = uuid.NewV4()
+	if w.Events == nil {
+		w.Events = pq.StringArray{}
+	}
+	if err := r.db.Create(w).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": w.SpaceID,
+			"err":      err,
+		}, "unable to create the webhook")
+		return errors.NewInternalError(ctx, err)
+	}
+	return nil
+}
+
+// Load returns the webhook with the given ID
+func (r *GormRepository) Load(ctx context.Context, id uuid.UUID) (*Webhook, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "show"}, time.Now())
+	w := Webhook{}
+	tx := r.db.Where("id = ?", id).First(&w)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("webhook", id.String())
+	}
+	if tx.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"webhook_id": id,
+			"err":        tx.Error,
+		}, "unable to load the webhook by ID")
+		return nil, errors.NewInternalError(ctx, tx.Error)
+	}
+	return &w, nil
+}
+
+// List returns all webhooks of a space
+func (r *GormRepository) List(ctx context.Context, spaceID uuid.UUID) ([]Webhook, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "list"}, time.Now())
+	var objs []Webhook
+	err := r.db.Where("space_id = ?", spaceID).Order("created_at").Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// ListActive returns the active webhooks of a space that subscribe to the
+// given event type
+func (r *GormRepository) ListActive(ctx context.Context, spaceID uuid.UUID, eventType string) ([]Webhook, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "listactive"}, time.Now())
+	var objs []Webhook
+	err := r.db.Where("space_id = ? AND active AND (cardinality(events) = 0 OR ? = ANY(events))", spaceID, eventType).Find(&objs).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	return objs, nil
+}
+
+// Save updates the given webhook
+func (r *GormRepository) Save(ctx context.Context, w Webhook) (*Webhook, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "save"}, time.Now())
+	if err := w.Validate(); err != nil {
+		return nil, err
+	}
+	if w.Events == nil {
+		w.Events = pq.StringArray{}
+	}
+	existing := Webhook{}
+	tx := r.db.Where("id = ?", w.ID).First(&existing)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("webhook", w.ID.String())
+	}
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	oldVersion := w.Version
+	w.Version = existing.Version + 1
+	tx = r.db.Where("version = ?", oldVersion).Save(&w)
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"webhook_id": w.ID,
+			"err":        err,
+		}, "unable to save the webhook")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	return &w, nil
+}
+
+// Delete removes the webhook with the given ID
+func (r *GormRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "webhook", "delete"}, time.Now())
+	tx := r.db.Delete(Webhook{ID: id})
+	if err := tx.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"webhook_id": id,
+			"err":        err,
+		}, "unable to delete the webhook")
+		return errors.NewInternalError(ctx, err)
+	}
+	if tx.RowsAffected == 0 {
+		return errors.NewNotFoundError("webhook", id.String())
+	}
+	return nil
+}
+
+// Payload is the JSON document posted to a webhook
+type Payload struct {
+	ID        uuid.UUID `json:"id"`
+	Event     string    `json:"event"`
+	SpaceID   uuid.UUID `json:"space_id"`
+	TargetID  string    `json:"target_id"`
+	UserID    *string   `json:"user_id,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	// Custom holds additional details of the event, e.g. the ID of the
+	// mentioned user
+	Custom map[string]interface{} `json:"custom,omitempty"`
+}
@@ -0,0 +1,297 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/notification"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/webhook"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type webhookRepositorySuite struct {
+	gormtestsupport.DBTestSuite
+}
+
+func TestWebhookRepository(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &webhookRepositorySuite{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *webhookRepositorySuite) createWebhook(spaceID uuid.UUID, url string, events ...string) *webhook.Webhook {
+	w := webhook.Webhook{
+		SpaceID: spaceID,
+		URL:     url,
+		Secret:  "s3cr3t",
+		Events:  events,
+		Active:  true,
+	}
+	require.NoError(s.T(), webhook.NewRepository(s.DB).Create(s.Ctx, &w))
+	return &w
+}
+
+func (s *webhookRepositorySuite) TestCreateAndLoad() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	repo := webhook.NewRepository(s.DB)
+
+	s.T().Run("ok", func(t *testing.T) {
+		w := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/hook", webhook.EventWorkItemCreate)
+		require.NotEqual(t, uuid.Nil, w.ID)
+		loaded, err := repo.Load(s.Ctx, w.ID)
+		require.NoError(t, err)
+		assert.Equal(t, w.URL, loaded.URL)
+		assert.Equal(t, w.Secret, loaded.Secret)
+		assert.Equal(t, []string{webhook.EventWorkItemCreate}, []string(loaded.Events))
+		assert.True(t, loaded.Active)
+	})
+	s.T().Run("invalid", func(t *testing.T) {
+		w := webhook.Webhook{SpaceID: fxt.Spaces[0].ID, URL: "not a url", Secret: "s3cr3t"}
+		err := repo.Create(s.Ctx, &w)
+		require.Error(t, err)
+		_, ok := errors.IsBadParameterError(err)
+		assert.True(t, ok)
+	})
+	s.T().Run("not found", func(t *testing.T) {
+		_, err := repo.Load(s.Ctx, uuid.NewV4())
+		require.Error(t, err)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+	})
+}
+
+func (s *webhookRepositorySuite) TestListActive() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(2))
+	repo := webhook.NewRepository(s.DB)
+	all := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/all")
+	comments := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/comments", webhook.EventCommentCreate)
+	inactive := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/inactive")
+	inactive.Active = false
+	_, err := repo.Save(s.Ctx, *inactive)
+	require.NoError(s.T(), err)
+	s.createWebhook(fxt.Spaces[1].ID, "https://ci.example.com/other")
+
+	list, err := repo.List(s.Ctx, fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	assert.Len(s.T(), list, 3)
+
+	active, err := repo.ListActive(s.Ctx, fxt.Spaces[0].ID, webhook.EventWorkItemCreate)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), active, 1)
+	assert.Equal(s.T(), all.ID, active[0].ID)
+
+	active, err = repo.ListActive(s.Ctx, fxt.Spaces[0].ID, webhook.EventCommentCreate)
+	require.NoError(s.T(), err)
+	ids := []uuid.UUID{}
+	for _, w := range active {
+		ids = append(ids, w.ID)
+	}
+	assert.ElementsMatch(s.T(), []uuid.UUID{all.ID, comments.ID}, ids)
+}
+
+func (s *webhookRepositorySuite) TestSaveAndDelete() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	repo := webhook.NewRepository(s.DB)
+	w := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/hook")
+
+	s.T().Run("save", func(t *testing.T) {
+		w.URL = "https://ci.example.com/other"
+		saved, err := repo.Save(s.Ctx, *w)
+		require.NoError(t, err)
+		assert.Equal(t, "https://ci.example.com/other", saved.URL)
+		assert.Equal(t, w.Version+1, saved.Version)
+		// saving with the outdated version must fail
+		_, err = repo.Save(s.Ctx, *w)
+		require.Error(t, err)
+		ok, _ := errors.IsVersionConflictError(err)
+		assert.True(t, ok)
+	})
+	s.T().Run("delete", func(t *testing.T) {
+		require.NoError(t, repo.Delete(s.Ctx, w.ID))
+		_, err := repo.Load(s.Ctx, w.ID)
+		ok, _ := errors.IsNotFoundError(err)
+		assert.True(t, ok)
+		err = repo.Delete(s.Ctx, w.ID)
+		ok, _ = errors.IsNotFoundError(err)
+		assert.True(t, ok)
+	})
+}
+
+func (s *webhookRepositorySuite) TestDeliveries() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	w := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/hook")
+	repo := webhook.NewDeliveryRepository(s.DB)
+	d := webhook.Delivery{
+		WebhookID: w.ID,
+		MessageID: uuid.NewV4(),
+		EventType: webhook.EventWorkItemCreate,
+		Payload:   `{}`,
+	}
+	require.NoError(s.T(), repo.Enqueue(s.Ctx, &d))
+	// enqueuing the same message again is a no-op
+	dup := d
+	require.NoError(s.T(), repo.Enqueue(s.Ctx, &dup))
+	list, err := repo.List(s.Ctx, w.ID)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), list, 1)
+
+	now := time.Now()
+	due, err := repo.ListDue(s.Ctx, now, 10)
+	require.NoError(s.T(), err)
+	require.Len(s.T(), due, 1)
+
+	s.T().Run("failed attempt is retried later", func(t *testing.T) {
+		require.NoError(t, repo.MarkFailed(s.Ctx, &due[0], errors.NewInternalErrorFromString("boom"), now, 3))
+		assert.Equal(t, 1, due[0].Attempts)
+		assert.Nil(t, due[0].FailedAt)
+		due, err := repo.ListDue(s.Ctx, now, 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+		due, err = repo.ListDue(s.Ctx, now.Add(webhook.Backoff(1)), 10)
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+	})
+	s.T().Run("delivery is given up after max attempts", func(t *testing.T) {
+		dl := due[0]
+		require.NoError(t, repo.MarkFailed(s.Ctx, &dl, errors.NewInternalErrorFromString("boom"), now, 2))
+		assert.NotNil(t, dl.FailedAt)
+		due, err := repo.ListDue(s.Ctx, now.Add(24*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+	s.T().Run("delivered", func(t *testing.T) {
+		other := webhook.Delivery{WebhookID: w.ID, MessageID: uuid.NewV4(), EventType: webhook.EventWorkItemUpdate, Payload: `{}`}
+		require.NoError(t, repo.Enqueue(s.Ctx, &other))
+		require.NoError(t, repo.MarkDelivered(s.Ctx, other.ID, now))
+		due, err := repo.ListDue(s.Ctx, now.Add(24*time.Hour), 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+	})
+	s.T().Run("claimed", func(t *testing.T) {
+		other := webhook.Delivery{WebhookID: w.ID, MessageID: uuid.NewV4(), EventType: webhook.EventWorkItemUpdate, Payload: `{}`}
+		require.NoError(t, repo.Enqueue(s.Ctx, &other))
+		require.NoError(t, repo.Claim(s.Ctx, []uuid.UUID{other.ID}, now.Add(time.Hour)))
+		// the claimed delivery is left alone until the claim expires
+		due, err := repo.ListDue(s.Ctx, now.Add(time.Minute), 10)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+		due, err = repo.ListDue(s.Ctx, now.Add(2*time.Hour), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		assert.Equal(t, other.ID, due[0].ID)
+	})
+}
+
+func (s *webhookRepositorySuite) TestChannel() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1), tf.WorkItems(1), tf.Comments(1))
+	w := s.createWebhook(fxt.Spaces[0].ID, "https://ci.example.com/hook")
+	channel := notification.NewWebhookChannel(s.DB)
+
+	s.T().Run("work item", func(t *testing.T) {
+		msg := notification.NewWorkItemUpdated(fxt.WorkItems[0].ID.String())
+		channel.Send(s.Ctx, msg)
+		channel.Wait()
+		list, err := webhook.NewDeliveryRepository(s.DB).List(s.Ctx, w.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, list)
+		assert.Equal(t, msg.MessageID, list[0].MessageID)
+		p := webhook.Payload{}
+		require.NoError(t, json.Unmarshal([]byte(list[0].Payload), &p))
+		assert.Equal(t, fxt.Spaces[0].ID, p.SpaceID)
+		assert.Equal(t, webhook.EventWorkItemUpdate, p.Event)
+		assert.Equal(t, fxt.WorkItems[0].ID.String(), p.TargetID)
+	})
+	s.T().Run("comment", func(t *testing.T) {
+		msg := notification.NewCommentCreated(fxt.Comments[0].ID.String())
+		channel.Send(s.Ctx, msg)
+		channel.Wait()
+		list, err := webhook.NewDeliveryRepository(s.DB).List(s.Ctx, w.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, list)
+		assert.Equal(t, msg.MessageID, list[0].MessageID)
+	})
+}
+
+type dispatcherConfig struct {
+	maxAttempts int
+}
+
+func (c dispatcherConfig) GetWebhookDispatchInterval() time.Duration { return time.Second }
+func (c dispatcherConfig) GetWebhookMaxAttempts() int                { return c.maxAttempts }
+func (c dispatcherConfig) GetWebhookHTTPTimeout() time.Duration      { return time.Second }
+func (c dispatcherConfig) GetWebhookAllowPrivateAddresses() bool     { return true }
+
+func (s *webhookRepositorySuite) TestDispatcher() {
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1), tf.WorkItems(1))
+	var mu sync.Mutex
+	status := http.StatusOK
+	var bodies []string
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		body, _ := ioutil.ReadAll(req.Body)
+		if webhook.VerifySignature("s3cr3t", body, req.Header.Get(webhook.SignatureHeader)) {
+			bodies = append(bodies, string(body))
+		}
+		rw.WriteHeader(status)
+	}))
+	defer srv.Close()
+	w := s.createWebhook(fxt.Spaces[0].ID, srv.URL)
+	dispatcher := webhook.NewDispatcher(s.DB, dispatcherConfig{maxAttempts: 2})
+	deliveries := webhook.NewDeliveryRepository(s.DB)
+	channel := notification.NewWebhookChannel(s.DB)
+
+	s.T().Run("failure is retried", func(t *testing.T) {
+		mu.Lock()
+		status = http.StatusInternalServerError
+		mu.Unlock()
+		channel.Send(s.Ctx, notification.NewWorkItemCreated(fxt.WorkItems[0].ID.String()))
+		channel.Wait()
+		n, err := dispatcher.DispatchDue(s.Ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		list, err := deliveries.List(s.Ctx, w.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, 1, list[0].Attempts)
+		require.NotNil(t, list[0].LastError)
+
+		mu.Lock()
+		status = http.StatusOK
+		mu.Unlock()
+		n, err = dispatcher.DispatchDue(s.Ctx, time.Now().Add(webhook.Backoff(1)))
+		require.NoError(t, err)
+		assert.Equal(t, 1, n)
+		list, err = deliveries.List(s.Ctx, w.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, list[0].DeliveredAt)
+		mu.Lock()
+		assert.Len(t, bodies, 1)
+		mu.Unlock()
+	})
+	s.T().Run("inactive webhook is given up", func(t *testing.T) {
+		channel.Send(s.Ctx, notification.NewWorkItemUpdated(fxt.WorkItems[0].ID.String()))
+		channel.Wait()
+		w.Active = false
+		_, err := webhook.NewRepository(s.DB).Save(s.Ctx, *w)
+		require.NoError(t, err)
+		n, err := dispatcher.DispatchDue(s.Ctx, time.Now())
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		list, err := deliveries.List(s.Ctx, w.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+		assert.NotNil(t, list[0].FailedAt)
+	})
+}
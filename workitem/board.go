@@ -83,6 +83,12 @@ type BoardColumn struct {
 	Order                 int       `json:"order" gorm:"column:column_order"`
 	TransRuleKey          string    `json:"trans_rule_key"`
 	TransRuleArgument     string    `json:"trans_rule_argument"` // TODO: this is a JSON, not a string
+	// WIPLimit is the maximum number of work items of a space in this column;
+	// zero means no limit.
+	WIPLimit int `json:"wip_limit,omitempty"`
+	// WIPLimitEnforced rejects moves into the column that would exceed the
+	// WIPLimit; otherwise such moves are only logged as a warning.
+	WIPLimitEnforced bool `json:"wip_limit_enforced,omitempty"`
 }
 
 // TableName implements gorm.tabler
@@ -118,5 +124,11 @@ func (wibc BoardColumn) Equal(u convert.Equaler) bool {
 	if wibc.TransRuleArgument != other.TransRuleArgument {
 		return false
 	}
+	if wibc.WIPLimit != other.WIPLimit {
+		return false
+	}
+	if wibc.WIPLimitEnforced != other.WIPLimitEnforced {
+		return false
+	}
 	return true
 }
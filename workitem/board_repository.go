@@ -103,6 +103,9 @@ func (r *GormBoardRepository) Create(ctx context.Context, b Board) (*Board, erro
 	if len(b.Columns) <= 0 {
 		return nil, errors.NewBadParameterError("columns", b.Columns).Expected("not empty")
 	}
+	if err := b.Validate(); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	if b.ID == uuid.Nil {
 		b.ID = uuid.NewV4()
 	}
@@ -69,7 +69,7 @@ func (s *workItemBoardRepoTest) TestCreate() {
 				Name:              "New",
 				Order:             0,
 				TransRuleKey:      "updateStateFromColumnMove",
-				TransRuleArgument: "{ 'metastate': 'mNew' }",
+				TransRuleArgument: `{ "metaState": "mNew" }`,
 				BoardID:           ID,
 			},
 			{
@@ -77,7 +77,9 @@ func (s *workItemBoardRepoTest) TestCreate() {
 				Name:              "Done",
 				Order:             1,
 				TransRuleKey:      "updateStateFromColumnMove",
-				TransRuleArgument: "{ 'metastate': 'mDone' }",
+				TransRuleArgument: `{ "metaState": "mDone" }`,
+				WIPLimit:          3,
+				WIPLimitEnforced:  true,
 				BoardID:           ID,
 			},
 		},
@@ -124,6 +126,31 @@ func (s *workItemBoardRepoTest) TestCreate() {
 			require.Error(t, err)
 			require.Contains(t, err.Error(), "work_item_board_id_order_unique")
 		})
+		t.Run("invalid transition rule", func(t *testing.T) {
+			g := expected
+			g.ID = uuid.NewV4()
+			g.Name = uuid.NewV4().String()
+			g.Columns = []workitem.BoardColumn{{
+				Name:              "New",
+				TransRuleKey:      "updateStateFromColumnMove",
+				TransRuleArgument: "{ 'metaState': 'mNew' }",
+			}}
+			_, err := s.repo.Create(s.Ctx, g)
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+		t.Run("negative WIP limit", func(t *testing.T) {
+			g := expected
+			g.ID = uuid.NewV4()
+			g.Name = uuid.NewV4().String()
+			g.Columns = []workitem.BoardColumn{{
+				Name:     "New",
+				WIPLimit: -1,
+			}}
+			_, err := s.repo.Create(s.Ctx, g)
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
 	})
 }
 
@@ -193,7 +220,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 				Name:              "New",
 				Order:             0,
 				TransRuleKey:      "updateStateFromColumnMove",
-				TransRuleArgument: "{ 'metastate': 'mNew' }",
+				TransRuleArgument: `{ "metaState": "mNew" }`,
 				BoardID:           ID,
 			},
 			{
@@ -201,7 +228,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 				Name:              "Done",
 				Order:             1,
 				TransRuleKey:      "updateStateFromColumnMove",
-				TransRuleArgument: "{ 'metastate': 'mDone' }",
+				TransRuleArgument: `{ "metaState": "mDone" }`,
 				BoardID:           ID,
 			},
 		},
@@ -239,7 +266,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 					Name:              "New",
 					Order:             0,
 					TransRuleKey:      "updateStateFromColumnMove",
-					TransRuleArgument: "{ 'metastate': 'mNew' }",
+					TransRuleArgument: `{ "metaState": "mNew" }`,
 					BoardID:           ID,
 				},
 				{
@@ -247,7 +274,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 					Name:              "Done",
 					Order:             1,
 					TransRuleKey:      "updateStateFromColumnMove",
-					TransRuleArgument: "{ 'metastate': 'mDone' }",
+					TransRuleArgument: `{ "metaState": "mDone" }`,
 					BoardID:           ID,
 				},
 			}
@@ -262,7 +289,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 					Name:              "New",
 					Order:             0,
 					TransRuleKey:      "updateStateFromColumnMove",
-					TransRuleArgument: "{ 'metastate': 'mNew' }",
+					TransRuleArgument: `{ "metaState": "mNew" }`,
 					BoardID:           ID,
 				},
 			}
@@ -276,7 +303,7 @@ func TestWorkItemBoard_Equal(t *testing.T) {
 				Name:              "New 1",
 				Order:             0,
 				TransRuleKey:      "updateStateFromColumnMove",
-				TransRuleArgument: "{ 'metastate': 'mNew' }",
+				TransRuleArgument: `{ "metaState": "mNew" }`,
 				BoardID:           ID,
 			})
 			require.False(t, a.Equal(b))
@@ -307,7 +334,7 @@ func TestWorkItemBoardColumn_Equal(t *testing.T) {
 		Name:              "New",
 		Order:             0,
 		TransRuleKey:      "updateStateFromColumnMove",
-		TransRuleArgument: "{ 'metastate': 'mNew' }",
+		TransRuleArgument: `{ "metaState": "mNew" }`,
 		BoardID:           uuid.NewV4(),
 	}
 	t.Run("equality", func(t *testing.T) {
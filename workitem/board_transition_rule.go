@@ -0,0 +1,154 @@
+package workitem
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	errs "github.com/pkg/errors"
+)
+
+// TransRuleKeyUpdateStateFromColumnMove is the key of the transition rule that
+// sets the state of a work item which is moved into a board column to the
+// state that matches the meta-state given in the rule argument, e.g.
+//
+//	{ "metaState": "mInprogress" }
+const TransRuleKeyUpdateStateFromColumnMove = "updateStateFromColumnMove"
+
+// TransitionRule is what happens to a work item that is moved into a board
+// column.
+type TransitionRule interface {
+	// Apply applies the rule to the new fields of a work item of the given
+	// type which is moved into the column. The old fields are the ones before
+	// the move. A BadParameterError is returned if the move violates the rule.
+	Apply(wit WorkItemType, oldFields, newFields Fields) error
+}
+
+// TransitionRuleParser parses and validates the argument of a transition rule
+// and returns the rule.
+type TransitionRuleParser func(argument string) (TransitionRule, error)
+
+// transitionRules holds the parsers of the known transition rules by key
+var transitionRules = map[string]TransitionRuleParser{}
+
+func init() {
+	RegisterTransitionRule(TransRuleKeyUpdateStateFromColumnMove, parseUpdateStateFromColumnMove)
+}
+
+// RegisterTransitionRule makes the transition rule parsed by the given parser
+// available for board columns under the given key.
+func RegisterTransitionRule(key string, parser TransitionRuleParser) {
+	transitionRules[key] = parser
+}
+
+// TransitionRule returns the transition rule of the column or nil if the
+// column has no transition rule. A BadParameterError is returned if the rule
+// key is unknown or the rule argument is invalid.
+func (wibc BoardColumn) TransitionRule() (TransitionRule, error) {
+	if wibc.TransRuleKey == "" {
+		return nil, nil
+	}
+	parse, ok := transitionRules[wibc.TransRuleKey]
+	if !ok {
+		keys := make([]string, 0, len(transitionRules))
+		for k := range transitionRules {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		return nil, errors.NewBadParameterError("trans_rule_key", wibc.TransRuleKey).Expected(strings.Join(keys, ", "))
+	}
+	rule, err := parse(wibc.TransRuleArgument)
+	if err != nil {
+		return nil, errs.Wrapf(err, "invalid argument of the transition rule of board column \"%s\"", wibc.Name)
+	}
+	return rule, nil
+}
+
+// Validate returns a BadParameterError if the transition rule or the work in
+// progress limit of one of the columns of the board is invalid.
+func (wib Board) Validate() error {
+	for _, column := range wib.Columns {
+		if column.WIPLimit < 0 {
+			return errors.NewBadParameterError("wip_limit", column.WIPLimit).Expected("zero or a positive number")
+		}
+		if _, err := column.TransitionRule(); err != nil {
+			return errs.Wrapf(err, "invalid work item board \"%s\"", wib.Name)
+		}
+	}
+	return nil
+}
+
+// UpdateStateFromColumnMove is the transition rule that sets the state of a
+// work item to the state that matches the meta-state of the column.
+type UpdateStateFromColumnMove struct {
+	MetaState string `json:"metaState"`
+}
+
+// parseUpdateStateFromColumnMove implements TransitionRuleParser
+func parseUpdateStateFromColumnMove(argument string) (TransitionRule, error) {
+	var rule UpdateStateFromColumnMove
+	if err := json.Unmarshal([]byte(argument), &rule); err != nil {
+		return nil, errors.NewBadParameterError("trans_rule_argument", argument).Expected(`JSON object like { "metaState": "mNew" }`)
+	}
+	if rule.MetaState == "" {
+		return nil, errors.NewBadParameterError("trans_rule_argument", argument).Expected("non-empty metaState")
+	}
+	return rule, nil
+}
+
+// Apply implements TransitionRule. The move is rejected if the state is
+// explicitly changed to a different state along with the move.
+func (r UpdateStateFromColumnMove) Apply(wit WorkItemType, oldFields, newFields Fields) error {
+	state, err := wit.StateForMetaState(r.MetaState)
+	if err != nil {
+		return errs.WithStack(err)
+	}
+	if newState := newFields[SystemState]; newState != oldFields[SystemState] && newState != state {
+		return errors.NewBadParameterError(SystemState, newState).Expected(state)
+	}
+	newFields[SystemState] = state
+	return nil
+}
+
+// enumValues returns the values of an enum field type or nil if the given
+// type is no enum
+func enumValues(t FieldType) []interface{} {
+	switch enum := t.(type) {
+	case EnumType:
+		return enum.Values
+	case *EnumType:
+		return enum.Values
+	}
+	return nil
+}
+
+// StateForMetaState returns the value of the state field that corresponds to
+// the given meta-state. The values of the meta-state field are given in the
+// same order as the ones of the state field. A BadParameterError is returned
+// if the type has no such meta-state or no state for it.
+func (wit WorkItemType) StateForMetaState(metaState string) (string, error) {
+	metaStateDef, ok := wit.Fields[SystemMetaState]
+	if !ok {
+		return "", errors.NewBadParameterError(SystemBoardcolumns, metaState).Expected("column of a work item type with meta-states")
+	}
+	stateDef, ok := wit.Fields[SystemState]
+	if !ok {
+		return "", errors.NewBadParameterError(SystemBoardcolumns, metaState).Expected("column of a work item type with states")
+	}
+	states := enumValues(stateDef.Type)
+	for i, v := range enumValues(metaStateDef.Type) {
+		if v != metaState {
+			continue
+		}
+		if i >= len(states) {
+			break
+		}
+		state, ok := states[i].(string)
+		if !ok {
+			break
+		}
+		return state, nil
+	}
+	return "", errors.NewBadParameterError(SystemBoardcolumns, metaState).Expected("meta-state with a state of work item type " + wit.Name)
+}
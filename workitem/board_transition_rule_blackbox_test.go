@@ -0,0 +1,112 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoardColumnTransitionRule(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("update state from column move", func(t *testing.T) {
+		rule, err := workitem.BoardColumn{
+			TransRuleKey:      workitem.TransRuleKeyUpdateStateFromColumnMove,
+			TransRuleArgument: `{ "metaState": "mInprogress" }`,
+		}.TransitionRule()
+		require.NoError(t, err)
+		assert.Equal(t, workitem.UpdateStateFromColumnMove{MetaState: "mInprogress"}, rule)
+	})
+	t.Run("no rule", func(t *testing.T) {
+		rule, err := workitem.BoardColumn{}.TransitionRule()
+		require.NoError(t, err)
+		assert.Nil(t, rule)
+	})
+	t.Run("unknown key", func(t *testing.T) {
+		_, err := workitem.BoardColumn{TransRuleKey: "foo"}.TransitionRule()
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	t.Run("invalid arguments", func(t *testing.T) {
+		for _, arg := range []string{``, `{ 'metaState': 'mNew' }`, `{ "metaState": "" }`, `{ "state": "mNew" }`} {
+			t.Run(arg, func(t *testing.T) {
+				_, err := workitem.BoardColumn{
+					TransRuleKey:      workitem.TransRuleKeyUpdateStateFromColumnMove,
+					TransRuleArgument: arg,
+				}.TransitionRule()
+				require.Error(t, err)
+				assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+			})
+		}
+	})
+	t.Run("invalid board", func(t *testing.T) {
+		err := workitem.Board{Columns: []workitem.BoardColumn{
+			{TransRuleKey: workitem.TransRuleKeyUpdateStateFromColumnMove, TransRuleArgument: `{ "metaState": "mNew" }`},
+			{TransRuleKey: workitem.TransRuleKeyUpdateStateFromColumnMove, TransRuleArgument: `{}`},
+		}}.Validate()
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func TestUpdateStateFromColumnMove(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given a type whose states have different names than the ones of the
+	// base type
+	enum := func(values ...interface{}) workitem.FieldDefinition {
+		return workitem.FieldDefinition{Type: workitem.EnumType{
+			SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+			BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+			Values:     values,
+		}}
+	}
+	wit := workitem.WorkItemType{
+		Name: "task",
+		Fields: workitem.FieldDefinitions{
+			workitem.SystemState:     enum("to do", "doing", "done"),
+			workitem.SystemMetaState: enum("mNew", "mInprogress", "mResolved", "mClosed"),
+		},
+	}
+	t.Run("state for meta-state", func(t *testing.T) {
+		state, err := wit.StateForMetaState("mInprogress")
+		require.NoError(t, err)
+		assert.Equal(t, "doing", state)
+	})
+	t.Run("sets the state", func(t *testing.T) {
+		newFields := workitem.Fields{workitem.SystemState: "to do"}
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mResolved"}.Apply(wit, workitem.Fields{workitem.SystemState: "to do"}, newFields)
+		require.NoError(t, err)
+		assert.Equal(t, "done", newFields[workitem.SystemState])
+	})
+	t.Run("accepts the same state", func(t *testing.T) {
+		newFields := workitem.Fields{workitem.SystemState: "done"}
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mResolved"}.Apply(wit, workitem.Fields{workitem.SystemState: "to do"}, newFields)
+		require.NoError(t, err)
+		assert.Equal(t, "done", newFields[workitem.SystemState])
+	})
+	t.Run("rejects a different state", func(t *testing.T) {
+		newFields := workitem.Fields{workitem.SystemState: "doing"}
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mResolved"}.Apply(wit, workitem.Fields{workitem.SystemState: "to do"}, newFields)
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	t.Run("rejects a meta-state without state", func(t *testing.T) {
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mClosed"}.Apply(wit, workitem.Fields{}, workitem.Fields{})
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	t.Run("rejects an unknown meta-state", func(t *testing.T) {
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mDone"}.Apply(wit, workitem.Fields{}, workitem.Fields{})
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	t.Run("rejects a type without meta-states", func(t *testing.T) {
+		err := workitem.UpdateStateFromColumnMove{MetaState: "mNew"}.Apply(workitem.WorkItemType{}, workitem.Fields{}, workitem.Fields{})
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
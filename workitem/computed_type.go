@@ -0,0 +1,264 @@
+package workitem
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/convert"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	errs "github.com/pkg/errors"
+)
+
+// FormulaFunction tells how a computed field combines the values it is
+// derived from
+type FormulaFunction string
+
+// constants for describing possible formula functions
+const (
+	FormulaSum       FormulaFunction = "sum"
+	FormulaMin       FormulaFunction = "min"
+	FormulaMax       FormulaFunction = "max"
+	FormulaAvg       FormulaFunction = "avg"
+	FormulaCount     FormulaFunction = "count"
+	FormulaDaysSince FormulaFunction = "days_since"
+)
+
+// Formula describes how the value of a computed field is derived.
+//
+// Without Children the function combines the values of the given Fields of the
+// work item itself, e.g. "days_since" of "system.created_at". With Children
+// the function aggregates the value of the single given field over all child
+// work items that are linked to the work item by a link type of the tree
+// topology, e.g. the "sum" of the children's story points. "count" counts the
+// children (that have a value for the field if one is given).
+type Formula struct {
+	Function FormulaFunction `json:"function"`
+	Fields   []string        `json:"fields,omitempty"`
+	Children bool            `json:"children,omitempty"`
+}
+
+// validate checks that the formula of the computed field with the given name
+// refers to as many fields as its function needs and that it results in a
+// number.
+func (f Formula) validate(name string, resultType SimpleType) error {
+	if resultType.Kind != KindInteger && resultType.Kind != KindFloat {
+		return errors.NewBadParameterError(fmt.Sprintf("result type of field %q", name), resultType.Kind).Expected("integer or float")
+	}
+	fields := fmt.Sprintf("formula fields of field %q", name)
+	for _, field := range f.Fields {
+		if field == name {
+			return errors.NewBadParameterError(fields, f.Fields).Expected("other fields")
+		}
+	}
+	switch f.Function {
+	case FormulaSum, FormulaMin, FormulaMax, FormulaAvg:
+		if len(f.Fields) == 0 || (f.Children && len(f.Fields) != 1) {
+			return errors.NewBadParameterError(fields, f.Fields).Expected("one field of the children or at least one field of the work item")
+		}
+	case FormulaCount:
+		if (f.Children && len(f.Fields) > 1) || (!f.Children && len(f.Fields) == 0) {
+			return errors.NewBadParameterError(fields, f.Fields).Expected("at most one field of the children or at least one field of the work item")
+		}
+	case FormulaDaysSince:
+		if f.Children || len(f.Fields) != 1 {
+			return errors.NewBadParameterError(fields, f.Fields).Expected("one instant field of the work item")
+		}
+	default:
+		return errors.NewBadParameterError(fmt.Sprintf("formula function of field %q", name), f.Function).Expected("sum, min, max, avg, count or days_since")
+	}
+	return nil
+}
+
+// ComputedType describes a field whose value is derived from other fields of
+// the work item or of its children. The value is stored with the work item
+// whenever the work item or one of its children changes so that it can be
+// searched for like any other value. Values that change as time passes, like
+// "days_since", are evaluated again when the work item is read; their stored
+// values are outdated, so work items can't be filtered or sorted by them.
+type ComputedType struct {
+	SimpleType `json:"simple_type"`
+	ResultType SimpleType `json:"result_type"`
+	Formula    Formula    `json:"formula"`
+}
+
+// Ensure ComputedType implements the FieldType interface
+var _ FieldType = ComputedType{}
+var _ FieldType = (*ComputedType)(nil)
+
+// Ensure ComputedType implements the Equaler interface
+var _ convert.Equaler = ComputedType{}
+var _ convert.Equaler = (*ComputedType)(nil)
+
+// DefaultValue implements FieldType
+func (t ComputedType) DefaultValue(value interface{}) (interface{}, error) {
+	return value, nil
+}
+
+// Equal returns true if two ComputedType objects are equal; otherwise false is
+// returned.
+func (t ComputedType) Equal(u convert.Equaler) bool {
+	other, ok := u.(ComputedType)
+	if !ok {
+		return false
+	}
+	if !t.SimpleType.Equal(other.SimpleType) {
+		return false
+	}
+	if !t.ResultType.Equal(other.ResultType) {
+		return false
+	}
+	return reflect.DeepEqual(t.Formula, other.Formula)
+}
+
+// dependsOnTime returns true if the value of the field changes as time passes
+// even though neither the work item nor its children change
+func (t ComputedType) dependsOnTime() bool {
+	return t.Formula.Function == FormulaDaysSince
+}
+
+// ConvertToModel implements the FieldType interface. It only accepts the
+// numbers that Compute returns because computed values can't be set.
+func (t ComputedType) ConvertToModel(value interface{}) (interface{}, error) {
+	return t.ResultType.ConvertToModel(value)
+}
+
+// ConvertFromModel implements the FieldType interface
+func (t ComputedType) ConvertFromModel(value interface{}) (interface{}, error) {
+	return t.ResultType.ConvertFromModel(value)
+}
+
+// Compute derives the value of the field from the stored fields of a work item
+// and of its children at the given time. The stored fields must contain the
+// creation and update time of the work item if the formula refers to them. The
+// result is nil if there is nothing to derive the value from; otherwise it is
+// returned in the persistence format of the result type.
+func (t ComputedType) Compute(fields Fields, children []Fields, now time.Time) (interface{}, error) {
+	var values []float64
+	if t.Formula.Children {
+		for _, child := range children {
+			if len(t.Formula.Fields) == 0 {
+				values = append(values, 1)
+				continue
+			}
+			if v, ok := numericValue(child[t.Formula.Fields[0]]); ok {
+				values = append(values, v)
+			}
+		}
+	} else {
+		for _, name := range t.Formula.Fields {
+			if v, ok := numericValue(fields[name]); ok {
+				values = append(values, v)
+			}
+		}
+	}
+	var result float64
+	switch t.Formula.Function {
+	case FormulaCount:
+		result = float64(len(values))
+	case FormulaDaysSince:
+		if len(values) == 0 {
+			return nil, nil
+		}
+		// instants are stored as nanoseconds since the epoch
+		result = math.Floor(now.Sub(time.Unix(0, int64(values[0]))).Hours() / 24)
+	case FormulaSum:
+		for _, v := range values {
+			result += v
+		}
+	case FormulaAvg:
+		if len(values) == 0 {
+			return nil, nil
+		}
+		for _, v := range values {
+			result += v
+		}
+		result = result / float64(len(values))
+	case FormulaMin, FormulaMax:
+		if len(values) == 0 {
+			return nil, nil
+		}
+		result = values[0]
+		for _, v := range values[1:] {
+			if (t.Formula.Function == FormulaMin && v < result) || (t.Formula.Function == FormulaMax && v > result) {
+				result = v
+			}
+		}
+	default:
+		return nil, errs.Errorf("unknown formula function: %s", t.Formula.Function)
+	}
+	if t.ResultType.GetKind() == KindInteger {
+		return t.ResultType.ConvertToModel(math.Floor(result + 0.5))
+	}
+	return t.ResultType.ConvertToModel(result)
+}
+
+// numericValue returns the given stored value as a float64 if it is a number
+func numericValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+// validateFormulas checks that computed fields which don't aggregate values of
+// the children derive their values from numbers or instants of the work item
+// that aren't computed themselves.
+func (wit WorkItemType) validateFormulas() error {
+	for name, def := range wit.Fields {
+		t, ok := def.computedType()
+		if !ok || t.Formula.Children {
+			continue
+		}
+		param := fmt.Sprintf("formula fields of field %q", name)
+		for _, field := range t.Formula.Fields {
+			other, ok := wit.Fields[field]
+			if !ok {
+				return errors.NewBadParameterError(param, field).Expected("field of work item type " + wit.Name)
+			}
+			if other.IsComputed() {
+				return errors.NewBadParameterError(param, field).Expected("field which isn't computed")
+			}
+			kind := other.Type.GetKind()
+			if t.Formula.Function == FormulaDaysSince && kind != KindInstant {
+				return errors.NewBadParameterError(param, field).Expected("instant field")
+			}
+			if t.Formula.Function != FormulaDaysSince && kind != KindInteger && kind != KindFloat && kind != KindDuration {
+				return errors.NewBadParameterError(param, field).Expected("integer, float or duration field")
+			}
+		}
+	}
+	return nil
+}
+
+// computedType returns the type of the field if it is a computed field
+func (f FieldDefinition) computedType() (ComputedType, bool) {
+	switch t := f.Type.(type) {
+	case ComputedType:
+		return t, true
+	case *ComputedType:
+		return *t, true
+	}
+	return ComputedType{}, false
+}
+
+// DependsOnTime returns true if the value of the field changes as time passes
+// even though the work item doesn't change. Work items can't be filtered or
+// sorted by such a field because its stored value is outdated.
+func (f FieldDefinition) DependsOnTime() bool {
+	t, ok := f.computedType()
+	return ok && t.dependsOnTime()
+}
+
+// IsComputed returns true if the value of the field is derived from other
+// values and therefore can't be set.
+func (f FieldDefinition) IsComputed() bool {
+	_, ok := f.computedType()
+	return ok
+}
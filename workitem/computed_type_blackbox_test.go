@@ -0,0 +1,164 @@
+package workitem_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/convert"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func newComputedType(resultKind workitem.Kind, function workitem.FormulaFunction, children bool, fields ...string) workitem.ComputedType {
+	return workitem.ComputedType{
+		SimpleType: workitem.SimpleType{Kind: workitem.KindComputed},
+		ResultType: workitem.SimpleType{Kind: resultKind},
+		Formula: workitem.Formula{
+			Function: function,
+			Fields:   fields,
+			Children: children,
+		},
+	}
+}
+
+func TestComputedType_Equal(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	a := newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points")
+
+	t.Run("type inequality", func(t *testing.T) {
+		require.False(t, a.Equal(convert.DummyEqualer{}))
+	})
+	t.Run("equal", func(t *testing.T) {
+		require.True(t, a.Equal(newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points")))
+	})
+	t.Run("result type difference", func(t *testing.T) {
+		require.False(t, a.Equal(newComputedType(workitem.KindInteger, workitem.FormulaSum, true, "points")))
+	})
+	t.Run("formula difference", func(t *testing.T) {
+		require.False(t, a.Equal(newComputedType(workitem.KindFloat, workitem.FormulaMax, true, "points")))
+		require.False(t, a.Equal(newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "points")))
+		require.False(t, a.Equal(newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "effort")))
+	})
+}
+
+func TestComputedType_Compute(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	now := time.Date(2018, 6, 10, 12, 0, 0, 0, time.UTC)
+	fields := workitem.Fields{
+		"estimate":               3.0,
+		"spent":                  2,
+		workitem.SystemCreatedAt: float64(now.Add(-50 * time.Hour).UnixNano()),
+	}
+	children := []workitem.Fields{
+		{"points": 1.0},
+		{"points": 2.5},
+		{"title": "no points"},
+	}
+
+	tests := []struct {
+		name     string
+		typ      workitem.ComputedType
+		expected interface{}
+	}{
+		{"sum of own fields", newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "estimate", "spent"), 5.0},
+		{"days since", newComputedType(workitem.KindInteger, workitem.FormulaDaysSince, false, workitem.SystemCreatedAt), 2},
+		{"days since without value", newComputedType(workitem.KindInteger, workitem.FormulaDaysSince, false, "unknown"), nil},
+		{"sum of children", newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points"), 3.5},
+		{"rounded sum of children", newComputedType(workitem.KindInteger, workitem.FormulaSum, true, "points"), 4},
+		{"min of children", newComputedType(workitem.KindFloat, workitem.FormulaMin, true, "points"), 1.0},
+		{"max of children", newComputedType(workitem.KindFloat, workitem.FormulaMax, true, "points"), 2.5},
+		{"avg of children", newComputedType(workitem.KindFloat, workitem.FormulaAvg, true, "points"), 1.75},
+		{"count of children", newComputedType(workitem.KindInteger, workitem.FormulaCount, true), 3},
+		{"count of children with value", newComputedType(workitem.KindInteger, workitem.FormulaCount, true, "points"), 2},
+		{"max without children", newComputedType(workitem.KindFloat, workitem.FormulaMax, true, "unknown"), nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value, err := test.typ.Compute(fields, children, now)
+			require.NoError(t, err)
+			require.Equal(t, test.expected, value)
+		})
+	}
+}
+
+func TestComputedType_Validate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	valid := map[string]workitem.ComputedType{
+		"sum of children":    newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points"),
+		"count of children":  newComputedType(workitem.KindInteger, workitem.FormulaCount, true),
+		"sum of own fields":  newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "estimate", "spent"),
+		"days since created": newComputedType(workitem.KindInteger, workitem.FormulaDaysSince, false, workitem.SystemCreatedAt),
+	}
+	for name, typ := range valid {
+		t.Run("valid "+name, func(t *testing.T) {
+			require.NoError(t, workitem.FieldDefinition{Label: name, Type: typ}.Validate("computed"))
+		})
+	}
+	invalid := map[string]workitem.ComputedType{
+		"string result":          newComputedType(workitem.KindString, workitem.FormulaSum, true, "points"),
+		"unknown function":       newComputedType(workitem.KindFloat, "median", true, "points"),
+		"sum without fields":     newComputedType(workitem.KindFloat, workitem.FormulaSum, true),
+		"sum of two children":    newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points", "effort"),
+		"days since of children": newComputedType(workitem.KindInteger, workitem.FormulaDaysSince, true, workitem.SystemCreatedAt),
+		"refers to itself":       newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "computed"),
+	}
+	for name, typ := range invalid {
+		t.Run("invalid "+name, func(t *testing.T) {
+			err := workitem.FieldDefinition{Label: name, Type: typ}.Validate("computed")
+			require.Error(t, err)
+			require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+	}
+
+	t.Run("fields of the work item type", func(t *testing.T) {
+		wit := workitem.WorkItemType{
+			Name:            "story",
+			SpaceTemplateID: uuid.NewV4(),
+			Fields: workitem.FieldDefinitions{
+				"estimate": {Label: "Estimate", Type: workitem.SimpleType{Kind: workitem.KindFloat}},
+				"title":    {Label: "Title", Type: workitem.SimpleType{Kind: workitem.KindString}},
+			},
+		}
+		check := func(typ workitem.ComputedType) error {
+			w := wit
+			w.Fields = workitem.FieldDefinitions{"computed": {Label: "Computed", Type: typ}}
+			for name, def := range wit.Fields {
+				w.Fields[name] = def
+			}
+			return w.Validate()
+		}
+		require.NoError(t, check(newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "estimate")))
+		require.Error(t, check(newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "unknown")))
+		require.Error(t, check(newComputedType(workitem.KindFloat, workitem.FormulaSum, false, "title")))
+		require.Error(t, check(newComputedType(workitem.KindInteger, workitem.FormulaDaysSince, false, "estimate")))
+	})
+}
+
+func TestComputedType_JSON(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	def := workitem.FieldDefinition{
+		Label:       "Total points",
+		Description: "The sum of the children's points",
+		ReadOnly:    true,
+		Type:        newComputedType(workitem.KindFloat, workitem.FormulaSum, true, "points"),
+	}
+	bytes, err := json.Marshal(def)
+	require.NoError(t, err)
+	var loaded workitem.FieldDefinition
+	require.NoError(t, json.Unmarshal(bytes, &loaded))
+	require.True(t, def.Equal(loaded))
+	require.True(t, loaded.IsComputed())
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/rendering"
 	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
 )
 
 // APIStringTypeEvents represent the type of event
@@ -22,6 +23,10 @@ const APIStringTypeEvents = "events"
 type Repository interface {
 	//repository.Exister
 	List(ctx context.Context, wiID uuid.UUID) ([]Event, error)
+	// History returns the differences of a work item between the revisions
+	// written for the given work item versions. When from is nil the first
+	// revision is used, when to is nil the latest one.
+	History(ctx context.Context, wiID uuid.UUID, from, to *int) (*History, error)
 }
 
 // NewEventRepository creates a work item event repository based on gorm
@@ -32,6 +37,7 @@ func NewEventRepository(db *gorm.DB) *GormEventRepository {
 		wiRevisionRepo:   workitem.NewRevisionRepository(db),
 		workItemTypeRepo: workitem.NewWorkItemTypeRepository(db),
 		identityRepo:     account.NewIdentityRepository(db),
+		linkRevisionRepo: link.NewRevisionRepository(db),
 	}
 }
 
@@ -42,6 +48,7 @@ type GormEventRepository struct {
 	wiRevisionRepo   *workitem.GormRevisionRepository
 	workItemTypeRepo *workitem.GormWorkItemTypeRepository
 	identityRepo     *account.GormIdentityRepository
+	linkRevisionRepo *link.GormWorkItemLinkRevisionRepository
 }
 
 // List return the events
@@ -228,3 +235,54 @@ func (r *GormEventRepository) List(ctx context.Context, wiID uuid.UUID) ([]Event
 	}
 	return eventList, nil
 }
+
+// History returns the differences of a work item between two of its revisions
+func (r *GormEventRepository) History(ctx context.Context, wiID uuid.UUID, from, to *int) (*History, error) {
+	revisionList, err := r.wiRevisionRepo.List(ctx, wiID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "error during fetching work item history")
+	}
+	if len(revisionList) == 0 {
+		return nil, errors.NewNotFoundError("work item", wiID.String())
+	}
+	fromRevision := revisionList[0]
+	toRevision := revisionList[len(revisionList)-1]
+	if from != nil {
+		rev, err := r.wiRevisionRepo.LoadByVersion(ctx, wiID, *from)
+		if err != nil {
+			return nil, errs.Wrapf(err, "error during fetching work item history")
+		}
+		fromRevision = *rev
+	}
+	if to != nil {
+		rev, err := r.wiRevisionRepo.LoadByVersion(ctx, wiID, *to)
+		if err != nil {
+			return nil, errs.Wrapf(err, "error during fetching work item history")
+		}
+		toRevision = *rev
+	}
+	if fromRevision.Time.After(toRevision.Time) {
+		return nil, errors.NewBadParameterError("from", fromRevision.WorkItemVersion).Expected(fmt.Sprintf("version not after %d", toRevision.WorkItemVersion))
+	}
+	fromType, err := r.workItemTypeRepo.Load(ctx, fromRevision.WorkItemTypeID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "error during fetching work item history")
+	}
+	toType, err := r.workItemTypeRepo.Load(ctx, toRevision.WorkItemTypeID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "error during fetching work item history")
+	}
+	linkRevisions, err := r.linkRevisionRepo.ListByWorkItem(ctx, wiID)
+	if err != nil {
+		return nil, errs.Wrapf(err, "error during fetching work item history")
+	}
+	return &History{
+		WorkItemID:  wiID,
+		FromVersion: fromRevision.WorkItemVersion,
+		ToVersion:   toRevision.WorkItemVersion,
+		FromTime:    fromRevision.Time,
+		ToTime:      toRevision.Time,
+		Fields:      DiffFields(*fromType, *toType, fromRevision.WorkItemFields, toRevision.WorkItemFields),
+		Links:       DiffLinks(linkRevisions, fromRevision.Time, toRevision.Time),
+	}, nil
+}
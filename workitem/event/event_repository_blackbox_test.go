@@ -10,6 +10,7 @@ import (
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/event"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
@@ -384,3 +385,70 @@ func (s *eventRepoBlackBoxTest) TestList() {
 		assert.Equal(t, 2, c)
 	})
 }
+
+func (s *eventRepoBlackBoxTest) TestHistory() {
+	s.T().Run("fields", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemLabels] = []string{"label1", "label2"}
+			fxt.WorkItems[idx].Fields[workitem.SystemDescription] = rendering.NewMarkupContentFromLegacy("description1")
+			return nil
+		}))
+		fxt.WorkItems[0].Fields[workitem.SystemLabels] = []string{"label2", "label3"}
+		fxt.WorkItems[0].Fields[workitem.SystemDescription] = rendering.NewMarkupContent("description2", rendering.SystemMarkupMarkdown)
+		wiNew, err := s.wiRepo.Save(s.Ctx, fxt.WorkItems[0].SpaceID, *fxt.WorkItems[0], fxt.Identities[0].ID)
+		require.NoError(t, err)
+		wiNew.Fields[workitem.SystemState] = workitem.SystemStateResolved
+		_, err = s.wiRepo.Save(s.Ctx, fxt.WorkItems[0].SpaceID, *wiNew, fxt.Identities[0].ID)
+		require.NoError(t, err)
+
+		history, err := s.wiEventRepo.History(s.Ctx, fxt.WorkItems[0].ID, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, fxt.WorkItems[0].Version, history.FromVersion)
+		assert.Equal(t, fxt.WorkItems[0].Version+2, history.ToVersion)
+		require.Len(t, history.Fields, 3)
+		assert.Equal(t, workitem.SystemDescription, history.Fields[0].Name)
+		assert.Equal(t, rendering.NewMarkupContentFromLegacy("description1"), history.Fields[0].Old)
+		assert.Equal(t, rendering.NewMarkupContent("description2", rendering.SystemMarkupMarkdown), history.Fields[0].New)
+		assert.Equal(t, workitem.SystemLabels, history.Fields[1].Name)
+		assert.Equal(t, []interface{}{"label3"}, history.Fields[1].Added)
+		assert.Equal(t, []interface{}{"label1"}, history.Fields[1].Removed)
+		assert.Equal(t, workitem.SystemState, history.Fields[2].Name)
+		assert.Equal(t, workitem.SystemStateResolved, history.Fields[2].New)
+
+		t.Run("between given versions", func(t *testing.T) {
+			from := fxt.WorkItems[0].Version + 1
+			history, err := s.wiEventRepo.History(s.Ctx, fxt.WorkItems[0].ID, &from, nil)
+			require.NoError(t, err)
+			require.Len(t, history.Fields, 1)
+			assert.Equal(t, workitem.SystemState, history.Fields[0].Name)
+		})
+		t.Run("unknown version", func(t *testing.T) {
+			to := 42
+			_, err := s.wiEventRepo.History(s.Ctx, fxt.WorkItems[0].ID, nil, &to)
+			require.Error(t, err)
+		})
+		t.Run("from after to", func(t *testing.T) {
+			from := fxt.WorkItems[0].Version + 2
+			to := fxt.WorkItems[0].Version
+			_, err := s.wiEventRepo.History(s.Ctx, fxt.WorkItems[0].ID, &from, &to)
+			require.Error(t, err)
+		})
+	})
+
+	s.T().Run("links", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(2), tf.WorkItemLinkTypes(1))
+		linkRepo := link.NewWorkItemLinkRepository(s.DB)
+		l, err := linkRepo.Create(s.Ctx, fxt.WorkItems[0].ID, fxt.WorkItems[1].ID, fxt.WorkItemLinkTypes[0].ID, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		fxt.WorkItems[0].Fields[workitem.SystemState] = workitem.SystemStateResolved
+		_, err = s.wiRepo.Save(s.Ctx, fxt.WorkItems[0].SpaceID, *fxt.WorkItems[0], fxt.Identities[0].ID)
+		require.NoError(t, err)
+
+		history, err := s.wiEventRepo.History(s.Ctx, fxt.WorkItems[0].ID, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, history.Links, 1)
+		assert.Equal(t, l.ID, history.Links[0].LinkID)
+		assert.Equal(t, event.LinkAdded, history.Links[0].Change)
+		assert.Equal(t, fxt.WorkItems[1].ID, history.Links[0].TargetID)
+	})
+}
@@ -0,0 +1,223 @@
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/fabric8-services/fabric8-wit/rendering"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+)
+
+// APIStringTypeHistory represent the type of a work item history diff
+const APIStringTypeHistory = "workitemhistory"
+
+// Kinds of link changes
+const (
+	LinkAdded   = "added"
+	LinkRemoved = "removed"
+)
+
+// FieldChange describes how the value of a single work item field differs
+// between two revisions
+type FieldChange struct {
+	Name string
+	// Old and New hold the complete values. Markup fields are given as
+	// rendering.MarkupContent.
+	Old interface{}
+	New interface{}
+	// Added and Removed hold the elements of a list field that only appear in
+	// the new or in the old revision respectively
+	Added   []interface{}
+	Removed []interface{}
+}
+
+// LinkChange describes a link of the work item that was created or removed
+// between two revisions
+type LinkChange struct {
+	LinkID     uuid.UUID
+	LinkTypeID uuid.UUID
+	SourceID   uuid.UUID
+	TargetID   uuid.UUID
+	// Change is either LinkAdded or LinkRemoved
+	Change    string
+	Timestamp time.Time
+	Modifier  uuid.UUID
+}
+
+// History represents the differences of a work item between two of its
+// revisions
+type History struct {
+	WorkItemID  uuid.UUID
+	FromVersion int
+	ToVersion   int
+	FromTime    time.Time
+	ToTime      time.Time
+	Fields      []FieldChange
+	Links       []LinkChange
+}
+
+// GetETagData returns the field values to use to generate the ETag
+func (h History) GetETagData() []interface{} {
+	return []interface{}{h.WorkItemID, h.FromVersion, h.ToVersion}
+}
+
+// GetLastModified returns the last modification time
+func (h History) GetLastModified() time.Time {
+	return h.ToTime.Truncate(time.Second)
+}
+
+// DiffFields compares the field values of two revisions for every field of
+// the given work item types. Fields whose values are equal are omitted and the
+// changes are sorted by field name.
+func DiffFields(fromType, toType workitem.WorkItemType, from, to workitem.Fields) []FieldChange {
+	fieldDefs := map[string]workitem.FieldDefinition{}
+	for name, def := range fromType.Fields {
+		fieldDefs[name] = def
+	}
+	for name, def := range toType.Fields {
+		fieldDefs[name] = def
+	}
+	names := make([]string, 0, len(fieldDefs))
+	for name := range fieldDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := []FieldChange{}
+	for _, name := range names {
+		oldValue := from[name]
+		newValue := to[name]
+		switch fieldType := fieldDefs[name].Type.(type) {
+		case workitem.ListType:
+			o := toList(oldValue)
+			n := toList(newValue)
+			added := listDifference(n, o)
+			removed := listDifference(o, n)
+			if len(added) > 0 || len(removed) > 0 {
+				changes = append(changes, FieldChange{
+					Name:    name,
+					Old:     o,
+					New:     n,
+					Added:   added,
+					Removed: removed,
+				})
+			}
+		case workitem.SimpleType:
+			if fieldType.Kind == workitem.KindMarkup {
+				o := toMarkup(oldValue)
+				n := toMarkup(newValue)
+				if o != n {
+					changes = append(changes, FieldChange{Name: name, Old: o, New: n})
+				}
+				continue
+			}
+			if !reflect.DeepEqual(oldValue, newValue) {
+				changes = append(changes, FieldChange{Name: name, Old: oldValue, New: newValue})
+			}
+		default:
+			if !reflect.DeepEqual(oldValue, newValue) {
+				changes = append(changes, FieldChange{Name: name, Old: oldValue, New: newValue})
+			}
+		}
+	}
+	return changes
+}
+
+// DiffLinks returns the links of the given work item that exist at one of the
+// given points in time but not at the other. The link revisions must be
+// sorted by time.
+func DiffLinks(revisions []link.Revision, from, to time.Time) []LinkChange {
+	type state struct {
+		existsBefore bool
+		existsAfter  bool
+		last         link.Revision
+	}
+	states := map[uuid.UUID]*state{}
+	order := []uuid.UUID{}
+	for _, rev := range revisions {
+		if rev.Time.After(to) {
+			break
+		}
+		s, ok := states[rev.WorkItemLinkID]
+		if !ok {
+			s = &state{}
+			states[rev.WorkItemLinkID] = s
+			order = append(order, rev.WorkItemLinkID)
+		}
+		exists := rev.Type != link.RevisionTypeDelete
+		if !rev.Time.After(from) {
+			s.existsBefore = exists
+		}
+		s.existsAfter = exists
+		s.last = rev
+	}
+	changes := []LinkChange{}
+	for _, id := range order {
+		s := states[id]
+		if s.existsBefore == s.existsAfter {
+			continue
+		}
+		c := LinkChange{
+			LinkID:     id,
+			LinkTypeID: s.last.WorkItemLinkTypeID,
+			SourceID:   s.last.WorkItemLinkSourceID,
+			TargetID:   s.last.WorkItemLinkTargetID,
+			Change:     LinkAdded,
+			Timestamp:  s.last.Time,
+			Modifier:   s.last.ModifierIdentity,
+		}
+		if s.existsBefore {
+			c.Change = LinkRemoved
+		}
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// toList converts a stored list field value into a slice
+func toList(v interface{}) []interface{} {
+	res := []interface{}{}
+	switch l := v.(type) {
+	case []interface{}:
+		res = append(res, l...)
+	case []string:
+		for _, e := range l {
+			res = append(res, e)
+		}
+	case nil:
+	default:
+		res = append(res, l)
+	}
+	return res
+}
+
+// listDifference returns the elements of a that are not in b
+func listDifference(a, b []interface{}) []interface{} {
+	seen := map[string]struct{}{}
+	for _, e := range b {
+		seen[fmt.Sprint(e)] = struct{}{}
+	}
+	res := []interface{}{}
+	for _, e := range a {
+		if _, ok := seen[fmt.Sprint(e)]; !ok {
+			res = append(res, e)
+		}
+	}
+	return res
+}
+
+// toMarkup converts a stored markup field value into markup content
+func toMarkup(v interface{}) rendering.MarkupContent {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return rendering.NewMarkupContentFromMap(m)
+	case string:
+		return rendering.NewMarkupContentFromLegacy(m)
+	}
+	return rendering.MarkupContent{}
+}
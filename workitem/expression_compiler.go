@@ -2,8 +2,10 @@ package workitem
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fabric8-services/fabric8-wit/criteria"
 	errs "github.com/pkg/errors"
@@ -12,8 +14,28 @@ import (
 
 const (
 	jsonAnnotation = "JSON"
+	// jsonFieldAnnotation marks field expressions that refer to a field in the
+	// jsonb "fields" column regardless of their name (see JSONField)
+	jsonFieldAnnotation = "JSONField"
 )
 
+// JSONField constructs a field expression which refers to a field in the jsonb
+// "fields" column. Field names without a dot (e.g. the custom field "effort")
+// would otherwise be taken as columns of the work_items table.
+func JSONField(name string) criteria.Expression {
+	f := criteria.Field(name)
+	f.SetAnnotation(jsonFieldAnnotation, true)
+	return f
+}
+
+// JSONFieldIsNull constructs an IsNull expression for a field in the jsonb
+// "fields" column (see JSONField).
+func JSONFieldIsNull(name string) criteria.Expression {
+	e := criteria.IsNull(name)
+	e.SetAnnotation(jsonFieldAnnotation, true)
+	return e
+}
+
 // Compile takes an expression and compiles it to a where clause for use with
 // gorm.DB.Where(). Returns the number of expected parameters for the query and a
 // slice of errors if something goes wrong.
@@ -55,7 +77,7 @@ func bubbleUpJSONContext(c *expressionCompiler) func(exp criteria.Expression) bo
 	return func(exp criteria.Expression) bool {
 		switch t := exp.(type) {
 		case *criteria.FieldExpression:
-			_, isJSONField := c.getFieldName(t.FieldName)
+			_, isJSONField := c.fieldName(t, t.FieldName)
 			if isJSONField {
 				t.SetAnnotation(jsonAnnotation, true)
 			}
@@ -87,11 +109,22 @@ func Column(table, column string) string {
 // NOTE: anything not listed here will be treated as if it is nested inside the
 // jsonb "fields" column.
 var fieldMap = map[string]string{
-	"ID":      "id",
-	"Type":    "type",
-	"Version": "version",
-	"Number":  "number",
-	"SpaceID": "space_id",
+	"ID":        "id",
+	"Type":      "type",
+	"Version":   "version",
+	"Number":    "number",
+	"SpaceID":   "space_id",
+	"CreatedAt": "created_at",
+	"UpdatedAt": "updated_at",
+}
+
+// fieldName returns the result of getFieldName for the field name of the
+// given expression unless the expression is marked as a JSON field.
+func (c *expressionCompiler) fieldName(e criteria.Expression, fieldName string) (mappedFieldName string, isJSONField bool) {
+	if e.Annotation(jsonFieldAnnotation) == true {
+		return fieldName, true
+	}
+	return c.getFieldName(fieldName)
 }
 
 // getFieldName applies any potentially necessary mapping to field names (e.g.
@@ -259,7 +292,7 @@ func (c *expressionCompiler) Field(f *criteria.FieldExpression) interface{} {
 		return nil
 	}
 
-	mappedFieldName, isJSONField := c.getFieldName(f.FieldName)
+	mappedFieldName, isJSONField := c.fieldName(f, f.FieldName)
 
 	// Check if this field is referencing joinable data
 	for _, j := range c.joins {
@@ -371,7 +404,7 @@ func (c *expressionCompiler) Substring(e *criteria.SubstringExpression) interfac
 }
 
 func (c *expressionCompiler) IsNull(e *criteria.IsNullExpression) interface{} {
-	mappedFieldName, isJSONField := c.getFieldName(e.FieldName)
+	mappedFieldName, isJSONField := c.fieldName(e, e.FieldName)
 	if isJSONField {
 		return "(" + Column(WorkItemStorage{}.TableName(), "fields") + "->>'" + mappedFieldName + "' IS NULL)"
 	}
@@ -394,6 +427,168 @@ func (c *expressionCompiler) Not(e *criteria.NotExpression) interface{} {
 	return c.binary(e, "!=")
 }
 
+func (c *expressionCompiler) Greater(e *criteria.GreaterExpression) interface{} {
+	return c.comparison(e, ">")
+}
+
+func (c *expressionCompiler) GreaterOrEquals(e *criteria.GreaterOrEqualsExpression) interface{} {
+	return c.comparison(e, ">=")
+}
+
+func (c *expressionCompiler) Less(e *criteria.LessExpression) interface{} {
+	return c.comparison(e, "<")
+}
+
+func (c *expressionCompiler) LessOrEquals(e *criteria.LessOrEqualsExpression) interface{} {
+	return c.comparison(e, "<=")
+}
+
+// comparison compiles one of the ordering operators (e.g. ">" or "<="). The
+// left side must be a field and the right side must be a literal. Values of
+// JSON fields are extracted as text from the jsonb column and then cast to an
+// SQL type that matches the kind of the literal (see jsonComparable).
+func (c *expressionCompiler) comparison(e criteria.BinaryExpression, op string) interface{} {
+	left, lit := c.fieldAndLiteral(e)
+	if left == nil || lit == nil {
+		return nil
+	}
+	col, isJSONField := c.comparisonColumn(left)
+	if col == "" {
+		return nil
+	}
+	if !isJSONField {
+		c.parameters = append(c.parameters, lit.Value)
+		return "(" + col + " " + op + " ?)"
+	}
+	kind, val, err := jsonComparable(lit.Value)
+	if err != nil {
+		c.err = append(c.err, errs.Wrapf(err, `failed to compare field "%s"`, left.FieldName))
+		return nil
+	}
+	c.parameters = append(c.parameters, val)
+	return "(" + jsonFieldAs(col, kind) + " " + op + " ?)"
+}
+
+// In compiles the set membership operator. The right side must be a literal
+// holding a non-empty slice of values.
+func (c *expressionCompiler) In(e *criteria.InExpression) interface{} {
+	left, lit := c.fieldAndLiteral(e)
+	if left == nil || lit == nil {
+		return nil
+	}
+	values := reflect.ValueOf(lit.Value)
+	if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+		c.err = append(c.err, errs.Errorf("value of right literal expression must be a slice: %+v", lit.Value))
+		return nil
+	}
+	if values.Len() == 0 {
+		c.err = append(c.err, errs.Errorf(`no values given for "%s"`, left.FieldName))
+		return nil
+	}
+	col, isJSONField := c.comparisonColumn(left)
+	if col == "" {
+		return nil
+	}
+	placeholders := make([]string, values.Len())
+	var kind Kind
+	for i := 0; i < values.Len(); i++ {
+		placeholders[i] = "?"
+		v := values.Index(i).Interface()
+		if !isJSONField {
+			c.parameters = append(c.parameters, v)
+			continue
+		}
+		k, val, err := jsonComparable(v)
+		if err != nil {
+			c.err = append(c.err, errs.Wrapf(err, `failed to compare field "%s"`, left.FieldName))
+			return nil
+		}
+		if i > 0 && k != kind {
+			c.err = append(c.err, errs.Errorf(`values for "%s" must all be of the same kind: %+v`, left.FieldName, lit.Value))
+			return nil
+		}
+		kind = k
+		c.parameters = append(c.parameters, val)
+	}
+	if isJSONField {
+		col = jsonFieldAs(col, kind)
+	}
+	return "(" + col + " IN (" + strings.Join(placeholders, ",") + "))"
+}
+
+// fieldAndLiteral returns the left side of the given binary expression as a
+// field expression and the right side as a literal expression. If that isn't
+// possible an error is recorded and nil is returned.
+func (c *expressionCompiler) fieldAndLiteral(e criteria.BinaryExpression) (*criteria.FieldExpression, *criteria.LiteralExpression) {
+	left, ok := e.Left().(*criteria.FieldExpression)
+	if !ok {
+		c.err = append(c.err, errs.Errorf("invalid left expression (not a field expression): %+v", e.Left()))
+		return nil, nil
+	}
+	lit, ok := e.Right().(*criteria.LiteralExpression)
+	if !ok {
+		c.err = append(c.err, errs.Errorf("failed to convert right expression to literal expression: %+v", e.Right()))
+		return nil, nil
+	}
+	return left, lit
+}
+
+// comparisonColumn returns the column (or the JSON field name) to compare
+// against for the given field expression and tells if it is a JSON field. An
+// empty column is returned when the field cannot be used.
+func (c *expressionCompiler) comparisonColumn(f *criteria.FieldExpression) (string, bool) {
+	if strings.ContainsAny(f.FieldName, `"'`) {
+		c.err = append(c.err, errs.Errorf("field name must not contain quotes: %s", f.FieldName))
+		return "", false
+	}
+	if join, ok := c.expressionRefersToJoinedData(f); ok {
+		col, err := join.TranslateFieldName(f.FieldName)
+		if err != nil {
+			c.err = append(c.err, errs.Wrapf(err, `failed to translate field name: "%s"`, f.FieldName))
+			return "", false
+		}
+		return col, false
+	}
+	return c.fieldName(f, f.FieldName)
+}
+
+// jsonComparable converts a literal value into the representation that is used
+// for it inside the jsonb "fields" column and returns the kind of field the
+// value can be compared with (e.g. instants are stored as nanoseconds since
+// the epoch).
+func jsonComparable(value interface{}) (Kind, interface{}, error) {
+	switch t := value.(type) {
+	case time.Time:
+		return KindInstant, t.UnixNano(), nil
+	case time.Duration:
+		return KindDuration, int64(t), nil
+	case int:
+		return KindInteger, int64(t), nil
+	case int64:
+		return KindInteger, t, nil
+	case float64:
+		return KindFloat, t, nil
+	case string:
+		return KindString, t, nil
+	default:
+		return "", nil, errs.Errorf(`unknown value type "%T": %+v`, value, value)
+	}
+}
+
+// jsonFieldAs returns an SQL expression that extracts the given field from the
+// jsonb "fields" column and casts it to a type suitable for the given kind.
+func jsonFieldAs(fieldName string, kind Kind) string {
+	field := Column(WorkItemStorage{}.TableName(), "fields") + "->>'" + fieldName + "'"
+	switch kind {
+	case KindInstant, KindInteger, KindDuration:
+		return "(" + field + ")::bigint"
+	case KindFloat:
+		return "(" + field + ")::numeric"
+	default:
+		return field
+	}
+}
+
 func (c *expressionCompiler) Parameter(v *criteria.ParameterExpression) interface{} {
 	c.err = append(c.err, errs.Errorf("parameter expression not supported"))
 	return nil
@@ -2,6 +2,7 @@ package workitem_test
 
 import (
 	"testing"
+	"time"
 
 	c "github.com/fabric8-services/fabric8-wit/criteria"
 	"github.com/fabric8-services/fabric8-wit/resource"
@@ -156,6 +157,16 @@ func TestIsNull(t *testing.T) {
 	expect(t, c.IsNull("SpaceID"), `(`+workitem.Column(wiTbl, "space_id")+` IS NULL)`, []interface{}{}, nil)
 }
 
+func TestJSONField(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	fields := workitem.Column(workitem.WorkItemStorage{}.TableName(), "fields")
+	expect(t, c.Equals(workitem.JSONField("effort"), c.Literal(3.5)), `(`+fields+` @> '{"effort" : 3.5}')`, []interface{}{}, nil)
+	expect(t, c.Greater(workitem.JSONField("effort"), c.Literal(3.5)), `((`+fields+`->>'effort')::numeric > ?)`, []interface{}{3.5}, nil)
+	expect(t, c.In(workitem.JSONField("severity"), c.Literal([]string{"high", "low"})), `(`+fields+`->>'severity' IN (?,?))`, []interface{}{"high", "low"}, nil)
+	expect(t, workitem.JSONFieldIsNull("effort"), `(`+fields+`->>'effort' IS NULL)`, []interface{}{}, nil)
+}
+
 func expect(t *testing.T, expr c.Expression, expectedClause string, expectedParameters []interface{}, expectedJoins []*workitem.TableJoin) {
 	clause, parameters, joins, compileErrors := workitem.Compile(expr)
 	t.Run("check for compile errors", func(t *testing.T) {
@@ -213,3 +224,78 @@ func TestSubstring(t *testing.T) {
 		assert.Equal(t, "", where)
 	})
 }
+
+func TestComparison(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	wiTbl := workitem.WorkItemStorage{}.TableName()
+	fields := workitem.Column(wiTbl, "fields")
+	t.Run("column", func(t *testing.T) {
+		now := time.Now()
+		expect(t, c.Greater(c.Field("CreatedAt"), c.Literal(now)), `(`+workitem.Column(wiTbl, "created_at")+` > ?)`, []interface{}{now}, nil)
+		expect(t, c.LessOrEquals(c.Field("Number"), c.Literal(42)), `(`+workitem.Column(wiTbl, "number")+` <= ?)`, []interface{}{42}, nil)
+	})
+	t.Run("instant", func(t *testing.T) {
+		now := time.Now()
+		expect(t, c.GreaterOrEquals(c.Field("system.target_date"), c.Literal(now)), `((`+fields+`->>'system.target_date')::bigint >= ?)`, []interface{}{now.UnixNano()}, nil)
+	})
+	t.Run("integer", func(t *testing.T) {
+		expect(t, c.Less(c.Field("system.remote_count"), c.Literal(5)), `((`+fields+`->>'system.remote_count')::bigint < ?)`, []interface{}{int64(5)}, nil)
+	})
+	t.Run("float", func(t *testing.T) {
+		expect(t, c.Greater(c.Field("system.storypoints"), c.Literal(2.5)), `((`+fields+`->>'system.storypoints')::numeric > ?)`, []interface{}{2.5}, nil)
+	})
+	t.Run("duration", func(t *testing.T) {
+		expect(t, c.LessOrEquals(c.Field("system.estimate"), c.Literal(2*time.Hour)), `((`+fields+`->>'system.estimate')::bigint <= ?)`, []interface{}{int64(2 * time.Hour)}, nil)
+	})
+	t.Run("string", func(t *testing.T) {
+		expect(t, c.Less(c.Field("system.title"), c.Literal("m")), `(`+fields+`->>'system.title' < ?)`, []interface{}{"m"}, nil)
+	})
+	t.Run("joined field", func(t *testing.T) {
+		j := *workitem.DefaultTableJoins()["iteration"]
+		j.Active = true
+		j.HandledFields = []string{"created_at"}
+		now := time.Now()
+		expect(t, c.Greater(c.Field("iteration.created_at"), c.Literal(now)), `(`+workitem.Column("iter", "created_at")+` > ?)`, []interface{}{now}, []*workitem.TableJoin{&j})
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Run("literal on left side", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.Greater(c.Literal(1), c.Field("system.storypoints")))
+			require.NotEmpty(t, compileErrors)
+		})
+		t.Run("single quote in field name", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.Greater(c.Field("system.title'"), c.Literal(1)))
+			require.NotEmpty(t, compileErrors)
+		})
+		t.Run("unsupported literal", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.Greater(c.Field("system.title"), c.Literal(true)))
+			require.NotEmpty(t, compileErrors)
+		})
+	})
+}
+
+func TestIn(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	wiTbl := workitem.WorkItemStorage{}.TableName()
+	t.Run("column", func(t *testing.T) {
+		expect(t, c.In(c.Field("Number"), c.Literal([]int{1, 2, 3})), `(`+workitem.Column(wiTbl, "number")+` IN (?,?,?))`, []interface{}{1, 2, 3}, nil)
+	})
+	t.Run("json field", func(t *testing.T) {
+		expect(t, c.In(c.Field("system.state"), c.Literal([]string{"new", "open"})), `(`+workitem.Column(wiTbl, "fields")+`->>'system.state' IN (?,?))`, []interface{}{"new", "open"}, nil)
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Run("empty slice", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.In(c.Field("system.state"), c.Literal([]string{})))
+			require.NotEmpty(t, compileErrors)
+		})
+		t.Run("no slice", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.In(c.Field("system.state"), c.Literal("new")))
+			require.NotEmpty(t, compileErrors)
+		})
+		t.Run("mixed kinds", func(t *testing.T) {
+			_, _, _, compileErrors := workitem.Compile(c.In(c.Field("system.state"), c.Literal([]interface{}{"new", 1.5})))
+			require.NotEmpty(t, compileErrors)
+		})
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/fabric8-services/fabric8-wit/convert"
+	"github.com/fabric8-services/fabric8-wit/errors"
 	errs "github.com/pkg/errors"
 )
 
@@ -29,14 +30,16 @@ const (
 	KindMarkup      Kind = "markup"
 	KindArea        Kind = "area"
 	KindCodebase    Kind = "codebase"
+	KindComputed    Kind = "computed"
 )
 
 // Kind is the kind of field type
 type Kind string
 
-// IsSimpleType returns 'true' if the kind is simple, i.e., not a list nor an enum
+// IsSimpleType returns 'true' if the kind is simple, i.e., not a list, an enum
+// nor a computed value
 func (k Kind) IsSimpleType() bool {
-	return k != KindEnum && k != KindList
+	return k != KindEnum && k != KindList && k != KindComputed
 }
 
 // String implements the Stringer interface and returns the kind as a string
@@ -99,6 +102,83 @@ func (f FieldDefinition) Equal(u convert.Equaler) bool {
 	return f.Type.Equal(other.Type)
 }
 
+// Validate checks that the field has a name and a type which can hold values.
+// The component type of a list and the base type of an enum must be simple
+// types and the values of an enum must be unique values of its base type.
+func (f FieldDefinition) Validate(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.NewBadParameterError("field name", name).Expected("not empty")
+	}
+	if f.Type == nil {
+		return errors.NewBadParameterError(fmt.Sprintf("type of field %q", name), nil).Expected("not nil")
+	}
+	if _, err := ConvertStringToKind(string(f.Type.GetKind())); err != nil {
+		return errors.NewBadParameterError(fmt.Sprintf("kind of field %q", name), f.Type.GetKind()).Expected("known kind")
+	}
+	fieldType := f.Type
+	switch t := fieldType.(type) {
+	case *ListType:
+		fieldType = *t
+	case *EnumType:
+		fieldType = *t
+	case *ComputedType:
+		fieldType = *t
+	}
+	switch t := fieldType.(type) {
+	case ListType:
+		if !t.ComponentType.GetKind().IsSimpleType() {
+			return errors.NewBadParameterError(fmt.Sprintf("component type of field %q", name), t.ComponentType.GetKind()).Expected("simple type")
+		}
+	case EnumType:
+		if !t.BaseType.GetKind().IsSimpleType() {
+			return errors.NewBadParameterError(fmt.Sprintf("base type of field %q", name), t.BaseType.GetKind()).Expected("simple type")
+		}
+		if len(t.Values) == 0 {
+			return errors.NewBadParameterError(fmt.Sprintf("values of field %q", name), t.Values).Expected("at least one value")
+		}
+		seen := map[interface{}]struct{}{}
+		for _, v := range t.Values {
+			converted, err := t.BaseType.ConvertToModel(v)
+			if err != nil {
+				return errors.NewBadParameterError(fmt.Sprintf("values of field %q", name), v).Expected(string(t.BaseType.GetKind()))
+			}
+			if _, ok := seen[converted]; ok {
+				return errors.NewBadParameterError(fmt.Sprintf("values of field %q", name), v).Expected("unique values")
+			}
+			seen[converted] = struct{}{}
+		}
+	case ComputedType:
+		return t.Formula.validate(name, t.ResultType)
+	default:
+		if !f.Type.GetKind().IsSimpleType() {
+			return errors.NewBadParameterError(fmt.Sprintf("type of field %q", name), f.Type.GetKind()).Expected("simple type")
+		}
+	}
+	return nil
+}
+
+// ValueKind returns the kind of the single values stored for the field: the
+// component type of a list, the base type of an enum and the result type of a
+// computed field. Other fields store values of their own kind.
+func (f FieldDefinition) ValueKind() Kind {
+	switch t := f.Type.(type) {
+	case ListType:
+		return t.ComponentType.GetKind()
+	case *ListType:
+		return t.ComponentType.GetKind()
+	case EnumType:
+		return t.BaseType.GetKind()
+	case *EnumType:
+		return t.BaseType.GetKind()
+	case ComputedType:
+		return t.ResultType.GetKind()
+	case *ComputedType:
+		return t.ResultType.GetKind()
+	default:
+		return f.Type.GetKind()
+	}
+}
+
 // ConvertToModel converts a field value for use in the persistence layer
 func (f FieldDefinition) ConvertToModel(name string, value interface{}) (interface{}, error) {
 	// Overwrite value if default value if none was provided
@@ -220,6 +300,13 @@ func (f *FieldDefinition) UnmarshalJSON(bytes []byte) error {
 			return errs.WithStack(err)
 		}
 		*f = FieldDefinition{Type: theType, Required: temp.Required, ReadOnly: temp.ReadOnly, Label: temp.Label, Description: temp.Description}
+	case KindComputed:
+		theType := ComputedType{}
+		err = json.Unmarshal(*temp.Type, &theType)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		*f = FieldDefinition{Type: theType, Required: temp.Required, ReadOnly: temp.ReadOnly, Label: temp.Label, Description: temp.Description}
 	default:
 		theType := SimpleType{}
 		err = json.Unmarshal(*temp.Type, &theType)
@@ -243,12 +330,24 @@ func ConvertAnyToKind(any interface{}) (*Kind, error) {
 func ConvertStringToKind(k string) (*Kind, error) {
 	kind := Kind(k)
 	switch kind {
-	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindUser, KindEnum, KindList, KindIteration, KindMarkup, KindArea, KindCodebase, KindLabel, KindBoardColumn, KindBoolean:
+	case KindString, KindInteger, KindFloat, KindInstant, KindDuration, KindURL, KindUser, KindEnum, KindList, KindIteration, KindMarkup, KindArea, KindCodebase, KindLabel, KindBoardColumn, KindBoolean, KindComputed:
 		return &kind, nil
 	}
 	return nil, errs.Errorf("kind '%s' is not a simple type", k)
 }
 
+// compatibleTypeChange returns true if values of the old field type are still
+// valid values of the new one. This is the case if the types are equal or if
+// the values of a new enum contain all the values of the old one.
+func compatibleTypeChange(old FieldType, new FieldType) bool {
+	if new.Equal(old) {
+		return true
+	}
+	oldEnum, ok1 := old.(EnumType)
+	newEnum, ok2 := new.(EnumType)
+	return ok1 && ok2 && newEnum.EqualEnclosing(oldEnum)
+}
+
 // compatibleFields returns true if the existing and new field are compatible;
 // otherwise false is returned. It does so by comparing all members of the field
 // definition except for the label and description.
@@ -180,3 +180,45 @@ func TestListTypeConversion(t *testing.T) {
 		}
 	}
 }
+
+func TestFieldDefinitionValidate(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+
+	valid := map[string]FieldDefinition{
+		"simple":  {Label: "simple", Type: stString},
+		"list":    {Label: "list", Type: ListType{SimpleType: stList, ComponentType: stString}},
+		"enum":    {Label: "enum", Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: stString, Values: []interface{}{"a", "b"}}},
+		"pointer": {Label: "pointer", Type: &EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: stInt, Values: []interface{}{1, 2}}},
+	}
+	for name, def := range valid {
+		t.Run(name, func(t *testing.T) {
+			if err := def.Validate(name); err != nil {
+				t.Errorf("expected field %q to be valid: %v", name, err)
+			}
+		})
+	}
+
+	invalid := map[string]FieldDefinition{
+		"no type":            {Label: "no type"},
+		"unknown kind":       {Label: "unknown kind", Type: SimpleType{Kind: Kind("foo")}},
+		"list of lists":      {Label: "list of lists", Type: ListType{SimpleType: stList, ComponentType: stList}},
+		"enum without value": {Label: "enum without value", Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: stString}},
+		"enum of wrong type": {Label: "enum of wrong type", Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: stInt, Values: []interface{}{"a"}}},
+		"duplicate values":   {Label: "duplicate values", Type: EnumType{SimpleType: SimpleType{Kind: KindEnum}, BaseType: stString, Values: []interface{}{"a", "a"}}},
+		"enum kind only":     {Label: "enum kind only", Type: SimpleType{Kind: KindEnum}},
+	}
+	for name, def := range invalid {
+		t.Run(name, func(t *testing.T) {
+			if err := def.Validate(name); err == nil {
+				t.Errorf("expected field %q to be invalid", name)
+			}
+		})
+	}
+
+	t.Run("no name", func(t *testing.T) {
+		if err := (FieldDefinition{Type: stString}).Validate(" "); err == nil {
+			t.Error("expected a field without name to be invalid")
+		}
+	})
+}
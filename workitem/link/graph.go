@@ -0,0 +1,178 @@
+package link
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// GraphDirection determines which way the links are followed when a graph is
+// built
+type GraphDirection string
+
+// String implements the Stringer interface
+func (d GraphDirection) String() string { return string(d) }
+
+const (
+	// GraphDirectionForward follows the links from source to target (e.g.
+	// "blocks")
+	GraphDirectionForward GraphDirection = "forward"
+	// GraphDirectionReverse follows the links from target to source (e.g. "is
+	// blocked by")
+	GraphDirectionReverse GraphDirection = "reverse"
+)
+
+// GraphDepthAll can be used to build a graph with all reachable work items
+const GraphDepthAll int = -1
+
+// CheckValid returns nil if the given direction is valid; otherwise a
+// BadParameterError is returned.
+func (d GraphDirection) CheckValid() error {
+	switch d {
+	case GraphDirectionForward, GraphDirectionReverse:
+		return nil
+	default:
+		return errors.NewBadParameterError("direction", d).Expected(GraphDirectionForward + "|" + GraphDirectionReverse)
+	}
+}
+
+// GraphNode is a work item in a graph together with the minimal number of
+// links between it and the root of the graph.
+type GraphNode struct {
+	ID    uuid.UUID
+	Depth int
+}
+
+// Graph is the transitive closure of a link type from a root work item. The
+// edges are the links that were followed; they keep the source and target of
+// the link no matter in which direction the links were followed.
+type Graph struct {
+	RootID     uuid.UUID
+	LinkTypeID uuid.UUID
+	Direction  GraphDirection
+	Nodes      []GraphNode
+	Edges      []WorkItemLink
+}
+
+// CriticalPath is the chain of work items with the highest total weight in a
+// graph.
+type CriticalPath struct {
+	// IDs lists the work items of the path in link order (source before
+	// target).
+	IDs    []uuid.UUID
+	Weight float64
+}
+
+// successors returns the adjacency lists of the graph in the direction in
+// which the graph was built
+func (g Graph) successors() map[uuid.UUID][]uuid.UUID {
+	next := map[uuid.UUID][]uuid.UUID{}
+	for _, e := range g.Edges {
+		from, to := e.SourceID, e.TargetID
+		if g.Direction == GraphDirectionReverse {
+			from, to = to, from
+		}
+		next[from] = append(next[from], to)
+	}
+	return next
+}
+
+// CriticalPath returns the path starting at the root of the graph with the
+// highest sum of the weights of its work items. The graph must not contain
+// cycles.
+func (g Graph) CriticalPath(weight func(uuid.UUID) float64) (*CriticalPath, error) {
+	next := g.successors()
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := map[uuid.UUID]int{}
+	best := map[uuid.UUID]float64{}
+	bestNext := map[uuid.UUID]uuid.UUID{}
+	var visit func(id uuid.UUID) error
+	visit = func(id uuid.UUID) error {
+		switch state[id] {
+		case visiting:
+			return errors.NewBadParameterError("graph", g.LinkTypeID).Expected("links without cycles")
+		case done:
+			return nil
+		}
+		state[id] = visiting
+		best[id] = weight(id)
+		for _, n := range next[id] {
+			if err := visit(n); err != nil {
+				return err
+			}
+			if w := weight(id) + best[n]; w > best[id] {
+				best[id] = w
+				bestNext[id] = n
+			}
+		}
+		state[id] = done
+		return nil
+	}
+	if err := visit(g.RootID); err != nil {
+		return nil, err
+	}
+	path := CriticalPath{Weight: best[g.RootID]}
+	for id, ok := g.RootID, true; ok; id, ok = bestNext[id] {
+		path.IDs = append(path.IDs, id)
+	}
+	if g.Direction == GraphDirectionReverse {
+		for i, j := 0, len(path.IDs)-1; i < j; i, j = i+1, j-1 {
+			path.IDs[i], path.IDs[j] = path.IDs[j], path.IDs[i]
+		}
+	}
+	return &path, nil
+}
+
+// DOT renders the graph in the Graphviz DOT language. The label function
+// returns the label of a work item. The edges between work items of the given
+// critical path are highlighted.
+func (g Graph) DOT(label func(uuid.UUID) string, path *CriticalPath) string {
+	onPath := map[[2]uuid.UUID]bool{}
+	if path != nil {
+		for i := 0; i+1 < len(path.IDs); i++ {
+			onPath[[2]uuid.UUID{path.IDs[i], path.IDs[i+1]}] = true
+		}
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "digraph %s {\n", dotQuote(g.RootID.String()))
+	nodes := make([]GraphNode, len(g.Nodes))
+	copy(nodes, g.Nodes)
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].ID.String() < nodes[j].ID.String()
+	})
+	for _, n := range nodes {
+		fmt.Fprintf(&buf, "  %s [label=%s];\n", dotQuote(n.ID.String()), dotQuote(label(n.ID)))
+	}
+	edges := make([]WorkItemLink, len(g.Edges))
+	copy(edges, g.Edges)
+	sort.Slice(edges, func(i, j int) bool {
+		return edges[i].SourceID.String()+edges[i].TargetID.String() < edges[j].SourceID.String()+edges[j].TargetID.String()
+	})
+	for _, e := range edges {
+		attrs := ""
+		if onPath[[2]uuid.UUID{e.SourceID, e.TargetID}] {
+			attrs = " [color=red, penwidth=2]"
+		}
+		fmt.Fprintf(&buf, "  %s -> %s%s;\n", dotQuote(e.SourceID.String()), dotQuote(e.TargetID.String()), attrs)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// dotQuote returns the given string as a quoted DOT identifier
+func dotQuote(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, `"`, `\"`, -1)
+	s = strings.Replace(s, "\n", `\n`, -1)
+	return `"` + s + `"`
+}
@@ -0,0 +1,103 @@
+package link_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphCriticalPath(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given a dependency graph where A blocks B and C, which both block D:
+	//
+	//   A -> B -> D
+	//   A -> C -> D
+	A, B, C, D := uuid.NewV4(), uuid.NewV4(), uuid.NewV4(), uuid.NewV4()
+	names := map[uuid.UUID]string{A: "A", B: "B", C: "C", D: "D"}
+	estimates := map[uuid.UUID]float64{A: 1, B: 2, C: 5, D: 1}
+	weight := func(id uuid.UUID) float64 { return estimates[id] }
+	edges := []link.WorkItemLink{
+		{ID: uuid.NewV4(), SourceID: A, TargetID: B},
+		{ID: uuid.NewV4(), SourceID: A, TargetID: C},
+		{ID: uuid.NewV4(), SourceID: B, TargetID: D},
+		{ID: uuid.NewV4(), SourceID: C, TargetID: D},
+	}
+	pathNames := func(p *link.CriticalPath) []string {
+		res := []string{}
+		for _, id := range p.IDs {
+			res = append(res, names[id])
+		}
+		return res
+	}
+
+	t.Run("forward", func(t *testing.T) {
+		g := link.Graph{RootID: A, Direction: link.GraphDirectionForward, Edges: edges}
+		p, err := g.CriticalPath(weight)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "C", "D"}, pathNames(p))
+		assert.Equal(t, float64(7), p.Weight)
+	})
+	t.Run("reverse", func(t *testing.T) {
+		// what blocks D?
+		g := link.Graph{RootID: D, Direction: link.GraphDirectionReverse, Edges: edges}
+		p, err := g.CriticalPath(weight)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A", "C", "D"}, pathNames(p))
+		assert.Equal(t, float64(7), p.Weight)
+	})
+	t.Run("single work item", func(t *testing.T) {
+		g := link.Graph{RootID: D, Direction: link.GraphDirectionForward, Edges: edges}
+		p, err := g.CriticalPath(weight)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"D"}, pathNames(p))
+		assert.Equal(t, float64(1), p.Weight)
+	})
+	t.Run("cycle", func(t *testing.T) {
+		g := link.Graph{RootID: A, Direction: link.GraphDirectionForward, Edges: append(edges, link.WorkItemLink{SourceID: D, TargetID: A})}
+		_, err := g.CriticalPath(weight)
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func TestGraphDOT(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given
+	A := uuid.FromStringOrNil("00000000-0000-0000-0000-00000000000a")
+	B := uuid.FromStringOrNil("00000000-0000-0000-0000-00000000000b")
+	C := uuid.FromStringOrNil("00000000-0000-0000-0000-00000000000c")
+	g := link.Graph{
+		RootID:    A,
+		Direction: link.GraphDirectionForward,
+		Nodes:     []link.GraphNode{{ID: A, Depth: 0}, {ID: C, Depth: 1}, {ID: B, Depth: 1}},
+		Edges: []link.WorkItemLink{
+			{SourceID: A, TargetID: C},
+			{SourceID: A, TargetID: B},
+		},
+	}
+	labels := map[uuid.UUID]string{A: `#1 "quoted"`, B: "#2 second", C: `#3 back\slash`}
+	// when
+	dot := g.DOT(func(id uuid.UUID) string { return labels[id] }, &link.CriticalPath{IDs: []uuid.UUID{A, C}})
+	// then
+	assert.Equal(t, `digraph "00000000-0000-0000-0000-00000000000a" {
+  "00000000-0000-0000-0000-00000000000a" [label="#1 \"quoted\""];
+  "00000000-0000-0000-0000-00000000000b" [label="#2 second"];
+  "00000000-0000-0000-0000-00000000000c" [label="#3 back\\slash"];
+  "00000000-0000-0000-0000-00000000000a" -> "00000000-0000-0000-0000-00000000000b";
+  "00000000-0000-0000-0000-00000000000a" -> "00000000-0000-0000-0000-00000000000c" [color=red, penwidth=2];
+}
+`, dot)
+}
+
+func TestGraphDirectionCheckValid(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.NoError(t, link.GraphDirectionForward.CheckValid())
+	assert.NoError(t, link.GraphDirectionReverse.CheckValid())
+	assert.IsType(t, errors.BadParameterError{}, link.GraphDirection("up").CheckValid())
+}
@@ -45,6 +45,9 @@ type WorkItemLinkRepository interface {
 	WorkItemHasChildren(ctx context.Context, parentID uuid.UUID) (bool, error)
 	// GetAncestors returns all ancestors for the given work items.
 	GetAncestors(ctx context.Context, linkTypeID uuid.UUID, upToLevel int, workItemIDs ...uuid.UUID) (ancestors AncestorList, err error)
+	// GetGraph returns the transitive closure of the given link type from the
+	// given work item.
+	GetGraph(ctx context.Context, linkTypeID uuid.UUID, rootID uuid.UUID, direction GraphDirection, depth int) (*Graph, error)
 }
 
 // NewWorkItemLinkRepository creates a work item link repository based on gorm
@@ -271,6 +274,12 @@ func (r *GormWorkItemLinkRepository) Create(ctx context.Context, sourceID, targe
 	if err := r.revisionRepo.Create(ctx, creatorID, RevisionTypeCreate, *link); err != nil {
 		return nil, errs.Wrapf(err, "error while creating work item")
 	}
+	// the parent may aggregate values of its new child
+	if linkType.Topology == TopologyTree {
+		if err := r.workItemRepo.RecomputeFields(ctx, sourceID); err != nil {
+			return nil, errs.Wrapf(err, "failed to update the computed fields of work item %s", sourceID)
+		}
+	}
 	return link, nil
 }
 
@@ -403,6 +412,16 @@ func (r *GormWorkItemLinkRepository) deleteLink(ctx context.Context, lnk WorkIte
 	if err := r.revisionRepo.Create(ctx, suppressorID, RevisionTypeDelete, lnk); err != nil {
 		return errs.Wrapf(err, "error while deleting work item")
 	}
+	// the parent may have aggregated values of its former child
+	linkType, err := r.workItemLinkTypeRepo.Load(ctx, lnk.LinkTypeID)
+	if err != nil {
+		return errs.Wrapf(err, "failed to load link type %s", lnk.LinkTypeID)
+	}
+	if linkType.Topology == TopologyTree {
+		if err := r.workItemRepo.RecomputeFields(ctx, lnk.SourceID); err != nil {
+			return errs.Wrapf(err, "failed to update the computed fields of work item %s", lnk.SourceID)
+		}
+	}
 	return nil
 }
 
@@ -633,3 +652,66 @@ func (r *GormWorkItemLinkRepository) GetAncestors(ctx context.Context, linkTypeI
 	}
 	return ancestors, nil
 }
+
+// GetGraph returns the transitive closure of the given link type from the given
+// root work item. Links are followed in the given direction up to the given
+// depth; GraphDepthAll follows all links. Every work item and link appears only
+// once in the graph, even if the links form cycles.
+func (r *GormWorkItemLinkRepository) GetGraph(ctx context.Context, linkTypeID uuid.UUID, rootID uuid.UUID, direction GraphDirection, depth int) (*Graph, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemlink", "get", "graph"}, time.Now())
+	if err := direction.CheckValid(); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.workItemRepo.CheckExists(ctx, rootID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.workItemLinkTypeRepo.CheckExists(ctx, linkTypeID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	from := "source_id"
+	if direction == GraphDirectionReverse {
+		from = "target_id"
+	}
+	graph := Graph{
+		RootID:     rootID,
+		LinkTypeID: linkTypeID,
+		Direction:  direction,
+		Nodes:      []GraphNode{{ID: rootID, Depth: 0}},
+		Edges:      []WorkItemLink{},
+	}
+	visitedNodes := id.Map{rootID: struct{}{}}
+	visitedLinks := id.Map{}
+	frontier := []uuid.UUID{rootID}
+	// breadth-first search, one query per level
+	for level := 1; len(frontier) > 0 && (depth == GraphDepthAll || level <= depth); level++ {
+		var links []WorkItemLink
+		db := r.db.Model(&WorkItemLink{}).Where(from+" IN (?) AND link_type_id = ?", frontier, linkTypeID).Order("created_at").Find(&links)
+		if db.Error != nil {
+			log.Error(ctx, map[string]interface{}{
+				"err":          db.Error,
+				"wi_id":        rootID,
+				"link_type_id": linkTypeID,
+			}, "failed to build the graph of work item links")
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to build the graph of work item %s", rootID))
+		}
+		frontier = []uuid.UUID{}
+		for _, l := range links {
+			if _, ok := visitedLinks[l.ID]; ok {
+				continue
+			}
+			visitedLinks[l.ID] = struct{}{}
+			graph.Edges = append(graph.Edges, l)
+			next := l.TargetID
+			if direction == GraphDirectionReverse {
+				next = l.SourceID
+			}
+			if _, ok := visitedNodes[next]; ok {
+				continue
+			}
+			visitedNodes[next] = struct{}{}
+			graph.Nodes = append(graph.Nodes, GraphNode{ID: next, Depth: level})
+			frontier = append(frontier, next)
+		}
+	}
+	return &graph, nil
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/workitem"
 	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	_ "github.com/lib/pq" // need to import postgres driver
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -892,3 +893,61 @@ func (s *linkRepoBlackBoxTest) TestDeleteLinkAndListChildren() {
 		require.Len(t, childrenList, 0)
 	})
 }
+
+func (s *linkRepoBlackBoxTest) TestGetGraph() {
+	// given a network with a cycle:
+	//
+	//   A -> B -> C -> D
+	//        |         ^
+	//        +-> E ----+
+	//   D -> B
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItems(5, tf.SetWorkItemTitles("A", "B", "C", "D", "E")),
+		tf.WorkItemLinkTypes(1, tf.SetTopologies(link.TopologyNetwork)),
+		tf.WorkItemLinks(6, tf.BuildLinks(append(tf.LinkChain("A", "B", "C", "D"), tf.L("B", "E"), tf.L("E", "D"), tf.L("D", "B"))...)),
+	)
+	linkTypeID := fxt.WorkItemLinkTypes[0].ID
+	nodeDepths := func(g *link.Graph) map[string]int {
+		res := map[string]int{}
+		for _, n := range g.Nodes {
+			res[fxt.WorkItemByID(n.ID).Fields[workitem.SystemTitle].(string)] = n.Depth
+		}
+		return res
+	}
+
+	s.T().Run("forward", func(t *testing.T) {
+		g, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, fxt.WorkItemByTitle("A").ID, link.GraphDirectionForward, link.GraphDepthAll)
+		require.NoError(t, err)
+		require.Equal(t, fxt.WorkItemByTitle("A").ID, g.Nodes[0].ID)
+		require.Equal(t, map[string]int{"A": 0, "B": 1, "C": 2, "E": 2, "D": 3}, nodeDepths(g))
+		require.Len(t, g.Edges, 6)
+	})
+	s.T().Run("forward with depth", func(t *testing.T) {
+		g, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, fxt.WorkItemByTitle("A").ID, link.GraphDirectionForward, 2)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"A": 0, "B": 1, "C": 2, "E": 2}, nodeDepths(g))
+		require.Len(t, g.Edges, 3)
+	})
+	s.T().Run("reverse", func(t *testing.T) {
+		g, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, fxt.WorkItemByTitle("C").ID, link.GraphDirectionReverse, link.GraphDepthAll)
+		require.NoError(t, err)
+		require.Equal(t, map[string]int{"C": 0, "B": 1, "A": 2, "D": 2, "E": 3}, nodeDepths(g))
+		require.Len(t, g.Edges, 6)
+	})
+	s.T().Run("leaf", func(t *testing.T) {
+		g, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, fxt.WorkItemByTitle("A").ID, link.GraphDirectionReverse, link.GraphDepthAll)
+		require.NoError(t, err)
+		require.Len(t, g.Nodes, 1)
+		require.Empty(t, g.Edges)
+	})
+	s.T().Run("invalid direction", func(t *testing.T) {
+		_, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, fxt.WorkItemByTitle("A").ID, link.GraphDirection("sideways"), link.GraphDepthAll)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	s.T().Run("unknown work item", func(t *testing.T) {
+		_, err := s.workitemLinkRepo.GetGraph(s.Ctx, linkTypeID, uuid.NewV4(), link.GraphDirectionForward, link.GraphDepthAll)
+		require.Error(t, err)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
@@ -18,6 +18,9 @@ type RevisionRepository interface {
 	Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, l WorkItemLink) error
 	// List retrieves all revisions for a given work item link
 	List(ctx context.Context, workitemID uuid.UUID) ([]Revision, error)
+	// ListByWorkItem retrieves all revisions of the links in which the given
+	// work item is either the source or the target
+	ListByWorkItem(ctx context.Context, workitemID uuid.UUID) ([]Revision, error)
 }
 
 // NewRevisionRepository creates a GormCommentRevisionRepository
@@ -64,3 +67,14 @@ func (r *GormWorkItemLinkRevisionRepository) List(ctx context.Context, linkID uu
 	}
 	return revisions, nil
 }
+
+// ListByWorkItem retrieves all revisions of the links in which the given work
+// item is either the source or the target
+func (r *GormWorkItemLinkRevisionRepository) ListByWorkItem(ctx context.Context, workitemID uuid.UUID) ([]Revision, error) {
+	log.Debug(nil, map[string]interface{}{}, "List all link revisions for work item with ID=%v", workitemID.String())
+	var revisions []Revision
+	if err := r.db.Where("work_item_link_source_id = ? OR work_item_link_target_id = ?", workitemID, workitemID).Order("revision_time asc").Find(&revisions).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to retrieve work item link revisions"))
+	}
+	return revisions, nil
+}
@@ -12,6 +12,7 @@ import (
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	. "github.com/fabric8-services/fabric8-wit/workitem/number_sequence"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -72,3 +73,20 @@ func (s *workItemNumberSequenceTest) TestConcurrentNextVal() {
 	}
 
 }
+
+func (s *workItemNumberSequenceTest) TestReserve() {
+	// given
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.Spaces(1))
+	// when
+	err := s.repo.Reserve(context.Background(), fxt.Spaces[0].ID, 41)
+	// then
+	require.NoError(s.T(), err)
+	next, err := s.repo.NextVal(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 42, *next)
+	// reserving a lower number doesn't hand out numbers twice
+	require.NoError(s.T(), s.repo.Reserve(context.Background(), fxt.Spaces[0].ID, 7))
+	next, err = s.repo.NextVal(context.Background(), fxt.Spaces[0].ID)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), 43, *next)
+}
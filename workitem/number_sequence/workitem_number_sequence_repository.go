@@ -13,6 +13,7 @@ import (
 // WorkItemNumberSequenceRepository the interface for the work item number sequence repository
 type WorkItemNumberSequenceRepository interface {
 	NextVal(ctx context.Context, spaceID uuid.UUID) (*int, error)
+	Reserve(ctx context.Context, spaceID uuid.UUID, number int) error
 }
 
 // NewWorkItemNumberSequenceRepository creates a GormWorkItemNumberSequenceRepository
@@ -40,3 +41,16 @@ func (r *GormWorkItemNumberSequenceRepository) NextVal(ctx context.Context, spac
 	log.Debug(nil, map[string]interface{}{"space_id": spaceID, "next_val": currentVal}, "computed nextVal")
 	return &currentVal, nil
 }
+
+// Reserve makes sure that NextVal only returns numbers greater than the given
+// one for the given space ID, e.g. after work items were created with numbers
+// of their own
+func (r *GormWorkItemNumberSequenceRepository) Reserve(ctx context.Context, spaceID uuid.UUID, number int) error {
+	upsertStmt := fmt.Sprintf(`INSERT INTO %[1]s (space_id, current_val) VALUES ($1,$2)
+		ON CONFLICT (space_id) DO UPDATE SET current_val = GREATEST(%[1]s.current_val, EXCLUDED.current_val)`, WorkItemNumberSequence{}.TableName())
+	if _, err := r.db.CommonDB().Exec(upsertStmt, spaceID, number); err != nil {
+		return errs.Wrapf(err, "failed to reserve number %d for space with ID=`%s`", number, spaceID.String())
+	}
+	log.Debug(nil, map[string]interface{}{"space_id": spaceID, "number": number}, "reserved number")
+	return nil
+}
@@ -0,0 +1,166 @@
+// Package report contains the iteration reports (burndown and velocity) that
+// are rebuilt from the revision history of the work items.
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Defines "type" strings to be used while validating jsonapi spec based
+// payloads
+const (
+	APIStringTypeBurndown = "burndowns"
+	APIStringTypeVelocity = "velocities"
+)
+
+// DefaultField is the numeric work item field that is summed up if no other
+// field is requested
+const DefaultField = "storypoints"
+
+// DoneStates are the work item states (compared case-insensitively) in which a
+// work item counts as completed
+var DoneStates = []string{workitem.SystemStateClosed, workitem.SystemStateResolved, "done"}
+
+// BurndownPoint holds the work in an iteration at the end of a day
+type BurndownPoint struct {
+	// Date is the beginning of the day (UTC)
+	Date           time.Time
+	TotalCount     int
+	TotalValue     float64
+	RemainingCount int
+	RemainingValue float64
+}
+
+// Burndown is the daily series of the remaining work in an iteration between
+// its start and its end (or now, if the iteration has not ended yet).
+type Burndown struct {
+	IterationID uuid.UUID
+	Field       string
+	StartAt     time.Time
+	EndAt       time.Time
+	Points      []BurndownPoint
+}
+
+// IterationVelocity holds the work committed to and completed in an iteration
+type IterationVelocity struct {
+	IterationID    uuid.UUID
+	Name           string
+	StartAt        time.Time
+	EndAt          time.Time
+	CommittedCount int
+	CommittedValue float64
+	CompletedCount int
+	CompletedValue float64
+}
+
+// Velocity holds the completed work of the closed iterations of a space
+type Velocity struct {
+	SpaceID      uuid.UUID
+	Field        string
+	Iterations   []IterationVelocity
+	AverageCount float64
+	AverageValue float64
+}
+
+// sortByEnd sorts the given iteration velocities by their end
+func sortByEnd(iterations []IterationVelocity) {
+	sort.Slice(iterations, func(i, j int) bool {
+		return iterations[i].EndAt.Before(iterations[j].EndAt)
+	})
+}
+
+// IsDone returns true if the given state is one of the DoneStates
+func IsDone(state interface{}) bool {
+	s, _ := state.(string)
+	for _, done := range DoneStates {
+		if strings.EqualFold(s, done) {
+			return true
+		}
+	}
+	return false
+}
+
+// numericValue returns the value of a numeric field or 0
+func numericValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+// workload sums up work items
+type workload struct {
+	totalCount     int
+	totalValue     float64
+	remainingCount int
+	remainingValue float64
+}
+
+// snapshot replays the revisions of each work item up to the given time and
+// sums up the work items that were in one of the given iterations at that
+// time. The revisions of each work item must be sorted by time.
+func snapshot(revisions map[uuid.UUID][]workitem.Revision, at time.Time, iterationIDs id.Map, field string) workload {
+	var w workload
+	for _, revs := range revisions {
+		var latest *workitem.Revision
+		for i := range revs {
+			if revs[i].Time.After(at) {
+				break
+			}
+			latest = &revs[i]
+		}
+		if latest == nil || latest.Type == workitem.RevisionTypeDelete {
+			continue
+		}
+		iterationValue, _ := latest.WorkItemFields[workitem.SystemIteration].(string)
+		iterationID, err := uuid.FromString(iterationValue)
+		if err != nil {
+			continue
+		}
+		if _, ok := iterationIDs[iterationID]; !ok {
+			continue
+		}
+		value := numericValue(latest.WorkItemFields[field])
+		w.totalCount++
+		w.totalValue += value
+		if !IsDone(latest.WorkItemFields[workitem.SystemState]) {
+			w.remainingCount++
+			w.remainingValue += value
+		}
+	}
+	return w
+}
+
+// burndownPoints computes the remaining work at the end of each day between
+// start and end
+func burndownPoints(revisions map[uuid.UUID][]workitem.Revision, start, end time.Time, iterationIDs id.Map, field string) []BurndownPoint {
+	points := []BurndownPoint{}
+	for day := start.UTC().Truncate(24 * time.Hour); !day.After(end); day = day.Add(24 * time.Hour) {
+		at := day.Add(24*time.Hour - time.Nanosecond)
+		if at.After(end) {
+			at = end
+		}
+		w := snapshot(revisions, at, iterationIDs, field)
+		points = append(points, BurndownPoint{
+			Date:           day,
+			TotalCount:     w.totalCount,
+			TotalValue:     w.totalValue,
+			RemainingCount: w.remainingCount,
+			RemainingValue: w.remainingValue,
+		})
+	}
+	return points
+}
@@ -0,0 +1,161 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/application/repository"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/log"
+	"github.com/fabric8-services/fabric8-wit/space"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+
+	"github.com/goadesign/goa"
+	"github.com/jinzhu/gorm"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// Repository encapsulates the computation of iteration reports
+type Repository interface {
+	// Burndown returns the daily remaining work of the given iteration and its
+	// child iterations, counted and summed up over the given numeric field.
+	Burndown(ctx context.Context, iterationID uuid.UUID, field string) (*Burndown, error)
+	// Velocity returns the work committed to and completed in each closed
+	// iteration of the given space.
+	Velocity(ctx context.Context, spaceID uuid.UUID, field string) (*Velocity, error)
+}
+
+// NewReportRepository creates a report repository based on gorm
+func NewReportRepository(db *gorm.DB) *GormReportRepository {
+	return &GormReportRepository{
+		db:            db,
+		iterationRepo: iteration.NewIterationRepository(db),
+	}
+}
+
+// GormReportRepository implements Repository using gorm
+type GormReportRepository struct {
+	db            *gorm.DB
+	iterationRepo iteration.Repository
+}
+
+// loadRevisions returns the revisions written up to the given time of all work
+// items that were ever in one of the given iterations, grouped by work item and
+// sorted by time
+func (r *GormReportRepository) loadRevisions(ctx context.Context, iterationIDs id.Map, until time.Time) (map[uuid.UUID][]workitem.Revision, error) {
+	ids := []string{}
+	for iterationID := range iterationIDs {
+		ids = append(ids, iterationID.String())
+	}
+	var revisions []workitem.Revision
+	db := r.db.Where(`work_item_id IN (
+			SELECT work_item_id FROM `+workitem.Revision{}.TableName()+`
+			WHERE work_item_fields->>'`+workitem.SystemIteration+`' IN (?)
+		) AND revision_time <= ?`, ids, until).
+		Order("revision_time, work_item_version").
+		Find(&revisions)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"err":           db.Error,
+			"iteration_ids": ids,
+		}, "unable to load the work item revisions of iterations")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to load the work item revisions of iterations"))
+	}
+	res := map[uuid.UUID][]workitem.Revision{}
+	for _, rev := range revisions {
+		res[rev.WorkItemID] = append(res[rev.WorkItemID], rev)
+	}
+	return res, nil
+}
+
+// Burndown implements Repository
+func (r *GormReportRepository) Burndown(ctx context.Context, iterationID uuid.UUID, field string) (*Burndown, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "report", "burndown"}, time.Now())
+	itr, err := r.iterationRepo.Load(ctx, iterationID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if itr.StartAt == nil {
+		return nil, errors.NewBadParameterError("startAt", nil).Expected("iteration with a start date")
+	}
+	if itr.EndAt == nil {
+		return nil, errors.NewBadParameterError("endAt", nil).Expected("iteration with an end date")
+	}
+	children, err := r.iterationRepo.LoadChildren(ctx, iterationID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	iterationIDs := id.Map{iterationID: struct{}{}}
+	for _, child := range children {
+		iterationIDs[child.ID] = struct{}{}
+	}
+	end := *itr.EndAt
+	if now := time.Now(); end.After(now) {
+		end = now
+	}
+	revisions, err := r.loadRevisions(ctx, iterationIDs, end)
+	if err != nil {
+		return nil, err
+	}
+	return &Burndown{
+		IterationID: iterationID,
+		Field:       field,
+		StartAt:     *itr.StartAt,
+		EndAt:       *itr.EndAt,
+		Points:      burndownPoints(revisions, *itr.StartAt, end, iterationIDs, field),
+	}, nil
+}
+
+// Velocity implements Repository. Only the work items that were directly in an
+// iteration count for it, so that work items of child iterations aren't
+// counted twice.
+func (r *GormReportRepository) Velocity(ctx context.Context, spaceID uuid.UUID, field string) (*Velocity, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "report", "velocity"}, time.Now())
+	if err := repository.CheckExists(ctx, r.db, space.Space{}.TableName(), spaceID); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	iterations, err := r.iterationRepo.List(ctx, spaceID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	velocity := Velocity{
+		SpaceID:    spaceID,
+		Field:      field,
+		Iterations: []IterationVelocity{},
+	}
+	for _, itr := range iterations {
+		if itr.State != iteration.StateClose || itr.StartAt == nil || itr.EndAt == nil {
+			continue
+		}
+		iterationIDs := id.Map{itr.ID: struct{}{}}
+		revisions, err := r.loadRevisions(ctx, iterationIDs, *itr.EndAt)
+		if err != nil {
+			return nil, err
+		}
+		committed := snapshot(revisions, *itr.StartAt, iterationIDs, field)
+		completed := snapshot(revisions, *itr.EndAt, iterationIDs, field)
+		velocity.Iterations = append(velocity.Iterations, IterationVelocity{
+			IterationID:    itr.ID,
+			Name:           itr.Name,
+			StartAt:        *itr.StartAt,
+			EndAt:          *itr.EndAt,
+			CommittedCount: committed.totalCount,
+			CommittedValue: committed.totalValue,
+			CompletedCount: completed.totalCount - completed.remainingCount,
+			CompletedValue: completed.totalValue - completed.remainingValue,
+		})
+	}
+	sortByEnd(velocity.Iterations)
+	if n := len(velocity.Iterations); n > 0 {
+		for _, v := range velocity.Iterations {
+			velocity.AverageCount += float64(v.CompletedCount)
+			velocity.AverageValue += v.CompletedValue
+		}
+		velocity.AverageCount /= float64(n)
+		velocity.AverageValue /= float64(n)
+	}
+	return &velocity, nil
+}
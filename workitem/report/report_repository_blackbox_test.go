@@ -0,0 +1,162 @@
+package report_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
+	"github.com/fabric8-services/fabric8-wit/iteration"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/report"
+	errs "github.com/pkg/errors"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+)
+
+type reportRepoBlackBoxTest struct {
+	gormtestsupport.DBTestSuite
+	repo report.Repository
+}
+
+func TestRunReportRepoBlackBoxTest(t *testing.T) {
+	resource.Require(t, resource.Database)
+	suite.Run(t, &reportRepoBlackBoxTest{DBTestSuite: gormtestsupport.NewDBTestSuite()})
+}
+
+func (s *reportRepoBlackBoxTest) SetupTest() {
+	s.DBTestSuite.SetupTest()
+	s.repo = report.NewReportRepository(s.DB)
+}
+
+// createFixture creates a closed iteration of three days with a child
+// iteration of two days. The work item "A" with 3 story points is planned for
+// the iteration and closed on its second day, the work item "B" with 5 story
+// points is planned for the child iteration and never closed.
+func (s *reportRepoBlackBoxTest) createFixture(t *testing.T, start time.Time) *tf.TestFixture {
+	day := 24 * time.Hour
+	fxt := tf.NewTestFixture(t, s.DB,
+		tf.Iterations(2,
+			tf.SetIterationNames("sprint", "sub sprint"),
+			tf.PlaceIterationUnderRootIteration(),
+			func(fxt *tf.TestFixture, idx int) error {
+				endAt := start.Add(time.Duration(2-idx) * day)
+				fxt.Iterations[idx].StartAt = &start
+				fxt.Iterations[idx].EndAt = &endAt
+				fxt.Iterations[idx].State = iteration.StateClose
+				return nil
+			}),
+		tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Fields = map[string]workitem.FieldDefinition{
+				report.DefaultField: {
+					Type: &workitem.SimpleType{Kind: workitem.KindFloat},
+				},
+			}
+			return nil
+		}),
+		tf.WorkItems(2, tf.SetWorkItemTitles("A", "B"), func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemIteration] = fxt.Iterations[idx].ID.String()
+			fxt.WorkItems[idx].Fields[report.DefaultField] = []float64{3, 5}[idx]
+			return nil
+		}),
+	)
+	A := fxt.WorkItemByTitle("A")
+	A.Fields[workitem.SystemState] = workitem.SystemStateClosed
+	_, err := workitem.NewWorkItemRepository(s.DB).Save(s.Ctx, A.SpaceID, *A, fxt.Identities[0].ID)
+	require.NoError(t, err)
+	// move the revisions into the past
+	db := s.DB.Exec("UPDATE work_item_revisions SET revision_time = ? WHERE work_item_id IN (?) AND revision_type = ?",
+		start.Add(-time.Hour), []uuid.UUID{A.ID, fxt.WorkItemByTitle("B").ID}, workitem.RevisionTypeCreate)
+	require.NoError(t, db.Error)
+	db = s.DB.Exec("UPDATE work_item_revisions SET revision_time = ? WHERE work_item_id = ? AND revision_type = ?",
+		start.Add(day+time.Hour), A.ID, workitem.RevisionTypeUpdate)
+	require.NoError(t, db.Error)
+	return fxt
+}
+
+func (s *reportRepoBlackBoxTest) TestBurndown() {
+	start := time.Now().UTC().Truncate(24 * time.Hour).Add(-5*24*time.Hour + 9*time.Hour)
+	fxt := s.createFixture(s.T(), start)
+
+	s.T().Run("ok", func(t *testing.T) {
+		// when
+		burndown, err := s.repo.Burndown(s.Ctx, fxt.Iterations[0].ID, report.DefaultField)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, fxt.Iterations[0].ID, burndown.IterationID)
+		assert.Equal(t, report.DefaultField, burndown.Field)
+		require.Len(t, burndown.Points, 3)
+		day := start.Truncate(24 * time.Hour)
+		assert.Equal(t, report.BurndownPoint{Date: day, TotalCount: 2, TotalValue: 8, RemainingCount: 2, RemainingValue: 8}, burndown.Points[0])
+		assert.Equal(t, report.BurndownPoint{Date: day.Add(24 * time.Hour), TotalCount: 2, TotalValue: 8, RemainingCount: 1, RemainingValue: 5}, burndown.Points[1])
+		assert.Equal(t, report.BurndownPoint{Date: day.Add(48 * time.Hour), TotalCount: 2, TotalValue: 8, RemainingCount: 1, RemainingValue: 5}, burndown.Points[2])
+	})
+
+	s.T().Run("child iteration", func(t *testing.T) {
+		// when
+		burndown, err := s.repo.Burndown(s.Ctx, fxt.Iterations[1].ID, report.DefaultField)
+		// then
+		require.NoError(t, err)
+		require.Len(t, burndown.Points, 2)
+		assert.Equal(t, 1, burndown.Points[1].TotalCount)
+		assert.Equal(t, float64(5), burndown.Points[1].RemainingValue)
+	})
+
+	s.T().Run("iteration without dates", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Iterations(1))
+		_, err := s.repo.Burndown(s.Ctx, fxt.Iterations[0].ID, report.DefaultField)
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown iteration", func(t *testing.T) {
+		_, err := s.repo.Burndown(s.Ctx, uuid.NewV4(), report.DefaultField)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *reportRepoBlackBoxTest) TestVelocity() {
+	start := time.Now().UTC().Truncate(24 * time.Hour).Add(-5*24*time.Hour + 9*time.Hour)
+	fxt := s.createFixture(s.T(), start)
+
+	s.T().Run("ok", func(t *testing.T) {
+		// when
+		velocity, err := s.repo.Velocity(s.Ctx, fxt.Spaces[0].ID, report.DefaultField)
+		// then
+		require.NoError(t, err)
+		require.Len(t, velocity.Iterations, 2)
+		sub := velocity.Iterations[0]
+		assert.Equal(t, fxt.Iterations[1].ID, sub.IterationID)
+		assert.Equal(t, 1, sub.CommittedCount)
+		assert.Equal(t, float64(5), sub.CommittedValue)
+		assert.Equal(t, 0, sub.CompletedCount)
+		assert.Equal(t, float64(0), sub.CompletedValue)
+		sprint := velocity.Iterations[1]
+		assert.Equal(t, fxt.Iterations[0].ID, sprint.IterationID)
+		assert.Equal(t, 1, sprint.CommittedCount)
+		assert.Equal(t, float64(3), sprint.CommittedValue)
+		assert.Equal(t, 1, sprint.CompletedCount)
+		assert.Equal(t, float64(3), sprint.CompletedValue)
+		assert.Equal(t, 0.5, velocity.AverageCount)
+		assert.Equal(t, 1.5, velocity.AverageValue)
+	})
+
+	s.T().Run("no closed iterations", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB, tf.Iterations(1))
+		velocity, err := s.repo.Velocity(s.Ctx, fxt.Spaces[0].ID, report.DefaultField)
+		require.NoError(t, err)
+		assert.Empty(t, velocity.Iterations)
+		assert.Equal(t, float64(0), velocity.AverageCount)
+	})
+
+	s.T().Run("unknown space", func(t *testing.T) {
+		_, err := s.repo.Velocity(s.Ctx, uuid.NewV4(), report.DefaultField)
+		require.Error(t, err)
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
@@ -0,0 +1,66 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fabric8-services/fabric8-wit/id"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	uuid "github.com/satori/go.uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBurndownPoints(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	// given an iteration of three days
+	day := 24 * time.Hour
+	start := time.Date(2018, 3, 5, 9, 0, 0, 0, time.UTC)
+	end := start.Add(2 * day)
+	itr, otherItr := uuid.NewV4(), uuid.NewV4()
+	fields := func(iterationID uuid.UUID, state string, points float64) workitem.Fields {
+		return workitem.Fields{
+			workitem.SystemIteration: iterationID.String(),
+			workitem.SystemState:     state,
+			DefaultField:             points,
+		}
+	}
+	// A is planned before the start and closed on the second day, B is added
+	// on the first day and moved to another iteration on the third day, C is
+	// created and deleted on the second day and D is never in the iteration.
+	A, B, C, D := uuid.NewV4(), uuid.NewV4(), uuid.NewV4(), uuid.NewV4()
+	revisions := map[uuid.UUID][]workitem.Revision{
+		A: {
+			{Time: start.Add(-day), Type: workitem.RevisionTypeCreate, WorkItemFields: fields(itr, workitem.SystemStateNew, 3)},
+			{Time: start.Add(day + time.Hour), Type: workitem.RevisionTypeUpdate, WorkItemFields: fields(itr, workitem.SystemStateClosed, 3)},
+		},
+		B: {
+			{Time: start.Add(time.Hour), Type: workitem.RevisionTypeCreate, WorkItemFields: fields(itr, workitem.SystemStateOpen, 5)},
+			{Time: start.Add(2*day - time.Hour), Type: workitem.RevisionTypeUpdate, WorkItemFields: fields(otherItr, workitem.SystemStateOpen, 5)},
+		},
+		C: {
+			{Time: start.Add(day), Type: workitem.RevisionTypeCreate, WorkItemFields: fields(itr, workitem.SystemStateNew, 8)},
+			{Time: start.Add(day + 2*time.Hour), Type: workitem.RevisionTypeDelete},
+		},
+		D: {
+			{Time: start.Add(-day), Type: workitem.RevisionTypeCreate, WorkItemFields: fields(otherItr, workitem.SystemStateNew, 13)},
+		},
+	}
+	// when
+	points := burndownPoints(revisions, start, end, id.Map{itr: {}}, DefaultField)
+	// then
+	require.Len(t, points, 3)
+	assert.Equal(t, BurndownPoint{Date: time.Date(2018, 3, 5, 0, 0, 0, 0, time.UTC), TotalCount: 2, TotalValue: 8, RemainingCount: 2, RemainingValue: 8}, points[0])
+	assert.Equal(t, BurndownPoint{Date: time.Date(2018, 3, 6, 0, 0, 0, 0, time.UTC), TotalCount: 2, TotalValue: 8, RemainingCount: 1, RemainingValue: 5}, points[1])
+	assert.Equal(t, BurndownPoint{Date: time.Date(2018, 3, 7, 0, 0, 0, 0, time.UTC), TotalCount: 1, TotalValue: 3, RemainingCount: 0, RemainingValue: 0}, points[2])
+}
+
+func TestIsDone(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	assert.True(t, IsDone(workitem.SystemStateClosed))
+	assert.True(t, IsDone("Resolved"))
+	assert.True(t, IsDone("Done"))
+	assert.False(t, IsDone(workitem.SystemStateOpen))
+	assert.False(t, IsDone(nil))
+}
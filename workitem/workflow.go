@@ -0,0 +1,238 @@
+package workitem
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/fabric8-services/fabric8-wit/convert"
+	"github.com/fabric8-services/fabric8-wit/errors"
+	uuid "github.com/satori/go.uuid"
+)
+
+// TransitionFromAnyState can be used as the "from" state of a transition that
+// is allowed from every state.
+const TransitionFromAnyState = "*"
+
+// Transition allows work items to change their state from one value of the
+// state field to another, e.g.
+//
+//	transitions:
+//	- from: "in progress"
+//	  to: resolved
+//	  required_fields:
+//	  - system.assignees
+type Transition struct {
+	// From is the state before the transition or TransitionFromAnyState
+	From string `json:"from"`
+	// To is the state after the transition
+	To string `json:"to"`
+	// RequiredFields is a guard of the transition: the transition can only be
+	// made if these fields are set.
+	RequiredFields []string `json:"required_fields,omitempty"`
+}
+
+// Transitions is the state machine of a work item type. A work item type
+// without transitions allows all state changes.
+type Transitions []Transition
+
+// Ensure Transitions implements the Equaler interface
+var _ convert.Equaler = Transitions{}
+var _ convert.Equaler = (*Transitions)(nil)
+
+// Ensure Transitions implements the Scanner and Valuer interfaces
+var _ sql.Scanner = (*Transitions)(nil)
+var _ driver.Valuer = (*Transitions)(nil)
+
+// Equal returns true if two Transitions objects are equal; otherwise false is
+// returned.
+func (t Transitions) Equal(u convert.Equaler) bool {
+	other, ok := u.(Transitions)
+	if !ok {
+		return false
+	}
+	if len(t) == 0 && len(other) == 0 {
+		return true
+	}
+	return reflect.DeepEqual(t, other)
+}
+
+// Value implements the https://golang.org/pkg/database/sql/driver/#Valuer interface
+func (t Transitions) Value() (driver.Value, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return toBytes(t)
+}
+
+// Scan implements the https://golang.org/pkg/database/sql/#Scanner interface
+func (t *Transitions) Scan(src interface{}) error {
+	return fromBytes(src, t)
+}
+
+// Validate checks that all transitions have states and that there are no
+// duplicates.
+func (t Transitions) Validate() error {
+	seen := map[Transition]struct{}{}
+	for _, transition := range t {
+		if transition.From == "" {
+			return errors.NewBadParameterError("transitions.from", transition.From).Expected("non-empty state")
+		}
+		if transition.To == "" || transition.To == TransitionFromAnyState {
+			return errors.NewBadParameterError("transitions.to", transition.To).Expected("state")
+		}
+		key := Transition{From: transition.From, To: transition.To}
+		if _, ok := seen[key]; ok {
+			return errors.NewBadParameterError("transitions", fmt.Sprintf("%s -> %s", transition.From, transition.To)).Expected("unique transitions")
+		}
+		seen[key] = struct{}{}
+	}
+	return nil
+}
+
+// States returns the values of the state field of the work item type
+func (wit WorkItemType) States() []string {
+	stateDef, ok := wit.Fields[SystemState]
+	if !ok {
+		return nil
+	}
+	states := []string{}
+	for _, v := range enumValues(stateDef.Type) {
+		if s, ok := v.(string); ok {
+			states = append(states, s)
+		}
+	}
+	return states
+}
+
+// Validate checks the name, the field definitions and the transitions of the
+// work item type.
+func (wit WorkItemType) Validate() error {
+	if strings.TrimSpace(wit.Name) == "" {
+		return errors.NewBadParameterError("name", wit.Name).Expected("not empty")
+	}
+	if wit.SpaceTemplateID == uuid.Nil {
+		return errors.NewBadParameterError("space template id", wit.SpaceTemplateID).Expected("non-nil UUID")
+	}
+	for name, def := range wit.Fields {
+		if err := def.Validate(name); err != nil {
+			return err
+		}
+	}
+	if err := wit.validateFormulas(); err != nil {
+		return err
+	}
+	return wit.ValidateTransitions()
+}
+
+// ValidateTransitions checks that the transitions of the work item type only
+// refer to its states and fields.
+func (wit WorkItemType) ValidateTransitions() error {
+	if err := wit.Transitions.Validate(); err != nil {
+		return err
+	}
+	if len(wit.Transitions) == 0 {
+		return nil
+	}
+	states := map[string]struct{}{TransitionFromAnyState: {}}
+	for _, s := range wit.States() {
+		states[s] = struct{}{}
+	}
+	for _, transition := range wit.Transitions {
+		if _, ok := states[transition.From]; !ok {
+			return errors.NewBadParameterError("transitions.from", transition.From).Expected("state of work item type " + wit.Name)
+		}
+		if _, ok := states[transition.To]; !ok {
+			return errors.NewBadParameterError("transitions.to", transition.To).Expected("state of work item type " + wit.Name)
+		}
+		for _, field := range transition.RequiredFields {
+			if _, ok := wit.Fields[field]; !ok {
+				return errors.NewBadParameterError("transitions.required_fields", field).Expected("field of work item type " + wit.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// TransitionsFrom returns the transitions which change the state of a work
+// item from the given state. If the work item type has no transitions, all
+// other states can be reached.
+func (wit WorkItemType) TransitionsFrom(state string) Transitions {
+	res := Transitions{}
+	if len(wit.Transitions) == 0 {
+		for _, s := range wit.States() {
+			if s != state {
+				res = append(res, Transition{From: state, To: s})
+			}
+		}
+		return res
+	}
+	for _, transition := range wit.Transitions {
+		if transition.To == state {
+			continue
+		}
+		if transition.From == state || transition.From == TransitionFromAnyState {
+			res = append(res, transition)
+		}
+	}
+	return res
+}
+
+// isSet returns true if the given field value is neither nil nor empty
+func isSet(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	case []string:
+		return len(v) > 0
+	}
+	return true
+}
+
+// MissingFields returns the required fields of the transition which are not
+// set in the given fields.
+func (t Transition) MissingFields(fields Fields) []string {
+	missing := []string{}
+	for _, field := range t.RequiredFields {
+		if !isSet(fields[field]) {
+			missing = append(missing, field)
+		}
+	}
+	return missing
+}
+
+// CheckTransition returns a BadParameterError if the state change between the
+// old and the new fields of a work item is not allowed by the transitions of
+// the work item type or if a required field of the transition is not set.
+func (wit WorkItemType) CheckTransition(oldFields, newFields Fields) error {
+	if len(wit.Transitions) == 0 {
+		return nil
+	}
+	from, _ := oldFields[SystemState].(string)
+	to, _ := newFields[SystemState].(string)
+	if from == "" || from == to {
+		return nil
+	}
+	var missing []string
+	allowed := []string{}
+	for _, transition := range wit.TransitionsFrom(from) {
+		allowed = append(allowed, transition.To)
+		if transition.To != to {
+			continue
+		}
+		missing = transition.MissingFields(newFields)
+		if len(missing) == 0 {
+			return nil
+		}
+	}
+	if missing != nil {
+		return errors.NewBadParameterError(missing[0], nil).Expected(fmt.Sprintf("value before changing the state from \"%s\" to \"%s\"", from, to))
+	}
+	return errors.NewBadParameterError(SystemState, to).Expected(fmt.Sprintf("state reachable from \"%s\": %s", from, strings.Join(allowed, ", ")))
+}
@@ -0,0 +1,118 @@
+package workitem_test
+
+import (
+	"testing"
+
+	"github.com/fabric8-services/fabric8-wit/errors"
+	"github.com/fabric8-services/fabric8-wit/resource"
+	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// workflowWIT returns a work item type with the states "new", "in progress",
+// "resolved" and "closed" that can only be resolved when it is assigned
+func workflowWIT() workitem.WorkItemType {
+	return workitem.WorkItemType{
+		Name: "story",
+		Fields: workitem.FieldDefinitions{
+			workitem.SystemState: {Type: workitem.EnumType{
+				SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+				BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+				Values:     []interface{}{"new", "in progress", "resolved", "closed"},
+			}},
+			workitem.SystemAssignees: {Type: workitem.ListType{
+				SimpleType:    workitem.SimpleType{Kind: workitem.KindList},
+				ComponentType: workitem.SimpleType{Kind: workitem.KindUser},
+			}},
+		},
+		Transitions: workitem.Transitions{
+			{From: "new", To: "in progress"},
+			{From: "in progress", To: "new"},
+			{From: "in progress", To: "resolved", RequiredFields: []string{workitem.SystemAssignees}},
+			{From: workitem.TransitionFromAnyState, To: "closed"},
+		},
+	}
+}
+
+func TestTransitionsValidate(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("ok", func(t *testing.T) {
+		require.NoError(t, workflowWIT().ValidateTransitions())
+		require.NoError(t, workitem.WorkItemType{}.ValidateTransitions())
+	})
+	invalid := map[string]workitem.Transition{
+		"empty from":       {To: "new"},
+		"empty to":         {From: "new"},
+		"any state as to":  {From: "new", To: workitem.TransitionFromAnyState},
+		"duplicate":        {From: "new", To: "in progress"},
+		"unknown from":     {From: "open", To: "new"},
+		"unknown to":       {From: "new", To: "open"},
+		"unknown required": {From: "new", To: "resolved", RequiredFields: []string{"foo"}},
+	}
+	for name, transition := range invalid {
+		t.Run(name, func(t *testing.T) {
+			wit := workflowWIT()
+			wit.Transitions = append(wit.Transitions, transition)
+			err := wit.ValidateTransitions()
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+	}
+}
+
+func TestTransitionsFrom(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	t.Run("workflow", func(t *testing.T) {
+		transitions := workflowWIT().TransitionsFrom("in progress")
+		to := []string{}
+		for _, transition := range transitions {
+			to = append(to, transition.To)
+		}
+		assert.Equal(t, []string{"new", "resolved", "closed"}, to)
+	})
+	t.Run("no workflow", func(t *testing.T) {
+		wit := workflowWIT()
+		wit.Transitions = nil
+		assert.Equal(t, workitem.Transitions{
+			{From: "new", To: "in progress"},
+			{From: "new", To: "resolved"},
+			{From: "new", To: "closed"},
+		}, wit.TransitionsFrom("new"))
+	})
+}
+
+func TestCheckTransition(t *testing.T) {
+	resource.Require(t, resource.UnitTest)
+	wit := workflowWIT()
+	state := func(s string, assignees ...interface{}) workitem.Fields {
+		fields := workitem.Fields{workitem.SystemState: s}
+		if len(assignees) > 0 {
+			fields[workitem.SystemAssignees] = assignees
+		}
+		return fields
+	}
+	t.Run("allowed", func(t *testing.T) {
+		assert.NoError(t, wit.CheckTransition(state("new"), state("in progress")))
+		assert.NoError(t, wit.CheckTransition(state("new"), state("closed")))
+		assert.NoError(t, wit.CheckTransition(state("new"), state("new")))
+		assert.NoError(t, wit.CheckTransition(state("in progress"), state("resolved", "someone")))
+	})
+	t.Run("not allowed", func(t *testing.T) {
+		err := wit.CheckTransition(state("new"), state("resolved"))
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	t.Run("required field missing", func(t *testing.T) {
+		err := wit.CheckTransition(state("in progress"), state("resolved"))
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		assert.Contains(t, err.Error(), workitem.SystemAssignees)
+	})
+	t.Run("no workflow", func(t *testing.T) {
+		wit := workflowWIT()
+		wit.Transitions = nil
+		assert.NoError(t, wit.CheckTransition(state("new"), state("resolved")))
+	})
+}
@@ -22,6 +22,10 @@ type WorkItem struct {
 	SpaceID uuid.UUID
 	// The field values, according to the field type
 	Fields map[string]interface{}
+	// Warnings describe problems which didn't keep the work item from being
+	// created or saved, e.g. an exceeded work in progress limit which isn't
+	// enforced. They aren't stored.
+	Warnings []string
 	// optional, private timestamp of the latest addition/removal of a relationship with this workitem
 	// this field is used to generate the `ETag` and `Last-Modified` values in the HTTP responses and conditional requests processing
 	relationShipsChangedAt *time.Time
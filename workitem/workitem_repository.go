@@ -2,7 +2,9 @@ package workitem
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -51,33 +53,125 @@ var (
 	SortWorkItemsByDefault       = SortWorkItemsByExecutionDesc
 )
 
+// sortColumns maps the well-known sort keys to their columns in the work_items
+// table. The columns are qualified with the table name to avoid ambiguities
+// when the work items are joined with other tables (see TableJoin).
+var sortColumns = map[string]string{
+	"execution":     Column(WorkItemStorage{}.TableName(), "execution_order"),
+	"created":       Column(WorkItemStorage{}.TableName(), "created_at"),
+	"updated":       Column(WorkItemStorage{}.TableName(), "updated_at"),
+	"number":        Column(WorkItemStorage{}.TableName(), "number"),
+	SystemOrder:     Column(WorkItemStorage{}.TableName(), "execution_order"),
+	SystemCreatedAt: Column(WorkItemStorage{}.TableName(), "created_at"),
+	SystemUpdatedAt: Column(WorkItemStorage{}.TableName(), "updated_at"),
+	SystemNumber:    Column(WorkItemStorage{}.TableName(), "number"),
+}
+
+// sortTiebreaker ends every parsed sort order so that work items with equal
+// sort keys are always returned in the same order, which pagination relies on.
+var sortTiebreaker = Column(WorkItemStorage{}.TableName(), "id") + " ASC"
+
+// sortKeyRegex restricts the sort keys to characters that are allowed in field
+// names because the keys end up in the ORDER BY clause.
+var sortKeyRegex = regexp.MustCompile(`^[a-zA-Z0-9_.]+$`)
+
+// FieldDefinitionLoader looks up the definition of a work item field by its
+// name
+type FieldDefinitionLoader interface {
+	LoadFieldDefinition(ctx context.Context, name string) (*FieldDefinition, error)
+}
+
+// SpaceTemplateFields is a FieldDefinitionLoader which looks up the fields in
+// the work item types of a single space template, i.e. the types which the
+// work items of a space can have.
+type SpaceTemplateFields struct {
+	Types           WorkItemTypeRepository
+	SpaceTemplateID uuid.UUID
+}
+
+// LoadFieldDefinition implements FieldDefinitionLoader
+func (f SpaceTemplateFields) LoadFieldDefinition(ctx context.Context, name string) (*FieldDefinition, error) {
+	return f.Types.LoadFieldDefinition(ctx, f.SpaceTemplateID, name)
+}
+
 // ParseSortWorkItemsBy parses the string input and returns object of type SortWorkItemsBy
 // which can directly be used while querying database to order the output.
-func ParseSortWorkItemsBy(s *string) (SortWorkItemsBy, error) {
+//
+// The input is a comma separated list of sort keys, e.g. "-priority,created".
+// A key prefixed with "-" sorts in descending order. Apart from the well-known
+// keys ("execution", "created", "updated", "number") any field of a work item
+// type can be used as a key (e.g. "system.title" or a custom enum field). The
+// fields are looked up with the given fields and a BadParameterError is
+// returned for unknown ones. Without fields only the system fields can be used.
+// Enum fields are ordered by the position of their values in the declared
+// values, all other fields by their jsonb value which orders numbers
+// numerically and strings lexically. Fields whose values change as time passes
+// can't be used because their stored values are outdated. The ID of the work
+// items is appended as the last key.
+func ParseSortWorkItemsBy(ctx context.Context, s *string, fields FieldDefinitionLoader) (SortWorkItemsBy, error) {
 	if s == nil {
 		// this is the default case
 		// which returns workitems with highest execution order
-		return SortWorkItemsByDefault, nil
-	}
-
-	var sort SortWorkItemsBy
-	switch *s {
-	case "execution":
-		sort = SortWorkItemsByExecutionAsc
-	case "-execution":
-		sort = SortWorkItemsByExecutionDesc
-	case "created":
-		sort = SortWorkItemsByCreatedAtAsc
-	case "-created":
-		sort = SortWorkItemsByCreatedAtDesc
-	case "updated":
-		sort = SortWorkItemsByUpdatedAtAsc
-	case "-updated":
-		sort = SortWorkItemsByUpdatedAtDesc
-	default:
-		return SortWorkItemsBy(""), errors.NewBadParameterError("sort", *s)
+		return SortWorkItemsBy(string(SortWorkItemsByDefault) + ", " + sortTiebreaker), nil
+	}
+
+	keys := strings.Split(*s, ",")
+	clauses := make([]string, len(keys), len(keys)+1)
+	for i, key := range keys {
+		key = strings.TrimSpace(key)
+		direction := "ASC"
+		if strings.HasPrefix(key, "-") {
+			direction = "DESC"
+			key = strings.TrimPrefix(key, "-")
+		}
+		col, ok := sortColumns[key]
+		if !ok {
+			if !sortKeyRegex.MatchString(key) {
+				return SortWorkItemsBy(""), errors.NewBadParameterError("sort", *s)
+			}
+			col = Column(WorkItemStorage{}.TableName(), "fields") + "->'" + key + "'"
+			if fields == nil {
+				if !strings.HasPrefix(key, "system.") {
+					return SortWorkItemsBy(""), errors.NewBadParameterError("sort", key).Expected("well-known sort key or system field")
+				}
+			} else {
+				def, err := fields.LoadFieldDefinition(ctx, key)
+				if err != nil {
+					if _, ok := errs.Cause(err).(errors.NotFoundError); ok {
+						return SortWorkItemsBy(""), errors.NewBadParameterError("sort", key).Expected("well-known sort key or field of the work item types")
+					}
+					return SortWorkItemsBy(""), err
+				}
+				if def.DependsOnTime() {
+					return SortWorkItemsBy(""), errors.NewBadParameterError("sort", key).Expected("field whose value doesn't change as time passes")
+				}
+				if values := enumValues(def.Type); values != nil {
+					col, err = enumSortColumn(col, values)
+					if err != nil {
+						return SortWorkItemsBy(""), err
+					}
+				}
+			}
+		}
+		clauses[i] = col + " " + direction
+	}
+	clauses = append(clauses, sortTiebreaker)
+	return SortWorkItemsBy(strings.Join(clauses, ", ")), nil
+}
+
+// enumSortColumn returns an expression which maps the value of an enum field
+// to the position of the value in the declared values. Values which aren't
+// declared are mapped to NULL and come last when sorted in ascending order.
+func enumSortColumn(col string, values []interface{}) (string, error) {
+	expr := "CASE " + col
+	for i, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return "", errs.Wrapf(err, "failed to marshal enum value %v", v)
+		}
+		expr += " WHEN '" + strings.Replace(string(b), "'", "''", -1) + "'::jsonb THEN " + strconv.Itoa(i)
 	}
-	return sort, nil
+	return expr + " END", nil
 }
 
 // WorkItemRepository encapsulates storage & retrieval of work items
@@ -89,14 +183,17 @@ type WorkItemRepository interface {
 	LoadByIteration(ctx context.Context, id uuid.UUID) ([]*WorkItem, error)
 	LookupIDByNamedSpaceAndNumber(ctx context.Context, ownerName, spaceName string, wiNumber int) (*uuid.UUID, *uuid.UUID, error)
 	Save(ctx context.Context, spaceID uuid.UUID, wi WorkItem, modifierID uuid.UUID) (*WorkItem, error)
+	Restore(ctx context.Context, id uuid.UUID, version int, modifierID uuid.UUID) (*WorkItem, error)
 	Reorder(ctx context.Context, spaceID uuid.UUID, direction DirectionType, targetID *uuid.UUID, wi WorkItem, modifierID uuid.UUID) (*WorkItem, error)
 	Delete(ctx context.Context, id uuid.UUID, suppressorID uuid.UUID) error
 	Create(ctx context.Context, spaceID uuid.UUID, typeID uuid.UUID, fields map[string]interface{}, creatorID uuid.UUID) (*WorkItem, error)
 	List(ctx context.Context, spaceID uuid.UUID, criteria criteria.Expression, parentExists *bool, start *int, length *int, sort SortWorkItemsBy) ([]WorkItem, int, error)
 	Fetch(ctx context.Context, spaceID uuid.UUID, criteria criteria.Expression) (*WorkItem, error)
 	GetCountsPerIteration(ctx context.Context, spaceID uuid.UUID) (map[string]WICountsPerIteration, error)
+	GetCountsPerBoardColumn(ctx context.Context, spaceID uuid.UUID) (map[string]int, error)
 	GetCountsForIteration(ctx context.Context, itr *iteration.Iteration) (map[string]WICountsPerIteration, error)
 	Count(ctx context.Context, spaceID uuid.UUID, criteria criteria.Expression) (int, error)
+	RecomputeFields(ctx context.Context, ids ...uuid.UUID) error
 }
 
 // NewWorkItemRepository creates a GormWorkItemRepository
@@ -541,7 +638,7 @@ func (r *GormWorkItemRepository) Reorder(ctx context.Context, spaceID uuid.UUID,
 	res.ExecutionOrder = order
 
 	for fieldName, fieldDef := range wiType.Fields {
-		if fieldDef.ReadOnly {
+		if fieldDef.ReadOnly || fieldDef.IsComputed() {
 			continue
 		}
 		fieldValue := wi.Fields[fieldName]
@@ -551,6 +648,9 @@ func (r *GormWorkItemRepository) Reorder(ctx context.Context, spaceID uuid.UUID,
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	if _, err := r.computeFields(ctx, *wiType, &res); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	tx = tx.Where("Version = ?", wi.Version).Save(&res)
 	if err := tx.Error; err != nil {
 		return nil, errors.NewInternalError(ctx, err)
@@ -579,10 +679,11 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, spaceID uuid.UUID, up
 	}
 	wiStorage.Version = wiStorage.Version + 1
 	wiStorage.Type = updatedWorkItem.Type
+	oldFields := wiStorage.Fields
 	wiStorage.Fields = Fields{}
 
 	for fieldName, fieldDef := range wiType.Fields {
-		if fieldDef.ReadOnly {
+		if fieldDef.ReadOnly || fieldDef.IsComputed() {
 			continue
 		}
 		fieldValue := updatedWorkItem.Fields[fieldName]
@@ -606,6 +707,28 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, spaceID uuid.UUID, up
 			return nil, errors.NewBadParameterError(fieldName, fieldValue)
 		}
 	}
+	columns, err := r.addedBoardColumns(ctx, oldFields, wiStorage.Fields)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := applyTransitionRules(*wiType, columns, oldFields, wiStorage.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	warnings, err := r.checkWIPLimits(ctx, spaceID, wiStorage.ID, columns)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := wiType.CheckTransition(oldFields, wiStorage.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.lockIteration(ctx, oldFields, wiStorage.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	// gorm sets the time of the update when saving
+	wiStorage.UpdatedAt = time.Now()
+	if _, err := r.computeFields(ctx, *wiType, wiStorage); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	tx := r.db.Where("Version = ?", updatedWorkItem.Version).Save(&wiStorage)
 	if err := tx.Error; err != nil {
 		log.Error(ctx, map[string]interface{}{
@@ -624,11 +747,246 @@ func (r *GormWorkItemRepository) Save(ctx context.Context, spaceID uuid.UUID, up
 	if err != nil {
 		return nil, errs.Wrapf(err, "error while saving work item")
 	}
+	// the computed fields of the parents may depend on the changed values
+	parents, err := r.parentIDs(ctx, wiStorage.ID)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.RecomputeFields(ctx, parents...); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	log.Info(ctx, map[string]interface{}{
 		"wi_id":    updatedWorkItem.ID,
 		"space_id": spaceID,
 	}, "Updated work item repository")
-	return ConvertWorkItemStorageToModel(wiType, wiStorage)
+	result, err := ConvertWorkItemStorageToModel(wiType, wiStorage)
+	if err != nil {
+		return nil, err
+	}
+	result.Warnings = warnings
+	return result, nil
+}
+
+// boardColumnIDs returns the IDs of the board columns in the given value of
+// the board columns field
+func boardColumnIDs(value interface{}) []uuid.UUID {
+	var values []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		values = v
+	case []string:
+		for _, s := range v {
+			values = append(values, s)
+		}
+	}
+	ids := []uuid.UUID{}
+	for _, v := range values {
+		s, _ := v.(string)
+		if id, err := uuid.FromString(s); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// addedBoardColumns returns the board columns into which the work item is
+// moved, i.e. the columns which are in the new but not in the old fields,
+// ordered by their position.
+func (r *GormWorkItemRepository) addedBoardColumns(ctx context.Context, oldFields, newFields Fields) ([]BoardColumn, error) {
+	old := map[uuid.UUID]struct{}{}
+	for _, id := range boardColumnIDs(oldFields[SystemBoardcolumns]) {
+		old[id] = struct{}{}
+	}
+	added := []uuid.UUID{}
+	for _, id := range boardColumnIDs(newFields[SystemBoardcolumns]) {
+		if _, ok := old[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+	var columns []BoardColumn
+	db := r.db.Where("id IN (?)", added).Order("column_order").Find(&columns)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"column_ids": added,
+			"err":        db.Error,
+		}, "unable to load the board columns")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to load the board columns"))
+	}
+	return columns, nil
+}
+
+// applyTransitionRules applies the transition rules of the board columns into
+// which the work item is moved. The rules are applied in the order of the
+// columns.
+func applyTransitionRules(wiType WorkItemType, columns []BoardColumn, oldFields, newFields Fields) error {
+	for _, column := range columns {
+		rule, err := column.TransitionRule()
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		if rule == nil {
+			continue
+		}
+		if err := rule.Apply(wiType, oldFields, newFields); err != nil {
+			return errs.Wrapf(err, "failed to move the work item into board column \"%s\"", column.Name)
+		}
+	}
+	return nil
+}
+
+// lockIteration takes a share lock on the iteration into which the work item
+// is moved until the end of the transaction. An iteration which is rolled over
+// is locked for update, so a work item can't slip into it unnoticed while its
+// unfinished work items are moved and it is closed.
+func (r *GormWorkItemRepository) lockIteration(ctx context.Context, oldFields, newFields Fields) error {
+	iterationID := newFields[SystemIteration]
+	if iterationID == nil || iterationID == oldFields[SystemIteration] {
+		return nil
+	}
+	if err := r.db.Exec("SELECT 1 FROM iterations WHERE id = ? FOR SHARE", iterationID).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"iteration_id": iterationID,
+			"err":          err,
+		}, "unable to lock the iteration")
+		return errors.NewInternalError(ctx, errs.Wrap(err, "failed to lock the iteration"))
+	}
+	return nil
+}
+
+// checkWIPLimits checks the work in progress limits of the board columns into
+// which the work item is moved. If a column would hold more work items of the
+// space than its limit allows, a DataConflictError is returned for enforced
+// limits and a warning is returned otherwise. The space is locked until the
+// end of the transaction, so that concurrent moves into a column are counted
+// one after the other and can't exceed its limit together.
+func (r *GormWorkItemRepository) checkWIPLimits(ctx context.Context, spaceID, wiID uuid.UUID, columns []BoardColumn) ([]string, error) {
+	limited := false
+	for _, column := range columns {
+		if column.WIPLimit > 0 {
+			limited = true
+		}
+	}
+	if !limited {
+		return nil, nil
+	}
+	if err := r.db.Exec("SELECT 1 FROM spaces WHERE id = ? FOR UPDATE", spaceID).Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      err,
+		}, "unable to lock the space")
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to lock the space"))
+	}
+	var warnings []string
+	for _, column := range columns {
+		if column.WIPLimit <= 0 {
+			continue
+		}
+		count, err := r.countInBoardColumn(ctx, spaceID, column.ID, wiID)
+		if err != nil {
+			return nil, errs.WithStack(err)
+		}
+		if count < column.WIPLimit {
+			continue
+		}
+		if column.WIPLimitEnforced {
+			return nil, errors.NewDataConflictError(fmt.Sprintf("board column \"%s\" already holds %d work items and its work in progress limit is %d", column.Name, count, column.WIPLimit))
+		}
+		log.Warn(ctx, map[string]interface{}{
+			"wi_id":     wiID,
+			"space_id":  spaceID,
+			"column_id": column.ID,
+			"count":     count + 1,
+			"wip_limit": column.WIPLimit,
+		}, "work in progress limit of board column exceeded")
+		warnings = append(warnings, fmt.Sprintf("board column \"%s\" holds %d work items and its work in progress limit is %d", column.Name, count+1, column.WIPLimit))
+	}
+	return warnings, nil
+}
+
+// countInBoardColumn returns the number of work items of the space in the
+// given board column, not counting the work item with the given ID.
+func (r *GormWorkItemRepository) countInBoardColumn(ctx context.Context, spaceID, columnID, excludedWIID uuid.UUID) (int, error) {
+	var count int
+	db := r.db.Model(&WorkItemStorage{}).
+		Where("space_id = ? AND id <> ?", spaceID, excludedWIID).
+		Where(fmt.Sprintf("fields->'%s' @> ?", SystemBoardcolumns), fmt.Sprintf(`["%s"]`, columnID)).
+		Count(&count)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id":  spaceID,
+			"column_id": columnID,
+			"err":       db.Error,
+		}, "unable to count the work items in the board column")
+		return 0, errors.NewInternalError(ctx, errs.Wrap(db.Error, "failed to count the work items in the board column"))
+	}
+	return count, nil
+}
+
+// GetCountsPerBoardColumn counts the work items of a space per board column
+// and returns a map of boardColumnID->count
+func (r *GormWorkItemRepository) GetCountsPerBoardColumn(ctx context.Context, spaceID uuid.UUID) (map[string]int, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "getCountsPerBoardColumn"}, time.Now())
+	type columnCount struct {
+		ColumnID string `gorm:"column:columnid"`
+		Total    int
+	}
+	var res []columnCount
+	query := fmt.Sprintf(`SELECT col AS columnid, count(*) AS total
+		FROM %[1]s wi, jsonb_array_elements_text(wi.fields->'%[2]s') col
+		WHERE wi.space_id = ?
+		AND wi.fields @> '{"%[2]s": []}'
+		AND wi.deleted_at IS NULL
+		GROUP BY col`,
+		workitemTableName, SystemBoardcolumns)
+	db := r.db.Raw(query, spaceID)
+	db.Scan(&res)
+	if db.Error != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_id": spaceID,
+			"err":      db.Error,
+		}, "unable to count work items per board column")
+		return nil, errors.NewInternalError(ctx, db.Error)
+	}
+	countsMap := map[string]int{}
+	for _, c := range res {
+		countsMap[c.ColumnID] = c.Total
+	}
+	return countsMap, nil
+}
+
+// Restore sets the fields of the work item back to the values they had in the
+// revision with the given work item version. The restored work item is stored
+// like any other update, hence a new revision is written.
+// returns NotFoundError, VersionConflictError, ConversionError or InternalError
+func (r *GormWorkItemRepository) Restore(ctx context.Context, id uuid.UUID, version int, modifierID uuid.UUID) (*WorkItem, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitem", "restore"}, time.Now())
+	current, err := r.LoadFromDB(ctx, id)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	revision, err := r.wirr.LoadByVersion(ctx, id, version)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if revision.Type == RevisionTypeDelete {
+		return nil, errors.NewBadParameterError("version", version).Expected("version of a revision that is not a deletion")
+	}
+	wiType, err := r.witr.Load(ctx, current.Type)
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	// The number, type and position of the work item are kept, only the field
+	// values are taken from the revision.
+	restored := *current
+	restored.Fields = revision.WorkItemFields
+	wi, err := ConvertWorkItemStorageToModel(wiType, &restored)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	return r.Save(ctx, current.SpaceID, *wi, modifierID)
 }
 
 // Create creates a new work item in the repository
@@ -686,7 +1044,7 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, spaceID uuid.UUID,
 	}
 	fields[SystemCreator] = creatorID.String()
 	for fieldName, fieldDef := range wiType.Fields {
-		if fieldDef.ReadOnly {
+		if fieldDef.ReadOnly || fieldDef.IsComputed() {
 			continue
 		}
 		fieldValue := fields[fieldName]
@@ -705,6 +1063,26 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, spaceID uuid.UUID,
 			}
 		}
 	}
+	// the board columns of a new work item are checked like the ones a work
+	// item is moved into and their rules determine its state
+	oldFields := Fields{SystemState: wi.Fields[SystemState]}
+	columns, err := r.addedBoardColumns(ctx, oldFields, wi.Fields)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := applyTransitionRules(*wiType, columns, oldFields, wi.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	warnings, err := r.checkWIPLimits(ctx, spaceID, wi.ID, columns)
+	if err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if err := r.lockIteration(ctx, nil, wi.Fields); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if _, err := r.computeFields(ctx, *wiType, &wi); err != nil {
+		return nil, errs.WithStack(err)
+	}
 	if err = r.db.Create(&wi).Error; err != nil {
 		return nil, errs.Wrapf(err, "failed to create work item")
 	}
@@ -713,6 +1091,7 @@ func (r *GormWorkItemRepository) Create(ctx context.Context, spaceID uuid.UUID,
 	if err != nil {
 		return nil, err
 	}
+	witem.Warnings = warnings
 	// store a revision of the created work item
 	err = r.wirr.Create(context.Background(), creatorID, RevisionTypeCreate, wi)
 	if err != nil {
@@ -740,6 +1119,22 @@ func ConvertWorkItemStorageToModel(wiType *WorkItemType, wi *WorkItemStorage) (*
 	if _, ok := wiType.Fields[SystemNumber]; ok {
 		result.Fields[SystemNumber] = wi.Number
 	}
+	// values which only depend on the work item itself may depend on the
+	// current time, so they are evaluated again
+	now := time.Now()
+	for name, def := range wiType.Fields {
+		t, ok := def.computedType()
+		if !ok || t.Formula.Children {
+			continue
+		}
+		value, err := t.Compute(storedFields(*wi, now), nil, now)
+		if err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+		if result.Fields[name], err = t.ConvertFromModel(value); err != nil {
+			return nil, errors.NewConversionError(err.Error())
+		}
+	}
 	return result, nil
 
 }
@@ -1131,3 +1526,136 @@ func (r *GormWorkItemRepository) LoadByIteration(ctx context.Context, iterationI
 	}
 	return workitems, nil
 }
+
+// storedFields returns the stored fields of the work item together with the
+// times of its creation and last update, which computed fields may refer to.
+// A time which isn't set yet is replaced by the given time.
+func storedFields(wi WorkItemStorage, now time.Time) Fields {
+	res := Fields{}
+	for name, value := range wi.Fields {
+		res[name] = value
+	}
+	res[SystemCreatedAt] = now.UnixNano()
+	if !wi.CreatedAt.IsZero() {
+		res[SystemCreatedAt] = wi.CreatedAt.UnixNano()
+	}
+	res[SystemUpdatedAt] = now.UnixNano()
+	if !wi.UpdatedAt.IsZero() {
+		res[SystemUpdatedAt] = wi.UpdatedAt.UnixNano()
+	}
+	return res
+}
+
+// treeLinksSQL selects the links of all link types with a tree topology
+const treeLinksSQL = `SELECT l.source_id, l.target_id FROM work_item_links l
+	JOIN work_item_link_types t ON t.id = l.link_type_id
+	WHERE t.topology = 'tree' AND l.deleted_at IS NULL`
+
+// computeFields sets the values of the computed fields of the given work item
+// and returns true if one of them has changed. Values that are aggregated over
+// the children of the work item are derived from their stored values.
+func (r *GormWorkItemRepository) computeFields(ctx context.Context, wiType WorkItemType, wi *WorkItemStorage) (bool, error) {
+	now := time.Now()
+	fields := storedFields(*wi, now)
+	var children []Fields
+	childrenLoaded := false
+	changed := false
+	for name, def := range wiType.Fields {
+		t, ok := def.computedType()
+		if !ok {
+			continue
+		}
+		if t.Formula.Children && !childrenLoaded {
+			var storages []WorkItemStorage
+			db := r.db.Where("id IN (SELECT target_id FROM ("+treeLinksSQL+") AS tree WHERE source_id = ?)", wi.ID).Find(&storages)
+			if db.Error != nil {
+				return false, errors.NewInternalError(ctx, db.Error)
+			}
+			for _, child := range storages {
+				children = append(children, storedFields(child, now))
+			}
+			childrenLoaded = true
+		}
+		value, err := t.Compute(fields, children, now)
+		if err != nil {
+			return false, errs.Wrapf(err, "failed to compute field %q", name)
+		}
+		oldValue, hadValue := numericValue(wi.Fields[name])
+		newValue, hasValue := numericValue(value)
+		if hadValue != hasValue || oldValue != newValue {
+			changed = true
+		}
+		if wi.Fields == nil {
+			wi.Fields = Fields{}
+		}
+		if value == nil {
+			delete(wi.Fields, name)
+			continue
+		}
+		wi.Fields[name] = value
+	}
+	return changed, nil
+}
+
+// parentIDs returns the IDs of the work items which are linked to the given
+// work item as its parents by a link type with a tree topology.
+func (r *GormWorkItemRepository) parentIDs(ctx context.Context, id uuid.UUID) ([]uuid.UUID, error) {
+	var res []uuid.UUID
+	rows, err := r.db.Raw("SELECT source_id FROM ("+treeLinksSQL+") AS tree WHERE target_id = ?", id).Rows()
+	if err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	defer closeable.Close(ctx, rows)
+	for rows.Next() {
+		var parentID uuid.UUID
+		if err := rows.Scan(&parentID); err != nil {
+			return nil, errors.NewInternalError(ctx, err)
+		}
+		res = append(res, parentID)
+	}
+	return res, nil
+}
+
+// RecomputeFields updates the stored values of the computed fields of the
+// work items with the given IDs. The ancestors of a work item whose values
+// have changed are updated as well. The values are changed in place without a
+// new version or revision of the work items because nobody has edited them.
+func (r *GormWorkItemRepository) RecomputeFields(ctx context.Context, ids ...uuid.UUID) error {
+	visited := map[uuid.UUID]struct{}{}
+	for len(ids) > 0 {
+		id := ids[0]
+		ids = ids[1:]
+		if _, ok := visited[id]; ok {
+			continue
+		}
+		visited[id] = struct{}{}
+		wi, err := r.LoadFromDB(ctx, id)
+		if err != nil {
+			if ok, _ := errors.IsNotFoundError(err); ok {
+				continue
+			}
+			return errs.WithStack(err)
+		}
+		wiType, err := r.witr.Load(ctx, wi.Type)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		changed, err := r.computeFields(ctx, *wiType, wi)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		if !changed {
+			continue
+		}
+		db := r.db.Model(wi).UpdateColumn("fields", wi.Fields)
+		if db.Error != nil {
+			return errors.NewInternalError(ctx, db.Error)
+		}
+		parents, err := r.parentIDs(ctx, id)
+		if err != nil {
+			return errs.WithStack(err)
+		}
+		ids = append(ids, parents...)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/davecgh/go-spew/spew"
 	"github.com/fabric8-services/fabric8-wit/codebase"
+	"github.com/fabric8-services/fabric8-wit/criteria"
 	"github.com/fabric8-services/fabric8-wit/errors"
 	"github.com/fabric8-services/fabric8-wit/gormtestsupport"
 	"github.com/fabric8-services/fabric8-wit/id"
@@ -19,6 +20,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/space"
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/fabric8-services/fabric8-wit/workitem"
+	"github.com/fabric8-services/fabric8-wit/workitem/link"
 	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
@@ -121,6 +123,89 @@ func (s *workItemRepoBlackBoxTest) TestSave() {
 	})
 }
 
+func (s *workItemRepoBlackBoxTest) TestRestore() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItems[idx].Fields[workitem.SystemTitle] = "old title"
+			return nil
+		}))
+		oldVersion := fxt.WorkItems[0].Version
+		fxt.WorkItems[0].Fields[workitem.SystemTitle] = "new title"
+		fxt.WorkItems[0].Fields[workitem.SystemLabels] = []string{"label1"}
+		wiNew, err := s.repo.Save(s.Ctx, fxt.WorkItems[0].SpaceID, *fxt.WorkItems[0], fxt.Identities[0].ID)
+		require.NoError(t, err)
+		// when
+		restored, err := s.repo.Restore(s.Ctx, fxt.WorkItems[0].ID, oldVersion, fxt.Identities[0].ID)
+		// then
+		require.NoError(t, err)
+		assert.Equal(t, "old title", restored.Fields[workitem.SystemTitle])
+		assert.Empty(t, restored.Fields[workitem.SystemLabels])
+		assert.Equal(t, wiNew.Version+1, restored.Version)
+		revisions, err := workitem.NewRevisionRepository(s.DB).List(s.Ctx, fxt.WorkItems[0].ID)
+		require.NoError(t, err)
+		require.Len(t, revisions, 3)
+		assert.Equal(t, restored.Version, revisions[2].WorkItemVersion)
+	})
+
+	s.T().Run("fail - unknown version", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItems(1))
+		// when
+		_, err := s.repo.Restore(s.Ctx, fxt.WorkItems[0].ID, 42, fxt.Identities[0].ID)
+		// then
+		assert.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *workItemRepoBlackBoxTest) TestSaveEnforcesTransitions() {
+	// given a type whose work items can only be resolved when they are open
+	// and assigned
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Transitions = workitem.Transitions{
+				{From: workitem.SystemStateNew, To: workitem.SystemStateOpen},
+				{From: workitem.SystemStateOpen, To: workitem.SystemStateResolved, RequiredFields: []string{workitem.SystemAssignees}},
+			}
+			return nil
+		}),
+		tf.WorkItems(1),
+	)
+	wi := *fxt.WorkItems[0]
+
+	s.T().Run("not allowed", func(t *testing.T) {
+		wi := wi
+		wi.Fields[workitem.SystemState] = workitem.SystemStateResolved
+		_, err := s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+		require.Error(t, err)
+		assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("allowed", func(t *testing.T) {
+		wi.Fields[workitem.SystemState] = workitem.SystemStateOpen
+		saved, err := s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.SystemStateOpen, saved.Fields[workitem.SystemState])
+		wi = *saved
+
+		t.Run("required field missing", func(t *testing.T) {
+			wi := wi
+			wi.Fields[workitem.SystemState] = workitem.SystemStateResolved
+			_, err := s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+			require.Error(t, err)
+			assert.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+
+		t.Run("required field set", func(t *testing.T) {
+			wi.Fields[workitem.SystemState] = workitem.SystemStateResolved
+			wi.Fields[workitem.SystemAssignees] = []string{fxt.Identities[0].ID.String()}
+			saved, err := s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+			require.NoError(t, err)
+			assert.Equal(t, workitem.SystemStateResolved, saved.Fields[workitem.SystemState])
+		})
+	})
+}
+
 func (s *workItemRepoBlackBoxTest) TestLoadID() {
 	s.T().Run("fail - load nil ID", func(t *testing.T) {
 		_, err := s.repo.LoadByID(s.Ctx, uuid.Nil)
@@ -387,6 +472,102 @@ func (s *workItemRepoBlackBoxTest) TestGetCountsPerIteration() {
 	})
 }
 
+func (s *workItemRepoBlackBoxTest) TestGetCountsPerBoardColumn() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		testFxt := tf.NewTestFixture(t, s.DB, tf.WorkItemBoards(1), tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			columns := fxt.WorkItemBoards[0].Columns
+			if idx < 2 {
+				fxt.WorkItems[idx].Fields[workitem.SystemBoardcolumns] = []interface{}{columns[0].ID.String()}
+			} else {
+				fxt.WorkItems[idx].Fields[workitem.SystemBoardcolumns] = []interface{}{columns[0].ID.String(), columns[1].ID.String()}
+			}
+			return nil
+		}))
+		// when
+		countsMap, err := s.repo.GetCountsPerBoardColumn(s.Ctx, testFxt.Spaces[0].ID)
+		// then
+		require.NoError(t, err)
+		columns := testFxt.WorkItemBoards[0].Columns
+		assert.Equal(t, map[string]int{
+			columns[0].ID.String(): 3,
+			columns[1].ID.String(): 1,
+		}, countsMap)
+	})
+}
+
+func (s *workItemRepoBlackBoxTest) TestSaveChecksWIPLimits() {
+	// given a board whose first column allows only one work item and whose
+	// second column warns about more than one work item
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemBoards(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemBoards[idx].Columns[0].WIPLimit = 1
+			fxt.WorkItemBoards[idx].Columns[0].WIPLimitEnforced = true
+			fxt.WorkItemBoards[idx].Columns[1].WIPLimit = 1
+			return nil
+		}),
+		tf.WorkItems(3, func(fxt *tf.TestFixture, idx int) error {
+			if idx == 0 {
+				fxt.WorkItems[idx].Fields[workitem.SystemBoardcolumns] = []interface{}{fxt.WorkItemBoards[0].Columns[0].ID.String()}
+			}
+			return nil
+		}),
+	)
+	columns := fxt.WorkItemBoards[0].Columns
+	move := func(wi workitem.WorkItem, column workitem.BoardColumn) (*workitem.WorkItem, error) {
+		wi.Fields[workitem.SystemBoardcolumns] = []interface{}{column.ID.String()}
+		return s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+	}
+
+	s.T().Run("enforced limit reached", func(t *testing.T) {
+		_, err := move(*fxt.WorkItems[1], columns[0])
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("work item already in the column", func(t *testing.T) {
+		wi := *fxt.WorkItems[0]
+		wi.Fields[workitem.SystemTitle] = "updated title"
+		_, err := s.repo.Save(s.Ctx, wi.SpaceID, wi, fxt.Identities[0].ID)
+		require.NoError(t, err)
+	})
+
+	s.T().Run("limit exceeded with a warning", func(t *testing.T) {
+		wi, err := move(*fxt.WorkItems[1], columns[1])
+		require.NoError(t, err)
+		assert.Empty(t, wi.Warnings)
+		wi, err = move(*fxt.WorkItems[2], columns[1])
+		require.NoError(t, err)
+		assert.Len(t, wi.Warnings, 1)
+		countsMap, err := s.repo.GetCountsPerBoardColumn(s.Ctx, fxt.Spaces[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, countsMap[columns[1].ID.String()])
+	})
+
+	create := func(column workitem.BoardColumn) (*workitem.WorkItem, error) {
+		return s.repo.Create(s.Ctx, fxt.Spaces[0].ID, fxt.WorkItemTypes[0].ID, map[string]interface{}{
+			workitem.SystemTitle:        "new work item",
+			workitem.SystemState:        workitem.SystemStateNew,
+			workitem.SystemBoardcolumns: []interface{}{column.ID.String()},
+		}, fxt.Identities[0].ID)
+	}
+
+	s.T().Run("create with enforced limit reached", func(t *testing.T) {
+		_, err := create(columns[0])
+		require.Error(t, err)
+		assert.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("create with a warning and the state of the column", func(t *testing.T) {
+		wi, err := create(columns[1])
+		require.NoError(t, err)
+		assert.Len(t, wi.Warnings, 1)
+		state, err := fxt.WorkItemTypes[0].StateForMetaState("mInprogress")
+		require.NoError(t, err)
+		assert.Equal(t, state, wi.Fields[workitem.SystemState])
+	})
+}
+
 func (s *workItemRepoBlackBoxTest) TestLookupIDByNamedSpaceAndNumber() {
 	s.T().Run("ok", func(t *testing.T) {
 		// given
@@ -554,7 +735,7 @@ func (s *workItemRepoBlackBoxTest) TestList() {
 		t.Run("by created descending", func(t *testing.T) {
 			// when
 			exp, _ := query.Parse(ptr.String(`{"system.state": "open"}`))
-			sort, _ := workitem.ParseSortWorkItemsBy(ptr.String("-created"))
+			sort, _ := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("-created"), nil)
 			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
 			// then
 			require.NoError(t, err)
@@ -581,7 +762,7 @@ func (s *workItemRepoBlackBoxTest) TestList() {
 		t.Run("by created ascending", func(t *testing.T) {
 			// when
 			exp, _ := query.Parse(ptr.String(`{"system.state": "open"}`))
-			sort, _ := workitem.ParseSortWorkItemsBy(ptr.String("created"))
+			sort, _ := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("created"), nil)
 			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
 			// then
 			require.NoError(t, err)
@@ -611,7 +792,7 @@ func (s *workItemRepoBlackBoxTest) TestList() {
 				s.repo.Save(context.Background(), fxt.WorkItems[v].SpaceID, *fxt.WorkItems[v], fxt.Identities[0].ID)
 			}
 			exp, _ := query.Parse(ptr.String(`{"system.state": "open"}`))
-			sort, _ := workitem.ParseSortWorkItemsBy(ptr.String("-updated"))
+			sort, _ := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("-updated"), nil)
 			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
 			// then
 			require.NoError(t, err)
@@ -641,7 +822,7 @@ func (s *workItemRepoBlackBoxTest) TestList() {
 				s.repo.Save(context.Background(), fxt.WorkItems[v].SpaceID, *fxt.WorkItems[v], fxt.Identities[0].ID)
 			}
 			exp, _ := query.Parse(ptr.String(`{"system.state": "open"}`))
-			sort, _ := workitem.ParseSortWorkItemsBy(ptr.String("updated"))
+			sort, _ := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("updated"), nil)
 			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
 			// then
 			require.NoError(t, err)
@@ -665,6 +846,241 @@ func (s *workItemRepoBlackBoxTest) TestList() {
 			}
 			require.Empty(t, toBeFound, "failed to find all work items: %+s", toBeFound)
 		})
+		t.Run("by field and created descending", func(t *testing.T) {
+			// when
+			exp, _ := query.Parse(nil)
+			sort, err := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String(workitem.SystemState+",-created"), nil)
+			require.NoError(t, err)
+			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, 10, count)
+			// "new" work items come before "open" ones
+			for i, v := range []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0} {
+				require.Equal(t, fxt.WorkItems[v].ID, res[i].ID)
+			}
+		})
+		t.Run("paged by field", func(t *testing.T) {
+			// when
+			exp, _ := query.Parse(nil)
+			sort, err := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("-"+workitem.SystemState+",created"), nil)
+			require.NoError(t, err)
+			res, count, err := s.repo.List(context.Background(), fxt.Spaces[0].ID, exp, nil, ptr.Int(6), ptr.Int(2), sort)
+			// then
+			require.NoError(t, err)
+			assert.Equal(t, 10, count)
+			require.Len(t, res, 2)
+			require.Equal(t, fxt.WorkItems[6].ID, res[0].ID)
+			require.Equal(t, fxt.WorkItems[7].ID, res[1].ID)
+		})
+	})
+	s.T().Run("list by enum field", func(t *testing.T) {
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItemTypes[idx].Fields["priority"] = workitem.FieldDefinition{
+					Label:       "Priority",
+					Description: "The priority of the work item",
+					Type: workitem.EnumType{
+						SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+						BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+						Values:     []interface{}{"high", "medium", "low"},
+					},
+				}
+				return nil
+			}),
+			tf.WorkItems(3, tf.SetWorkItemField("priority", "low", "high", "medium")),
+		)
+		fields := workitem.SpaceTemplateFields{Types: workitem.NewWorkItemTypeRepository(s.DB), SpaceTemplateID: fxt.WorkItemTypes[0].SpaceTemplateID}
+		exp, _ := query.Parse(nil)
+		// when
+		sort, err := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("priority"), fields)
+		require.NoError(t, err)
+		res, count, err := s.repo.List(s.Ctx, fxt.Spaces[0].ID, exp, nil, nil, nil, sort)
+		// then the declared order is used instead of the lexical one
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		for i, v := range []int{1, 2, 0} {
+			require.Equal(t, fxt.WorkItems[v].ID, res[i].ID)
+		}
+		t.Run("unknown field", func(t *testing.T) {
+			_, err := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("-unknown"), fields)
+			require.Error(t, err)
+			require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+	})
+}
+
+// sortFields are the field definitions used to parse sort keys
+type sortFields workitem.FieldDefinitions
+
+func (f sortFields) LoadFieldDefinition(ctx context.Context, name string) (*workitem.FieldDefinition, error) {
+	def, ok := f[name]
+	if !ok {
+		return nil, errors.NewNotFoundError("field", name)
+	}
+	return &def, nil
+}
+
+func TestParseSortWorkItemsBy(t *testing.T) {
+	t.Parallel()
+	resource.Require(t, resource.UnitTest)
+	wiTbl := workitem.WorkItemStorage{}.TableName()
+	// the ID always breaks ties
+	tiebreaker := ", " + workitem.Column(wiTbl, "id") + " ASC"
+	t.Run("default", func(t *testing.T) {
+		sort, err := workitem.ParseSortWorkItemsBy(context.Background(), nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, workitem.SortWorkItemsByDefault+workitem.SortWorkItemsBy(tiebreaker), sort)
+	})
+	fields := sortFields{
+		"system.state": {Type: workitem.SimpleType{Kind: workitem.KindString}},
+		"type":         {Type: workitem.SimpleType{Kind: workitem.KindString}},
+		"priority": {Type: workitem.EnumType{
+			SimpleType: workitem.SimpleType{Kind: workitem.KindEnum},
+			BaseType:   workitem.SimpleType{Kind: workitem.KindString},
+			Values:     []interface{}{"high", "it's low"},
+		}},
+	}
+	priority := "CASE " + workitem.Column(wiTbl, "fields") + `->'priority' WHEN '"high"'::jsonb THEN 0 WHEN '"it''s low"'::jsonb THEN 1 END`
+	t.Run("valid", func(t *testing.T) {
+		testData := map[string]workitem.SortWorkItemsBy{
+			"created":            workitem.SortWorkItemsBy(workitem.Column(wiTbl, "created_at") + " ASC"),
+			"-updated":           workitem.SortWorkItemsBy(workitem.Column(wiTbl, "updated_at") + " DESC"),
+			"-system.updated_at": workitem.SortWorkItemsBy(workitem.Column(wiTbl, "updated_at") + " DESC"),
+			"system.title":       workitem.SortWorkItemsBy(workitem.Column(wiTbl, "fields") + "->'system.title' ASC"),
+		}
+		for input, expected := range testData {
+			t.Run(input, func(t *testing.T) {
+				sort, err := workitem.ParseSortWorkItemsBy(context.Background(), ptr.String(input), nil)
+				require.NoError(t, err)
+				require.Equal(t, expected+workitem.SortWorkItemsBy(tiebreaker), sort)
+			})
+		}
+	})
+	t.Run("valid with fields", func(t *testing.T) {
+		testData := map[string]workitem.SortWorkItemsBy{
+			"created":                   workitem.SortWorkItemsBy(workitem.Column(wiTbl, "created_at") + " ASC"),
+			"-priority, execution":      workitem.SortWorkItemsBy(priority + " DESC, " + workitem.Column(wiTbl, "execution_order") + " ASC"),
+			"system.state,-number,type": workitem.SortWorkItemsBy(workitem.Column(wiTbl, "fields") + "->'system.state' ASC, " + workitem.Column(wiTbl, "number") + " DESC, " + workitem.Column(wiTbl, "fields") + "->'type' ASC"),
+		}
+		for input, expected := range testData {
+			t.Run(input, func(t *testing.T) {
+				sort, err := workitem.ParseSortWorkItemsBy(context.Background(), ptr.String(input), fields)
+				require.NoError(t, err)
+				require.Equal(t, expected+workitem.SortWorkItemsBy(tiebreaker), sort)
+			})
+		}
+	})
+	t.Run("invalid", func(t *testing.T) {
+		for _, input := range []string{"", "-", "created,", "system.title'; DROP TABLE work_items", `"foo"`, "foo bar", "-priority"} {
+			t.Run(input, func(t *testing.T) {
+				_, err := workitem.ParseSortWorkItemsBy(context.Background(), ptr.String(input), nil)
+				require.Error(t, err)
+				require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+			})
+		}
+	})
+	t.Run("unknown field", func(t *testing.T) {
+		_, err := workitem.ParseSortWorkItemsBy(context.Background(), ptr.String("created,unknown"), fields)
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+}
+
+func (s *workItemRepoBlackBoxTest) TestComputedFields() {
+	computed := func(resultKind workitem.Kind, formula workitem.Formula) workitem.FieldDefinition {
+		return workitem.FieldDefinition{
+			Label:       string(formula.Function),
+			Description: "computed field",
+			Type: workitem.ComputedType{
+				SimpleType: workitem.SimpleType{Kind: workitem.KindComputed},
+				ResultType: workitem.SimpleType{Kind: resultKind},
+				Formula:    formula,
+			},
+		}
+	}
+	// given a tree D -> A -> (B, C) whose points are 8, 1, 2 and 4
+	fxt := tf.NewTestFixture(s.T(), s.DB,
+		tf.WorkItemTypes(1, func(fxt *tf.TestFixture, idx int) error {
+			fxt.WorkItemTypes[idx].Fields = workitem.FieldDefinitions{
+				"points":       {Label: "Points", Description: "Story points", Type: workitem.SimpleType{Kind: workitem.KindFloat}},
+				"total_points": computed(workitem.KindFloat, workitem.Formula{Function: workitem.FormulaSum, Fields: []string{"points"}, Children: true}),
+				"rollup":       computed(workitem.KindFloat, workitem.Formula{Function: workitem.FormulaSum, Fields: []string{"total_points"}, Children: true}),
+				"children":     computed(workitem.KindInteger, workitem.Formula{Function: workitem.FormulaCount, Children: true}),
+				"age":          computed(workitem.KindInteger, workitem.Formula{Function: workitem.FormulaDaysSince, Fields: []string{workitem.SystemCreatedAt}}),
+			}
+			return nil
+		}),
+		tf.WorkItems(4, tf.SetWorkItemTitles("D", "A", "B", "C"), tf.SetWorkItemField("points", 8.0, 1.0, 2.0, 4.0)),
+		tf.WorkItemLinksCustom(3, tf.BuildLinks(tf.L("D", "A"), tf.L("A", "B"), tf.L("A", "C"))),
+	)
+	load := func(t *testing.T, title string) *workitem.WorkItem {
+		wi, err := s.repo.LoadByID(s.Ctx, fxt.WorkItemByTitle(title).ID)
+		require.NoError(t, err)
+		return wi
+	}
 
+	s.T().Run("aggregated over children and stored", func(t *testing.T) {
+		a := load(t, "A")
+		require.Equal(t, 6.0, a.Fields["total_points"])
+		require.Equal(t, 2.0, a.Fields["children"])
+		require.Equal(t, 0, a.Fields["age"])
+		d := load(t, "D")
+		require.Equal(t, 1.0, d.Fields["total_points"])
+		require.Equal(t, 6.0, d.Fields["rollup"])
+		b := load(t, "B")
+		require.Equal(t, 0.0, b.Fields["total_points"])
+		// the stored value can be searched for
+		res, count, err := s.repo.List(s.Ctx, fxt.Spaces[0].ID, criteria.Equals(workitem.JSONField("total_points"), criteria.Literal(6)), nil, nil, nil, workitem.SortWorkItemsByExecutionDesc)
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		require.Equal(t, a.ID, res[0].ID)
+	})
+
+	s.T().Run("days since are evaluated on read", func(t *testing.T) {
+		// given the work item was created ten days ago and not saved since
+		a := load(t, "A")
+		createdAt := time.Now().Add(-10*24*time.Hour - time.Hour)
+		require.NoError(t, s.DB.Model(&workitem.WorkItemStorage{}).Where("id = ?", a.ID).UpdateColumn("created_at", createdAt).Error)
+		// when
+		a = load(t, "A")
+		// then
+		require.Equal(t, 10, a.Fields["age"])
+	})
+
+	s.T().Run("days since can't be sorted by", func(t *testing.T) {
+		// when
+		_, err := workitem.ParseSortWorkItemsBy(s.Ctx, ptr.String("-age"), workitem.SpaceTemplateFields{Types: workitem.NewWorkItemTypeRepository(s.DB), SpaceTemplateID: fxt.WorkItemTypes[0].SpaceTemplateID})
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("computed values can't be set", func(t *testing.T) {
+		wi, err := s.repo.Create(s.Ctx, fxt.Spaces[0].ID, fxt.WorkItemTypes[0].ID, map[string]interface{}{
+			workitem.SystemTitle: "E",
+			workitem.SystemState: workitem.SystemStateNew,
+			"total_points":       100.0,
+		}, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, 0.0, wi.Fields["total_points"])
+	})
+
+	s.T().Run("update of a child changes the ancestors", func(t *testing.T) {
+		b := load(t, "B")
+		b.Fields["points"] = 5.0
+		_, err := s.repo.Save(s.Ctx, b.SpaceID, *b, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, 9.0, load(t, "A").Fields["total_points"])
+		require.Equal(t, 9.0, load(t, "D").Fields["rollup"])
+	})
+
+	s.T().Run("removed child isn't aggregated", func(t *testing.T) {
+		err := link.NewWorkItemLinkRepository(s.DB).Delete(s.Ctx, fxt.WorkItemLinks[2].ID, fxt.Identities[0].ID)
+		require.NoError(t, err)
+		a := load(t, "A")
+		require.Equal(t, 5.0, a.Fields["total_points"])
+		require.Equal(t, 1.0, a.Fields["children"])
+		require.Equal(t, 5.0, load(t, "D").Fields["rollup"])
 	})
 }
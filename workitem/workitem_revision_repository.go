@@ -2,6 +2,7 @@ package workitem
 
 import (
 	"context"
+	"strconv"
 
 	"time"
 
@@ -18,6 +19,9 @@ type RevisionRepository interface {
 	Create(ctx context.Context, modifierID uuid.UUID, revisionType RevisionType, workitem WorkItemStorage) error
 	// List retrieves all revisions for a given work item
 	List(ctx context.Context, workitemID uuid.UUID) ([]Revision, error)
+	// LoadByVersion retrieves the revision of a given work item that was
+	// written for the given work item version
+	LoadByVersion(ctx context.Context, workitemID uuid.UUID, version int) (*Revision, error)
 }
 
 // NewRevisionRepository creates a GormRevisionRepository
@@ -67,3 +71,18 @@ func (r *GormRevisionRepository) List(ctx context.Context, workitemID uuid.UUID)
 	}
 	return revisions, nil
 }
+
+// LoadByVersion retrieves the revision of a given work item that was written
+// for the given work item version
+func (r *GormRevisionRepository) LoadByVersion(ctx context.Context, workitemID uuid.UUID, version int) (*Revision, error) {
+	log.Debug(nil, map[string]interface{}{}, "Load revision %d of work item with ID=%v", version, workitemID)
+	var revision Revision
+	tx := r.db.Where("work_item_id = ? AND work_item_version = ?", workitemID, version).Order("revision_time desc").First(&revision)
+	if tx.RecordNotFound() {
+		return nil, errors.NewNotFoundError("work item revision", strconv.Itoa(version))
+	}
+	if err := tx.Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrap(err, "failed to retrieve work item revision"))
+	}
+	return &revision, nil
+}
@@ -26,6 +26,7 @@ const (
 	SystemDescriptionMarkup   = "system.description.markup"
 	SystemDescriptionRendered = "system.description.rendered"
 	SystemState               = "system.state"
+	SystemMetaState           = "system.metastate"
 	SystemAssignees           = "system.assignees"
 	SystemCreator             = "system.creator"
 	SystemCreatedAt           = "system.created_at"
@@ -109,6 +110,10 @@ type WorkItemType struct {
 	// type of this work item. This field is filled upon loading the work item
 	// type from the DB.
 	ChildTypeIDs []uuid.UUID `gorm:"-" json:"child_types,omitempty"`
+
+	// Transitions are the allowed changes of the state field of work items of
+	// this type. If there are no transitions, all changes are allowed.
+	Transitions Transitions `sql:"type:jsonb" json:"transitions,omitempty"`
 }
 
 // GetTypePathSeparator returns the work item type's path separator "."
@@ -128,6 +133,16 @@ func LtreeSafeID(witID uuid.UUID) string {
 	return strings.Replace(witID.String(), "-", "_", -1)
 }
 
+// ExtendedTypeID returns the ID of the work item type which this type
+// extends, if any. It is the second to last element of the type's path.
+func (wit WorkItemType) ExtendedTypeID() uuid.UUID {
+	elems := strings.Split(wit.Path, pathSep)
+	if len(elems) < 2 {
+		return uuid.Nil
+	}
+	return uuid.FromStringOrNil(strings.Replace(elems[len(elems)-2], "_", "-", -1))
+}
+
 // TableName implements gorm.tabler
 func (wit WorkItemType) TableName() string {
 	return "work_item_types"
@@ -209,6 +224,9 @@ func (wit WorkItemType) Equal(u convert.Equaler) bool {
 	if wit.SpaceTemplateID != other.SpaceTemplateID {
 		return false
 	}
+	if !wit.Transitions.Equal(other.Transitions) {
+		return false
+	}
 	return true
 }
 
@@ -224,12 +242,21 @@ func (wit WorkItemType) ConvertWorkItemStorageToModel(workItem WorkItemStorage)
 		relationShipsChangedAt: workItem.RelationShipsChangedAt,
 	}
 
+	now := time.Now()
 	for name, field := range wit.Fields {
 		var err error
 		if name == SystemCreatedAt {
 			continue
 		}
-		result.Fields[name], err = field.ConvertFromModel(name, workItem.Fields[name])
+		value := workItem.Fields[name]
+		// the stored value is outdated as soon as time passes
+		if t, ok := field.computedType(); ok && t.dependsOnTime() {
+			value, err = t.Compute(storedFields(workItem, now), nil, now)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to compute field %q", name)
+			}
+		}
+		result.Fields[name], err = field.ConvertFromModel(name, value)
 		if err != nil {
 			return nil, errors.WithStack(err)
 		}
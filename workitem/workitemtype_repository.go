@@ -2,6 +2,7 @@ package workitem
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/fabric8-services/fabric8-wit/application/repository"
@@ -28,6 +29,9 @@ type WorkItemTypeRepository interface {
 	List(ctx context.Context, spaceTemplateID uuid.UUID) ([]WorkItemType, error)
 	ListPlannerItemTypes(ctx context.Context, spaceTemplateID uuid.UUID) ([]WorkItemType, error)
 	AddChildTypes(ctx context.Context, parentTypeID uuid.UUID, childTypeIDs []uuid.UUID) error
+	Save(ctx context.Context, wit WorkItemType) (*WorkItemType, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	LoadFieldDefinition(ctx context.Context, spaceTemplateID uuid.UUID, name string) (*FieldDefinition, error)
 }
 
 // NewWorkItemTypeRepository creates a wi type repository based on gorm
@@ -52,28 +56,38 @@ func (r *GormWorkItemTypeRepository) Load(ctx context.Context, id uuid.UUID) (*W
 		log.Info(ctx, map[string]interface{}{
 			"wit_id": id,
 		}, "Work item type doesn't exist in the cache. Loading from DB...")
-		res = WorkItemType{}
-
-		db := r.db.Model(&res).Where("id=?", id).First(&res)
-		if db.RecordNotFound() {
-			log.Error(ctx, map[string]interface{}{
-				"wit_id": id,
-			}, "work item type not found")
-			return nil, errors.NewNotFoundError("work item type", id.String())
-		}
-		if err := db.Error; err != nil {
-			return nil, errors.NewInternalError(ctx, err)
-		}
-		childTypes, err := r.loadChildTypeList(ctx, res.ID)
+		wit, err := r.loadFromDB(ctx, id)
 		if err != nil {
-			return nil, errs.Wrapf(err, `failed to load child types for WIT "%s" (%s)`, res.Name, res.ID)
+			return nil, err
 		}
-		res.ChildTypeIDs = childTypes
+		res = *wit
 		cache.Put(res)
 	}
 	return &res, nil
 }
 
+// loadFromDB returns the work item type with the given ID without looking at
+// the cache
+func (r *GormWorkItemTypeRepository) loadFromDB(ctx context.Context, id uuid.UUID) (*WorkItemType, error) {
+	res := WorkItemType{}
+	db := r.db.Model(&res).Where("id=?", id).First(&res)
+	if db.RecordNotFound() {
+		log.Error(ctx, map[string]interface{}{
+			"wit_id": id,
+		}, "work item type not found")
+		return nil, errors.NewNotFoundError("work item type", id.String())
+	}
+	if err := db.Error; err != nil {
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	childTypes, err := r.loadChildTypeList(ctx, res.ID)
+	if err != nil {
+		return nil, errs.Wrapf(err, `failed to load child types for WIT "%s" (%s)`, res.Name, res.ID)
+	}
+	res.ChildTypeIDs = childTypes
+	return &res, nil
+}
+
 // CheckExists returns nil if the given ID exists otherwise returns an error
 func (r *GormWorkItemTypeRepository) CheckExists(ctx context.Context, id uuid.UUID) error {
 	defer goa.MeasureSince([]string{"goa", "db", "workitemtype", "exists"}, time.Now())
@@ -88,6 +102,34 @@ func (r *GormWorkItemTypeRepository) CheckExists(ctx context.Context, id uuid.UU
 	return repository.CheckExists(ctx, r.db, WorkItemType{}.TableName(), id)
 }
 
+// LoadFieldDefinition returns the definition of the field with the given name
+// from the work item types of the given space template which have such a
+// field. A NotFoundError is returned if no type has the field and a
+// BadParameterError if the types store values of different kinds for it.
+func (r *GormWorkItemTypeRepository) LoadFieldDefinition(ctx context.Context, spaceTemplateID uuid.UUID, name string) (*FieldDefinition, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemtype", "loadFieldDefinition"}, time.Now())
+	var wits []WorkItemType
+	db := r.db.Select("fields").Where("space_template_id = ? AND fields->(?::text) IS NOT NULL", spaceTemplateID, name).Find(&wits)
+	if err := db.Error; err != nil {
+		log.Error(ctx, map[string]interface{}{
+			"space_template_id": spaceTemplateID,
+			"field":             name,
+			"err":               err,
+		}, "unable to load the work item types with the field")
+		return nil, errors.NewInternalError(ctx, err)
+	}
+	if len(wits) == 0 {
+		return nil, errors.NewNotFoundError("work item field", name)
+	}
+	res := wits[0].Fields[name]
+	for _, wit := range wits[1:] {
+		if def := wit.Fields[name]; def.Type == nil || def.ValueKind() != res.ValueKind() || def.Type.GetKind() != res.Type.GetKind() {
+			return nil, errors.NewBadParameterError("field", name).Expected("field with the same kind in all work item types")
+		}
+	}
+	return &res, nil
+}
+
 // ClearGlobalWorkItemTypeCache removes all work items from the global cache
 func ClearGlobalWorkItemTypeCache() {
 	cache.Clear()
@@ -152,6 +194,9 @@ func (r *GormWorkItemTypeRepository) CreateFromModel(ctx context.Context, model
 	model.Version = 0
 	model.Path = path
 	model.Fields = allFields
+	if err := model.ValidateTransitions(); err != nil {
+		return nil, errs.WithStack(err)
+	}
 
 	db := r.db.Create(&model)
 	if db.Error != nil {
@@ -160,6 +205,203 @@ func (r *GormWorkItemTypeRepository) CreateFromModel(ctx context.Context, model
 	return &model, nil
 }
 
+// Save updates the name, description, icon, fields and transitions of the
+// given work item type and whether work items can be created from it. Fields
+// inherited from the extended type must be kept as they are and the other
+// fields may only change their types in a compatible way. A field can only be
+// removed as long as no work item has a value for it. Changes to the fields
+// are passed on to all subtypes. The child types are replaced unless they are
+// nil.
+func (r *GormWorkItemTypeRepository) Save(ctx context.Context, wit WorkItemType) (*WorkItemType, error) {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemtype", "save"}, time.Now())
+	res, err := r.loadFromDB(ctx, wit.ID)
+	if err != nil {
+		return nil, err
+	}
+	if res.Version != wit.Version {
+		return nil, errors.NewVersionConflictError("version conflict")
+	}
+	oldFields := res.Fields
+	candidate := *res
+	candidate.Name = wit.Name
+	candidate.Fields = wit.Fields
+	candidate.Transitions = wit.Transitions
+	if err := candidate.Validate(); err != nil {
+		return nil, errs.WithStack(err)
+	}
+	if extendedTypeID := res.ExtendedTypeID(); extendedTypeID != uuid.Nil {
+		extendedType, err := r.loadFromDB(ctx, extendedTypeID)
+		if err != nil {
+			return nil, errs.Wrapf(err, "failed to load the extended type of work item type %s", wit.ID)
+		}
+		for name, def := range extendedType.Fields {
+			newDef, ok := wit.Fields[name]
+			if !ok {
+				return nil, errors.NewBadParameterError("fields", name).Expected("field inherited from work item type " + extendedType.Name)
+			}
+			if !compatibleFields(def, newDef) {
+				return nil, errors.NewBadParameterError(fmt.Sprintf("field %q", name), newDef.Type.GetKind()).Expected("same type and required flag as in work item type " + extendedType.Name)
+			}
+		}
+	}
+	var removed []string
+	// the stored values of computed fields are derived anew if a computed
+	// field is added, changed or removed
+	recompute := false
+	for name, def := range wit.Fields {
+		if old, ok := res.Fields[name]; def.IsComputed() && (!ok || !old.Equal(def)) {
+			recompute = true
+		}
+	}
+	for name, def := range res.Fields {
+		newDef, ok := wit.Fields[name]
+		if !ok {
+			removed = append(removed, name)
+			recompute = recompute || def.IsComputed()
+			continue
+		}
+		if def.IsComputed() && newDef.IsComputed() {
+			continue
+		}
+		if !compatibleTypeChange(def.Type, newDef.Type) {
+			return nil, errors.NewBadParameterError(fmt.Sprintf("type of field %q", name), newDef.Type.GetKind()).Expected(fmt.Sprintf("type compatible with %s", def.Type.GetKind()))
+		}
+	}
+	subtypes, err := r.listSubtypes(ctx, *res)
+	if err != nil {
+		return nil, err
+	}
+	typeIDs := []uuid.UUID{res.ID}
+	for _, subtype := range subtypes {
+		typeIDs = append(typeIDs, subtype.ID)
+	}
+	for _, name := range removed {
+		if res.Fields[name].IsComputed() {
+			db := r.db.Model(&WorkItemStorage{}).Where("type IN (?)", typeIDs).UpdateColumn("fields", gorm.Expr("fields - ?", name))
+			if db.Error != nil {
+				return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to remove the values of computed field %s", name))
+			}
+			continue
+		}
+		var count int
+		db := r.db.Model(&WorkItemStorage{}).Where("type IN (?) AND fields->>? IS NOT NULL", typeIDs, name).Count(&count)
+		if db.Error != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to count the work items with values for field %s", name))
+		}
+		if count > 0 {
+			return nil, errors.NewDataConflictError(fmt.Sprintf("field %q can't be removed from work item type %s because %d work items have a value for it", name, res.Name, count))
+		}
+	}
+
+	res.Name = wit.Name
+	res.Description = wit.Description
+	res.Icon = wit.Icon
+	res.CanConstruct = wit.CanConstruct
+	res.Fields = wit.Fields
+	res.Transitions = wit.Transitions
+	res.Version = res.Version + 1
+	if err := r.db.Save(res).Error; err != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrapf(err, "failed to update work item type %s", wit.ID))
+	}
+	for _, subtype := range subtypes {
+		if subtype.Fields == nil {
+			subtype.Fields = FieldDefinitions{}
+		}
+		for name, def := range wit.Fields {
+			if old, ok := oldFields[name]; !ok || !old.Equal(def) {
+				subtype.Fields[name] = def
+			}
+		}
+		for _, name := range removed {
+			delete(subtype.Fields, name)
+		}
+		if err := subtype.ValidateTransitions(); err != nil {
+			return nil, errs.Wrapf(err, "the change breaks the transitions of subtype %s", subtype.Name)
+		}
+		subtype.Version = subtype.Version + 1
+		if err := r.db.Save(&subtype).Error; err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(err, "failed to update work item type %s", subtype.ID))
+		}
+	}
+	if wit.ChildTypeIDs != nil {
+		db := r.db.Unscoped().Delete(ChildType{}, "parent_work_item_type_id = ?", res.ID)
+		if db.Error != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to delete the child types of work item type %s", res.ID))
+		}
+		if err := r.AddChildTypes(ctx, res.ID, wit.ChildTypeIDs); err != nil {
+			return nil, errs.WithStack(err)
+		}
+		res.ChildTypeIDs = wit.ChildTypeIDs
+	}
+	ClearGlobalWorkItemTypeCache()
+	if recompute {
+		var ids []uuid.UUID
+		if err := r.db.Model(&WorkItemStorage{}).Where("type IN (?)", typeIDs).Pluck("id", &ids).Error; err != nil {
+			return nil, errors.NewInternalError(ctx, errs.Wrapf(err, "failed to list the work items of work item type %s", res.ID))
+		}
+		if err := NewWorkItemRepository(r.db).RecomputeFields(ctx, ids...); err != nil {
+			return nil, errs.Wrapf(err, "failed to compute the fields of the work items of work item type %s", res.ID)
+		}
+	}
+	log.Debug(ctx, map[string]interface{}{
+		"wit_id":   res.ID,
+		"subtypes": len(subtypes),
+	}, "work item type updated")
+	return res, nil
+}
+
+// Delete removes the work item type from its space template and from all type
+// groups and child type lists. A type can't be removed as long as work items
+// or subtypes of it exist.
+func (r *GormWorkItemTypeRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer goa.MeasureSince([]string{"goa", "db", "workitemtype", "delete"}, time.Now())
+	wit, err := r.loadFromDB(ctx, id)
+	if err != nil {
+		return err
+	}
+	var count int
+	if err := r.db.Model(&WorkItemStorage{}).Where("type = ?", id).Count(&count).Error; err != nil {
+		return errors.NewInternalError(ctx, errs.Wrapf(err, "failed to count the work items of type %s", id))
+	}
+	if count > 0 {
+		return errors.NewDataConflictError(fmt.Sprintf("work item type %s can't be deleted because %d work items have it", wit.Name, count))
+	}
+	subtypes, err := r.listSubtypes(ctx, *wit)
+	if err != nil {
+		return err
+	}
+	if len(subtypes) > 0 {
+		return errors.NewDataConflictError(fmt.Sprintf("work item type %s can't be deleted because %d work item types extend it", wit.Name, len(subtypes)))
+	}
+	db := r.db.Unscoped().Delete(ChildType{}, "parent_work_item_type_id = ? OR child_work_item_type_id = ?", id, id)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to delete the child types of work item type %s", id))
+	}
+	db = r.db.Unscoped().Delete(typeGroupMember{}, "work_item_type_id = ?", id)
+	if db.Error != nil {
+		return errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to remove work item type %s from its type groups", id))
+	}
+	if err := r.db.Delete(wit).Error; err != nil {
+		return errors.NewInternalError(ctx, errs.Wrapf(err, "failed to delete work item type %s", id))
+	}
+	ClearGlobalWorkItemTypeCache()
+	log.Debug(ctx, map[string]interface{}{
+		"wit_id": id,
+	}, "work item type deleted")
+	return nil
+}
+
+// listSubtypes returns all work item types which directly or indirectly
+// extend the given type
+func (r *GormWorkItemTypeRepository) listSubtypes(ctx context.Context, wit WorkItemType) ([]WorkItemType, error) {
+	var res []WorkItemType
+	db := r.db.Where("path::text LIKE ?", wit.Path+pathSep+"%").Order("created_at").Find(&res)
+	if db.Error != nil {
+		return nil, errors.NewInternalError(ctx, errs.Wrapf(db.Error, "failed to list the subtypes of work item type %s", wit.ID))
+	}
+	return res, nil
+}
+
 // ListPlannerItemTypes returns work item types that derives from PlannerItem type
 func (r *GormWorkItemTypeRepository) ListPlannerItemTypes(ctx context.Context, spaceTemplateID uuid.UUID) ([]WorkItemType, error) {
 	defer goa.MeasureSince([]string{"goa", "db", "workitemtype", "listPlannerItemTypes"}, time.Now())
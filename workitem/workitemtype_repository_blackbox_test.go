@@ -8,6 +8,7 @@ import (
 	"github.com/fabric8-services/fabric8-wit/id"
 	tf "github.com/fabric8-services/fabric8-wit/test/testfixture"
 	"github.com/fabric8-services/fabric8-wit/workitem"
+	errs "github.com/pkg/errors"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -272,3 +273,244 @@ func (s *workItemTypeRepoBlackBoxTest) TestAddChildTypes() {
 		require.Equal(t, []uuid.UUID{fxt.WorkItemTypes[2].ID}, wit.ChildTypeIDs)
 	})
 }
+
+func (s *workItemTypeRepoBlackBoxTest) TestSave() {
+	customer := workitem.FieldDefinition{
+		Label:       "Customer",
+		Description: "The customer who asked for it",
+		Type:        workitem.SimpleType{Kind: workitem.KindString},
+	}
+	// withFields returns a copy of the given type with a copy of its fields
+	// which the given function may change
+	withFields := func(wit workitem.WorkItemType, change func(fields workitem.FieldDefinitions)) workitem.WorkItemType {
+		fields := workitem.FieldDefinitions{}
+		for name, def := range wit.Fields {
+			fields[name] = def
+		}
+		change(fields)
+		wit.Fields = fields
+		return wit
+	}
+	extendFirst := func(fxt *tf.TestFixture, idx int) error {
+		if idx == 1 {
+			fxt.WorkItemTypes[1].Extends = fxt.WorkItemTypes[0].ID
+		}
+		return nil
+	}
+
+	s.T().Run("add field and pass it on to subtypes", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(2, extendFirst))
+		wit, err := s.repo.Load(s.Ctx, fxt.WorkItemTypes[0].ID)
+		require.NoError(t, err)
+		// when
+		changed := withFields(*wit, func(fields workitem.FieldDefinitions) {
+			fields["customer"] = customer
+		})
+		changed.Name = "renamed"
+		saved, err := s.repo.Save(s.Ctx, changed)
+		// then
+		require.NoError(t, err)
+		require.Equal(t, wit.Version+1, saved.Version)
+		require.Equal(t, "renamed", saved.Name)
+		require.Equal(t, customer, saved.Fields["customer"])
+		loaded, err := s.repo.Load(s.Ctx, fxt.WorkItemTypes[0].ID)
+		require.NoError(t, err)
+		require.Equal(t, "renamed", loaded.Name)
+		subtype, err := s.repo.Load(s.Ctx, fxt.WorkItemTypes[1].ID)
+		require.NoError(t, err)
+		require.Equal(t, customer, subtype.Fields["customer"])
+		require.Equal(t, fxt.WorkItemTypes[1].Version+1, subtype.Version)
+	})
+
+	s.T().Run("inherited enum changed", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		wit, err := s.repo.Load(s.Ctx, fxt.WorkItemTypes[0].ID)
+		require.NoError(t, err)
+		state := wit.Fields[workitem.SystemState]
+		enum := state.Type.(workitem.EnumType)
+		// when
+		_, err = s.repo.Save(s.Ctx, withFields(*wit, func(fields workitem.FieldDefinitions) {
+			enum.Values = append(append([]interface{}{}, enum.Values...), "blocked")
+			state.Type = enum
+			fields[workitem.SystemState] = state
+		}))
+		// then the state is inherited from the planner item and must not change
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("version conflict", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		wit := *fxt.WorkItemTypes[0]
+		wit.Version = wit.Version + 1
+		// when
+		_, err := s.repo.Save(s.Ctx, wit)
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.VersionConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("inherited field removed", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1))
+		// when
+		_, err := s.repo.Save(s.Ctx, withFields(*fxt.WorkItemTypes[0], func(fields workitem.FieldDefinitions) {
+			delete(fields, workitem.SystemTitle)
+		}))
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+
+	s.T().Run("own fields", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB,
+			tf.WorkItemTypes(2, func(fxt *tf.TestFixture, idx int) error {
+				fxt.WorkItemTypes[idx].Fields = workitem.FieldDefinitions{"customer": customer}
+				return nil
+			}),
+			tf.WorkItems(1, tf.SetWorkItemField("customer", "ACME")),
+		)
+		used, unused := *fxt.WorkItemTypes[0], *fxt.WorkItemTypes[1]
+
+		t.Run("incompatible type change", func(t *testing.T) {
+			_, err := s.repo.Save(s.Ctx, withFields(unused, func(fields workitem.FieldDefinitions) {
+				changed := customer
+				changed.Type = workitem.SimpleType{Kind: workitem.KindInteger}
+				fields["customer"] = changed
+			}))
+			require.Error(t, err)
+			require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+		t.Run("invalid field", func(t *testing.T) {
+			_, err := s.repo.Save(s.Ctx, withFields(unused, func(fields workitem.FieldDefinitions) {
+				fields["priority"] = workitem.FieldDefinition{
+					Label: "Priority",
+					Type:  workitem.EnumType{SimpleType: workitem.SimpleType{Kind: workitem.KindEnum}, BaseType: workitem.SimpleType{Kind: workitem.KindString}},
+				}
+			}))
+			require.Error(t, err)
+			require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+		})
+		t.Run("remove field with values", func(t *testing.T) {
+			_, err := s.repo.Save(s.Ctx, withFields(used, func(fields workitem.FieldDefinitions) {
+				delete(fields, "customer")
+			}))
+			require.Error(t, err)
+			require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+		})
+		t.Run("remove field without values", func(t *testing.T) {
+			saved, err := s.repo.Save(s.Ctx, withFields(unused, func(fields workitem.FieldDefinitions) {
+				delete(fields, "customer")
+			}))
+			require.NoError(t, err)
+			require.NotContains(t, saved.Fields, "customer")
+		})
+	})
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestDelete() {
+	s.T().Run("ok", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(2), tf.WorkItemTypeGroups(1))
+		require.NoError(t, s.repo.AddChildTypes(s.Ctx, fxt.WorkItemTypes[1].ID, []uuid.UUID{fxt.WorkItemTypes[0].ID}))
+		// when
+		err := s.repo.Delete(s.Ctx, fxt.WorkItemTypes[0].ID)
+		// then
+		require.NoError(t, err)
+		_, err = s.repo.Load(s.Ctx, fxt.WorkItemTypes[0].ID)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+		parent, err := s.repo.Load(s.Ctx, fxt.WorkItemTypes[1].ID)
+		require.NoError(t, err)
+		require.Empty(t, parent.ChildTypeIDs)
+		group, err := workitem.NewWorkItemTypeGroupRepository(s.DB).Load(s.Ctx, fxt.WorkItemTypeGroups[0].ID)
+		require.NoError(t, err)
+		require.Empty(t, group.TypeList)
+	})
+
+	s.T().Run("used by work items", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(1), tf.WorkItems(1))
+		// when
+		err := s.repo.Delete(s.Ctx, fxt.WorkItemTypes[0].ID)
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("extended by another type", func(t *testing.T) {
+		// given
+		fxt := tf.NewTestFixture(t, s.DB, tf.WorkItemTypes(2, func(fxt *tf.TestFixture, idx int) error {
+			if idx == 1 {
+				fxt.WorkItemTypes[1].Extends = fxt.WorkItemTypes[0].ID
+			}
+			return nil
+		}))
+		// when
+		err := s.repo.Delete(s.Ctx, fxt.WorkItemTypes[0].ID)
+		// then
+		require.Error(t, err)
+		require.IsType(t, errors.DataConflictError{}, errs.Cause(err))
+	})
+
+	s.T().Run("unknown type", func(t *testing.T) {
+		err := s.repo.Delete(s.Ctx, uuid.NewV4())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}
+
+func (s *workItemTypeRepoBlackBoxTest) TestLoadFieldDefinition() {
+	// given two types sharing a field and a third one with another kind, and
+	// a type of another space template which stores text in the shared field
+	field := "effort_" + uuid.NewV4().String()
+	other := "severity_" + uuid.NewV4().String()
+	fxt := tf.NewTestFixture(s.T(), s.DB, tf.SpaceTemplates(2), tf.WorkItemTypes(4, func(fxt *tf.TestFixture, idx int) error {
+		if idx == 3 {
+			fxt.WorkItemTypes[idx].SpaceTemplateID = fxt.SpaceTemplates[1].ID
+			fxt.WorkItemTypes[idx].Fields[field] = workitem.FieldDefinition{
+				Label: "Effort",
+				Type:  workitem.SimpleType{Kind: workitem.KindString},
+			}
+			return nil
+		}
+		fxt.WorkItemTypes[idx].Fields[field] = workitem.FieldDefinition{
+			Label: "Effort",
+			Type:  workitem.SimpleType{Kind: workitem.KindFloat},
+		}
+		kind := workitem.KindString
+		if idx == 2 {
+			kind = workitem.KindInteger
+		}
+		fxt.WorkItemTypes[idx].Fields[other] = workitem.FieldDefinition{
+			Label: "Severity",
+			Type:  workitem.SimpleType{Kind: kind},
+		}
+		return nil
+	}))
+
+	s.T().Run("ok", func(t *testing.T) {
+		def, err := s.repo.LoadFieldDefinition(s.Ctx, fxt.SpaceTemplates[0].ID, field)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.KindFloat, def.Type.GetKind())
+	})
+	s.T().Run("other space template", func(t *testing.T) {
+		def, err := s.repo.LoadFieldDefinition(s.Ctx, fxt.SpaceTemplates[1].ID, field)
+		require.NoError(t, err)
+		assert.Equal(t, workitem.KindString, def.Type.GetKind())
+	})
+	s.T().Run("different kinds", func(t *testing.T) {
+		_, err := s.repo.LoadFieldDefinition(s.Ctx, fxt.SpaceTemplates[0].ID, other)
+		require.IsType(t, errors.BadParameterError{}, errs.Cause(err))
+	})
+	s.T().Run("unknown field", func(t *testing.T) {
+		_, err := s.repo.LoadFieldDefinition(s.Ctx, fxt.SpaceTemplates[0].ID, "unknown_"+uuid.NewV4().String())
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+	s.T().Run("field of another space template", func(t *testing.T) {
+		_, err := s.repo.LoadFieldDefinition(s.Ctx, fxt.SpaceTemplates[1].ID, other)
+		require.IsType(t, errors.NotFoundError{}, errs.Cause(err))
+	})
+}